@@ -0,0 +1,38 @@
+package solanavalidatorfailover
+
+import (
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	agentCmd = &cobra.Command{
+		Use:          "agent",
+		Short:        "run on the active node so a passive node can initiate a failover with run --via-agent",
+		SilenceUsage: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := loadConfig()
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to load config")
+			}
+
+			v, err := validator.NewFromConfig(&cfg.Validator)
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to create validator")
+			}
+
+			// re-create the validator for each request so its role is re-read from gossip
+			err = v.RunAgent(func() (*validator.Validator, error) {
+				return validator.NewFromConfig(&cfg.Validator)
+			})
+			if err != nil {
+				log.Fatal().Err(err).Msg("agent stopped")
+			}
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(agentCmd)
+}
@@ -0,0 +1,69 @@
+package solanavalidatorfailover
+
+import (
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/peertrust"
+	"github.com/sol-strategies/solana-validator-failover/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	clientCertsOutDir string
+	clientCertsCmd    = &cobra.Command{
+		Use:   "client-certs",
+		Short: "create a certificate authority and issue the client certificates peers present when validator.failover.tls.require_client_certificates is set",
+	}
+
+	clientCertsInitCACmd = &cobra.Command{
+		Use:          "init-ca",
+		Short:        "create the client certificate authority in <config.validator.failover.tls.dir> - copy its client-ca.pem to every node",
+		SilenceUsage: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			dir := loadTLSDir("it holds the client certificate authority")
+
+			certFile, err := peertrust.CreateClientCA(dir)
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to create client certificate authority")
+			}
+			log.Info().
+				Str("cert_file", certFile).
+				Msgf("Created client certificate authority - copy %s to the tls dir of every node", peertrust.ClientCACertFileName)
+		},
+	}
+
+	clientCertsIssueCmd = &cobra.Command{
+		Use:          "issue <peer-name>",
+		Short:        "issue a client certificate for the node other nodes know as <peer-name> in validator.failover.peers",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			dir := loadTLSDir("it holds the client certificate authority")
+
+			outDir := dir
+			if clientCertsOutDir != "" {
+				var err error
+				outDir, err = utils.ResolvePath(clientCertsOutDir)
+				if err != nil {
+					log.Fatal().Err(err).Msg("invalid --out-dir")
+				}
+			}
+
+			certFile, keyFile, err := peertrust.IssueClientCertificate(dir, args[0], outDir)
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to issue client certificate")
+			}
+			log.Info().
+				Str("peer", args[0]).
+				Str("cert_file", certFile).
+				Str("key_file", keyFile).
+				Msgf("Issued client certificate - copy it to the tls dir of %s as %s and %s", args[0], peertrust.ClientCertFileName, peertrust.ClientKeyFileName)
+		},
+	}
+)
+
+func init() {
+	clientCertsIssueCmd.Flags().StringVar(&clientCertsOutDir, "out-dir", "", "directory to write the certificate and key to (default: <config.validator.failover.tls.dir>)")
+	clientCertsCmd.AddCommand(clientCertsInitCACmd)
+	clientCertsCmd.AddCommand(clientCertsIssueCmd)
+	rootCmd.AddCommand(clientCertsCmd)
+}
@@ -0,0 +1,96 @@
+package solanavalidatorfailover
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/failover"
+	"github.com/sol-strategies/solana-validator-failover/internal/style"
+	"github.com/sol-strategies/solana-validator-failover/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	configCmd = &cobra.Command{
+		Use:   "config",
+		Short: "inspect this node's effective config",
+	}
+
+	configDiffPeerName     string
+	configDiffOutputFormat string
+	configDiffCmd          = &cobra.Command{
+		Use:          "diff",
+		Short:        "compare this node's effective config with a peer's, fetched from its agent, and list the differences - the peer must set failover.agent.share_config",
+		SilenceUsage: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := (failover.OutputConfig{Format: configDiffOutputFormat}).Validate(); err != nil {
+				log.Fatal().Err(err).Msg("invalid --output")
+			}
+
+			cfg, err := loadConfig()
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to load config")
+			}
+
+			v, err := validator.NewFromConfig(&cfg.Validator)
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to create validator")
+			}
+
+			peerHostname, differences, err := v.ConfigDiff(configDiffPeerName)
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to diff config")
+			}
+
+			if configDiffOutputFormat == failover.OutputFormatJSON {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				if differences == nil {
+					differences = []failover.ConfigDifference{}
+				}
+				if err := encoder.Encode(differences); err != nil {
+					log.Fatal().Err(err).Msg("failed to write config diff")
+				}
+			} else if len(differences) == 0 {
+				log.Info().Str("peer", peerHostname).Msg("Config matches peer")
+			} else {
+				fmt.Println(renderConfigDiffTable(v.Hostname, peerHostname, differences))
+				fmt.Printf("%d difference(s) with %s\n", len(differences), peerHostname)
+			}
+
+			if len(differences) > 0 {
+				os.Exit(1)
+			}
+		},
+	}
+)
+
+// renderConfigDiffTable renders config differences as a table of key, this node's value and the peer's
+func renderConfigDiffTable(hostname, peerHostname string, differences []failover.ConfigDifference) string {
+	rows := make([][]string, 0, len(differences))
+	for _, difference := range differences {
+		rows = append(rows, []string{difference.Key, difference.Local, difference.Peer})
+	}
+
+	return style.RenderTable(
+		[]string{"Key", hostname, peerHostname},
+		rows,
+		func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return style.TableHeaderStyle
+			}
+			return style.TableCellStyle.Align(lipgloss.Left)
+		},
+	)
+}
+
+func init() {
+	configDiffCmd.Flags().StringVar(&configDiffPeerName, "peer", "", "name of the peer in failover.peers to diff against - required when more than one is configured")
+	configDiffCmd.Flags().StringVarP(&configDiffOutputFormat, "output", "o", failover.OutputFormatText, "text for a styled table or json for a json array of differences on stdout")
+	configCmd.AddCommand(configDiffCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,85 @@
+package solanavalidatorfailover
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/constants"
+	"github.com/sol-strategies/solana-validator-failover/internal/failover"
+	"github.com/sol-strategies/solana-validator-failover/internal/identities"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mockPeerRole          string
+	mockPeerScript        string
+	mockPeerPort          int
+	mockPeerServerAddress string
+	mockPeerName          string
+	mockPeerPublicIP      string
+	mockPeerStepDelay     time.Duration
+	devCmd                = &cobra.Command{
+		Use:   "dev",
+		Short: "tools for developing hooks, integrations and this program",
+	}
+
+	devMockPeerCmd = &cobra.Command{
+		Use:          "mock-peer",
+		Short:        "pretend to be the other side of a dry-run failover so hooks and ui can be worked on without a second node",
+		SilenceUsage: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			if mockPeerRole == constants.NodeRoleActive && mockPeerServerAddress == "" {
+				log.Fatal().Msg("--server-address is required when the mock peer plays the active node")
+			}
+
+			cfg, err := loadConfig()
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to load config")
+			}
+
+			// only the identities are needed - peers authenticate with the active identity keypair
+			ids, err := identities.NewFromConfig(&cfg.Validator.Identities)
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to load identities")
+			}
+
+			port := mockPeerPort
+			if port == 0 {
+				port = cfg.Validator.Failover.Server.Port
+			}
+
+			mockPeer, err := failover.NewMockPeer(failover.MockPeerConfig{
+				Role:          mockPeerRole,
+				Script:        mockPeerScript,
+				Port:          port,
+				ServerAddress: mockPeerServerAddress,
+				Hostname:      mockPeerName,
+				PublicIP:      mockPeerPublicIP,
+				Identities:    ids,
+				StepDelay:     mockPeerStepDelay,
+			})
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to create mock peer")
+			}
+
+			err = mockPeer.Start()
+			if err != nil {
+				log.Fatal().Err(err).Msg("mock peer failed")
+			}
+		},
+	}
+)
+
+func init() {
+	devMockPeerCmd.Flags().StringVar(&mockPeerRole, "role", constants.NodeRolePassive, fmt.Sprintf("role the mock peer plays - %s listens for the active node, %s connects to a passive node", constants.NodeRolePassive, constants.NodeRoleActive))
+	devMockPeerCmd.Flags().StringVar(&mockPeerScript, "script", failover.MockPeerScriptHappy, fmt.Sprintf("how the mock peer behaves - one of %s", strings.Join(failover.MockPeerScripts(), ", ")))
+	devMockPeerCmd.Flags().IntVar(&mockPeerPort, "port", 0, "port to listen on when passive (default: <config.validator.failover.server.port>)")
+	devMockPeerCmd.Flags().StringVar(&mockPeerServerAddress, "server-address", "", "host:port of the passive node's failover server to connect to when active")
+	devMockPeerCmd.Flags().StringVar(&mockPeerName, "name", "mock-peer", "hostname the mock peer advertises")
+	devMockPeerCmd.Flags().StringVar(&mockPeerPublicIP, "public-ip", "127.0.0.1", "public IP the mock peer advertises - when active, the passive node looks it up in gossip expecting the active identity")
+	devMockPeerCmd.Flags().DurationVar(&mockPeerStepDelay, "step-delay", 0, "time to pause before each step so the other side can be watched waiting")
+	devCmd.AddCommand(devMockPeerCmd)
+	rootCmd.AddCommand(devCmd)
+}
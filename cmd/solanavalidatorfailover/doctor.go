@@ -0,0 +1,58 @@
+package solanavalidatorfailover
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/failover"
+	"github.com/sol-strategies/solana-validator-failover/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	doctorOutputFormat string
+	doctorCmd          = &cobra.Command{
+		Use:          "doctor",
+		Short:        "run preflight checks on this node and its peers and report each as pass, warn or fail - no failover is performed",
+		SilenceUsage: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := (failover.OutputConfig{Format: doctorOutputFormat}).Validate(); err != nil {
+				log.Fatal().Err(err).Msg("invalid --output")
+			}
+
+			var report validator.DoctorReport
+			cfg, err := loadConfig()
+			if err == nil {
+				var v *validator.Validator
+				v, err = validator.NewFromConfig(&cfg.Validator)
+				if err == nil {
+					report = v.Doctor()
+				}
+			}
+			if err != nil {
+				report = validator.NewConfigDoctorReport(err)
+			}
+
+			if doctorOutputFormat == failover.OutputFormatJSON {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				if err := encoder.Encode(report); err != nil {
+					log.Fatal().Err(err).Msg("failed to write doctor report")
+				}
+			} else {
+				fmt.Println(report.TableString())
+			}
+
+			if report.HasFailures() {
+				os.Exit(1)
+			}
+		},
+	}
+)
+
+func init() {
+	doctorCmd.Flags().StringVarP(&doctorOutputFormat, "output", "o", failover.OutputFormatText, "text for a styled table or json for the checks on stdout")
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -0,0 +1,38 @@
+package solanavalidatorfailover
+
+import (
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/deprecation"
+	"github.com/sol-strategies/solana-validator-failover/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	drillCmd = &cobra.Command{
+		Use:          "drill",
+		Short:        "deprecated - watch runs the drills in <config.validator.failover.drill.schedule>. Runs them like watch without watching the active identity",
+		SilenceUsage: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := loadConfig()
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to load config")
+			}
+
+			deprecation.Use("drill command", "the drill command is deprecated (but still works) in favour of watch, which runs scheduled drills between its checks - run it on the passive node with the agent on the active node")
+
+			v, err := validator.NewFromConfig(&cfg.Validator)
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to create validator")
+			}
+
+			err = v.RunDrills()
+			if err != nil {
+				log.Fatal().Err(err).Msg("drill stopped")
+			}
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(drillCmd)
+}
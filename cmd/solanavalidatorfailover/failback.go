@@ -0,0 +1,54 @@
+package solanavalidatorfailover
+
+import (
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	failbackNotADrill             bool
+	failbackNoWaitForHealthy      bool
+	failbackNoMinTimeToLeaderSlot bool
+	failbackViaAgent              bool
+	failbackPeerName              string
+	failbackNonInteractive        bool
+	failbackCmd                   = &cobra.Command{
+		Use:          "failback",
+		Short:        "reverse the most recent real failover in this node's audit log with the same peer - e.g. to restore the original active node after maintenance",
+		SilenceUsage: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := loadConfig()
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to load config")
+			}
+
+			v, err := validator.NewFromConfig(&cfg.Validator)
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to create validator")
+			}
+
+			err = v.Failback(validator.FailoverParams{
+				NotADrill:             failbackNotADrill, // ignored when run on active node
+				NoWaitForHealthy:      failbackNoWaitForHealthy,
+				NoMinTimeToLeaderSlot: failbackNoMinTimeToLeaderSlot, // ignored when run on passive node unless via agent
+				ViaAgent:              failbackViaAgent,              // ignored when run on active node
+				PeerName:              failbackPeerName,
+				NonInteractive:        failbackNonInteractive,
+			})
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to fail back")
+			}
+		},
+	}
+)
+
+func init() {
+	failbackCmd.Flags().BoolVar(&failbackNotADrill, "not-a-drill", false, "fail back for real (not a drill)")
+	failbackCmd.Flags().BoolVar(&failbackNoWaitForHealthy, "no-wait-for-healthy", false, "don't wait for node to report being healthy by calling <config.validator.rpc_address>/health")
+	failbackCmd.Flags().BoolVar(&failbackNoMinTimeToLeaderSlot, "no-min-time-to-leader-slot", false, "when run on an active node, don't wait until it has no leader slots in the next <config.validator.min_time_to_leader_slot> (default: 5m) - ignored when run on a passive node")
+	failbackCmd.Flags().BoolVar(&failbackViaAgent, "via-agent", false, "when run on a passive node, ask the agent on the active peer to hand over instead of running this program there - ignored when run on an active node")
+	failbackCmd.Flags().StringVar(&failbackPeerName, "peer", "", "name of the peer in <config.validator.failover.peers> to fail back with - defaults to the one the failover was recorded with")
+	failbackCmd.Flags().BoolVarP(&failbackNonInteractive, "yes", "y", false, "never prompt")
+	rootCmd.AddCommand(failbackCmd)
+}
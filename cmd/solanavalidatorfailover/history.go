@@ -0,0 +1,173 @@
+package solanavalidatorfailover
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/audit"
+	"github.com/sol-strategies/solana-validator-failover/internal/failover"
+	"github.com/sol-strategies/solana-validator-failover/internal/style"
+	"github.com/sol-strategies/solana-validator-failover/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyLimit        int
+	historyFailoverID   string
+	historyNode         string
+	historySince        string
+	historyDryRun       bool
+	historyReal         bool
+	historyFailed       bool
+	historyOutputFormat string
+	historyCmd          = &cobra.Command{
+		Use:          "history",
+		Short:        "list past drills and failovers this node took part in from its audit log, newest first",
+		SilenceUsage: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := (failover.OutputConfig{Format: historyOutputFormat}).Validate(); err != nil {
+				log.Fatal().Err(err).Msg("invalid --output")
+			}
+
+			filter := audit.Filter{
+				FailoverID: historyFailoverID,
+				Node:       historyNode,
+				DryRun:     historyDryRun,
+				Real:       historyReal,
+				Failed:     historyFailed,
+			}
+			if historySince != "" {
+				since, err := parseHistorySince(historySince)
+				if err != nil {
+					log.Fatal().Err(err).Msg("invalid --since")
+				}
+				filter.Since = since
+			}
+
+			auditLog := loadAuditLog()
+			records, err := auditLog.Read()
+			if err != nil {
+				// still list the records that could be read
+				log.Warn().Err(err).Msg("some audit records could not be read")
+			}
+			records = audit.Select(records, filter, historyLimit)
+
+			if historyOutputFormat == failover.OutputFormatJSON {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				if err := encoder.Encode(records); err != nil {
+					log.Fatal().Err(err).Msg("failed to write history")
+				}
+				return
+			}
+
+			if len(records) == 0 {
+				log.Info().Str("path", auditLog.Path()).Msg("No failovers recorded")
+				return
+			}
+			fmt.Println(renderHistoryTable(records))
+		},
+	}
+)
+
+// loadAuditLog loads the audit log from <config.validator.failover.audit.dir>
+func loadAuditLog() *audit.Log {
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load config")
+	}
+
+	if cfg.Validator.Failover.Audit.Dir == "" {
+		log.Fatal().Msg("validator.failover.audit.dir is not set - failovers are not recorded")
+	}
+	if !cfg.Validator.Failover.Audit.Enabled {
+		log.Warn().Msg("validator.failover.audit.enabled is false - new failovers are not being recorded")
+	}
+
+	dir, err := utils.ResolvePath(cfg.Validator.Failover.Audit.Dir)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid validator.failover.audit.dir")
+	}
+	return audit.NewLog(dir)
+}
+
+// parseHistorySince parses --since as a duration back from now e.g. 72h, a date or an RFC3339 time
+func parseHistorySince(since string) (time.Time, error) {
+	if d, err := time.ParseDuration(since); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, since); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a duration like 72h, a date like 2025-06-01 or an RFC3339 time", since)
+}
+
+// renderHistoryTable renders audit records as a table, failed ones in the warning color
+func renderHistoryTable(records []audit.Record) string {
+	rows := make([][]string, 0, len(records))
+	for _, record := range records {
+		kind := "drill"
+		if !record.IsDryRun {
+			kind = "real"
+		}
+		result := "success"
+		if !record.Success {
+			result = "failed"
+			if record.ErrorMessage != "" {
+				result = "failed: " + record.ErrorMessage
+			}
+		}
+		rows = append(rows, []string{
+			record.Time.Local().Format(time.DateTime),
+			shortFailoverID(record.FailoverID),
+			kind,
+			record.Role,
+			fmt.Sprintf("%s -> %s", record.ActiveNode.Hostname, record.PassiveNode.Hostname),
+			style.FormatInt(record.Slots),
+			style.FormatDuration(time.Duration(record.IdentityGapMs) * time.Millisecond),
+			style.FormatDuration(time.Duration(record.TotalDurationMs) * time.Millisecond),
+			result,
+		})
+	}
+
+	return style.RenderTable(
+		[]string{"Time", "FailoverID", "Kind", "Role", "ActiveToPassive", "Slots", "IdentityGap", "TotalDuration", "Result"},
+		rows,
+		func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return style.TableHeaderStyle
+			}
+			if !records[row].Success {
+				return style.TableCellStyle.Foreground(style.ColorWarning)
+			}
+			return style.TableCellStyle
+		},
+	)
+}
+
+// shortFailoverID shortens a failover id for the table - --failover-id matches on a prefix
+func shortFailoverID(failoverID string) string {
+	if len(failoverID) > 8 {
+		return failoverID[:8]
+	}
+	return failoverID
+}
+
+func init() {
+	historyCmd.Flags().IntVar(&historyLimit, "limit", 20, "most failovers to list - 0 lists all")
+	historyCmd.Flags().StringVar(&historyFailoverID, "failover-id", "", "only list the failover whose id starts with this")
+	historyCmd.Flags().StringVar(&historyNode, "node", "", "only list failovers with a node of this hostname, public IP or pubkey")
+	historyCmd.Flags().StringVar(&historySince, "since", "", "only list failovers since a duration ago e.g. 72h, a date e.g. 2025-06-01 or an RFC3339 time")
+	historyCmd.Flags().BoolVar(&historyDryRun, "dry-run", false, "only list drills")
+	historyCmd.Flags().BoolVar(&historyReal, "real", false, "only list real failovers (not drills)")
+	historyCmd.Flags().BoolVar(&historyFailed, "failed", false, "only list failovers that didn't complete")
+	historyCmd.Flags().StringVarP(&historyOutputFormat, "output", "o", failover.OutputFormatText, "text for a styled table or json for a json array on stdout")
+	rootCmd.AddCommand(historyCmd)
+}
@@ -0,0 +1,99 @@
+package solanavalidatorfailover
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"syscall"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	monitorBaselineRank int
+	monitorNotADrill    bool
+	monitorCmd          = &cobra.Command{
+		Use:          "monitor",
+		Short:        "monitor the active identity's vote credits as after a failover - started in the background by run --detach-post-monitor",
+		SilenceUsage: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := loadConfig()
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to load config")
+			}
+
+			v, err := validator.NewFromConfig(&cfg.Validator)
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to create validator")
+			}
+
+			err = v.RunPostMonitor(monitorBaselineRank, !monitorNotADrill)
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to monitor vote credits")
+			}
+		},
+	}
+)
+
+func init() {
+	monitorCmd.Flags().IntVar(&monitorBaselineRank, "baseline-rank", 0, "vote credit rank before the failover to compare with - 0 compares with the first sample")
+	monitorCmd.Flags().BoolVar(&monitorNotADrill, "not-a-drill", false, "the failover being monitored was for real (not a drill) - reported in the monitor_complete event")
+	rootCmd.AddCommand(monitorCmd)
+}
+
+// detachPostMonitor returns a function that starts the monitor command in a session of its own so it outlives this
+// process - its output is appended to logFile
+func detachPostMonitor(logFile string, notADrill bool) func(baselineRank int) error {
+	return func(baselineRank int) error {
+		executable, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to find this executable: %w", err)
+		}
+
+		absConfigPath, err := filepath.Abs(configPath)
+		if err != nil {
+			return fmt.Errorf("failed to resolve config path %s: %w", configPath, err)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(logFile), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", logFile, err)
+		}
+		output, err := os.OpenFile(logFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", logFile, err)
+		}
+		defer output.Close()
+
+		args := []string{
+			"monitor",
+			"--config", absConfigPath,
+			"--log-level", logLevel,
+			"--log-format", logFormat,
+			"--baseline-rank", strconv.Itoa(baselineRank),
+		}
+		if validatorName != "" {
+			args = append(args, "--validator", validatorName)
+		}
+		if notADrill {
+			args = append(args, "--not-a-drill")
+		}
+
+		monitor := exec.Command(executable, args...)
+		monitor.Stdout = output
+		monitor.Stderr = output
+		monitor.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+		if err := monitor.Start(); err != nil {
+			return fmt.Errorf("failed to start monitor: %w", err)
+		}
+
+		log.Info().
+			Int("pid", monitor.Process.Pid).
+			Str("log_file", logFile).
+			Msg("🩺 Monitoring vote credits post-failover in the background")
+		return monitor.Process.Release()
+	}
+}
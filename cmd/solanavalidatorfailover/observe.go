@@ -0,0 +1,36 @@
+package solanavalidatorfailover
+
+import (
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	observePeerName string
+	observeCmd      = &cobra.Command{
+		Use:          "observe",
+		Short:        "follow a failover on a passive peer read-only - for a second operator or a NOC screen",
+		SilenceUsage: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := loadConfig()
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to load config")
+			}
+
+			v, err := validator.NewFromConfig(&cfg.Validator)
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to create validator")
+			}
+
+			if err := v.Observe(observePeerName); err != nil {
+				log.Fatal().Err(err).Msg("failed to observe failover")
+			}
+		},
+	}
+)
+
+func init() {
+	observeCmd.Flags().StringVar(&observePeerName, "peer", "", "name of the passive peer in failover.peers whose failover server to observe")
+	rootCmd.AddCommand(observeCmd)
+}
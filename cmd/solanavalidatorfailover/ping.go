@@ -0,0 +1,64 @@
+package solanavalidatorfailover
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/failover"
+	"github.com/sol-strategies/solana-validator-failover/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pingPeerName     string
+	pingOutputFormat string
+	pingCmd          = &cobra.Command{
+		Use:          "ping",
+		Short:        "handshake and say hello to each peer's failover server and agent, reporting rtt, negotiated protocol, certificate and version - exits 1 when a peer has no endpoint answering",
+		SilenceUsage: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := (failover.OutputConfig{Format: pingOutputFormat}).Validate(); err != nil {
+				log.Fatal().Err(err).Msg("invalid --output")
+			}
+
+			cfg, err := loadConfig()
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to load config")
+			}
+
+			v, err := validator.NewFromConfig(&cfg.Validator)
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to create validator")
+			}
+
+			pings, err := v.PingPeers(pingPeerName)
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to ping peers")
+			}
+
+			if pingOutputFormat == failover.OutputFormatJSON {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				if err := encoder.Encode(pings); err != nil {
+					log.Fatal().Err(err).Msg("failed to write pings")
+				}
+			} else {
+				fmt.Println(pings.TableString())
+			}
+
+			if unreachable := pings.Unreachable(); len(unreachable) > 0 {
+				log.Error().Msgf("no endpoint answered on %s", strings.Join(unreachable, ", "))
+				os.Exit(1)
+			}
+		},
+	}
+)
+
+func init() {
+	pingCmd.Flags().StringVar(&pingPeerName, "peer", "", "name of the peer in <config.validator.failover.peers> to ping - default: all of them")
+	pingCmd.Flags().StringVarP(&pingOutputFormat, "output", "o", failover.OutputFormatText, "text for a table or json for a json document on stdout instead")
+	rootCmd.AddCommand(pingCmd)
+}
@@ -0,0 +1,90 @@
+package solanavalidatorfailover
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/peertrust"
+	"github.com/sol-strategies/solana-validator-failover/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pinsCmd = &cobra.Command{
+		Use:   "pins",
+		Short: "list or forget peer certificates pinned after their first successful failover",
+	}
+
+	pinsListCmd = &cobra.Command{
+		Use:          "list",
+		Short:        "list pinned peer certificate fingerprints and the identity material each peer presented in its last failover",
+		SilenceUsage: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			store := loadPeerPins()
+			for _, name := range store.Names() {
+				peer, _ := store.Get(name)
+				fingerprint := peer.Fingerprint
+				if fingerprint == "" {
+					fingerprint = "(not pinned)"
+				}
+				fmt.Printf("%s\t%s\tlast seen %s", name, fingerprint, peer.LastSeen.Format(time.RFC3339))
+				if len(peer.Sessions) > 0 {
+					last := peer.Sessions[len(peer.Sessions)-1]
+					fmt.Printf("\t%s\tactive %s\tpassive %s\tclient %s", last.Hostname, last.ActivePubkey, last.PassivePubkey, last.ClientVersion)
+				}
+				fmt.Println()
+			}
+		},
+	}
+
+	pinsForgetCmd = &cobra.Command{
+		Use:          "forget <peer-name>",
+		Short:        "forget a peer's pinned certificate so the next one it presents is trusted - run after rotating its certificate",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			store := loadPeerPins()
+			if err := store.Forget(args[0]); err != nil {
+				log.Fatal().Err(err).Msg("failed to forget peer")
+			}
+			log.Info().Str("peer", args[0]).Msg("Forgot pinned peer certificate")
+		},
+	}
+)
+
+// loadPeerPins loads the pinned peer certificates from <config.validator.failover.tls.dir>
+func loadPeerPins() *peertrust.Store {
+	dir := loadTLSDir("peer certificates are not pinned")
+
+	store, err := peertrust.Load(filepath.Join(dir, peertrust.KnownPeersFileName))
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load pinned peer certificates")
+	}
+	return store
+}
+
+// loadTLSDir loads and resolves <config.validator.failover.tls.dir> - unsetReason explains why it must be set
+func loadTLSDir(unsetReason string) string {
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load config")
+	}
+
+	if cfg.Validator.Failover.TLS.Dir == "" {
+		log.Fatal().Msgf("validator.failover.tls.dir is not set - %s", unsetReason)
+	}
+
+	dir, err := utils.ResolvePath(cfg.Validator.Failover.TLS.Dir)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid validator.failover.tls.dir")
+	}
+	return dir
+}
+
+func init() {
+	pinsCmd.AddCommand(pinsListCmd)
+	pinsCmd.AddCommand(pinsForgetCmd)
+	rootCmd.AddCommand(pinsCmd)
+}
@@ -0,0 +1,44 @@
+package solanavalidatorfailover
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	planCount int
+	planCmd   = &cobra.Command{
+		Use:          "plan",
+		Short:        "show the next gaps between the active identity's leader slots long enough to fail over in, with wall-clock times",
+		SilenceUsage: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			if planCount < 1 {
+				log.Fatal().Int("count", planCount).Msg("--count must be at least 1")
+			}
+
+			cfg, err := loadConfig()
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to load config")
+			}
+
+			v, err := validator.NewFromConfig(&cfg.Validator)
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to create validator")
+			}
+
+			plan, err := v.PlanFailoverWindows(planCount)
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to plan failover windows")
+			}
+			fmt.Println(plan.TableString())
+		},
+	}
+)
+
+func init() {
+	planCmd.Flags().IntVar(&planCount, "count", 5, "number of windows to show")
+	rootCmd.AddCommand(planCmd)
+}
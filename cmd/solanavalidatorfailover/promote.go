@@ -0,0 +1,51 @@
+package solanavalidatorfailover
+
+import (
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	promoteEmergency      bool
+	promoteNotADrill      bool
+	promoteFreshTower     bool
+	promoteNonInteractive bool
+	promoteCmd            = &cobra.Command{
+		Use:          "promote",
+		Short:        "promote this passive node when its active peer is dead and can't hand over - requires --emergency, use run to fail over gracefully",
+		SilenceUsage: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !promoteEmergency {
+				log.Fatal().Msg("promote only runs with --emergency - use run to fail over gracefully, or swap to promote without a peer")
+			}
+
+			cfg, err := loadConfig()
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to load config")
+			}
+
+			v, err := validator.NewFromConfig(&cfg.Validator)
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to create validator")
+			}
+
+			err = v.EmergencyPromote(validator.EmergencyPromoteParams{
+				NotADrill:      promoteNotADrill,
+				FreshTower:     promoteFreshTower,
+				NonInteractive: promoteNonInteractive,
+			})
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to promote")
+			}
+		},
+	}
+)
+
+func init() {
+	promoteCmd.Flags().BoolVar(&promoteEmergency, "emergency", false, "acknowledge the active node is dead - refused unless the active identity stays out of gossip for <config.validator.failover.promote.min_gossip_absence> with its vote account delinquent")
+	promoteCmd.Flags().BoolVar(&promoteNotADrill, "not-a-drill", false, "promote for real (not a drill)")
+	promoteCmd.Flags().BoolVar(&promoteFreshTower, "fresh-tower", false, "when no tower of the active identity is usable, promote without one - the validator may vote against lockouts the dead node held")
+	promoteCmd.Flags().BoolVarP(&promoteNonInteractive, "yes", "y", false, "never prompt")
+	rootCmd.AddCommand(promoteCmd)
+}
@@ -0,0 +1,35 @@
+package solanavalidatorfailover
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/failover"
+	"github.com/spf13/cobra"
+)
+
+var (
+	protocolCmd = &cobra.Command{
+		Use:   "protocol",
+		Short: "inspect the failover wire protocol",
+	}
+
+	protocolDescribeCmd = &cobra.Command{
+		Use:          "describe",
+		Short:        "print the message schema, message types and phases of this version's wire protocol as json",
+		SilenceUsage: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			description, err := json.MarshalIndent(failover.DescribeProtocol(), "", "  ")
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to encode protocol description")
+			}
+			fmt.Println(string(description))
+		},
+	}
+)
+
+func init() {
+	protocolCmd.AddCommand(protocolDescribeCmd)
+	rootCmd.AddCommand(protocolCmd)
+}
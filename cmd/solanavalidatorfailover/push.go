@@ -0,0 +1,39 @@
+package solanavalidatorfailover
+
+import (
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pushPeerName   string
+	pushRemotePath string
+	pushCmd        = &cobra.Command{
+		Use:          "push <file>",
+		Short:        "push a file to a peer's agent, e.g. to keep config or hook scripts in sync - the peer must allow its dir",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := loadConfig()
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to load config")
+			}
+
+			v, err := validator.NewFromConfig(&cfg.Validator)
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to create validator")
+			}
+
+			if err := v.PushFile(pushPeerName, args[0], pushRemotePath); err != nil {
+				log.Fatal().Err(err).Msg("failed to push file")
+			}
+		},
+	}
+)
+
+func init() {
+	pushCmd.Flags().StringVar(&pushPeerName, "peer", "", "name of the peer in failover.peers whose agent to push to")
+	pushCmd.Flags().StringVar(&pushRemotePath, "to", "", "absolute path to write the file to on the peer - defaults to the file's own path")
+	rootCmd.AddCommand(pushCmd)
+}
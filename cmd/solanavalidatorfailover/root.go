@@ -8,18 +8,25 @@ import (
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/cleanup"
 	"github.com/sol-strategies/solana-validator-failover/internal/config"
 	internalconstants "github.com/sol-strategies/solana-validator-failover/internal/constants"
+	"github.com/sol-strategies/solana-validator-failover/internal/deprecation"
 	"github.com/sol-strategies/solana-validator-failover/internal/style"
+	"github.com/sol-strategies/solana-validator-failover/internal/ui"
 	"github.com/sol-strategies/solana-validator-failover/pkg/constants"
 	"github.com/spf13/cobra"
 )
 
 var (
 	// Validator available to all commands
-	configPath string
-	logLevel   string
-	rootCmd    = &cobra.Command{
+	configPath    string
+	logLevel      string
+	logFormat     string
+	validatorName string
+	rawValues     bool
+	plainUI       bool
+	rootCmd       = &cobra.Command{
 		Aliases: []string{},
 		Use:     style.RenderPurpleString(constants.AppName),
 		Version: constants.AppVersion,
@@ -48,11 +55,25 @@ func Execute() {
 	rootCmd.PersistentFlags().StringVarP(&configPath, "config", "c", config.DefaultConfigPath, "path to config file")
 	// log level flag
 	rootCmd.PersistentFlags().StringVarP(&logLevel, "log-level", "l", "info", "log level")
+	// log format flag
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", config.DefaultLogFormat, "text for styled console logs or json for one json object per line e.g. for Loki or ELK ingestion - overrides <config.log.format>")
+	// validator flag
+	rootCmd.PersistentFlags().StringVar(&validatorName, "validator", "", "name of the validator to use from <config.validators> when more than one is declared")
+	// raw values flag
+	rootCmd.PersistentFlags().BoolVar(&rawValues, "raw-values", false, "show durations and numbers in tables unformatted e.g. 1m23.456789s and 1234567 rather than 1m 23.5s and 1,234,567")
+
+	// plain ui flag
+	rootCmd.PersistentFlags().BoolVar(&plainUI, "plain-ui", false, "print spinners as plain lines and prompt for numbers on stdin instead of drawing the terminal ui - used automatically when stdout isn't a terminal or the terminal ui fails to start")
 
 	// execute
 	if err := rootCmd.Execute(); err != nil {
 		log.Fatal().Err(err)
 	}
+
+	// count what deprecated features were used so they can be phased out
+	if counts := deprecation.Counts(); len(counts) > 0 {
+		log.Debug().Interface("deprecated_usage", counts).Msg("deprecated features used")
+	}
 }
 
 func init() {
@@ -60,7 +81,7 @@ func init() {
 }
 
 func initLog() {
-	// configure logger
+	// configure logger - switched to json in persistentPreRun when asked for
 	log.Logger = log.Output(zerolog.ConsoleWriter{
 		Out:          os.Stderr,
 		TimeLocation: time.UTC,
@@ -91,7 +112,49 @@ func initLog() {
 				return style.LogLevels[levelStr].Render(i.(string))
 			}
 		},
-	}).With().Timestamp().Logger()
+	}).With().Timestamp().Logger().Hook(cleanup.Hook{}) // clean up opened resources before fatal logs exit
+}
+
+// initJSONLog switches the logger to unstyled json lines with UTC RFC3339 nanosecond timestamps
+func initJSONLog() {
+	zerolog.TimeFieldFormat = time.RFC3339Nano
+	zerolog.TimestampFunc = func() time.Time { return time.Now().UTC() }
+	log.Logger = zerolog.New(os.Stderr).With().Timestamp().Logger().Hook(cleanup.Hook{}) // clean up opened resources before fatal logs exit
+}
+
+// resolveLogFormat returns --log-format when passed, otherwise <config.log.format> - a config that can't be loaded
+// falls back to the default and is left for the command to report
+func resolveLogFormat(cmd *cobra.Command) (string, error) {
+	format := logFormat
+	if !cmd.Flags().Changed("log-format") {
+		if configFormat, err := config.LogFormatFromFile(configPath); err == nil && configFormat != "" {
+			format = configFormat
+		}
+	}
+	if format != internalconstants.LogFormatText && format != internalconstants.LogFormatJSON {
+		return "", fmt.Errorf("invalid log format %q - must be one of: %s, %s", format, internalconstants.LogFormatText, internalconstants.LogFormatJSON)
+	}
+	return format, nil
+}
+
+// loadConfig loads the config file and selects the validator to use
+func loadConfig() (*config.SolanaValidatorFailover, error) {
+	return loadConfigWithOverrides(nil)
+}
+
+// loadConfigWithOverrides loads the config with validatorOverrides merged over the selected validator's config
+func loadConfigWithOverrides(validatorOverrides map[string]any) (*config.SolanaValidatorFailover, error) {
+	cfg, err := config.NewFromFileWithOverrides(configPath, validatorOverrides)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cfg.SelectValidator(validatorName)
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
 }
 
 // configureLogger configures the logger
@@ -103,5 +166,23 @@ func persistentPreRun(cmd *cobra.Command, args []string) (err error) {
 	}
 	zerolog.SetGlobalLevel(logLevel)
 
+	// keep styled console logs unless json was asked for
+	logFormat, err = resolveLogFormat(cmd)
+	if err != nil {
+		return err
+	}
+	if logFormat == internalconstants.LogFormatJSON {
+		initJSONLog()
+	}
+
+	// humanize table values unless asked not to
+	style.HumanizeTableValues = !rawValues
+
+	// skip the terminal ui where it can't be drawn - it also falls back by itself if it fails to start
+	ui.SetPlain(plainUI || !ui.TerminalSupportsUI())
+
+	// clean up opened resources when interrupted or terminated
+	cleanup.HandleSignals()
+
 	return nil
 }
@@ -1,8 +1,11 @@
 package solanavalidatorfailover
 
 import (
+	"time"
+
 	"github.com/rs/zerolog/log"
-	"github.com/sol-strategies/solana-validator-failover/internal/config"
+	"github.com/sol-strategies/solana-validator-failover/internal/failover"
+	"github.com/sol-strategies/solana-validator-failover/internal/runbook"
 	"github.com/sol-strategies/solana-validator-failover/internal/validator"
 	"github.com/spf13/cobra"
 )
@@ -12,26 +15,114 @@ var (
 	notADrill             bool
 	noWaitForHealthy      bool
 	noMinTimeToLeaderSlot bool
+	viaAgent              bool
+	noPostMonitor         bool
+	detachPostMonitorFlag bool
+	force                 bool
+	peerName              string
+	assumeYes             bool
+	nonInteractive        bool
+	outputFormat          string
+	outputFile            string
+	runbookPath           string
+	switchAtFlag          string
+	beforeLeaderWindow    bool
+	warmHold              bool
+	abortPending          bool
+	abortReason           string
+	releaseHeld           bool
 	runCmd                = &cobra.Command{
 		Use:          "run",
 		Short:        "run a failover - automatically detects what to do based on the node's role (active or passive)",
 		SilenceUsage: true,
 		Run: func(cmd *cobra.Command, args []string) {
-			cfg, err := config.NewFromFile(configPath)
+			output := failover.OutputConfig{Format: outputFormat, File: outputFile}
+			if err := output.Validate(); err != nil {
+				log.Fatal().Err(err).Msg("invalid --output")
+			}
+
+			var switchAt time.Time
+			if switchAtFlag != "" {
+				var err error
+				switchAt, err = time.Parse(time.RFC3339, switchAtFlag)
+				if err != nil {
+					log.Fatal().Err(err).Msg("invalid --at - must be an RFC3339 time e.g. 2025-01-02T15:04:05Z")
+				}
+			}
+
+			var rb *runbook.Runbook
+			var validatorOverrides map[string]any
+			if runbookPath != "" {
+				var err error
+				rb, err = runbook.Load(runbookPath)
+				if err != nil {
+					log.Fatal().Err(err).Msg("failed to load runbook")
+				}
+				validatorOverrides = rb.Validator
+				applyRunbookFlags(cmd, rb)
+				log.Info().
+					Str("runbook", rb.Name).
+					Str("description", rb.Description).
+					Str("path", rb.Path).
+					Str("sha256", rb.Hash).
+					Msg("Running runbook")
+			}
+
+			cfg, err := loadConfigWithOverrides(validatorOverrides)
 			if err != nil {
 				log.Fatal().Err(err).Msg("failed to load config")
 			}
+			if rb != nil {
+				if err := rb.ApplyHooks(&cfg.Validator.Failover.Hooks); err != nil {
+					log.Fatal().Err(err).Msg("invalid runbook")
+				}
+			}
 
 			v, err := validator.NewFromConfig(&cfg.Validator)
 			if err != nil {
 				log.Fatal().Err(err).Msg("failed to create validator")
 			}
 
-			err = v.Failover(validator.FailoverParams{
+			if abortPending {
+				if err := v.AbortFailover(peerName, abortReason); err != nil {
+					log.Fatal().Err(err).Msg("failed to abort failover")
+				}
+				return
+			}
+
+			if releaseHeld {
+				if err := v.ReleaseHeldFailover(peerName); err != nil {
+					log.Fatal().Err(err).Msg("failed to release held failover")
+				}
+				return
+			}
+
+			params := validator.FailoverParams{
 				NotADrill:             notADrill, // ignored when run on active node
 				NoWaitForHealthy:      noWaitForHealthy,
-				NoMinTimeToLeaderSlot: noMinTimeToLeaderSlot, // ignored when run on passive node
-			})
+				NoMinTimeToLeaderSlot: noMinTimeToLeaderSlot, // ignored when run on passive node unless via agent
+				ViaAgent:              viaAgent,              // ignored when run on active node
+				PeerName:              peerName,
+				NonInteractive:        assumeYes || nonInteractive,
+				ReloadPeers: func() (validator.PeersConfig, error) {
+					reloadedCfg, err := loadConfigWithOverrides(validatorOverrides)
+					if err != nil {
+						return nil, err
+					}
+					return reloadedCfg.Validator.Failover.Peers, nil
+				},
+				NoPostMonitor:          noPostMonitor, // ignored when run on active node
+				Output:                 output,
+				Force:                  force,
+				SwitchAt:               switchAt,
+				BeforeNextLeaderWindow: beforeLeaderWindow,
+				WarmHold:               warmHold, // ignored when run on passive node
+			}
+			if detachPostMonitorFlag {
+				params.DetachPostMonitor = detachPostMonitor(v.Monitor.DetachedLogFile, notADrill) // ignored when run on active node
+			}
+
+			err = v.Failover(params)
 			if err != nil {
 				log.Fatal().Err(err).Msg("failed to failover")
 			}
@@ -39,9 +130,48 @@ var (
 	}
 )
 
+// applyRunbookFlags sets the run flags from the runbook - flags passed on the command line take precedence
+func applyRunbookFlags(cmd *cobra.Command, rb *runbook.Runbook) {
+	flags := cmd.Flags()
+	if !flags.Changed("peer") && rb.Peer != "" {
+		peerName = rb.Peer
+	}
+	if !flags.Changed("not-a-drill") {
+		notADrill = rb.NotADrill
+	}
+	if !flags.Changed("no-wait-for-healthy") {
+		noWaitForHealthy = rb.NoWaitForHealthy
+	}
+	if !flags.Changed("no-min-time-to-leader-slot") {
+		noMinTimeToLeaderSlot = rb.NoMinTimeToLeaderSlot
+	}
+	if !flags.Changed("yes") && !flags.Changed("non-interactive") {
+		nonInteractive = rb.IsNonInteractive()
+	}
+}
+
 func init() {
 	runCmd.Flags().BoolVar(&notADrill, "not-a-drill", false, "execute failover for real (not a drill)")
 	runCmd.Flags().BoolVar(&noWaitForHealthy, "no-wait-for-healthy", false, "don't wait for node to report being healthy by calling <config.validator.rpc_address>/health")
 	runCmd.Flags().BoolVar(&noMinTimeToLeaderSlot, "no-min-time-to-leader-slot", false, "when run on an active node, don't wait until it has no leader slots in the next <config.validator.min_time_to_leader_slot> (default: 5m) - ignored when run on a passive node")
+	runCmd.Flags().BoolVar(&viaAgent, "via-agent", false, "when run on a passive node, ask the agent on the active peer to hand over instead of running this program there - ignored when run on an active node")
+	runCmd.Flags().BoolVar(&noPostMonitor, "no-post-monitor", false, "when run on a passive node, skip vote credit monitoring after the failover - ignored when run on an active node")
+	runCmd.Flags().BoolVar(&detachPostMonitorFlag, "detach-post-monitor", false, "when run on a passive node, monitor vote credits after the failover in the background and exit - output goes to <config.validator.failover.monitor.detached_log_file>, ignored when run on an active node")
+	runCmd.MarkFlagsMutuallyExclusive("no-post-monitor", "detach-post-monitor")
+	runCmd.Flags().BoolVar(&force, "force", false, "fail over even within <config.validator.failover.epoch_boundary.window_slots> of an epoch boundary when its action is refuse and, when run on a passive node, when the active identity is already delinquent")
+	runCmd.Flags().StringVar(&switchAtFlag, "at", "", "schedule the switch for this RFC3339 time - both nodes connect, check and confirm straight away then wait for it, on either node")
+	runCmd.Flags().BoolVar(&beforeLeaderWindow, "before-next-leader-window", false, "schedule the switch so the failover completes just before the active identity's next leader window - see the plan command")
+	runCmd.Flags().BoolVar(&warmHold, "hold", false, "when run on an active node, connect, check and confirm straight away then hold the session open with heartbeats until enter is pressed, the process is sent SIGUSR1 or --release is run - the switch then starts without connecting or checking again, ignored when run on a passive node")
+	runCmd.MarkFlagsMutuallyExclusive("at", "before-next-leader-window", "hold")
+	runCmd.Flags().BoolVar(&releaseHeld, "release", false, "release the failover the active node holds with --hold through the passive peer - the switch then starts as though enter was pressed on the active node")
+	runCmd.MarkFlagsMutuallyExclusive("abort", "release")
+	runCmd.Flags().BoolVar(&abortPending, "abort", false, "abort the failover pending on the passive peer - it and the active node stop without changing anything, as long as the active node hasn't begun switching identity")
+	runCmd.Flags().StringVar(&abortReason, "abort-reason", "aborted by operator", "why the failover is aborted with --abort - shown on both nodes and in the abort notification")
+	runCmd.Flags().StringVar(&peerName, "peer", "", "name of the peer in <config.validator.failover.peers> to failover with - skips the selection prompt")
+	runCmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "never prompt - same as --non-interactive")
+	runCmd.Flags().BoolVar(&nonInteractive, "non-interactive", false, "never prompt, for running from scripts, cron or orchestration - with several peers configured pass --peer <name> (or enable <config.validator.failover.peer_selection.auto_select>) or the run errors instead of prompting")
+	runCmd.Flags().StringVarP(&outputFormat, "output", "o", failover.OutputFormatText, "how to report the failover result - text for styled tables or json for a json document on stdout instead")
+	runCmd.Flags().StringVar(&outputFile, "output-file", "", "write the --output json document to this file instead of stdout")
+	runCmd.Flags().StringVar(&runbookPath, "runbook", "", "yaml file describing this failover - its peer, whether it is for real, confirmation, hooks and validator config overrides - flags passed alongside it take precedence")
 	rootCmd.AddCommand(runCmd)
 }
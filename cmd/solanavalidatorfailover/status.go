@@ -0,0 +1,38 @@
+package solanavalidatorfailover
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statusCmd = &cobra.Command{
+		Use:          "status",
+		Short:        "show this node's role, gossip state and tower file, and whether each peer's failover server is reachable",
+		SilenceUsage: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := loadConfig()
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to load config")
+			}
+
+			v, err := validator.NewFromConfig(&cfg.Validator)
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to create validator")
+			}
+
+			status, err := v.Status()
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to get status")
+			}
+			fmt.Println(status.TableString())
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+}
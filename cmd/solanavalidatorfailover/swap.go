@@ -0,0 +1,48 @@
+package solanavalidatorfailover
+
+import (
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	swapNotADrill             bool
+	swapNoWaitForHealthy      bool
+	swapNoMinTimeToLeaderSlot bool
+	swapFromTowerReplica      bool
+	swapCmd                   = &cobra.Command{
+		Use:          "swap",
+		Short:        "swap this node between its active and passive identities without a peer - e.g. to demote it before maintenance when the standby is handled separately",
+		SilenceUsage: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := loadConfig()
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to load config")
+			}
+
+			v, err := validator.NewFromConfig(&cfg.Validator)
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to create validator")
+			}
+
+			err = v.SwapIdentity(validator.SwapParams{
+				NotADrill:             swapNotADrill,
+				NoWaitForHealthy:      swapNoWaitForHealthy,
+				NoMinTimeToLeaderSlot: swapNoMinTimeToLeaderSlot, // ignored when promoting to active
+				FromTowerReplica:      swapFromTowerReplica,      // ignored when demoting to passive
+			})
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to swap identity")
+			}
+		},
+	}
+)
+
+func init() {
+	swapCmd.Flags().BoolVar(&swapNotADrill, "not-a-drill", false, "swap identity for real (not a drill)")
+	swapCmd.Flags().BoolVar(&swapNoWaitForHealthy, "no-wait-for-healthy", false, "don't wait for node to report being healthy by calling <config.validator.rpc_address>/health")
+	swapCmd.Flags().BoolVar(&swapNoMinTimeToLeaderSlot, "no-min-time-to-leader-slot", false, "when demoting an active node, don't refuse when it has leader slots in the next <config.validator.min_time_to_leader_slot> (default: 5m) - ignored when promoting")
+	swapCmd.Flags().BoolVar(&swapFromTowerReplica, "from-tower-replica", false, "when promoting a passive node without a tower file, install the last tower replica its active peer shipped with <config.validator.tower.replication> - ignored when demoting")
+	rootCmd.AddCommand(swapCmd)
+}
@@ -0,0 +1,40 @@
+package solanavalidatorfailover
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/config"
+	"github.com/sol-strategies/solana-validator-failover/internal/style"
+	"github.com/spf13/cobra"
+)
+
+var (
+	validateConfigCmd = &cobra.Command{
+		Use:          "validate-config",
+		Short:        "check the config file for unknown keys and invalid values and list every problem found - nothing on this host or the network is checked",
+		SilenceUsage: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			problems, err := config.ValidateFile(configPath)
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to read config")
+			}
+
+			if len(problems) == 0 {
+				log.Info().Str("config", configPath).Msg("Config is valid")
+				return
+			}
+
+			for _, problem := range problems {
+				fmt.Println(style.ColorError.Render("✗ " + problem))
+			}
+			fmt.Printf("%d problem(s) found in %s\n", len(problems), configPath)
+			os.Exit(1)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(validateConfigCmd)
+}
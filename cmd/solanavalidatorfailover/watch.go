@@ -0,0 +1,35 @@
+package solanavalidatorfailover
+
+import (
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchCmd = &cobra.Command{
+		Use:          "watch",
+		Short:        "run on the passive node to fail over unattended when the active identity fails the conditions in <config.validator.failover.watch> - requires the agent on the active node",
+		SilenceUsage: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := loadConfig()
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to load config")
+			}
+
+			v, err := validator.NewFromConfig(&cfg.Validator)
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to create validator")
+			}
+
+			err = v.RunWatch()
+			if err != nil {
+				log.Fatal().Err(err).Msg("watch stopped")
+			}
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+}
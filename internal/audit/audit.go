@@ -0,0 +1,213 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// FileName is the name of the failover audit log in the state dir
+	FileName = "failovers.jsonl"
+
+	// maxLineSize is the longest record line read back - records are a few kilobytes
+	maxLineSize = 1024 * 1024
+)
+
+// Node is a node taking part in a failover as it appears in a record
+type Node struct {
+	Hostname string `json:"hostname"`
+	PublicIP string `json:"public_ip"`
+	Pubkey   string `json:"pubkey"`
+}
+
+// Record is a single failover as seen by the node that wrote it - both nodes write one when both keep an audit log
+type Record struct {
+	// Time is when the record was written
+	Time       time.Time `json:"time"`
+	FailoverID string    `json:"failover_id"`
+	// Hostname is the node that wrote the record and Role the role it held before the failover
+	Hostname     string `json:"hostname"`
+	Role         string `json:"role"`
+	IsDryRun     bool   `json:"is_dry_run"`
+	Success      bool   `json:"success"`
+	ErrorMessage string `json:"error_message,omitempty"`
+	Summary      string `json:"summary,omitempty"`
+	// ActiveNode and PassiveNode are the nodes holding each role before the failover
+	ActiveNode      Node      `json:"active_node"`
+	PassiveNode     Node      `json:"passive_node"`
+	SwitchAt        time.Time `json:"switch_at,omitzero"`
+	StartSlot       uint64    `json:"start_slot"`
+	EndSlot         uint64    `json:"end_slot"`
+	Slots           uint64    `json:"slots"`
+	DurationMs      int64     `json:"duration_ms"`
+	TotalDurationMs int64     `json:"total_duration_with_phases_ms"`
+	IdentityGapMs   int64     `json:"identity_gap_ms"`
+	TowerFileBytes  int       `json:"tower_file_bytes"`
+	TowerFileHash   string    `json:"tower_file_hash"`
+	// Peer is the name the writing node has the other node configured as in failover.peers - empty when it isn't one
+	Peer string `json:"peer,omitempty"`
+	// Phases are the timed phases up to the failover completing - compared against earlier failovers by Regressions
+	Phases []Phase `json:"phases,omitempty"`
+}
+
+// Log is an append-only failover audit log of json lines - a nil log records nothing
+type Log struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewLog returns the audit log in dir - the dir is created on the first append
+func NewLog(dir string) *Log {
+	return &Log{path: filepath.Join(dir, FileName)}
+}
+
+// Path returns the audit log file path
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append writes a record as a line at the end of the log and syncs it to disk
+func (l *Log) Append(record Record) error {
+	if l == nil {
+		return nil
+	}
+	if record.Time.IsZero() {
+		record.Time = time.Now().UTC()
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	line = append(line, '\n')
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0700); err != nil {
+		return fmt.Errorf("failed to create audit log dir: %w", err)
+	}
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log %s: %w", l.path, err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(line); err != nil {
+		return fmt.Errorf("failed to write audit log %s: %w", l.path, err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync audit log %s: %w", l.path, err)
+	}
+	return nil
+}
+
+// Read returns every record in the log oldest first - a missing log has none, lines that can't be parsed are
+// returned as an error alongside the records that could
+func (l *Log) Read() (records []Record, err error) {
+	file, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log %s: %w", l.path, err)
+	}
+	defer file.Close()
+
+	var badLines []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			badLines = append(badLines, fmt.Sprint(lineNumber))
+			continue
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return records, fmt.Errorf("failed to read audit log %s: %w", l.path, err)
+	}
+	if len(badLines) > 0 {
+		return records, fmt.Errorf("failed to parse audit log %s lines %s", l.path, strings.Join(badLines, ", "))
+	}
+	return records, nil
+}
+
+// Filter selects records - zero values match everything
+type Filter struct {
+	// Since only matches records written at or after it
+	Since time.Time
+	// FailoverID matches records whose failover id starts with it
+	FailoverID string
+	// Node matches records where either node's hostname, public ip or pubkey equals it
+	Node string
+	// DryRun and Real only match drills or real failovers - both or neither match either
+	DryRun bool
+	Real   bool
+	// Failed only matches failovers that didn't complete
+	Failed bool
+}
+
+// Matches returns true if the record matches every set criterion
+func (f Filter) Matches(record Record) bool {
+	if !f.Since.IsZero() && record.Time.Before(f.Since) {
+		return false
+	}
+	if f.FailoverID != "" && !strings.HasPrefix(record.FailoverID, f.FailoverID) {
+		return false
+	}
+	if f.Node != "" && !record.ActiveNode.is(f.Node) && !record.PassiveNode.is(f.Node) {
+		return false
+	}
+	if f.DryRun != f.Real && record.IsDryRun != f.DryRun {
+		return false
+	}
+	if f.Failed && record.Success {
+		return false
+	}
+	return true
+}
+
+// is returns true if the node's hostname, public ip or pubkey equals value
+func (n Node) is(value string) bool {
+	return value == n.Hostname || value == n.PublicIP || value == n.Pubkey
+}
+
+// Select returns the records matching the filter, newest first, up to limit - zero is no limit
+func Select(records []Record, filter Filter, limit int) []Record {
+	selected := make([]Record, 0, len(records))
+	for i := len(records) - 1; i >= 0; i-- {
+		if !filter.Matches(records[i]) {
+			continue
+		}
+		selected = append(selected, records[i])
+		if limit > 0 && len(selected) == limit {
+			break
+		}
+	}
+	return selected
+}
+
+// LastRealFailover returns the most recent real failover that completed - false when there's none
+func LastRealFailover(records []Record) (Record, bool) {
+	for i := len(records) - 1; i >= 0; i-- {
+		if records[i].Success && !records[i].IsDryRun {
+			return records[i], true
+		}
+	}
+	return Record{}, false
+}
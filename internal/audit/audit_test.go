@@ -0,0 +1,142 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRecord(failoverID string, at time.Time, isDryRun, success bool) Record {
+	return Record{
+		Time:        at,
+		FailoverID:  failoverID,
+		Hostname:    "node-a",
+		Role:        "active",
+		IsDryRun:    isDryRun,
+		Success:     success,
+		ActiveNode:  Node{Hostname: "node-a", PublicIP: "10.0.0.1", Pubkey: "PubkeyA"},
+		PassiveNode: Node{Hostname: "node-b", PublicIP: "10.0.0.2", Pubkey: "PubkeyB"},
+	}
+}
+
+func TestAppendAndRead(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "state")
+	log := NewLog(dir)
+	assert.Equal(t, filepath.Join(dir, FileName), log.Path())
+
+	records, err := log.Read()
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	first := testRecord("first", time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC), true, true)
+	first.TowerFileHash = "abc123"
+	first.SwitchAt = time.Date(2025, 6, 1, 10, 0, 3, 0, time.UTC)
+	require.NoError(t, log.Append(first))
+	second := testRecord("second", time.Time{}, false, false)
+	second.ErrorMessage = "aborted"
+	require.NoError(t, log.Append(second))
+
+	records, err = log.Read()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, first, records[0])
+	assert.Equal(t, "second", records[1].FailoverID)
+	assert.Equal(t, "aborted", records[1].ErrorMessage)
+	assert.False(t, records[1].Time.IsZero(), "append stamps records without a time")
+
+	info, err := os.Stat(log.Path())
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestAppend_NilLog(t *testing.T) {
+	var log *Log
+	assert.NoError(t, log.Append(testRecord("id", time.Now(), true, true)))
+}
+
+func TestRead_BadLines(t *testing.T) {
+	log := NewLog(t.TempDir())
+	require.NoError(t, log.Append(testRecord("good", time.Now(), true, true)))
+
+	file, err := os.OpenFile(log.Path(), os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = file.WriteString("{not json\n\n")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	records, err := log.Read()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "lines 2")
+	require.Len(t, records, 1)
+	assert.Equal(t, "good", records[0].FailoverID)
+}
+
+func TestFilter_Matches(t *testing.T) {
+	at := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	drill := testRecord("abcd-1234", at, true, true)
+	failed := testRecord("ef01-5678", at, false, false)
+
+	assert.True(t, Filter{}.Matches(drill))
+
+	assert.True(t, Filter{Since: at}.Matches(drill))
+	assert.False(t, Filter{Since: at.Add(time.Second)}.Matches(drill))
+
+	assert.True(t, Filter{FailoverID: "abcd"}.Matches(drill))
+	assert.False(t, Filter{FailoverID: "1234"}.Matches(drill))
+
+	assert.True(t, Filter{Node: "node-b"}.Matches(drill))
+	assert.True(t, Filter{Node: "10.0.0.1"}.Matches(drill))
+	assert.True(t, Filter{Node: "PubkeyB"}.Matches(drill))
+	assert.False(t, Filter{Node: "node-c"}.Matches(drill))
+
+	assert.True(t, Filter{DryRun: true}.Matches(drill))
+	assert.False(t, Filter{DryRun: true}.Matches(failed))
+	assert.True(t, Filter{Real: true}.Matches(failed))
+	assert.False(t, Filter{Real: true}.Matches(drill))
+	assert.True(t, Filter{DryRun: true, Real: true}.Matches(drill))
+
+	assert.True(t, Filter{Failed: true}.Matches(failed))
+	assert.False(t, Filter{Failed: true}.Matches(drill))
+}
+
+func TestSelect_NewestFirstWithLimit(t *testing.T) {
+	at := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	records := []Record{
+		testRecord("1", at, true, true),
+		testRecord("2", at.Add(time.Hour), false, true),
+		testRecord("3", at.Add(2*time.Hour), true, true),
+		testRecord("4", at.Add(3*time.Hour), true, false),
+	}
+
+	ids := func(records []Record) (ids []string) {
+		for _, record := range records {
+			ids = append(ids, record.FailoverID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"4", "3", "2", "1"}, ids(Select(records, Filter{}, 0)))
+	assert.Equal(t, []string{"4", "3"}, ids(Select(records, Filter{}, 2)))
+	assert.Equal(t, []string{"4", "3", "1"}, ids(Select(records, Filter{DryRun: true}, 0)))
+	assert.Empty(t, Select(records, Filter{FailoverID: "5"}, 0))
+}
+
+func TestLastRealFailover(t *testing.T) {
+	at := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	records := []Record{
+		testRecord("real", at, false, true),
+		testRecord("drill", at.Add(time.Hour), true, true),
+		testRecord("failed", at.Add(2*time.Hour), false, false),
+	}
+
+	record, ok := LastRealFailover(records)
+	require.True(t, ok)
+	assert.Equal(t, "real", record.FailoverID)
+
+	_, ok = LastRealFailover(records[1:])
+	assert.False(t, ok)
+}
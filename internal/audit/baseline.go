@@ -0,0 +1,107 @@
+package audit
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Phase is a timed phase of a failover as it appears in a record
+type Phase struct {
+	Name string `json:"name"`
+	// Role is the role the node timing the phase held before the failover
+	Role       string `json:"role"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// BaselineConfig is how a failover's phase durations are compared against the failovers before it
+type BaselineConfig struct {
+	// Failovers is how many of the latest failovers of the same kind the median is taken over
+	Failovers int
+	// MinFailovers is how many of them must have timed a phase for it to be compared
+	MinFailovers int
+	// ThresholdPercent is how much slower than the median a phase must be to regress
+	ThresholdPercent float64
+	// MinIncrease is how much slower than the median a phase must also be - keeps fast phases from regressing on noise
+	MinIncrease time.Duration
+}
+
+// Regression is a phase that took longer than the baseline allows
+type Regression struct {
+	Phase           string  `json:"phase"`
+	Role            string  `json:"role"`
+	DurationMs      int64   `json:"duration_ms"`
+	MedianMs        int64   `json:"median_ms"`
+	IncreasePercent float64 `json:"increase_percent"`
+}
+
+// String returns the regression as a short human readable line
+func (r Regression) String() string {
+	return fmt.Sprintf("%s (%s) took %s vs median %s (+%.0f%%)",
+		r.Phase,
+		r.Role,
+		time.Duration(r.DurationMs)*time.Millisecond,
+		time.Duration(r.MedianMs)*time.Millisecond,
+		r.IncreasePercent,
+	)
+}
+
+// Regressions compares each phase of record with its median over the latest successful failovers of the same kind
+// (drill or real) in records and returns those slower than cfg allows - in the order the record timed them
+func Regressions(records []Record, record Record, cfg BaselineConfig) (regressions []Regression) {
+	baseline := make([]Record, 0, cfg.Failovers)
+	for i := len(records) - 1; i >= 0 && len(baseline) < cfg.Failovers; i-- {
+		previous := records[i]
+		if !previous.Success || previous.IsDryRun != record.IsDryRun || len(previous.Phases) == 0 {
+			continue
+		}
+		if previous.FailoverID == record.FailoverID {
+			continue
+		}
+		baseline = append(baseline, previous)
+	}
+
+	for _, phase := range record.Phases {
+		durations := make([]int64, 0, len(baseline))
+		for _, previous := range baseline {
+			for _, previousPhase := range previous.Phases {
+				if previousPhase.Name == phase.Name && previousPhase.Role == phase.Role {
+					durations = append(durations, previousPhase.DurationMs)
+					break
+				}
+			}
+		}
+		if len(durations) == 0 || len(durations) < cfg.MinFailovers {
+			continue
+		}
+
+		medianMs := median(durations)
+		if medianMs <= 0 {
+			continue
+		}
+		increaseMs := phase.DurationMs - medianMs
+		increasePercent := float64(increaseMs) / float64(medianMs) * 100
+		if increasePercent <= cfg.ThresholdPercent || time.Duration(increaseMs)*time.Millisecond < cfg.MinIncrease {
+			continue
+		}
+		regressions = append(regressions, Regression{
+			Phase:           phase.Name,
+			Role:            phase.Role,
+			DurationMs:      phase.DurationMs,
+			MedianMs:        medianMs,
+			IncreasePercent: increasePercent,
+		})
+	}
+	return regressions
+}
+
+// median returns the median of values - the mean of the middle two for an even count
+func median(values []int64) int64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
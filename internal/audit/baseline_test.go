@@ -0,0 +1,69 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPhasedRecord(failoverID string, isDryRun, success bool, preHooksMs, towerSyncMs int64) Record {
+	record := testRecord(failoverID, time.Now(), isDryRun, success)
+	record.Phases = []Phase{
+		{Name: "pre hooks", Role: "passive", DurationMs: preHooksMs},
+		{Name: "tower file sync", Role: "active", DurationMs: towerSyncMs},
+	}
+	return record
+}
+
+func testBaselineConfig() BaselineConfig {
+	return BaselineConfig{Failovers: 5, MinFailovers: 3, ThresholdPercent: 50, MinIncrease: 100 * time.Millisecond}
+}
+
+func TestRegressions(t *testing.T) {
+	records := []Record{
+		testPhasedRecord("old", false, true, 9000, 9000), // outside the latest 5
+		testPhasedRecord("a", false, true, 1000, 40),
+		testPhasedRecord("b", false, true, 1200, 50),
+		testPhasedRecord("failed", false, false, 9000, 9000),
+		testPhasedRecord("drill", true, true, 9000, 9000),
+		testPhasedRecord("c", false, true, 800, 60),
+		testPhasedRecord("d", false, true, 1100, 40),
+		testPhasedRecord("e", false, true, 900, 50),
+	}
+	current := testPhasedRecord("current", false, true, 2000, 90)
+	records = append(records, current)
+
+	regressions := Regressions(records, current, testBaselineConfig())
+	require.Len(t, regressions, 1, "tower file sync is +80%% but under the minimum increase")
+	assert.Equal(t, "pre hooks", regressions[0].Phase)
+	assert.Equal(t, "passive", regressions[0].Role)
+	assert.Equal(t, int64(2000), regressions[0].DurationMs)
+	assert.Equal(t, int64(1000), regressions[0].MedianMs)
+	assert.InDelta(t, 100, regressions[0].IncreasePercent, 0.01)
+	assert.Equal(t, "pre hooks (passive) took 2s vs median 1s (+100%)", regressions[0].String())
+}
+
+func TestRegressions_WithinThreshold(t *testing.T) {
+	records := []Record{
+		testPhasedRecord("a", false, true, 1000, 40),
+		testPhasedRecord("b", false, true, 1000, 40),
+		testPhasedRecord("c", false, true, 1000, 40),
+	}
+	assert.Empty(t, Regressions(records, testPhasedRecord("current", false, true, 1500, 40), testBaselineConfig()))
+}
+
+func TestRegressions_TooFewFailovers(t *testing.T) {
+	records := []Record{
+		testPhasedRecord("a", true, true, 1000, 40),
+		testPhasedRecord("b", true, true, 1000, 40),
+		testRecord("no-phases", time.Now(), true, true),
+	}
+	assert.Empty(t, Regressions(records, testPhasedRecord("current", true, true, 5000, 40), testBaselineConfig()))
+}
+
+func TestMedian(t *testing.T) {
+	assert.Equal(t, int64(3), median([]int64{5, 1, 3}))
+	assert.Equal(t, int64(25), median([]int64{40, 10, 20, 30}))
+}
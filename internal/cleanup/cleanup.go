@@ -0,0 +1,133 @@
+package cleanup
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// Registry tracks opened resources (files, listeners, connections, temp artifacts) so they are torn down in
+// reverse order on any exit path - normal return, fatal log or signal
+type Registry struct {
+	mu      sync.Mutex
+	nextID  int
+	entries []*entry
+}
+
+// entry is a registered resource cleanup
+type entry struct {
+	id   int
+	name string
+	once sync.Once
+	fn   func() error
+	err  error
+}
+
+// defaultRegistry is the process-wide registry used by the package level functions
+var defaultRegistry = &Registry{}
+
+// NewRegistry creates a new empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register registers a cleanup function for a named resource and returns a release function that runs it now
+// and removes it from the registry - release is safe to call more than once, later calls return the first error
+func (r *Registry) Register(name string, fn func() error) (release func() error) {
+	r.mu.Lock()
+	r.nextID++
+	e := &entry{id: r.nextID, name: name, fn: fn}
+	r.entries = append(r.entries, e)
+	r.mu.Unlock()
+
+	log.Debug().Str("resource", name).Msg("registered resource for cleanup")
+
+	return func() error {
+		r.remove(e.id)
+		return e.run()
+	}
+}
+
+// Run runs every registered cleanup in reverse registration order and empties the registry
+func (r *Registry) Run() {
+	r.mu.Lock()
+	entries := r.entries
+	r.entries = nil
+	r.mu.Unlock()
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		if err := entries[i].run(); err != nil {
+			log.Debug().Err(err).Str("resource", entries[i].name).Msg("failed to clean up resource")
+			continue
+		}
+		log.Debug().Str("resource", entries[i].name).Msg("cleaned up resource")
+	}
+}
+
+// Len returns the number of registered resources not yet cleaned up
+func (r *Registry) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.entries)
+}
+
+// remove removes an entry from the registry
+func (r *Registry) remove(id int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, e := range r.entries {
+		if e.id == id {
+			r.entries = append(r.entries[:i], r.entries[i+1:]...)
+			return
+		}
+	}
+}
+
+// run runs the entry's cleanup function once
+func (e *entry) run() error {
+	e.once.Do(func() {
+		e.err = e.fn()
+	})
+	return e.err
+}
+
+// Register registers a cleanup function with the default registry - see Registry.Register
+func Register(name string, fn func() error) (release func() error) {
+	return defaultRegistry.Register(name, fn)
+}
+
+// Run runs every cleanup registered with the default registry - call it before exiting outside of a fatal log
+func Run() {
+	defaultRegistry.Run()
+}
+
+// Exit runs every cleanup registered with the default registry then exits with code
+func Exit(code int) {
+	defaultRegistry.Run()
+	os.Exit(code)
+}
+
+// Hook is a zerolog hook that runs the default registry before a fatal or panic log exits the process
+type Hook struct{}
+
+// Run implements zerolog.Hook
+func (Hook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	if level == zerolog.FatalLevel || level == zerolog.PanicLevel {
+		defaultRegistry.Run()
+	}
+}
+
+// HandleSignals runs the default registry and exits when the process is interrupted or terminated
+func HandleSignals() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		log.Warn().Str("signal", sig.String()).Msg("received signal - cleaning up and exiting")
+		Exit(1)
+	}()
+}
@@ -0,0 +1,52 @@
+package cleanup
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_RunsInReverseOrder(t *testing.T) {
+	r := NewRegistry()
+	order := []string{}
+
+	r.Register("first", func() error { order = append(order, "first"); return nil })
+	r.Register("second", func() error { order = append(order, "second"); return nil })
+	r.Register("third", func() error { order = append(order, "third"); return nil })
+	assert.Equal(t, 3, r.Len())
+
+	r.Run()
+
+	assert.Equal(t, []string{"third", "second", "first"}, order)
+	assert.Equal(t, 0, r.Len())
+}
+
+func TestRegistry_ReleaseRunsOnceAndRemoves(t *testing.T) {
+	r := NewRegistry()
+	calls := 0
+
+	release := r.Register("file", func() error { calls++; return errors.New("close failed") })
+
+	err := release()
+	assert.EqualError(t, err, "close failed")
+	assert.Equal(t, 0, r.Len())
+
+	// releasing again or running the registry doesn't run it twice
+	err = release()
+	assert.EqualError(t, err, "close failed")
+	r.Run()
+	assert.Equal(t, 1, calls)
+}
+
+func TestRegistry_RunContinuesPastErrors(t *testing.T) {
+	r := NewRegistry()
+	ran := false
+
+	r.Register("ok", func() error { ran = true; return nil })
+	r.Register("broken", func() error { return errors.New("broken") })
+
+	r.Run()
+
+	assert.True(t, ran)
+}
@@ -0,0 +1,133 @@
+package clock
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SourceNTP queries an ntp server directly for the local clock offset
+	SourceNTP = "ntp"
+	// SourceChronyc reads the local clock offset chronyd reports in chronyc tracking
+	SourceChronyc = "chronyc"
+
+	// ActionWarn logs a warning and carries on when the offset exceeds the maximum
+	ActionWarn = "warn"
+	// ActionRefuse refuses to carry on when the offset exceeds the maximum
+	ActionRefuse = "refuse"
+)
+
+const (
+	ntpPacketSize = 48
+	// ntpEpochOffset is the number of seconds between the ntp epoch (1900) and the unix epoch (1970)
+	ntpEpochOffset = 2208988800
+	// ntpClientRequestHeader is leap indicator 0, version 4, mode 3 (client)
+	ntpClientRequestHeader = 0x23
+	ntpModeServer          = 4
+	ntpLeapNotSynchronized = 3
+)
+
+// NTPOffset queries the ntp server at address (host:port) and returns how far the local clock is ahead of it -
+// negative when the local clock is behind
+func NTPOffset(address string, timeout time.Duration) (time.Duration, error) {
+	conn, err := net.DialTimeout("udp", address, timeout)
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to ntp server %s: %w", address, err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return 0, err
+	}
+
+	request := make([]byte, ntpPacketSize)
+	request[0] = ntpClientRequestHeader
+	sentAt := time.Now()
+	binary.BigEndian.PutUint64(request[40:], toNTPTime(sentAt))
+
+	if _, err := conn.Write(request); err != nil {
+		return 0, fmt.Errorf("failed to query ntp server %s: %w", address, err)
+	}
+
+	response := make([]byte, ntpPacketSize)
+	n, err := conn.Read(response)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read ntp response from %s: %w", address, err)
+	}
+	receivedAt := time.Now()
+
+	if n < ntpPacketSize {
+		return 0, fmt.Errorf("short ntp response from %s: %d bytes", address, n)
+	}
+	if mode := response[0] & 0x07; mode != ntpModeServer {
+		return 0, fmt.Errorf("unexpected ntp response mode %d from %s", mode, address)
+	}
+	if leap := response[0] >> 6; leap == ntpLeapNotSynchronized {
+		return 0, fmt.Errorf("ntp server %s is not synchronized", address)
+	}
+	// stratum 0 is a kiss-o'-death packet telling the client to back off
+	if stratum := response[1]; stratum == 0 {
+		return 0, fmt.Errorf("ntp server %s refused the query (kiss code %q)", address, string(response[12:16]))
+	}
+	if originate := binary.BigEndian.Uint64(response[24:]); originate != binary.BigEndian.Uint64(request[40:]) {
+		return 0, fmt.Errorf("ntp response from %s does not match the request", address)
+	}
+
+	serverReceivedAt := fromNTPTime(binary.BigEndian.Uint64(response[32:]))
+	serverSentAt := fromNTPTime(binary.BigEndian.Uint64(response[40:]))
+
+	// the server's clock minus ours is ((t2 - t1) + (t3 - t4)) / 2 - the local offset is the opposite of that
+	serverAhead := (serverReceivedAt.Sub(sentAt) + serverSentAt.Sub(receivedAt)) / 2
+	return -serverAhead, nil
+}
+
+// ChronycOffset returns how far the local clock is ahead of true time as reported by chronyc tracking - negative when
+// the local clock is behind
+func ChronycOffset(timeout time.Duration) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "chronyc", "-c", "tracking").Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to run chronyc tracking: %w", err)
+	}
+
+	return parseChronycTracking(string(output))
+}
+
+// parseChronycTracking parses the csv output of chronyc -c tracking - its fifth field is the system time offset in
+// seconds, positive when the local clock is fast
+func parseChronycTracking(output string) (time.Duration, error) {
+	fields := strings.Split(strings.TrimSpace(output), ",")
+	if len(fields) < 5 {
+		return 0, fmt.Errorf("unexpected chronyc tracking output: %q", output)
+	}
+
+	seconds, err := strconv.ParseFloat(fields[4], 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse chronyc system time offset %q: %w", fields[4], err)
+	}
+
+	return time.Duration(math.Round(seconds * float64(time.Second))), nil
+}
+
+// toNTPTime converts t to a 64-bit ntp timestamp - 32 bits of seconds since 1900 and 32 bits of fraction
+func toNTPTime(t time.Time) uint64 {
+	seconds := uint64(t.Unix() + ntpEpochOffset)
+	fraction := (uint64(t.Nanosecond()) << 32) / uint64(time.Second)
+	return seconds<<32 | fraction
+}
+
+// fromNTPTime converts a 64-bit ntp timestamp to a time
+func fromNTPTime(ntpTime uint64) time.Time {
+	seconds := int64(ntpTime>>32) - ntpEpochOffset
+	nanoseconds := int64(((ntpTime & 0xffffffff) * uint64(time.Second)) >> 32)
+	return time.Unix(seconds, nanoseconds)
+}
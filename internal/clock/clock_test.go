@@ -0,0 +1,115 @@
+package clock
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startFakeNTPServer answers ntp queries with its clock skewed by serverAhead - respond can modify the response
+func startFakeNTPServer(t *testing.T, serverAhead time.Duration, respond func(response []byte)) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		request := make([]byte, ntpPacketSize)
+		for {
+			n, addr, err := conn.ReadFrom(request)
+			if err != nil {
+				return
+			}
+			if n < ntpPacketSize {
+				continue
+			}
+
+			response := make([]byte, ntpPacketSize)
+			response[0] = 0x24 // leap indicator 0, version 4, mode 4 (server)
+			response[1] = 2    // stratum
+			copy(response[24:32], request[40:48])
+			binary.BigEndian.PutUint64(response[32:], toNTPTime(time.Now().Add(serverAhead)))
+			binary.BigEndian.PutUint64(response[40:], toNTPTime(time.Now().Add(serverAhead)))
+			if respond != nil {
+				respond(response)
+			}
+			_, _ = conn.WriteTo(response, addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func TestNTPOffset(t *testing.T) {
+	for _, serverAhead := range []time.Duration{0, 2 * time.Second, -3 * time.Second} {
+		address := startFakeNTPServer(t, serverAhead, nil)
+
+		offset, err := NTPOffset(address, time.Second)
+		require.NoError(t, err)
+		assert.InDelta(t, float64(-serverAhead), float64(offset), float64(50*time.Millisecond), "server ahead %s", serverAhead)
+	}
+}
+
+func TestNTPOffset_NotSynchronized(t *testing.T) {
+	address := startFakeNTPServer(t, 0, func(response []byte) {
+		response[0] |= ntpLeapNotSynchronized << 6
+	})
+
+	_, err := NTPOffset(address, time.Second)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not synchronized")
+}
+
+func TestNTPOffset_KissOfDeath(t *testing.T) {
+	address := startFakeNTPServer(t, 0, func(response []byte) {
+		response[1] = 0
+		copy(response[12:16], "RATE")
+	})
+
+	_, err := NTPOffset(address, time.Second)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "RATE")
+}
+
+func TestNTPOffset_MismatchedOriginate(t *testing.T) {
+	address := startFakeNTPServer(t, 0, func(response []byte) {
+		binary.BigEndian.PutUint64(response[24:], 1)
+	})
+
+	_, err := NTPOffset(address, time.Second)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "does not match")
+}
+
+func TestNTPOffset_Timeout(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = NTPOffset(conn.LocalAddr().String(), 100*time.Millisecond)
+	assert.Error(t, err)
+}
+
+func TestNTPTimeRoundTrip(t *testing.T) {
+	now := time.Now()
+	assert.WithinDuration(t, now, fromNTPTime(toNTPTime(now)), time.Microsecond)
+}
+
+func TestParseChronycTracking(t *testing.T) {
+	offset, err := parseChronycTracking("A9FEA97B,169.254.169.123,4,1718000000.123456789,-0.000012345,0.000001234,0.000002345,-1.234,0.001,0.010,0.000123,0.000456,64.5,Normal\n")
+	require.NoError(t, err)
+	assert.Equal(t, -12345*time.Nanosecond, offset)
+
+	offset, err = parseChronycTracking("A9FEA97B,169.254.169.123,4,1718000000.1,0.750000000,0,0,0,0,0,0,0,64,Normal")
+	require.NoError(t, err)
+	assert.Equal(t, 750*time.Millisecond, offset)
+
+	_, err = parseChronycTracking("506 Cannot talk to daemon")
+	assert.Error(t, err)
+
+	_, err = parseChronycTracking("A,B,C,D,not-a-number")
+	assert.Error(t, err)
+}
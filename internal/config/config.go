@@ -3,8 +3,11 @@ package config
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
+	"github.com/mitchellh/mapstructure"
 	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/failover"
 	"github.com/sol-strategies/solana-validator-failover/internal/utils"
 	"github.com/sol-strategies/solana-validator-failover/internal/validator"
 	"github.com/sol-strategies/solana-validator-failover/pkg/constants"
@@ -12,14 +15,37 @@ import (
 )
 
 const (
+	// DefaultLogFormat is the default log format - styled console logs
+	DefaultLogFormat = "text"
+
 	// DefaultBin is the default validator binary
 	DefaultBin = "agave-validator"
 
 	// DefaultCluster is the default cluster for the validator
 	DefaultCluster = "testnet"
 
+	// DefaultIdentitiesRemoteSignerTimeout is how long a request to the remote signer may take by default
+	DefaultIdentitiesRemoteSignerTimeout = "5s"
 	// DefaultFailoverServerPort is the default port for the failover server
 	DefaultFailoverServerPort = 9898
+	// DefaultFailoverAgentPort is the default port for the failover agent
+	DefaultFailoverAgentPort = 9897
+	// DefaultFailoverAgentFileTransferMaxSize is the largest file in bytes the agent accepts from a peer by default
+	DefaultFailoverAgentFileTransferMaxSize = 10 * 1024 * 1024
+	// DefaultFailoverTLSDir is the default directory this node's certificate and pinned peer certificates are kept in
+	DefaultFailoverTLSDir = "~/solana-validator-failover/tls"
+	// DefaultFailoverAuditEnabled is whether every drill and real failover is recorded in the audit log by default
+	DefaultFailoverAuditEnabled = true
+	// DefaultFailoverAuditDir is the default state dir the failover audit log is kept in
+	DefaultFailoverAuditDir = "~/solana-validator-failover/state"
+	// DefaultFailoverRoleIntentEnabled is whether the role this node should hold is recorded after failovers by default
+	DefaultFailoverRoleIntentEnabled = true
+	// DefaultFailoverRoleIntentDir is the default state dir the role intent file is kept in
+	DefaultFailoverRoleIntentDir = "~/solana-validator-failover/state"
+	// DefaultFailoverTLSPinPeerCertificates is whether peer certificates are pinned on first use by default
+	DefaultFailoverTLSPinPeerCertificates = true
+	// DefaultFailoverTLSRequireClientCertificates is whether connecting peers must present a client certificate by default
+	DefaultFailoverTLSRequireClientCertificates = false
 
 	// DefaultFailoverServerHeartbeatInterval is the default heartbeat interval for the failover server
 	DefaultFailoverServerHeartbeatInterval = "5s"
@@ -27,23 +53,179 @@ const (
 	// DefaultFailoverServerStreamTimeout is the default stream timeout for the failover server
 	DefaultFailoverServerStreamTimeout = "5m"
 
+	// DefaultFailoverServerWaitProgressInterval is the default interval progress is logged at while the failover server
+	// waits for the active node
+	DefaultFailoverServerWaitProgressInterval = "1m"
+
+	// DefaultFailoverServerPeerAllowlist is whether the failover server rejects connecting nodes that aren't configured
+	// peers by default
+	DefaultFailoverServerPeerAllowlist = true
+
+	// DefaultFailoverClientDialRetries is the default number of times the failover client retries dialing a peer
+	DefaultFailoverClientDialRetries = 30
+
+	// DefaultFailoverClientDialRetryInterval is the default initial interval between failover client dial retries
+	DefaultFailoverClientDialRetryInterval = "1s"
+
+	// DefaultFailoverClientDialRetryMaxInterval is the default maximum interval between failover client dial retries
+	DefaultFailoverClientDialRetryMaxInterval = "10s"
+
+	// DefaultFailoverDrillTimeout is the default time the passive node waits for the active node to connect for a scheduled drill
+	DefaultFailoverDrillTimeout = "10m"
+
+	// DefaultFailoverEventsTimeout is the default time allowed to connect to the broker and publish a failover event
+	DefaultFailoverEventsTimeout = "5s"
+
+	// DefaultFailoverNotificationsTimeout is the default time allowed to send a failover notification to each target
+	DefaultFailoverNotificationsTimeout = "5s"
+	// DefaultFailoverTracingTimeout is the default time allowed to export a failover's spans
+	DefaultFailoverTracingTimeout = "5s"
+
+	// DefaultFailoverPathMTUProbeEnabled is whether the path mtu to the passive node is probed before a failover by default
+	DefaultFailoverPathMTUProbeEnabled = true
+	// DefaultFailoverPathMTUProbeMinMTU is the default path mtu below which a failover warns
+	DefaultFailoverPathMTUProbeMinMTU = 1400
+	// DefaultFailoverPathMTUProbeTimeout is the default time allowed to probe the path mtu
+	DefaultFailoverPathMTUProbeTimeout = "3s"
+
+	// DefaultFailoverClockCheckSource is the default source the local clock offset is read from
+	DefaultFailoverClockCheckSource = "ntp"
+	// DefaultFailoverClockCheckNTPServer is the default ntp server queried for the local clock offset
+	DefaultFailoverClockCheckNTPServer = "pool.ntp.org:123"
+	// DefaultFailoverClockCheckMaxOffset is the default largest local clock offset tolerated before a failover
+	DefaultFailoverClockCheckMaxOffset = "500ms"
+	// DefaultFailoverClockCheckAction is the default action taken when the local clock offset exceeds the maximum
+	DefaultFailoverClockCheckAction = "warn"
+	// DefaultFailoverClockCheckTimeout is the default time allowed to read the local clock offset
+	DefaultFailoverClockCheckTimeout = "5s"
+
+	// DefaultFailoverEpochBoundaryEnabled is whether failing over near an epoch boundary is guarded against by default
+	DefaultFailoverEpochBoundaryEnabled = true
+	// DefaultFailoverEpochBoundaryWindowSlots is the default number of slots either side of an epoch boundary guarded
+	DefaultFailoverEpochBoundaryWindowSlots = 1000
+	// DefaultFailoverEpochBoundaryAction is the default action taken when failing over within the window
+	DefaultFailoverEpochBoundaryAction = "warn"
+	// DefaultFailoverFeatureGatesWindowSlots is the default number of slots either side of a feature gate activation
+	// a failover is warned about (~1h)
+	DefaultFailoverFeatureGatesWindowSlots = 9000
+
+	// DefaultFailoverMonitorIdentityGapAlarmThreshold is the default identity gap above which a failover alarms
+	DefaultFailoverMonitorIdentityGapAlarmThreshold = "2s"
+
+	// DefaultFailoverMonitorDurationRegressionEnabled is whether a failover's phase durations are compared against
+	// earlier failovers in the audit log by default
+	DefaultFailoverMonitorDurationRegressionEnabled = true
+	// DefaultFailoverMonitorDurationRegressionThresholdPercent is the default percentage slower than the median a
+	// phase must be to regress
+	DefaultFailoverMonitorDurationRegressionThresholdPercent = 50
+	// DefaultFailoverMonitorDurationRegressionBaselineFailovers is the default number of latest failovers the median
+	// is taken over
+	DefaultFailoverMonitorDurationRegressionBaselineFailovers = 10
+	// DefaultFailoverMonitorDurationRegressionMinBaselineFailovers is the default number of earlier failovers needed
+	// to compare a phase
+	DefaultFailoverMonitorDurationRegressionMinBaselineFailovers = 3
+	// DefaultFailoverMonitorDurationRegressionMinIncrease is the default time slower than the median a phase must
+	// also be to regress
+	DefaultFailoverMonitorDurationRegressionMinIncrease = "500ms"
+
+	// DefaultFailoverMonitorDetachedLogFile is the default file post-failover monitoring run in the background writes to
+	DefaultFailoverMonitorDetachedLogFile = "~/solana-validator-failover/post-monitor.log"
+
+	// DefaultFailoverPeerSelectionTimeout is the default time allowed to query passive peers' status for ranking
+	DefaultFailoverPeerSelectionTimeout = "5s"
+
+	// DefaultFailoverWatchInterval is the default interval the watch daemon checks the active identity at
+	DefaultFailoverWatchInterval = "10s"
+	// DefaultFailoverWatchFailureThreshold is the default time a failure condition must persist before watch fails over
+	DefaultFailoverWatchFailureThreshold = "1m"
+	// DefaultFailoverWatchConditionEnabled is whether each watch failure condition is checked by default
+	DefaultFailoverWatchConditionEnabled = true
+	// DefaultFailoverWatchCooldown is the default time watching pauses after a failover attempt
+	DefaultFailoverWatchCooldown = "30m"
+	// DefaultFailoverWatchTimeout is the default time the passive node waits for the active node to connect once its
+	// agent accepts a handover started by watch
+	DefaultFailoverWatchTimeout = "5m"
+
+	// DefaultFailoverPromoteMinGossipAbsence is the default time the active identity must stay out of gossip
+	// before an emergency promotion
+	DefaultFailoverPromoteMinGossipAbsence = "1m"
+
+	// DefaultDebugLogSampleInterval is the default minimum interval between repeated debug logs on high-volume rpc paths
+	DefaultDebugLogSampleInterval = "10s"
+	// DefaultNetworkRPCTimeout is the default time a network rpc call may take before the next url is tried
+	DefaultNetworkRPCTimeout = "10s"
+	// DefaultNetworkRPCUnhealthyCooldown is the default time a network rpc url that failed is tried after the others
+	DefaultNetworkRPCUnhealthyCooldown = "30s"
+	// DefaultNetworkRPCRetryMaxAttempts is the default number of times a failed network rpc call is made
+	DefaultNetworkRPCRetryMaxAttempts = 4
+	// DefaultNetworkRPCRetryBaseDelay is the default delay before the first network rpc retry - doubled for each after
+	DefaultNetworkRPCRetryBaseDelay = "500ms"
+	// DefaultNetworkRPCRetryMaxDelay is the default longest delay between network rpc retries
+	DefaultNetworkRPCRetryMaxDelay = "5s"
+	// DefaultNetworkRPCRetryJitter is the default fraction network rpc retry delays are randomly varied by
+	DefaultNetworkRPCRetryJitter = 0.2
+	// DefaultNetworkRPCClusterNodesCacheTTL is the default time the cluster's gossip nodes are reused for lookups
+	DefaultNetworkRPCClusterNodesCacheTTL = "5s"
+	// DefaultSilenceDeprecationWarnings is whether notices about deprecated features in use are silenced by default
+	DefaultSilenceDeprecationWarnings = false
+	// DefaultFailoverMonitorMetricsSnapshotBlockProductionSlots is the default number of recent slots block production
+	// is compared over in pre and post-failover metrics snapshots
+	DefaultFailoverMonitorMetricsSnapshotBlockProductionSlots = 1000
+	// DefaultFailoverMonitorBlockProductionEnabled is whether the active identity's block production after a failover is
+	// checked by default
+	DefaultFailoverMonitorBlockProductionEnabled = true
+	// DefaultFailoverMonitorBlockProductionLeaderSlots is the default number of leader slots after a failover checked
+	DefaultFailoverMonitorBlockProductionLeaderSlots = 8
+	// DefaultFailoverMonitorBlockProductionTimeout is the default time waited for those leader slots to pass
+	DefaultFailoverMonitorBlockProductionTimeout = "5m"
+	// DefaultFailoverMonitorRewardsEstimateEnabled is whether the post-failover report estimates what the identity gap
+	// cost in epoch rewards by default
+	DefaultFailoverMonitorRewardsEstimateEnabled = false
+	// DefaultFailoverLogSlotContextInterval is the default interval slot context is sampled at for critical window log lines
+	DefaultFailoverLogSlotContextInterval = failover.DefaultLogSlotContextIntervalDurationStr
+
+	// DefaultFailoverMinActiveIdentityBalance is the default minimum lamports the active identity must hold to pay vote fees (0.1 SOL)
+	DefaultFailoverMinActiveIdentityBalance = 100_000_000
+	// DefaultFailoverMaxSlotLag is the default number of slots the passive node may be behind the network and still take over
+	DefaultFailoverMaxSlotLag = 20
+
 	// DefaultFailoverMinimumTimeToLeaderSlot is the default minimum time to leader slot for the failover server
 	DefaultFailoverMinimumTimeToLeaderSlot = "5m"
 
+	// DefaultFailoverSwitchCountdown is the default time both nodes count down to the moment the active node switches
+	DefaultFailoverSwitchCountdown = "3s"
+
 	// DefaultFailoverMonitorCreditSamplesCount is the default credit samples count for the failover server
 	DefaultFailoverMonitorCreditSamplesCount = 5
 
 	// DefaultFailoverMonitorCreditSamplesInterval is the default credit samples interval for the failover server
 	DefaultFailoverMonitorCreditSamplesInterval = "5s"
 
+	// DefaultTowerDriftMonitorInterval is the default interval the tower file is checked for drift when passive
+	DefaultTowerDriftMonitorInterval = "5s"
+
+	// DefaultTowerBackupEnabled is whether the tower file is backed up before the passive node overwrites or removes it
+	DefaultTowerBackupEnabled = true
+
+	// DefaultTowerBackupRetention is the default number of tower file backups kept
+	DefaultTowerBackupRetention = 5
+
+	// DefaultTowerReplicationInterval is the default interval the active node ships its tower file to its peers at
+	DefaultTowerReplicationInterval = "10s"
+
+	// DefaultTowerSanityCheck is whether the passive node sanity checks the tower file it receives before using it
+	DefaultTowerSanityCheck = true
+
 	// DefaultTowerFileNameTemplate is the default tower file name template for the validator
 	DefaultTowerFileNameTemplate = "tower-1_9-{{ .Identities.Active.PubKey }}.bin"
 
-	// DefaultSetIdentityPassiveCmdTemplate is the default set identity passive command template for the validator
-	DefaultSetIdentityPassiveCmdTemplate = "{{ .Bin }} --ledger {{ .LedgerDir }} set-identity {{ .Identities.Passive.KeyFile }}"
+	// DefaultSetIdentityPassiveCmdTemplate is the default set identity passive command template for the validator -
+	// empty selects the built-in template for the client detected from the validator binary
+	DefaultSetIdentityPassiveCmdTemplate = ""
 
-	// DefaultSetIdentityActiveCmdTemplate is the default set identity active command template for the validator
-	DefaultSetIdentityActiveCmdTemplate = "{{ .Bin }} --ledger {{ .LedgerDir }} set-identity {{ .Identities.Active.KeyFile }} --require-tower"
+	// DefaultSetIdentityActiveCmdTemplate is the default set identity active command template for the validator -
+	// empty selects the built-in template for the client detected from the validator binary
+	DefaultSetIdentityActiveCmdTemplate = ""
 )
 
 var (
@@ -53,14 +235,35 @@ var (
 
 // SolanaValidatorFailover is the configuration for the program
 type SolanaValidatorFailover struct {
+	Log       LogConfig        `mapstructure:"log"`
 	Validator validator.Config `mapstructure:"validator"`
+	// Validators are named validators sharing this host - each inherits validator and overrides what it sets
+	Validators []NamedValidatorConfig `mapstructure:"-"`
+}
+
+// LogConfig is the configuration for the program's logs
+type LogConfig struct {
+	// Format is text for styled console logs or json for one json object per line - --log-format overrides it
+	Format string `mapstructure:"format"`
+}
+
+// NamedValidatorConfig is a named validator entry from the validators list
+type NamedValidatorConfig struct {
+	Name   string
+	Config validator.Config
 }
 
 // NewFromFile creates a new SolanaValidatorFailover configuration from a config file
 func NewFromFile(configPath string) (s *SolanaValidatorFailover, err error) {
+	return NewFromFileWithOverrides(configPath, nil)
+}
+
+// NewFromFileWithOverrides creates a new SolanaValidatorFailover configuration from a config file with
+// validatorOverrides merged over the validator config, and over each entry of the validators list
+func NewFromFileWithOverrides(configPath string, validatorOverrides map[string]any) (s *SolanaValidatorFailover, err error) {
 	s = &SolanaValidatorFailover{}
 
-	err = s.LoadFromConfigFile(configPath)
+	err = s.loadFromConfigFile(configPath, validatorOverrides)
 	if err != nil {
 		return nil, err
 	}
@@ -70,6 +273,11 @@ func NewFromFile(configPath string) (s *SolanaValidatorFailover, err error) {
 
 // LoadFromConfigFile loads the config from a config file
 func (s *SolanaValidatorFailover) LoadFromConfigFile(configPath string) (err error) {
+	return s.loadFromConfigFile(configPath, nil)
+}
+
+// loadFromConfigFile loads the config from a config file with validatorOverrides merged over the validator config
+func (s *SolanaValidatorFailover) loadFromConfigFile(configPath string, validatorOverrides map[string]any) (err error) {
 	logger := log.With().Str("component", "config").Logger()
 	v := viper.New()
 
@@ -85,27 +293,261 @@ func (s *SolanaValidatorFailover) LoadFromConfigFile(configPath string) (err err
 	}
 
 	v.SetConfigFile(loadConfigPath)
+	setDefaults(v)
+	bindEnv(v)
+
+	// Read config file
+	logger.Debug().Str("config_file", loadConfigPath).Msg("loading")
+	err = v.ReadInConfig()
+	if err != nil {
+		return
+	}
+
+	// overrides are checked against the config keys on their own so a misspelt key fails rather than being ignored
+	if len(validatorOverrides) > 0 {
+		if err = validateValidatorOverrides(validatorOverrides); err != nil {
+			return
+		}
+		setOverrides(v, "validator", validatorOverrides)
+	}
+
+	// Unmarshal into the full config structure
+	err = v.Unmarshal(&s)
+	if err != nil {
+		return
+	}
+
+	return s.loadValidators(v, validatorOverrides)
+}
+
+// LogFormatFromFile reads only log.format from a config file so logging can be set up before the config is loaded
+func LogFormatFromFile(configPath string) (string, error) {
+	if configPath == "" {
+		configPath = DefaultConfigPath
+	}
+	resolvedConfigPath, err := utils.ResolvePath(configPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve config path: %w", err)
+	}
+
+	v := viper.New()
+	v.SetConfigFile(resolvedConfigPath)
+	v.SetDefault("log.format", DefaultLogFormat)
+	bindEnv(v)
+	if err := v.ReadInConfig(); err != nil {
+		return "", err
+	}
+	return v.GetString("log.format"), nil
+}
+
+// SelectValidator sets Validator to the named entry from the validators list - with no name the only entry
+// is selected, and with no validators list the top-level validator is used as is
+func (s *SolanaValidatorFailover) SelectValidator(name string) error {
+	if len(s.Validators) == 0 {
+		if name != "" {
+			return fmt.Errorf("validator %s selected but no validators are declared", name)
+		}
+		return nil
+	}
+
+	names := make([]string, 0, len(s.Validators))
+	for _, namedValidator := range s.Validators {
+		names = append(names, namedValidator.Name)
+	}
+
+	if name == "" {
+		if len(s.Validators) > 1 {
+			return fmt.Errorf("multiple validators declared - select one with --validator: %s", strings.Join(names, ", "))
+		}
+		name = s.Validators[0].Name
+	}
+
+	for _, namedValidator := range s.Validators {
+		if namedValidator.Name == name {
+			s.Validator = namedValidator.Config
+			log.Debug().Str("validator", name).Msg("validator selected")
+			return nil
+		}
+	}
+
+	return fmt.Errorf("validator %s not found in validators: %s", name, strings.Join(names, ", "))
+}
+
+// loadValidators loads the optional validators list - each entry is the top-level validator config deep merged
+// with the entry so shared settings only need declaring once, and environment variables override both
+func (s *SolanaValidatorFailover) loadValidators(v *viper.Viper, validatorOverrides map[string]any) error {
+	rawValidators := v.Get("validators")
+	if rawValidators == nil {
+		return nil
+	}
+
+	entries, ok := rawValidators.([]any)
+	if !ok {
+		return fmt.Errorf("validators must be a list")
+	}
+
+	baseValidator := v.AllSettings()["validator"]
+	seenNames := map[string]bool{}
+
+	for i, rawEntry := range entries {
+		entry, ok := toStringMap(rawEntry)
+		if !ok {
+			return fmt.Errorf("validators[%d] must be a map", i)
+		}
 
-	// Set defaults
+		name, _ := entry["name"].(string)
+		if name == "" {
+			return fmt.Errorf("validators[%d].name is required", i)
+		}
+		if seenNames[name] {
+			return fmt.Errorf("validators[%d].name %s is declared more than once", i, name)
+		}
+		seenNames[name] = true
+
+		entryOverrides := make(map[string]any, len(entry))
+		for key, value := range entry {
+			if key != "name" {
+				entryOverrides[key] = value
+			}
+		}
+
+		entryViper := viper.New()
+		setDefaults(entryViper)
+		bindEnv(entryViper)
+		if err := entryViper.MergeConfigMap(map[string]any{"validator": baseValidator}); err != nil {
+			return fmt.Errorf("validators[%d]: %w", i, err)
+		}
+		if err := entryViper.MergeConfigMap(map[string]any{"validator": entryOverrides}); err != nil {
+			return fmt.Errorf("validators[%d]: %w", i, err)
+		}
+		if len(validatorOverrides) > 0 {
+			setOverrides(entryViper, "validator", validatorOverrides)
+		}
+
+		// unmarshalled whole as UnmarshalKey only reads the config layer of a key holding a map, leaving out
+		// environment variables and overrides
+		entryConfig := SolanaValidatorFailover{}
+		if err := entryViper.Unmarshal(&entryConfig); err != nil {
+			return fmt.Errorf("validators[%d]: %w", i, err)
+		}
+		s.Validators = append(s.Validators, NamedValidatorConfig{Name: name, Config: entryConfig.Validator})
+	}
+
+	return nil
+}
+
+// validateValidatorOverrides ensures every key in validatorOverrides is a validator config key
+func validateValidatorOverrides(validatorOverrides map[string]any) error {
+	overridesViper := viper.New()
+	if err := overridesViper.MergeConfigMap(map[string]any{"validator": validatorOverrides}); err != nil {
+		return fmt.Errorf("invalid validator overrides: %w", err)
+	}
+	err := overridesViper.UnmarshalKey("validator", &validator.Config{}, func(decoderConfig *mapstructure.DecoderConfig) {
+		decoderConfig.ErrorUnused = true
+	})
+	if err != nil {
+		return fmt.Errorf("invalid validator overrides: %w", err)
+	}
+	return nil
+}
+
+// toStringMap returns a map entry of a list with string keys - yaml decodes maps nested in lists with any keys
+func toStringMap(value any) (map[string]any, bool) {
+	switch typed := value.(type) {
+	case map[string]any:
+		return typed, true
+	case map[any]any:
+		converted := make(map[string]any, len(typed))
+		for key, v := range typed {
+			converted[fmt.Sprintf("%v", key)] = v
+		}
+		return converted, true
+	}
+	return nil, false
+}
+
+// setDefaults sets the default values for the validator config
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("log.format", DefaultLogFormat)
 	v.SetDefault("validator.bin", DefaultBin)
 	v.SetDefault("validator.cluster", DefaultCluster)
+	v.SetDefault("validator.debug_log_sample_interval", DefaultDebugLogSampleInterval)
+	v.SetDefault("validator.silence_deprecation_warnings", DefaultSilenceDeprecationWarnings)
+	v.SetDefault("validator.identities.remote_signer.timeout", DefaultIdentitiesRemoteSignerTimeout)
+	v.SetDefault("validator.network_rpc.timeout", DefaultNetworkRPCTimeout)
+	v.SetDefault("validator.network_rpc.unhealthy_cooldown", DefaultNetworkRPCUnhealthyCooldown)
+	v.SetDefault("validator.network_rpc.retry.max_attempts", DefaultNetworkRPCRetryMaxAttempts)
+	v.SetDefault("validator.network_rpc.retry.base_delay", DefaultNetworkRPCRetryBaseDelay)
+	v.SetDefault("validator.network_rpc.retry.max_delay", DefaultNetworkRPCRetryMaxDelay)
+	v.SetDefault("validator.network_rpc.retry.jitter", DefaultNetworkRPCRetryJitter)
+	v.SetDefault("validator.network_rpc.cluster_nodes_cache_ttl", DefaultNetworkRPCClusterNodesCacheTTL)
+	v.SetDefault("validator.failover.agent.file_transfer.max_size", DefaultFailoverAgentFileTransferMaxSize)
+	v.SetDefault("validator.failover.agent.port", DefaultFailoverAgentPort)
+	v.SetDefault("validator.failover.audit.dir", DefaultFailoverAuditDir)
+	v.SetDefault("validator.failover.audit.enabled", DefaultFailoverAuditEnabled)
+	v.SetDefault("validator.failover.role_intent.dir", DefaultFailoverRoleIntentDir)
+	v.SetDefault("validator.failover.role_intent.enabled", DefaultFailoverRoleIntentEnabled)
+	v.SetDefault("validator.failover.clock_check.action", DefaultFailoverClockCheckAction)
+	v.SetDefault("validator.failover.clock_check.max_offset", DefaultFailoverClockCheckMaxOffset)
+	v.SetDefault("validator.failover.clock_check.ntp_server", DefaultFailoverClockCheckNTPServer)
+	v.SetDefault("validator.failover.clock_check.source", DefaultFailoverClockCheckSource)
+	v.SetDefault("validator.failover.clock_check.timeout", DefaultFailoverClockCheckTimeout)
+	v.SetDefault("validator.failover.client.dial_retries", DefaultFailoverClientDialRetries)
+	v.SetDefault("validator.failover.client.dial_retry_interval", DefaultFailoverClientDialRetryInterval)
+	v.SetDefault("validator.failover.client.dial_retry_max_interval", DefaultFailoverClientDialRetryMaxInterval)
+	v.SetDefault("validator.failover.drill.timeout", DefaultFailoverDrillTimeout)
+	v.SetDefault("validator.failover.epoch_boundary.action", DefaultFailoverEpochBoundaryAction)
+	v.SetDefault("validator.failover.epoch_boundary.enabled", DefaultFailoverEpochBoundaryEnabled)
+	v.SetDefault("validator.failover.epoch_boundary.window_slots", DefaultFailoverEpochBoundaryWindowSlots)
+	v.SetDefault("validator.failover.events.timeout", DefaultFailoverEventsTimeout)
+	v.SetDefault("validator.failover.notifications.timeout", DefaultFailoverNotificationsTimeout)
+	v.SetDefault("validator.failover.tracing.timeout", DefaultFailoverTracingTimeout)
+	v.SetDefault("validator.failover.feature_gates.window_slots", DefaultFailoverFeatureGatesWindowSlots)
+	v.SetDefault("validator.failover.log_slot_context.interval", DefaultFailoverLogSlotContextInterval)
+	v.SetDefault("validator.failover.min_active_identity_balance_lamports", DefaultFailoverMinActiveIdentityBalance)
+	v.SetDefault("validator.failover.max_slot_lag", DefaultFailoverMaxSlotLag)
 	v.SetDefault("validator.failover.min_time_to_leader_slot", DefaultFailoverMinimumTimeToLeaderSlot)
+	v.SetDefault("validator.failover.switch_countdown", DefaultFailoverSwitchCountdown)
+	v.SetDefault("validator.failover.monitor.block_production.enabled", DefaultFailoverMonitorBlockProductionEnabled)
+	v.SetDefault("validator.failover.monitor.block_production.leader_slots", DefaultFailoverMonitorBlockProductionLeaderSlots)
+	v.SetDefault("validator.failover.monitor.block_production.timeout", DefaultFailoverMonitorBlockProductionTimeout)
 	v.SetDefault("validator.failover.monitor.credit_samples.count", DefaultFailoverMonitorCreditSamplesCount)
 	v.SetDefault("validator.failover.monitor.credit_samples.interval", DefaultFailoverMonitorCreditSamplesInterval)
+	v.SetDefault("validator.failover.monitor.detached_log_file", DefaultFailoverMonitorDetachedLogFile)
+	v.SetDefault("validator.failover.monitor.duration_regression.baseline_failovers", DefaultFailoverMonitorDurationRegressionBaselineFailovers)
+	v.SetDefault("validator.failover.monitor.duration_regression.enabled", DefaultFailoverMonitorDurationRegressionEnabled)
+	v.SetDefault("validator.failover.monitor.duration_regression.min_baseline_failovers", DefaultFailoverMonitorDurationRegressionMinBaselineFailovers)
+	v.SetDefault("validator.failover.monitor.duration_regression.min_increase", DefaultFailoverMonitorDurationRegressionMinIncrease)
+	v.SetDefault("validator.failover.monitor.duration_regression.threshold_percent", DefaultFailoverMonitorDurationRegressionThresholdPercent)
+	v.SetDefault("validator.failover.monitor.identity_gap_alarm_threshold", DefaultFailoverMonitorIdentityGapAlarmThreshold)
+	v.SetDefault("validator.failover.monitor.metrics_snapshot.block_production_slots", DefaultFailoverMonitorMetricsSnapshotBlockProductionSlots)
+	v.SetDefault("validator.failover.monitor.rewards_estimate.enabled", DefaultFailoverMonitorRewardsEstimateEnabled)
+	v.SetDefault("validator.failover.path_mtu_probe.enabled", DefaultFailoverPathMTUProbeEnabled)
+	v.SetDefault("validator.failover.path_mtu_probe.min_mtu", DefaultFailoverPathMTUProbeMinMTU)
+	v.SetDefault("validator.failover.path_mtu_probe.timeout", DefaultFailoverPathMTUProbeTimeout)
+	v.SetDefault("validator.failover.peer_selection.timeout", DefaultFailoverPeerSelectionTimeout)
+	v.SetDefault("validator.failover.promote.min_gossip_absence", DefaultFailoverPromoteMinGossipAbsence)
 	v.SetDefault("validator.failover.server.heartbeat_interval", DefaultFailoverServerHeartbeatInterval)
 	v.SetDefault("validator.failover.server.port", DefaultFailoverServerPort)
 	v.SetDefault("validator.failover.server.stream_timeout", DefaultFailoverServerStreamTimeout)
+	v.SetDefault("validator.failover.server.wait_progress_interval", DefaultFailoverServerWaitProgressInterval)
+	v.SetDefault("validator.failover.server.peer_allowlist", DefaultFailoverServerPeerAllowlist)
 	v.SetDefault("validator.failover.set_identity_active_cmd_template", DefaultSetIdentityActiveCmdTemplate)
 	v.SetDefault("validator.failover.set_identity_passive_cmd_template", DefaultSetIdentityPassiveCmdTemplate)
+	v.SetDefault("validator.failover.tls.dir", DefaultFailoverTLSDir)
+	v.SetDefault("validator.failover.tls.pin_peer_certificates", DefaultFailoverTLSPinPeerCertificates)
+	v.SetDefault("validator.failover.tls.require_client_certificates", DefaultFailoverTLSRequireClientCertificates)
+	v.SetDefault("validator.failover.watch.conditions.delinquent", DefaultFailoverWatchConditionEnabled)
+	v.SetDefault("validator.failover.watch.conditions.not_in_gossip", DefaultFailoverWatchConditionEnabled)
+	v.SetDefault("validator.failover.watch.conditions.vote_credits_stalled", DefaultFailoverWatchConditionEnabled)
+	v.SetDefault("validator.failover.watch.cooldown", DefaultFailoverWatchCooldown)
+	v.SetDefault("validator.failover.watch.failure_threshold", DefaultFailoverWatchFailureThreshold)
+	v.SetDefault("validator.failover.watch.interval", DefaultFailoverWatchInterval)
+	v.SetDefault("validator.failover.watch.timeout", DefaultFailoverWatchTimeout)
+	v.SetDefault("validator.tower.backup.enabled", DefaultTowerBackupEnabled)
+	v.SetDefault("validator.tower.backup.retention", DefaultTowerBackupRetention)
+	v.SetDefault("validator.tower.drift_monitor.interval", DefaultTowerDriftMonitorInterval)
 	v.SetDefault("validator.tower.file_name_template", DefaultTowerFileNameTemplate)
-
-	// Read config file
-	logger.Debug().Str("config_file", loadConfigPath).Msg("loading")
-	err = v.ReadInConfig()
-	if err != nil {
-		return
-	}
-
-	// Unmarshal into the full config structure
-	return v.Unmarshal(&s)
+	v.SetDefault("validator.tower.replication.interval", DefaultTowerReplicationInterval)
+	v.SetDefault("validator.tower.sanity_check", DefaultTowerSanityCheck)
 }
@@ -132,15 +132,85 @@ validator:
 	require.NoError(t, err)
 
 	// Verify defaults are set correctly
+	assert.Equal(t, DefaultLogFormat, cfg.Log.Format)                                                                   // default
 	assert.Equal(t, DefaultBin, cfg.Validator.Bin)                                                                      // default
 	assert.Equal(t, DefaultCluster, cfg.Validator.Cluster)                                                              // from config
 	assert.Equal(t, DefaultFailoverServerPort, cfg.Validator.Failover.Server.Port)                                      // default
 	assert.Equal(t, DefaultFailoverServerHeartbeatInterval, cfg.Validator.Failover.Server.HeartbeatInterval)            // default
 	assert.Equal(t, DefaultFailoverServerStreamTimeout, cfg.Validator.Failover.Server.StreamTimeout)                    // default
+	assert.Equal(t, DefaultFailoverServerWaitProgressInterval, cfg.Validator.Failover.Server.WaitProgressInterval)      // default
+	assert.Equal(t, DefaultFailoverServerPeerAllowlist, cfg.Validator.Failover.Server.PeerAllowlist)                    // default
 	assert.Equal(t, DefaultFailoverMinimumTimeToLeaderSlot, cfg.Validator.Failover.MinimumTimeToLeaderSlot)             // default
+	assert.Equal(t, DefaultFailoverSwitchCountdown, cfg.Validator.Failover.SwitchCountdown)                             // default
 	assert.Equal(t, DefaultFailoverMonitorCreditSamplesCount, cfg.Validator.Failover.Monitor.CreditSamples.Count)       // default
 	assert.Equal(t, DefaultFailoverMonitorCreditSamplesInterval, cfg.Validator.Failover.Monitor.CreditSamples.Interval) // default
 	assert.Equal(t, DefaultTowerFileNameTemplate, cfg.Validator.Tower.FileNameTemplate)                                 // default
+	assert.Equal(t, DefaultSetIdentityActiveCmdTemplate, cfg.Validator.Failover.SetIdentityActiveCmdTemplate)           // default
+	assert.Equal(t, DefaultSetIdentityPassiveCmdTemplate, cfg.Validator.Failover.SetIdentityPassiveCmdTemplate)         // default
+	assert.Equal(t, DefaultFailoverClientDialRetries, cfg.Validator.Failover.Client.DialRetries)                        // default
+	assert.Equal(t, DefaultFailoverClientDialRetryInterval, cfg.Validator.Failover.Client.DialRetryInterval)            // default
+	assert.Equal(t, DefaultFailoverClientDialRetryMaxInterval, cfg.Validator.Failover.Client.DialRetryMaxInterval)      // default
+	assert.Equal(t, DefaultDebugLogSampleInterval, cfg.Validator.DebugLogSampleInterval)                                // default
+	assert.Equal(t, DefaultSilenceDeprecationWarnings, cfg.Validator.SilenceDeprecationWarnings)                        // default
+	assert.False(t, cfg.Validator.Failover.LogSlotContext.Enabled)                                                      // default
+	assert.Equal(t, DefaultFailoverLogSlotContextInterval, cfg.Validator.Failover.LogSlotContext.Interval)              // default
+	assert.Empty(t, cfg.Validator.Failover.Drill.Schedule)                                                              // default
+	assert.Equal(t, DefaultFailoverDrillTimeout, cfg.Validator.Failover.Drill.Timeout)                                  // default
+	assert.Equal(t, uint64(DefaultFailoverMinActiveIdentityBalance), cfg.Validator.Failover.MinActiveIdentityBalance)   // default
+	assert.Equal(t, DefaultFailoverEventsTimeout, cfg.Validator.Failover.Events.Timeout)                                // default
+	assert.Equal(t, DefaultFailoverNotificationsTimeout, cfg.Validator.Failover.Notifications.Timeout)                  // default
+	assert.Equal(t, DefaultFailoverTracingTimeout, cfg.Validator.Failover.Tracing.Timeout)                              // default
+	assert.Empty(t, cfg.Validator.Failover.Notifications.Targets)                                                       // default
+	assert.Equal(t, DefaultFailoverAgentPort, cfg.Validator.Failover.Agent.Port)                                        // default
+	assert.Equal(t, int64(DefaultFailoverAgentFileTransferMaxSize), cfg.Validator.Failover.Agent.FileTransfer.MaxSize)  // default
+	assert.Empty(t, cfg.Validator.Failover.Agent.FileTransfer.AllowedDirs)                                              // default
+	assert.Equal(t, DefaultFailoverTLSDir, cfg.Validator.Failover.TLS.Dir)                                              // default
+	assert.Equal(t, DefaultFailoverAuditEnabled, cfg.Validator.Failover.Audit.Enabled)                                  // default
+	assert.Equal(t, DefaultFailoverAuditDir, cfg.Validator.Failover.Audit.Dir)                                          // default
+	assert.True(t, cfg.Validator.Failover.TLS.PinPeerCertificates)                                                      // default
+	assert.False(t, cfg.Validator.Failover.TLS.RequireClientCertificates)                                               // default
+
+	assert.Equal(t, DefaultFailoverMonitorIdentityGapAlarmThreshold, cfg.Validator.Failover.Monitor.IdentityGapAlarmThreshold) // default
+	assert.Equal(t, DefaultFailoverMonitorDetachedLogFile, cfg.Validator.Failover.Monitor.DetachedLogFile)                     // default
+
+	metricsSnapshot := cfg.Validator.Failover.Monitor.MetricsSnapshot
+	assert.False(t, metricsSnapshot.Enabled)                                                                               // default
+	assert.EqualValues(t, DefaultFailoverMonitorMetricsSnapshotBlockProductionSlots, metricsSnapshot.BlockProductionSlots) // default
+
+	assert.Equal(t, DefaultFailoverMonitorRewardsEstimateEnabled, cfg.Validator.Failover.Monitor.RewardsEstimate.Enabled) // default
+
+	clockCheck := cfg.Validator.Failover.ClockCheck
+	assert.False(t, clockCheck.Enabled)                                       // default
+	assert.Equal(t, DefaultFailoverClockCheckSource, clockCheck.Source)       // default
+	assert.Equal(t, DefaultFailoverClockCheckNTPServer, clockCheck.NTPServer) // default
+	assert.Equal(t, DefaultFailoverClockCheckMaxOffset, clockCheck.MaxOffset) // default
+	assert.Equal(t, DefaultFailoverClockCheckAction, clockCheck.Action)       // default
+	assert.Equal(t, DefaultFailoverClockCheckTimeout, clockCheck.Timeout)     // default
+
+	pathMTUProbe := cfg.Validator.Failover.PathMTUProbe
+	assert.Equal(t, DefaultFailoverPathMTUProbeEnabled, pathMTUProbe.Enabled) // default
+	assert.Equal(t, DefaultFailoverPathMTUProbeMinMTU, pathMTUProbe.MinMTU)   // default
+	assert.Equal(t, DefaultFailoverPathMTUProbeTimeout, pathMTUProbe.Timeout) // default
+
+	featureGates := cfg.Validator.Failover.FeatureGates
+	assert.EqualValues(t, DefaultFailoverFeatureGatesWindowSlots, featureGates.WindowSlots) // default
+	assert.Empty(t, featureGates.Features)                                                  // default
+
+	peerSelection := cfg.Validator.Failover.PeerSelection
+	assert.False(t, peerSelection.AutoSelect)                                   // default
+	assert.Equal(t, DefaultFailoverPeerSelectionTimeout, peerSelection.Timeout) // default
+
+	watch := cfg.Validator.Failover.Watch
+	assert.Empty(t, watch.Peer)                                                                // default
+	assert.Equal(t, DefaultFailoverWatchInterval, watch.Interval)                              // default
+	assert.Equal(t, DefaultFailoverWatchFailureThreshold, watch.FailureThreshold)              // default
+	assert.Equal(t, DefaultFailoverWatchCooldown, watch.Cooldown)                              // default
+	assert.Equal(t, DefaultFailoverWatchTimeout, watch.Timeout)                                // default
+	assert.False(t, watch.NotADrill)                                                           // default
+	assert.False(t, watch.Takeover)                                                            // default
+	assert.Equal(t, DefaultFailoverWatchConditionEnabled, watch.Conditions.NotInGossip)        // default
+	assert.Equal(t, DefaultFailoverWatchConditionEnabled, watch.Conditions.Delinquent)         // default
+	assert.Equal(t, DefaultFailoverWatchConditionEnabled, watch.Conditions.VoteCreditsStalled) // default
 }
 
 func TestLoadFromConfigFile_WithInvalidYAML(t *testing.T) {
@@ -275,3 +345,152 @@ validator:
 	assert.Equal(t, "home-validator", cfg.Validator.Bin)
 	assert.Equal(t, "home-testnet", cfg.Validator.Cluster)
 }
+
+func TestLoadFromConfigFile_WithValidators(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "multi-config.yaml")
+
+	configContent := `
+validator:
+  cluster: mainnet-beta
+  rpc_address: http://localhost:8899
+  failover:
+    min_time_to_leader_slot: 10m
+validators:
+  - name: validator-a
+    ledger_dir: /mnt/ledger-a
+    failover:
+      server:
+        port: 9001
+  - name: validator-b
+    ledger_dir: /mnt/ledger-b
+    rpc_address: http://localhost:9899
+    failover:
+      server:
+        port: 9002
+`
+	err := os.WriteFile(configPath, []byte(configContent), 0644)
+	require.NoError(t, err)
+
+	cfg, err := NewFromFile(configPath)
+	require.NoError(t, err)
+	require.Len(t, cfg.Validators, 2)
+
+	// no selection with more than one validator is an error
+	err = cfg.SelectValidator("")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "--validator")
+
+	err = cfg.SelectValidator("validator-c")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	err = cfg.SelectValidator("validator-b")
+	require.NoError(t, err)
+	assert.Equal(t, "mainnet-beta", cfg.Validator.Cluster)                                                   // shared
+	assert.Equal(t, "10m", cfg.Validator.Failover.MinimumTimeToLeaderSlot)                                   // shared
+	assert.Equal(t, "http://localhost:9899", cfg.Validator.RPCAddress)                                       // overridden
+	assert.Equal(t, "/mnt/ledger-b", cfg.Validator.LedgerDir)                                                // entry
+	assert.Equal(t, 9002, cfg.Validator.Failover.Server.Port)                                                // entry
+	assert.Equal(t, DefaultFailoverServerHeartbeatInterval, cfg.Validator.Failover.Server.HeartbeatInterval) // default
+
+	err = cfg.SelectValidator("validator-a")
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8899", cfg.Validator.RPCAddress)
+	assert.Equal(t, 9001, cfg.Validator.Failover.Server.Port)
+}
+
+func TestLoadFromConfigFile_WithValidatorsMissingName(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "multi-config.yaml")
+
+	configContent := `
+validators:
+  - ledger_dir: /mnt/ledger-a
+`
+	err := os.WriteFile(configPath, []byte(configContent), 0644)
+	require.NoError(t, err)
+
+	_, err = NewFromFile(configPath)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "validators[0].name is required")
+}
+
+func TestNewFromFileWithOverrides(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "multi-config.yaml")
+
+	configContent := `
+validator:
+  cluster: mainnet-beta
+  failover:
+    min_time_to_leader_slot: 10m
+    switch_countdown: 2s
+validators:
+  - name: validator-a
+    failover:
+      switch_countdown: 4s
+`
+	err := os.WriteFile(configPath, []byte(configContent), 0644)
+	require.NoError(t, err)
+
+	cfg, err := NewFromFileWithOverrides(configPath, map[string]any{
+		"failover": map[string]any{
+			"switch_countdown": "6s",
+			"monitor": map[string]any{
+				"credit_samples": map[string]any{"count": 10},
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "6s", cfg.Validator.Failover.SwitchCountdown)          // overridden
+	assert.Equal(t, "10m", cfg.Validator.Failover.MinimumTimeToLeaderSlot) // kept
+
+	err = cfg.SelectValidator("validator-a")
+	require.NoError(t, err)
+	assert.Equal(t, "mainnet-beta", cfg.Validator.Cluster)                                                              // shared
+	assert.Equal(t, "6s", cfg.Validator.Failover.SwitchCountdown)                                                       // overrides the entry
+	assert.Equal(t, 10, cfg.Validator.Failover.Monitor.CreditSamples.Count)                                             // overridden
+	assert.Equal(t, DefaultFailoverMonitorCreditSamplesInterval, cfg.Validator.Failover.Monitor.CreditSamples.Interval) // default
+}
+
+func TestNewFromFileWithOverrides_UnknownKey(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config.yaml")
+	err := os.WriteFile(configPath, []byte("validator:\n  cluster: testnet\n"), 0644)
+	require.NoError(t, err)
+
+	_, err = NewFromFileWithOverrides(configPath, map[string]any{
+		"failover": map[string]any{"switch_countdwn": "6s"},
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "switch_countdwn")
+}
+
+func TestLogFormatFromFile(t *testing.T) {
+	tempDir := t.TempDir()
+
+	configPath := filepath.Join(tempDir, "config.yaml")
+	err := os.WriteFile(configPath, []byte("log:\n  format: json\nvalidator:\n  cluster: testnet\n"), 0644)
+	require.NoError(t, err)
+	format, err := LogFormatFromFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "json", format)
+
+	defaultConfigPath := filepath.Join(tempDir, "default.yaml")
+	err = os.WriteFile(defaultConfigPath, []byte("validator:\n  cluster: testnet\n"), 0644)
+	require.NoError(t, err)
+	format, err = LogFormatFromFile(defaultConfigPath)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultLogFormat, format)
+
+	_, err = LogFormatFromFile(filepath.Join(tempDir, "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestSelectValidator_WithoutValidators(t *testing.T) {
+	cfg := &SolanaValidatorFailover{}
+
+	assert.NoError(t, cfg.SelectValidator(""))
+	assert.Error(t, cfg.SelectValidator("validator-a"))
+}
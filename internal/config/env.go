@@ -0,0 +1,117 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// EnvPrefix prefixes environment variables overriding config keys - validator.rpc_address is overridden by
+// SOLANA_VALIDATOR_FAILOVER_VALIDATOR_RPC_ADDRESS
+const EnvPrefix = "SOLANA_VALIDATOR_FAILOVER"
+
+// bindEnv makes every config key overridable by its environment variable - keys are bound explicitly as viper only
+// reads the environment for keys it already knows of, and map entries like peers are found by scanning it
+func bindEnv(v *viper.Viper) {
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+	for _, key := range envKeys(reflect.TypeOf(SolanaValidatorFailover{}), "", os.Environ()) {
+		_ = v.BindEnv(key)
+	}
+}
+
+// envKeys returns the config keys of t under prefix that can be set from the environment - lists of maps can't
+// be expressed as a single value and are left to the config file
+func envKeys(t reflect.Type, prefix string, environ []string) (keys []string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := field.Tag.Get("mapstructure")
+			if name == "-" || !field.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = strings.ToLower(field.Name)
+			}
+			keys = append(keys, envKeys(field.Type, joinKey(prefix, name), environ)...)
+		}
+		return keys
+	case reflect.Map:
+		return envMapKeys(t, prefix, environ)
+	case reflect.Slice, reflect.Array:
+		if elem := t.Elem(); elem.Kind() == reflect.Struct || elem.Kind() == reflect.Map {
+			return nil
+		}
+	}
+	return []string{prefix}
+}
+
+// envMapKeys returns the keys of map entries set in the environment - entry names are what is left of the variable
+// name once the map's key and, for maps of structs, a field key are taken off, lowercased as viper does
+func envMapKeys(t reflect.Type, prefix string, environ []string) (keys []string) {
+	mapEnvPrefix := envName(prefix) + "_"
+	elem := t.Elem()
+	for elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+
+	var fieldKeys []string
+	if elem.Kind() == reflect.Struct {
+		fieldKeys = envKeys(elem, "", nil)
+		// longest first so a field is never mistaken for the end of another field
+		sort.Slice(fieldKeys, func(i, j int) bool { return len(fieldKeys[i]) > len(fieldKeys[j]) })
+	}
+
+	for _, variable := range environ {
+		name, _, _ := strings.Cut(variable, "=")
+		rest, ok := strings.CutPrefix(name, mapEnvPrefix)
+		if !ok || rest == "" {
+			continue
+		}
+		if fieldKeys == nil {
+			keys = append(keys, joinKey(prefix, strings.ToLower(rest)))
+			continue
+		}
+		for _, fieldKey := range fieldKeys {
+			entryName, ok := strings.CutSuffix(rest, "_"+strings.ToUpper(strings.ReplaceAll(fieldKey, ".", "_")))
+			if ok && entryName != "" {
+				keys = append(keys, joinKey(prefix, strings.ToLower(entryName)+"."+fieldKey))
+				break
+			}
+		}
+	}
+	return keys
+}
+
+// envName returns the environment variable name for a config key
+func envName(key string) string {
+	return strings.ToUpper(EnvPrefix + "_" + strings.ReplaceAll(key, ".", "_"))
+}
+
+// joinKey joins a config key to its parent key
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// setOverrides sets each value in overrides under key so it takes precedence over the config file and environment
+func setOverrides(v *viper.Viper, key string, overrides map[string]any) {
+	for overrideKey, value := range overrides {
+		if nested, ok := toStringMap(value); ok {
+			setOverrides(v, joinKey(key, overrideKey), nested)
+			continue
+		}
+		v.Set(joinKey(key, overrideKey), value)
+	}
+}
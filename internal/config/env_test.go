@@ -0,0 +1,90 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeEnvTestConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestNewFromFile_EnvOverrides(t *testing.T) {
+	configPath := writeEnvTestConfig(t, `
+validator:
+  rpc_address: http://localhost:8899
+  failover:
+    peers:
+      backup:
+        address: backup.example.com:9898
+`)
+	t.Setenv("SOLANA_VALIDATOR_FAILOVER_VALIDATOR_RPC_ADDRESS", "http://rpc:8899")
+	t.Setenv("SOLANA_VALIDATOR_FAILOVER_VALIDATOR_IDENTITIES_ACTIVE", "/keys/active.json")
+	t.Setenv("SOLANA_VALIDATOR_FAILOVER_VALIDATOR_FAILOVER_SERVER_PORT", "9999")
+	t.Setenv("SOLANA_VALIDATOR_FAILOVER_VALIDATOR_FAILOVER_PEERS_BACKUP_PORT", "9000")
+	t.Setenv("SOLANA_VALIDATOR_FAILOVER_VALIDATOR_FAILOVER_PEERS_DR_SITE_ADDRESS", "dr.example.com:9898")
+	t.Setenv("SOLANA_VALIDATOR_FAILOVER_VALIDATOR_FAILOVER_PEERS_DR_SITE_GOSSIP_IP", "10.0.0.2")
+
+	cfg, err := NewFromFile(configPath)
+	require.NoError(t, err)
+
+	assert.Equal(t, "http://rpc:8899", cfg.Validator.RPCAddress)
+	assert.Equal(t, "/keys/active.json", cfg.Validator.Identities.Active)
+	assert.Equal(t, 9999, cfg.Validator.Failover.Server.Port)
+	assert.Equal(t, DefaultFailoverServerHeartbeatInterval, cfg.Validator.Failover.Server.HeartbeatInterval)
+	require.Len(t, cfg.Validator.Failover.Peers, 2)
+	assert.Equal(t, "backup.example.com:9898", cfg.Validator.Failover.Peers["backup"].Address)
+	assert.Equal(t, 9000, cfg.Validator.Failover.Peers["backup"].Port)
+	assert.Equal(t, "dr.example.com:9898", cfg.Validator.Failover.Peers["dr_site"].Address)
+	assert.Equal(t, "10.0.0.2", cfg.Validator.Failover.Peers["dr_site"].GossipIP)
+}
+
+func TestNewFromFile_EnvOverridesValidators(t *testing.T) {
+	configPath := writeEnvTestConfig(t, `
+validator:
+  cluster: testnet
+validators:
+  - name: validator-a
+    cluster: mainnet-beta
+    failover:
+      switch_countdown: 4s
+`)
+	t.Setenv("SOLANA_VALIDATOR_FAILOVER_VALIDATOR_FAILOVER_SWITCH_COUNTDOWN", "5s")
+
+	cfg, err := NewFromFileWithOverrides(configPath, map[string]any{
+		"failover": map[string]any{"min_time_to_leader_slot": "1m"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, cfg.SelectValidator("validator-a"))
+
+	assert.Equal(t, "mainnet-beta", cfg.Validator.Cluster)
+	assert.Equal(t, "5s", cfg.Validator.Failover.SwitchCountdown)
+	assert.Equal(t, "1m", cfg.Validator.Failover.MinimumTimeToLeaderSlot)
+}
+
+func TestNewFromFileWithOverrides_OverridesEnv(t *testing.T) {
+	configPath := writeEnvTestConfig(t, "validator:\n  cluster: testnet\n")
+	t.Setenv("SOLANA_VALIDATOR_FAILOVER_VALIDATOR_FAILOVER_SWITCH_COUNTDOWN", "5s")
+
+	cfg, err := NewFromFileWithOverrides(configPath, map[string]any{
+		"failover": map[string]any{"switch_countdown": "6s"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "6s", cfg.Validator.Failover.SwitchCountdown)
+}
+
+func TestLogFormatFromFile_EnvOverride(t *testing.T) {
+	configPath := writeEnvTestConfig(t, "log:\n  format: text\n")
+	t.Setenv("SOLANA_VALIDATOR_FAILOVER_LOG_FORMAT", "json")
+
+	format, err := LogFormatFromFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "json", format)
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	internalconstants "github.com/sol-strategies/solana-validator-failover/internal/constants"
+	"github.com/sol-strategies/solana-validator-failover/internal/utils"
+	"github.com/sol-strategies/solana-validator-failover/internal/validator"
+	"github.com/spf13/viper"
+)
+
+// ValidateFile checks a config file and returns every problem found - unknown keys, values of the wrong type and
+// invalid durations, templates, peer addresses and clusters. err is only set when the file can't be read or parsed
+func ValidateFile(configPath string) (problems []string, err error) {
+	if configPath == "" {
+		configPath = DefaultConfigPath
+	}
+	resolvedConfigPath, err := utils.ResolvePath(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve config path: %w", err)
+	}
+
+	// only the keys set in the file - defaults would hide nothing but aren't worth reporting on
+	v := viper.New()
+	v.SetConfigFile(resolvedConfigPath)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	settings := v.AllSettings()
+	rawValidators, hasValidators := settings["validators"]
+	delete(settings, "validators")
+	problems = append(problems, decodeProblems("", settings, &SolanaValidatorFailover{})...)
+
+	if hasValidators {
+		entries, ok := rawValidators.([]any)
+		if !ok {
+			return append(problems, "validators must be a list"), nil
+		}
+		for i, rawEntry := range entries {
+			entry, ok := toStringMap(rawEntry)
+			if !ok {
+				problems = append(problems, fmt.Sprintf("validators[%d] must be a map", i))
+				continue
+			}
+			entrySettings := make(map[string]any, len(entry))
+			for key, value := range entry {
+				if key != "name" {
+					entrySettings[key] = value
+				}
+			}
+			problems = append(problems, decodeProblems(fmt.Sprintf("validators[%d]", i), entrySettings, &validator.Config{})...)
+		}
+	}
+
+	// unknown keys are ignored when loading, but values that can't be decoded fail it and are already reported
+	s := &SolanaValidatorFailover{}
+	if err := s.LoadFromConfigFile(resolvedConfigPath); err != nil {
+		if len(problems) == 0 {
+			problems = append(problems, err.Error())
+		}
+		return problems, nil
+	}
+
+	if s.Log.Format != internalconstants.LogFormatText && s.Log.Format != internalconstants.LogFormatJSON {
+		problems = append(problems, fmt.Sprintf(
+			"invalid log.format %q - must be one of: %s, %s",
+			s.Log.Format, internalconstants.LogFormatText, internalconstants.LogFormatJSON,
+		))
+	}
+
+	// with a validators list the top-level validator only holds what the entries share
+	if len(s.Validators) == 0 {
+		for _, problem := range validator.ValidateConfig(&s.Validator) {
+			problems = append(problems, fmt.Sprintf("validator: %s", problem))
+		}
+	}
+	for _, namedValidator := range s.Validators {
+		for _, problem := range validator.ValidateConfig(&namedValidator.Config) {
+			problems = append(problems, fmt.Sprintf("validators[%s]: %s", namedValidator.Name, problem))
+		}
+	}
+
+	return problems, nil
+}
+
+// decodeProblems decodes settings into result as loading does, but with unknown keys as errors, and returns every
+// error - prefix is prepended to each key
+func decodeProblems(prefix string, settings map[string]any, result any) (problems []string) {
+	decodeViper := viper.New()
+	if err := decodeViper.MergeConfigMap(settings); err != nil {
+		return []string{fmt.Sprintf("%s: %s", prefix, err)}
+	}
+	err := decodeViper.Unmarshal(result, func(decoderConfig *mapstructure.DecoderConfig) {
+		decoderConfig.ErrorUnused = true
+	})
+	if err == nil {
+		return nil
+	}
+
+	var decodeErr *mapstructure.Error
+	if !errors.As(err, &decodeErr) {
+		return []string{decodeProblem(prefix, err.Error())}
+	}
+	for _, message := range decodeErr.Errors {
+		problems = append(problems, decodeProblem(prefix, message))
+	}
+	return problems
+}
+
+// decodeProblem rewrites a mapstructure error to name keys as they appear in the config file
+func decodeProblem(prefix, message string) string {
+	message = strings.Replace(message, "has invalid keys:", "has unknown keys:", 1)
+	if rest, ok := strings.CutPrefix(message, "'' has unknown keys:"); ok {
+		if prefix == "" {
+			return "unknown top-level keys:" + rest
+		}
+		return fmt.Sprintf("'%s' has unknown keys:%s", prefix, rest)
+	}
+	if prefix != "" && strings.HasPrefix(message, "'") {
+		return fmt.Sprintf("'%s.%s", prefix, message[1:])
+	}
+	return message
+}
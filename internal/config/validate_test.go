@@ -0,0 +1,125 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validateTestConfig = `
+validator:
+  bin: agave-validator
+  cluster: testnet
+  rpc_address: http://localhost:8899
+  ledger_dir: /mnt/ledger
+  identities:
+    active: /home/sol/active.json
+    passive: /home/sol/passive.json
+  tower:
+    dir: /mnt/ledger
+  failover:
+    peers:
+      backup:
+        address: backup.example.com:9898
+`
+
+func writeValidateTestConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestValidateFile_Valid(t *testing.T) {
+	problems, err := ValidateFile(writeValidateTestConfig(t, validateTestConfig))
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+}
+
+func TestValidateFile_UnknownKeys(t *testing.T) {
+	problems, err := ValidateFile(writeValidateTestConfig(t, validateTestConfig+`
+    swtich_countdown: 5s
+    server:
+      prot: 9898
+logs:
+  format: json
+`))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"unknown top-level keys: logs",
+		"'validator.failover' has unknown keys: swtich_countdown",
+		"'validator.failover.server' has unknown keys: prot",
+	}, problems)
+}
+
+func TestValidateFile_ReportsEveryProblem(t *testing.T) {
+	problems, err := ValidateFile(writeValidateTestConfig(t, `
+validator:
+  bin: agave-validator
+  cluster: mainnet
+  rpc_address: localhost
+  ledger_dir: /mnt/ledger
+  identities:
+    active: /home/sol/active.json
+  tower:
+    dir: /mnt/ledger
+    file_name_template: "tower-{{ .Identities.Active.PubKey }.bin"
+  failover:
+    min_time_to_leader_slot: 5 minutes
+    peers:
+      backup:
+        address: backup.example.com
+    watch:
+      interval: often
+`))
+	require.NoError(t, err)
+
+	expected := []string{
+		"identities.passive is required",
+		"invalid cluster",
+		"tower.file_name_template",
+		"invalid peer address backup.example.com",
+		"minimum time to leader slot",
+		"failover.watch.interval",
+	}
+	require.Len(t, problems, len(expected), problems)
+	for i, problem := range problems {
+		assert.Contains(t, problem, "validator: ")
+		assert.Contains(t, problem, expected[i])
+	}
+}
+
+func TestValidateFile_Validators(t *testing.T) {
+	problems, err := ValidateFile(writeValidateTestConfig(t, validateTestConfig+`
+validators:
+  - name: mainnet
+    cluster: mainnet-beta
+  - name: testnet
+    rpc_adress: http://localhost:8900
+    failover:
+      switch_countdown: soon
+`))
+	require.NoError(t, err)
+	require.Len(t, problems, 2)
+	assert.Equal(t, "'validators[1]' has unknown keys: rpc_adress", problems[0])
+	assert.Contains(t, problems[1], "validators[testnet]: ")
+	assert.Contains(t, problems[1], "switch_countdown")
+}
+
+func TestValidateFile_WrongType(t *testing.T) {
+	problems, err := ValidateFile(writeValidateTestConfig(t, validateTestConfig+`
+    server:
+      port: lots
+`))
+	require.NoError(t, err)
+	require.Len(t, problems, 1)
+	assert.Contains(t, problems[0], "validator.failover.server.port")
+}
+
+func TestValidateFile_Missing(t *testing.T) {
+	_, err := ValidateFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
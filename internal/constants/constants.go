@@ -22,11 +22,26 @@ var (
 	// NodeRoleActive is the role of an active node
 	NodeRoleActive = "active"
 
+	// NodeRoleUnknown is the role of a node whose gossip identity matches neither of its identities
+	NodeRoleUnknown = "unknown"
+
+	// LogFormatText is the styled console log format meant for interactive use
+	LogFormatText = "text"
+
+	// LogFormatJSON is the log format of one json object per line meant for log ingestion e.g. Loki or ELK
+	LogFormatJSON = "json"
+
 	// ClientTypeAgave is the type of agave-validator client
 	ClientTypeAgave = "agave"
 
 	// ClientTypeFiredancer is the type of firedancer client
 	ClientTypeFiredancer = "firedancer"
+
+	// ClientTypeJitoAgave is the type of jito-agave validator client
+	ClientTypeJitoAgave = "jito-agave"
+
+	// ClientTypeUnknown is the type of a validator client that couldn't be detected
+	ClientTypeUnknown = "unknown"
 )
 
 func init() {
@@ -0,0 +1,73 @@
+package deprecation
+
+import (
+	"expvar"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog/log"
+)
+
+// SilenceEnvVar silences deprecation notices when set to true - usage is still counted
+const SilenceEnvVar = "SOLANA_VALIDATOR_FAILOVER_SILENCE_DEPRECATIONS"
+
+var (
+	// usage counts calls to each deprecated feature by name - published as the deprecated_usage expvar
+	usage = expvar.NewMap("deprecated_usage")
+	// notices holds a sync.Once per deprecated feature so each is only noticed once
+	notices sync.Map
+	// silenced is whether notices are logged
+	silenced atomic.Bool
+)
+
+func init() {
+	if silence, err := strconv.ParseBool(os.Getenv(SilenceEnvVar)); err == nil {
+		silenced.Store(silence)
+	}
+}
+
+// SetSilenced sets whether deprecation notices are logged
+func SetSilenced(silence bool) {
+	silenced.Store(silence)
+}
+
+// Silenced returns whether deprecation notices are silenced
+func Silenced() bool {
+	return silenced.Load()
+}
+
+// Use counts a use of the deprecated feature name and logs message the first time it is used unless notices are
+// silenced
+func Use(name, message string) {
+	usage.Add(name, 1)
+	if silenced.Load() {
+		return
+	}
+	once, _ := notices.LoadOrStore(name, &sync.Once{})
+	once.(*sync.Once).Do(func() {
+		log.Warn().
+			Str("deprecated", name).
+			Msgf("%s - only noticed once, set %s=true or silence_deprecation_warnings: true to silence", message, SilenceEnvVar)
+	})
+}
+
+// Count returns how many times the deprecated feature name has been used
+func Count(name string) int64 {
+	if count, ok := usage.Get(name).(*expvar.Int); ok {
+		return count.Value()
+	}
+	return 0
+}
+
+// Counts returns how many times each deprecated feature used has been used
+func Counts() map[string]int64 {
+	counts := make(map[string]int64)
+	usage.Do(func(kv expvar.KeyValue) {
+		if count, ok := kv.Value.(*expvar.Int); ok {
+			counts[kv.Key] = count.Value()
+		}
+	})
+	return counts
+}
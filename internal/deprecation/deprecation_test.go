@@ -0,0 +1,51 @@
+package deprecation
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+// captureLogs sends log output to a buffer for the duration of the test
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	original := log.Logger
+	log.Logger = zerolog.New(&buf)
+	t.Cleanup(func() {
+		log.Logger = original
+		SetSilenced(false)
+	})
+	return &buf
+}
+
+func TestUse_NoticesOnceAndCountsEveryUse(t *testing.T) {
+	logs := captureLogs(t)
+
+	Use("TestUse_NoticesOnce", "old thing is deprecated")
+	Use("TestUse_NoticesOnce", "old thing is deprecated")
+	Use("TestUse_NoticesOnce", "old thing is deprecated")
+
+	assert.Equal(t, 1, strings.Count(logs.String(), "old thing is deprecated"))
+	assert.Equal(t, int64(3), Count("TestUse_NoticesOnce"))
+	assert.Equal(t, int64(3), Counts()["TestUse_NoticesOnce"])
+}
+
+func TestUse_Silenced(t *testing.T) {
+	logs := captureLogs(t)
+	SetSilenced(true)
+
+	Use("TestUse_Silenced", "silenced thing is deprecated")
+
+	assert.True(t, Silenced())
+	assert.Empty(t, logs.String())
+	assert.Equal(t, int64(1), Count("TestUse_Silenced"))
+}
+
+func TestCount_Unused(t *testing.T) {
+	assert.Equal(t, int64(0), Count("TestCount_Unused"))
+}
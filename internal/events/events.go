@@ -0,0 +1,218 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// TypeStart is published when the failover critical window starts
+	TypeStart = "start"
+	// TypeComplete is published when the failover completes successfully
+	TypeComplete = "complete"
+	// TypeAbort is published when a failover is aborted after being initiated
+	TypeAbort = "abort"
+	// TypeIdentityGapAlarm is published when the identity gap of a completed failover exceeds its alarm threshold
+	TypeIdentityGapAlarm = "identity_gap_alarm"
+	// TypeDurationRegression is published when phases of a completed failover are slower than the median of earlier
+	// failovers in the audit log
+	TypeDurationRegression = "duration_regression"
+	// TypeMonitorComplete is published when post-failover vote credit monitoring run in the background completes
+	TypeMonitorComplete = "monitor_complete"
+	// TypeDrillFailure is published when a scheduled drill run by the watch daemon fails
+	TypeDrillFailure = "drill_failure"
+
+	// DefaultTimeout is the default time allowed to connect and publish an event
+	DefaultTimeout = 5 * time.Second
+)
+
+// Config is the configuration for publishing failover events to a message broker
+type Config struct {
+	// URL of the broker - nats://, tls:// (nats over tls), mqtt:// or mqtts:// - empty disables publishing
+	URL      string `mapstructure:"url"`
+	Topic    string `mapstructure:"topic"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	// Token is a nats auth token - ignored for mqtt
+	Token   string `mapstructure:"token"`
+	Timeout string `mapstructure:"timeout"`
+}
+
+// Node is a node taking part in a failover as it appears in an event
+type Node struct {
+	Hostname string `json:"hostname"`
+	PublicIP string `json:"public_ip"`
+	Pubkey   string `json:"pubkey"`
+}
+
+// Event is a failover lifecycle event
+type Event struct {
+	Type        string    `json:"type"`
+	Time        time.Time `json:"time"`
+	IsDryRun    bool      `json:"is_dry_run"`
+	ActiveNode  Node      `json:"active_node"`
+	PassiveNode Node      `json:"passive_node"`
+	Reason      string    `json:"reason,omitempty"`
+	// IdentityGapMs is how long the validator wasn't voting anywhere - set once the passive node has set its identity
+	IdentityGapMs int64 `json:"identity_gap_ms,omitempty"`
+	// VoteCreditRankBefore and VoteCreditRankAfter are the active identity's vote credit rank either side of
+	// post-failover monitoring - set on monitor_complete
+	VoteCreditRankBefore int `json:"vote_credit_rank_before,omitempty"`
+	VoteCreditRankAfter  int `json:"vote_credit_rank_after,omitempty"`
+	// ClientCertPeer is the configured peer name the active node's verified client certificate was issued for - set
+	// when the passive node requires client certificates
+	ClientCertPeer string `json:"client_cert_peer,omitempty"`
+	// FailoverID is the short random id the passive node gave the failover
+	FailoverID string `json:"failover_id,omitempty"`
+	// Summary is the failover as a single line for pasting into incident channels - set on complete and abort
+	Summary string `json:"summary,omitempty"`
+}
+
+// Publisher publishes events to a message broker in the background - a publisher with no url is a no-op
+type Publisher struct {
+	brokerURL *url.URL
+	topic     string
+	username  string
+	password  string
+	token     string
+	timeout   time.Duration
+	wg        sync.WaitGroup
+	logger    zerolog.Logger
+}
+
+// Validate ensures the config is valid when a url is set
+func (c Config) Validate() error {
+	if c.URL == "" {
+		return nil
+	}
+	brokerURL, err := parseBrokerURL(c.URL)
+	if err != nil {
+		return err
+	}
+	if c.Topic == "" {
+		return fmt.Errorf("topic is required when url is set")
+	}
+	if isMQTT(brokerURL) && c.Username == "" && brokerURL.User.Username() == "" {
+		if _, ok := brokerURL.User.Password(); ok || c.Password != "" {
+			return fmt.Errorf("username is required with a password for mqtt")
+		}
+	}
+	if c.Timeout != "" {
+		if _, err := time.ParseDuration(c.Timeout); err != nil {
+			return fmt.Errorf("failed to parse timeout %s: %w", c.Timeout, err)
+		}
+	}
+	return nil
+}
+
+// NewPublisher creates a new publisher from a config
+func NewPublisher(cfg Config) (*Publisher, error) {
+	p := &Publisher{
+		topic:    cfg.Topic,
+		username: cfg.Username,
+		password: cfg.Password,
+		token:    cfg.Token,
+		timeout:  DefaultTimeout,
+		logger:   log.With().Str("component", "events").Logger(),
+	}
+
+	if cfg.URL == "" {
+		return p, nil
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	p.brokerURL, _ = parseBrokerURL(cfg.URL)
+	if cfg.Timeout != "" {
+		p.timeout, _ = time.ParseDuration(cfg.Timeout)
+	}
+
+	// credentials in the url are used unless set explicitly
+	if p.brokerURL.User != nil {
+		if p.username == "" {
+			p.username = p.brokerURL.User.Username()
+		}
+		if password, ok := p.brokerURL.User.Password(); ok && p.password == "" {
+			p.password = password
+		}
+	}
+
+	return p, nil
+}
+
+// IsEnabled returns true if the publisher has a broker to publish to
+func (p *Publisher) IsEnabled() bool {
+	return p != nil && p.brokerURL != nil
+}
+
+// Publish publishes an event in the background so it never holds up a failover - failures are logged
+func (p *Publisher) Publish(event Event) {
+	if !p.IsEnabled() {
+		return
+	}
+
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		if err := p.publish(event); err != nil {
+			p.logger.Warn().Err(err).Str("type", event.Type).Msg("failed to publish failover event")
+			return
+		}
+		p.logger.Debug().Str("type", event.Type).Str("topic", p.topic).Msg("published failover event")
+	}()
+}
+
+// Wait waits for events being published in the background - each is bounded by the publish timeout
+func (p *Publisher) Wait() {
+	if !p.IsEnabled() {
+		return
+	}
+	p.wg.Wait()
+}
+
+// publish connects to the broker and publishes a single event
+func (p *Publisher) publish(event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	if isMQTT(p.brokerURL) {
+		return p.publishMQTT(payload)
+	}
+	return p.publishNATS(payload)
+}
+
+// isMQTT returns true when the broker url is an mqtt broker's rather than a nats server's
+func isMQTT(brokerURL *url.URL) bool {
+	return brokerURL.Scheme == "mqtt" || brokerURL.Scheme == "mqtts"
+}
+
+// parseBrokerURL parses and validates a broker url
+func parseBrokerURL(rawURL string) (*url.URL, error) {
+	brokerURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url %s: %w", rawURL, err)
+	}
+	switch brokerURL.Scheme {
+	case "nats", "tls", "mqtt", "mqtts":
+	default:
+		return nil, fmt.Errorf("invalid url %s: scheme must be one of nats, tls, mqtt or mqtts", rawURL)
+	}
+	if brokerURL.Hostname() == "" || brokerURL.Port() == "" {
+		return nil, fmt.Errorf("invalid url %s: must include a host and port", rawURL)
+	}
+	return brokerURL, nil
+}
@@ -0,0 +1,195 @@
+package events
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listen starts a local tcp listener handling a single connection with handler
+func listen(t *testing.T, handler func(conn net.Conn)) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		handler(conn)
+	}()
+
+	return listener.Addr().String()
+}
+
+// readRemainingLength decodes an mqtt variable byte integer
+func readRemainingLength(reader *bufio.Reader) (length int) {
+	multiplier := 1
+	for {
+		digit, err := reader.ReadByte()
+		if err != nil {
+			return length
+		}
+		length += int(digit&0x7f) * multiplier
+		if digit&0x80 == 0 {
+			return length
+		}
+		multiplier *= 128
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, Config{}.Validate())
+	assert.NoError(t, Config{URL: "nats://localhost:4222", Topic: "failover"}.Validate())
+	assert.NoError(t, Config{URL: "mqtts://broker:8883", Topic: "failover", Timeout: "2s"}.Validate())
+
+	err := Config{URL: "http://localhost:4222", Topic: "failover"}.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "scheme")
+
+	err = Config{URL: "nats://localhost", Topic: "failover"}.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "host and port")
+
+	err = Config{URL: "nats://localhost:4222"}.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "topic")
+
+	err = Config{URL: "nats://localhost:4222", Topic: "failover", Timeout: "soon"}.Validate()
+	assert.Error(t, err)
+}
+
+func TestPublisher_Disabled(t *testing.T) {
+	p, err := NewPublisher(Config{})
+	require.NoError(t, err)
+	assert.False(t, p.IsEnabled())
+
+	// no-ops
+	p.Publish(Event{Type: TypeStart})
+	p.Wait()
+}
+
+func TestPublisher_NATS(t *testing.T) {
+	received := make(chan string, 4)
+	addr := listen(t, func(conn net.Conn) {
+		_, _ = conn.Write([]byte("INFO {\"server_id\":\"test\"}\r\n"))
+		reader := bufio.NewReader(conn)
+		for i := 0; i < 4; i++ {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimSpace(line)
+			received <- line
+			if line == "PING" {
+				_, _ = conn.Write([]byte("PONG\r\n"))
+				return
+			}
+		}
+	})
+
+	p, err := NewPublisher(Config{URL: "nats://alice:secret@" + addr, Topic: "solana.failover"})
+	require.NoError(t, err)
+
+	err = p.publish(Event{Type: TypeComplete, IsDryRun: true})
+	require.NoError(t, err)
+
+	connect := <-received
+	assert.True(t, strings.HasPrefix(connect, "CONNECT "))
+	assert.Contains(t, connect, `"user":"alice"`)
+	assert.Contains(t, connect, `"pass":"secret"`)
+
+	pub := <-received
+	assert.True(t, strings.HasPrefix(pub, "PUB solana.failover "))
+
+	var event Event
+	require.NoError(t, json.Unmarshal([]byte(<-received), &event))
+	assert.Equal(t, TypeComplete, event.Type)
+	assert.True(t, event.IsDryRun)
+}
+
+func TestPublisher_NATSError(t *testing.T) {
+	addr := listen(t, func(conn net.Conn) {
+		_, _ = conn.Write([]byte("INFO {}\r\n"))
+		_, _ = bufio.NewReader(conn).ReadString('\n')
+		_, _ = conn.Write([]byte("-ERR 'Authorization Violation'\r\n"))
+	})
+
+	p, err := NewPublisher(Config{URL: "nats://" + addr, Topic: "solana.failover"})
+	require.NoError(t, err)
+
+	err = p.publish(Event{Type: TypeAbort})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Authorization Violation")
+}
+
+func TestPublisher_MQTT(t *testing.T) {
+	received := make(chan []byte, 1)
+	addr := listen(t, func(conn net.Conn) {
+		reader := bufio.NewReader(conn)
+
+		// CONNECT
+		header, _ := reader.ReadByte()
+		if header != mqttPacketConnect {
+			return
+		}
+		if _, err := io.CopyN(io.Discard, reader, int64(readRemainingLength(reader))); err != nil {
+			return
+		}
+		_, _ = conn.Write([]byte{mqttPacketConnack, 2, 0, 0})
+
+		// PUBLISH
+		header, _ = reader.ReadByte()
+		if header != mqttPacketPublish {
+			return
+		}
+		body := make([]byte, readRemainingLength(reader))
+		if _, err := io.ReadFull(reader, body); err != nil {
+			return
+		}
+		received <- body
+	})
+
+	p, err := NewPublisher(Config{URL: "mqtt://" + addr, Topic: "failover", Username: "bob", Password: "pw"})
+	require.NoError(t, err)
+
+	err = p.publish(Event{Type: TypeStart})
+	require.NoError(t, err)
+
+	body := <-received
+	topicLength := int(body[0])<<8 | int(body[1])
+	assert.Equal(t, "failover", string(body[2:2+topicLength]))
+
+	var event Event
+	require.NoError(t, json.Unmarshal(body[2+topicLength:], &event))
+	assert.Equal(t, TypeStart, event.Type)
+}
+
+func TestPublisher_MQTTRefused(t *testing.T) {
+	addr := listen(t, func(conn net.Conn) {
+		_, _ = conn.Read(make([]byte, 256))
+		_, _ = conn.Write([]byte{mqttPacketConnack, 2, 0, 5})
+	})
+
+	p, err := NewPublisher(Config{URL: "mqtt://" + addr, Topic: "failover"})
+	require.NoError(t, err)
+
+	err = p.publish(Event{Type: TypeStart})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "return code 5")
+}
+
+func TestMQTTRemainingLength(t *testing.T) {
+	assert.Equal(t, []byte{0x00}, mqttRemainingLength(0))
+	assert.Equal(t, []byte{0x7f}, mqttRemainingLength(127))
+	assert.Equal(t, []byte{0x80, 0x01}, mqttRemainingLength(128))
+	assert.Equal(t, []byte{0xff, 0x7f}, mqttRemainingLength(16383))
+}
@@ -0,0 +1,147 @@
+package events
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	mqttPacketConnect    = 0x10
+	mqttPacketConnack    = 0x20
+	mqttPacketPublish    = 0x30
+	mqttPacketDisconnect = 0xe0
+
+	mqttConnectFlagCleanSession = 0x02
+	mqttConnectFlagPassword     = 0x40
+	mqttConnectFlagUsername     = 0x80
+
+	mqttKeepAliveSeconds = 30
+)
+
+// mqttConnackReturnCodes are the reasons a broker refuses a connection in its CONNACK
+var mqttConnackReturnCodes = map[byte]string{
+	1: "unacceptable protocol version",
+	2: "client identifier rejected",
+	3: "server unavailable",
+	4: "bad username or password",
+	5: "not authorized",
+}
+
+// publishMQTT publishes a payload at QoS 0 using mqtt 3.1.1 - CONNECT, wait for CONNACK, PUBLISH then DISCONNECT
+func (p *Publisher) publishMQTT(payload []byte) error {
+	deadline := time.Now().Add(p.timeout)
+	dialer := &net.Dialer{Timeout: p.timeout}
+
+	var (
+		conn net.Conn
+		err  error
+	)
+	if p.brokerURL.Scheme == "mqtts" {
+		conn, err = tls.DialWithDialer(dialer, "tcp", p.brokerURL.Host, &tls.Config{ServerName: p.brokerURL.Hostname()})
+	} else {
+		conn, err = dialer.Dial("tcp", p.brokerURL.Host)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", p.brokerURL.Host, err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	if _, err := conn.Write(p.mqttConnectPacket()); err != nil {
+		return fmt.Errorf("failed to send CONNECT: %w", err)
+	}
+
+	connack := make([]byte, 4)
+	if _, err := io.ReadFull(conn, connack); err != nil {
+		return fmt.Errorf("failed to read CONNACK: %w", err)
+	}
+	if connack[0] != mqttPacketConnack || connack[1] != 2 {
+		return fmt.Errorf("unexpected packet 0x%x waiting for CONNACK", connack[:2])
+	}
+	if connack[3] != 0 {
+		return fmt.Errorf("broker refused connection with return code %d (%s)", connack[3], mqttConnackReturnCodes[connack[3]])
+	}
+
+	if _, err := conn.Write(mqttPublishPacket(p.topic, payload)); err != nil {
+		return fmt.Errorf("failed to send PUBLISH: %w", err)
+	}
+
+	if _, err := conn.Write([]byte{mqttPacketDisconnect, 0}); err != nil {
+		return fmt.Errorf("failed to send DISCONNECT: %w", err)
+	}
+
+	return nil
+}
+
+// mqttConnectPacket builds a CONNECT packet with a clean session and optional credentials - Validate ensures there is
+// no password without a username, which mqtt 3.1.1 doesn't allow
+func (p *Publisher) mqttConnectPacket() []byte {
+	flags := byte(mqttConnectFlagCleanSession)
+	if p.username != "" {
+		flags |= mqttConnectFlagUsername
+	}
+	if p.password != "" {
+		flags |= mqttConnectFlagPassword
+	}
+
+	var body bytes.Buffer
+	body.Write(mqttString("MQTT"))
+	body.WriteByte(4) // protocol level 3.1.1
+	body.WriteByte(flags)
+	_ = binary.Write(&body, binary.BigEndian, uint16(mqttKeepAliveSeconds))
+	// client ids of up to 23 characters must be accepted by every broker
+	body.Write(mqttString("svf-" + strconv.FormatInt(time.Now().UnixNano(), 36)))
+	if p.username != "" {
+		body.Write(mqttString(p.username))
+	}
+	if p.password != "" {
+		body.Write(mqttString(p.password))
+	}
+
+	return mqttPacket(mqttPacketConnect, body.Bytes())
+}
+
+// mqttPublishPacket builds a QoS 0 PUBLISH packet
+func mqttPublishPacket(topic string, payload []byte) []byte {
+	var body bytes.Buffer
+	body.Write(mqttString(topic))
+	body.Write(payload)
+	return mqttPacket(mqttPacketPublish, body.Bytes())
+}
+
+// mqttPacket prefixes a packet body with its fixed header
+func mqttPacket(packetType byte, body []byte) []byte {
+	packet := []byte{packetType}
+	packet = append(packet, mqttRemainingLength(len(body))...)
+	return append(packet, body...)
+}
+
+// mqttRemainingLength encodes a length as an mqtt variable byte integer
+func mqttRemainingLength(length int) (encoded []byte) {
+	for {
+		digit := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			digit |= 0x80
+		}
+		encoded = append(encoded, digit)
+		if length == 0 {
+			return encoded
+		}
+	}
+}
+
+// mqttString encodes a length-prefixed utf-8 string
+func mqttString(s string) []byte {
+	encoded := make([]byte, 2, 2+len(s))
+	binary.BigEndian.PutUint16(encoded, uint16(len(s)))
+	return append(encoded, s...)
+}
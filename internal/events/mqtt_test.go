@@ -0,0 +1,242 @@
+package events
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mqttSession is what a fake mqtt broker received from a publisher
+type mqttSession struct {
+	protocolName  string
+	protocolLevel byte
+	flags         byte
+	keepAlive     uint16
+	clientID      string
+	username      string
+	password      string
+	topic         string
+	payload       []byte
+	disconnected  bool
+	err           error
+}
+
+// mqttBroker starts a fake mqtt 3.1.1 broker that decodes CONNECT strictly, answers it with returnCode and reads
+// the PUBLISH and DISCONNECT that follow an accepted connection
+func mqttBroker(t *testing.T, returnCode byte) (addr string, sessions <-chan mqttSession) {
+	received := make(chan mqttSession, 1)
+	addr = listen(t, func(conn net.Conn) {
+		session, err := readMQTTSession(conn, returnCode)
+		session.err = err
+		received <- session
+	})
+	return addr, received
+}
+
+// readMQTTSession reads a publisher's session as a broker would
+func readMQTTSession(conn net.Conn, returnCode byte) (session mqttSession, err error) {
+	reader := bufio.NewReader(conn)
+
+	header, body, err := readMQTTPacket(reader)
+	if err != nil {
+		return session, err
+	}
+	if header != mqttPacketConnect {
+		return session, fmt.Errorf("expected CONNECT, got 0x%x", header)
+	}
+	if session.protocolName, body, err = readMQTTString(body); err != nil {
+		return session, err
+	}
+	if len(body) < 4 {
+		return session, fmt.Errorf("CONNECT variable header is %d bytes", len(body))
+	}
+	session.protocolLevel, session.flags = body[0], body[1]
+	session.keepAlive = binary.BigEndian.Uint16(body[2:4])
+	body = body[4:]
+	if session.flags&0x01 != 0 {
+		return session, fmt.Errorf("reserved CONNECT flag set")
+	}
+	if session.flags&mqttConnectFlagPassword != 0 && session.flags&mqttConnectFlagUsername == 0 {
+		return session, fmt.Errorf("password flag set without username flag")
+	}
+	if session.clientID, body, err = readMQTTString(body); err != nil {
+		return session, err
+	}
+	if session.flags&mqttConnectFlagUsername != 0 {
+		if session.username, body, err = readMQTTString(body); err != nil {
+			return session, err
+		}
+	}
+	if session.flags&mqttConnectFlagPassword != 0 {
+		if session.password, body, err = readMQTTString(body); err != nil {
+			return session, err
+		}
+	}
+	if len(body) != 0 {
+		return session, fmt.Errorf("%d bytes left over in CONNECT", len(body))
+	}
+
+	if _, err := conn.Write([]byte{mqttPacketConnack, 2, 0, returnCode}); err != nil {
+		return session, err
+	}
+	if returnCode != 0 {
+		return session, nil
+	}
+
+	header, body, err = readMQTTPacket(reader)
+	if err != nil {
+		return session, err
+	}
+	if header != mqttPacketPublish {
+		return session, fmt.Errorf("expected a QoS 0 PUBLISH, got 0x%x", header)
+	}
+	if session.topic, session.payload, err = readMQTTString(body); err != nil {
+		return session, err
+	}
+
+	header, body, err = readMQTTPacket(reader)
+	if err != nil {
+		return session, err
+	}
+	session.disconnected = header == mqttPacketDisconnect && len(body) == 0
+	return session, nil
+}
+
+// readMQTTPacket reads a packet's fixed header and body
+func readMQTTPacket(reader *bufio.Reader) (header byte, body []byte, err error) {
+	if header, err = reader.ReadByte(); err != nil {
+		return 0, nil, err
+	}
+	body = make([]byte, readRemainingLength(reader))
+	_, err = io.ReadFull(reader, body)
+	return header, body, err
+}
+
+// readMQTTString reads a length-prefixed string off the front of b
+func readMQTTString(b []byte) (s string, rest []byte, err error) {
+	if len(b) < 2 {
+		return "", nil, fmt.Errorf("string length missing")
+	}
+	length := int(binary.BigEndian.Uint16(b))
+	if len(b) < 2+length {
+		return "", nil, fmt.Errorf("string of %d bytes truncated", length)
+	}
+	return string(b[2 : 2+length]), b[2+length:], nil
+}
+
+func TestPublishMQTT_Session(t *testing.T) {
+	addr, sessions := mqttBroker(t, 0)
+	p, err := NewPublisher(Config{URL: "mqtt://" + addr, Topic: "solana/failover", Username: "bob", Password: "pw"})
+	require.NoError(t, err)
+
+	require.NoError(t, p.publish(Event{Type: TypeComplete, FailoverID: "failover-1"}))
+
+	session := <-sessions
+	require.NoError(t, session.err)
+	assert.Equal(t, "MQTT", session.protocolName)
+	assert.Equal(t, byte(4), session.protocolLevel)
+	assert.Equal(t, byte(mqttConnectFlagCleanSession|mqttConnectFlagUsername|mqttConnectFlagPassword), session.flags)
+	assert.Equal(t, uint16(mqttKeepAliveSeconds), session.keepAlive)
+	assert.NotEmpty(t, session.clientID)
+	assert.LessOrEqual(t, len(session.clientID), 23)
+	assert.Equal(t, "bob", session.username)
+	assert.Equal(t, "pw", session.password)
+
+	assert.Equal(t, "solana/failover", session.topic)
+	var event Event
+	require.NoError(t, json.Unmarshal(session.payload, &event))
+	assert.Equal(t, TypeComplete, event.Type)
+	assert.Equal(t, "failover-1", event.FailoverID)
+	assert.True(t, session.disconnected)
+}
+
+func TestPublishMQTT_Anonymous(t *testing.T) {
+	addr, sessions := mqttBroker(t, 0)
+	p, err := NewPublisher(Config{URL: "mqtt://" + addr, Topic: "failover"})
+	require.NoError(t, err)
+
+	require.NoError(t, p.publish(Event{Type: TypeStart}))
+
+	session := <-sessions
+	require.NoError(t, session.err)
+	assert.Equal(t, byte(mqttConnectFlagCleanSession), session.flags)
+	assert.Empty(t, session.username)
+}
+
+func TestPublishMQTT_CredentialsFromURL(t *testing.T) {
+	addr, sessions := mqttBroker(t, 0)
+	p, err := NewPublisher(Config{URL: "mqtt://alice:secret@" + addr, Topic: "failover"})
+	require.NoError(t, err)
+
+	require.NoError(t, p.publish(Event{Type: TypeStart}))
+
+	session := <-sessions
+	require.NoError(t, session.err)
+	assert.Equal(t, "alice", session.username)
+	assert.Equal(t, "secret", session.password)
+}
+
+func TestPublishMQTT_LargePayload(t *testing.T) {
+	addr, sessions := mqttBroker(t, 0)
+	p, err := NewPublisher(Config{URL: "mqtt://" + addr, Topic: "failover"})
+	require.NoError(t, err)
+
+	// a remaining length needing more than one byte
+	summary := string(make([]byte, 20000))
+	require.NoError(t, p.publish(Event{Type: TypeComplete, Summary: summary}))
+
+	session := <-sessions
+	require.NoError(t, session.err)
+	var event Event
+	require.NoError(t, json.Unmarshal(session.payload, &event))
+	assert.Equal(t, summary, event.Summary)
+}
+
+func TestPublishMQTT_AuthRefused(t *testing.T) {
+	tests := []struct {
+		returnCode byte
+		want       string
+	}{
+		{4, "return code 4 (bad username or password)"},
+		{5, "return code 5 (not authorized)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			addr, sessions := mqttBroker(t, tt.returnCode)
+			p, err := NewPublisher(Config{URL: "mqtt://" + addr, Topic: "failover", Username: "bob", Password: "wrong"})
+			require.NoError(t, err)
+
+			assert.ErrorContains(t, p.publish(Event{Type: TypeStart}), tt.want)
+			require.NoError(t, (<-sessions).err)
+		})
+	}
+}
+
+func TestPublishMQTT_NotABroker(t *testing.T) {
+	addr := listen(t, func(conn net.Conn) {
+		_, _ = conn.Read(make([]byte, 256))
+		_, _ = conn.Write([]byte("HTTP/1.1 400 Bad Request\r\n\r\n"))
+	})
+	p, err := NewPublisher(Config{URL: "mqtt://" + addr, Topic: "failover"})
+	require.NoError(t, err)
+
+	assert.ErrorContains(t, p.publish(Event{Type: TypeStart}), "waiting for CONNACK")
+}
+
+func TestConfig_ValidateMQTTPasswordWithoutUsername(t *testing.T) {
+	err := Config{URL: "mqtt://broker:1883", Topic: "failover", Password: "pw"}.Validate()
+	assert.ErrorContains(t, err, "username is required with a password for mqtt")
+
+	err = Config{URL: "mqtt://:pw@broker:1883", Topic: "failover"}.Validate()
+	assert.ErrorContains(t, err, "username is required with a password for mqtt")
+
+	// nats servers take either
+	assert.NoError(t, Config{URL: "nats://broker:4222", Topic: "failover", Password: "pw"}.Validate())
+}
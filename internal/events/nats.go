@@ -0,0 +1,98 @@
+package events
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-failover/pkg/constants"
+)
+
+// natsConnectOptions are the options sent in a nats CONNECT
+type natsConnectOptions struct {
+	Verbose   bool   `json:"verbose"`
+	Pedantic  bool   `json:"pedantic"`
+	Name      string `json:"name"`
+	Lang      string `json:"lang"`
+	Version   string `json:"version"`
+	User      string `json:"user,omitempty"`
+	Pass      string `json:"pass,omitempty"`
+	AuthToken string `json:"auth_token,omitempty"`
+}
+
+// publishNATS publishes a payload using the nats text protocol - INFO, CONNECT, PUB then PING/PONG to
+// confirm the server accepted it
+func (p *Publisher) publishNATS(payload []byte) error {
+	deadline := time.Now().Add(p.timeout)
+
+	conn, err := net.DialTimeout("tcp", p.brokerURL.Host, p.timeout)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", p.brokerURL.Host, err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	// the server always opens with INFO
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("failed to read INFO: %w", err)
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		return fmt.Errorf("unexpected greeting from nats server: %s", strings.TrimSpace(line))
+	}
+
+	// upgrade to tls after INFO
+	if p.brokerURL.Scheme == "tls" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: p.brokerURL.Hostname()})
+		if err := tlsConn.SetDeadline(deadline); err != nil {
+			return err
+		}
+		if err := tlsConn.Handshake(); err != nil {
+			return fmt.Errorf("tls handshake failed: %w", err)
+		}
+		conn = tlsConn
+		reader = bufio.NewReader(conn)
+	}
+
+	connectOptions, err := json.Marshal(natsConnectOptions{
+		Name:      constants.AppName,
+		Lang:      "go",
+		Version:   strings.TrimSpace(constants.AppVersion),
+		User:      p.username,
+		Pass:      p.password,
+		AuthToken: p.token,
+	})
+	if err != nil {
+		return err
+	}
+
+	var message strings.Builder
+	fmt.Fprintf(&message, "CONNECT %s\r\n", connectOptions)
+	fmt.Fprintf(&message, "PUB %s %d\r\n%s\r\n", p.topic, len(payload), payload)
+	message.WriteString("PING\r\n")
+	if _, err := conn.Write([]byte(message.String())); err != nil {
+		return fmt.Errorf("failed to publish: %w", err)
+	}
+
+	// errors (e.g. authorization) arrive before the PONG
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("failed to confirm publish: %w", err)
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PONG":
+			return nil
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("nats server error: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
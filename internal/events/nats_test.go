@@ -0,0 +1,173 @@
+package events
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// natsSession is what a fake nats server received from a publisher
+type natsSession struct {
+	connect natsConnectOptions
+	subject string
+	payload []byte
+	err     error
+}
+
+// natsServer starts a fake nats server that greets with INFO and reads CONNECT strictly - when authorize refuses the
+// CONNECT options it answers with an authorization error and hangs up as a nats server does, otherwise it reads the
+// PUB and answers the PING
+func natsServer(t *testing.T, authorize func(natsConnectOptions) bool) (addr string, sessions <-chan natsSession) {
+	received := make(chan natsSession, 1)
+	addr = listen(t, func(conn net.Conn) {
+		session, err := readNATSSession(conn, authorize)
+		session.err = err
+		received <- session
+	})
+	return addr, received
+}
+
+// readNATSSession reads a publisher's session as a nats server would
+func readNATSSession(conn net.Conn, authorize func(natsConnectOptions) bool) (session natsSession, err error) {
+	if _, err := conn.Write([]byte(`INFO {"server_id":"test","version":"2.10.0","auth_required":true,"max_payload":1048576}` + "\r\n")); err != nil {
+		return session, err
+	}
+	reader := bufio.NewReader(conn)
+
+	line, err := readNATSLine(reader)
+	if err != nil {
+		return session, err
+	}
+	options, ok := strings.CutPrefix(line, "CONNECT ")
+	if !ok {
+		return session, fmt.Errorf("expected CONNECT, got %q", line)
+	}
+	decoder := json.NewDecoder(strings.NewReader(options))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&session.connect); err != nil {
+		return session, fmt.Errorf("invalid CONNECT options: %w", err)
+	}
+	if !authorize(session.connect) {
+		_, err := conn.Write([]byte("-ERR 'Authorization Violation'\r\n"))
+		return session, err
+	}
+
+	line, err = readNATSLine(reader)
+	if err != nil {
+		return session, err
+	}
+	fields := strings.Fields(line)
+	if len(fields) != 3 || fields[0] != "PUB" {
+		return session, fmt.Errorf("expected PUB <subject> <size>, got %q", line)
+	}
+	session.subject = fields[1]
+	size, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return session, fmt.Errorf("invalid PUB size %q", fields[2])
+	}
+	payload := make([]byte, size+2)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		return session, err
+	}
+	if string(payload[size:]) != "\r\n" {
+		return session, fmt.Errorf("payload longer than its PUB size %d", size)
+	}
+	session.payload = payload[:size]
+
+	if line, err = readNATSLine(reader); err != nil || line != "PING" {
+		return session, fmt.Errorf("expected PING, got %q: %v", line, err)
+	}
+	_, err = conn.Write([]byte("PONG\r\n"))
+	return session, err
+}
+
+// readNATSLine reads a protocol line, which must end in CRLF
+func readNATSLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasSuffix(line, "\r\n") {
+		return "", fmt.Errorf("line %q doesn't end in CRLF", line)
+	}
+	return strings.TrimSuffix(line, "\r\n"), nil
+}
+
+func TestPublishNATS_Session(t *testing.T) {
+	addr, sessions := natsServer(t, func(options natsConnectOptions) bool {
+		return options.User == "alice" && options.Pass == "secret"
+	})
+	p, err := NewPublisher(Config{URL: "nats://" + addr, Topic: "solana.failover", Username: "alice", Password: "secret"})
+	require.NoError(t, err)
+
+	require.NoError(t, p.publish(Event{Type: TypeComplete, FailoverID: "failover-1"}))
+
+	session := <-sessions
+	require.NoError(t, session.err)
+	assert.False(t, session.connect.Verbose)
+	assert.False(t, session.connect.Pedantic)
+	assert.Equal(t, "go", session.connect.Lang)
+	assert.NotEmpty(t, session.connect.Name)
+	assert.Empty(t, session.connect.AuthToken)
+
+	assert.Equal(t, "solana.failover", session.subject)
+	var event Event
+	require.NoError(t, json.Unmarshal(session.payload, &event))
+	assert.Equal(t, TypeComplete, event.Type)
+	assert.Equal(t, "failover-1", event.FailoverID)
+}
+
+func TestPublishNATS_Token(t *testing.T) {
+	addr, sessions := natsServer(t, func(options natsConnectOptions) bool {
+		return options.AuthToken == "s3cret"
+	})
+	p, err := NewPublisher(Config{URL: "nats://" + addr, Topic: "solana.failover", Token: "s3cret"})
+	require.NoError(t, err)
+
+	require.NoError(t, p.publish(Event{Type: TypeStart}))
+
+	session := <-sessions
+	require.NoError(t, session.err)
+	assert.Empty(t, session.connect.User)
+	assert.Empty(t, session.connect.Pass)
+}
+
+func TestPublishNATS_AuthorizationViolation(t *testing.T) {
+	addr, sessions := natsServer(t, func(options natsConnectOptions) bool {
+		return options.AuthToken == "s3cret"
+	})
+	p, err := NewPublisher(Config{URL: "nats://" + addr, Topic: "solana.failover", Token: "wrong"})
+	require.NoError(t, err)
+
+	assert.ErrorContains(t, p.publish(Event{Type: TypeStart}), "nats server error: 'Authorization Violation'")
+	require.NoError(t, (<-sessions).err)
+}
+
+func TestPublishNATS_ClosedBeforePong(t *testing.T) {
+	addr := listen(t, func(conn net.Conn) {
+		_, _ = conn.Write([]byte("INFO {}\r\n"))
+		_, _ = bufio.NewReader(conn).ReadString('\n')
+	})
+	p, err := NewPublisher(Config{URL: "nats://" + addr, Topic: "solana.failover"})
+	require.NoError(t, err)
+
+	assert.ErrorContains(t, p.publish(Event{Type: TypeStart}), "failed to confirm publish")
+}
+
+func TestPublishNATS_NotANATSServer(t *testing.T) {
+	addr := listen(t, func(conn net.Conn) {
+		_, _ = conn.Write([]byte("SSH-2.0-OpenSSH_9.6\r\n"))
+	})
+	p, err := NewPublisher(Config{URL: "nats://" + addr, Topic: "solana.failover"})
+	require.NoError(t, err)
+
+	assert.ErrorContains(t, p.publish(Event{Type: TypeStart}), "unexpected greeting from nats server: SSH-2.0-OpenSSH_9.6")
+}
@@ -0,0 +1,327 @@
+package failover
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/sol-strategies/solana-validator-failover/internal/identities"
+)
+
+const (
+	// AbortErrorCode is the application error code a node closes the failover connection with to abort a pending
+	// failover - the close reason says why
+	AbortErrorCode quic.ApplicationErrorCode = 0xab
+
+	// abortResponseTimeout is how long an abort requester has to read the response before the server stops
+	abortResponseTimeout = 3 * time.Second
+)
+
+// AbortRequest asks a passive node's failover server to abort its pending failover - sent after authenticating on a
+// stream opened with MessageTypeAbortRequest
+type AbortRequest struct {
+	Hostname string
+	Reason   string
+}
+
+// AbortResponse is the passive node's answer to an abort request - Error is empty when the failover was aborted
+type AbortResponse struct {
+	Error string
+}
+
+// AbortConfig is the configuration for asking a passive node's failover server to abort its pending failover
+type AbortConfig struct {
+	ServerName    string
+	ServerAddress string
+	// ActiveIdentity is the validator's active identity keypair - proven like the active node does
+	ActiveIdentity    *identities.Identity
+	ClientCertificate *tls.Certificate
+	Request           AbortRequest
+}
+
+// pendingAbort tracks whether a failover can still be aborted - until the active node begins setting its identity
+// neither node has changed anything the other relies on - and makes sure it is aborted at most once
+type pendingAbort struct {
+	mutex  sync.Mutex
+	armed  bool
+	reason string
+}
+
+// arm allows the failover to be aborted - forgetting any earlier abort
+func (p *pendingAbort) arm() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.armed = true
+	p.reason = ""
+}
+
+// commit stops the failover being aborted from here on - it returns the abort's error when it already was
+func (p *pendingAbort) commit() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.armed = false
+	return p.errLocked()
+}
+
+// abort aborts the failover for reason - false when it can't be aborted or already was
+func (p *pendingAbort) abort(reason string) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if !p.armed {
+		return false
+	}
+	p.armed = false
+	p.reason = reason
+	return true
+}
+
+// err returns why the failover was aborted - nil when it wasn't
+func (p *pendingAbort) err() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.errLocked()
+}
+
+// errLocked is err with the mutex held
+func (p *pendingAbort) errLocked() error {
+	if p.reason == "" {
+		return nil
+	}
+	return fmt.Errorf("failover aborted: %s", p.reason)
+}
+
+// closeWithAbort closes the failover connection telling the peer the failover is aborted and why
+func closeWithAbort(conn quic.Connection, reason string) {
+	if conn == nil {
+		return
+	}
+	_ = conn.CloseWithError(AbortErrorCode, reason)
+}
+
+// peerAbortReason returns why the peer aborted the failover when err is it closing the connection to do so
+func peerAbortReason(err error) (reason string, ok bool) {
+	var appErr *quic.ApplicationError
+	if !errors.As(err, &appErr) || !appErr.Remote || appErr.ErrorCode != AbortErrorCode {
+		return "", false
+	}
+	return appErr.ErrorMessage, true
+}
+
+// connAbortReason returns why the peer aborted the failover when it has closed conn to do so
+func connAbortReason(conn quic.Connection) (reason string, ok bool) {
+	if conn == nil {
+		return "", false
+	}
+	return peerAbortReason(context.Cause(conn.Context()))
+}
+
+// abort aborts the failover for reason when it still can be, telling the passive node by closing the connection
+func (c *Client) abort(reason string) {
+	if !c.pendingAbort.abort(reason) {
+		return
+	}
+	c.logger.Warn().Msgf("🛑 Aborting the failover before switching identity: %s", reason)
+	closeWithAbort(c.Conn, reason)
+}
+
+// exitIfPeerAborted exits without switching identity when err, or the connection closing, is the passive node
+// aborting the failover - nothing has changed on this node yet
+func (c *Client) exitIfPeerAborted(err error) {
+	reason, ok := peerAbortReason(err)
+	if !ok {
+		reason, ok = connAbortReason(c.Conn)
+	}
+	if !ok || !c.pendingAbort.abort(reason) {
+		return
+	}
+	c.notifyAbort(fmt.Sprintf("%s aborted the failover: %s", c.serverName, reason))
+	c.logger.Fatal().Msgf("🛑 %s aborted the failover before this node switched identity - nothing was changed: %s", c.serverName, reason)
+}
+
+// abortFailover aborts the pending failover for reason when it still can be - telling the active node by closing its
+// connection, putting back the tower file this node opened for it and reporting the abort - false when it can't be. The
+// server is stopped once the abort is reported
+func (s *Server) abortFailover(reason string) bool {
+	if !s.abortPendingFailover(reason) {
+		return false
+	}
+	s.stop()
+	return true
+}
+
+// abortPendingFailover is abortFailover without stopping the server
+func (s *Server) abortPendingFailover(reason string) bool {
+	if !s.pendingAbort.abort(reason) {
+		return false
+	}
+	s.logger.Warn().Msgf("🛑 Aborting the failover: %s", reason)
+	closeWithAbort(s.activeConn, reason)
+	s.rollbackTowerFile()
+	// nothing to report while still waiting for the active node
+	if s.handshakeReceived() {
+		s.publishAbortEvent(reason)
+	}
+	return true
+}
+
+// stopIfAborted returns true when the failover was aborted - handling the active node aborting it when err, or the
+// connection closing, says it did
+func (s *Server) stopIfAborted(err error) bool {
+	reason, ok := peerAbortReason(err)
+	if !ok {
+		reason, ok = connAbortReason(s.activeConn)
+	}
+	if ok && s.pendingAbort.abort(reason) {
+		s.logger.Error().Msgf("🛑 %s aborted the failover before switching identity - nothing was changed: %s", s.failoverStream.GetActiveNodeInfo().Hostname, reason)
+		s.rollbackTowerFile()
+		s.publishAbortEvent(fmt.Sprintf("%s aborted the failover: %s", s.failoverStream.GetActiveNodeInfo().Hostname, reason))
+		s.stop()
+	}
+	return s.pendingAbort.err() != nil
+}
+
+// watchPeerAbort handles the active node aborting the failover while this node is busy with something other than
+// reading from it, e.g. waiting for the operator to confirm, until the returned func is called
+func (s *Server) watchPeerAbort() (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-s.activeConn.Context().Done():
+			s.stopIfAborted(nil)
+		case <-done:
+		}
+	}()
+	return func() { close(done) }
+}
+
+// towerFileRollback is how to put back the tower file this node opens for the failover - opening it truncates it and
+// it stays empty until the tower file arrives, which can no longer happen once the failover is aborted
+type towerFileRollback struct {
+	path string
+	// backup is the tower file's backup - empty when backups are disabled or there was nothing to back up
+	backup string
+	// existed and contents are the tower file as it was when there is no backup of it
+	existed  bool
+	contents []byte
+}
+
+// prepareTowerFileRollback backs up the tower file about to be opened for the failover and records how to put it back
+// if the failover is aborted
+func (s *Server) prepareTowerFileRollback(towerFile string) error {
+	backup, err := BackupTowerFile(towerFile, s.towerBackup, s.logger)
+	if err != nil {
+		return err
+	}
+	rollback := &towerFileRollback{path: towerFile, backup: backup, existed: backup != ""}
+	// with backups disabled, or an empty tower file not worth backing up, keep what there was to put back
+	if backup == "" {
+		rollback.contents, err = os.ReadFile(towerFile)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to read tower file %s: %w", towerFile, err)
+		}
+		rollback.existed = err == nil
+	}
+	s.towerFileRollback = rollback
+	return nil
+}
+
+// rollbackTowerFile puts back the tower file this node opened for the aborted failover - restored from its backup, or
+// removed when there was no tower file before
+func (s *Server) rollbackTowerFile() {
+	rollback := s.towerFileRollback
+	if rollback == nil {
+		return
+	}
+	s.towerFileRollback = nil
+
+	if !rollback.existed {
+		if err := os.Remove(rollback.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			s.logger.Error().Err(err).Msgf("failed to remove tower file %s opened for the aborted failover", rollback.path)
+			return
+		}
+		s.logger.Debug().Str("tower_file", rollback.path).Msg("removed tower file opened for the aborted failover")
+		return
+	}
+
+	contents := rollback.contents
+	if rollback.backup != "" {
+		var err error
+		if contents, err = os.ReadFile(rollback.backup); err != nil {
+			s.logger.Error().Err(err).Msgf("failed to read tower file backup %s - copy it to %s to restore the tower file", rollback.backup, rollback.path)
+			return
+		}
+	}
+	if err := os.WriteFile(rollback.path, contents, 0644); err != nil {
+		s.logger.Error().Err(err).Msgf("failed to restore tower file %s truncated for the aborted failover", rollback.path)
+		return
+	}
+	s.logger.Info().Str("tower_file", rollback.path).Str("backup", rollback.backup).Msg("restored tower file truncated for the aborted failover")
+}
+
+// handshakeReceived returns true once the active node has sent its info
+func (s *Server) handshakeReceived() bool {
+	return s.failoverStream != nil && s.failoverStream.GetActiveNodeInfo() != nil
+}
+
+// handleAbortStream has a node prove it holds the active identity keypair then aborts the pending failover as it
+// asks - the response is flushed before the server stops
+func (s *Server) handleAbortStream(conn quic.Connection, stream quic.Stream) {
+	abortStream := NewFailoverStream(stream)
+	if err := abortStream.AuthenticateAsPassive(s.identities.Active); err != nil {
+		s.logger.Error().Err(err).Str("remote_addr", conn.RemoteAddr().String()).Msg("🔒 abort requester authentication failed - ignoring abort request")
+		return
+	}
+
+	var request AbortRequest
+	if err := abortStream.decoder.Decode(&request); err != nil {
+		s.logger.Error().Err(err).Msg("failed to read abort request")
+		return
+	}
+
+	var response AbortResponse
+	aborted := s.abortPendingFailover(fmt.Sprintf("%s asked to abort: %s", request.Hostname, request.Reason))
+	if !aborted {
+		response.Error = "the failover can no longer be aborted - the active node has begun switching identity"
+	}
+	if err := abortStream.encoder.Encode(response); err != nil {
+		s.logger.Error().Err(err).Msg("failed to send abort response")
+	}
+	if !aborted {
+		return
+	}
+
+	// the requester hangs up once it has read the response - stopping closes every connection
+	select {
+	case <-conn.Context().Done():
+	case <-time.After(abortResponseTimeout):
+	}
+	s.stop()
+}
+
+// RequestAbort asks a passive node's failover server to abort its pending failover - while it waits for the active
+// node or before the active node begins setting its identity
+func RequestAbort(config AbortConfig) error {
+	conn, stream, err := dialAuthenticated(config.ServerName, config.ServerAddress, config.ActiveIdentity, config.ClientCertificate, QUICConfig{}, MessageTypeAbortRequest)
+	if err != nil {
+		return err
+	}
+	defer conn.CloseWithError(0, "abort request done")
+	defer stream.Stream.Close()
+
+	if err := stream.encoder.Encode(config.Request); err != nil {
+		return fmt.Errorf("failed to send abort request: %w", err)
+	}
+	var response AbortResponse
+	if err := stream.decoder.Decode(&response); err != nil {
+		return fmt.Errorf("failed to read abort response: %w", err)
+	}
+	if response.Error != "" {
+		return fmt.Errorf("%s didn't abort: %s", config.ServerName, response.Error)
+	}
+	return nil
+}
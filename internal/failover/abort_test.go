@@ -0,0 +1,146 @@
+package failover
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/sol-strategies/solana-validator-failover/internal/identities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAbortTestServer returns a passive node's server with a pending failover that can still be aborted - the tower
+// file it opens for the failover is in a temp dir
+func newAbortTestServer(t *testing.T, towerBackup TowerBackupConfig) (*Server, string) {
+	t.Helper()
+	s := &Server{
+		logger:          zerolog.Nop(),
+		observers:       newObserverHub(zerolog.Nop()),
+		passiveNodeInfo: &NodeInfo{Hostname: "passive"},
+		towerBackup:     towerBackup,
+	}
+	s.pendingAbort.arm()
+	return s, filepath.Join(t.TempDir(), "tower-1_9-identity.bin")
+}
+
+// openTowerFile opens the tower file for the failover as the passive node does once the failover is confirmed
+func openTowerFile(t *testing.T, s *Server, towerFile string) {
+	t.Helper()
+	require.NoError(t, s.prepareTowerFileRollback(towerFile))
+	file, err := os.OpenFile(towerFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+}
+
+func TestAbort_BeforeConfirm(t *testing.T) {
+	s, towerFile := newAbortTestServer(t, TowerBackupConfig{Enabled: true})
+	require.NoError(t, os.WriteFile(towerFile, []byte("tower"), 0644))
+
+	assert.True(t, s.abortPendingFailover("operator asked"))
+	assert.False(t, s.abortPendingFailover("again"))
+
+	// nothing was opened so the tower file is as it was
+	towerFileBytes, err := os.ReadFile(towerFile)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("tower"), towerFileBytes)
+	assert.ErrorContains(t, s.pendingAbort.commit(), "failover aborted: operator asked")
+}
+
+func TestAbort_AfterConfirm(t *testing.T) {
+	tests := []struct {
+		name        string
+		towerBackup TowerBackupConfig
+		existing    []byte
+	}{
+		{"restored from backup", TowerBackupConfig{Enabled: true}, []byte("tower")},
+		{"restored without backups", TowerBackupConfig{}, []byte("tower")},
+		{"empty tower file kept", TowerBackupConfig{Enabled: true}, []byte{}},
+		{"no tower file before", TowerBackupConfig{Enabled: true}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, towerFile := newAbortTestServer(t, tt.towerBackup)
+			if tt.existing != nil {
+				require.NoError(t, os.WriteFile(towerFile, tt.existing, 0644))
+			}
+			openTowerFile(t, s, towerFile)
+
+			assert.True(t, s.abortPendingFailover("operator asked"))
+			assert.ErrorContains(t, s.pendingAbort.commit(), "failover aborted")
+
+			towerFileBytes, err := os.ReadFile(towerFile)
+			if tt.existing == nil {
+				assert.ErrorIs(t, err, os.ErrNotExist)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.existing, towerFileBytes)
+		})
+	}
+}
+
+// newTransferTestServer returns a server with a pending failover whose tower file is opened, waiting for the tower
+// file from the active node on the other end of the returned stream
+func newTransferTestServer(t *testing.T) (s *Server, active *Stream, towerFile string) {
+	t.Helper()
+	s, towerFile = newAbortTestServer(t, TowerBackupConfig{Enabled: true})
+	require.NoError(t, os.WriteFile(towerFile, []byte("tower"), 0644))
+	openTowerFile(t, s, towerFile)
+
+	passive, active, _ := newPipeStreams(t)
+	s.failoverStream = passive
+	s.identities = &identities.Identities{Active: newTestIdentity(t), Passive: newTestIdentity(t)}
+	nodeIdentities := NewNodeIdentities(s.identities)
+	s.passiveNodeInfo.Identities = nodeIdentities
+	s.failoverStream.message.ActiveNodeInfo = NodeInfo{Hostname: "active", Identities: nodeIdentities}
+	s.failoverStream.message.PassiveNodeInfo = NodeInfo{Hostname: "passive", Identities: nodeIdentities}
+
+	active.message.ActiveNodeInfo = NodeInfo{Hostname: "active", Identities: nodeIdentities, TowerFileWireSize: 2 * towerFileChunkSize}
+	return s, active, towerFile
+}
+
+func TestAbort_DuringTowerTransfer(t *testing.T) {
+	s, active, towerFile := newTransferTestServer(t)
+	towerFileBytes := make([]byte, 2*towerFileChunkSize)
+
+	received := make(chan error, 1)
+	go func() { received <- s.receiveTowerFile() }()
+
+	// the active node says it has switched identity and the passive node acknowledges the first chunk
+	require.NoError(t, active.encoder.Encode(active.message))
+	require.NoError(t, active.encoder.Encode(TowerFileChunk{Offset: 0, Bytes: towerFileBytes[:towerFileChunkSize]}))
+	var ack TowerFileChunkAck
+	require.NoError(t, active.decoder.Decode(&ack))
+
+	// too late to abort - the tower file is left for the transfer to fill
+	assert.False(t, s.abortPendingFailover("operator asked"))
+	onDisk, err := os.ReadFile(towerFile)
+	require.NoError(t, err)
+	assert.Empty(t, onDisk)
+
+	require.NoError(t, active.encoder.Encode(TowerFileChunk{Offset: towerFileChunkSize, Bytes: towerFileBytes[towerFileChunkSize:]}))
+	require.NoError(t, active.decoder.Decode(&ack))
+	require.NoError(t, <-received)
+	assert.NoError(t, s.pendingAbort.commit())
+	assert.Equal(t, towerFileBytes, s.failoverStream.GetActiveNodeInfo().TowerFileBytes)
+}
+
+func TestAbort_BeforeActiveNodeSwitched(t *testing.T) {
+	s, active, towerFile := newTransferTestServer(t)
+
+	received := make(chan error, 1)
+	go func() { received <- s.receiveTowerFile() }()
+
+	// aborted while waiting for the active node to switch identity
+	assert.True(t, s.abortPendingFailover("operator asked"))
+	onDisk, err := os.ReadFile(towerFile)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("tower"), onDisk)
+
+	// an active node that switched regardless isn't taken up on it
+	require.NoError(t, active.encoder.Encode(active.message))
+	assert.ErrorContains(t, <-received, "failover aborted: operator asked")
+	assert.Empty(t, s.failoverStream.GetActiveNodeInfo().TowerFileBytes)
+}
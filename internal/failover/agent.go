@@ -0,0 +1,417 @@
+package failover
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/cleanup"
+	"github.com/sol-strategies/solana-validator-failover/internal/identities"
+	"github.com/sol-strategies/solana-validator-failover/internal/peertrust"
+	"github.com/sol-strategies/solana-validator-failover/internal/ports"
+	"github.com/sol-strategies/solana-validator-failover/internal/utils"
+)
+
+// agentAcceptingInterval is the longest the agent waits for a request before going round its accept loop again
+const agentAcceptingInterval = time.Second
+
+// AgentRequest is sent by a passive node asking the agent on the active node to hand over to it
+type AgentRequest struct {
+	Hostname                       string
+	PublicIP                       string
+	PassivePubkey                  string
+	NoMinTimeToLeaderSlot          bool
+	NoWaitForHealthy               bool
+	SolanaValidatorFailoverVersion string
+	ProtocolRevision               int
+	MinProtocolRevision            int
+	// ServerPort is the port the requester's failover server listens on - the agent connects to it rather than the
+	// configured peer port when set e.g. after falling back to an ephemeral port
+	ServerPort int
+	// ProveIdentity is set when the requester proves it holds the validator's active identity keypair straight after
+	// the request - only then is it matched to a peer by the public IP it claims, not just the address it connects from
+	ProveIdentity bool
+}
+
+// AgentResponse is the agent's answer to a handover request
+type AgentResponse struct {
+	Accepted     bool
+	ErrorMessage string
+}
+
+// AgentConfig is the configuration for the failover agent
+type AgentConfig struct {
+	Port  int
+	Peers []PeerInfo
+	// PrepareHandover is called for a request from a configured peer before it is accepted - it returns the handover
+	// to run once the requester has been told, or an error to reject the request with
+	PrepareHandover func(peer PeerInfo, request AgentRequest) (handover func() error, err error)
+	// TLSCertificate is this node's persisted certificate peers pin - an ephemeral one is generated when nil
+	TLSCertificate *tls.Certificate
+	// ClientCAs requires requesters to present a client certificate it issued for a configured peer name - nil
+	// matches requesters to peers by IP
+	ClientCAs *x509.CertPool
+	// FileTransfer is where peers may push files to - disabled when it has no allowed dirs
+	FileTransfer FileTransferConfig
+	// Hostname is this node's hostname, sent with its config view
+	Hostname string
+	// ConfigView is the config shared with peers asking for it to catch drift - nil shares none
+	ConfigView ConfigView
+	// TowerReplica is where the tower replica the active peer ships is kept - disabled when it has no path
+	TowerReplica TowerReplicaConfig
+	// OnListening is called once the agent listens for requests - nil calls nothing
+	OnListening func()
+	// OnAccepting is called each time the agent goes back to accepting requests, and at least every
+	// agentAcceptingInterval while it waits for one - nil calls nothing
+	OnAccepting func()
+	// ActiveIdentity is the validator's active identity requesters prove they hold - without it only requesters
+	// presenting a verified client certificate can push files
+	ActiveIdentity *identities.Identity
+}
+
+// Agent is the failover agent - run by the active node so a passive node can initiate a failover from its side
+type Agent struct {
+	port            int
+	tlsConfig       *tls.Config
+	peers           []PeerInfo
+	prepareHandover func(peer PeerInfo, request AgentRequest) (func() error, error)
+	fileTransfer    FileTransferConfig
+	hostname        string
+	configView      ConfigView
+	towerReplica    TowerReplicaConfig
+	onListening     func()
+	onAccepting     func()
+	activeIdentity  *identities.Identity
+	logger          zerolog.Logger
+	busy            atomic.Bool
+}
+
+// NewAgentFromConfig creates a new failover agent from a configuration
+func NewAgentFromConfig(config AgentConfig) (*Agent, error) {
+	if config.PrepareHandover == nil {
+		return nil, fmt.Errorf("agent requires a handover function")
+	}
+
+	tlsCert, err := serverCertificate(config.TLSCertificate)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Agent{
+		port: config.Port,
+		tlsConfig: &tls.Config{
+			Certificates: []tls.Certificate{tlsCert},
+			NextProtos:   []string{AgentProtocolName},
+		},
+		peers:           config.Peers,
+		prepareHandover: config.PrepareHandover,
+		fileTransfer:    config.FileTransfer,
+		hostname:        config.Hostname,
+		configView:      config.ConfigView,
+		towerReplica:    config.TowerReplica,
+		onListening:     config.OnListening,
+		onAccepting:     config.OnAccepting,
+		activeIdentity:  config.ActiveIdentity,
+		logger:          log.With().Str("component", "agent").Logger(),
+	}
+
+	if config.ClientCAs != nil {
+		requireClientCertificates(a.tlsConfig, config.ClientCAs, a.peers)
+	}
+
+	if a.port == 0 {
+		a.port = DefaultAgentPort
+	}
+	if a.fileTransfer.MaxSize <= 0 {
+		a.fileTransfer.MaxSize = DefaultFileTransferMaxSize
+	}
+
+	return a, nil
+}
+
+// Start listens for handover requests until the process exits - one handover runs at a time
+func (a *Agent) Start() error {
+	if err := ports.CheckBindable(a.port); err != nil {
+		return fmt.Errorf("failed to create agent listener: %w", err)
+	}
+	listener, err := quic.ListenAddr(fmt.Sprintf(":%d", a.port), a.tlsConfig, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create agent listener: %v", err)
+	}
+	releaseListener := cleanup.Register(fmt.Sprintf("failover agent listener :%d", a.port), listener.Close)
+	defer releaseListener()
+
+	a.logger.Info().Msgf("Agent listening on port %d - run this program with --via-agent on the PASSIVE validator to fail over", a.port)
+	if a.onListening != nil {
+		a.onListening()
+	}
+
+	for {
+		if a.onAccepting != nil {
+			a.onAccepting()
+		}
+		acceptCtx, cancel := context.WithTimeout(context.Background(), agentAcceptingInterval)
+		conn, err := listener.Accept(acceptCtx)
+		cancel()
+		if errors.Is(err, context.DeadlineExceeded) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("agent failed to accept connection: %w", err)
+		}
+		go a.handleConnection(conn)
+	}
+}
+
+// handleConnection handles a single handover, file transfer, tower replica, config view or ping request on a
+// connection
+func (a *Agent) handleConnection(conn quic.Connection) {
+	defer conn.CloseWithError(0, "done")
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultAgentRequestTimeout)
+	defer cancel()
+
+	stream, err := conn.AcceptStream(ctx)
+	if err != nil {
+		a.logger.Debug().Err(err).Str("remote_addr", conn.RemoteAddr().String()).Msg("failed to accept stream")
+		return
+	}
+	defer stream.Close()
+	if err := stream.SetDeadline(time.Now().Add(DefaultAgentRequestTimeout)); err != nil {
+		a.logger.Debug().Err(err).Msg("failed to set stream deadline")
+		return
+	}
+
+	msgType := make([]byte, 1)
+	if _, err := io.ReadFull(stream, msgType); err != nil {
+		a.logger.Debug().Err(err).Str("remote_addr", conn.RemoteAddr().String()).Msg("failed to read agent message type")
+		return
+	}
+	switch msgType[0] {
+	case MessageTypeAgentHandoverRequest:
+	case MessageTypeFileTransfer:
+		if err := stream.SetDeadline(time.Now().Add(DefaultFileTransferTimeout)); err != nil {
+			a.logger.Debug().Err(err).Msg("failed to set stream deadline")
+			return
+		}
+		a.handleFileTransfer(conn, stream)
+		return
+	case MessageTypeTowerReplica:
+		a.handleTowerReplica(conn, stream)
+		return
+	case MessageTypeConfigViewRequest:
+		a.handleConfigViewRequest(conn, stream)
+		return
+	case MessageTypePing:
+		// closing the connection straight after the response can drop it - the peer hangs up once it has read it
+		defer awaitPeerClose(conn)
+		answerPing(conn, stream, a.peers, a.hostname, a.logger)
+		return
+	default:
+		a.logger.Debug().Str("remote_addr", conn.RemoteAddr().String()).Msg("ignoring unexpected agent message")
+		return
+	}
+
+	requestStream := NewFailoverStream(stream)
+	var request AgentRequest
+	if err := requestStream.decoder.Decode(&request); err != nil {
+		a.logger.Debug().Err(err).Msg("failed to decode handover request")
+		return
+	}
+
+	identityProven := false
+	if request.ProveIdentity {
+		if err := a.authenticateRequester(requestStream); err != nil {
+			a.logger.Warn().Err(err).Str("requester", request.Hostname).Str("remote_addr", conn.RemoteAddr().String()).Msg("🔒 rejected handover request - requester authentication failed")
+			return
+		}
+		identityProven = true
+	}
+
+	peerCertName := peertrust.ClientCertificatePeerName(conn.ConnectionState().TLS)
+	handover, err := a.acceptRequest(utils.HostFromAddress(conn.RemoteAddr().String()), peerCertName, identityProven, request)
+	response := AgentResponse{Accepted: err == nil}
+	if err != nil {
+		response.ErrorMessage = err.Error()
+		a.logger.Warn().Err(err).Str("requester", request.Hostname).Msg("rejected handover request")
+	}
+	if encodeErr := requestStream.encoder.Encode(response); encodeErr != nil {
+		a.logger.Error().Err(encodeErr).Msg("failed to send handover response")
+		if handover != nil {
+			a.busy.Store(false)
+		}
+		return
+	}
+	if handover == nil {
+		return
+	}
+
+	// the requester starts its failover server once accepted - the handover dials it with retries
+	defer a.busy.Store(false)
+	a.logger.Info().Str("requester", request.Hostname).Str("peer_name", peerCertName).Msg("Accepted handover request - handing over")
+	if err := handover(); err != nil {
+		a.logger.Error().Err(err).Str("requester", request.Hostname).Msg("handover failed")
+	}
+}
+
+// acceptRequest validates a handover request and marks the agent busy when it is accepted - a requester that
+// presented a verified client certificate is the peer it was issued for, others are matched by IP
+func (a *Agent) acceptRequest(remoteIP, peerCertName string, identityProven bool, request AgentRequest) (handover func() error, err error) {
+	if err := CheckProtocolRevision(request.SolanaValidatorFailoverVersion, request.ProtocolRevision, request.MinProtocolRevision); err != nil {
+		return nil, err
+	}
+
+	peer, ok := matchPeer(a.peers, remoteIP, request.PublicIP, identityProven)
+	if peerCertName != "" {
+		peer, ok = a.peerByName(peerCertName)
+	}
+	if !ok {
+		return nil, fmt.Errorf("requester %s (%s) is not a configured peer", request.Hostname, remoteIP)
+	}
+
+	if !a.busy.CompareAndSwap(false, true) {
+		return nil, fmt.Errorf("a handover is already in progress")
+	}
+
+	handover, err = a.prepareHandover(peer, request)
+	if err != nil {
+		a.busy.Store(false)
+		return nil, err
+	}
+
+	return handover, nil
+}
+
+// authenticateRequester has a requester that offered to prove it holds the validator's active identity keypair do so,
+// proving the same back
+func (a *Agent) authenticateRequester(s *Stream) error {
+	if a.activeIdentity == nil {
+		return fmt.Errorf("agent has no active identity to authenticate requesters with")
+	}
+	return s.AuthenticateAsPassive(a.activeIdentity)
+}
+
+// peerByName finds the configured peer with the given name
+func (a *Agent) peerByName(name string) (PeerInfo, bool) {
+	return peerByName(a.peers, name)
+}
+
+// peerByName finds the peer with the given name in peers
+func peerByName(peers []PeerInfo, name string) (PeerInfo, bool) {
+	for _, peer := range peers {
+		if peer.Name == name {
+			return peer, true
+		}
+	}
+	return PeerInfo{}, false
+}
+
+// matchPeer finds the configured peer a connection came from by its remote IP or, only once the connection is
+// authenticated, the public IP it claims - anyone can claim a peer's IP
+func matchPeer(peers []PeerInfo, remoteIP, claimedPublicIP string, authenticated bool) (PeerInfo, bool) {
+	for _, peer := range peers {
+		peerHost := utils.HostFromAddress(peer.Address)
+		if peerHost == remoteIP || peer.GossipIP == remoteIP {
+			return peer, true
+		}
+	}
+	if !authenticated {
+		return PeerInfo{}, false
+	}
+	// fall back to the claimed public IP when the request arrives from another address (e.g. NAT)
+	for _, peer := range peers {
+		peerHost := utils.HostFromAddress(peer.Address)
+		if peerHost == claimedPublicIP || (peer.GossipIP != "" && peer.GossipIP == claimedPublicIP) {
+			return peer, true
+		}
+	}
+	return PeerInfo{}, false
+}
+
+// RequestHandoverParams are the parameters for asking an agent to hand over
+type RequestHandoverParams struct {
+	AgentName    string
+	AgentAddress string
+	Request      AgentRequest
+	// PeerPins pins the agent's certificate fingerprint on first use and requires it after - nil disables pinning
+	PeerPins *peertrust.Store
+	// ClientCertificate is presented to the agent when it requires client certificates - nil presents none
+	ClientCertificate *tls.Certificate
+	// ActiveIdentity is the validator's active identity this node proves it holds to the agent - nil proves nothing,
+	// so the agent only matches this node by the address it connects from or its client certificate
+	ActiveIdentity *identities.Identity
+}
+
+// RequestHandover asks the agent on the active node to hand over to this node - the caller must start its failover
+// server once accepted so the agent can connect to it
+func RequestHandover(params RequestHandoverParams) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultAgentRequestTimeout)
+	defer cancel()
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: true,
+		NextProtos:         []string{AgentProtocolName},
+	}
+	if params.ClientCertificate != nil {
+		tlsConfig.Certificates = []tls.Certificate{*params.ClientCertificate}
+	}
+	var fingerprint string
+	if params.PeerPins != nil {
+		tlsConfig.VerifyPeerCertificate = params.PeerPins.VerifyPeerCertificate(params.AgentName, &fingerprint)
+	}
+
+	conn, err := quic.DialAddr(ctx, params.AgentAddress, tlsConfig, nil)
+	if err != nil {
+		return fmt.Errorf("failed to connect to agent on %s at %s: %w", params.AgentName, params.AgentAddress, err)
+	}
+	defer conn.CloseWithError(0, "done")
+
+	stream, err := conn.OpenStreamSync(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open stream to agent: %w", err)
+	}
+	defer stream.Close()
+	if err := stream.SetDeadline(time.Now().Add(DefaultAgentRequestTimeout)); err != nil {
+		return err
+	}
+
+	if _, err := stream.Write([]byte{MessageTypeAgentHandoverRequest}); err != nil {
+		return fmt.Errorf("failed to send handover request: %w", err)
+	}
+	request := params.Request
+	request.ProveIdentity = params.ActiveIdentity != nil
+	requestStream := NewFailoverStream(stream)
+	if err := requestStream.encoder.Encode(request); err != nil {
+		return fmt.Errorf("failed to send handover request: %w", err)
+	}
+	if request.ProveIdentity {
+		if err := requestStream.AuthenticateAsActive(params.ActiveIdentity); err != nil {
+			return fmt.Errorf("agent on %s refused this node's identity: %w", params.AgentName, err)
+		}
+	}
+
+	var response AgentResponse
+	if err := requestStream.decoder.Decode(&response); err != nil {
+		return fmt.Errorf("failed to read handover response: %w", err)
+	}
+	if !response.Accepted {
+		return fmt.Errorf("agent on %s rejected handover: %s", params.AgentName, response.ErrorMessage)
+	}
+
+	// the agent shares its node's failover server certificate so trust it from now on
+	if params.PeerPins != nil {
+		if err := params.PeerPins.Pin(params.AgentName, fingerprint); err != nil {
+			log.Warn().Err(err).Msgf("failed to pin certificate for %s", params.AgentName)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package failover
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestAgent returns an agent with a single peer at 10.0.0.2 whose handovers do nothing
+func newTestAgent(t *testing.T) *Agent {
+	t.Helper()
+	agent, err := NewAgentFromConfig(AgentConfig{
+		Peers: []PeerInfo{{Name: "peer", Address: "10.0.0.2:9898", GossipIP: "10.0.1.2"}},
+		PrepareHandover: func(peer PeerInfo, request AgentRequest) (func() error, error) {
+			return func() error { return nil }, nil
+		},
+	})
+	require.NoError(t, err)
+	return agent
+}
+
+// agentRequest returns a handover request from a requester claiming publicIP
+func agentRequest(publicIP string) AgentRequest {
+	return AgentRequest{
+		Hostname:            "peer",
+		PublicIP:            publicIP,
+		ProtocolRevision:    ProtocolRevision,
+		MinProtocolRevision: MinProtocolRevision,
+	}
+}
+
+func TestAgentAcceptRequest(t *testing.T) {
+	for name, accept := range map[string]func(a *Agent) (func() error, error){
+		"remote address": func(a *Agent) (func() error, error) {
+			return a.acceptRequest("10.0.0.2", "", false, agentRequest("10.9.9.9"))
+		},
+		"gossip ip": func(a *Agent) (func() error, error) {
+			return a.acceptRequest("10.0.1.2", "", false, agentRequest(""))
+		},
+		"claimed ip once identity is proven": func(a *Agent) (func() error, error) {
+			return a.acceptRequest("192.168.0.1", "", true, agentRequest("10.0.0.2"))
+		},
+		"client certificate": func(a *Agent) (func() error, error) {
+			return a.acceptRequest("192.168.0.1", "peer", false, agentRequest(""))
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			agent := newTestAgent(t)
+			handover, err := accept(agent)
+			require.NoError(t, err)
+			assert.NotNil(t, handover)
+			assert.True(t, agent.busy.Load())
+		})
+	}
+}
+
+func TestAgentAcceptRequest_Rejected(t *testing.T) {
+	agent := newTestAgent(t)
+
+	// claiming a peer's public IP without proving the identity
+	_, err := agent.acceptRequest("192.168.0.1", "", false, agentRequest("10.0.0.2"))
+	assert.ErrorContains(t, err, "is not a configured peer")
+
+	// a client certificate for a name that isn't a peer wins over a matching address
+	_, err = agent.acceptRequest("10.0.0.2", "stranger", false, agentRequest("10.0.0.2"))
+	assert.ErrorContains(t, err, "is not a configured peer")
+
+	// an incompatible protocol revision
+	request := agentRequest("")
+	request.MinProtocolRevision = ProtocolRevision + 1
+	_, err = agent.acceptRequest("10.0.0.2", "", false, request)
+	assert.Error(t, err)
+
+	assert.False(t, agent.busy.Load())
+}
+
+func TestAgentAcceptRequest_PrepareHandoverFails(t *testing.T) {
+	agent := newTestAgent(t)
+	agent.prepareHandover = func(peer PeerInfo, request AgentRequest) (func() error, error) {
+		return nil, errors.New("not active")
+	}
+
+	_, err := agent.acceptRequest("10.0.0.2", "", false, agentRequest(""))
+	assert.ErrorContains(t, err, "not active")
+	assert.False(t, agent.busy.Load())
+}
+
+func TestAgentAcceptRequest_Busy(t *testing.T) {
+	agent := newTestAgent(t)
+
+	_, err := agent.acceptRequest("10.0.0.2", "", false, agentRequest(""))
+	require.NoError(t, err)
+
+	_, err = agent.acceptRequest("10.0.0.2", "", false, agentRequest(""))
+	assert.ErrorContains(t, err, "a handover is already in progress")
+
+	// accepted again once the running handover ends
+	agent.busy.Store(false)
+	_, err = agent.acceptRequest("10.0.0.2", "", false, agentRequest(""))
+	assert.NoError(t, err)
+}
+
+func TestMatchPeer(t *testing.T) {
+	peers := []PeerInfo{{Name: "peer", Address: "10.0.0.2:9898", GossipIP: "10.0.1.2"}}
+
+	peer, ok := matchPeer(peers, "10.0.0.2", "", false)
+	assert.True(t, ok)
+	assert.Equal(t, "peer", peer.Name)
+
+	_, ok = matchPeer(peers, "192.168.0.1", "10.0.1.2", false)
+	assert.False(t, ok)
+
+	peer, ok = matchPeer(peers, "192.168.0.1", "10.0.1.2", true)
+	assert.True(t, ok)
+	assert.Equal(t, "peer", peer.Name)
+}
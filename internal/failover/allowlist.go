@@ -0,0 +1,118 @@
+package failover
+
+import (
+	"fmt"
+
+	"github.com/sol-strategies/solana-validator-failover/internal/utils"
+)
+
+const (
+	// PeerRejectionReasonUnknownAddress is when the remote address isn't a configured peer's, nor is the peer its client
+	// certificate was issued for
+	PeerRejectionReasonUnknownAddress = "unknown_address"
+	// PeerRejectionReasonActivePubkeyMismatch is when the claimed active identity isn't this validator's
+	PeerRejectionReasonActivePubkeyMismatch = "active_pubkey_mismatch"
+	// PeerRejectionReasonPassivePubkeyMismatch is when the claimed passive identity isn't the matched peer's
+	PeerRejectionReasonPassivePubkeyMismatch = "passive_pubkey_mismatch"
+)
+
+// PeerRejection is why the passive node refused a connecting node that isn't an allowed peer - it is sent back over
+// the stream so the refused node can say why
+type PeerRejection struct {
+	// Reason is one of the PeerRejectionReason constants
+	Reason          string
+	RemoteIP        string
+	ClaimedPublicIP string
+	ClaimedPubkey   string
+	Detail          string
+}
+
+// Error implements error
+func (r *PeerRejection) Error() string {
+	return fmt.Sprintf("rejected as %s: %s", r.Reason, r.Detail)
+}
+
+// allowPeer returns the configured peer a connecting node is - the peer its verified client certificate was issued
+// for, or matched by its remote IP - or why it isn't allowed. The public IP it claims is never trusted to match it.
+// Its claimed active identity must be this validator's and, when the peer has a passive pubkey configured, its
+// claimed passive identity must be that
+func allowPeer(peers []PeerInfo, remoteIP, peerCertName string, claimed *NodeInfo, activePubkey string) (PeerInfo, *PeerRejection) {
+	rejection := &PeerRejection{
+		RemoteIP:        remoteIP,
+		ClaimedPublicIP: claimed.PublicIP,
+	}
+
+	peer, ok := matchPeer(peers, remoteIP, "", false)
+	if peerCertName != "" {
+		peer, ok = peerByName(peers, peerCertName)
+	}
+	if !ok && peerCertName != "" {
+		rejection.Reason = PeerRejectionReasonUnknownAddress
+		rejection.Detail = fmt.Sprintf("client certificate issued for %s which is not a configured peer", peerCertName)
+		return PeerInfo{}, rejection
+	}
+	if !ok {
+		rejection.Reason = PeerRejectionReasonUnknownAddress
+		rejection.Detail = fmt.Sprintf("remote address %s is not a configured peer's address host or gossip_ip", remoteIP)
+		return PeerInfo{}, rejection
+	}
+
+	if claimed.Identities == nil || claimed.Identities.Active == nil || claimed.Identities.Passive == nil {
+		rejection.Reason = PeerRejectionReasonActivePubkeyMismatch
+		rejection.Detail = fmt.Sprintf("peer %s claimed no identities", peer.Name)
+		return peer, rejection
+	}
+
+	if claimedActive := claimed.Identities.Active.PubKey(); claimedActive != activePubkey {
+		rejection.Reason = PeerRejectionReasonActivePubkeyMismatch
+		rejection.ClaimedPubkey = claimedActive
+		rejection.Detail = fmt.Sprintf("peer %s claimed active identity %s, expected %s", peer.Name, claimedActive, activePubkey)
+		return peer, rejection
+	}
+
+	if claimedPassive := claimed.Identities.Passive.PubKey(); peer.PassivePubkey != "" && claimedPassive != peer.PassivePubkey {
+		rejection.Reason = PeerRejectionReasonPassivePubkeyMismatch
+		rejection.ClaimedPubkey = claimedPassive
+		rejection.Detail = fmt.Sprintf("peer %s claimed passive identity %s, expected %s", peer.Name, claimedPassive, peer.PassivePubkey)
+		return peer, rejection
+	}
+
+	return peer, nil
+}
+
+// checkPeerAllowlist rejects the connecting active node over the stream when it isn't an allowed peer
+func (s *Server) checkPeerAllowlist() (allowed bool) {
+	remoteIP := ""
+	if s.activeConn != nil {
+		remoteIP = utils.HostFromAddress(s.activeConn.RemoteAddr().String())
+	}
+
+	peer, rejection := allowPeer(s.peers, remoteIP, s.activePeerCertName, s.failoverStream.GetActiveNodeInfo(), s.passiveNodeInfo.Identities.Active.PubKey())
+	if rejection == nil {
+		s.logger.Debug().Str("peer", peer.Name).Str("remote_ip", remoteIP).Msg("connecting node is an allowed peer")
+		return true
+	}
+
+	s.logger.Warn().
+		Str("reason", rejection.Reason).
+		Str("remote_ip", rejection.RemoteIP).
+		Str("claimed_public_ip", rejection.ClaimedPublicIP).
+		Str("claimed_pubkey", rejection.ClaimedPubkey).
+		Msgf("🚫 Rejected connecting node - %s", rejection.Detail)
+	s.failoverStream.SetPeerRejection(rejection)
+	if err := s.failoverStream.Encode(); err != nil {
+		s.logger.Error().Err(err).Msg("failed to send peer rejection to connecting node")
+	}
+	return false
+}
+
+// SetPeerRejection sets why the connecting node was refused - its error message is set too
+func (s *Stream) SetPeerRejection(rejection *PeerRejection) {
+	s.message.PeerRejection = rejection
+	s.message.ErrorMessage = rejection.Error()
+}
+
+// GetPeerRejection returns why the passive node refused this node - nil when it didn't
+func (s *Stream) GetPeerRejection() *PeerRejection {
+	return s.message.PeerRejection
+}
@@ -0,0 +1,52 @@
+package failover
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowPeer(t *testing.T) {
+	activePubkey := solana.NewWallet().PublicKey()
+	passivePubkey := solana.NewWallet().PublicKey()
+	peers := []PeerInfo{{Name: "peer", Address: "10.0.0.2:9898", GossipIP: "10.0.1.2", PassivePubkey: passivePubkey.String()}}
+
+	claimed := func(publicIP string, active, passive solana.PublicKey) *NodeInfo {
+		return &NodeInfo{
+			PublicIP:   publicIP,
+			Identities: &NodeIdentities{Active: &NodeIdentity{Pubkey: active}, Passive: &NodeIdentity{Pubkey: passive}},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		remoteIP     string
+		peerCertName string
+		claimed      *NodeInfo
+		wantReason   string
+	}{
+		{"remote address", "10.0.0.2", "", claimed("", activePubkey, passivePubkey), ""},
+		{"gossip ip", "10.0.1.2", "", claimed("", activePubkey, passivePubkey), ""},
+		{"client certificate", "192.168.0.1", "peer", claimed("", activePubkey, passivePubkey), ""},
+		{"claimed public ip", "192.168.0.1", "", claimed("10.0.0.2", activePubkey, passivePubkey), PeerRejectionReasonUnknownAddress},
+		{"client certificate of a stranger", "10.0.0.2", "stranger", claimed("", activePubkey, passivePubkey), PeerRejectionReasonUnknownAddress},
+		{"no identities", "10.0.0.2", "", &NodeInfo{}, PeerRejectionReasonActivePubkeyMismatch},
+		{"other active identity", "10.0.0.2", "", claimed("", passivePubkey, passivePubkey), PeerRejectionReasonActivePubkeyMismatch},
+		{"other passive identity", "10.0.0.2", "", claimed("", activePubkey, activePubkey), PeerRejectionReasonPassivePubkeyMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			peer, rejection := allowPeer(peers, tt.remoteIP, tt.peerCertName, tt.claimed, activePubkey.String())
+			if tt.wantReason == "" {
+				require.Nil(t, rejection)
+				assert.Equal(t, "peer", peer.Name)
+				return
+			}
+			require.NotNil(t, rejection)
+			assert.Equal(t, tt.wantReason, rejection.Reason)
+			assert.Equal(t, tt.remoteIP, rejection.RemoteIP)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package failover
+
+import (
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/sol-strategies/solana-validator-failover/internal/audit"
+	"github.com/sol-strategies/solana-validator-failover/internal/constants"
+)
+
+// newAuditRecord creates an audit record of the failover as seen by hostname in role with peer, the other node's
+// configured peer name, from the current state of the stream - reason is why it was aborted, empty when it wasn't
+func (s *Stream) newAuditRecord(hostname, role, peer, reason string) audit.Record {
+	result := s.GetResult()
+	record := audit.Record{
+		FailoverID:      result.FailoverID,
+		Hostname:        hostname,
+		Role:            role,
+		IsDryRun:        result.IsDryRun,
+		Success:         result.Success && reason == "",
+		ErrorMessage:    result.ErrorMessage,
+		ActiveNode:      newAuditNode(result.RolesBefore.Active),
+		PassiveNode:     newAuditNode(result.RolesBefore.Passive),
+		Peer:            peer,
+		SwitchAt:        s.GetSwitchAt(),
+		StartSlot:       result.StartSlot,
+		EndSlot:         result.EndSlot,
+		Slots:           result.Slots,
+		DurationMs:      result.DurationMs,
+		TotalDurationMs: result.TotalDurationMs,
+		IdentityGapMs:   result.IdentityGapMs,
+		TowerFileBytes:  result.TowerFile.Bytes,
+		TowerFileHash:   result.TowerFile.Hash,
+	}
+	for _, stage := range result.Stages {
+		stageRole := constants.NodeRolePassive
+		if stage.Hostname == result.RolesBefore.Active.Hostname {
+			stageRole = constants.NodeRoleActive
+		}
+		record.Phases = append(record.Phases, audit.Phase{Name: stage.Stage, Role: stageRole, DurationMs: stage.DurationMs})
+	}
+	if reason != "" {
+		record.ErrorMessage = reason
+	}
+	if record.Success {
+		record.Summary = result.Summary
+	}
+	return record
+}
+
+// newAuditNode returns a result node as it appears in an audit record
+func newAuditNode(node ResultNode) audit.Node {
+	return audit.Node{
+		Hostname: node.Hostname,
+		PublicIP: node.PublicIP,
+		Pubkey:   node.Pubkey,
+	}
+}
+
+// durationRegressions returns the phases of record slower than cfg allows compared with earlier failovers in the
+// audit log - none when comparing is disabled or no audit log is kept
+func durationRegressions(auditLog *audit.Log, record audit.Record, cfg DurationRegressionConfig, logger zerolog.Logger) []audit.Regression {
+	if auditLog == nil || !cfg.Enabled {
+		return nil
+	}
+
+	records, err := auditLog.Read()
+	if err != nil {
+		// compare against the records that could be read
+		logger.Warn().Err(err).Msg("some audit records could not be read for the duration baseline")
+	}
+
+	minIncrease, _ := time.ParseDuration(cfg.MinIncrease)
+	return audit.Regressions(records, record, audit.BaselineConfig{
+		Failovers:        cfg.BaselineFailovers,
+		MinFailovers:     cfg.MinBaselineFailovers,
+		ThresholdPercent: cfg.ThresholdPercent,
+		MinIncrease:      minIncrease,
+	})
+}
+
+// appendAuditRecord appends a record to the audit log when one is kept - failing to is logged rather than failing
+// the failover
+func appendAuditRecord(auditLog *audit.Log, record audit.Record, logger zerolog.Logger) {
+	if auditLog == nil {
+		return
+	}
+	if err := auditLog.Append(record); err != nil {
+		logger.Error().Err(err).Msg("failed to write failover audit record")
+		return
+	}
+	logger.Debug().Str("path", auditLog.Path()).Msg("wrote failover audit record")
+}
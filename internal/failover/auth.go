@@ -0,0 +1,130 @@
+package failover
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/sol-strategies/solana-validator-failover/internal/identities"
+)
+
+const (
+	// authNonceSize is the number of random bytes each side challenges the other with
+	authNonceSize = 32
+	// authContextActive and authContextPassive separate what each role signs so signatures can't be reflected
+	authContextActive  = ProtocolName + "/auth/active"
+	authContextPassive = ProtocolName + "/auth/passive"
+)
+
+// AuthChallenge is a random nonce a node sends for its peer to sign with the validator's active identity
+type AuthChallenge struct {
+	Nonce []byte
+}
+
+// AuthResponse is a node's signature of both nonces with the validator's active identity - ErrorMessage is set
+// instead when the peer's signature didn't verify
+type AuthResponse struct {
+	Signature    []byte
+	ErrorMessage string
+}
+
+// ErrPeerAuthentication is returned when a peer can't prove it holds the validator's active identity
+var ErrPeerAuthentication = errors.New("peer failed to prove it holds the active identity keypair")
+
+// AuthenticateAsActive proves to the passive node that this node holds the validator's active identity keypair and
+// has it prove the same back - nothing else is exchanged until both have
+func (s *Stream) AuthenticateAsActive(activeIdentity *identities.Identity) error {
+	activeNonce, err := newAuthNonce()
+	if err != nil {
+		return err
+	}
+	if err := s.encoder.Encode(AuthChallenge{Nonce: activeNonce}); err != nil {
+		return fmt.Errorf("failed to send auth challenge: %w", err)
+	}
+
+	var passiveChallenge AuthChallenge
+	if err := s.decoder.Decode(&passiveChallenge); err != nil {
+		return fmt.Errorf("failed to read auth challenge: %w", err)
+	}
+	if len(passiveChallenge.Nonce) != authNonceSize {
+		return fmt.Errorf("%w: auth challenge nonce is %d bytes, expected %d", ErrPeerAuthentication, len(passiveChallenge.Nonce), authNonceSize)
+	}
+
+	challenge := authChallengeBytes(passiveChallenge.Nonce, activeNonce)
+	signature, err := activeIdentity.SignChallenge(authContextActive, challenge)
+	if err != nil {
+		return err
+	}
+	if err := s.encoder.Encode(AuthResponse{Signature: signature}); err != nil {
+		return fmt.Errorf("failed to send auth response: %w", err)
+	}
+
+	var passiveResponse AuthResponse
+	if err := s.decoder.Decode(&passiveResponse); err != nil {
+		return fmt.Errorf("failed to read auth response: %w", err)
+	}
+	if passiveResponse.ErrorMessage != "" {
+		return fmt.Errorf("passive node refused this node: %s", passiveResponse.ErrorMessage)
+	}
+	if !activeIdentity.VerifyChallenge(authContextPassive, challenge, passiveResponse.Signature) {
+		return fmt.Errorf("%w %s", ErrPeerAuthentication, activeIdentity.PubKey())
+	}
+	return nil
+}
+
+// AuthenticateAsPassive has the active node prove it holds the validator's active identity keypair before proving
+// the same back - the active node is verified first so nothing is signed for a peer that can't
+func (s *Stream) AuthenticateAsPassive(activeIdentity *identities.Identity) error {
+	var activeChallenge AuthChallenge
+	if err := s.decoder.Decode(&activeChallenge); err != nil {
+		return fmt.Errorf("failed to read auth challenge: %w", err)
+	}
+	if len(activeChallenge.Nonce) != authNonceSize {
+		return fmt.Errorf("%w: auth challenge nonce is %d bytes, expected %d", ErrPeerAuthentication, len(activeChallenge.Nonce), authNonceSize)
+	}
+
+	passiveNonce, err := newAuthNonce()
+	if err != nil {
+		return err
+	}
+	if err := s.encoder.Encode(AuthChallenge{Nonce: passiveNonce}); err != nil {
+		return fmt.Errorf("failed to send auth challenge: %w", err)
+	}
+
+	var activeResponse AuthResponse
+	if err := s.decoder.Decode(&activeResponse); err != nil {
+		return fmt.Errorf("failed to read auth response: %w", err)
+	}
+
+	challenge := authChallengeBytes(passiveNonce, activeChallenge.Nonce)
+	if !activeIdentity.VerifyChallenge(authContextActive, challenge, activeResponse.Signature) {
+		err := fmt.Errorf("%w %s", ErrPeerAuthentication, activeIdentity.PubKey())
+		if encodeErr := s.encoder.Encode(AuthResponse{ErrorMessage: err.Error()}); encodeErr != nil {
+			return fmt.Errorf("%w (and failed to tell it: %v)", err, encodeErr)
+		}
+		return err
+	}
+
+	signature, err := activeIdentity.SignChallenge(authContextPassive, challenge)
+	if err != nil {
+		return err
+	}
+	if err := s.encoder.Encode(AuthResponse{Signature: signature}); err != nil {
+		return fmt.Errorf("failed to send auth response: %w", err)
+	}
+	return nil
+}
+
+// newAuthNonce returns a random auth challenge nonce
+func newAuthNonce() ([]byte, error) {
+	nonce := make([]byte, authNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate auth nonce: %w", err)
+	}
+	return nonce, nil
+}
+
+// authChallengeBytes returns what both nodes sign - the passive node's nonce then the active node's
+func authChallengeBytes(passiveNonce, activeNonce []byte) []byte {
+	return append(append([]byte{}, passiveNonce...), activeNonce...)
+}
@@ -0,0 +1,100 @@
+package failover
+
+import (
+	"testing"
+
+	"github.com/sol-strategies/solana-validator-failover/internal/identities"
+	"github.com/stretchr/testify/assert"
+)
+
+// authenticate runs both sides of the handshake over an in-memory connection, the active side with activeIdentity
+// and the passive side with passiveIdentity
+func authenticate(t *testing.T, activeIdentity, passiveIdentity *identities.Identity) (activeErr, passiveErr error) {
+	t.Helper()
+	active, passive, _ := newPipeStreams(t)
+	passiveErrs := make(chan error, 1)
+	go func() { passiveErrs <- passive.AuthenticateAsPassive(passiveIdentity) }()
+	return active.AuthenticateAsActive(activeIdentity), <-passiveErrs
+}
+
+func TestAuthenticate(t *testing.T) {
+	identity := newTestIdentity(t)
+
+	activeErr, passiveErr := authenticate(t, identity, identity)
+	assert.NoError(t, activeErr)
+	assert.NoError(t, passiveErr)
+}
+
+func TestAuthenticate_WrongKey(t *testing.T) {
+	activeErr, passiveErr := authenticate(t, newTestIdentity(t), newTestIdentity(t))
+
+	// the passive node refuses the active node before signing anything for it
+	assert.ErrorIs(t, passiveErr, ErrPeerAuthentication)
+	assert.ErrorContains(t, activeErr, "passive node refused this node")
+}
+
+func TestAuthenticate_ShortNonce(t *testing.T) {
+	identity := newTestIdentity(t)
+
+	// a passive node challenging with a short nonce
+	active, passive, _ := newPipeStreams(t)
+	go func() {
+		var challenge AuthChallenge
+		_ = passive.decoder.Decode(&challenge)
+		_ = passive.encoder.Encode(AuthChallenge{Nonce: make([]byte, authNonceSize-1)})
+	}()
+	err := active.AuthenticateAsActive(identity)
+	assert.ErrorIs(t, err, ErrPeerAuthentication)
+	assert.ErrorContains(t, err, "nonce is 31 bytes")
+
+	// an active node challenging with a short nonce
+	active, passive, _ = newPipeStreams(t)
+	go func() { _ = active.encoder.Encode(AuthChallenge{Nonce: make([]byte, authNonceSize-1)}) }()
+	err = passive.AuthenticateAsPassive(identity)
+	assert.ErrorIs(t, err, ErrPeerAuthentication)
+}
+
+func TestAuthenticate_ReflectedSignature(t *testing.T) {
+	identity := newTestIdentity(t)
+
+	// an active node without the key answers with a signature the passive node makes for the same challenge - the
+	// roles sign under separate contexts so it doesn't verify as the active node's
+	active, passive, _ := newPipeStreams(t)
+	refusals := make(chan AuthResponse, 1)
+	go func() {
+		activeNonce := make([]byte, authNonceSize)
+		_ = active.encoder.Encode(AuthChallenge{Nonce: activeNonce})
+		var passiveChallenge AuthChallenge
+		_ = active.decoder.Decode(&passiveChallenge)
+		signature, _ := identity.SignChallenge(authContextPassive, authChallengeBytes(passiveChallenge.Nonce, activeNonce))
+		_ = active.encoder.Encode(AuthResponse{Signature: signature})
+		var response AuthResponse
+		_ = active.decoder.Decode(&response)
+		refusals <- response
+	}()
+
+	err := passive.AuthenticateAsPassive(identity)
+	assert.ErrorIs(t, err, ErrPeerAuthentication)
+	response := <-refusals
+	assert.Empty(t, response.Signature)
+	assert.Contains(t, response.ErrorMessage, identity.PubKey())
+}
+
+func TestAuthenticate_ActiveVerifiesPassive(t *testing.T) {
+	identity := newTestIdentity(t)
+
+	// a passive node without the key reflecting the active node's own signature back
+	active, passive, _ := newPipeStreams(t)
+	go func() {
+		var activeChallenge AuthChallenge
+		_ = passive.decoder.Decode(&activeChallenge)
+		passiveNonce := make([]byte, authNonceSize)
+		_ = passive.encoder.Encode(AuthChallenge{Nonce: passiveNonce})
+		var activeResponse AuthResponse
+		_ = passive.decoder.Decode(&activeResponse)
+		_ = passive.encoder.Encode(AuthResponse{Signature: activeResponse.Signature})
+	}()
+
+	err := active.AuthenticateAsActive(identity)
+	assert.ErrorIs(t, err, ErrPeerAuthentication)
+}
@@ -0,0 +1,146 @@
+package failover
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/sol-strategies/solana-validator-failover/internal/solana"
+	"github.com/sol-strategies/solana-validator-failover/internal/utils"
+)
+
+const (
+	// leaderWindowSlots is the number of consecutive slots a leader is scheduled for at a time
+	leaderWindowSlots = 4
+	// blockProductionPollInterval is how often the network slot is checked while waiting for leader slots to pass
+	blockProductionPollInterval = 2 * time.Second
+	// blockProductionLogMaxRanges is the maximum number of leader slot ranges listed when logging them
+	blockProductionLogMaxRanges = 5
+)
+
+// BlockProductionCheck is the active identity's block production in its first leader slots after a failover
+type BlockProductionCheck struct {
+	// LeaderSlots are the leader slots checked - those that passed before the timeout
+	LeaderSlots []uint64
+	// Production covers all checked leader slots and FirstWindow only the first leader window's
+	Production  solana.BlockProduction
+	FirstWindow solana.BlockProduction
+}
+
+// FirstWindowSkipped returns true when no block was produced in any slot of the first leader window
+func (c *BlockProductionCheck) FirstWindowSkipped() bool {
+	return c.FirstWindow.LeaderSlots > 0 && c.FirstWindow.BlocksProduced == 0
+}
+
+// firstLeaderWindow returns the consecutive leader slots the first of slots starts - at most leaderWindowSlots
+func firstLeaderWindow(slots []uint64) []uint64 {
+	end := 1
+	for end < len(slots) && end < leaderWindowSlots && slots[end] == slots[0]+uint64(end) {
+		end++
+	}
+	return slots[:end]
+}
+
+// PullBlockProduction waits for the active identity's first configured leader slots after the failover to pass and
+// checks how many blocks it produced in them - no check is recorded when it has no leader slots left this epoch
+func (s *Stream) PullBlockProduction(solanaRPCClient solana.ClientInterface, logger zerolog.Logger) error {
+	cfg := s.message.MonitorConfig.BlockProduction
+	pubkey := s.message.ActiveNodeInfo.Identities.Active.PublicKey()
+
+	// the detached post-failover monitor doesn't know the slot the failover ended at - it checks from now instead
+	afterSlot := s.message.FailoverEndSlot
+	if afterSlot == 0 {
+		currentSlot, err := solanaRPCClient.GetCurrentSlot()
+		if err != nil {
+			return fmt.Errorf("failed to get current slot: %w", err)
+		}
+		afterSlot = currentSlot
+	}
+
+	slots, err := solanaRPCClient.GetLeaderSlotsAfter(pubkey, afterSlot, cfg.LeaderSlots)
+	if err != nil {
+		return fmt.Errorf("failed to get leader slots: %w", err)
+	}
+	if len(slots) == 0 {
+		logger.Info().Msg("No leader slots left this epoch - not checking block production")
+		return nil
+	}
+
+	logger.Info().
+		Str("leader_slots", utils.FormatSlotRanges(slots, blockProductionLogMaxRanges)).
+		Msgf("🧱 Waiting for %d leader slot(s) to pass to check block production...", len(slots))
+	currentSlot, err := waitForSlot(solanaRPCClient, slots[len(slots)-1], cfg.TimeoutDuration(), logger)
+	if err != nil {
+		return err
+	}
+
+	// only the leader slots that passed before the timeout can be checked
+	passed := 0
+	for passed < len(slots) && slots[passed] <= currentSlot {
+		passed++
+	}
+	if passed == 0 {
+		return fmt.Errorf("leader slot %d didn't pass within %s - current slot %d", slots[0], cfg.Timeout, currentSlot)
+	}
+	slots = slots[:passed]
+
+	check := &BlockProductionCheck{LeaderSlots: slots}
+	check.Production, err = solanaRPCClient.GetBlockProduction(pubkey, slots[0], slots[len(slots)-1])
+	if err != nil {
+		return err
+	}
+	window := firstLeaderWindow(slots)
+	check.FirstWindow, err = solanaRPCClient.GetBlockProduction(pubkey, window[0], window[len(window)-1])
+	if err != nil {
+		return err
+	}
+	s.message.BlockProduction = check
+
+	logger.Info().
+		Str("leader_slots", utils.FormatSlotRanges(slots, blockProductionLogMaxRanges)).
+		Uint64("produced", check.Production.BlocksProduced).
+		Uint64("skipped", check.Production.SkippedSlots()).
+		Float64("skip_rate", check.Production.SkipRate()).
+		Msgf("🧱 Produced %d of %d leader slot(s) after failover", check.Production.BlocksProduced, check.Production.LeaderSlots)
+	if check.FirstWindowSkipped() {
+		logger.Error().Msgf("🚨 %s", s.GetBlockProductionFailure())
+	}
+	return nil
+}
+
+// GetBlockProductionFailure returns why the post-failover block production check failed - empty unless the first
+// leader window was fully skipped
+func (s *Stream) GetBlockProductionFailure() string {
+	check := s.message.BlockProduction
+	if check == nil || !check.FirstWindowSkipped() {
+		return ""
+	}
+	return fmt.Sprintf(
+		"first leader window after failover fully skipped: slots %d-%d produced no blocks - check the validator is running with the active identity",
+		check.FirstWindow.FirstSlot, check.FirstWindow.LastSlot,
+	)
+}
+
+// waitForSlot waits until the network's confirmed slot is past slot or timeout passes, returning the last slot seen
+func waitForSlot(solanaRPCClient solana.ClientInterface, slot uint64, timeout time.Duration, logger zerolog.Logger) (currentSlot uint64, err error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		latest, getErr := solanaRPCClient.GetCurrentSlot()
+		if getErr != nil {
+			logger.Debug().Err(getErr).Msg("failed to get current slot")
+			err = getErr
+		} else {
+			currentSlot = latest
+		}
+		if currentSlot > slot {
+			return currentSlot, nil
+		}
+		if !time.Now().Before(deadline) {
+			if currentSlot == 0 {
+				return 0, fmt.Errorf("failed to get current slot within %s: %w", timeout, err)
+			}
+			return currentSlot, nil
+		}
+		time.Sleep(blockProductionPollInterval)
+	}
+}
@@ -3,21 +3,32 @@ package failover
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"net"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/charmbracelet/huh/spinner"
 	"github.com/gagliardetto/solana-go/rpc"
 	"github.com/quic-go/quic-go"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/audit"
+	"github.com/sol-strategies/solana-validator-failover/internal/cleanup"
 	"github.com/sol-strategies/solana-validator-failover/internal/constants"
 	"github.com/sol-strategies/solana-validator-failover/internal/hooks"
+	"github.com/sol-strategies/solana-validator-failover/internal/identities"
+	"github.com/sol-strategies/solana-validator-failover/internal/notify"
+	"github.com/sol-strategies/solana-validator-failover/internal/peertrust"
+	"github.com/sol-strategies/solana-validator-failover/internal/roleintent"
 	"github.com/sol-strategies/solana-validator-failover/internal/solana"
 	"github.com/sol-strategies/solana-validator-failover/internal/style"
+	"github.com/sol-strategies/solana-validator-failover/internal/tracing"
+	"github.com/sol-strategies/solana-validator-failover/internal/ui"
 	"github.com/sol-strategies/solana-validator-failover/internal/utils"
-	pkgconstants "github.com/sol-strategies/solana-validator-failover/pkg/constants"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // ClientConfig is the configuration for the failover client, client is always the active node
@@ -30,15 +41,53 @@ type ClientConfig struct {
 	Hooks                          hooks.FailoverHooks
 	LocalRPCClient                 *rpc.Client
 	SolanaRPCClient                solana.ClientInterface
+	DialRetries                    int
+	DialRetryInterval              string
+	DialRetryMaxInterval           string
+	LogSlotContext                 LogSlotContextConfig
+	// Identities are this node's identities - ActiveNodeInfo only holds their pubkeys, which are sent to the server
+	Identities *identities.Identities
+	// PeerPins pins the server's certificate fingerprint on first use and requires it after - nil disables pinning
+	PeerPins *peertrust.Store
+	// ClientCertificate is presented to the server when it requires client certificates - nil presents none
+	ClientCertificate *tls.Certificate
+	// ServerCAs requires the server's certificate to be issued by them for its peer name or address host - nil only
+	// checks the pin
+	ServerCAs *x509.CertPool
+	// Output is how the failover result is reported - nothing beyond logs by default
+	Output        OutputConfig
+	Notifications notify.Config
+	// AuditLog is appended a record of the failover when it completes or aborts - nil keeps no record
+	AuditLog *audit.Log
+	// RoleIntent records this node as meant to be passive once a real failover completes - nil records nothing
+	RoleIntent *roleintent.Store
+	// Tracing exports the failover's spans when an endpoint is set
+	Tracing tracing.Config
+	// WaitForHealthy is how long this node waited to report healthy before the failover - recorded as a span
+	WaitForHealthy tracing.Interval
+	// PathMTUProbe probes the path mtu to the server once connected, before the handshake
+	PathMTUProbe PathMTUProbeConfig
+	// ScheduledSwitchAt is when both nodes switch once the failover is confirmed - zero switches as soon as this node
+	// is ready unless the server scheduled the switch
+	ScheduledSwitchAt time.Time
+	// Standbys are the other passive peers - those waiting for a failover are told who took over once it completes
+	Standbys []PeerInfo
+	// QUIC tunes the quic transport of the connection to the server
+	QUIC QUICConfig
+	// WarmHold holds the session open once the failover is confirmed, with heartbeats, until the operator presses
+	// enter or the process is sent SIGUSR1 - the switch then starts without connecting or checking again
+	WarmHold bool
 }
 
 // Client is the failover client - an active node connects to a passive node server to handover as active
 type Client struct {
 	Conn                           quic.Connection
+	releaseConn                    func() error
 	ctx                            context.Context
 	cancel                         context.CancelFunc
 	logger                         zerolog.Logger
 	activeNodeInfo                 *NodeInfo
+	identities                     *identities.Identities
 	failoverStream                 *Stream
 	hooks                          hooks.FailoverHooks
 	minTimeToLeaderSlot            time.Duration
@@ -46,6 +95,25 @@ type Client struct {
 	localRPCClient                 *rpc.Client
 	solanaRPCClient                solana.ClientInterface
 	serverName                     string
+	logSlotContext                 LogSlotContextConfig
+	peerPins                       *peertrust.Store
+	serverCAs                      *x509.CertPool
+	peerFingerprint                string
+	clientCertificate              *tls.Certificate
+	output                         OutputConfig
+	notifier                       *notify.Notifier
+	auditLog                       *audit.Log
+	roleIntent                     *roleintent.Store
+	tracer                         *tracing.Tracer
+	failoverTrace                  *failoverTrace
+	waitForHealthy                 tracing.Interval
+	pathMTUProbe                   PathMTUProbeConfig
+	scheduledSwitchAt              time.Time
+	pendingAbort                   pendingAbort
+	standbys                       []PeerInfo
+	serverAddress                  string
+	warmHold                       bool
+	quic                           QUICConfig
 }
 
 // NewClientFromConfig creates a new QUIC client from a configuration
@@ -57,32 +125,192 @@ func NewClientFromConfig(config ClientConfig) (client *Client, err error) {
 		ctx:                            ctx,
 		cancel:                         cancel,
 		activeNodeInfo:                 config.ActiveNodeInfo,
+		identities:                     config.Identities,
 		hooks:                          config.Hooks,
 		minTimeToLeaderSlot:            config.MinTimeToLeaderSlot,
 		waitMinTimeToLeaderSlotEnabled: config.WaitMinTimeToLeaderSlotEnabled,
 		localRPCClient:                 config.LocalRPCClient,
 		solanaRPCClient:                config.SolanaRPCClient,
 		serverName:                     config.ServerName,
+		logSlotContext:                 config.LogSlotContext,
+		peerPins:                       config.PeerPins,
+		serverCAs:                      config.ServerCAs,
+		clientCertificate:              config.ClientCertificate,
+		output:                         config.Output,
+		auditLog:                       config.AuditLog,
+		roleIntent:                     config.RoleIntent,
+		waitForHealthy:                 config.WaitForHealthy,
+		pathMTUProbe:                   config.PathMTUProbe,
+		scheduledSwitchAt:              config.ScheduledSwitchAt,
+		standbys:                       config.Standbys,
+		serverAddress:                  config.ServerAddress,
+		warmHold:                       config.WarmHold,
+		quic:                           config.QUIC,
 	}
 
-	// dial the server
-	client.Conn, err = quic.DialAddr(ctx, config.ServerAddress, &tls.Config{
-		InsecureSkipVerify: true,
-		NextProtos:         []string{ProtocolName},
-	}, nil)
+	client.activeNodeInfo.setProtocol()
+
+	if config.DialRetryInterval == "" {
+		config.DialRetryInterval = DefaultDialRetryIntervalDurationStr
+	}
+
+	if config.DialRetryMaxInterval == "" {
+		config.DialRetryMaxInterval = DefaultDialRetryMaxIntervalDurationStr
+	}
+
+	client.notifier, err = notify.NewNotifier(config.Notifications)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to create notifier: %w", err)
+	}
+
+	client.tracer, err = tracing.NewTracer(config.Tracing, config.ActiveNodeInfo.Hostname)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to create tracer: %w", err)
+	}
+
+	dialRetryInterval, err := time.ParseDuration(config.DialRetryInterval)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to parse dial retry interval: %v", err)
+	}
+
+	dialRetryMaxInterval, err := time.ParseDuration(config.DialRetryMaxInterval)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to parse dial retry max interval: %v", err)
+	}
+
+	// dial the server - retrying with backoff as the passive node may not be listening yet
+	client.Conn, err = client.dialWithRetry(config.ServerAddress, config.DialRetries, dialRetryInterval, dialRetryMaxInterval)
 	if err != nil {
 		cancel()
 		return nil, fmt.Errorf("failed to connect to server: %v", err)
 	}
 
+	client.releaseConn = cleanup.Register(fmt.Sprintf("connection to %s", config.ServerAddress), func() error {
+		return client.Conn.CloseWithError(0, "connection closed")
+	})
+
 	client.logger.Debug().Msgf("Connected to %s", style.RenderPassiveString(config.ServerName, false))
 
 	return client, nil
 }
 
+// dialWithRetry dials the server, retrying up to maxRetries times with exponential backoff between
+// retryInterval and maxRetryInterval - operators often start the passive and active sides in either order
+func (c *Client) dialWithRetry(serverAddress string, maxRetries int, retryInterval, maxRetryInterval time.Duration) (conn quic.Connection, err error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: true,
+		NextProtos:         []string{ProtocolName},
+	}
+	if c.clientCertificate != nil {
+		tlsConfig.Certificates = []tls.Certificate{*c.clientCertificate}
+	}
+
+	// check the server's certificate chains to the configured authority and is the one pinned for it - a certificate
+	// failing either isn't retried
+	var verifiers []func([][]byte, [][]*x509.Certificate) error
+	if c.serverCAs != nil {
+		host, _, _ := net.SplitHostPort(serverAddress)
+		verifiers = append(verifiers, peertrust.VerifyCertificateChain(c.serverCAs, c.serverName, host))
+	}
+	if c.peerPins != nil {
+		verifiers = append(verifiers, c.peerPins.VerifyPeerCertificate(c.serverName, &c.peerFingerprint))
+	}
+	var verifyErr error
+	if len(verifiers) > 0 {
+		tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, chains [][]*x509.Certificate) error {
+			for _, verify := range verifiers {
+				if verifyErr = verify(rawCerts, chains); verifyErr != nil {
+					return verifyErr
+				}
+			}
+			return nil
+		}
+	}
+
+	// first attempt without a spinner - the common case is the peer is already listening
+	conn, err = c.quic.dial(c.ctx, serverAddress, tlsConfig, false)
+	if err == nil || maxRetries <= 0 {
+		return conn, err
+	}
+	if verifyErr != nil {
+		return nil, verifyErr
+	}
+
+	c.logger.Debug().Err(err).Msgf("failed to connect to %s - retrying up to %d times", serverAddress, maxRetries)
+
+	sp := ui.NewSpinner().
+		Context(c.ctx).
+		TitleStyle(style.SpinnerTitleStyle).
+		Title(fmt.Sprintf("Waiting for %s to start listening...", style.RenderPassiveString(c.serverName, false)))
+
+	sp.ActionWithErr(func(ctx context.Context) error {
+		sleepDuration := retryInterval
+		for attempt := 1; attempt <= maxRetries; attempt++ {
+			sp.Title(style.RenderWarningStringf(
+				"Waiting for %s to start listening at %s - retrying in %s (attempt %d of %d)...",
+				c.serverName,
+				serverAddress,
+				sleepDuration.String(),
+				attempt,
+				maxRetries,
+			))
+			// stop waiting as soon as the client is cancelled rather than after the backoff
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("stopped waiting for %s after %d of %d retries: %w", serverAddress, attempt-1, maxRetries, ctx.Err())
+			case <-time.After(sleepDuration):
+			}
+
+			conn, err = c.quic.dial(ctx, serverAddress, tlsConfig, false)
+			if err == nil {
+				return nil
+			}
+			if verifyErr != nil {
+				return verifyErr
+			}
+			c.logger.Debug().Err(err).Msgf("(attempt %d of %d) failed to connect to %s", attempt, maxRetries, serverAddress)
+
+			sleepDuration *= 2
+			if sleepDuration > maxRetryInterval {
+				sleepDuration = maxRetryInterval
+			}
+		}
+		return fmt.Errorf("gave up after %d retries: %w", maxRetries, err)
+	})
+
+	if spErr := sp.Run(); spErr != nil {
+		// the terminal ui says it was killed rather than why when the client is cancelled
+		if ctxErr := c.ctx.Err(); ctxErr != nil && !errors.Is(spErr, ctxErr) {
+			return nil, fmt.Errorf("stopped waiting for %s: %w", serverAddress, ctxErr)
+		}
+		return nil, spErr
+	}
+
+	return conn, nil
+}
+
 // Start starts the QUIC client
 func (c *Client) Start() {
 	c.logger.Debug().Msg("Starting QUIC client")
+	defer c.releaseConn()
+	defer c.cancel()
+	defer c.notifier.Wait()
+
+	// this node starts the failover's trace - its root span covers the wait to report healthy before it connected
+	traceStartTime := time.Now()
+	if !c.waitForHealthy.IsZero() {
+		traceStartTime = c.waitForHealthy.Start
+	}
+	c.failoverTrace = startFailoverTrace(c.tracer, c.ctx, constants.NodeRoleActive, c.activeNodeInfo.Hostname, traceStartTime)
+	defer c.failoverTrace.end("")
+	c.failoverTrace.record(spanWaitForHealthy, c.waitForHealthy)
+
+	// find out before anything is sent whether the path will slow the tower file transfer down
+	pathMTUProbe := c.probePathMTU()
 
 	// open a bidirectional stream to the server
 	stream, err := c.Conn.OpenStreamSync(c.ctx)
@@ -102,31 +330,78 @@ func (c *Client) Start() {
 		return
 	}
 
-	// send message with your own info
+	// prove this node holds the active identity keypair and have the server prove the same before sending anything
+	handshakeSpan := c.failoverTrace.start(spanHandshake)
+	if err := c.failoverStream.AuthenticateAsActive(c.identities.Active); err != nil {
+		c.logger.Fatal().Err(err).Msg("peer authentication failed")
+		return
+	}
+	c.logger.Debug().Msg("peer authenticated with the active identity keypair")
+
+	// detect this node's role from gossip so the server can catch operator mistakes at handshake
+	role, err := c.activeNodeInfo.DetectRole(c.solanaRPCClient)
+	if err != nil {
+		c.logger.Warn().Err(err).Msg("failed to detect this node's role from gossip")
+	}
+	c.logger.Debug().Str("role", role).Msg("detected this node's role for handshake")
+
+	// send message with your own info and the trace the passive node's spans join
 	c.failoverStream.SetActiveNodeInfo(c.activeNodeInfo)
+	c.failoverStream.SetTraceParent(c.failoverTrace.traceParent())
+	c.failoverStream.SetPathMTUProbe(pathMTUProbe)
+	c.failoverStream.SetScheduledSwitchAt(c.scheduledSwitchAt)
+	c.failoverStream.SetWarmHold(c.warmHold)
 	err = c.failoverStream.Encode()
 	if err != nil {
 		return
 	}
 
+	// until this node begins setting its identity either node can abort the failover - tell the passive node when
+	// this one stops before then so it doesn't wait for a switch that never comes
+	c.pendingAbort.arm()
+	releaseAbort := cleanup.Register("pending failover abort", func() error {
+		c.abort("active node stopped before switching identity")
+		return nil
+	})
+	defer releaseAbort()
+
 	c.logger.Debug().Msg("Sent message type")
 
 	// wait for failover signal from server before proceeding
-	sp := spinner.New().Title(fmt.Sprintf("Waiting for failover signal from %s...", style.RenderPassiveString(c.serverName, false)))
+	sp := ui.NewSpinner().Title(fmt.Sprintf("Waiting for failover signal from %s...", style.RenderPassiveString(c.serverName, false)))
 	sp.ActionWithErr(func(ctx context.Context) error {
 		return c.failoverStream.Decode()
 	})
 	err = sp.Run()
 	if err != nil {
+		c.exitIfPeerAborted(err)
 		c.logger.Fatal().Err(err).Msg("failed to wait for failover signal")
 		return
 	}
 
-	// ensure server is running the same version of this program
-	serverVersion := c.failoverStream.GetPassiveNodeInfo().SolanaValidatorFailoverVersion
-	clientVersion := pkgconstants.AppVersion
-	if serverVersion != clientVersion {
-		c.logger.Fatal().Msgf("server is running a different version of this program: %s (them) != %s (us)", serverVersion, clientVersion)
+	// the server says why when it refuses this node as a peer
+	if rejection := c.failoverStream.GetPeerRejection(); rejection != nil {
+		c.logger.Fatal().
+			Str("reason", rejection.Reason).
+			Str("remote_ip", rejection.RemoteIP).
+			Str("claimed_public_ip", rejection.ClaimedPublicIP).
+			Str("claimed_pubkey", rejection.ClaimedPubkey).
+			Msgf("%s refused this node as a peer: %s", c.serverName, rejection.Detail)
+		return
+	}
+
+	// ensure the server speaks a protocol revision this node interoperates with
+	negotiation, err := NegotiateProtocol(*c.failoverStream.GetPassiveNodeInfo())
+	if err != nil {
+		c.logger.Fatal().Err(err).Msg("server speaks an incompatible protocol")
+		return
+	}
+	negotiation.Log(c.logger)
+
+	// ensure the roles both sides detected for themselves make sense
+	err = checkHandshakeRoles(c.failoverStream.GetActiveNodeInfo(), c.failoverStream.GetPassiveNodeInfo())
+	if err != nil {
+		c.logger.Fatal().Err(err).Msg("role handshake failed")
 		return
 	}
 
@@ -135,15 +410,47 @@ func (c *Client) Start() {
 		c.logger.Fatal().Msg(c.failoverStream.GetErrorMessage())
 		return
 	}
+	c.failoverTrace.setFailover(c.failoverStream)
+	tracing.End(handshakeSpan, nil)
+	if traceID := c.failoverStream.GetTraceID(); traceID != "" {
+		c.logger.Info().Str("trace_id", traceID).Msg("Tracing failover")
+	}
+
+	// hold the confirmed failover until the operator triggers the switch - the leader slot check below is then made
+	// for that moment
+	if c.warmHold {
+		warmHoldSpan := c.failoverTrace.start(spanWarmHold)
+		err = c.holdWarm()
+		tracing.End(warmHoldSpan, err)
+		if err != nil {
+			c.exitIfPeerAborted(err)
+			c.logger.Fatal().Err(err).Msg("failed to hold the failover")
+			return
+		}
+	}
+
+	// wait for the scheduled switch with the passive node - the leader slot check below is then made for that moment
+	if scheduledSwitchAt := c.failoverStream.GetScheduledSwitchAt(); !scheduledSwitchAt.IsZero() {
+		err = waitForScheduledSwitch(c.Conn.Context(), c.logger, scheduledSwitchAt, style.RenderPassiveString(c.serverName, false))
+		if err != nil {
+			c.exitIfPeerAborted(err)
+			c.logger.Fatal().Err(err).Msg("failed to wait for scheduled switch")
+			return
+		}
+	}
 
 	// wait until the next leader slot is at least the minimum time to leader slot
+	leaderSlotWaitSpan := c.failoverTrace.start(spanLeaderSlotWait)
 	err = c.waitMinTimeToLeaderSlot()
+	tracing.End(leaderSlotWaitSpan, err)
 	if err != nil {
+		c.exitIfPeerAborted(err)
 		c.logger.Fatal().Err(err).Msg("failed to wait for next leader slot")
 		return
 	}
 
-	// run pre hooks when active
+	// run pre hooks when active - timed so the passive node can show them in its timing summary
+	phaseStartTime := time.Now()
 	err = c.hooks.RunPreWhenActive(c.getHookEnvMap(hookEnvMapParams{
 		isDryRunFailover: c.failoverStream.GetIsDryRunFailover(),
 		isPreFailover:    true,
@@ -152,12 +459,50 @@ func (c *Client) Start() {
 		c.logger.Fatal().Err(err).Msg("failed to run pre hooks when active")
 		return
 	}
+	if len(c.hooks.Pre.WhenActive) > 0 {
+		c.failoverStream.RecordPhaseTiming(newPhaseTiming(c.activeNodeInfo.Hostname, PhasePreHooks, phaseStartTime))
+	}
+
+	// agree the moment to switch with the passive node and count down to it together
+	if countdown := c.failoverStream.GetSwitchCountdown(); countdown > 0 {
+		switchAt := time.Now().UTC().Add(countdown)
+		c.failoverStream.SetSwitchAt(switchAt)
+		if err := c.failoverStream.Encode(); err != nil {
+			c.logger.Fatal().Err(err).Msgf("failed to send switch time to %s", c.serverName)
+			return
+		}
+		if err := countDownToSwitch(c.Conn.Context(), c.logger, switchAt, style.RenderPassiveString(c.serverName, false)); err != nil {
+			c.exitIfPeerAborted(err)
+			c.logger.Fatal().Err(err).Msg("failed to count down to switch")
+			return
+		}
+	}
+
+	// the tower file is signed once this node has demoted itself - make sure a remote signer answers while it can
+	// still stop the failover as active
+	if err := probeTowerSigner(c.identities.Active); err != nil {
+		c.notifyAbort(err.Error())
+		c.logger.Fatal().Err(err).Msg("not failing over - the tower file couldn't be signed")
+		return
+	}
+
+	// decorate log lines in the critical window with slot and epoch when enabled
+	baseLogger := c.logger
+	var stopSlotContext func()
+	c.logger, stopSlotContext = withSlotContext(c.logger, c.logSlotContext, c.solanaRPCClient)
+	defer stopSlotContext()
 
 	c.logger.Info().Msg("🟢 Failover started")
+	c.notifier.Notify(c.failoverStream.newNotification(notify.TypeStart, "", c.activeNodeInfo.Hostname))
 
 	// get the current slot and set it as the failover start slot
+	rpcCallStartTime := time.Now()
 	slot, err := c.solanaRPCClient.GetCurrentSlot()
+	c.failoverStream.RecordRPCCall(newRPCCallRecord(
+		c.activeNodeInfo.Hostname, "failover start slot", c.solanaRPCClient.NetworkRPCURL(), rpcCallStartTime, err,
+	))
 	if err != nil {
+		c.notifyAbort(fmt.Sprintf("failed to get current slot: %v", err))
 		c.logger.Fatal().Err(err).Msg("failed to get current slot")
 		return
 	}
@@ -168,10 +513,29 @@ func (c *Client) Start() {
 	// wait until the next slot starts so we switch right at the beginning of the next slot
 	err = c.waitUntilStartOfNextSlot()
 	if err != nil {
+		c.notifyAbort(fmt.Sprintf("failed to wait for next slot to start: %v", err))
 		c.logger.Fatal().Err(err).Msgf("failed to wait for next slot to start")
 		return
 	}
 
+	// the passive node can't abort the failover from here on - this node is about to change its identity
+	c.exitIfPeerAborted(nil)
+	if err := c.pendingAbort.commit(); err != nil {
+		c.logger.Error().Err(err).Msg("not switching identity")
+		return
+	}
+
+	// run before set identity hooks when active - a failing one that must succeed leaves this node active
+	err = c.hooks.RunBeforeSetIdentityWhenActive(c.getHookEnvMap(hookEnvMapParams{
+		isDryRunFailover: c.failoverStream.GetIsDryRunFailover(),
+		isPreFailover:    true,
+	}))
+	if err != nil {
+		c.logger.Error().Err(err).Msg("failed to run before set identity hooks when active")
+		c.notifyAbort(fmt.Sprintf("failed to run before set identity hooks when active: %v", err))
+		return
+	}
+
 	// set identity to passive
 	dryRunPrefix := " "
 	if c.failoverStream.GetIsDryRunFailover() {
@@ -187,13 +551,17 @@ func (c *Client) Start() {
 
 	c.failoverStream.SetActiveNodeSetIdentityStartTime()
 
+	setIdentitySpan := c.failoverTrace.start(spanSetIdentity)
 	err = utils.RunCommand(utils.RunCommandParams{
 		CommandSlice: strings.Split(c.failoverStream.GetActiveNodeInfo().SetIdentityCommand, " "),
+		Stdin:        c.identities.Passive.DecryptedKeypair(),
 		DryRun:       c.failoverStream.GetIsDryRunFailover(),
 		LogDebug:     c.logger.Debug().Enabled(),
 	})
+	tracing.End(setIdentitySpan, err)
 	if err != nil {
 		c.logger.Error().Err(err).Msgf("failed to set identity to passive")
+		c.notifyAbort(fmt.Sprintf("failed to set identity to passive: %v", err))
 		return
 	}
 	c.failoverStream.SetActiveNodeSetIdentityEndTime()
@@ -202,39 +570,128 @@ func (c *Client) Start() {
 
 	// Read the tower file into TowerFileBytes
 	c.failoverStream.SetActiveNodeSyncTowerFileStartTime()
+	towerTransferSpan := c.failoverTrace.start(spanTowerTransfer)
 	err = c.failoverStream.GetActiveNodeInfo().SetTowerFileBytes()
 	if err != nil {
+		tracing.End(towerTransferSpan, err)
 		c.logger.Error().Err(err).Msgf("failed to set tower file bytes for %s", c.failoverStream.GetActiveNodeInfo().TowerFile)
+		c.notifyAbort(fmt.Sprintf("failed to read tower file: %v", err))
 		return
 	}
+	// sign the tower file as it is on disk so the passive node can tell it came from the active identity unaltered
+	// this node has already demoted itself so a signer failing now falls back to the tower file's hash rather than
+	// leave neither node voting
+	if err := c.failoverStream.signTowerFile(c.identities.Active); err != nil {
+		c.logger.Warn().Err(err).Msg("failed to sign tower file - sending it unsigned, vouched for by its hash alone")
+		c.failoverStream.GetActiveNodeInfo().TowerFileUnsignedReason = err.Error()
+	}
 	c.failoverStream.SetActiveNodeSyncTowerFileEndTime()
 
-	// Send the updated node info with tower file bytes
-	if err := c.failoverStream.Encode(); err != nil {
+	// compress the tower file for the wire when the passive node accepts it compressed - it is sent as is otherwise
+	activeNodeInfo := c.failoverStream.GetActiveNodeInfo()
+	towerFileSize := len(activeNodeInfo.TowerFileBytes)
+	if err := activeNodeInfo.encodeTowerFile(c.failoverStream.GetPassiveNodeInfo().Capabilities); err != nil {
+		c.logger.Warn().Err(err).Msg("sending tower file uncompressed")
+	}
+	towerFileWireSize := len(activeNodeInfo.TowerFileBytes)
+	c.logger.Debug().
+		Int("tower_file_bytes", towerFileSize).
+		Int("tower_file_wire_bytes", towerFileWireSize).
+		Str("tower_file_encoding", activeNodeInfo.TowerFileEncoding).
+		Msg("encoded tower file for the wire")
+
+	// Send the updated node info then the tower file bytes, resuming the transfer if the connection is lost
+	towerFileWireBytes := activeNodeInfo.TowerFileBytes
+	err = c.sendTowerFile(towerFileWireBytes)
+	activeNodeInfo.TowerFileBytes = towerFileWireBytes
+	towerTransferSpan.SetAttributes(
+		attribute.Int("tower_file.bytes", towerFileSize),
+		attribute.Int("tower_file.wire_bytes", towerFileWireSize),
+	)
+	tracing.End(towerTransferSpan, err)
+	// the result and audit record describe the tower file as it is on disk
+	if decodeErr := activeNodeInfo.decodeTowerFile(); decodeErr != nil {
+		c.logger.Warn().Err(decodeErr).Msg("failed to restore tower file bytes after sending them")
+	}
+	if err != nil {
 		c.logger.Error().Err(err).Msgf("failed to send tower file bytes for %s", c.failoverStream.GetActiveNodeInfo().TowerFile)
+		c.notifyAbort(fmt.Sprintf("failed to send tower file: %v", err))
 		return
 	}
 
 	// wait for confirmation from server that failover is complete
+	waitForPassiveSpan := c.failoverTrace.start(spanWaitForPassive)
 	err = c.failoverStream.Decode()
+	tracing.End(waitForPassiveSpan, err)
 	if err != nil {
 		c.logger.Error().Err(err).Msg("failed to decode failover stream")
+		c.notifyAbort(fmt.Sprintf("failed to hear back from %s: %v", c.serverName, err))
 		return
 	}
 
 	// send a message to the server to confirm we're proceeding
 	if !c.failoverStream.GetIsSuccessfullyCompleted() {
 		c.logger.Error().Msgf("server failed to complete failover: %s", c.failoverStream.GetErrorMessage())
+		c.notifyAbort(fmt.Sprintf("server failed to complete failover: %s", c.failoverStream.GetErrorMessage()))
+		c.writeResult()
 		return
 	}
 
-	c.logger.Info().Msg("🟤 Failover complete")
+	c.logger.Info().Str("summary", c.failoverStream.GetSummaryLine()).Msg("🟤 Failover complete")
+	c.notifier.Notify(c.failoverStream.newNotification(notify.TypeComplete, "", c.activeNodeInfo.Hostname))
+	appendAuditRecord(c.auditLog, c.failoverStream.newAuditRecord(c.activeNodeInfo.Hostname, constants.NodeRoleActive, c.serverName, ""), c.logger)
+	c.recordRoleIntent()
+	stopSlotContext()
+	c.logger = baseLogger
+
+	// trust the server's certificate from now on and remember what it presented
+	if c.peerPins != nil {
+		if err := c.peerPins.Pin(c.serverName, c.peerFingerprint); err != nil {
+			c.logger.Warn().Err(err).Msgf("failed to pin certificate for %s", c.serverName)
+		}
+		recordPeerSession(c.peerPins, c.serverName, c.failoverStream.GetPassiveNodeInfo(), c.peerFingerprint, c.logger)
+	}
+
+	// the other passive peers stop waiting for a failover that went elsewhere
+	c.informStandbys()
 
 	// run post hooks now this is passive and active node says all is peachy
 	c.hooks.RunPostWhenPassive(c.getHookEnvMap(hookEnvMapParams{
 		isDryRunFailover: c.failoverStream.GetIsDryRunFailover(),
 		isPostFailover:   true,
 	}))
+
+	c.writeResult()
+}
+
+// notifyAbort notifies that the failover was aborted, records it in the audit log and waits for the notification to
+// be sent - the caller may be about to exit
+func (c *Client) notifyAbort(reason string) {
+	c.notifier.Notify(c.failoverStream.newNotification(notify.TypeAbort, reason, c.activeNodeInfo.Hostname))
+	appendAuditRecord(c.auditLog, c.failoverStream.newAuditRecord(c.activeNodeInfo.Hostname, constants.NodeRoleActive, c.serverName, reason), c.logger)
+	c.failoverTrace.end(reason)
+	c.runAbortHooks(reason)
+	c.notifier.Wait()
+}
+
+// runAbortHooks runs the on abort hooks for the node that was active - once the passive node's info, which they are
+// told about, has been received
+func (c *Client) runAbortHooks(reason string) {
+	if c.failoverStream.GetPassiveNodeInfo().Identities == nil {
+		return
+	}
+	c.hooks.RunOnAbortWhenActive(c.getHookEnvMap(hookEnvMapParams{
+		isDryRunFailover: c.failoverStream.GetIsDryRunFailover(),
+		isPreFailover:    true,
+		abortReason:      reason,
+	}))
+}
+
+// writeResult writes the failover result as a json document when asked to
+func (c *Client) writeResult() {
+	if err := writeResult(c.failoverStream, c.output); err != nil {
+		c.logger.Error().Err(err).Msg("failed to write failover result")
+	}
 }
 
 // waitUntilStartOfNextSlot waits until the start of the next slot
@@ -245,7 +702,11 @@ func (c *Client) waitUntilStartOfNextSlot() (err error) {
 	sleepDuration := 10 * time.Microsecond
 
 	// get the expected current slot end time
+	rpcCallStartTime := time.Now()
 	expectedCurrentSlotEndTime, err := c.solanaRPCClient.GetCurrentSlotEndTime()
+	c.failoverStream.RecordRPCCall(newRPCCallRecord(
+		c.activeNodeInfo.Hostname, "current slot end time", c.solanaRPCClient.NetworkRPCURL(), rpcCallStartTime, err,
+	))
 	if err != nil {
 		return fmt.Errorf("failed to get current slot end time: %w", err)
 	}
@@ -267,29 +728,21 @@ func (c *Client) waitMinTimeToLeaderSlot() (err error) {
 	}
 
 	c.logger.Debug().Msgf("Ensuring next leader slot is at least %s in the future", c.minTimeToLeaderSlot.String())
-	sp := spinner.New().TitleStyle(style.SpinnerTitleStyle).Title("Checking next leader slot...")
-	maxRetries := 10
+	sp := ui.NewSpinner().TitleStyle(style.SpinnerTitleStyle).Title("Checking next leader slot...")
 	sp.ActionWithErr(func(ctx context.Context) error {
 		sleepDuration := 2 * time.Second
-		pubkey := c.activeNodeInfo.Identities.Active.Key.PublicKey()
-		remainingRetries := maxRetries
+		pubkey := c.activeNodeInfo.Identities.Active.PublicKey()
 
 		for {
+			// the passive node may abort the failover while this node waits
+			if connCtx := c.Conn.Context(); connCtx.Err() != nil {
+				return context.Cause(connCtx)
+			}
+
+			// failed rpc calls are retried by the rpc client
 			isOnLeaderSchedule, timeToNextLeaderSlot, err := c.solanaRPCClient.GetTimeToNextLeaderSlotForPubkey(pubkey)
 			if err != nil {
-				if remainingRetries == 0 {
-					return fmt.Errorf("failed to get time to next leader slot: %w", err)
-				}
-				log.Debug().Err(err).Msgf("failed to get time to next leader slot")
-				sp.Title(style.RenderErrorStringf(
-					"Failed to get time to next leader slot, retrying in %s (%d retries left): %s",
-					sleepDuration.String(),
-					remainingRetries,
-					err.Error(),
-				))
-				remainingRetries--
-				time.Sleep(sleepDuration)
-				continue
+				return fmt.Errorf("failed to get time to next leader slot: %w", err)
 			}
 
 			if !isOnLeaderSchedule {
@@ -346,6 +799,11 @@ func (c *Client) getHookEnvMap(params hookEnvMapParams) (envMap map[string]strin
 	envMap = map[string]string{}
 
 	envMap["IS_DRY_RUN_FAILOVER"] = fmt.Sprintf("%t", params.isDryRunFailover)
+	envMap["FAILOVER_ID"] = c.failoverStream.GetFailoverID()
+	if params.abortReason != "" {
+		envMap["ABORT_REASON"] = params.abortReason
+	}
+	c.failoverStream.setHookEnv(envMap, params, c.failoverStream.GetActiveNodeInfo())
 
 	// this node is active
 	if params.isPreFailover {
@@ -357,16 +815,22 @@ func (c *Client) getHookEnvMap(params hookEnvMapParams) (envMap map[string]strin
 	if params.isPostFailover {
 		envMap["THIS_NODE_ROLE"] = constants.NodeRolePassive
 		envMap["PEER_NODE_ROLE"] = constants.NodeRoleActive
+		envMap["IDENTITY_GAP_MS"] = strconv.FormatInt(c.failoverStream.GetIdentityGap().Milliseconds(), 10)
+		envMap["SUMMARY"] = c.failoverStream.GetSummaryLine()
+		if switchAt := c.failoverStream.GetSwitchAt(); !switchAt.IsZero() {
+			envMap["SWITCH_AT"] = switchAt.UTC().Format(time.RFC3339Nano)
+		}
 	}
 
 	// this node is active
 	envMap["THIS_NODE_NAME"] = c.activeNodeInfo.Hostname
 	envMap["THIS_NODE_PUBLIC_IP"] = c.activeNodeInfo.PublicIP
 	envMap["THIS_NODE_ACTIVE_IDENTITY_PUBKEY"] = c.activeNodeInfo.Identities.Active.PubKey()
-	envMap["THIS_NODE_ACTIVE_IDENTITY_KEYPAIR_FILE"] = c.activeNodeInfo.Identities.Active.KeyFile
+	envMap["THIS_NODE_ACTIVE_IDENTITY_KEYPAIR_FILE"] = c.identities.Active.KeyFile
 	envMap["THIS_NODE_PASSIVE_IDENTITY_PUBKEY"] = c.activeNodeInfo.Identities.Passive.PubKey()
-	envMap["THIS_NODE_PASSIVE_IDENTITY_KEYPAIR_FILE"] = c.activeNodeInfo.Identities.Passive.KeyFile
+	envMap["THIS_NODE_PASSIVE_IDENTITY_KEYPAIR_FILE"] = c.identities.Passive.KeyFile
 	envMap["THIS_NODE_CLIENT_VERSION"] = c.activeNodeInfo.ClientVersion
+	envMap["THIS_NODE_CLIENT"] = c.activeNodeInfo.Client
 
 	// peer node
 	envMap["PEER_NODE_NAME"] = c.failoverStream.GetPassiveNodeInfo().Hostname
@@ -374,6 +838,7 @@ func (c *Client) getHookEnvMap(params hookEnvMapParams) (envMap map[string]strin
 	envMap["PEER_NODE_ACTIVE_IDENTITY_PUBKEY"] = c.failoverStream.GetPassiveNodeInfo().Identities.Active.PubKey()
 	envMap["PEER_NODE_PASSIVE_IDENTITY_PUBKEY"] = c.failoverStream.GetPassiveNodeInfo().Identities.Passive.PubKey()
 	envMap["PEER_NODE_CLIENT_VERSION"] = c.failoverStream.GetPassiveNodeInfo().ClientVersion
+	envMap["PEER_NODE_CLIENT"] = c.failoverStream.GetPassiveNodeInfo().Client
 
 	return envMap
 }
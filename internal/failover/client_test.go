@@ -0,0 +1,46 @@
+package failover
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/rs/zerolog"
+	"github.com/sol-strategies/solana-validator-failover/internal/ui"
+	"github.com/sol-strategies/solana-validator-failover/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialWithRetry_CancelledDuringBackoff(t *testing.T) {
+	// a server refusing the failover protocol fails each dial straight away
+	tlsCert, err := utils.GenerateTLSCertificate()
+	require.NoError(t, err)
+	listener, err := quic.ListenAddr("127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{tlsCert}, NextProtos: []string{"not-failover"}}, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	// plain lines - the terminal ui's spinner title isn't safe to set from the action under the race detector
+	ui.SetPlain(true)
+	t.Cleanup(func() { ui.SetPlain(false) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var logs bytes.Buffer
+	c := &Client{ctx: ctx, cancel: cancel, logger: zerolog.New(&logs).Level(zerolog.DebugLevel), serverName: "passive"}
+
+	// cancelled while backing off before the first retry
+	time.AfterFunc(100*time.Millisecond, cancel)
+	startTime := time.Now()
+	conn, err := c.dialWithRetry(listener.Addr().String(), 5, time.Hour, time.Hour)
+
+	assert.Nil(t, conn)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorContains(t, err, "after 0 of 5 retries")
+	assert.Less(t, time.Since(startTime), 5*time.Second)
+	assert.Contains(t, logs.String(), "retrying up to 5 times")
+	assert.NotContains(t, logs.String(), "(attempt 1 of 5) failed")
+}
@@ -0,0 +1,210 @@
+package failover
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/peertrust"
+	"github.com/sol-strategies/solana-validator-failover/internal/utils"
+	pkgconstants "github.com/sol-strategies/solana-validator-failover/pkg/constants"
+)
+
+// configViewHashLength is how many hex characters of a hashed config value are shared
+const configViewHashLength = 16
+
+// ConfigViewEntry is a config value as shared with peers - values that may hold secrets or node-specific paths
+// (templates, hook commands) are only shared hashed
+type ConfigViewEntry struct {
+	Value  string
+	Hashed bool
+}
+
+// String returns the value, prefixed sha256: when hashed
+func (e ConfigViewEntry) String() string {
+	if e.Hashed {
+		return "sha256:" + e.Value
+	}
+	return e.Value
+}
+
+// ConfigView is the effective config a node shares with its peers to catch drift, by dotted config key
+type ConfigView map[string]ConfigViewEntry
+
+// Set adds a value shared as it is
+func (v ConfigView) Set(key, value string) {
+	v[key] = ConfigViewEntry{Value: value}
+}
+
+// SetHashed adds a value shared only as a truncated sha256 so equal values still compare equal
+func (v ConfigView) SetHashed(key, value string) {
+	sum := sha256.Sum256([]byte(value))
+	v[key] = ConfigViewEntry{Value: hex.EncodeToString(sum[:])[:configViewHashLength], Hashed: true}
+}
+
+// ConfigDifference is a config key whose value differs between two nodes - a value missing on one side is empty
+type ConfigDifference struct {
+	Key   string `json:"key"`
+	Local string `json:"local"`
+	Peer  string `json:"peer"`
+}
+
+// DiffConfigViews returns the keys whose values differ between local and peer, sorted by key
+func DiffConfigViews(local, peer ConfigView) (differences []ConfigDifference) {
+	keys := make([]string, 0, len(local)+len(peer))
+	for key := range local {
+		keys = append(keys, key)
+	}
+	for key := range peer {
+		if _, ok := local[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		localEntry, localOK := local[key]
+		peerEntry, peerOK := peer[key]
+		if localOK && peerOK && localEntry == peerEntry {
+			continue
+		}
+		difference := ConfigDifference{Key: key}
+		if localOK {
+			difference.Local = localEntry.String()
+		}
+		if peerOK {
+			difference.Peer = peerEntry.String()
+		}
+		differences = append(differences, difference)
+	}
+	return differences
+}
+
+// ConfigViewRequest is sent by a peer asking the agent for its node's config view
+type ConfigViewRequest struct {
+	Hostname                       string
+	PublicIP                       string
+	SolanaValidatorFailoverVersion string
+}
+
+// ConfigViewResponse is the agent's answer to a config view request
+type ConfigViewResponse struct {
+	Hostname     string
+	View         ConfigView
+	ErrorMessage string
+}
+
+// handleConfigViewRequest answers a config view request on a stream the message type has been read from - versions
+// may differ as the view includes them
+func (a *Agent) handleConfigViewRequest(conn quic.Connection, stream quic.Stream) {
+	// closing the connection straight after the response can drop it - the peer hangs up once it has read it
+	defer awaitPeerClose(conn)
+
+	var request ConfigViewRequest
+	if err := gob.NewDecoder(stream).Decode(&request); err != nil {
+		a.logger.Debug().Err(err).Msg("failed to decode config view request")
+		return
+	}
+
+	response := ConfigViewResponse{Hostname: a.hostname}
+	remoteIP := utils.HostFromAddress(conn.RemoteAddr().String())
+	peer, ok := matchPeer(a.peers, remoteIP, request.PublicIP, false)
+	if peerCertName := peertrust.ClientCertificatePeerName(conn.ConnectionState().TLS); peerCertName != "" {
+		peer, ok = a.peerByName(peerCertName)
+	}
+	switch {
+	case a.configView == nil:
+		response.ErrorMessage = "config sharing is disabled - set failover.agent.share_config to enable it"
+	case !ok:
+		response.ErrorMessage = fmt.Sprintf("requester %s (%s) is not a configured peer", request.Hostname, remoteIP)
+	default:
+		response.View = a.configView
+	}
+	if response.ErrorMessage != "" {
+		a.logger.Warn().Str("requester", request.Hostname).Msgf("rejected config view request: %s", response.ErrorMessage)
+	} else {
+		a.logger.Debug().Str("peer_name", peer.Name).Msg("shared config view")
+	}
+
+	if err := gob.NewEncoder(stream).Encode(response); err != nil {
+		a.logger.Debug().Err(err).Msg("failed to send config view response")
+	}
+}
+
+// RequestConfigViewParams are the parameters for asking a peer's agent for its node's config view
+type RequestConfigViewParams struct {
+	AgentName    string
+	AgentAddress string
+	Hostname     string
+	PublicIP     string
+	// PeerPins pins the agent's certificate fingerprint on first use and requires it after - nil disables pinning
+	PeerPins *peertrust.Store
+	// ClientCertificate is presented to the agent when it requires client certificates - nil presents none
+	ClientCertificate *tls.Certificate
+}
+
+// RequestConfigView asks the agent on a peer for its node's config view
+func RequestConfigView(params RequestConfigViewParams) (response ConfigViewResponse, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultAgentRequestTimeout)
+	defer cancel()
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: true,
+		NextProtos:         []string{AgentProtocolName},
+	}
+	if params.ClientCertificate != nil {
+		tlsConfig.Certificates = []tls.Certificate{*params.ClientCertificate}
+	}
+	var fingerprint string
+	if params.PeerPins != nil {
+		tlsConfig.VerifyPeerCertificate = params.PeerPins.VerifyPeerCertificate(params.AgentName, &fingerprint)
+	}
+
+	conn, err := quic.DialAddr(ctx, params.AgentAddress, tlsConfig, nil)
+	if err != nil {
+		return response, fmt.Errorf("failed to connect to agent on %s at %s: %w", params.AgentName, params.AgentAddress, err)
+	}
+	defer conn.CloseWithError(0, "done")
+
+	stream, err := conn.OpenStreamSync(ctx)
+	if err != nil {
+		return response, fmt.Errorf("failed to open stream to agent: %w", err)
+	}
+	defer stream.Close()
+	if err := stream.SetDeadline(time.Now().Add(DefaultAgentRequestTimeout)); err != nil {
+		return response, err
+	}
+
+	if _, err := stream.Write([]byte{MessageTypeConfigViewRequest}); err != nil {
+		return response, fmt.Errorf("failed to send config view request: %w", err)
+	}
+	request := ConfigViewRequest{
+		Hostname:                       params.Hostname,
+		PublicIP:                       params.PublicIP,
+		SolanaValidatorFailoverVersion: pkgconstants.AppVersion,
+	}
+	if err := gob.NewEncoder(stream).Encode(request); err != nil {
+		return response, fmt.Errorf("failed to send config view request: %w", err)
+	}
+	if err := gob.NewDecoder(stream).Decode(&response); err != nil {
+		return response, fmt.Errorf("failed to read config view response: %w", err)
+	}
+	if response.ErrorMessage != "" {
+		return response, fmt.Errorf("agent on %s rejected config view request: %s", params.AgentName, response.ErrorMessage)
+	}
+
+	if params.PeerPins != nil {
+		if err := params.PeerPins.Pin(params.AgentName, fingerprint); err != nil {
+			log.Warn().Err(err).Msgf("failed to pin certificate for %s", params.AgentName)
+		}
+	}
+
+	return response, nil
+}
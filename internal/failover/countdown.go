@@ -0,0 +1,66 @@
+package failover
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/sol-strategies/solana-validator-failover/internal/style"
+	"github.com/sol-strategies/solana-validator-failover/internal/ui"
+)
+
+// countdownTick is how often the countdown is redrawn
+const countdownTick = 100 * time.Millisecond
+
+// countDownToSwitch shows a countdown to switchAt, the moment both nodes agreed the active node switches identity -
+// it stops early with the connection's close cause when the peer goes away or aborts the failover
+func countDownToSwitch(connCtx context.Context, logger zerolog.Logger, switchAt time.Time, peerName string) error {
+	logger.Info().
+		Time("switch_at", switchAt).
+		Msgf("⏳ Switching with %s at %s", peerName, switchAt.UTC().Format("15:04:05.000"))
+
+	title := func(remaining time.Duration) string {
+		seconds := (remaining + time.Second - 1).Truncate(time.Second)
+		return style.RenderWarningStringf("Switching with %s in %s...", peerName, seconds)
+	}
+
+	sp := ui.NewSpinner().
+		TitleStyle(style.SpinnerTitleStyle).
+		Title(title(time.Until(switchAt)))
+	sp.ActionWithErr(func(ctx context.Context) error {
+		for {
+			remaining := time.Until(switchAt)
+			if remaining <= 0 {
+				return nil
+			}
+			if connCtx.Err() != nil {
+				return context.Cause(connCtx)
+			}
+			sp.Title(title(remaining))
+			time.Sleep(min(remaining, countdownTick))
+		}
+	})
+	if err := sp.Run(); err != nil {
+		return fmt.Errorf("countdown to switch stopped: %w", err)
+	}
+	return nil
+}
+
+// localSwitchAt returns the moment to count down to on this node given the peer's switchAt - clocks differing by
+// more than the countdown would otherwise hold this node up past the switch, so it's capped to the countdown from now
+func localSwitchAt(switchAt time.Time, countdown time.Duration, logger zerolog.Logger) time.Time {
+	remaining := time.Until(switchAt)
+	if remaining <= countdown && remaining >= 0 {
+		return switchAt
+	}
+
+	logger.Warn().
+		Time("switch_at", switchAt).
+		Dur("countdown", countdown).
+		Msgf("peer's switch time is %s away but the countdown is %s - clocks differ, counting down on this node's clock", remaining.Round(time.Millisecond), countdown)
+	if remaining < 0 {
+		return time.Now()
+	}
+	return time.Now().Add(countdown)
+}
@@ -1,5 +1,7 @@
 package failover
 
+import "time"
+
 const (
 	// ProtocolName is the name of the QUIC protocol
 	ProtocolName = "solana-validator-failover"
@@ -13,11 +15,68 @@ const (
 	// DefaultStreamTimeoutDurationStr is the default stream timeout duration string
 	DefaultStreamTimeoutDurationStr = "1m"
 
+	// DefaultWaitProgressIntervalDurationStr is the default interval progress is logged at while waiting for the active node
+	DefaultWaitProgressIntervalDurationStr = "1m"
+
+	// DefaultDialRetryIntervalDurationStr is the default initial interval between client dial retries
+	DefaultDialRetryIntervalDurationStr = "1s"
+
+	// DefaultDialRetryMaxIntervalDurationStr is the default maximum interval between client dial retries
+	DefaultDialRetryMaxIntervalDurationStr = "10s"
+
+	// DefaultLogSlotContextIntervalDurationStr is the default interval slot context is sampled at for critical window
+	// log lines
+	DefaultLogSlotContextIntervalDurationStr = "400ms"
+
 	// MessageTypeFailoverInitiateRequest is the message type for initiating a failover
 	MessageTypeFailoverInitiateRequest byte = 1
 
 	// MessageTypeFileTransfer is the message type for file transfer
 	MessageTypeFileTransfer byte = 2
+
+	// MessageTypeAgentHandoverRequest is the message type for asking an agent to hand over
+	MessageTypeAgentHandoverRequest byte = 3
+
+	// MessageTypeObserveRequest is the message type for following a failover as a read-only observer
+	MessageTypeObserveRequest byte = 4
+
+	// MessageTypeConfigViewRequest is the message type for asking an agent for its node's config view
+	MessageTypeConfigViewRequest byte = 5
+
+	// MessageTypeAbortRequest is the message type for asking a passive node's failover server to abort its pending
+	// failover
+	MessageTypeAbortRequest byte = 6
+
+	// MessageTypePing is the message type for saying hello to a failover server or agent to check connectivity
+	MessageTypePing byte = 7
+
+	// MessageTypeStandbyOutcome is the message type for telling a passive node's failover server the active node failed
+	// over to another passive node
+	MessageTypeStandbyOutcome byte = 8
+
+	// MessageTypeTowerFileResume is the message type for resuming the tower file transfer after the failover
+	// connection was lost while it was sent
+	MessageTypeTowerFileResume byte = 9
+
+	// MessageTypeTowerReplica is the message type for shipping a replica of the active node's tower file to a peer's
+	// agent between failovers
+	MessageTypeTowerReplica byte = 10
+
+	// MessageTypeReleaseRequest is the message type for asking a passive node's failover server to release the
+	// failover the active node holds
+	MessageTypeReleaseRequest byte = 11
+
+	// AgentProtocolName is the name of the QUIC protocol spoken by the agent
+	AgentProtocolName = "solana-validator-failover-agent"
+
+	// DefaultAgentPort is the default port for the agent
+	DefaultAgentPort = 9897
+
+	// DefaultAgentRequestTimeout is how long a handover request may take to be answered
+	DefaultAgentRequestTimeout = 30 * time.Second
+
+	// DefaultPeerProbeTimeout is how long probing a peer's failover server may take
+	DefaultPeerProbeTimeout = 3 * time.Second
 )
 
 // hookEnvMapParams is the parameters for the hook environment map
@@ -25,4 +84,6 @@ type hookEnvMapParams struct {
 	isDryRunFailover bool
 	isPreFailover    bool
 	isPostFailover   bool
+	// abortReason is why the failover failed, for on abort hooks
+	abortReason string
 }
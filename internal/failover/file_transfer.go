@@ -0,0 +1,355 @@
+package failover
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/identities"
+	"github.com/sol-strategies/solana-validator-failover/internal/peertrust"
+	"github.com/sol-strategies/solana-validator-failover/internal/utils"
+	pkgconstants "github.com/sol-strategies/solana-validator-failover/pkg/constants"
+)
+
+const (
+	// DefaultFileTransferMaxSize is the largest file an agent accepts by default
+	DefaultFileTransferMaxSize int64 = 10 * 1024 * 1024
+
+	// DefaultFileTransferTimeout is how long pushing a file may take
+	DefaultFileTransferTimeout = 2 * time.Minute
+
+	// fileTransferCloseTimeout is how long the agent waits for the pushing peer to hang up after its last response
+	fileTransferCloseTimeout = 5 * time.Second
+)
+
+// FileTransferConfig is the configuration for files peers may push to the agent - no allowed dirs disables it
+type FileTransferConfig struct {
+	// AllowedDirs are the resolved directories a pushed file may be written to - subdirectories are not included
+	AllowedDirs []string
+	MaxSize     int64
+}
+
+// IsEnabled returns true if the agent accepts file pushes
+func (c FileTransferConfig) IsEnabled() bool {
+	return len(c.AllowedDirs) > 0
+}
+
+// FileTransferRequest is sent by a peer asking the agent to write a file - the file's bytes follow once accepted
+type FileTransferRequest struct {
+	Hostname string
+	PublicIP string
+	// Path is the absolute path the file is written to on the agent's node
+	Path string
+	Size int64
+	// SHA256 is the hex sha256 of the file the agent verifies before writing it in place
+	SHA256 string
+	// Mode is the file's permissions - exec bits are never set on the written file
+	Mode                           uint32
+	SolanaValidatorFailoverVersion string
+	ProtocolRevision               int
+	MinProtocolRevision            int
+	// ProveIdentity is set when the requester proves it holds the validator's active identity keypair straight after
+	// the request - a requester without a verified client certificate must
+	ProveIdentity bool
+}
+
+// FileTransferResponse is the agent's answer to a file transfer request, sent once before the file's bytes and once
+// after them
+type FileTransferResponse struct {
+	Accepted     bool
+	ErrorMessage string
+}
+
+// handleFileTransfer handles a file transfer request on a stream the message type has been read from
+func (a *Agent) handleFileTransfer(conn quic.Connection, stream quic.Stream) {
+	// closing the connection straight after the last response can drop it - the peer hangs up once it has read it
+	defer awaitPeerClose(conn)
+
+	transferStream := NewFailoverStream(stream)
+	encoder := transferStream.encoder
+
+	var request FileTransferRequest
+	if err := transferStream.decoder.Decode(&request); err != nil {
+		a.logger.Debug().Err(err).Msg("failed to decode file transfer request")
+		return
+	}
+
+	identityProven := false
+	if request.ProveIdentity {
+		if err := a.authenticateRequester(transferStream); err != nil {
+			a.logger.Warn().Err(err).Str("requester", request.Hostname).Str("remote_addr", conn.RemoteAddr().String()).Msg("🔒 rejected file transfer - requester authentication failed")
+			return
+		}
+		identityProven = true
+	}
+
+	peerCertName := peertrust.ClientCertificatePeerName(conn.ConnectionState().TLS)
+	peer, err := a.acceptFileTransfer(utils.HostFromAddress(conn.RemoteAddr().String()), peerCertName, identityProven, request)
+	if err != nil {
+		a.logger.Warn().Err(err).Str("requester", request.Hostname).Str("path", request.Path).Msg("rejected file transfer")
+		if encodeErr := encoder.Encode(FileTransferResponse{ErrorMessage: err.Error()}); encodeErr != nil {
+			a.logger.Debug().Err(encodeErr).Msg("failed to send file transfer response")
+		}
+		return
+	}
+	if err := encoder.Encode(FileTransferResponse{Accepted: true}); err != nil {
+		a.logger.Debug().Err(err).Msg("failed to send file transfer response")
+		return
+	}
+
+	response := FileTransferResponse{Accepted: true}
+	if err := receiveFile(stream, request); err != nil {
+		response = FileTransferResponse{ErrorMessage: err.Error()}
+		a.logger.Error().Err(err).Str("peer_name", peer.Name).Str("path", request.Path).Msg("file transfer failed")
+	} else {
+		a.logger.Info().
+			Str("peer_name", peer.Name).
+			Str("path", request.Path).
+			Int64("size", request.Size).
+			Str("sha256", request.SHA256).
+			Msgf("Received %s from %s", request.Path, peer.Name)
+	}
+	if err := encoder.Encode(response); err != nil {
+		a.logger.Debug().Err(err).Msg("failed to send file transfer response")
+	}
+}
+
+// awaitPeerClose waits for the peer to close conn, or fileTransferCloseTimeout
+func awaitPeerClose(conn quic.Connection) {
+	select {
+	case <-conn.Context().Done():
+	case <-time.After(fileTransferCloseTimeout):
+	}
+}
+
+// acceptFileTransfer validates a file transfer request - the requester must have presented a verified client
+// certificate or proven it holds the active identity keypair, be a configured peer and the file must fit the size limit
+// and go directly into an allowed dir
+func (a *Agent) acceptFileTransfer(remoteIP, peerCertName string, identityProven bool, request FileTransferRequest) (PeerInfo, error) {
+	if !a.fileTransfer.IsEnabled() {
+		return PeerInfo{}, fmt.Errorf("file transfers are disabled - set failover.agent.file_transfer.allowed_dirs to enable them")
+	}
+	if err := CheckProtocolRevision(request.SolanaValidatorFailoverVersion, request.ProtocolRevision, request.MinProtocolRevision); err != nil {
+		return PeerInfo{}, err
+	}
+
+	if peerCertName == "" && !identityProven {
+		return PeerInfo{}, fmt.Errorf("requester %s (%s) presented no client certificate and did not prove it holds the active identity keypair", request.Hostname, remoteIP)
+	}
+
+	peer, ok := matchPeer(a.peers, remoteIP, request.PublicIP, identityProven)
+	if peerCertName != "" {
+		peer, ok = a.peerByName(peerCertName)
+	}
+	if !ok {
+		return PeerInfo{}, fmt.Errorf("requester %s (%s) is not a configured peer", request.Hostname, remoteIP)
+	}
+
+	if request.Size < 0 || request.Size > a.fileTransfer.MaxSize {
+		return PeerInfo{}, fmt.Errorf("file is %d bytes, more than failover.agent.file_transfer.max_size %d", request.Size, a.fileTransfer.MaxSize)
+	}
+	if _, err := hex.DecodeString(request.SHA256); err != nil || len(request.SHA256) != sha256.Size*2 {
+		return PeerInfo{}, fmt.Errorf("invalid sha256 %q", request.SHA256)
+	}
+	if err := a.fileTransfer.checkPath(request.Path); err != nil {
+		return PeerInfo{}, err
+	}
+
+	return peer, nil
+}
+
+// checkPath ensures path is an absolute path directly inside an allowed dir - symlinks in its dir are resolved so
+// they can't lead out of one
+func (c FileTransferConfig) checkPath(path string) error {
+	if !filepath.IsAbs(path) || filepath.Clean(path) != path || strings.HasSuffix(path, string(filepath.Separator)) {
+		return fmt.Errorf("path %s must be absolute and clean", path)
+	}
+	dir, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return fmt.Errorf("failed to resolve the dir of %s: %w", path, err)
+	}
+	for _, allowedDir := range c.AllowedDirs {
+		if dir == allowedDir {
+			if info, err := os.Lstat(path); err == nil && !info.Mode().IsRegular() {
+				return fmt.Errorf("%s exists and is not a regular file", path)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is not in failover.agent.file_transfer.allowed_dirs", filepath.Dir(path))
+}
+
+// receiveFile reads the file's bytes from r into a temporary file beside its path and moves it in place once its
+// hash matches - a failed transfer leaves any existing file untouched
+func receiveFile(r io.Reader, request FileTransferRequest) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(request.Path), "."+filepath.Base(request.Path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(tmp.Name())
+		}
+	}()
+	defer utils.SafeCloseFile(tmp)
+
+	hash := sha256.New()
+	if _, err = io.CopyN(io.MultiWriter(tmp, hash), r, request.Size); err != nil {
+		return fmt.Errorf("failed to receive file: %w", err)
+	}
+	if got := hex.EncodeToString(hash.Sum(nil)); got != request.SHA256 {
+		return fmt.Errorf("sha256 mismatch - sent %s, received %s", request.SHA256, got)
+	}
+	// a peer may write files but never make them executable
+	if err = tmp.Chmod(os.FileMode(request.Mode).Perm() &^ 0111); err != nil {
+		return fmt.Errorf("failed to set file mode: %w", err)
+	}
+	if err = tmp.Sync(); err != nil {
+		return fmt.Errorf("failed to sync file: %w", err)
+	}
+	if err = os.Rename(tmp.Name(), request.Path); err != nil {
+		return fmt.Errorf("failed to move file in place: %w", err)
+	}
+	return nil
+}
+
+// PushFileParams are the parameters for pushing a file to a peer's agent
+type PushFileParams struct {
+	AgentName    string
+	AgentAddress string
+	Hostname     string
+	PublicIP     string
+	// LocalPath is the file to push and RemotePath the absolute path it is written to on the peer
+	LocalPath  string
+	RemotePath string
+	// PeerPins pins the agent's certificate fingerprint on first use and requires it after - nil disables pinning
+	PeerPins *peertrust.Store
+	// ClientCertificate is presented to the agent when it requires client certificates - nil presents none
+	ClientCertificate *tls.Certificate
+	// ActiveIdentity is the validator's active identity this node proves it holds to the agent - nil proves nothing,
+	// leaving the client certificate to authenticate the push
+	ActiveIdentity *identities.Identity
+}
+
+// PushFile pushes a file to the agent on a peer, which verifies its hash before writing it in place
+func PushFile(params PushFileParams) (err error) {
+	file, err := os.Open(params.LocalPath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", params.LocalPath, err)
+	}
+	defer utils.SafeCloseFile(file)
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", params.LocalPath, err)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a regular file", params.LocalPath)
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return fmt.Errorf("failed to hash %s: %w", params.LocalPath, err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind %s: %w", params.LocalPath, err)
+	}
+
+	request := FileTransferRequest{
+		Hostname:                       params.Hostname,
+		PublicIP:                       params.PublicIP,
+		Path:                           params.RemotePath,
+		Size:                           info.Size(),
+		SHA256:                         hex.EncodeToString(hash.Sum(nil)),
+		Mode:                           uint32(info.Mode().Perm()),
+		SolanaValidatorFailoverVersion: pkgconstants.AppVersion,
+		ProtocolRevision:               ProtocolRevision,
+		MinProtocolRevision:            MinProtocolRevision,
+		ProveIdentity:                  params.ActiveIdentity != nil,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultFileTransferTimeout)
+	defer cancel()
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: true,
+		NextProtos:         []string{AgentProtocolName},
+	}
+	if params.ClientCertificate != nil {
+		tlsConfig.Certificates = []tls.Certificate{*params.ClientCertificate}
+	}
+	var fingerprint string
+	if params.PeerPins != nil {
+		tlsConfig.VerifyPeerCertificate = params.PeerPins.VerifyPeerCertificate(params.AgentName, &fingerprint)
+	}
+
+	conn, err := quic.DialAddr(ctx, params.AgentAddress, tlsConfig, nil)
+	if err != nil {
+		return fmt.Errorf("failed to connect to agent on %s at %s: %w", params.AgentName, params.AgentAddress, err)
+	}
+	defer conn.CloseWithError(0, "done")
+
+	stream, err := conn.OpenStreamSync(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open stream to agent: %w", err)
+	}
+	defer stream.Close()
+	if err := stream.SetDeadline(time.Now().Add(DefaultFileTransferTimeout)); err != nil {
+		return err
+	}
+
+	if _, err := stream.Write([]byte{MessageTypeFileTransfer}); err != nil {
+		return fmt.Errorf("failed to send file transfer request: %w", err)
+	}
+	transferStream := NewFailoverStream(stream)
+	encoder := transferStream.encoder
+	decoder := transferStream.decoder
+	if err := encoder.Encode(request); err != nil {
+		return fmt.Errorf("failed to send file transfer request: %w", err)
+	}
+	if request.ProveIdentity {
+		if err := transferStream.AuthenticateAsActive(params.ActiveIdentity); err != nil {
+			return fmt.Errorf("agent on %s refused this node's identity: %w", params.AgentName, err)
+		}
+	}
+
+	var response FileTransferResponse
+	if err := decoder.Decode(&response); err != nil {
+		return fmt.Errorf("failed to read file transfer response: %w", err)
+	}
+	if !response.Accepted {
+		return fmt.Errorf("agent on %s rejected file transfer: %s", params.AgentName, response.ErrorMessage)
+	}
+
+	if _, err := io.CopyN(stream, file, request.Size); err != nil {
+		return fmt.Errorf("failed to send %s: %w", params.LocalPath, err)
+	}
+	if err := decoder.Decode(&response); err != nil {
+		return fmt.Errorf("failed to read file transfer result: %w", err)
+	}
+	if !response.Accepted {
+		return fmt.Errorf("agent on %s failed to write %s: %s", params.AgentName, params.RemotePath, response.ErrorMessage)
+	}
+
+	if params.PeerPins != nil {
+		if err := params.PeerPins.Pin(params.AgentName, fingerprint); err != nil {
+			log.Warn().Err(err).Msgf("failed to pin certificate for %s", params.AgentName)
+		}
+	}
+
+	log.Info().
+		Str("peer_name", params.AgentName).
+		Str("path", params.RemotePath).
+		Int64("size", request.Size).
+		Str("sha256", request.SHA256).
+		Msgf("Pushed %s to %s:%s", params.LocalPath, params.AgentName, params.RemotePath)
+	return nil
+}
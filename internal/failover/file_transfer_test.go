@@ -0,0 +1,131 @@
+package failover
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFileTransferAgent returns an agent accepting pushes into a temporary dir from a single peer at 10.0.0.2
+func newFileTransferAgent(t *testing.T) (*Agent, string) {
+	t.Helper()
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	return &Agent{
+		peers:        []PeerInfo{{Name: "peer", Address: "10.0.0.2:9898"}},
+		fileTransfer: FileTransferConfig{AllowedDirs: []string{dir}, MaxSize: 1024},
+	}, dir
+}
+
+// fileTransferRequest returns a request to push content to path
+func fileTransferRequest(path string, content []byte) FileTransferRequest {
+	hash := sha256.Sum256(content)
+	return FileTransferRequest{
+		Hostname:            "peer",
+		PublicIP:            "10.0.0.2",
+		Path:                path,
+		Size:                int64(len(content)),
+		SHA256:              hex.EncodeToString(hash[:]),
+		Mode:                0755,
+		ProtocolRevision:    ProtocolRevision,
+		MinProtocolRevision: MinProtocolRevision,
+	}
+}
+
+func TestAcceptFileTransfer(t *testing.T) {
+	agent, dir := newFileTransferAgent(t)
+	request := fileTransferRequest(filepath.Join(dir, "hook.sh"), []byte("echo hi"))
+
+	peer, err := agent.acceptFileTransfer("10.0.0.2", "", true, request)
+	require.NoError(t, err)
+	assert.Equal(t, "peer", peer.Name)
+
+	peer, err = agent.acceptFileTransfer("10.9.9.9", "peer", false, request)
+	require.NoError(t, err)
+	assert.Equal(t, "peer", peer.Name)
+}
+
+func TestAcceptFileTransfer_SpoofedPublicIP(t *testing.T) {
+	agent, dir := newFileTransferAgent(t)
+	request := fileTransferRequest(filepath.Join(dir, "hook.sh"), []byte("echo hi"))
+
+	// claiming the peer's public IP proves nothing
+	_, err := agent.acceptFileTransfer("10.9.9.9", "", false, request)
+	assert.ErrorContains(t, err, "did not prove it holds the active identity keypair")
+
+	// nor does connecting from it without a client certificate or proof of identity
+	_, err = agent.acceptFileTransfer("10.0.0.2", "", false, request)
+	assert.ErrorContains(t, err, "did not prove it holds the active identity keypair")
+
+	// a client certificate for a name that isn't a peer
+	_, err = agent.acceptFileTransfer("10.0.0.2", "stranger", false, request)
+	assert.ErrorContains(t, err, "is not a configured peer")
+}
+
+func TestAcceptFileTransfer_PathEscapes(t *testing.T) {
+	agent, dir := newFileTransferAgent(t)
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0700))
+	outside := t.TempDir()
+	require.NoError(t, os.Symlink(outside, filepath.Join(dir, "link")))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "target"), filepath.Join(dir, "symlinked-file")))
+
+	for name, path := range map[string]string{
+		"relative":           "hook.sh",
+		"dot dot":            dir + "/../hook.sh",
+		"unclean":            dir + "//hook.sh",
+		"trailing slash":     dir + "/hook.sh/",
+		"subdir":             filepath.Join(dir, "sub", "hook.sh"),
+		"symlinked dir":      filepath.Join(dir, "link", "hook.sh"),
+		"symlink in place":   filepath.Join(dir, "symlinked-file"),
+		"other dir":          filepath.Join(outside, "hook.sh"),
+		"allowed dir itself": dir,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := agent.acceptFileTransfer("10.0.0.2", "peer", false, fileTransferRequest(path, []byte("echo hi")))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestAcceptFileTransfer_Oversize(t *testing.T) {
+	agent, dir := newFileTransferAgent(t)
+
+	request := fileTransferRequest(filepath.Join(dir, "big"), bytes.Repeat([]byte("x"), 1025))
+	_, err := agent.acceptFileTransfer("10.0.0.2", "peer", false, request)
+	assert.ErrorContains(t, err, "more than failover.agent.file_transfer.max_size")
+
+	request.Size = -1
+	_, err = agent.acceptFileTransfer("10.0.0.2", "peer", false, request)
+	assert.Error(t, err)
+}
+
+func TestReceiveFile_StripsExecBits(t *testing.T) {
+	dir := t.TempDir()
+	content := []byte("#!/bin/sh\necho hi\n")
+	request := fileTransferRequest(filepath.Join(dir, "hook.sh"), content)
+
+	require.NoError(t, receiveFile(bytes.NewReader(content), request))
+
+	info, err := os.Stat(request.Path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+}
+
+func TestReceiveFile_HashMismatchLeavesFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("old"), 0600))
+
+	request := fileTransferRequest(path, []byte("new"))
+	assert.ErrorContains(t, receiveFile(bytes.NewReader([]byte("bad")), request), "sha256 mismatch")
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(content))
+}
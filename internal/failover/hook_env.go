@@ -0,0 +1,50 @@
+package failover
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// result values hooks get in RESULT
+const (
+	hookResultOK      = "ok"
+	hookResultFailed  = "failed"
+	hookResultAborted = "aborted"
+)
+
+// setHookEnv sets the env variables both nodes give their hooks from what the stream knows of the failover so far -
+// thisNode is the node running them
+func (s *Stream) setHookEnv(envMap map[string]string, params hookEnvMapParams, thisNode *NodeInfo) {
+	envMap["THIS_NODE_TOWER_FILE"] = thisNode.TowerFile
+
+	// known once the active node picked the slot to start at and read its tower file
+	if startSlot := s.GetFailoverStartSlot(); startSlot > 0 {
+		envMap["FAILOVER_START_SLOT"] = strconv.FormatUint(startSlot, 10)
+	}
+	if towerFileHash := s.message.ActiveNodeInfo.TowerFileHash; towerFileHash != "" {
+		envMap["TOWER_FILE_HASH"] = towerFileHash
+	}
+
+	if params.abortReason != "" {
+		envMap["RESULT"] = hookResultAborted
+	} else if params.isPostFailover {
+		envMap["RESULT"] = hookResultFailed
+		if s.message.IsSuccessfullyCompleted {
+			envMap["RESULT"] = hookResultOK
+		}
+	}
+
+	if !params.isPostFailover {
+		return
+	}
+	m := s.message
+	envMap["FAILOVER_END_SLOT"] = strconv.FormatUint(m.FailoverEndSlot, 10)
+	envMap["FAILOVER_SLOTS"] = strconv.FormatUint(s.GetFailoverSlotsDuration(), 10)
+	envMap["FAILOVER_DURATION_MS"] = strconv.FormatInt(s.GetFailoverDuration().Milliseconds(), 10)
+	envMap["ACTIVE_SET_IDENTITY_DURATION_MS"] = strconv.FormatInt(m.ActiveNodeSetIdentityEndTime.Sub(m.ActiveNodeSetIdentityStartTime).Milliseconds(), 10)
+	envMap["TOWER_FILE_SYNC_DURATION_MS"] = strconv.FormatInt(m.PassiveNodeSyncTowerFileEndTime.Sub(m.ActiveNodeSyncTowerFileStartTime).Milliseconds(), 10)
+	envMap["PASSIVE_SET_IDENTITY_DURATION_MS"] = strconv.FormatInt(m.PassiveNodeSetIdentityEndTime.Sub(m.PassiveNodeSetIdentityStartTime).Milliseconds(), 10)
+	if result, err := json.Marshal(s.GetResult()); err == nil {
+		envMap["RESULT_JSON"] = string(result)
+	}
+}
@@ -0,0 +1,99 @@
+package failover
+
+import (
+	"encoding/json"
+	"maps"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetHookEnv(t *testing.T) {
+	postFailover := map[string]string{
+		"THIS_NODE_TOWER_FILE":             "/tower/tower-1_9-active.bin",
+		"FAILOVER_START_SLOT":              "1000",
+		"TOWER_FILE_HASH":                  "hash",
+		"FAILOVER_END_SLOT":                "1004",
+		"FAILOVER_SLOTS":                   "4",
+		"FAILOVER_DURATION_MS":             "400",
+		"ACTIVE_SET_IDENTITY_DURATION_MS":  "100",
+		"TOWER_FILE_SYNC_DURATION_MS":      "50",
+		"PASSIVE_SET_IDENTITY_DURATION_MS": "250",
+	}
+
+	tests := []struct {
+		name       string
+		notStarted bool
+		failed     bool
+		params     hookEnvMapParams
+		want       map[string]string
+	}{
+		{
+			name:       "pre hooks before the start slot is picked",
+			notStarted: true,
+			params:     hookEnvMapParams{isPreFailover: true},
+			want:       map[string]string{"THIS_NODE_TOWER_FILE": "/tower/tower-1_9-active.bin"},
+		},
+		{
+			name:   "pre hooks once the start slot is picked",
+			params: hookEnvMapParams{isPreFailover: true},
+			want: map[string]string{
+				"THIS_NODE_TOWER_FILE": "/tower/tower-1_9-active.bin",
+				"FAILOVER_START_SLOT":  "1000",
+				"TOWER_FILE_HASH":      "hash",
+			},
+		},
+		{
+			name:       "aborted before the start slot is picked",
+			notStarted: true,
+			params:     hookEnvMapParams{abortReason: "operator asked"},
+			want: map[string]string{
+				"THIS_NODE_TOWER_FILE": "/tower/tower-1_9-active.bin",
+				"RESULT":               hookResultAborted,
+			},
+		},
+		{
+			name:   "post hooks",
+			params: hookEnvMapParams{isPostFailover: true},
+			want:   withHookEnv(postFailover, "RESULT", hookResultOK),
+		},
+		{
+			name:   "post hooks of a failed failover",
+			failed: true,
+			params: hookEnvMapParams{isPostFailover: true},
+			want:   withHookEnv(postFailover, "RESULT", hookResultFailed),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newResultStream()
+			if tt.notStarted {
+				s.message.FailoverStartSlot = 0
+				s.message.ActiveNodeInfo.TowerFileHash = ""
+			}
+			s.message.IsSuccessfullyCompleted = !tt.failed
+
+			envMap := map[string]string{}
+			s.setHookEnv(envMap, tt.params, &NodeInfo{TowerFile: "/tower/tower-1_9-active.bin"})
+
+			// the result document is the stream's result as json
+			if tt.params.isPostFailover {
+				var result Result
+				require.NoError(t, json.Unmarshal([]byte(envMap["RESULT_JSON"]), &result))
+				assert.Equal(t, s.GetResult(), result)
+				delete(envMap, "RESULT_JSON")
+			} else {
+				assert.NotContains(t, envMap, "RESULT_JSON")
+			}
+			assert.Equal(t, tt.want, envMap)
+		})
+	}
+}
+
+// withHookEnv returns a copy of envMap with key set to value
+func withHookEnv(envMap map[string]string, key, value string) map[string]string {
+	copied := maps.Clone(envMap)
+	copied[key] = value
+	return copied
+}
@@ -1,10 +1,13 @@
 package failover
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/table"
+	"github.com/sol-strategies/solana-validator-failover/internal/hooks"
+	"github.com/sol-strategies/solana-validator-failover/internal/solana"
 	"github.com/sol-strategies/solana-validator-failover/internal/style"
 )
 
@@ -28,6 +31,43 @@ type Message struct {
 	// key is the identity pubkey
 	CreditSamples CreditSamples
 	MonitorConfig                    MonitorConfig
+	// critical rpc calls made by either node during the failover
+	RPCCalls []RPCCallRecord
+	// hook and verification phases run by either node, in the order they ran
+	PhaseTimings []PhaseTiming
+	// local rpc metrics snapshots either side of the failover when enabled
+	PreFailoverMetrics  *MetricsSnapshot
+	PostFailoverMetrics *MetricsSnapshot
+	// verdicts of the passive node's check hooks - any that disallow the failover veto it
+	CheckResults []hooks.CheckResult
+	// short random id the passive node gives the failover to tell it apart in logs, events and chat
+	FailoverID string
+	// set by the passive node when it refuses a node that isn't an allowed peer
+	PeerRejection *PeerRejection
+	// how long both nodes count down to the switch - set by the passive node, zero switches without one
+	SwitchCountdown time.Duration
+	// the moment the active node switches identity - set by the active node, counted down to by both
+	SwitchAt time.Time
+	// the moment the failover is scheduled to switch at - set by whichever node scheduled it, both nodes wait for it
+	// after confirmation, zero switches as soon as the active node is ready
+	ScheduledSwitchAt time.Time
+	// set by the active node when it holds the failover once confirmed, sending WarmHoldHeartbeat values until its
+	// operator triggers the switch
+	WarmHold bool
+	// w3c traceparent of the active node's failover span - set by the active node when it traces so the passive
+	// node's spans join the same trace
+	TraceParent string
+	// largest packet found to reach the passive node and come back - set by the active node when it probed
+	PathMTUProbe PathMTUProbeResult
+	// set by the passive node when the active identity was already delinquent before the failover - its vote credit
+	// rank then is no baseline for the post-failover monitor
+	ActiveIdentityDelinquent bool
+	// the active identity's block production in its first leader slots after the failover - set by the passive node
+	// during post-failover monitoring
+	BlockProduction *BlockProductionCheck
+	// the active identity's vote account before the failover - set by the passive node so operators can check they
+	// are moving the validator they think they are
+	ActiveVoteAccount solana.VoteAccountStatus
 }
 
 func (m *Message) currentStateTableString() string {
@@ -44,14 +84,14 @@ func (m *Message) currentStateTableString() string {
 			activeNodeInfo.Hostname,
 			activeNodeInfo.PublicIP,
 			activeNodeInfo.Identities.Active.PubKey(),
-			activeNodeInfo.ClientVersion,
+			activeNodeInfo.clientString(),
 		},
 		{
 			"passive",
 			passiveNodeInfo.Hostname,
 			passiveNodeInfo.PublicIP,
 			passiveNodeInfo.Identities.Passive.PubKey(),
-			passiveNodeInfo.ClientVersion,
+			passiveNodeInfo.clientString(),
 		},
 	}
 	if m.IsSuccessfullyCompleted && !m.IsDryRunFailover {
@@ -89,9 +129,49 @@ func (m *Message) currentStateTableString() string {
 			case 3: // pubkey
 				return rowStyle.Width(46)
 			case 4: // ClientVersion
-				return rowStyle.Width(18)
+				return rowStyle.Width(28)
 			}
 			return rowStyle
 		},
+	) + m.voteAccountTableString()
+}
+
+// voteAccountTableString returns a table of the active identity's vote account before the failover - empty when it
+// wasn't found
+func (m *Message) voteAccountTableString() string {
+	voteAccount := m.ActiveVoteAccount
+	if !voteAccount.Found {
+		return ""
+	}
+
+	status := "current"
+	if voteAccount.Delinquent {
+		status = "delinquent"
+	}
+	rows := [][]string{
+		{
+			voteAccount.VotePubkey,
+			fmt.Sprintf(
+				"%s SOL (%.2f%%)",
+				style.FormatInt(uint64(solana.LamportsToSOL(voteAccount.ActivatedStake))),
+				voteAccount.StakeWeight,
+			),
+			fmt.Sprintf("%d%%", voteAccount.Commission),
+			style.FormatInt(voteAccount.LastVote),
+			status,
+		},
+	}
+	return "\n" + style.RenderTable(
+		[]string{"VoteAccount", "ActivatedStake", "Commission", "LastVote", "Status"},
+		rows,
+		func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return style.TableHeaderStyle
+			}
+			if voteAccount.Delinquent && col == 4 {
+				return style.TableCellStyle.Foreground(style.ColorWarning)
+			}
+			return style.TableCellStyle
+		},
 	)
 }
@@ -0,0 +1,132 @@
+package failover
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/sol-strategies/solana-validator-failover/internal/solana"
+	"github.com/sol-strategies/solana-validator-failover/internal/style"
+)
+
+// MetricsSnapshot is a snapshot of the active identity's metrics taken from the local rpc before or after a failover
+type MetricsSnapshot struct {
+	solana.ValidatorMetrics
+	Timestamp time.Time
+}
+
+// PullMetricsSnapshot takes a snapshot of the active identity's metrics from the local rpc - the post-failover
+// snapshot's block production only covers slots since the failover ended so the two can be compared
+func (s *Stream) PullMetricsSnapshot(solanaRPCClient solana.ClientInterface, isPostFailover bool) (err error) {
+	var firstSlot uint64
+	if isPostFailover {
+		firstSlot = s.message.FailoverEndSlot
+	}
+
+	metrics, err := solanaRPCClient.GetLocalValidatorMetrics(
+		s.message.ActiveNodeInfo.Identities.Active.PublicKey(),
+		firstSlot,
+		s.message.MonitorConfig.MetricsSnapshot.BlockProductionSlots,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to get local validator metrics: %w", err)
+	}
+
+	snapshot := &MetricsSnapshot{
+		ValidatorMetrics: *metrics,
+		Timestamp:        time.Now(),
+	}
+	if isPostFailover {
+		s.message.PostFailoverMetrics = snapshot
+	} else {
+		s.message.PreFailoverMetrics = snapshot
+	}
+	return nil
+}
+
+// GetPreFailoverMetrics returns the pre-failover metrics snapshot - nil when not taken
+func (s *Stream) GetPreFailoverMetrics() *MetricsSnapshot {
+	return s.message.PreFailoverMetrics
+}
+
+// GetPostFailoverMetrics returns the post-failover metrics snapshot - nil when not taken
+func (s *Stream) GetPostFailoverMetrics() *MetricsSnapshot {
+	return s.message.PostFailoverMetrics
+}
+
+// GetMetricsComparisonTableString returns a table comparing the pre and post-failover metrics snapshots
+func (s *Stream) GetMetricsComparisonTableString() (string, error) {
+	pre := s.message.PreFailoverMetrics
+	post := s.message.PostFailoverMetrics
+	if pre == nil || post == nil {
+		return "", fmt.Errorf("pre and post-failover metrics snapshots are required to compare them")
+	}
+
+	rows := [][]string{
+		{
+			"Slot",
+			style.FormatInt(pre.Slot),
+			style.FormatInt(post.Slot),
+			style.FormatSignedInt(int64(post.Slot) - int64(pre.Slot)),
+		},
+		{
+			"Transactions",
+			style.FormatInt(pre.TransactionCount),
+			style.FormatInt(post.TransactionCount),
+			style.FormatSignedInt(int64(post.TransactionCount) - int64(pre.TransactionCount)),
+		},
+		{
+			"Block production slots",
+			fmt.Sprintf("%s-%s", style.FormatInt(pre.BlockProductionFirstSlot), style.FormatInt(pre.Slot)),
+			fmt.Sprintf("%s-%s", style.FormatInt(post.BlockProductionFirstSlot), style.FormatInt(post.Slot)),
+			"",
+		},
+		{
+			"Leader slots",
+			style.FormatInt(pre.LeaderSlots),
+			style.FormatInt(post.LeaderSlots),
+			style.FormatSignedInt(int64(post.LeaderSlots) - int64(pre.LeaderSlots)),
+		},
+		{
+			"Blocks produced",
+			style.FormatInt(pre.BlocksProduced),
+			style.FormatInt(post.BlocksProduced),
+			style.FormatSignedInt(int64(post.BlocksProduced) - int64(pre.BlocksProduced)),
+		},
+		{
+			"Skip rate",
+			fmt.Sprintf("%.1f%%", pre.SkipRate()),
+			fmt.Sprintf("%.1f%%", post.SkipRate()),
+			fmt.Sprintf("%+.1f%%", post.SkipRate()-pre.SkipRate()),
+		},
+	}
+
+	// vote credits come from the credit samples taken either side of the failover
+	samples := s.message.CreditSamples[s.message.ActiveNodeInfo.Identities.Active.PubKey()]
+	if len(samples) >= 2 {
+		first := samples[0]
+		last := samples[len(samples)-1]
+		rows = append(rows, []string{
+			"Epoch vote credits",
+			style.FormatInt(first.Credits),
+			style.FormatInt(last.Credits),
+			style.FormatSignedInt(last.Credits - first.Credits),
+		})
+	}
+
+	return style.RenderTable(
+		[]string{"Metric", "Pre-failover", "Post-failover", "Change"},
+		rows,
+		func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return style.TableHeaderStyle
+			}
+			// a worse skip rate is what the switch cost
+			if col == 3 && rows[row][0] == "Skip rate" && post.SkipRate() > pre.SkipRate() {
+				return style.TableCellStyle.Align(lipgloss.Left).Foreground(style.ColorWarning)
+			}
+			return style.TableCellStyle.Align(lipgloss.Left)
+		},
+	), nil
+}
@@ -0,0 +1,356 @@
+package failover
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/constants"
+	"github.com/sol-strategies/solana-validator-failover/internal/identities"
+	"github.com/sol-strategies/solana-validator-failover/internal/style"
+	"github.com/sol-strategies/solana-validator-failover/internal/tower"
+	pkgconstants "github.com/sol-strategies/solana-validator-failover/pkg/constants"
+)
+
+const (
+	// MockPeerScriptHappy completes the failover
+	MockPeerScriptHappy = "happy"
+	// MockPeerScriptRefuse refuses the failover at handshake like a vetoing check hook - passive only
+	MockPeerScriptRefuse = "refuse"
+	// MockPeerScriptDisconnect drops the connection in the critical window - once the passive node has the tower
+	// file, or once the active node has the go-ahead
+	MockPeerScriptDisconnect = "disconnect"
+	// MockPeerScriptFail reports failing to set identity after receiving the tower file - passive only
+	MockPeerScriptFail = "fail"
+	// MockPeerScriptBadTower sends a tower file that doesn't match its hash - active only
+	MockPeerScriptBadTower = "bad-tower"
+)
+
+// mockPeerHangUpTimeout is how long the passive mock peer waits for the active node to hang up once it's done
+const mockPeerHangUpTimeout = 5 * time.Second
+
+// mockPeerScriptRoles are the roles each mock peer script can be played in
+var mockPeerScriptRoles = map[string][]string{
+	MockPeerScriptHappy:      {constants.NodeRoleActive, constants.NodeRolePassive},
+	MockPeerScriptRefuse:     {constants.NodeRolePassive},
+	MockPeerScriptDisconnect: {constants.NodeRoleActive, constants.NodeRolePassive},
+	MockPeerScriptFail:       {constants.NodeRolePassive},
+	MockPeerScriptBadTower:   {constants.NodeRoleActive},
+}
+
+// MockPeerScripts returns the names of the scripts a mock peer can follow, sorted
+func MockPeerScripts() []string {
+	scripts := make([]string, 0, len(mockPeerScriptRoles))
+	for script := range mockPeerScriptRoles {
+		scripts = append(scripts, script)
+	}
+	sort.Strings(scripts)
+	return scripts
+}
+
+// MockPeerConfig is the configuration for a mock peer
+type MockPeerConfig struct {
+	// Role is the role the mock peer plays - passive listens for the active node, active connects to a passive node
+	Role   string
+	Script string
+	// Port is listened on when passive
+	Port int
+	// ServerAddress is connected to when active
+	ServerAddress string
+	Hostname      string
+	PublicIP      string
+	// Identities must hold the validator's active identity keypair - peers authenticate with it before anything else
+	Identities *identities.Identities
+	// StepDelay is slept before each step so hook and ui work can watch the other side wait
+	StepDelay time.Duration
+}
+
+// MockPeer pretends to be the other side of a failover over the real wire protocol without touching a validator -
+// it never sets identities or writes a tower file and only takes part in dry runs
+type MockPeer struct {
+	config   MockPeerConfig
+	nodeInfo *NodeInfo
+	logger   zerolog.Logger
+}
+
+// NewMockPeer creates a new mock peer from a configuration
+func NewMockPeer(config MockPeerConfig) (*MockPeer, error) {
+	roles, ok := mockPeerScriptRoles[config.Script]
+	if !ok {
+		return nil, fmt.Errorf("unknown mock peer script %q - must be one of %s", config.Script, strings.Join(MockPeerScripts(), ", "))
+	}
+	if !slices.Contains(roles, config.Role) {
+		return nil, fmt.Errorf("mock peer script %s can't be played as %s - it can be played as %s", config.Script, config.Role, strings.Join(roles, " or "))
+	}
+	if config.Identities == nil || config.Identities.Active == nil || config.Identities.Passive == nil {
+		return nil, fmt.Errorf("mock peer needs the validator's active and passive identities")
+	}
+	if config.Role == constants.NodeRoleActive && config.Identities.Active.IsRemote() {
+		return nil, fmt.Errorf("mock peer needs the active identity keypair file to play the active node - it signs its mock tower file with it")
+	}
+	if config.Port == 0 {
+		config.Port = DefaultPort
+	}
+
+	return &MockPeer{
+		config: config,
+		nodeInfo: &NodeInfo{
+			PublicIP:                       config.PublicIP,
+			Hostname:                       config.Hostname,
+			Identities:                     NewNodeIdentities(config.Identities),
+			TowerFile:                      "/dev/null",
+			SetIdentityCommand:             "true",
+			ClientVersion:                  "mock",
+			SolanaValidatorFailoverVersion: pkgconstants.AppVersion,
+			Role:                           config.Role,
+			ProtocolRevision:               ProtocolRevision,
+			MinProtocolRevision:            MinProtocolRevision,
+			Capabilities:                   capabilities(),
+		},
+		logger: log.With().Str("component", "mock-peer").Str("script", config.Script).Logger(),
+	}, nil
+}
+
+// Start plays the mock peer's script once and returns once the failover is over
+func (m *MockPeer) Start() error {
+	if m.config.Role == constants.NodeRolePassive {
+		return m.startPassive()
+	}
+	return m.startActive()
+}
+
+// step logs what the mock peer is about to do and sleeps the step delay
+func (m *MockPeer) step(format string, a ...any) {
+	m.logger.Info().Msgf("🎭 "+format, a...)
+	if m.config.StepDelay > 0 {
+		time.Sleep(m.config.StepDelay)
+	}
+}
+
+// startPassive listens for the active node and plays the passive side of the failover with the first one to connect
+func (m *MockPeer) startPassive() error {
+	tlsCert, err := serverCertificate(nil)
+	if err != nil {
+		return err
+	}
+	listener, err := quic.ListenAddr(
+		fmt.Sprintf(":%d", m.config.Port),
+		&tls.Config{Certificates: []tls.Certificate{tlsCert}, NextProtos: []string{ProtocolName}},
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create listener: %v", err)
+	}
+	defer listener.Close()
+
+	m.logger.Info().Msgf("Mock %s peer listening on port %d - run this program on the ACTIVE node to continue",
+		style.RenderPassiveString(constants.NodeRolePassive, false), m.config.Port)
+
+	conn, err := listener.Accept(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to accept connection: %w", err)
+	}
+	quicStream, err := conn.AcceptStream(context.Background())
+	if err != nil {
+		conn.CloseWithError(0, "mock peer done")
+		return fmt.Errorf("failed to accept stream: %w", err)
+	}
+	defer m.hangUp(conn, quicStream)
+
+	msgType := make([]byte, 1)
+	if _, err := io.ReadFull(quicStream, msgType); err != nil {
+		return fmt.Errorf("failed to read message type: %w", err)
+	}
+	if msgType[0] != MessageTypeFailoverInitiateRequest {
+		return fmt.Errorf("unexpected message type %d", msgType[0])
+	}
+
+	stream := NewFailoverStream(quicStream)
+	if err := stream.AuthenticateAsPassive(m.config.Identities.Active); err != nil {
+		return err
+	}
+	if err := stream.Decode(); err != nil {
+		return err
+	}
+	m.step("%s connected - handing it a dry run", stream.GetActiveNodeInfo().Hostname)
+
+	stream.SetIsDryRunFailover(true)
+	stream.SetFailoverID(newFailoverID())
+	stream.SetPassiveNodeInfo(m.nodeInfo)
+
+	if m.config.Script == MockPeerScriptRefuse {
+		m.step("Refusing the failover")
+		stream.SetErrorMessagef("server cancelled failover: mock peer refused it (script %s)", m.config.Script)
+		return stream.Encode()
+	}
+
+	m.step("Giving the go-ahead")
+	stream.SetCanProceed(true)
+	if err := stream.Encode(); err != nil {
+		return err
+	}
+
+	if err := stream.receiveTowerFile(); err != nil {
+		return fmt.Errorf("failed to receive tower file: %w", err)
+	}
+	activeNodeInfo := stream.GetActiveNodeInfo()
+	if err := activeNodeInfo.decodeTowerFile(); err != nil {
+		return err
+	}
+	if activeNodeInfo.ComputeTowerFileHashFromBytes(activeNodeInfo.TowerFileBytes) != activeNodeInfo.TowerFileHash {
+		return fmt.Errorf("tower file hash mismatch - got %d bytes not matching %s", len(activeNodeInfo.TowerFileBytes), activeNodeInfo.TowerFileHash)
+	}
+	if _, err := stream.verifyTowerFileSignature(m.config.Identities.Active); err != nil {
+		return err
+	}
+	if _, err := tower.Parse(activeNodeInfo.TowerFileBytes, m.config.Identities.Active.PublicKey()); err != nil {
+		return fmt.Errorf("tower file failed its sanity check: %w", err)
+	}
+	stream.SetPassiveNodeSyncTowerFileEndTime()
+	m.step("Received %d byte tower file - discarding it", len(activeNodeInfo.TowerFileBytes))
+
+	if m.config.Script == MockPeerScriptDisconnect {
+		m.step("Dropping the connection")
+		return conn.CloseWithError(1, "mock peer disconnected")
+	}
+
+	stream.SetPassiveNodeSetIdentityStartTime()
+	m.step("(dry run) Setting identity to %s", style.RenderActiveString(strings.ToUpper(constants.NodeRoleActive), false))
+	stream.SetPassiveNodeSetIdentityEndTime()
+	stream.SetFailoverEndSlot(stream.GetFailoverStartSlot())
+
+	if m.config.Script == MockPeerScriptFail {
+		m.step("Reporting failure")
+		stream.SetErrorMessagef("mock peer failed to set identity to active (script %s)", m.config.Script)
+		return stream.Encode()
+	}
+
+	stream.SetIsSuccessfullyCompleted(true)
+	if err := stream.Encode(); err != nil {
+		return err
+	}
+	m.logger.Info().Str("summary", stream.GetSummaryLine()).Msg("🎭 Failover complete")
+	return nil
+}
+
+// hangUp closes the stream then waits for the active node to hang up before closing the connection - closing it
+// straight away can discard the last message before the active node reads it
+func (m *MockPeer) hangUp(conn quic.Connection, stream quic.Stream) {
+	stream.Close()
+	select {
+	case <-conn.Context().Done():
+	case <-time.After(mockPeerHangUpTimeout):
+	}
+	conn.CloseWithError(0, "mock peer done")
+}
+
+// startActive connects to a passive node and plays the active side of the failover with it
+func (m *MockPeer) startActive() error {
+	conn, err := quic.DialAddr(context.Background(), m.config.ServerAddress, &tls.Config{
+		InsecureSkipVerify: true,
+		NextProtos:         []string{ProtocolName},
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", m.config.ServerAddress, err)
+	}
+	defer conn.CloseWithError(0, "mock peer done")
+
+	quicStream, err := conn.OpenStreamSync(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to open stream: %w", err)
+	}
+	defer quicStream.Close()
+
+	if _, err := quicStream.Write([]byte{MessageTypeFailoverInitiateRequest}); err != nil {
+		return fmt.Errorf("failed to send message type: %w", err)
+	}
+
+	stream := NewFailoverStream(quicStream)
+	if err := stream.AuthenticateAsActive(m.config.Identities.Active); err != nil {
+		return err
+	}
+	m.step("Connected to %s - asking it to take over", m.config.ServerAddress)
+
+	stream.SetActiveNodeInfo(m.nodeInfo)
+	if err := stream.Encode(); err != nil {
+		return err
+	}
+	if err := stream.Decode(); err != nil {
+		return fmt.Errorf("failed to wait for failover signal: %w", err)
+	}
+
+	if rejection := stream.GetPeerRejection(); rejection != nil {
+		return rejection
+	}
+	if !stream.GetCanProceed() {
+		return fmt.Errorf("%s refused the failover: %s", stream.GetPassiveNodeInfo().Hostname, stream.GetErrorMessage())
+	}
+	// a passive node running --not-a-drill would really set its identity - hang up before it does
+	if !stream.GetIsDryRunFailover() {
+		return fmt.Errorf("%s is running a real failover - the mock peer only takes part in dry runs", stream.GetPassiveNodeInfo().Hostname)
+	}
+	m.step("%s gave the go-ahead", stream.GetPassiveNodeInfo().Hostname)
+
+	if scheduledSwitchAt := stream.GetScheduledSwitchAt(); !scheduledSwitchAt.IsZero() {
+		if err := waitForScheduledSwitch(conn.Context(), m.logger, scheduledSwitchAt, stream.GetPassiveNodeInfo().Hostname); err != nil {
+			return err
+		}
+	}
+
+	if countdown := stream.GetSwitchCountdown(); countdown > 0 {
+		switchAt := time.Now().UTC().Add(countdown)
+		stream.SetSwitchAt(switchAt)
+		if err := stream.Encode(); err != nil {
+			return err
+		}
+		if err := countDownToSwitch(conn.Context(), m.logger, switchAt, stream.GetPassiveNodeInfo().Hostname); err != nil {
+			return err
+		}
+	}
+
+	if m.config.Script == MockPeerScriptDisconnect {
+		m.step("Dropping the connection")
+		return conn.CloseWithError(1, "mock peer disconnected")
+	}
+
+	stream.SetActiveNodeSetIdentityStartTime()
+	m.step("(dry run) Setting identity to %s", style.RenderPassiveString(strings.ToUpper(constants.NodeRolePassive), false))
+	stream.SetActiveNodeSetIdentityEndTime()
+
+	stream.SetActiveNodeSyncTowerFileStartTime()
+	activeNodeInfo := stream.GetActiveNodeInfo()
+	// an empty tower the active identity signed - the passive node has no start slot to check its votes against
+	activeNodeInfo.TowerFileBytes, err = tower.Encode(tower.Tower{}, m.config.Identities.Active.Key)
+	if err != nil {
+		return err
+	}
+	activeNodeInfo.setTowerFileHash()
+	if err := stream.signTowerFile(m.config.Identities.Active); err != nil {
+		return err
+	}
+	if m.config.Script == MockPeerScriptBadTower {
+		activeNodeInfo.TowerFileBytes = []byte("corrupted mock tower file")
+	}
+	stream.SetActiveNodeSyncTowerFileEndTime()
+	m.step("Sending %d byte tower file", len(activeNodeInfo.TowerFileBytes))
+	if err := stream.sendTowerFile(activeNodeInfo.TowerFileBytes); err != nil {
+		return err
+	}
+
+	if err := stream.Decode(); err != nil {
+		return fmt.Errorf("failed to wait for failover to complete: %w", err)
+	}
+	if !stream.GetIsSuccessfullyCompleted() {
+		return fmt.Errorf("%s failed to complete failover: %s", stream.GetPassiveNodeInfo().Hostname, stream.GetErrorMessage())
+	}
+	m.logger.Info().Str("summary", stream.GetSummaryLine()).Msg("🎭 Failover complete")
+	return nil
+}
@@ -0,0 +1,151 @@
+package failover
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/sol-strategies/solana-validator-failover/internal/constants"
+	"github.com/sol-strategies/solana-validator-failover/internal/identities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// freeUDPPort returns a loopback udp port nothing is listening on
+func freeUDPPort(t *testing.T) int {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+func TestNewMockPeer(t *testing.T) {
+	ids := &identities.Identities{Active: newTestIdentity(t), Passive: newTestIdentity(t)}
+	remoteIds := &identities.Identities{Active: &identities.Identity{Key: ids.Active.Key, Signer: &failingSigner{}}, Passive: ids.Passive}
+
+	tests := []struct {
+		name    string
+		config  MockPeerConfig
+		wantErr string
+	}{
+		{
+			name:   "passive",
+			config: MockPeerConfig{Role: constants.NodeRolePassive, Script: MockPeerScriptRefuse, Identities: ids},
+		},
+		{
+			name:   "active",
+			config: MockPeerConfig{Role: constants.NodeRoleActive, Script: MockPeerScriptBadTower, Identities: ids},
+		},
+		{
+			name:    "unknown script",
+			config:  MockPeerConfig{Role: constants.NodeRolePassive, Script: "flaky", Identities: ids},
+			wantErr: `unknown mock peer script "flaky" - must be one of bad-tower, disconnect, fail, happy, refuse`,
+		},
+		{
+			name:    "script for the other role",
+			config:  MockPeerConfig{Role: constants.NodeRoleActive, Script: MockPeerScriptRefuse, Identities: ids},
+			wantErr: "mock peer script refuse can't be played as active - it can be played as passive",
+		},
+		{
+			name:    "no identities",
+			config:  MockPeerConfig{Role: constants.NodeRolePassive, Script: MockPeerScriptHappy},
+			wantErr: "mock peer needs the validator's active and passive identities",
+		},
+		{
+			name:    "remote signer playing active",
+			config:  MockPeerConfig{Role: constants.NodeRoleActive, Script: MockPeerScriptHappy, Identities: remoteIds},
+			wantErr: "mock peer needs the active identity keypair file to play the active node",
+		},
+		{
+			name:   "remote signer playing passive",
+			config: MockPeerConfig{Role: constants.NodeRolePassive, Script: MockPeerScriptHappy, Identities: remoteIds},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewMockPeer(tt.config)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, DefaultPort, m.config.Port)
+			assert.Equal(t, tt.config.Role, m.nodeInfo.Role)
+			assert.Equal(t, ProtocolRevision, m.nodeInfo.ProtocolRevision)
+		})
+	}
+}
+
+func TestMockPeer_Scripts(t *testing.T) {
+	tests := []struct {
+		name           string
+		activeScript   string
+		passiveScript  string
+		wantActiveErr  string
+		wantPassiveErr string
+	}{
+		{
+			name:          "happy",
+			activeScript:  MockPeerScriptHappy,
+			passiveScript: MockPeerScriptHappy,
+		},
+		{
+			name:          "passive refuses",
+			activeScript:  MockPeerScriptHappy,
+			passiveScript: MockPeerScriptRefuse,
+			wantActiveErr: "mock-passive refused the failover: server cancelled failover: mock peer refused it (script refuse)",
+		},
+		{
+			name:          "passive fails",
+			activeScript:  MockPeerScriptHappy,
+			passiveScript: MockPeerScriptFail,
+			wantActiveErr: "mock-passive failed to complete failover: mock peer failed to set identity to active (script fail)",
+		},
+		{
+			name:          "passive disconnects",
+			activeScript:  MockPeerScriptHappy,
+			passiveScript: MockPeerScriptDisconnect,
+			wantActiveErr: "mock peer disconnected",
+		},
+		{
+			name:           "active disconnects",
+			activeScript:   MockPeerScriptDisconnect,
+			passiveScript:  MockPeerScriptHappy,
+			wantPassiveErr: "failed to receive tower file",
+		},
+		{
+			name:           "active sends a bad tower",
+			activeScript:   MockPeerScriptBadTower,
+			passiveScript:  MockPeerScriptHappy,
+			wantActiveErr:  "failed to wait for failover to complete",
+			wantPassiveErr: "tower file hash mismatch",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// mock peers play both sides against each other
+			ids := &identities.Identities{Active: newTestIdentity(t), Passive: newTestIdentity(t)}
+			port := freeUDPPort(t)
+			passive, err := NewMockPeer(MockPeerConfig{Role: constants.NodeRolePassive, Script: tt.passiveScript, Port: port, Hostname: "mock-passive", Identities: ids})
+			require.NoError(t, err)
+			active, err := NewMockPeer(MockPeerConfig{Role: constants.NodeRoleActive, Script: tt.activeScript, ServerAddress: net.JoinHostPort("127.0.0.1", fmt.Sprint(port)), Hostname: "mock-active", Identities: ids})
+			require.NoError(t, err)
+
+			passiveErrs := make(chan error, 1)
+			go func() { passiveErrs <- passive.Start() }()
+			activeErr := active.Start()
+
+			if tt.wantActiveErr == "" {
+				assert.NoError(t, activeErr)
+			} else {
+				assert.ErrorContains(t, activeErr, tt.wantActiveErr)
+			}
+			if passiveErr := <-passiveErrs; tt.wantPassiveErr == "" {
+				assert.NoError(t, passiveErr)
+			} else {
+				assert.ErrorContains(t, passiveErr, tt.wantPassiveErr)
+			}
+		})
+	}
+}
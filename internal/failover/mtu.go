@@ -0,0 +1,311 @@
+package failover
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// pathMTUProbeMagic starts every path mtu probe - its first byte has the two high bits clear so quic-go hands it
+	// to the failover server as a non-quic packet instead of dropping it
+	pathMTUProbeMagic = "\x00svf-mtu"
+
+	// pathMTUProbeHeaderSize is the magic, an 8 byte nonce and the 2 byte probed size
+	pathMTUProbeHeaderSize = len(pathMTUProbeMagic) + 8 + 2
+
+	// pathMTUProbeMinPayload is the smallest udp payload quic allows - a path that can't carry it can't fail over
+	pathMTUProbeMinPayload = 1200
+
+	// pathMTUProbeMaxPayload is the largest udp payload quic-go sends - a path carrying it is used to the full
+	pathMTUProbeMaxPayload = 1452
+
+	// pathMTUProbePrecision is how close the largest payload found is to the largest the path carries
+	pathMTUProbePrecision = 8
+
+	// pathMTUProbeAttempts is how many times a probe of a size is sent before that size counts as dropped
+	pathMTUProbeAttempts = 2
+
+	// pathMTUProbeAttemptTimeout is how long an echo of a probe is waited for
+	pathMTUProbeAttemptTimeout = 300 * time.Millisecond
+
+	// ipv4UDPHeaderSize and ipv6UDPHeaderSize are the ip and udp header bytes on top of a udp payload
+	ipv4UDPHeaderSize = 20 + 8
+	ipv6UDPHeaderSize = 40 + 8
+
+	// DefaultPathMTUProbeMinMTU is the path mtu below which a failover warns by default
+	DefaultPathMTUProbeMinMTU = 1400
+
+	// DefaultPathMTUProbeTimeout is how long probing the path mtu may take by default
+	DefaultPathMTUProbeTimeout = 3 * time.Second
+)
+
+// PathMTUProbeConfig is the configuration for probing the path mtu to the passive node before a failover
+type PathMTUProbeConfig struct {
+	Enabled bool
+	// MinMTU is the path mtu below which the failover warns that the tower file transfer will be slowed
+	MinMTU  int
+	Timeout time.Duration
+}
+
+// PathMTUProbeResult is the largest packet found to reach the passive node and come back
+type PathMTUProbeResult struct {
+	// MaxPayload is the largest udp payload echoed and PathMTU the ip packet size it makes
+	MaxPayload int
+	PathMTU    int
+	// MinMTU is the path mtu below which the result is low
+	MinMTU int
+	// DroppedAbove is the smallest payload sent without error but never echoed - packets above MaxPayload are
+	// dropped silently e.g. by a tunnel or firewall that doesn't send icmp errors. 0 when none were
+	DroppedAbove int
+	// RefusedAbove is the smallest payload this node's kernel refused to send - the path reports a lower mtu or the
+	// interface mtu is lower. 0 when none were
+	RefusedAbove int
+}
+
+// IsLow returns true if the path mtu is below the configured minimum
+func (r PathMTUProbeResult) IsLow() bool {
+	return r.PathMTU > 0 && r.MinMTU > 0 && r.PathMTU < r.MinMTU
+}
+
+// IsProbed returns true if the path mtu was probed
+func (r PathMTUProbeResult) IsProbed() bool {
+	return r.PathMTU > 0
+}
+
+// warn logs a warning when the path mtu is low or larger packets are dropped silently
+func (r PathMTUProbeResult) warn(logger zerolog.Logger, peerName string) {
+	if !r.IsProbed() {
+		return
+	}
+	event := logger.Info()
+	message := fmt.Sprintf("Path MTU to %s is %d bytes", peerName, r.PathMTU)
+	if r.DroppedAbove > 0 {
+		event = logger.Warn()
+		message = fmt.Sprintf(
+			"Packets to %s with udp payloads above %d bytes are dropped silently - quic will find this slowly and the tower file transfer may stall or slow down",
+			peerName, r.MaxPayload,
+		)
+	} else if r.IsLow() {
+		event = logger.Warn()
+		message = fmt.Sprintf(
+			"Path MTU to %s is %d bytes, below %d - the tower file transfer will be slower than usual",
+			peerName, r.PathMTU, r.MinMTU,
+		)
+	}
+	event.
+		Int("path_mtu", r.PathMTU).
+		Int("max_udp_payload", r.MaxPayload).
+		Int("dropped_above", r.DroppedAbove).
+		Int("refused_above", r.RefusedAbove).
+		Msg(message)
+}
+
+// ProbePathMTU finds the largest udp payload that reaches the failover server at address and is echoed back - probes
+// are sent with the don't fragment bit set, so a payload that doesn't make it was either refused by this node's
+// kernel or dropped on the way. It errors when not even the smallest quic payload is echoed e.g. the server isn't
+// listening or runs a version that doesn't answer probes
+func ProbePathMTU(ctx context.Context, address string, minMTU int) (result PathMTUProbeResult, err error) {
+	remoteAddr, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		return result, fmt.Errorf("failed to resolve %s: %w", address, err)
+	}
+
+	udpConn, err := net.ListenUDP("udp", nil)
+	if err != nil {
+		return result, fmt.Errorf("failed to open probe socket: %w", err)
+	}
+	defer udpConn.Close()
+	// quic-go sets the don't fragment bit on the sockets it wraps
+	transport := &quic.Transport{Conn: udpConn}
+	defer transport.Close()
+
+	prober := &pathMTUProber{transport: transport, remoteAddr: remoteAddr, echoes: make(chan []byte, pathMTUProbeAttempts*4)}
+	if _, err := rand.Read(prober.nonce[:]); err != nil {
+		return result, fmt.Errorf("failed to create probe nonce: %w", err)
+	}
+
+	readCtx, cancelRead := context.WithCancel(ctx)
+	defer cancelRead()
+	go prober.readEchoes(readCtx)
+
+	headerSize := ipv4UDPHeaderSize
+	if remoteAddr.IP.To4() == nil {
+		headerSize = ipv6UDPHeaderSize
+	}
+	result.MinMTU = minMTU
+
+	// the smallest quic payload must pass or there is nothing to learn about the path
+	passed, err := prober.probe(ctx, pathMTUProbeMinPayload)
+	if err != nil {
+		return result, err
+	}
+	if !passed {
+		return result, fmt.Errorf("no path mtu probe of %d bytes was answered by %s", pathMTUProbeMinPayload, address)
+	}
+	largestPassed, smallestFailed := pathMTUProbeMinPayload, 0
+
+	// then the largest, and search between the two when it doesn't pass
+	low, high := pathMTUProbeMinPayload, pathMTUProbeMaxPayload
+	size := high
+	for {
+		passed, err := prober.probe(ctx, size)
+		if err != nil {
+			// out of time - report what was found so far
+			break
+		}
+		if passed {
+			largestPassed, low = size, size
+		} else {
+			smallestFailed, high = size, size
+		}
+		if high-low <= pathMTUProbePrecision || low == pathMTUProbeMaxPayload {
+			break
+		}
+		size = low + (high-low)/2
+	}
+
+	result.MaxPayload = largestPassed
+	result.PathMTU = largestPassed + headerSize
+	if smallestFailed > 0 {
+		if prober.refusedAt(smallestFailed) {
+			result.RefusedAbove = smallestFailed
+		} else {
+			result.DroppedAbove = smallestFailed
+		}
+	}
+	return result, nil
+}
+
+// pathMTUProber sends probes from its own socket and matches the echoes that come back
+type pathMTUProber struct {
+	transport  *quic.Transport
+	remoteAddr *net.UDPAddr
+	nonce      [8]byte
+	echoes     chan []byte
+	refused    []int
+}
+
+// probe sends a probe of size bytes up to pathMTUProbeAttempts times and returns whether it was echoed - it only
+// errors when ctx is done
+func (p *pathMTUProber) probe(ctx context.Context, size int) (bool, error) {
+	packet := p.packet(size)
+	for attempt := 0; attempt < pathMTUProbeAttempts; attempt++ {
+		if _, err := p.transport.WriteTo(packet, p.remoteAddr); err != nil {
+			if errors.Is(err, syscall.EMSGSIZE) {
+				p.refused = append(p.refused, size)
+				return false, nil
+			}
+			return false, fmt.Errorf("failed to send path mtu probe: %w", err)
+		}
+
+		timer := time.NewTimer(pathMTUProbeAttemptTimeout)
+		for waiting := true; waiting; {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return false, ctx.Err()
+			case <-timer.C:
+				waiting = false
+			case echo := <-p.echoes:
+				if bytes.Equal(echo, packet) {
+					timer.Stop()
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+// refusedAt returns true if this node's kernel refused to send a probe of size bytes
+func (p *pathMTUProber) refusedAt(size int) bool {
+	for _, refused := range p.refused {
+		if refused == size {
+			return true
+		}
+	}
+	return false
+}
+
+// packet returns a probe of size bytes carrying the nonce and its size
+func (p *pathMTUProber) packet(size int) []byte {
+	packet := make([]byte, size)
+	copy(packet, pathMTUProbeMagic)
+	copy(packet[len(pathMTUProbeMagic):], p.nonce[:])
+	binary.BigEndian.PutUint16(packet[len(pathMTUProbeMagic)+len(p.nonce):], uint16(size))
+	return packet
+}
+
+// readEchoes passes probes echoed by the server on to probe until ctx is done
+func (p *pathMTUProber) readEchoes(ctx context.Context) {
+	buffer := make([]byte, pathMTUProbeMaxPayload)
+	for {
+		n, _, err := p.transport.ReadNonQUICPacket(ctx, buffer)
+		if err != nil {
+			return
+		}
+		if !isPathMTUProbe(buffer[:n]) {
+			continue
+		}
+		select {
+		case p.echoes <- bytes.Clone(buffer[:n]):
+		default:
+		}
+	}
+}
+
+// isPathMTUProbe returns true if packet is a path mtu probe
+func isPathMTUProbe(packet []byte) bool {
+	return len(packet) >= pathMTUProbeHeaderSize && bytes.HasPrefix(packet, []byte(pathMTUProbeMagic))
+}
+
+// servePathMTUProbes echoes path mtu probes received on the failover server's socket back to their sender until the
+// transport is closed or ctx is done - echoes keep the don't fragment bit so they test the way back too
+func servePathMTUProbes(ctx context.Context, transport *quic.Transport, logger zerolog.Logger) {
+	buffer := make([]byte, pathMTUProbeMaxPayload)
+	for {
+		n, remoteAddr, err := transport.ReadNonQUICPacket(ctx, buffer)
+		if err != nil {
+			return
+		}
+		if !isPathMTUProbe(buffer[:n]) {
+			continue
+		}
+		if _, err := transport.WriteTo(buffer[:n], remoteAddr); err != nil {
+			logger.Debug().Err(err).Int("size", n).Str("remote_addr", remoteAddr.String()).Msg("failed to echo path mtu probe")
+		}
+	}
+}
+
+// probePathMTU probes the path mtu to the server when enabled and warns when it will slow the tower file transfer -
+// a server that doesn't answer probes is only logged at debug level
+func (c *Client) probePathMTU() PathMTUProbeResult {
+	if !c.pathMTUProbe.Enabled {
+		return PathMTUProbeResult{}
+	}
+
+	timeout := c.pathMTUProbe.Timeout
+	if timeout <= 0 {
+		timeout = DefaultPathMTUProbeTimeout
+	}
+	ctx, cancel := context.WithTimeout(c.ctx, timeout)
+	defer cancel()
+
+	result, err := ProbePathMTU(ctx, c.Conn.RemoteAddr().String(), c.pathMTUProbe.MinMTU)
+	if err != nil {
+		c.logger.Debug().Err(err).Msg("failed to probe path mtu")
+		return PathMTUProbeResult{}
+	}
+	result.warn(c.logger, c.serverName)
+	return result
+}
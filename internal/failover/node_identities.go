@@ -0,0 +1,43 @@
+package failover
+
+import (
+	"github.com/gagliardetto/solana-go"
+	"github.com/sol-strategies/solana-validator-failover/internal/identities"
+)
+
+// NodeIdentities are a node's identities as its peer is told them - pubkeys only, the keys and signers behind them
+// never leave the node
+type NodeIdentities struct {
+	Active  *NodeIdentity
+	Passive *NodeIdentity
+}
+
+// NodeIdentity is an identity's pubkey as it is sent to the peer
+type NodeIdentity struct {
+	Pubkey solana.PublicKey
+}
+
+// NewNodeIdentities returns the pubkeys of identities to send to the peer - nil when there are none
+func NewNodeIdentities(ids *identities.Identities) *NodeIdentities {
+	if ids == nil {
+		return nil
+	}
+	nodeIdentities := &NodeIdentities{}
+	if ids.Active != nil {
+		nodeIdentities.Active = &NodeIdentity{Pubkey: ids.Active.PublicKey()}
+	}
+	if ids.Passive != nil {
+		nodeIdentities.Passive = &NodeIdentity{Pubkey: ids.Passive.PublicKey()}
+	}
+	return nodeIdentities
+}
+
+// PubKey returns the identity's pubkey as a string, as identities.Identity does for templates and logs
+func (i *NodeIdentity) PubKey() string {
+	return i.Pubkey.String()
+}
+
+// PublicKey returns the identity's public key
+func (i *NodeIdentity) PublicKey() solana.PublicKey {
+	return i.Pubkey
+}
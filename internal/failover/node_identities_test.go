@@ -0,0 +1,56 @@
+package failover
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/sol-strategies/solana-validator-failover/internal/identities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSigner signs as a remote signer would - the key never reaches the identity
+type testSigner struct {
+	key solana.PrivateKey
+}
+
+func (s *testSigner) PublicKey() solana.PublicKey {
+	return s.key.PublicKey()
+}
+
+func (s *testSigner) Sign(message []byte) (solana.Signature, error) {
+	return s.key.Sign(message)
+}
+
+func TestNodeInfo_GobRoundTrip(t *testing.T) {
+	activeKey, err := solana.NewRandomPrivateKey()
+	require.NoError(t, err)
+	passiveKey, err := solana.NewRandomPrivateKey()
+	require.NoError(t, err)
+
+	ids := &identities.Identities{
+		Active:  &identities.Identity{Signer: &testSigner{key: activeKey}},
+		Passive: &identities.Identity{KeyFile: "/keys/passive.json", Key: passiveKey},
+	}
+
+	var buf bytes.Buffer
+	sent := Message{ActiveNodeInfo: NodeInfo{Hostname: "active", Identities: NewNodeIdentities(ids)}}
+	require.NoError(t, gob.NewEncoder(&buf).Encode(sent))
+
+	// nothing private goes over the wire
+	assert.NotContains(t, buf.String(), "/keys/passive.json")
+	assert.False(t, bytes.Contains(buf.Bytes(), passiveKey))
+
+	var received Message
+	require.NoError(t, gob.NewDecoder(&buf).Decode(&received))
+	require.NotNil(t, received.ActiveNodeInfo.Identities)
+	assert.Equal(t, activeKey.PublicKey(), received.ActiveNodeInfo.Identities.Active.PublicKey())
+	assert.Equal(t, passiveKey.PublicKey().String(), received.ActiveNodeInfo.Identities.Passive.PubKey())
+}
+
+func TestNewNodeIdentities_Nil(t *testing.T) {
+	assert.Nil(t, NewNodeIdentities(nil))
+	assert.Nil(t, NewNodeIdentities(&identities.Identities{}).Active)
+}
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/sol-strategies/solana-validator-failover/internal/identities"
+	"github.com/rs/zerolog"
+	"github.com/sol-strategies/solana-validator-failover/internal/constants"
+	"github.com/sol-strategies/solana-validator-failover/internal/peertrust"
+	"github.com/sol-strategies/solana-validator-failover/internal/solana"
 	"github.com/zeebo/xxh3"
 )
 
@@ -12,13 +15,34 @@ import (
 type NodeInfo struct {
 	PublicIP                       string
 	Hostname                       string
-	Identities                     *identities.Identities
+	Identities                     *NodeIdentities
 	TowerFile                      string
 	TowerFileBytes                 []byte
 	TowerFileHash                  string
 	SetIdentityCommand             string
 	ClientVersion                  string
 	SolanaValidatorFailoverVersion string
+	// Role is the role this node detected for itself from gossip at handshake time
+	Role string
+	// Client is the validator client flavor detected from the validator binary and BinVersion the version it
+	// reports - the binary can be newer than the ClientVersion running in gossip until the validator restarts
+	Client     string
+	BinVersion string
+	// ProtocolRevision is the wire protocol revision this node speaks and MinProtocolRevision the oldest it
+	// interoperates with - zero from nodes that predate negotiating them
+	ProtocolRevision    int
+	MinProtocolRevision int
+	// Capabilities are the optional protocol features this node supports - a peer only uses those both support
+	Capabilities []string
+	// TowerFileEncoding is how TowerFileBytes are encoded on the wire - empty when they are the file as it is
+	TowerFileEncoding string
+	// TowerFileWireSize is how many bytes TowerFileBytes are on the wire - they follow the message in chunks
+	TowerFileWireSize int64
+	// TowerFileSignature is the active identity's signature of the tower file and the failover it is sent in
+	TowerFileSignature []byte
+	// TowerFileUnsignedReason is why the active node sent the tower file unsigned though it signs them - its hash alone
+	// vouches for it
+	TowerFileUnsignedReason string
 }
 
 // SetTowerFileBytes sets the tower file bytes
@@ -42,3 +66,129 @@ func (n NodeInfo) ComputeTowerFileHashFromBytes(towerFileBytes []byte) string {
 	hash := xxh3.Hash(towerFileBytes)
 	return fmt.Sprintf("xxh3:%x", hash)
 }
+
+// DetectRole sets and returns the node's role by comparing its gossip pubkey against its identities
+func (n *NodeInfo) DetectRole(solanaRPCClient solana.ClientInterface) (role string, err error) {
+	role, err = n.gossipRole(solanaRPCClient)
+	if err != nil {
+		return role, err
+	}
+	n.Role = role
+	return n.Role, nil
+}
+
+// gossipRole returns the node's role by comparing its gossip pubkey against its identities without setting it - for
+// checks running alongside whatever owns the node info
+func (n *NodeInfo) gossipRole(solanaRPCClient solana.ClientInterface) (role string, err error) {
+	gossipNode, err := solanaRPCClient.NodeFromIP(n.PublicIP)
+	if err != nil {
+		return constants.NodeRoleUnknown, fmt.Errorf("failed to find %s in gossip: %w", n.Hostname, err)
+	}
+
+	switch gossipNode.PubKey() {
+	case n.Identities.Active.PubKey():
+		return constants.NodeRoleActive, nil
+	case n.Identities.Passive.PubKey():
+		return constants.NodeRolePassive, nil
+	default:
+		return constants.NodeRoleUnknown, nil
+	}
+}
+
+// checkHandshakeRoles checks the roles each side detected for itself at handshake make sense for a
+// failover from activeNodeInfo to passiveNodeInfo, returning a targeted error with remediation hints if not
+func checkHandshakeRoles(activeNodeInfo, passiveNodeInfo *NodeInfo) error {
+	// an older peer may not report its role - nothing to compare
+	if activeNodeInfo.Role == "" || passiveNodeInfo.Role == "" {
+		return nil
+	}
+
+	if activeNodeInfo.Identities.Active.PubKey() != passiveNodeInfo.Identities.Active.PubKey() {
+		return fmt.Errorf(
+			"nodes are configured with different active identities: %s (%s) != %s (%s) - check validator.identities.active on both nodes",
+			activeNodeInfo.Identities.Active.PubKey(),
+			activeNodeInfo.Hostname,
+			passiveNodeInfo.Identities.Active.PubKey(),
+			passiveNodeInfo.Hostname,
+		)
+	}
+
+	if activeNodeInfo.Role == constants.NodeRolePassive && passiveNodeInfo.Role == constants.NodeRolePassive {
+		return fmt.Errorf(
+			"both nodes report passive - check identities on %s: it initiated the failover as active but gossip shows it with its passive identity %s",
+			activeNodeInfo.Hostname,
+			activeNodeInfo.Identities.Passive.PubKey(),
+		)
+	}
+
+	if activeNodeInfo.Role == constants.NodeRoleActive && passiveNodeInfo.Role == constants.NodeRoleActive {
+		return fmt.Errorf(
+			"both nodes report active - check identities on %s: it is waiting to take over but gossip shows it with the active identity %s",
+			passiveNodeInfo.Hostname,
+			passiveNodeInfo.Identities.Active.PubKey(),
+		)
+	}
+
+	if activeNodeInfo.Role != constants.NodeRoleActive {
+		return fmt.Errorf(
+			"%s reports role %s, expected %s - ensure its gossip identity matches validator.identities.active (%s)",
+			activeNodeInfo.Hostname,
+			activeNodeInfo.Role,
+			constants.NodeRoleActive,
+			activeNodeInfo.Identities.Active.PubKey(),
+		)
+	}
+
+	if passiveNodeInfo.Role != constants.NodeRolePassive {
+		return fmt.Errorf(
+			"%s reports role %s, expected %s - ensure its gossip identity matches validator.identities.passive (%s)",
+			passiveNodeInfo.Hostname,
+			passiveNodeInfo.Role,
+			constants.NodeRolePassive,
+			passiveNodeInfo.Identities.Passive.PubKey(),
+		)
+	}
+
+	return nil
+}
+
+// clientString returns the gossip client version with the detected client flavor when it is known
+func (n *NodeInfo) clientString() string {
+	if n.Client == "" || n.Client == constants.ClientTypeUnknown {
+		return n.ClientVersion
+	}
+	return fmt.Sprintf("%s (%s)", n.ClientVersion, n.Client)
+}
+
+// warnClientMismatch warns when the nodes run different validator clients - failing over between clients works
+// but set identity commands, hooks and tower file handling differ between them so it is worth knowing about
+func warnClientMismatch(activeNodeInfo, passiveNodeInfo *NodeInfo, logger zerolog.Logger) {
+	if activeNodeInfo.Client == "" || passiveNodeInfo.Client == "" ||
+		activeNodeInfo.Client == constants.ClientTypeUnknown || passiveNodeInfo.Client == constants.ClientTypeUnknown ||
+		activeNodeInfo.Client == passiveNodeInfo.Client {
+		return
+	}
+	logger.Warn().
+		Str("active_client", activeNodeInfo.Client).
+		Str("passive_client", passiveNodeInfo.Client).
+		Msgf("⚠️  %s runs %s and %s runs %s - failing over between different validator clients",
+			activeNodeInfo.Hostname, activeNodeInfo.Client, passiveNodeInfo.Hostname, passiveNodeInfo.Client)
+}
+
+// recordPeerSession records the identity material the peer known as peerName presented in a successful failover,
+// warning when it changed since the last one - a rebuilt or compromised standby presents new identity material
+func recordPeerSession(store *peertrust.Store, peerName string, peer *NodeInfo, fingerprint string, logger zerolog.Logger) {
+	changes, err := store.RecordSession(peerName, peertrust.PeerSession{
+		Hostname:      peer.Hostname,
+		ActivePubkey:  peer.Identities.Active.PubKey(),
+		PassivePubkey: peer.Identities.Passive.PubKey(),
+		ClientVersion: peer.ClientVersion,
+		Fingerprint:   fingerprint,
+	})
+	if err != nil {
+		logger.Warn().Err(err).Msgf("failed to record session with %s", peerName)
+	}
+	for _, change := range changes {
+		logger.Warn().Str("peer", peerName).Msgf("⚠️  %s %s since the last failover - was it rebuilt or replaced?", peerName, change)
+	}
+}
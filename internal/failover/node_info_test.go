@@ -0,0 +1,127 @@
+package failover
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/sol-strategies/solana-validator-failover/internal/constants"
+	solanapkg "github.com/sol-strategies/solana-validator-failover/internal/solana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeInfo_DetectRole(t *testing.T) {
+	identities := &NodeIdentities{
+		Active:  &NodeIdentity{Pubkey: solana.NewWallet().PublicKey()},
+		Passive: &NodeIdentity{Pubkey: solana.NewWallet().PublicKey()},
+	}
+
+	tests := []struct {
+		name      string
+		gossipKey solana.PublicKey
+		gossipErr error
+		wantRole  string
+		wantErr   string
+	}{
+		{"active identity", identities.Active.Pubkey, nil, constants.NodeRoleActive, ""},
+		{"passive identity", identities.Passive.Pubkey, nil, constants.NodeRolePassive, ""},
+		{"other identity", solana.NewWallet().PublicKey(), nil, constants.NodeRoleUnknown, ""},
+		{"not in gossip", solana.PublicKey{}, solanapkg.ErrNodeNotFound, constants.NodeRoleUnknown, "failed to find node in gossip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := &NodeInfo{Hostname: "node", PublicIP: "10.0.0.1", Identities: identities}
+			client := solanapkg.NewMockClient().WithNodeFromIP(func(ip string) (*solanapkg.Node, error) {
+				assert.Equal(t, "10.0.0.1", ip)
+				if tt.gossipErr != nil {
+					return nil, tt.gossipErr
+				}
+				return solanapkg.NewMockNode(tt.gossipKey, "2.0.0"), nil
+			})
+
+			role, err := n.DetectRole(client)
+			assert.Equal(t, tt.wantRole, role)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				assert.True(t, errors.Is(err, tt.gossipErr))
+				assert.Empty(t, n.Role)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRole, n.Role)
+		})
+	}
+}
+
+func TestCheckHandshakeRoles(t *testing.T) {
+	activePubkey := solana.NewWallet().PublicKey()
+	passivePubkey := solana.NewWallet().PublicKey()
+	nodeInfo := func(hostname, role string, active solana.PublicKey) *NodeInfo {
+		return &NodeInfo{
+			Hostname:   hostname,
+			Role:       role,
+			Identities: &NodeIdentities{Active: &NodeIdentity{Pubkey: active}, Passive: &NodeIdentity{Pubkey: passivePubkey}},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		active      *NodeInfo
+		passive     *NodeInfo
+		wantErrPart string
+	}{
+		{
+			"roles as expected",
+			nodeInfo("active", constants.NodeRoleActive, activePubkey),
+			nodeInfo("passive", constants.NodeRolePassive, activePubkey),
+			"",
+		},
+		{
+			"role not reported by an older peer",
+			nodeInfo("active", "", activePubkey),
+			nodeInfo("passive", constants.NodeRoleActive, activePubkey),
+			"",
+		},
+		{
+			"both active",
+			nodeInfo("active", constants.NodeRoleActive, activePubkey),
+			nodeInfo("passive", constants.NodeRoleActive, activePubkey),
+			"both nodes report active - check identities on passive",
+		},
+		{
+			"both passive",
+			nodeInfo("active", constants.NodeRolePassive, activePubkey),
+			nodeInfo("passive", constants.NodeRolePassive, activePubkey),
+			"both nodes report passive - check identities on active",
+		},
+		{
+			"mismatched active identities",
+			nodeInfo("active", constants.NodeRoleActive, activePubkey),
+			nodeInfo("passive", constants.NodeRolePassive, solana.NewWallet().PublicKey()),
+			"nodes are configured with different active identities",
+		},
+		{
+			"active node unknown",
+			nodeInfo("active", constants.NodeRoleUnknown, activePubkey),
+			nodeInfo("passive", constants.NodeRolePassive, activePubkey),
+			"active reports role unknown, expected active",
+		},
+		{
+			"passive node unknown",
+			nodeInfo("active", constants.NodeRoleActive, activePubkey),
+			nodeInfo("passive", constants.NodeRoleUnknown, activePubkey),
+			"passive reports role unknown, expected passive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkHandshakeRoles(tt.active, tt.passive)
+			if tt.wantErrPart == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErrPart)
+		})
+	}
+}
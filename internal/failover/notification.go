@@ -0,0 +1,37 @@
+package failover
+
+import "github.com/sol-strategies/solana-validator-failover/internal/notify"
+
+// newNotification creates a failover notification sent by hostname from the current state of the stream
+func (s *Stream) newNotification(notificationType, reason, hostname string) notify.Notification {
+	notification := notify.Notification{
+		Type:          notificationType,
+		Hostname:      hostname,
+		IsDryRun:      s.message.IsDryRunFailover,
+		FailoverID:    s.message.FailoverID,
+		Reason:        reason,
+		IdentityGapMs: s.GetIdentityGap().Milliseconds(),
+		ActiveNode: notify.Node{
+			Hostname: s.message.ActiveNodeInfo.Hostname,
+			PublicIP: s.message.ActiveNodeInfo.PublicIP,
+		},
+		PassiveNode: notify.Node{
+			Hostname: s.message.PassiveNodeInfo.Hostname,
+			PublicIP: s.message.PassiveNodeInfo.PublicIP,
+		},
+	}
+	if s.message.ActiveNodeInfo.Identities != nil {
+		notification.ActiveNode.Pubkey = s.message.ActiveNodeInfo.Identities.Active.PubKey()
+	}
+	if s.message.PassiveNodeInfo.Identities != nil {
+		notification.PassiveNode.Pubkey = s.message.PassiveNodeInfo.Identities.Passive.PubKey()
+	}
+	if notificationType == notify.TypeComplete {
+		notification.Summary = s.GetSummaryLine()
+	}
+	if _, first, last, err := s.GetVoteCreditRankDifference(); err == nil {
+		notification.VoteCreditRankBefore = first
+		notification.VoteCreditRankAfter = last
+	}
+	return notification
+}
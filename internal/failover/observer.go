@@ -0,0 +1,248 @@
+package failover
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/rs/zerolog"
+	"github.com/sol-strategies/solana-validator-failover/internal/identities"
+)
+
+const (
+	// ObserverUpdateTypeProgress is an observer update for a step of the failover that isn't a lifecycle event
+	ObserverUpdateTypeProgress = "progress"
+
+	// observerUpdateBufferSize is how many updates may queue for a slow observer before it is sent no more - an
+	// observer must never hold up a failover
+	observerUpdateBufferSize = 64
+
+	// observerFlushTimeout is how long queued updates may take to reach observers when the failover ends
+	observerFlushTimeout = 3 * time.Second
+)
+
+// ObserverUpdate is sent to read-only observers as the failover progresses
+type ObserverUpdate struct {
+	Time time.Time
+	// Hostname is the passive node sending the update
+	Hostname string
+	// Type is progress or an events type - start, complete, abort or identity_gap_alarm
+	Type       string
+	FailoverID string
+	Message    string
+}
+
+// observer is a single authenticated read-only observer stream
+type observer struct {
+	remoteAddr string
+	encoder    *gob.Encoder
+	updates    chan ObserverUpdate
+	done       chan struct{}
+}
+
+// observerHub fans failover updates out to observers - updates sent before an observer joins are replayed to it so
+// it can catch up
+type observerHub struct {
+	mu        sync.Mutex
+	observers map[*observer]struct{}
+	history   []ObserverUpdate
+	closed    bool
+	logger    zerolog.Logger
+}
+
+// newObserverHub creates a new hub with no observers
+func newObserverHub(logger zerolog.Logger) *observerHub {
+	return &observerHub{
+		observers: make(map[*observer]struct{}),
+		logger:    logger,
+	}
+}
+
+// broadcast queues an update for every observer - observers too slow to keep up are dropped rather than block
+func (h *observerHub) broadcast(update ObserverUpdate) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+
+	h.history = append(h.history, update)
+	for o := range h.observers {
+		select {
+		case o.updates <- update:
+		default:
+			h.logger.Warn().Str("remote_addr", o.remoteAddr).Msg("👀 observer too slow to keep up - dropping it")
+			delete(h.observers, o)
+			close(o.updates)
+		}
+	}
+}
+
+// serve sends updates to an observer stream until the hub is closed or the observer goes away
+func (h *observerHub) serve(conn quic.Connection, stream quic.Stream, encoder *gob.Encoder) {
+	remoteAddr := conn.RemoteAddr().String()
+	o := &observer{
+		remoteAddr: remoteAddr,
+		encoder:    encoder,
+		updates:    make(chan ObserverUpdate, observerUpdateBufferSize),
+		done:       make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return
+	}
+	history := append([]ObserverUpdate(nil), h.history...)
+	h.observers[o] = struct{}{}
+	h.mu.Unlock()
+
+	h.logger.Info().Str("remote_addr", remoteAddr).Msg("👀 Observer connected")
+	defer close(o.done)
+
+	for _, update := range history {
+		if err := o.encoder.Encode(update); err != nil {
+			h.remove(o)
+			return
+		}
+	}
+
+	for {
+		select {
+		case update, ok := <-o.updates:
+			if !ok {
+				hangUpObserver(conn, stream)
+				return
+			}
+			if err := o.encoder.Encode(update); err != nil {
+				h.logger.Debug().Err(err).Str("remote_addr", remoteAddr).Msg("observer went away")
+				h.remove(o)
+				return
+			}
+		case <-stream.Context().Done():
+			h.remove(o)
+			return
+		}
+	}
+}
+
+// hangUpObserver closes the stream then waits for the observer to hang up - closing the connection straight away can
+// discard the last updates before the observer reads them
+func hangUpObserver(conn quic.Connection, stream quic.Stream) {
+	stream.Close()
+	select {
+	case <-conn.Context().Done():
+	case <-time.After(observerFlushTimeout):
+	}
+}
+
+// remove stops sending updates to an observer
+func (h *observerHub) remove(o *observer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.observers[o]; ok {
+		delete(h.observers, o)
+		close(o.updates)
+	}
+}
+
+// close stops accepting updates and waits up to the flush timeout for queued ones to reach observers - safe to call
+// more than once
+func (h *observerHub) close() {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return
+	}
+	h.closed = true
+	observers := make([]*observer, 0, len(h.observers))
+	for o := range h.observers {
+		observers = append(observers, o)
+		close(o.updates)
+	}
+	h.observers = make(map[*observer]struct{})
+	h.mu.Unlock()
+
+	timeout := time.After(observerFlushTimeout)
+	for _, o := range observers {
+		select {
+		case <-o.done:
+		case <-timeout:
+			return
+		}
+	}
+}
+
+// ObserveConfig is the configuration for observing a failover on a passive node's failover server
+type ObserveConfig struct {
+	ServerName    string
+	ServerAddress string
+	// ActiveIdentity is the validator's active identity keypair - observers prove they hold it like the active node does
+	ActiveIdentity    *identities.Identity
+	ClientCertificate *tls.Certificate
+}
+
+// Observe connects to a passive node's failover server as a read-only observer and calls onUpdate for each update
+// until the failover ends or the server goes away
+func Observe(config ObserveConfig, onUpdate func(ObserverUpdate)) error {
+	conn, observeStream, err := dialAuthenticated(config.ServerName, config.ServerAddress, config.ActiveIdentity, config.ClientCertificate, QUICConfig{}, MessageTypeObserveRequest)
+	if err != nil {
+		return err
+	}
+	defer conn.CloseWithError(0, "observer done")
+	defer observeStream.Stream.Close()
+
+	for {
+		var update ObserverUpdate
+		if err := observeStream.decoder.Decode(&update); err != nil {
+			var appErr *quic.ApplicationError
+			if errors.Is(err, io.EOF) || errors.As(err, &appErr) {
+				return nil
+			}
+			return fmt.Errorf("failed to read observer update: %w", err)
+		}
+		onUpdate(update)
+	}
+}
+
+// dialAuthenticated connects to a passive node's failover server, opens a stream for messageType and proves this
+// node holds the active identity keypair on it like the active node does
+func dialAuthenticated(serverName, serverAddress string, activeIdentity *identities.Identity, clientCertificate *tls.Certificate, transport QUICConfig, messageType byte) (quic.Connection, *Stream, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: true,
+		NextProtos:         []string{ProtocolName},
+	}
+	if clientCertificate != nil {
+		tlsConfig.Certificates = []tls.Certificate{*clientCertificate}
+	}
+
+	dialCtx, cancel := context.WithTimeout(context.Background(), DefaultPeerProbeTimeout)
+	defer cancel()
+	conn, err := transport.dial(dialCtx, serverAddress, tlsConfig, true)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to %s at %s: %w", serverName, serverAddress, err)
+	}
+
+	quicStream, err := conn.OpenStreamSync(context.Background())
+	if err != nil {
+		conn.CloseWithError(0, "failed to open stream")
+		return nil, nil, fmt.Errorf("failed to open stream: %w", err)
+	}
+	if _, err := quicStream.Write([]byte{messageType}); err != nil {
+		conn.CloseWithError(0, "failed to send message type")
+		return nil, nil, fmt.Errorf("failed to send message type: %w", err)
+	}
+
+	stream := NewFailoverStream(quicStream)
+	if err := stream.AuthenticateAsActive(activeIdentity); err != nil {
+		conn.CloseWithError(0, "authentication failed")
+		return nil, nil, err
+	}
+	return conn, stream, nil
+}
@@ -0,0 +1,190 @@
+package failover
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/rs/zerolog"
+	"github.com/sol-strategies/solana-validator-failover/internal/peertrust"
+	"github.com/sol-strategies/solana-validator-failover/internal/utils"
+	pkgconstants "github.com/sol-strategies/solana-validator-failover/pkg/constants"
+)
+
+// DefaultPingTimeout is how long pinging a peer's failover server or agent may take, handshake and hello included
+const DefaultPingTimeout = 5 * time.Second
+
+// PingRequest is the hello a node sends a peer's failover server or agent to check it can be reached and talked to
+type PingRequest struct {
+	Hostname                       string
+	PublicIP                       string
+	SolanaValidatorFailoverVersion string
+}
+
+// PingResponse is the answer to a hello - the hostname, version and protocol revisions are only shared with
+// configured peers
+type PingResponse struct {
+	Hostname                       string
+	SolanaValidatorFailoverVersion string
+	ErrorMessage                   string
+	ProtocolRevision               int
+	MinProtocolRevision            int
+}
+
+// PingParams are the parameters for pinging a peer's failover server or agent
+type PingParams struct {
+	PeerName string
+	Address  string
+	// Protocol is ProtocolName for a failover server or AgentProtocolName for an agent
+	Protocol string
+	Hostname string
+	PublicIP string
+	// PeerPins checks the presented certificate against its pin without pinning it - nil skips the check
+	PeerPins *peertrust.Store
+	// ClientCertificate is presented when the peer requires client certificates - nil presents none
+	ClientCertificate *tls.Certificate
+}
+
+// PingResult is what a ping found out about the connection to a peer's failover server or agent
+type PingResult struct {
+	// HandshakeRTT is how long the quic handshake took and HelloRTT the hello's round trip after it
+	HandshakeRTT time.Duration `json:"handshake_rtt"`
+	HelloRTT     time.Duration `json:"hello_rtt"`
+	ALPN         string        `json:"alpn"`
+	QUICVersion  string        `json:"quic_version"`
+	TLSVersion   string        `json:"tls_version"`
+	// CertificateSubject, CertificateFingerprint and CertificateNotAfter describe the certificate the peer presented,
+	// CertificatePinned is true when it matches the fingerprint pinned for the peer
+	CertificateSubject     string    `json:"certificate_subject"`
+	CertificateFingerprint string    `json:"certificate_fingerprint"`
+	CertificateNotAfter    time.Time `json:"certificate_not_after"`
+	CertificatePinned      bool      `json:"certificate_pinned"`
+	// Hostname, Version and ProtocolRevision are the peer's, empty when it doesn't recognise this node as a
+	// configured peer
+	Hostname         string `json:"hostname,omitempty"`
+	Version          string `json:"version,omitempty"`
+	ProtocolRevision int    `json:"protocol_revision,omitempty"`
+	// Incompatible says why the peer's protocol revision can't talk to this node's, empty when it can
+	Incompatible string `json:"incompatible,omitempty"`
+}
+
+// Ping completes a quic handshake with a peer's failover server or agent and says hello - a result is returned
+// alongside the error when the handshake succeeded but the hello didn't
+func Ping(params PingParams) (result PingResult, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultPingTimeout)
+	defer cancel()
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: true,
+		NextProtos:         []string{params.Protocol},
+	}
+	if params.ClientCertificate != nil {
+		tlsConfig.Certificates = []tls.Certificate{*params.ClientCertificate}
+	}
+	var fingerprint string
+	if params.PeerPins != nil {
+		tlsConfig.VerifyPeerCertificate = params.PeerPins.VerifyPeerCertificate(params.PeerName, &fingerprint)
+	}
+
+	start := time.Now()
+	conn, err := quic.DialAddr(ctx, params.Address, tlsConfig, nil)
+	if err != nil {
+		return result, fmt.Errorf("failed to connect to %s: %w", params.Address, err)
+	}
+	defer conn.CloseWithError(0, "ping done")
+	result.HandshakeRTT = time.Since(start)
+
+	state := conn.ConnectionState()
+	result.ALPN = state.TLS.NegotiatedProtocol
+	result.QUICVersion = state.Version.String()
+	result.TLSVersion = tls.VersionName(state.TLS.Version)
+	if len(state.TLS.PeerCertificates) > 0 {
+		certificate := state.TLS.PeerCertificates[0]
+		result.CertificateSubject = certificate.Subject.String()
+		result.CertificateFingerprint = peertrust.Fingerprint(certificate.Raw)
+		result.CertificateNotAfter = certificate.NotAfter
+	}
+	if params.PeerPins != nil {
+		pinned, ok := params.PeerPins.Get(params.PeerName)
+		result.CertificatePinned = ok && pinned.Fingerprint == result.CertificateFingerprint
+	}
+
+	stream, err := conn.OpenStreamSync(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to open stream: %w", err)
+	}
+	defer stream.Close()
+	if err := stream.SetDeadline(time.Now().Add(DefaultPingTimeout)); err != nil {
+		return result, err
+	}
+
+	start = time.Now()
+	if _, err := stream.Write([]byte{MessageTypePing}); err != nil {
+		return result, fmt.Errorf("failed to send hello: %w", err)
+	}
+	request := PingRequest{
+		Hostname:                       params.Hostname,
+		PublicIP:                       params.PublicIP,
+		SolanaValidatorFailoverVersion: pkgconstants.AppVersion,
+	}
+	if err := gob.NewEncoder(stream).Encode(request); err != nil {
+		return result, fmt.Errorf("failed to send hello: %w", err)
+	}
+	var response PingResponse
+	if err := gob.NewDecoder(stream).Decode(&response); err != nil {
+		if errors.Is(err, io.EOF) {
+			return result, fmt.Errorf("hello went unanswered - the peer may run a version without ping")
+		}
+		return result, fmt.Errorf("failed to read hello: %w", err)
+	}
+	result.HelloRTT = time.Since(start)
+	result.Hostname = response.Hostname
+	result.Version = response.SolanaValidatorFailoverVersion
+	result.ProtocolRevision = response.ProtocolRevision
+	if result.Version != "" {
+		if err := CheckProtocolRevision(result.Version, response.ProtocolRevision, response.MinProtocolRevision); err != nil {
+			result.Incompatible = err.Error()
+		}
+	}
+
+	if response.ErrorMessage != "" {
+		return result, fmt.Errorf("hello refused: %s", response.ErrorMessage)
+	}
+	return result, nil
+}
+
+// answerPing answers a hello on a stream the message type has been read from - only configured peers are told this
+// node's hostname, version and protocol revisions
+func answerPing(conn quic.Connection, stream quic.Stream, peers []PeerInfo, hostname string, logger zerolog.Logger) {
+	var request PingRequest
+	if err := gob.NewDecoder(stream).Decode(&request); err != nil {
+		logger.Debug().Err(err).Msg("failed to decode hello")
+		return
+	}
+
+	remoteIP := utils.HostFromAddress(conn.RemoteAddr().String())
+	_, ok := matchPeer(peers, remoteIP, request.PublicIP, false)
+	if peerCertName := peertrust.ClientCertificatePeerName(conn.ConnectionState().TLS); peerCertName != "" {
+		_, ok = peerByName(peers, peerCertName)
+	}
+
+	var response PingResponse
+	if ok {
+		response.Hostname = hostname
+		response.SolanaValidatorFailoverVersion = pkgconstants.AppVersion
+		response.ProtocolRevision = ProtocolRevision
+		response.MinProtocolRevision = MinProtocolRevision
+	} else {
+		response.ErrorMessage = fmt.Sprintf("requester %s (%s) is not a configured peer", request.Hostname, remoteIP)
+	}
+	logger.Debug().Str("requester", request.Hostname).Str("remote_addr", conn.RemoteAddr().String()).Bool("configured_peer", ok).Msg("answered hello")
+
+	if err := gob.NewEncoder(stream).Encode(response); err != nil {
+		logger.Debug().Err(err).Msg("failed to send hello response")
+	}
+}
@@ -0,0 +1,105 @@
+package failover
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/events"
+	"github.com/sol-strategies/solana-validator-failover/internal/identities"
+	"github.com/sol-strategies/solana-validator-failover/internal/notify"
+	"github.com/sol-strategies/solana-validator-failover/internal/solana"
+)
+
+// PostMonitorParams are the parameters for monitoring the active identity's vote credits after a failover in a
+// process of its own - the failover server hands monitoring off to it so the operator gets their terminal back
+type PostMonitorParams struct {
+	Hostname        string
+	PublicIP        string
+	Identities      *identities.Identities
+	SolanaRPCClient solana.ClientInterface
+	MonitorConfig   MonitorConfig
+	// BaselineRank is the active identity's vote credit rank before the failover - zero compares with the first
+	// sample instead
+	BaselineRank     int
+	IsDryRunFailover bool
+	Events           events.Config
+	Notifications    notify.Config
+}
+
+// RunPostMonitor pulls the configured vote credit samples for the active identity, logs the rank change and
+// publishes it as a monitor_complete event - notifying of a credit regression when the rank got worse. Block
+// production is then checked when enabled, notifying when the first leader window was fully skipped
+func RunPostMonitor(params PostMonitorParams) error {
+	publisher, err := events.NewPublisher(params.Events)
+	if err != nil {
+		return fmt.Errorf("failed to create event publisher: %w", err)
+	}
+	defer publisher.Wait()
+
+	notifier, err := notify.NewNotifier(params.Notifications)
+	if err != nil {
+		return fmt.Errorf("failed to create notifier: %w", err)
+	}
+	defer notifier.Wait()
+
+	stream := &Stream{
+		message: Message{
+			ActiveNodeInfo: NodeInfo{Identities: NewNodeIdentities(params.Identities)},
+			MonitorConfig:  params.MonitorConfig,
+			CreditSamples:  make(CreditSamples),
+		},
+	}
+
+	pubkey := params.Identities.Active.PubKey()
+	if params.BaselineRank > 0 {
+		stream.message.CreditSamples[pubkey] = []CreditsSample{{VoteRank: params.BaselineRank, Timestamp: time.Now()}}
+	}
+
+	log.Info().
+		Str("pubkey", pubkey).
+		Int("baseline_rank", params.BaselineRank).
+		Msg("🩺 Monitoring vote credits post-failover...")
+	stream.pullActiveIdentityVoteCreditsSamples(params.SolanaRPCClient, params.MonitorConfig.CreditSamples.Count, func(progress string) {
+		log.Info().Msg(progress)
+	})
+
+	rankDifference, firstRank, lastRank, err := stream.GetVoteCreditRankDifference()
+	if err != nil {
+		return fmt.Errorf("failed to get vote credit rank difference: %w", err)
+	}
+	log.Info().Msgf("🏁 Vote credit rank change: %d (%d -> %d)", rankDifference, firstRank, lastRank)
+
+	// this node is active now so it is the event's active node
+	publisher.Publish(events.Event{
+		Type:     events.TypeMonitorComplete,
+		IsDryRun: params.IsDryRunFailover,
+		ActiveNode: events.Node{
+			Hostname: params.Hostname,
+			PublicIP: params.PublicIP,
+			Pubkey:   pubkey,
+		},
+		VoteCreditRankBefore: firstRank,
+		VoteCreditRankAfter:  lastRank,
+	})
+
+	if rankDifference < 0 {
+		notification := stream.newNotification(notify.TypeCreditRegression, "", params.Hostname)
+		notification.IsDryRun = params.IsDryRunFailover
+		notification.ActiveNode = notify.Node{Hostname: params.Hostname, PublicIP: params.PublicIP, Pubkey: pubkey}
+		notifier.Notify(notification)
+	}
+
+	if params.MonitorConfig.BlockProduction.Enabled {
+		if err := stream.PullBlockProduction(params.SolanaRPCClient, log.Logger); err != nil {
+			log.Warn().Err(err).Msg("failed to check post-failover block production")
+		} else if reason := stream.GetBlockProductionFailure(); reason != "" {
+			notification := stream.newNotification(notify.TypeBlockProductionFailure, reason, params.Hostname)
+			notification.IsDryRun = params.IsDryRunFailover
+			notification.ActiveNode = notify.Node{Hostname: params.Hostname, PublicIP: params.PublicIP, Pubkey: pubkey}
+			notifier.Notify(notification)
+		}
+	}
+
+	return nil
+}
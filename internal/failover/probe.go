@@ -0,0 +1,41 @@
+package failover
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+
+	"github.com/quic-go/quic-go"
+)
+
+// ProbePeer completes a quic handshake with the failover server at address and hangs up without opening a stream -
+// it only succeeds while a failover server is listening there. clientCertificate is presented when not nil
+func ProbePeer(address string, clientCertificate *tls.Certificate) error {
+	return probe(address, ProtocolName, clientCertificate)
+}
+
+// ProbeAgent completes a quic handshake with the agent at address and hangs up without opening a stream - it only
+// succeeds while an agent is running there. clientCertificate is presented when not nil
+func ProbeAgent(address string, clientCertificate *tls.Certificate) error {
+	return probe(address, AgentProtocolName, clientCertificate)
+}
+
+// probe completes a quic handshake speaking protocol with address and hangs up
+func probe(address, protocol string, clientCertificate *tls.Certificate) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultPeerProbeTimeout)
+	defer cancel()
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: true,
+		NextProtos:         []string{protocol},
+	}
+	if clientCertificate != nil {
+		tlsConfig.Certificates = []tls.Certificate{*clientCertificate}
+	}
+
+	conn, err := quic.DialAddr(ctx, address, tlsConfig, nil)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+	return conn.CloseWithError(0, "probe done")
+}
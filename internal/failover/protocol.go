@@ -0,0 +1,330 @@
+package failover
+
+import (
+	"encoding"
+	"encoding/gob"
+	"fmt"
+	"reflect"
+
+	pkgconstants "github.com/sol-strategies/solana-validator-failover/pkg/constants"
+)
+
+// ProtocolDescription is a machine-readable description of the wire protocol for third-party implementations
+type ProtocolDescription struct {
+	Name string `json:"name"`
+	// Version is the program version - peers may run different ones as long as their protocol revisions interoperate
+	Version string `json:"version"`
+	// Revision is the wire protocol revision this version speaks and MinRevision the oldest it interoperates with -
+	// peers sending a revision of 0 predate negotiation and only interoperate with the same version
+	Revision     int                           `json:"revision"`
+	MinRevision  int                           `json:"min_revision"`
+	Transport    string                        `json:"transport"`
+	Encoding     string                        `json:"encoding"`
+	Endpoints    []EndpointDescription         `json:"endpoints"`
+	MessageTypes []MessageTypeDescription      `json:"message_types"`
+	Messages     map[string][]FieldDescription `json:"messages"`
+	// Types are the struct types messages are built from, by name
+	Types  map[string][]FieldDescription `json:"types"`
+	Phases []PhaseDescription            `json:"phases"`
+}
+
+// EndpointDescription is a quic listener a node runs
+type EndpointDescription struct {
+	Name        string `json:"name"`
+	ALPN        string `json:"alpn"`
+	DefaultPort int    `json:"default_port"`
+	RunBy       string `json:"run_by"`
+}
+
+// MessageTypeDescription is the single byte written at the start of a stream to say what it carries
+type MessageTypeDescription struct {
+	Name        string `json:"name"`
+	Value       byte   `json:"value"`
+	ALPN        string `json:"alpn"`
+	Description string `json:"description"`
+}
+
+// FieldDescription is a field of a gob-encoded struct in declaration order
+type FieldDescription struct {
+	Name string `json:"name"`
+	// Type is the field's wire type - struct types are described under types
+	Type string `json:"type"`
+	// GoType is the go type when it differs from the wire type e.g. time.Duration is an int64
+	GoType string `json:"go_type,omitempty"`
+}
+
+// PhaseDescription is a step of a protocol exchange
+type PhaseDescription struct {
+	Step        int    `json:"step"`
+	Exchange    string `json:"exchange"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Message     string `json:"message,omitempty"`
+	Description string `json:"description"`
+}
+
+var (
+	gobEncoderType          = reflect.TypeOf((*gob.GobEncoder)(nil)).Elem()
+	binaryMarshalerType     = reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()
+	exchangeFailover        = "failover"
+	exchangeAgentHandover   = "agent-handover"
+	exchangeObserve         = "observe"
+	exchangeFileTransfer    = "file-transfer"
+	exchangeConfigView      = "config-view"
+	exchangeAbort           = "abort"
+	exchangeRelease         = "release"
+	exchangePing            = "ping"
+	exchangeStandbyOutcome  = "standby-outcome"
+	exchangeTowerFileResume = "tower-file-resume"
+	exchangeTowerReplica    = "tower-replica"
+	roleStandby             = "standby"
+	roleObserver            = "observer"
+	rolePeer, roleAgent     = "peer", "agent"
+	roleActive, rolePassive = "active", "passive"
+)
+
+// DescribeProtocol returns a description of the current wire protocol - message schemas are read from the types
+// themselves so they can't drift from what is sent
+func DescribeProtocol() ProtocolDescription {
+	d := ProtocolDescription{
+		Name:        ProtocolName,
+		Version:     pkgconstants.AppVersion,
+		Revision:    ProtocolRevision,
+		MinRevision: MinProtocolRevision,
+		Transport:   "quic (tls 1.3, self-signed certificates - peers pin fingerprints rather than verify a chain)",
+		Encoding:    "go encoding/gob - one gob stream per quic stream, values encoded back to back after the message type byte",
+		Endpoints: []EndpointDescription{
+			{Name: "failover server", ALPN: ProtocolName, DefaultPort: DefaultPort, RunBy: "passive node"},
+			{Name: "agent", ALPN: AgentProtocolName, DefaultPort: DefaultAgentPort, RunBy: "active node, and any node accepting file pushes or tower replicas"},
+		},
+		MessageTypes: []MessageTypeDescription{
+			{
+				Name:        "FailoverInitiateRequest",
+				Value:       MessageTypeFailoverInitiateRequest,
+				ALPN:        ProtocolName,
+				Description: "starts a failover - AuthChallenge and AuthResponse values prove both nodes hold the active identity keypair, then Message values are exchanged in both directions on the stream",
+			},
+			{
+				Name:        "FileTransfer",
+				Value:       MessageTypeFileTransfer,
+				ALPN:        AgentProtocolName,
+				Description: "pushes a file to an agent - one FileTransferRequest is sent and a FileTransferResponse returned, then the file's bytes are sent and a second FileTransferResponse returned. The tower file doesn't use it, it travels inside Message",
+			},
+			{
+				Name:        "AgentHandoverRequest",
+				Value:       MessageTypeAgentHandoverRequest,
+				ALPN:        AgentProtocolName,
+				Description: "asks an agent to hand over - one AgentRequest is sent and one AgentResponse returned",
+			},
+			{
+				Name:        "ObserveRequest",
+				Value:       MessageTypeObserveRequest,
+				ALPN:        ProtocolName,
+				Description: "follows a failover read-only - AuthChallenge and AuthResponse values are exchanged as for a failover, then the passive node sends ObserverUpdate values until the failover ends",
+			},
+			{
+				Name:        "ConfigViewRequest",
+				Value:       MessageTypeConfigViewRequest,
+				ALPN:        AgentProtocolName,
+				Description: "asks an agent for its node's config view to diff against - one ConfigViewRequest is sent and one ConfigViewResponse returned",
+			},
+			{
+				Name:        "AbortRequest",
+				Value:       MessageTypeAbortRequest,
+				ALPN:        ProtocolName,
+				Description: "asks the passive node to abort its pending failover - AuthChallenge and AuthResponse values are exchanged as for a failover, then one AbortRequest is sent and one AbortResponse returned",
+			},
+			{
+				Name:        "ReleaseRequest",
+				Value:       MessageTypeReleaseRequest,
+				ALPN:        ProtocolName,
+				Description: "asks the passive node to release the failover the active node holds - AuthChallenge and AuthResponse values are exchanged as for a failover, then one ReleaseRequest is sent and one ReleaseResponse returned",
+			},
+			{
+				Name:        "StandbyOutcome",
+				Value:       MessageTypeStandbyOutcome,
+				ALPN:        ProtocolName,
+				Description: "tells a passive node waiting for a failover that the active node failed over to another passive node - AuthChallenge and AuthResponse values are exchanged as for a failover, then one StandbyOutcome is sent and one StandbyOutcomeResponse returned",
+			},
+			{
+				Name:        "TowerFileResume",
+				Value:       MessageTypeTowerFileResume,
+				ALPN:        ProtocolName,
+				Description: "resumes sending the tower file after the failover connection was lost while it was sent - AuthChallenge and AuthResponse values are exchanged as for a failover, then one TowerFileResumeRequest is sent and one TowerFileResumeResponse returned, and the failover carries on over the stream",
+			},
+			{
+				Name:        "TowerReplica",
+				Value:       MessageTypeTowerReplica,
+				ALPN:        AgentProtocolName,
+				Description: "ships a replica of the active node's tower file to an agent between failovers - one TowerReplicaRequest is sent and a FileTransferResponse returned, then the tower file's bytes are sent and a second FileTransferResponse returned",
+			},
+			{
+				Name:        "Ping",
+				Value:       MessageTypePing,
+				ALPN:        ProtocolName,
+				Description: "checks connectivity with a failover server - one PingRequest is sent and one PingResponse returned",
+			},
+			{
+				Name:        "Ping",
+				Value:       MessageTypePing,
+				ALPN:        AgentProtocolName,
+				Description: "checks connectivity with an agent - one PingRequest is sent and one PingResponse returned",
+			},
+		},
+		Messages: map[string][]FieldDescription{},
+		Types:    map[string][]FieldDescription{},
+		Phases:   protocolPhases(),
+	}
+
+	for _, message := range []any{AuthChallenge{}, AuthResponse{}, Message{}, AgentRequest{}, AgentResponse{}, FileTransferRequest{}, FileTransferResponse{}, ObserverUpdate{}, ConfigViewRequest{}, ConfigViewResponse{}, AbortRequest{}, AbortResponse{}, ReleaseRequest{}, ReleaseResponse{}, PingRequest{}, PingResponse{}, StandbyOutcome{}, StandbyOutcomeResponse{}, TowerFileChunk{}, TowerFileChunkAck{}, TowerFileResumeRequest{}, TowerFileResumeResponse{}, TowerReplicaRequest{}, WarmHoldHeartbeat{}, WarmHoldRelease{}} {
+		t := reflect.TypeOf(message)
+		d.Messages[t.Name()] = describeFields(t, d.Types)
+	}
+
+	return d
+}
+
+// protocolPhases returns the steps of the failover, agent handover, observe, file transfer, config view, abort, release,
+// ping, standby outcome and tower file resume exchanges
+func protocolPhases() []PhaseDescription {
+	return []PhaseDescription{
+		{1, exchangeFailover, roleActive, rolePassive, "",
+			fmt.Sprintf("dial the failover server with alpn %s, probe the path mtu when enabled, open a bidirectional stream and write message type %d - path mtu probes are udp datagrams to the failover port starting %q, an 8 byte nonce and the 2 byte big-endian datagram size, padded to that size and sent with the don't fragment bit set. The passive node echoes each one back unchanged", ProtocolName, MessageTypeFailoverInitiateRequest, pathMTUProbeMagic)},
+		{2, exchangeFailover, roleActive, rolePassive, "AuthChallenge",
+			fmt.Sprintf("a random %d byte nonce", authNonceSize)},
+		{3, exchangeFailover, rolePassive, roleActive, "AuthChallenge",
+			fmt.Sprintf("a random %d byte nonce", authNonceSize)},
+		{4, exchangeFailover, roleActive, rolePassive, "AuthResponse",
+			fmt.Sprintf("Signature set - the active identity's ed25519 signature of %q, a colon, then the passive nonce followed by the active nonce", authContextActive)},
+		{5, exchangeFailover, rolePassive, roleActive, "AuthResponse",
+			fmt.Sprintf("passive verifies the signature against its own active identity pubkey - replies with Signature set to the active identity's signature of %q, a colon, then the same nonces, or ErrorMessage set and closes the stream", authContextPassive)},
+		{6, exchangeFailover, roleActive, rolePassive, "Message",
+			"active verifies the signature against its own active identity pubkey then handshakes - ActiveNodeInfo (role detected from gossip, ProtocolRevision, MinProtocolRevision and Capabilities) set, TraceParent when it traces, PathMTUProbe when it probed, ScheduledSwitchAt when it scheduled the switch and WarmHold when it holds the failover once confirmed"},
+		{7, exchangeFailover, rolePassive, roleActive, "Message",
+			"passive checks the active node is an allowed peer when its allowlist is enabled, replying with PeerRejection and ErrorMessage set if not, then negotiates the protocol - the active node's ProtocolRevision must be at least this node's MinProtocolRevision and its MinProtocolRevision at most this node's ProtocolRevision, and only capabilities both support are used - then checks roles, its slot lag, gossip and the active identity's vote account (setting ActiveVoteAccount, and ActiveIdentityDelinquent when it is already delinquent) and that any ScheduledSwitchAt both nodes set agrees, hasn't passed and isn't set alongside WarmHold, runs check hooks, confirms and runs pre hooks - replies with PassiveNodeInfo (with its ProtocolRevision, MinProtocolRevision and Capabilities), MonitorConfig, IsDryRunFailover, SwitchCountdown and ScheduledSwitchAt set and CanProceed true, or ErrorMessage set to abort"},
+		{8, exchangeFailover, roleActive, rolePassive, "WarmHoldHeartbeat",
+			fmt.Sprintf("only when it set WarmHold, and stops instead when the passive node's Capabilities lack %s - sends a WarmHoldHeartbeat every %s until its operator triggers the switch, then one with Released true. The passive node aborts the failover when none arrives for %s. When both nodes' Capabilities include %s the passive node sends one WarmHoldRelease back during the hold - Trigger set to release it on a release requester's behalf, which the active node answers with the Released heartbeat, or empty once the Released heartbeat arrives to acknowledge it - and the active node reads it before carrying on", CapabilityWarmHold, warmHoldHeartbeatInterval, warmHoldHeartbeatTimeout, CapabilityWarmHoldRelease)},
+		{9, exchangeFailover, roleActive, rolePassive, "Message",
+			fmt.Sprintf("waits for ScheduledSwitchAt when set, as the passive node does, then for the minimum time to its next leader slot and runs pre hooks - then, only when SwitchCountdown is non-zero, SwitchAt set to that long from now and both nodes count down to it (the passive node caps its countdown at SwitchCountdown in case clocks differ). Until this message is sent either node may abort by closing the connection with application error code %d and the reason", AbortErrorCode)},
+		{10, exchangeFailover, roleActive, roleActive, "",
+			"waits for the start of the next slot and sets its identity to passive"},
+		{11, exchangeFailover, roleActive, rolePassive, "Message",
+			fmt.Sprintf("ActiveNodeInfo.TowerFileHash (of the file as it is on disk), TowerFileSignature (the active identity's signature of the failover id, both hostnames, FailoverStartSlot and the file's hash and sha256 - checked when the active node's Capabilities include %s unless TowerFileUnsignedReason says why it couldn't sign) and TowerFileWireSize, FailoverStartSlot and active node timings set, then the tower file as TowerFileChunk values of up to %d bytes, each acknowledged with a TowerFileChunkAck before the next is sent - the tower file is gzip-compressed with TowerFileEncoding %s when the passive node's Capabilities include %s and that makes it smaller. When the connection is lost before every chunk is acknowledged the active node resumes the transfer in the tower file resume exchange", CapabilityTowerSignature, towerFileChunkSize, TowerFileEncodingGzip, CapabilityTowerFileGzip)},
+		{12, exchangeFailover, rolePassive, rolePassive, "",
+			"verifies the tower file hash and, unless its sanity check is disabled, that it is a tower the active identity signed with its last vote plausible for FailoverStartSlot, then writes the tower file and sets its identity to active"},
+		{13, exchangeFailover, rolePassive, roleActive, "Message",
+			"IsSuccessfullyCompleted true and FailoverEndSlot set - the active node pins the passive node's certificate and runs post hooks"},
+		{1, exchangeAgentHandover, rolePassive, roleActive, "AgentRequest",
+			fmt.Sprintf("dial the agent with alpn %s, open a bidirectional stream and write message type %d then the request. With ProveIdentity set, AuthChallenge and AuthResponse values follow as in failover steps 2 to 5 with the requester as the active node - only then may the agent match it to a peer by its claimed PublicIP rather than the address it connects from", AgentProtocolName, MessageTypeAgentHandoverRequest)},
+		{2, exchangeAgentHandover, roleActive, rolePassive, "AgentResponse",
+			"Accepted, or ErrorMessage set when the requester isn't a configured peer, protocol revisions don't interoperate or a handover is already running"},
+		{3, exchangeAgentHandover, rolePassive, rolePassive, "",
+			"once accepted the passive node starts its failover server and the agent's node runs the failover exchange against it as the active node"},
+		{1, exchangeObserve, roleObserver, rolePassive, "",
+			fmt.Sprintf("dial the failover server with alpn %s, open a bidirectional stream and write message type %d", ProtocolName, MessageTypeObserveRequest)},
+		{2, exchangeObserve, roleObserver, rolePassive, "AuthChallenge",
+			"authenticates exactly as the active node does in failover steps 2 to 5 - observers must hold the active identity keypair"},
+		{3, exchangeObserve, rolePassive, roleObserver, "ObserverUpdate",
+			fmt.Sprintf("updates sent so far are replayed then each new one is sent as it happens - Type is %s or an event type, the stream closes when the failover completes or aborts", ObserverUpdateTypeProgress)},
+		{1, exchangeFileTransfer, rolePeer, roleAgent, "FileTransferRequest",
+			fmt.Sprintf("dial the agent with alpn %s, open a bidirectional stream and write message type %d then the request - Path absolute on the agent's node, Size, SHA256 (hex) and Mode of the file. With ProveIdentity set, AuthChallenge and AuthResponse values follow as in failover steps 2 to 5 with the requester as the active node - a requester without a verified client certificate must", AgentProtocolName, MessageTypeFileTransfer)},
+		{2, exchangeFileTransfer, roleAgent, rolePeer, "FileTransferResponse",
+			"Accepted, or ErrorMessage set when the requester neither presented a client certificate nor proved its identity, isn't a configured peer, protocol revisions don't interoperate, file transfers are disabled, the file is too large or Path isn't directly inside an allowed dir"},
+		{3, exchangeFileTransfer, rolePeer, roleAgent, "",
+			"once accepted exactly Size raw bytes of the file - the agent never sets its exec bits"},
+		{4, exchangeFileTransfer, roleAgent, rolePeer, "FileTransferResponse",
+			"Accepted once the sha256 matched and the file was moved in place, or ErrorMessage set - a failed transfer leaves any existing file untouched"},
+		{1, exchangeConfigView, rolePeer, roleAgent, "ConfigViewRequest",
+			fmt.Sprintf("dial the agent with alpn %s, open a bidirectional stream and write message type %d then the request - versions may differ", AgentProtocolName, MessageTypeConfigViewRequest)},
+		{2, exchangeConfigView, roleAgent, rolePeer, "ConfigViewResponse",
+			fmt.Sprintf("Hostname and View set - dotted config keys to values, templates and hook commands only as the first %d hex characters of their sha256 with Hashed true - or ErrorMessage set when the requester isn't a configured peer or config sharing is disabled", configViewHashLength)},
+		{1, exchangeAbort, rolePeer, rolePassive, "",
+			fmt.Sprintf("dial the failover server with alpn %s, open a bidirectional stream and write message type %d", ProtocolName, MessageTypeAbortRequest)},
+		{2, exchangeAbort, rolePeer, rolePassive, "AbortRequest",
+			"authenticates exactly as the active node does in failover steps 2 to 5, then Hostname and Reason set"},
+		{3, exchangeAbort, rolePassive, rolePeer, "AbortResponse",
+			fmt.Sprintf("empty Error once the pending failover is aborted as in the failover exchange's abort - closing the active node's connection with application error code %d - or Error set when the active node has begun switching identity", AbortErrorCode)},
+		{1, exchangeRelease, rolePeer, rolePassive, "",
+			fmt.Sprintf("dial the failover server with alpn %s, open a bidirectional stream and write message type %d", ProtocolName, MessageTypeReleaseRequest)},
+		{2, exchangeRelease, rolePeer, rolePassive, "ReleaseRequest",
+			"authenticates exactly as the active node does in failover steps 2 to 5, then Hostname set"},
+		{3, exchangeRelease, rolePassive, rolePeer, "ReleaseResponse",
+			"empty Error once the release is passed on to the active node as a WarmHoldRelease in failover step 8, or Error set when no failover is held that can be released on request"},
+		{1, exchangeStandbyOutcome, roleActive, roleStandby, "",
+			fmt.Sprintf("once a failover completes the old active node dials every other configured peer's failover server with alpn %s, opens a bidirectional stream and writes message type %d - standbys that aren't waiting for a failover don't answer", ProtocolName, MessageTypeStandbyOutcome)},
+		{2, exchangeStandbyOutcome, roleActive, roleStandby, "StandbyOutcome",
+			"authenticates exactly as in failover steps 2 to 5, then Hostname, NewActiveHostname, FailoverID and IsDryRunFailover set"},
+		{3, exchangeStandbyOutcome, roleStandby, roleActive, "StandbyOutcomeResponse",
+			"empty Error once the standby stops waiting and stays passive, or Error set when it is failing over with the active node itself"},
+		{1, exchangeTowerFileResume, roleActive, rolePassive, "",
+			fmt.Sprintf("the active node redials the failover server with alpn %s for up to %s, opens a bidirectional stream and writes message type %d", ProtocolName, DefaultTowerFileResumeTimeout, MessageTypeTowerFileResume)},
+		{2, exchangeTowerFileResume, roleActive, rolePassive, "TowerFileResumeRequest",
+			"authenticates exactly as in failover steps 2 to 5, then Hostname set and Message set to the failover step 11 message"},
+		{3, exchangeTowerFileResume, rolePassive, roleActive, "TowerFileResumeResponse",
+			"Received set to the tower file bytes it already has - kept only when TowerFileHash matches what it received before - or Error set when FailoverID isn't its failover's"},
+		{4, exchangeTowerFileResume, roleActive, rolePassive, "TowerFileChunk",
+			"the rest of the tower file from Received, acknowledged as in failover step 11 - the failover then carries on from step 12 over this stream"},
+		{1, exchangeTowerReplica, roleActive, roleAgent, "TowerReplicaRequest",
+			fmt.Sprintf("every tower.replication.interval the active node's agent dials each peer's agent with alpn %s, opens a bidirectional stream and writes message type %d then the request - Size and SHA256 (hex) of its tower file", AgentProtocolName, MessageTypeTowerReplica)},
+		{2, exchangeTowerReplica, roleAgent, roleActive, "FileTransferResponse",
+			"Accepted, or ErrorMessage set when the requester isn't a configured peer, protocol revisions don't interoperate, tower replicas are disabled or the tower file is too large"},
+		{3, exchangeTowerReplica, roleActive, roleAgent, "",
+			"once accepted exactly Size raw bytes of the tower file"},
+		{4, exchangeTowerReplica, roleAgent, roleActive, "FileTransferResponse",
+			"Accepted once the sha256 matched, the tower file is signed by and for the active identity and its last voted slot isn't older than the kept replica's, or ErrorMessage set - a rejected replica leaves the kept one untouched"},
+		{1, exchangePing, rolePeer, rolePeer, "PingRequest",
+			fmt.Sprintf("dial the failover server with alpn %s or the agent with alpn %s, open a bidirectional stream and write message type %d then the request", ProtocolName, AgentProtocolName, MessageTypePing)},
+		{2, exchangePing, rolePeer, rolePeer, "PingResponse",
+			"Hostname, SolanaValidatorFailoverVersion, ProtocolRevision and MinProtocolRevision set, or ErrorMessage set when the requester isn't a configured peer - versions may differ"},
+	}
+}
+
+// describeFields describes the exported fields of a struct type gob encodes - nested struct types are added to types
+func describeFields(t reflect.Type, types map[string][]FieldDescription) []FieldDescription {
+	fields := make([]FieldDescription, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		// gob only sends exported fields
+		if !field.IsExported() {
+			continue
+		}
+		description := FieldDescription{Name: field.Name, Type: wireTypeName(field.Type, types)}
+		if goType := field.Type.String(); goType != description.Type {
+			description.GoType = goType
+		}
+		fields = append(fields, description)
+	}
+	return fields
+}
+
+// wireTypeName returns the name of the type gob sends for t - pointers are flattened and types with their own
+// encoding are opaque bytes
+func wireTypeName(t reflect.Type, types map[string][]FieldDescription) string {
+	if t.Implements(gobEncoderType) || t.Implements(binaryMarshalerType) ||
+		reflect.PointerTo(t).Implements(gobEncoderType) || reflect.PointerTo(t).Implements(binaryMarshalerType) {
+		return fmt.Sprintf("opaque(%s)", t.String())
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return wireTypeName(t.Elem(), types)
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "bytes"
+		}
+		return "[]" + wireTypeName(t.Elem(), types)
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), wireTypeName(t.Elem(), types))
+	case reflect.Map:
+		return fmt.Sprintf("map[%s]%s", wireTypeName(t.Key(), types), wireTypeName(t.Elem(), types))
+	case reflect.Struct:
+		if _, ok := types[t.Name()]; !ok {
+			// reserve the name before recursing so self-referencing types terminate
+			types[t.Name()] = nil
+			types[t.Name()] = describeFields(t, types)
+		}
+		return t.Name()
+	default:
+		return t.Kind().String()
+	}
+}
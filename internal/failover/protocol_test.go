@@ -0,0 +1,110 @@
+package failover
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribeProtocol_MessageTypes(t *testing.T) {
+	d := DescribeProtocol()
+
+	// every message type is described once for each alpn it is served on
+	described := map[string]byte{}
+	for _, messageType := range d.MessageTypes {
+		key := messageType.ALPN + "/" + messageType.Name
+		assert.NotContains(t, described, key, "%s described twice", key)
+		described[key] = messageType.Value
+		assert.Contains(t, []string{ProtocolName, AgentProtocolName}, messageType.ALPN)
+		assert.NotEmpty(t, messageType.Description)
+	}
+	assert.Equal(t, map[string]byte{
+		ProtocolName + "/FailoverInitiateRequest":   MessageTypeFailoverInitiateRequest,
+		AgentProtocolName + "/FileTransfer":         MessageTypeFileTransfer,
+		AgentProtocolName + "/AgentHandoverRequest": MessageTypeAgentHandoverRequest,
+		ProtocolName + "/ObserveRequest":            MessageTypeObserveRequest,
+		AgentProtocolName + "/ConfigViewRequest":    MessageTypeConfigViewRequest,
+		ProtocolName + "/AbortRequest":              MessageTypeAbortRequest,
+		ProtocolName + "/ReleaseRequest":            MessageTypeReleaseRequest,
+		ProtocolName + "/StandbyOutcome":            MessageTypeStandbyOutcome,
+		ProtocolName + "/TowerFileResume":           MessageTypeTowerFileResume,
+		AgentProtocolName + "/TowerReplica":         MessageTypeTowerReplica,
+		ProtocolName + "/Ping":                      MessageTypePing,
+		AgentProtocolName + "/Ping":                 MessageTypePing,
+	}, described)
+}
+
+func TestDescribeProtocol_Phases(t *testing.T) {
+	d := DescribeProtocol()
+
+	nextStep := map[string]int{}
+	for _, phase := range d.Phases {
+		// each exchange's steps count up from 1
+		nextStep[phase.Exchange]++
+		assert.Equal(t, nextStep[phase.Exchange], phase.Step, "%s step out of order", phase.Exchange)
+		assert.NotEmpty(t, phase.From)
+		assert.NotEmpty(t, phase.To)
+		assert.NotEmpty(t, phase.Description)
+
+		// and only send messages that are described
+		if phase.Message != "" {
+			assert.Contains(t, d.Messages, phase.Message, "%s step %d", phase.Exchange, phase.Step)
+		}
+	}
+	assert.Len(t, nextStep, 11)
+}
+
+func TestDescribeProtocol_Messages(t *testing.T) {
+	d := DescribeProtocol()
+
+	// nested struct types are described once under types and referred to by name
+	require.Contains(t, d.Messages, "Message")
+	assert.Contains(t, d.Messages["Message"], FieldDescription{Name: "ActiveNodeInfo", Type: "NodeInfo", GoType: "failover.NodeInfo"})
+	assert.Contains(t, d.Types, "NodeInfo")
+	for name, fields := range d.Types {
+		assert.NotNil(t, fields, "%s has no fields described", name)
+	}
+
+	// the description is what protocol describe prints
+	b, err := json.Marshal(d)
+	require.NoError(t, err)
+	var decoded ProtocolDescription
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, d, decoded)
+}
+
+type describedNode struct {
+	Name     string
+	Timeout  time.Duration
+	At       time.Time
+	Payload  []byte
+	Children []describedNode
+	Parent   *describedNode
+	Labels   map[string]int
+	Hash     [4]byte
+	internal string
+}
+
+func TestDescribeFields(t *testing.T) {
+	types := map[string][]FieldDescription{}
+	fields := describeFields(reflect.TypeOf(describedNode{}), types)
+
+	want := []FieldDescription{
+		{Name: "Name", Type: "string"},
+		{Name: "Timeout", Type: "int64", GoType: "time.Duration"},
+		{Name: "At", Type: "opaque(time.Time)", GoType: "time.Time"},
+		{Name: "Payload", Type: "bytes", GoType: "[]uint8"},
+		{Name: "Children", Type: "[]describedNode", GoType: "[]failover.describedNode"},
+		{Name: "Parent", Type: "describedNode", GoType: "*failover.describedNode"},
+		{Name: "Labels", Type: "map[string]int"},
+		{Name: "Hash", Type: "[4]uint8"},
+	}
+	assert.Equal(t, want, fields)
+
+	// a type referring to itself is described once rather than recursing forever
+	assert.Equal(t, map[string][]FieldDescription{"describedNode": want}, types)
+}
@@ -0,0 +1,107 @@
+package failover
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/rs/zerolog"
+	pkgconstants "github.com/sol-strategies/solana-validator-failover/pkg/constants"
+)
+
+const (
+	// ProtocolRevision is the revision of the wire protocol this build speaks - bumped whenever a message or exchange
+	// changes. Optional features are capabilities instead so peers without them can leave them out
+	ProtocolRevision = 1
+
+	// MinProtocolRevision is the oldest revision this build interoperates with - raised only when a change breaks
+	// peers speaking an older one
+	MinProtocolRevision = 1
+)
+
+// ProtocolNegotiation is what this node and a peer agreed to speak
+type ProtocolNegotiation struct {
+	// Revision is the revision both speak - the older of the two
+	Revision     int
+	PeerRevision int
+	PeerVersion  string
+	// Capabilities are the optional features both support and Unshared those only one of them supports, which
+	// aren't used
+	Capabilities []string
+	Unshared     []string
+}
+
+// setProtocol sets the protocol revisions and capabilities this node advertises in a handshake
+func (n *NodeInfo) setProtocol() {
+	n.ProtocolRevision = ProtocolRevision
+	n.MinProtocolRevision = MinProtocolRevision
+	n.Capabilities = capabilities()
+}
+
+// CheckProtocolRevision returns an error when a peer speaking peerRevision down to peerMinRevision can't talk to this
+// build - a peer from before revisions were negotiated sends 0 and only talks to the exact same version
+func CheckProtocolRevision(peerVersion string, peerRevision, peerMinRevision int) error {
+	switch {
+	case peerRevision == 0 && peerVersion != pkgconstants.AppVersion:
+		return fmt.Errorf("peer runs %s, which predates protocol negotiation and only talks to the same version - this node runs %s", peerVersion, pkgconstants.AppVersion)
+	case peerRevision == 0:
+		return nil
+	case peerRevision < MinProtocolRevision:
+		return fmt.Errorf("peer runs %s speaking protocol revision %d, older than the %d this node (%s) needs - upgrade the peer", peerVersion, peerRevision, MinProtocolRevision, pkgconstants.AppVersion)
+	case peerMinRevision > ProtocolRevision:
+		return fmt.Errorf("peer runs %s needing protocol revision %d, newer than the %d this node (%s) speaks - upgrade this node", peerVersion, peerMinRevision, ProtocolRevision, pkgconstants.AppVersion)
+	}
+	return nil
+}
+
+// NegotiateProtocol checks the peer can talk to this build and works out the revision and capabilities both use
+func NegotiateProtocol(peer NodeInfo) (ProtocolNegotiation, error) {
+	if err := CheckProtocolRevision(peer.SolanaValidatorFailoverVersion, peer.ProtocolRevision, peer.MinProtocolRevision); err != nil {
+		return ProtocolNegotiation{}, err
+	}
+
+	negotiation := ProtocolNegotiation{
+		Revision:     min(ProtocolRevision, max(peer.ProtocolRevision, MinProtocolRevision)),
+		PeerRevision: peer.ProtocolRevision,
+		PeerVersion:  peer.SolanaValidatorFailoverVersion,
+	}
+	ours := capabilities()
+	for _, capability := range ours {
+		if slices.Contains(peer.Capabilities, capability) {
+			negotiation.Capabilities = append(negotiation.Capabilities, capability)
+		} else {
+			negotiation.Unshared = append(negotiation.Unshared, capability)
+		}
+	}
+	for _, capability := range peer.Capabilities {
+		if !slices.Contains(ours, capability) {
+			negotiation.Unshared = append(negotiation.Unshared, capability)
+		}
+	}
+	return negotiation, nil
+}
+
+// Log reports the negotiated protocol - at info when the peer runs a different version so a rollout's mixed versions
+// are visible, at debug otherwise
+func (n ProtocolNegotiation) Log(logger zerolog.Logger) {
+	event := logger.Debug()
+	if n.PeerVersion != pkgconstants.AppVersion {
+		event = logger.Info()
+	}
+	event.
+		Str("version", pkgconstants.AppVersion).
+		Str("peer_version", n.PeerVersion).
+		Int("protocol_revision", n.Revision).
+		Int("peer_protocol_revision", n.PeerRevision).
+		Str("capabilities", joinCapabilities(n.Capabilities)).
+		Str("unshared_capabilities", joinCapabilities(n.Unshared)).
+		Msg("Negotiated protocol")
+}
+
+// joinCapabilities lists capabilities for a log or report - none when there are none
+func joinCapabilities(capabilities []string) string {
+	if len(capabilities) == 0 {
+		return "none"
+	}
+	return strings.Join(capabilities, ",")
+}
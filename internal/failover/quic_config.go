@@ -0,0 +1,58 @@
+package failover
+
+import (
+	"context"
+	"crypto/tls"
+	"time"
+
+	"github.com/quic-go/quic-go"
+)
+
+// quicSessionCache keeps the session tickets failover servers issue this process so a reconnect can send 0-RTT - a
+// resumed session skips the certificate checks the full handshake that issued its ticket already made
+var quicSessionCache = tls.NewLRUClientSessionCache(0)
+
+// QUICConfig tunes the quic transport of failover connections - zero values keep quic-go's defaults. quic-go doesn't
+// let the congestion controller be chosen
+type QUICConfig struct {
+	// MaxIdleTimeout closes the connection when nothing arrives for it and KeepAlivePeriod sends a packet this often
+	// to keep it open - the server defaults them to its stream timeout and heartbeat interval
+	MaxIdleTimeout  time.Duration
+	KeepAlivePeriod time.Duration
+	// InitialStreamReceiveWindow and MaxStreamReceiveWindow bound a stream's flow-control window in bytes and the
+	// connection ones the whole connection's - the max ones must fit the link's bandwidth-delay product for the tower
+	// file to be sent at full speed
+	InitialStreamReceiveWindow     uint64
+	MaxStreamReceiveWindow         uint64
+	InitialConnectionReceiveWindow uint64
+	MaxConnectionReceiveWindow     uint64
+	// Allow0RTT accepts 0-RTT on a server and has the active node send its tower file resume request in 0-RTT when it
+	// reconnects to a server it already has a session with
+	Allow0RTT bool
+}
+
+// quicConfig returns the quic-go config for the tuning
+func (c QUICConfig) quicConfig() *quic.Config {
+	return &quic.Config{
+		MaxIdleTimeout:                 c.MaxIdleTimeout,
+		KeepAlivePeriod:                c.KeepAlivePeriod,
+		InitialStreamReceiveWindow:     c.InitialStreamReceiveWindow,
+		MaxStreamReceiveWindow:         c.MaxStreamReceiveWindow,
+		InitialConnectionReceiveWindow: c.InitialConnectionReceiveWindow,
+		MaxConnectionReceiveWindow:     c.MaxConnectionReceiveWindow,
+		Allow0RTT:                      c.Allow0RTT,
+	}
+}
+
+// dial connects to a failover server with the tuning - early sends in 0-RTT when it is allowed and a session with the
+// server was kept, which the caller must be ready to retry as the server may reject it
+func (c QUICConfig) dial(ctx context.Context, address string, tlsConfig *tls.Config, early bool) (quic.Connection, error) {
+	if !c.Allow0RTT {
+		return quic.DialAddr(ctx, address, tlsConfig, c.quicConfig())
+	}
+	tlsConfig.ClientSessionCache = quicSessionCache
+	if !early {
+		return quic.DialAddr(ctx, address, tlsConfig, c.quicConfig())
+	}
+	return quic.DialAddrEarly(ctx, address, tlsConfig, c.quicConfig())
+}
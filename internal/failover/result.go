@@ -0,0 +1,290 @@
+package failover
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// output formats for the failover result
+const (
+	OutputFormatText = "text"
+	OutputFormatJSON = "json"
+)
+
+// OutputConfig is how the failover result is reported
+type OutputConfig struct {
+	// Format is text for styled tables or json for a Result document instead
+	Format string
+	// File is written the json document instead of stdout - ignored for text
+	File string
+}
+
+// Validate checks the output format is known
+func (c OutputConfig) Validate() error {
+	switch c.Format {
+	case "", OutputFormatText, OutputFormatJSON:
+		return nil
+	}
+	return fmt.Errorf("unknown output format %q - must be %s or %s", c.Format, OutputFormatText, OutputFormatJSON)
+}
+
+// IsJSON returns true when the result is reported as a json document
+func (c OutputConfig) IsJSON() bool {
+	return c.Format == OutputFormatJSON
+}
+
+// textOutput returns where styled tables go - nowhere when the result is reported as json
+func (c OutputConfig) textOutput() io.Writer {
+	if c.IsJSON() {
+		return io.Discard
+	}
+	return os.Stdout
+}
+
+// Result is the machine-readable outcome of a failover for dashboards and runbooks
+type Result struct {
+	FailoverID       string               `json:"failover_id"`
+	TraceID          string               `json:"trace_id,omitempty"`
+	Success          bool                 `json:"success"`
+	IsDryRun         bool                 `json:"is_dry_run"`
+	ErrorMessage     string               `json:"error_message,omitempty"`
+	Summary          string               `json:"summary"`
+	RolesBefore      ResultRoles          `json:"roles_before"`
+	RolesAfter       ResultRoles          `json:"roles_after"`
+	StartSlot        uint64               `json:"start_slot"`
+	EndSlot          uint64               `json:"end_slot"`
+	Slots            uint64               `json:"slots"`
+	DurationMs       int64                `json:"duration_ms"`
+	TotalDurationMs  int64                `json:"total_duration_with_phases_ms"`
+	IdentityGapMs    int64                `json:"identity_gap_ms"`
+	IdentityGapAlarm bool                 `json:"identity_gap_alarm"`
+	Stages           []ResultStage        `json:"stages"`
+	TowerFile        ResultTowerFile      `json:"tower_file"`
+	PathMTU          *ResultPathMTU       `json:"path_mtu,omitempty"`
+	CreditSamples    []ResultCreditSample `json:"credit_samples"`
+	RPCCalls         []ResultRPCCall      `json:"rpc_calls"`
+	// ActiveIdentityDelinquent is true when the active identity was already delinquent before the failover
+	ActiveIdentityDelinquent bool `json:"active_identity_delinquent"`
+	// BlockProduction is the active identity's block production in its first leader slots after the failover - absent
+	// when not checked
+	BlockProduction *ResultBlockProduction `json:"block_production,omitempty"`
+}
+
+// ResultRoles are the nodes holding each role
+type ResultRoles struct {
+	Active  ResultNode `json:"active"`
+	Passive ResultNode `json:"passive"`
+}
+
+// ResultNode is a node and the identity it votes with in its role
+type ResultNode struct {
+	Hostname      string `json:"hostname"`
+	PublicIP      string `json:"public_ip"`
+	Pubkey        string `json:"pubkey"`
+	ClientVersion string `json:"client_version"`
+	Client        string `json:"client"`
+}
+
+// ResultStage is a timed stage of the failover - the critical window's set identity and tower file sync stages or a
+// hook or verification phase either side of it
+type ResultStage struct {
+	Stage      string    `json:"stage"`
+	Hostname   string    `json:"hostname"`
+	StartTime  time.Time `json:"start_time"`
+	DurationMs int64     `json:"duration_ms"`
+}
+
+// ResultTowerFile is the tower file synced from the active node
+type ResultTowerFile struct {
+	Bytes int    `json:"bytes"`
+	Hash  string `json:"hash"`
+}
+
+// ResultPathMTU is the path mtu the active node probed to the passive node before the failover
+type ResultPathMTU struct {
+	MTU           int  `json:"mtu"`
+	MaxUDPPayload int  `json:"max_udp_payload"`
+	DroppedAbove  int  `json:"dropped_above,omitempty"`
+	RefusedAbove  int  `json:"refused_above,omitempty"`
+	IsLow         bool `json:"is_low"`
+}
+
+// ResultBlockProduction is the active identity's block production in its first leader slots after the failover
+type ResultBlockProduction struct {
+	LeaderSlots        []uint64 `json:"leader_slots"`
+	BlocksProduced     uint64   `json:"blocks_produced"`
+	SkippedSlots       uint64   `json:"skipped_slots"`
+	SkipRate           float64  `json:"skip_rate"`
+	FirstWindowSkipped bool     `json:"first_window_skipped"`
+}
+
+// ResultCreditSample is a vote credits sample of the active identity
+type ResultCreditSample struct {
+	Timestamp time.Time `json:"timestamp"`
+	Credits   int       `json:"credits"`
+	VoteRank  int       `json:"vote_rank"`
+}
+
+// ResultRPCCall is a critical rpc call made during the failover
+type ResultRPCCall struct {
+	Hostname  string    `json:"hostname"`
+	Call      string    `json:"call"`
+	RPCURL    string    `json:"rpc_url"`
+	StartTime time.Time `json:"start_time"`
+	LatencyMs int64     `json:"latency_ms"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// GetResult returns the failover's machine-readable result
+func (s *Stream) GetResult() Result {
+	m := s.message
+	result := Result{
+		FailoverID:       m.FailoverID,
+		TraceID:          s.GetTraceID(),
+		Success:          m.IsSuccessfullyCompleted,
+		IsDryRun:         m.IsDryRunFailover,
+		ErrorMessage:     m.ErrorMessage,
+		Summary:          s.GetSummaryLine(),
+		RolesBefore:      newResultRoles(&m.ActiveNodeInfo, &m.PassiveNodeInfo),
+		RolesAfter:       newResultRoles(&m.ActiveNodeInfo, &m.PassiveNodeInfo),
+		StartSlot:        m.FailoverStartSlot,
+		EndSlot:          m.FailoverEndSlot,
+		Slots:            s.GetFailoverSlotsDuration(),
+		DurationMs:       s.GetFailoverDuration().Milliseconds(),
+		TotalDurationMs:  s.GetTotalDurationWithPhases().Milliseconds(),
+		IdentityGapMs:    s.GetIdentityGap().Milliseconds(),
+		IdentityGapAlarm: s.IsIdentityGapAlarm(),
+		TowerFile: ResultTowerFile{
+			Bytes: len(m.ActiveNodeInfo.TowerFileBytes),
+			Hash:  m.ActiveNodeInfo.TowerFileHash,
+		},
+		Stages:        []ResultStage{},
+		CreditSamples: []ResultCreditSample{},
+		RPCCalls:      []ResultRPCCall{},
+	}
+	result.ActiveIdentityDelinquent = m.ActiveIdentityDelinquent
+	if check := m.BlockProduction; check != nil {
+		result.BlockProduction = &ResultBlockProduction{
+			LeaderSlots:        check.LeaderSlots,
+			BlocksProduced:     check.Production.BlocksProduced,
+			SkippedSlots:       check.Production.SkippedSlots(),
+			SkipRate:           check.Production.SkipRate(),
+			FirstWindowSkipped: check.FirstWindowSkipped(),
+		}
+	}
+	if m.PathMTUProbe.IsProbed() {
+		result.PathMTU = &ResultPathMTU{
+			MTU:           m.PathMTUProbe.PathMTU,
+			MaxUDPPayload: m.PathMTUProbe.MaxPayload,
+			DroppedAbove:  m.PathMTUProbe.DroppedAbove,
+			RefusedAbove:  m.PathMTUProbe.RefusedAbove,
+			IsLow:         m.PathMTUProbe.IsLow(),
+		}
+	}
+	if m.IsSuccessfullyCompleted && !m.IsDryRunFailover {
+		result.RolesAfter = newResultRoles(&m.PassiveNodeInfo, &m.ActiveNodeInfo)
+	}
+
+	for _, timing := range m.PhaseTimings {
+		result.Stages = append(result.Stages, ResultStage{
+			Stage:      timing.Phase,
+			Hostname:   timing.Hostname,
+			StartTime:  timing.StartTime,
+			DurationMs: timing.Duration.Milliseconds(),
+		})
+	}
+	result.Stages = append(result.Stages,
+		ResultStage{
+			Stage:      "active set identity",
+			Hostname:   m.ActiveNodeInfo.Hostname,
+			StartTime:  m.ActiveNodeSetIdentityStartTime,
+			DurationMs: m.ActiveNodeSetIdentityEndTime.Sub(m.ActiveNodeSetIdentityStartTime).Milliseconds(),
+		},
+		ResultStage{
+			Stage:      "tower file sync",
+			Hostname:   m.ActiveNodeInfo.Hostname,
+			StartTime:  m.ActiveNodeSyncTowerFileStartTime,
+			DurationMs: m.PassiveNodeSyncTowerFileEndTime.Sub(m.ActiveNodeSyncTowerFileStartTime).Milliseconds(),
+		},
+		ResultStage{
+			Stage:      "passive set identity",
+			Hostname:   m.PassiveNodeInfo.Hostname,
+			StartTime:  m.PassiveNodeSetIdentityStartTime,
+			DurationMs: m.PassiveNodeSetIdentityEndTime.Sub(m.PassiveNodeSetIdentityStartTime).Milliseconds(),
+		},
+	)
+
+	for _, sample := range s.GetActiveIdentityVoteCreditsSamples() {
+		result.CreditSamples = append(result.CreditSamples, ResultCreditSample{
+			Timestamp: sample.Timestamp,
+			Credits:   sample.Credits,
+			VoteRank:  sample.VoteRank,
+		})
+	}
+
+	for _, call := range m.RPCCalls {
+		result.RPCCalls = append(result.RPCCalls, ResultRPCCall{
+			Hostname:  call.Hostname,
+			Call:      call.Call,
+			RPCURL:    call.RPCURL,
+			StartTime: call.StartTime,
+			LatencyMs: call.Latency.Milliseconds(),
+			Error:     call.Error,
+		})
+	}
+
+	return result
+}
+
+// newResultRoles returns the roles held by the given nodes
+func newResultRoles(active, passive *NodeInfo) ResultRoles {
+	return ResultRoles{
+		Active:  newResultNode(active, true),
+		Passive: newResultNode(passive, false),
+	}
+}
+
+// newResultNode returns a node in the role it holds - nodes vote with their active identity when active
+func newResultNode(node *NodeInfo, isActive bool) ResultNode {
+	resultNode := ResultNode{
+		Hostname:      node.Hostname,
+		PublicIP:      node.PublicIP,
+		ClientVersion: node.ClientVersion,
+		Client:        node.Client,
+	}
+	if node.Identities == nil {
+		return resultNode
+	}
+	if isActive {
+		resultNode.Pubkey = node.Identities.Active.PubKey()
+	} else {
+		resultNode.Pubkey = node.Identities.Passive.PubKey()
+	}
+	return resultNode
+}
+
+// writeResult writes the failover's result as a json document to the configured file or stdout - nothing is written
+// for text output
+func writeResult(stream *Stream, output OutputConfig) error {
+	if !output.IsJSON() {
+		return nil
+	}
+
+	document, err := json.MarshalIndent(stream.GetResult(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode failover result: %w", err)
+	}
+	document = append(document, '\n')
+
+	if output.File == "" {
+		_, err = os.Stdout.Write(document)
+		return err
+	}
+	if err := os.WriteFile(output.File, document, 0644); err != nil {
+		return fmt.Errorf("failed to write failover result to %s: %w", output.File, err)
+	}
+	return nil
+}
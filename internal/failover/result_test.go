@@ -0,0 +1,128 @@
+package failover
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resultStartTime is when the active node of newResultStream's failover began setting its identity
+var resultStartTime = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// newResultStream returns a stream for a completed real failover from active to passive
+func newResultStream() *Stream {
+	activePubkey := solana.NewWallet().PublicKey()
+	passivePubkey := solana.NewWallet().PublicKey()
+	identities := func() *NodeIdentities {
+		return &NodeIdentities{Active: &NodeIdentity{Pubkey: activePubkey}, Passive: &NodeIdentity{Pubkey: passivePubkey}}
+	}
+	startTime := resultStartTime
+
+	s := &Stream{message: Message{CreditSamples: make(CreditSamples)}}
+	m := &s.message
+	m.FailoverID = "failover-1"
+	m.IsSuccessfullyCompleted = true
+	m.FailoverStartSlot = 1000
+	m.FailoverEndSlot = 1004
+	m.ActiveNodeInfo = NodeInfo{Hostname: "active", PublicIP: "10.0.0.1", ClientVersion: "2.2.0", Client: "agave", Identities: identities(), TowerFileBytes: []byte("tower"), TowerFileHash: "hash"}
+	m.PassiveNodeInfo = NodeInfo{Hostname: "passive", PublicIP: "10.0.0.2", ClientVersion: "2.2.0", Client: "agave", Identities: identities()}
+	m.ActiveNodeSetIdentityStartTime = startTime
+	m.ActiveNodeSetIdentityEndTime = startTime.Add(100 * time.Millisecond)
+	m.ActiveNodeSyncTowerFileStartTime = startTime.Add(100 * time.Millisecond)
+	m.PassiveNodeSyncTowerFileEndTime = startTime.Add(150 * time.Millisecond)
+	m.PassiveNodeSetIdentityStartTime = startTime.Add(150 * time.Millisecond)
+	m.PassiveNodeSetIdentityEndTime = startTime.Add(400 * time.Millisecond)
+	m.PhaseTimings = []PhaseTiming{{Hostname: "active", Phase: PhasePreHooks, StartTime: startTime.Add(-time.Second), Duration: time.Second}}
+	m.RPCCalls = []RPCCallRecord{{Hostname: "active", Call: "failover start slot", RPCURL: "http://rpc", StartTime: startTime, Latency: 20 * time.Millisecond}}
+	m.CreditSamples[activePubkey.String()] = []CreditsSample{{VoteRank: 10, Credits: 500, Timestamp: startTime}}
+	return s
+}
+
+func TestWriteResult_RoundTrip(t *testing.T) {
+	s := newResultStream()
+	path := filepath.Join(t.TempDir(), "result.json")
+	require.NoError(t, writeResult(s, OutputConfig{Format: OutputFormatJSON, File: path}))
+
+	document, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var result Result
+	require.NoError(t, json.Unmarshal(document, &result))
+	assert.Equal(t, s.GetResult(), result)
+
+	assert.Equal(t, "failover-1", result.FailoverID)
+	assert.Equal(t, uint64(4), result.Slots)
+	assert.Equal(t, int64(400), result.DurationMs)
+	assert.Equal(t, int64(300), result.IdentityGapMs)
+	assert.Equal(t, ResultTowerFile{Bytes: 5, Hash: "hash"}, result.TowerFile)
+	require.Len(t, result.Stages, 4)
+	assert.Equal(t, PhasePreHooks, result.Stages[0].Stage)
+	assert.Equal(t, "tower file sync", result.Stages[2].Stage)
+	assert.Equal(t, int64(50), result.Stages[2].DurationMs)
+	require.Len(t, result.CreditSamples, 1)
+	require.Len(t, result.RPCCalls, 1)
+	assert.Equal(t, int64(20), result.RPCCalls[0].LatencyMs)
+
+	// a real failover swaps the roles
+	assert.Equal(t, "active", result.RolesBefore.Active.Hostname)
+	assert.Equal(t, "passive", result.RolesAfter.Active.Hostname)
+	assert.Equal(t, result.RolesBefore.Active.Pubkey, result.RolesAfter.Active.Pubkey)
+}
+
+func TestWriteResult_DocumentFields(t *testing.T) {
+	s := newResultStream()
+	s.message.IsDryRunFailover = true
+	path := filepath.Join(t.TempDir(), "result.json")
+	require.NoError(t, writeResult(s, OutputConfig{Format: OutputFormatJSON, File: path}))
+
+	document, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var fields map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(document, &fields))
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// dashboards and runbooks read these - optional fields that weren't set are left out
+	assert.Equal(t, []string{
+		"active_identity_delinquent",
+		"credit_samples",
+		"duration_ms",
+		"end_slot",
+		"failover_id",
+		"identity_gap_alarm",
+		"identity_gap_ms",
+		"is_dry_run",
+		"roles_after",
+		"roles_before",
+		"rpc_calls",
+		"slots",
+		"stages",
+		"start_slot",
+		"success",
+		"summary",
+		"total_duration_with_phases_ms",
+		"tower_file",
+	}, keys)
+	assert.JSONEq(t, `{"bytes": 5, "hash": "hash"}`, string(fields["tower_file"]))
+
+	// a drill leaves the roles as they were
+	var rolesBefore, rolesAfter ResultRoles
+	require.NoError(t, json.Unmarshal(fields["roles_before"], &rolesBefore))
+	require.NoError(t, json.Unmarshal(fields["roles_after"], &rolesAfter))
+	assert.Equal(t, rolesBefore, rolesAfter)
+}
+
+func TestWriteResult_TextOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "result.json")
+	require.NoError(t, writeResult(newResultStream(), OutputConfig{Format: OutputFormatText, File: path}))
+	assert.NoFileExists(t, path)
+}
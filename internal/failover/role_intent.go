@@ -0,0 +1,50 @@
+package failover
+
+import (
+	"github.com/rs/zerolog"
+	"github.com/sol-strategies/solana-validator-failover/internal/constants"
+	"github.com/sol-strategies/solana-validator-failover/internal/roleintent"
+)
+
+// RoleIntentReasonFailover is the reason recorded for a role set by a failover
+const RoleIntentReasonFailover = "failover"
+
+// recordRoleIntent records this passive node as meant to be active now a real failover has completed
+func (s *Server) recordRoleIntent() {
+	if s.isDryRunFailover {
+		return
+	}
+	writeRoleIntent(s.roleIntent, roleintent.Intent{
+		Role:       constants.NodeRoleActive,
+		Pubkey:     s.passiveNodeInfo.Identities.Active.PubKey(),
+		Hostname:   s.passiveNodeInfo.Hostname,
+		FailoverID: s.failoverStream.GetFailoverID(),
+		Reason:     RoleIntentReasonFailover,
+	}, s.logger)
+}
+
+// recordRoleIntent records this active node as meant to be passive now a real failover has completed
+func (c *Client) recordRoleIntent() {
+	if c.failoverStream.GetIsDryRunFailover() {
+		return
+	}
+	writeRoleIntent(c.roleIntent, roleintent.Intent{
+		Role:       constants.NodeRolePassive,
+		Pubkey:     c.activeNodeInfo.Identities.Passive.PubKey(),
+		Hostname:   c.activeNodeInfo.Hostname,
+		FailoverID: c.failoverStream.GetFailoverID(),
+		Reason:     RoleIntentReasonFailover,
+	}, c.logger)
+}
+
+// writeRoleIntent writes intent to store, logging rather than failing as the failover has already happened
+func writeRoleIntent(store *roleintent.Store, intent roleintent.Intent, logger zerolog.Logger) {
+	if store == nil {
+		return
+	}
+	if err := store.Write(intent); err != nil {
+		logger.Error().Err(err).Msg("failed to record role intent")
+		return
+	}
+	logger.Debug().Str("path", store.Path()).Str("role", intent.Role).Msg("recorded role intent")
+}
@@ -0,0 +1,74 @@
+package failover
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// scheduledSwitchTimeFormat is how the scheduled switch time is shown - local time with its zone so both nodes'
+// operators can tell when it is
+const scheduledSwitchTimeFormat = "Mon 15:04:05 MST"
+
+// scheduledSwitchString returns when the switch is scheduled and how long until then - empty when it isn't scheduled
+func (s *Stream) scheduledSwitchString() string {
+	scheduledSwitchAt := s.message.ScheduledSwitchAt
+	if scheduledSwitchAt.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf(
+		"%s (in %s)",
+		scheduledSwitchAt.Local().Format(scheduledSwitchTimeFormat),
+		time.Until(scheduledSwitchAt).Round(time.Second),
+	)
+}
+
+// agreeScheduledSwitchAt settles when the switch is scheduled - either node may schedule it but when both did they
+// must agree, and a time that has already passed is refused rather than switching straight away
+func (s *Server) agreeScheduledSwitchAt() error {
+	activeScheduledSwitchAt := s.failoverStream.GetScheduledSwitchAt()
+	scheduledSwitchAt := s.scheduledSwitchAt
+	switch {
+	case scheduledSwitchAt.IsZero():
+		scheduledSwitchAt = activeScheduledSwitchAt
+	case !activeScheduledSwitchAt.IsZero() && !activeScheduledSwitchAt.Equal(scheduledSwitchAt):
+		return fmt.Errorf(
+			"%s scheduled the switch for %s but this node for %s - schedule it on one node only",
+			s.failoverStream.GetActiveNodeInfo().Hostname,
+			activeScheduledSwitchAt.UTC().Format(time.RFC3339),
+			scheduledSwitchAt.UTC().Format(time.RFC3339),
+		)
+	}
+
+	if scheduledSwitchAt.IsZero() {
+		return nil
+	}
+	if s.failoverStream.GetWarmHold() {
+		return fmt.Errorf("%s holds the failover until triggered - don't schedule the switch too", s.failoverStream.GetActiveNodeInfo().Hostname)
+	}
+	if !scheduledSwitchAt.After(time.Now()) {
+		return fmt.Errorf("scheduled switch time %s has passed - re-run with a later one", scheduledSwitchAt.UTC().Format(time.RFC3339))
+	}
+	s.failoverStream.SetScheduledSwitchAt(scheduledSwitchAt)
+	s.logger.Info().
+		Time("scheduled_switch_at", scheduledSwitchAt).
+		Msgf("🗓️ Switch scheduled for %s", s.failoverStream.scheduledSwitchString())
+	return nil
+}
+
+// waitForScheduledSwitch counts down to the moment the failover is scheduled to switch at - the nodes stay connected
+// meanwhile, confirmed and with their checks done, and it stops early when the peer goes away or aborts
+func waitForScheduledSwitch(connCtx context.Context, logger zerolog.Logger, scheduledSwitchAt time.Time, peerName string) error {
+	if time.Until(scheduledSwitchAt) <= 0 {
+		logger.Warn().
+			Time("scheduled_switch_at", scheduledSwitchAt).
+			Msg("scheduled switch time passed before the failover was ready - switching now")
+		return nil
+	}
+	logger.Info().
+		Time("scheduled_switch_at", scheduledSwitchAt).
+		Msgf("🗓️ Waiting for the scheduled switch with %s", peerName)
+	return countDownToSwitch(connCtx, logger, scheduledSwitchAt, peerName)
+}
@@ -3,22 +3,37 @@ package failover
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"os"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
-	"github.com/charmbracelet/huh/spinner"
 	"github.com/quic-go/quic-go"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/audit"
+	"github.com/sol-strategies/solana-validator-failover/internal/cleanup"
 	"github.com/sol-strategies/solana-validator-failover/internal/constants"
+	"github.com/sol-strategies/solana-validator-failover/internal/events"
 	"github.com/sol-strategies/solana-validator-failover/internal/hooks"
+	"github.com/sol-strategies/solana-validator-failover/internal/identities"
+	"github.com/sol-strategies/solana-validator-failover/internal/notify"
+	"github.com/sol-strategies/solana-validator-failover/internal/peertrust"
+	"github.com/sol-strategies/solana-validator-failover/internal/ports"
+	"github.com/sol-strategies/solana-validator-failover/internal/roleintent"
 	"github.com/sol-strategies/solana-validator-failover/internal/solana"
 	"github.com/sol-strategies/solana-validator-failover/internal/style"
+	"github.com/sol-strategies/solana-validator-failover/internal/tracing"
+	"github.com/sol-strategies/solana-validator-failover/internal/ui"
 	"github.com/sol-strategies/solana-validator-failover/internal/utils"
-	pkgconstants "github.com/sol-strategies/solana-validator-failover/pkg/constants"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // ServerConfig is the configuration for the failover server
@@ -26,59 +41,213 @@ type ServerConfig struct {
 	Port              int
 	HeartbeatInterval string
 	StreamTimeout     string
-	PassiveNodeInfo   *NodeInfo
-	SolanaRPCClient   solana.ClientInterface
-	IsDryRunFailover  bool
-	Hooks             hooks.FailoverHooks
-	MonitorConfig     MonitorConfig
+	// WaitProgressInterval is how often progress is logged while waiting for the active node - 0s disables it
+	WaitProgressInterval string
+	PassiveNodeInfo      *NodeInfo
+	Peers                []PeerInfo
+	SolanaRPCClient      solana.ClientInterface
+	IsDryRunFailover     bool
+	Hooks                hooks.FailoverHooks
+	MonitorConfig        MonitorConfig
+	LogSlotContext       LogSlotContextConfig
+	TowerDriftMonitor    TowerDriftMonitorConfig
+	Events               events.Config
+	Notifications        notify.Config
+	// Identities are this node's identities - PassiveNodeInfo only holds their pubkeys, which are sent to the client
+	Identities *identities.Identities
+	// WaitTimeout stops the server with an error if no active node connects within it - zero waits forever
+	WaitTimeout time.Duration
+	// TLSCertificate is this node's persisted certificate peers pin - an ephemeral one is generated when nil
+	TLSCertificate *tls.Certificate
+	// ClientCAs requires connecting peers to present a client certificate it issued for a configured peer name - nil
+	// accepts any peer
+	ClientCAs *x509.CertPool
+	// PeerPins records the identity material the active node presents in each successful failover - nil disables it
+	PeerPins *peertrust.Store
+	// PeerAllowlist rejects connecting nodes that aren't configured peers claiming this validator's identities
+	PeerAllowlist bool
+	// NoPostMonitor skips post-failover vote credit monitoring and the metrics comparison
+	NoPostMonitor bool
+	// DetachPostMonitor hands post-failover vote credit monitoring off to a process that outlives this one, given
+	// the pre-failover vote credit rank - nil monitors in the foreground
+	DetachPostMonitor func(baselineRank int) error
+	// Output is how the failover result is reported - styled tables by default
+	Output OutputConfig
+	// SwitchCountdown is how long both nodes count down to the moment the active node switches - zero switches as
+	// soon as the active node is ready
+	SwitchCountdown time.Duration
+	// MaxSlotLag is how many slots this node may be behind the network to accept a failover - zero accepts any
+	MaxSlotLag uint64
+	// AllowDelinquentActive accepts a failover when the active identity is already delinquent - refused otherwise
+	AllowDelinquentActive bool
+	// AuditLog is appended a record of the failover when it completes or aborts - nil keeps no record
+	AuditLog *audit.Log
+	// RoleIntent records this node as meant to be active once a real failover completes - nil records nothing
+	RoleIntent *roleintent.Store
+	// Tracing exports the failover's spans when an endpoint is set - they join the active node's trace when it traces
+	Tracing tracing.Config
+	// WaitForHealthy is how long this node waited to report healthy before the failover - recorded as a span
+	WaitForHealthy tracing.Interval
+	// EphemeralFallback listens on an ephemeral port when Port is privileged and this process can't bind it
+	EphemeralFallback bool
+	// ScheduledSwitchAt is when both nodes switch once the failover is confirmed - zero switches as soon as the active
+	// node is ready unless it scheduled the switch itself
+	ScheduledSwitchAt time.Time
+	// TowerBackup backs up a tower file already in place before the received one overwrites it
+	TowerBackup TowerBackupConfig
+	// QUIC tunes the quic transport of the listener - its MaxIdleTimeout and KeepAlivePeriod default to StreamTimeout
+	// and HeartbeatInterval
+	QUIC QUICConfig
+	// TowerSanityCheck parses the received tower file and rejects it unless it is a tower the active identity saved
+	// with its last vote plausible for the failover's start slot
+	TowerSanityCheck bool
 }
 
 // Server is the failover server - run by the passive node
 type Server struct {
-	port              int
-	listenAddr        string
-	tlsConfig         *tls.Config
-	listener          quic.Listener
-	heartbeatInterval time.Duration
-	streamTimeout     time.Duration
-	ctx               context.Context
-	cancel            context.CancelFunc
-	logger            zerolog.Logger
-	passiveNodeInfo   *NodeInfo
-	solanaRPCClient   solana.ClientInterface
-	failoverStream    *Stream
-	isDryRunFailover  bool
-	activeConn        quic.Connection
-	hooks             hooks.FailoverHooks
-	monitorConfig     MonitorConfig
+	port                 int
+	listenAddr           string
+	tlsConfig            *tls.Config
+	accept               func(context.Context) (quic.Connection, error)
+	quic                 QUICConfig
+	transport            *quic.Transport
+	releaseListener      func() error
+	heartbeatInterval    time.Duration
+	streamTimeout        time.Duration
+	waitProgressInterval time.Duration
+	ctx                  context.Context
+	cancel               context.CancelFunc
+	logger               zerolog.Logger
+	passiveNodeInfo      *NodeInfo
+	identities           *identities.Identities
+	peers                []PeerInfo
+	solanaRPCClient      solana.ClientInterface
+	failoverStream       *Stream
+	isDryRunFailover     bool
+	activeConn           quic.Connection
+	activePeerCertName   string
+	peerPins             *peertrust.Store
+	peerAllowlist        bool
+	hooks                hooks.FailoverHooks
+	monitorConfig        MonitorConfig
+	logSlotContext       LogSlotContextConfig
+	towerDriftMonitor    *TowerDriftMonitor
+	events               *events.Publisher
+	notifier             *notify.Notifier
+	observers            *observerHub
+	waitTimeout          time.Duration
+	connected            atomic.Bool
+	failoverInProgress   atomic.Bool
+	timedOut             atomic.Bool
+	noPostMonitor        bool
+	detachPostMonitor    func(baselineRank int) error
+	output               OutputConfig
+	textOutput           io.Writer
+	switchCountdown      time.Duration
+	maxSlotLag           uint64
+	allowDelinquent      bool
+	auditLog             *audit.Log
+	roleIntent           *roleintent.Store
+	durationRegressions  []audit.Regression
+	tracer               *tracing.Tracer
+	failoverTrace        *failoverTrace
+	waitForHealthy       tracing.Interval
+	listeningSince       time.Time
+	// ephemeralFallback listens on an ephemeral port when the configured port can't be bound without privileges -
+	// ephemeralPort is set once it has
+	ephemeralFallback bool
+	ephemeralPort     bool
+	scheduledSwitchAt time.Time
+	// pendingAbort allows the failover to be aborted until the active node begins switching identity and
+	// towerFileRollback puts the tower file opened for it back as it was when it is
+	pendingAbort      pendingAbort
+	towerFileRollback *towerFileRollback
+	// warmHoldRelease passes release requests on to the active node while it holds the failover
+	warmHoldRelease warmHoldRelease
+	// standbyOutcome is set when the active node failed over to another passive node instead of this one
+	standbyOutcome atomic.Pointer[StandbyOutcome]
+	// towerFileResumes hands the failover the active node reconnecting to resume sending the tower file
+	towerFileResumes chan towerFileResume
+	towerBackup      TowerBackupConfig
+	towerSanityCheck bool
 }
 
 // NewServerFromConfig creates a new failover server from a configuration
 func NewServerFromConfig(config ServerConfig) (*Server, error) {
-	// TODO: accept and parse local cert if supplied
-	tlsCert, err := utils.GenerateTLSCertificate()
+	tlsCert, err := serverCertificate(config.TLSCertificate)
 	if err != nil {
 		return nil, err
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 
+	config.PassiveNodeInfo.setProtocol()
 	s := &Server{
-		port: config.Port,
+		port:              config.Port,
+		ephemeralFallback: config.EphemeralFallback,
 		tlsConfig: &tls.Config{
 			Certificates: []tls.Certificate{tlsCert},
 			NextProtos: []string{
 				ProtocolName,
 			},
 		},
-		logger:           log.With().Logger(),
-		ctx:              ctx,
-		cancel:           cancel,
-		passiveNodeInfo:  config.PassiveNodeInfo,
-		solanaRPCClient:  config.SolanaRPCClient,
-		isDryRunFailover: config.IsDryRunFailover,
-		hooks:            config.Hooks,
-		monitorConfig:    config.MonitorConfig,
+		logger:            log.With().Logger(),
+		ctx:               ctx,
+		cancel:            cancel,
+		passiveNodeInfo:   config.PassiveNodeInfo,
+		identities:        config.Identities,
+		peers:             config.Peers,
+		solanaRPCClient:   config.SolanaRPCClient,
+		isDryRunFailover:  config.IsDryRunFailover,
+		hooks:             config.Hooks,
+		monitorConfig:     config.MonitorConfig,
+		logSlotContext:    config.LogSlotContext,
+		waitTimeout:       config.WaitTimeout,
+		noPostMonitor:     config.NoPostMonitor,
+		detachPostMonitor: config.DetachPostMonitor,
+		peerPins:          config.PeerPins,
+		peerAllowlist:     config.PeerAllowlist,
+		output:            config.Output,
+		textOutput:        config.Output.textOutput(),
+		switchCountdown:   config.SwitchCountdown,
+		maxSlotLag:        config.MaxSlotLag,
+		allowDelinquent:   config.AllowDelinquentActive,
+		auditLog:          config.AuditLog,
+		roleIntent:        config.RoleIntent,
+		waitForHealthy:    config.WaitForHealthy,
+		scheduledSwitchAt: config.ScheduledSwitchAt,
+		towerFileResumes:  make(chan towerFileResume),
+		towerBackup:       config.TowerBackup,
+		towerSanityCheck:  config.TowerSanityCheck,
+	}
+
+	if config.ClientCAs != nil {
+		requireClientCertificates(s.tlsConfig, config.ClientCAs, s.peers)
+	}
+
+	s.events, err = events.NewPublisher(config.Events)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create event publisher: %w", err)
+	}
+
+	s.notifier, err = notify.NewNotifier(config.Notifications)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create notifier: %w", err)
+	}
+
+	s.tracer, err = tracing.NewTracer(config.Tracing, config.PassiveNodeInfo.Hostname)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tracer: %w", err)
+	}
+
+	s.observers = newObserverHub(s.logger)
+
+	if config.TowerDriftMonitor.Enabled {
+		s.towerDriftMonitor = NewTowerDriftMonitor(TowerDriftMonitorParams{
+			Config:          config.TowerDriftMonitor,
+			NodeInfo:        config.PassiveNodeInfo,
+			SolanaRPCClient: config.SolanaRPCClient,
+		})
 	}
 
 	if s.port == 0 {
@@ -94,6 +263,10 @@ func NewServerFromConfig(config ServerConfig) (*Server, error) {
 		config.StreamTimeout = DefaultStreamTimeoutDurationStr
 	}
 
+	if config.WaitProgressInterval == "" {
+		config.WaitProgressInterval = DefaultWaitProgressIntervalDurationStr
+	}
+
 	s.heartbeatInterval, err = time.ParseDuration(config.HeartbeatInterval)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse heartbeat interval: %v", err)
@@ -104,35 +277,137 @@ func NewServerFromConfig(config ServerConfig) (*Server, error) {
 		return nil, fmt.Errorf("failed to parse stream timeout: %v", err)
 	}
 
+	s.waitProgressInterval, err = time.ParseDuration(config.WaitProgressInterval)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse wait progress interval: %v", err)
+	}
+
+	s.quic = config.QUIC
+	if s.quic.KeepAlivePeriod == 0 {
+		s.quic.KeepAlivePeriod = s.heartbeatInterval
+	}
+	if s.quic.MaxIdleTimeout == 0 {
+		s.quic.MaxIdleTimeout = s.streamTimeout
+	}
+
 	return s, nil
 }
 
-// Start starts the failover server
-func (s *Server) Start() error {
-	listener, err := quic.ListenAddr(
-		fmt.Sprintf(":%d", s.port),
-		s.tlsConfig,
-		&quic.Config{
-			KeepAlivePeriod: s.heartbeatInterval,
-			MaxIdleTimeout:  s.streamTimeout,
-		},
-	)
+// Listen binds the failover port so its port is known before Start - Start listens itself when it hasn't been called
+func (s *Server) Listen() error {
+	if s.transport != nil {
+		return nil
+	}
+
+	// explain how to allow a privileged port rather than failing with a bare permission denied
+	if err := ports.CheckBindable(s.port); err != nil {
+		if !s.ephemeralFallback {
+			return fmt.Errorf("%w - or set failover.server.ephemeral_port_fallback: true to listen on an ephemeral port instead", err)
+		}
+		s.logger.Warn().Err(err).Msgf("Can't listen on port %d - falling back to an ephemeral port", s.port)
+		s.port = 0
+	}
+
+	// listen on a socket of our own so path mtu probes sent to the failover port can be echoed from it
+	udpConn, err := net.ListenUDP("udp", &net.UDPAddr{Port: s.port})
 	if err != nil {
 		return fmt.Errorf("failed to create listener: %v", err)
 	}
-	s.listener = *listener
+	transport := &quic.Transport{Conn: udpConn}
+	var closeListener func() error
+	if s.quic.Allow0RTT {
+		listener, err := transport.ListenEarly(s.tlsConfig, s.quic.quicConfig())
+		if err != nil {
+			udpConn.Close()
+			return fmt.Errorf("failed to create listener: %v", err)
+		}
+		s.accept = func(ctx context.Context) (quic.Connection, error) { return listener.Accept(ctx) }
+		closeListener = listener.Close
+	} else {
+		listener, err := transport.Listen(s.tlsConfig, s.quic.quicConfig())
+		if err != nil {
+			udpConn.Close()
+			return fmt.Errorf("failed to create listener: %v", err)
+		}
+		s.accept = listener.Accept
+		closeListener = listener.Close
+	}
+	if s.port == 0 {
+		s.port = udpConn.LocalAddr().(*net.UDPAddr).Port
+		s.ephemeralPort = true
+	}
+	s.transport = transport
+	s.releaseListener = cleanup.Register(fmt.Sprintf("failover server listener :%d", s.port), func() error {
+		return errors.Join(closeListener(), transport.Close(), udpConn.Close())
+	})
+	return nil
+}
+
+// Port returns the port the failover server listens on - the ephemeral port it fell back to once Listen is called
+func (s *Server) Port() int {
+	return s.port
+}
+
+// Start starts the failover server
+func (s *Server) Start() error {
+	if err := s.Listen(); err != nil {
+		return err
+	}
+	defer s.stopListening()
+	s.listeningSince = time.Now()
+
+	// until the active node begins switching identity the failover can be aborted - tell it when this node stops
+	// before then so it doesn't switch with no node to take over
+	s.pendingAbort.arm()
+	releaseAbort := cleanup.Register("pending failover abort", func() error {
+		s.abortPendingFailover("passive node stopped before the active node switched identity")
+		return nil
+	})
+	defer releaseAbort()
+	go servePathMTUProbes(s.ctx, s.transport, s.logger)
 
 	s.logger.Info().Msgf("Listening on port %d - run this program on the ACTIVE validator to continue", s.port)
+	if s.ephemeralPort {
+		s.logger.Warn().Msgf(
+			"Listening on ephemeral port %d - the active node only connects to it when asked with --via-agent, otherwise set this node's failover.peers.<name>.port to %d on the active node for this run",
+			s.port, s.port,
+		)
+	}
+
+	// watch the tower file while waiting - it should not appear or change while this node is passive
+	if s.towerDriftMonitor != nil {
+		s.towerDriftMonitor.Start()
+		defer s.towerDriftMonitor.Stop()
+	}
+
+	// log progress while waiting so operators know this process is alive and its context hasn't changed
+	if s.waitProgressInterval > 0 {
+		stopWaitProgress := s.startWaitProgress()
+		defer stopWaitProgress()
+	}
+
+	// give up waiting for the active node after the wait timeout if one is set
+	if s.waitTimeout > 0 {
+		waitTimer := time.AfterFunc(s.waitTimeout, func() {
+			if s.connected.Load() {
+				return
+			}
+			s.timedOut.Store(true)
+			s.stopListening()
+			s.cancel()
+		})
+		defer waitTimer.Stop()
+	}
 
 	for {
 		select {
 		case <-s.ctx.Done():
-			return nil
+			return s.stoppedErr()
 		default:
-			conn, err := s.listener.Accept(context.Background())
+			conn, err := s.accept(context.Background())
 			if err != nil {
 				if err.Error() == "quic: server closed" {
-					return nil
+					return s.stoppedErr()
 				}
 				s.logger.Error().Err(err).Msg("Failed to accept connection")
 				continue
@@ -143,12 +418,116 @@ func (s *Server) Start() error {
 	}
 }
 
+// startWaitProgress logs a status line every wait progress interval until the active node connects or the returned
+// func is called
+func (s *Server) startWaitProgress() (stop func()) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	startTime := time.Now()
+
+	go func() {
+		ticker := time.NewTicker(s.waitProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if s.connected.Load() {
+					return
+				}
+				s.logWaitProgress(time.Since(startTime))
+			}
+		}
+	}()
+
+	return cancel
+}
+
+// logWaitProgress logs the elapsed wait, local health, current slot and time to this node's next leader slot once it
+// is active - values that can't be fetched are logged as unknown rather than failing the wait
+func (s *Server) logWaitProgress(elapsed time.Duration) {
+	health, err := s.solanaRPCClient.GetLocalNodeHealth()
+	if err != nil {
+		health = "unknown"
+	}
+
+	slot := "unknown"
+	if currentSlot, err := s.solanaRPCClient.GetCurrentSlot(); err == nil {
+		slot = strconv.FormatUint(currentSlot, 10)
+	}
+
+	nextLeaderSlot := "unknown"
+	isOnLeaderSchedule, timeToNextLeaderSlot, err := s.solanaRPCClient.GetTimeToNextLeaderSlotForPubkey(
+		s.passiveNodeInfo.Identities.Active.PublicKey(),
+	)
+	switch {
+	case err != nil:
+		s.logger.Debug().Err(err).Msg("failed to get time to next leader slot")
+	case !isOnLeaderSchedule:
+		nextLeaderSlot = "not on leader schedule"
+	default:
+		nextLeaderSlot = timeToNextLeaderSlot.Round(time.Second).String()
+	}
+
+	s.logger.Info().
+		Str("elapsed", elapsed.Round(time.Second).String()).
+		Str("local_health", health).
+		Str("slot", slot).
+		Str("next_leader_slot_in", nextLeaderSlot).
+		Msgf("Still listening on port %d for the ACTIVE validator", s.port)
+}
+
+// stopListening closes the listener if the server is listening - safe to call more than once
+func (s *Server) stopListening() {
+	if s.releaseListener == nil {
+		return
+	}
+	if err := s.releaseListener(); err != nil {
+		s.logger.Error().Err(err).Msg("failed to close listener")
+	}
+}
+
+// stop stops the server - closing the listener and every connection
+func (s *Server) stop() {
+	s.stopListening()
+	s.cancel()
+}
+
+// stoppedErr returns why the server stopped early - the failover being aborted or no active node connecting in time,
+// and nil when the active node failed over to another passive node
+func (s *Server) stoppedErr() error {
+	if s.standbyOutcome.Load() != nil {
+		return nil
+	}
+	if err := s.pendingAbort.err(); err != nil {
+		return err
+	}
+	return s.waitTimeoutErr()
+}
+
+// waitTimeoutErr returns an error if the server stopped because no active node connected within the wait timeout
+func (s *Server) waitTimeoutErr() error {
+	if s.timedOut.Load() {
+		return fmt.Errorf("no active node connected within %s", s.waitTimeout)
+	}
+	return nil
+}
+
 // handleConnection handles a new failover connection
 func (s *Server) handleConnection(conn quic.Connection) {
-	defer conn.CloseWithError(0, "connection closed")
+	releaseConn := cleanup.Register(fmt.Sprintf("connection from %s", conn.RemoteAddr()), func() error {
+		return conn.CloseWithError(0, "connection closed")
+	})
+	defer releaseConn()
 
-	s.logger.Debug().Str("remote_addr", conn.RemoteAddr().String()).Msg("Accepted new connection")
-	s.activeConn = conn
+	peerCertName := peertrust.ClientCertificatePeerName(conn.ConnectionState().TLS)
+	s.logger.Debug().
+		Str("remote_addr", conn.RemoteAddr().String()).
+		Str("peer_name", peerCertName).
+		Msg("Accepted new connection")
+	if peerCertName != "" {
+		s.logger.Info().Str("peer_name", peerCertName).Str("remote_addr", conn.RemoteAddr().String()).Msg("Peer connected with a verified client certificate")
+	}
 
 	// Accept streams
 	for {
@@ -159,12 +538,12 @@ func (s *Server) handleConnection(conn quic.Connection) {
 		}
 
 		s.logger.Debug().Str("remote_addr", conn.RemoteAddr().String()).Msg("Accepted new stream")
-		go s.handleStream(stream)
+		go s.handleStream(conn, stream)
 	}
 }
 
-// handleStream handles a new failover stream
-func (s *Server) handleStream(stream quic.Stream) {
+// handleStream handles a new failover or observer stream
+func (s *Server) handleStream(conn quic.Connection, stream quic.Stream) {
 	defer stream.Close()
 
 	// Read the message type
@@ -181,49 +560,171 @@ func (s *Server) handleStream(stream quic.Stream) {
 	switch msgType[0] {
 	case MessageTypeFailoverInitiateRequest: // failover
 		s.logger.Debug().Msgf("Received failover initiate request")
-		s.handleFailoverStream(stream)
+		s.handleFailoverStream(conn, stream)
+	case MessageTypeObserveRequest: // read-only observer
+		s.logger.Debug().Msgf("Received observe request")
+		s.handleObserveStream(conn, stream)
+	case MessageTypeAbortRequest: // abort the pending failover
+		s.logger.Debug().Msgf("Received abort request")
+		s.handleAbortStream(conn, stream)
+	case MessageTypeReleaseRequest: // release the failover the active node holds
+		s.logger.Debug().Msgf("Received release request")
+		s.handleReleaseStream(conn, stream)
+	case MessageTypeStandbyOutcome: // the active node failed over to another passive node
+		s.logger.Debug().Msgf("Received standby outcome")
+		s.handleStandbyOutcomeStream(conn, stream)
+	case MessageTypeTowerFileResume: // the active node reconnected to resume sending the tower file
+		s.logger.Debug().Msgf("Received tower file resume request")
+		s.handleTowerFileResumeStream(conn, stream)
+	case MessageTypePing: // connectivity check
+		answerPing(conn, stream, s.peers, s.passiveNodeInfo.Hostname, s.logger)
 	default:
 		s.logger.Error().Msgf("Unknown message type: %d - ignoring stream", msgType[0])
 	}
 }
 
-func (s *Server) handleFailoverStream(stream quic.Stream) {
+// handleObserveStream has an observer prove it holds the active identity keypair then sends it the failover's
+// progress until the failover ends - observers have no say in the failover
+func (s *Server) handleObserveStream(conn quic.Connection, stream quic.Stream) {
+	observeStream := NewFailoverStream(stream)
+	if err := observeStream.AuthenticateAsPassive(s.identities.Active); err != nil {
+		s.logger.Error().Err(err).Str("remote_addr", conn.RemoteAddr().String()).Msg("🔒 observer authentication failed - ignoring observe request")
+		return
+	}
+	s.observers.serve(conn, stream, observeStream.encoder)
+}
+
+// refuseFailoverStream tells an authenticated active node a failover is already in progress on this node
+func refuseFailoverStream(failoverStream *Stream, conn quic.Connection, logger zerolog.Logger) {
+	logger.Warn().Str("remote_addr", conn.RemoteAddr().String()).Msg("failover already in progress - refusing another failover request")
+	if err := failoverStream.Decode(); err != nil {
+		return
+	}
+	failoverStream.SetErrorMessage("a failover is already in progress on this node")
+	if err := failoverStream.Encode(); err != nil {
+		logger.Error().Err(err).Msg("failed to send error message to client")
+	}
+}
+
+// handleFailoverStream has the active node authenticate then takes it through the failover
+func (s *Server) handleFailoverStream(conn quic.Connection, stream quic.Stream) {
+	// have the client prove it holds the active identity keypair before anything about this node is sent to it
+	failoverStream := NewFailoverStream(stream)
+	if err := failoverStream.AuthenticateAsPassive(s.identities.Active); err != nil {
+		s.logger.Error().Err(err).Str("remote_addr", conn.RemoteAddr().String()).Msg("🔒 peer authentication failed - ignoring failover request")
+		return
+	}
+	s.logger.Debug().Msg("peer authenticated with the active identity keypair")
+
+	// only one failover at a time - a second active node mustn't take over the connection of the one in progress
+	if !s.failoverInProgress.CompareAndSwap(false, true) {
+		refuseFailoverStream(failoverStream, conn, s.logger)
+		return
+	}
+	defer s.failoverInProgress.Store(false)
+	s.connected.Store(true)
+	s.activeConn = conn
+	s.activePeerCertName = peertrust.ClientCertificatePeerName(conn.ConnectionState().TLS)
+	s.observe(ObserverUpdateTypeProgress, fmt.Sprintf("active node connected from %s and authenticated", s.activeConn.RemoteAddr()))
+
+	// this node is about to write its tower file so stop watching it for drift
+	if s.towerDriftMonitor != nil {
+		s.towerDriftMonitor.Stop()
+	}
+
+	// give in-flight events and notifications a chance to be sent before returning
+	defer s.events.Wait()
+	defer s.notifier.Wait()
+
+	// each failover attempt can be aborted until the active node begins switching identity
+	s.pendingAbort.arm()
+
 	// read the message and parse it into a Stream struct
-	s.failoverStream = NewFailoverStream(stream)
+	connectedAt := time.Now()
+	s.failoverStream = failoverStream
 	if s.failoverStream.Decode() != nil {
 		return
 	}
 
+	// join the active node's trace - this node's root span covers its wait to report healthy and for the active node
+	traceStartTime := s.listeningSince
+	if !s.waitForHealthy.IsZero() {
+		traceStartTime = s.waitForHealthy.Start
+	}
+	s.failoverTrace = startFailoverTrace(
+		s.tracer,
+		tracing.WithTraceParent(s.ctx, s.failoverStream.GetTraceParent()),
+		constants.NodeRolePassive,
+		s.passiveNodeInfo.Hostname,
+		traceStartTime,
+	)
+	defer func() { s.failoverTrace.end(s.failoverStream.GetErrorMessage()) }()
+	s.failoverTrace.record(spanWaitForHealthy, s.waitForHealthy)
+	s.failoverTrace.record(spanWaitForActiveNode, tracing.Interval{Start: s.listeningSince, End: connectedAt})
+	handshakeSpan := s.failoverTrace.start(spanHandshake)
+	defer handshakeSpan.End()
+
+	// refuse nodes that aren't configured peers before telling them anything about this one
+	if s.peerAllowlist && !s.checkPeerAllowlist() {
+		return
+	}
+
 	// set the monitor configuration
 	s.failoverStream.SetMonitorConfig(s.monitorConfig)
+	s.failoverStream.SetSwitchCountdown(s.switchCountdown)
 
 	// set the is dry run failover flag
 	s.failoverStream.SetIsDryRunFailover(s.isDryRunFailover)
 
+	// give the failover an id to tell it apart in logs, events and chat
+	s.failoverStream.SetFailoverID(newFailoverID())
+
 	// set this node's info so subsequent responses can be sent to the client with it
 	s.failoverStream.SetPassiveNodeInfo(s.passiveNodeInfo)
 
-	// ensure client and this server are using the same version of solana-validator-failover
-	clientVersion := s.failoverStream.GetActiveNodeInfo().SolanaValidatorFailoverVersion
-	serverVersion := pkgconstants.AppVersion
-
-	s.logger.Debug().
-		Str("server_version", serverVersion).
-		Str("client_version", clientVersion).
-		Msg("checking for client and server version mismatch")
-
-	if clientVersion != serverVersion {
-		s.failoverStream.LogErrorWithSetMessagef("Server (%s) and client (%s) version mismatch", serverVersion, clientVersion)
+	// ensure the client speaks a protocol revision this server interoperates with
+	negotiation, err := NegotiateProtocol(*s.failoverStream.GetActiveNodeInfo())
+	if err != nil {
+		s.failoverStream.LogErrorWithSetMessagef("Incompatible protocol: %v", err)
 		if err := s.failoverStream.Encode(); err != nil {
 			s.logger.Error().Err(err).Msg("failed to send error message to client")
 		}
-		s.logger.Fatal().Msg("Server and client running different versions of this program - aborting")
+		s.logger.Fatal().Msg("Server and client speak incompatible protocols - aborting")
+		return
+	}
+	negotiation.Log(s.logger)
+
+	// detect this node's role now - gossip may have changed since the server started
+	if _, err := s.passiveNodeInfo.DetectRole(s.solanaRPCClient); err != nil {
+		s.logger.Warn().Err(err).Msg("failed to detect this node's role from gossip")
+	}
+	s.failoverStream.SetPassiveNodeInfo(s.passiveNodeInfo)
+
+	// ensure the roles both sides detected for themselves make sense
+	if err := checkHandshakeRoles(s.failoverStream.GetActiveNodeInfo(), s.failoverStream.GetPassiveNodeInfo()); err != nil {
+		s.failoverStream.LogErrorWithSetMessagef("Role handshake failed: %v", err)
+		if encodeErr := s.failoverStream.Encode(); encodeErr != nil {
+			s.logger.Error().Err(encodeErr).Msg("Failed to send error message to client")
+		}
+		return
+	}
+	warnClientMismatch(s.failoverStream.GetActiveNodeInfo(), s.failoverStream.GetPassiveNodeInfo(), s.logger)
+
+	// healthy isn't enough to take over - the node must be caught up enough to vote straight away
+	if err := s.checkSlotLag(); err != nil {
+		s.failoverStream.LogErrorWithSetMessagef("Passive node not ready: %v", err)
+		if encodeErr := s.failoverStream.Encode(); encodeErr != nil {
+			s.logger.Error().Err(encodeErr).Msg("Failed to send error message to client")
+		}
 		return
 	}
+	s.failoverStream.GetPathMTUProbe().warn(s.logger, s.failoverStream.GetActiveNodeInfo().Hostname)
 
-	// query gossip for client by its public IP
-	s.logger.Debug().Msgf("querying gossip for active node IP %s", s.failoverStream.GetActiveNodeInfo().PublicIP)
-	gossipActiveNode, err := s.solanaRPCClient.NodeFromIP(s.failoverStream.GetActiveNodeInfo().PublicIP)
+	// query gossip for client by its expected gossip IP - a configured peer's gossip_ip when
+	// it is behind NAT, otherwise the public IP it claims
+	expectedGossipIP := s.expectedActiveNodeGossipIP()
+	s.logger.Debug().Msgf("querying gossip for active node IP %s", expectedGossipIP)
+	gossipActiveNode, err := s.solanaRPCClient.NodeFromIP(expectedGossipIP)
 	if err != nil {
 		s.failoverStream.LogErrorWithSetMessagef("Failed to validate active node: %v", err)
 		if s.failoverStream.Encode() != nil {
@@ -232,12 +733,13 @@ func (s *Server) handleFailoverStream(stream quic.Stream) {
 		return
 	}
 
-	// ensure the failover request comes from the active node
-	if gossipActiveNode.IP() != s.failoverStream.GetActiveNodeInfo().PublicIP {
+	// ensure the failover request comes from the node voting with the active identity
+	if gossipActiveNode.PubKey() != s.failoverStream.GetActiveNodeInfo().Identities.Active.PubKey() {
 		s.failoverStream.LogErrorWithSetMessagef(
-			"Failed to validate active node: active node IP %s does not match expected IP %s",
-			gossipActiveNode.IP(),
-			s.failoverStream.GetActiveNodeInfo().PublicIP,
+			"Failed to validate active node: gossip node at %s has pubkey %s, expected active pubkey %s",
+			expectedGossipIP,
+			gossipActiveNode.PubKey(),
+			s.failoverStream.GetActiveNodeInfo().Identities.Active.PubKey(),
 		)
 		if s.failoverStream.Encode() != nil {
 			return
@@ -245,31 +747,67 @@ func (s *Server) handleFailoverStream(stream quic.Stream) {
 		return
 	}
 
+	// a delinquent active identity changes how urgent the failover is and what the post-failover monitor compares with
+	if err := s.checkActiveIdentityDelinquency(); err != nil {
+		s.failoverStream.LogErrorWithSetMessagef("Active identity delinquent: %v", err)
+		if encodeErr := s.failoverStream.Encode(); encodeErr != nil {
+			s.logger.Error().Err(encodeErr).Msg("Failed to send error message to client")
+		}
+		return
+	}
+
+	// a scheduled switch is agreed before confirming so the operator confirms knowing when it happens
+	if err := s.agreeScheduledSwitchAt(); err != nil {
+		s.failoverStream.LogErrorWithSetMessagef("Invalid switch schedule: %v", err)
+		if encodeErr := s.failoverStream.Encode(); encodeErr != nil {
+			s.logger.Error().Err(encodeErr).Msg("Failed to send error message to client")
+		}
+		return
+	}
+
+	s.failoverTrace.setFailover(s.failoverStream)
+	handshakeSpan.End()
+
+	// run check hooks so any of them can veto the failover before it is confirmed
+	if len(s.hooks.Check) > 0 {
+		phaseStartTime := time.Now()
+		s.failoverStream.SetCheckResults(s.hooks.RunChecks(s.getHookEnvMap(hookEnvMapParams{
+			isDryRunFailover: s.isDryRunFailover,
+			isPreFailover:    true,
+		})))
+		s.failoverStream.RecordPhaseTiming(newPhaseTiming(s.passiveNodeInfo.Hostname, PhaseCheckHooks, phaseStartTime))
+	}
+
 	// confirm the failover with the user
-	if err := s.failoverStream.ConfirmFailover(); err != nil {
+	// the active node may abort while the operator confirms
+	confirmSpan := s.failoverTrace.start(spanConfirm)
+	stopWatchingPeerAbort := s.watchPeerAbort()
+	err = s.failoverStream.ConfirmFailover(s.textOutput)
+	stopWatchingPeerAbort()
+	tracing.End(confirmSpan, err)
+	if s.pendingAbort.err() != nil {
+		return
+	}
+	if err != nil {
 		s.logger.Error().Err(err).Msg("failover cancelled")
 
-		// Send error message to client before exiting
+		// send the error message to the client then abort so it stops waiting either way
 		s.failoverStream.SetErrorMessagef("server cancelled failover: %v", err)
 		if encodeErr := s.failoverStream.Encode(); encodeErr != nil {
 			s.logger.Error().Err(encodeErr).Msg("Failed to send error message to client")
 		}
-
-		// close the server listener and cancel the context to stop accepting new connections
-		if s.listener != (quic.Listener{}) {
-			if err := s.listener.Close(); err != nil {
-				s.logger.Error().Err(err).Msg("failed to close listener")
-			}
-		}
-		s.cancel()
-		os.Exit(1)
+		s.abortFailover(fmt.Sprintf("server cancelled failover: %v", err))
+		return
 	}
 
 	// take a sample of vote credits and rank for the active key - use it to compare later
 	s.logger.Debug().Msg("Pulling pre-failover vote credits sample...")
+	creditSamplingSpan := s.failoverTrace.start(spanCreditSampling, attribute.String("credit_sampling.phase", "pre"))
 	err = s.failoverStream.PullActiveIdentityVoteCreditsSamples(s.solanaRPCClient, 1)
+	tracing.End(creditSamplingSpan, err)
 	if err != nil {
 		s.logger.Error().Err(err).Msg("failed to pull active identity vote credits sample")
+		s.publishAbortEvent(fmt.Sprintf("failed to pull active identity vote credits sample: %v", err))
 		s.failoverStream.SetErrorMessagef("server failed to pull active identity vote credits sample: %v", err)
 		if encodeErr := s.failoverStream.Encode(); encodeErr != nil {
 			s.logger.Error().Err(encodeErr).Msg("Failed to send error message to client")
@@ -277,8 +815,27 @@ func (s *Server) handleFailoverStream(stream quic.Stream) {
 		return
 	}
 
+	// snapshot local rpc metrics for the active identity to compare with after the failover - informational only
+	if s.monitorConfig.MetricsSnapshot.Enabled {
+		s.logger.Debug().Msg("Pulling pre-failover metrics snapshot...")
+		if err := s.failoverStream.PullMetricsSnapshot(s.solanaRPCClient, false); err != nil {
+			s.logger.Warn().Err(err).Msg("failed to pull pre-failover metrics snapshot - skipping metrics comparison")
+		}
+	}
+
 	// this is where the actual failover starts
 
+	// keep a copy of any tower file already in place - opening the tower file below truncates it
+	if err := s.prepareTowerFileRollback(s.failoverStream.GetPassiveNodeInfo().TowerFile); err != nil {
+		s.logger.Error().Err(err).Msg("failed to back up existing tower file")
+		s.publishAbortEvent(fmt.Sprintf("failed to back up existing tower file: %v", err))
+		s.failoverStream.SetErrorMessagef("server failed to back up its existing tower file: %v", err)
+		if encodeErr := s.failoverStream.Encode(); encodeErr != nil {
+			s.logger.Error().Err(encodeErr).Msg("Failed to send error message to client")
+		}
+		return
+	}
+
 	// Open tower file handle early to speed up failover
 	towerFile, err := os.OpenFile(
 		s.failoverStream.GetPassiveNodeInfo().TowerFile,
@@ -287,24 +844,31 @@ func (s *Server) handleFailoverStream(stream quic.Stream) {
 	)
 	if err != nil {
 		s.logger.Error().Err(err).Msgf("failed to open tower file %s", s.failoverStream.GetPassiveNodeInfo().TowerFile)
+		s.publishAbortEvent(fmt.Sprintf("failed to open tower file: %v", err))
 		s.failoverStream.SetErrorMessagef("server failed to open its tower file %s: %v", s.failoverStream.GetPassiveNodeInfo().TowerFile, err)
 		if encodeErr := s.failoverStream.Encode(); encodeErr != nil {
 			s.logger.Error().Err(encodeErr).Msg("Failed to send error message to client")
 		}
 		return
 	}
-	defer utils.SafeCloseFile(towerFile)
+	closeTowerFile := cleanup.Register(fmt.Sprintf("tower file handle %s", towerFile.Name()), towerFile.Close)
+	defer closeTowerFile()
 
 	// run pre hooks when passive
+	phaseStartTime := time.Now()
 	err = s.hooks.RunPreWhenPassive(s.getHookEnvMap(hookEnvMapParams{
 		isDryRunFailover: s.isDryRunFailover,
 		isPreFailover:    true,
 	}))
+	if len(s.hooks.Pre.WhenPassive) > 0 {
+		s.failoverStream.RecordPhaseTiming(newPhaseTiming(s.passiveNodeInfo.Hostname, PhasePreHooks, phaseStartTime))
+	}
 	if err != nil {
 		s.failoverStream.SetErrorMessagef("server failed to run its pre-failover hooks: %v", err)
 		if encodeErr := s.failoverStream.Encode(); encodeErr != nil {
 			s.logger.Error().Err(encodeErr).Msg("Failed to send error message to client")
 		}
+		s.publishAbortEvent(fmt.Sprintf("failed to run pre hooks when passive: %v", err))
 		s.logger.Fatal().Err(err).Msg("failed to run pre hooks when passive")
 		return
 	}
@@ -315,14 +879,82 @@ func (s *Server) handleFailoverStream(stream quic.Stream) {
 		return
 	}
 
-	s.logger.Info().Msgf("🟤 Failover started - waiting for tower file from %s", s.failoverStream.GetActiveNodeInfo().Hostname)
+	// follow the active node's heartbeats while it holds the failover - aborting when they stop so it can't switch
+	// after this node gave up on it
+	if s.failoverStream.GetWarmHold() {
+		if err := s.followWarmHold(); err != nil {
+			if s.stopIfAborted(err) {
+				return
+			}
+			s.logger.Error().Err(err).Msg("lost the active node while it held the failover")
+			s.abortFailover(fmt.Sprintf("lost the active node while it held the failover: %v", err))
+			return
+		}
+	}
+
+	// wait for the scheduled switch while the active node does - it then checks its leader slots and runs pre hooks
+	if scheduledSwitchAt := s.failoverStream.GetScheduledSwitchAt(); !scheduledSwitchAt.IsZero() {
+		s.observe(ObserverUpdateTypeProgress, fmt.Sprintf("switch scheduled for %s", scheduledSwitchAt.UTC().Format(time.RFC3339)))
+		activeNodeHostname := style.RenderActiveString(s.failoverStream.GetActiveNodeInfo().Hostname, false)
+		if err := waitForScheduledSwitch(s.activeConn.Context(), s.logger, scheduledSwitchAt, activeNodeHostname); err != nil && !s.stopIfAborted(err) {
+			s.logger.Warn().Err(err).Msg("failed to show countdown to scheduled switch - waiting for the active node")
+		}
+		if s.pendingAbort.err() != nil {
+			return
+		}
+	}
+
+	// count down with the active node to the moment it switches
+	if s.failoverStream.GetSwitchCountdown() > 0 {
+		if err := s.failoverStream.Decode(); err != nil {
+			if s.stopIfAborted(err) {
+				return
+			}
+			s.logger.Error().Err(err).Msg("failed to receive switch time")
+			s.publishAbortEvent(fmt.Sprintf("failed to receive switch time: %v", err))
+			return
+		}
+		// the active node switches once the countdown ends - too late to abort from here on
+		if s.pendingAbort.commit() != nil {
+			return
+		}
+		switchAt := localSwitchAt(s.failoverStream.GetSwitchAt(), s.failoverStream.GetSwitchCountdown(), s.logger)
+		s.observe(ObserverUpdateTypeProgress, fmt.Sprintf("switching at %s", switchAt.UTC().Format(time.RFC3339Nano)))
+		activeNodeHostname := style.RenderActiveString(s.failoverStream.GetActiveNodeInfo().Hostname, false)
+		if err := countDownToSwitch(s.activeConn.Context(), s.logger, switchAt, activeNodeHostname); err != nil {
+			s.logger.Warn().Err(err).Msg("failed to show countdown to switch - waiting for tower file")
+		}
+	}
+
+	// decorate log lines in the critical window with slot and epoch when enabled
+	baseLogger := s.logger
+	var stopSlotContext func()
+	s.logger, stopSlotContext = withSlotContext(s.logger, s.logSlotContext, s.solanaRPCClient)
+	defer stopSlotContext()
 
-	// Wait for the updated node info with tower file bytes
-	if err := s.failoverStream.Decode(); err != nil {
-		s.logger.Error().Err(err).Msg("failed to decode updated node info")
+	s.logger.Info().Msgf("🟤 Failover started - waiting for tower file from %s", s.failoverStream.GetActiveNodeInfo().Hostname)
+	s.events.Publish(s.newEvent(events.TypeStart, ""))
+	s.observe(events.TypeStart, fmt.Sprintf("failover started - waiting for tower file from %s", s.failoverStream.GetActiveNodeInfo().Hostname))
+	s.notifier.Notify(s.failoverStream.newNotification(notify.TypeStart, "", s.passiveNodeInfo.Hostname))
+
+	// Wait for the updated node info then the tower file bytes - the span includes the active node setting its identity
+	towerTransferSpan := s.failoverTrace.start(spanTowerTransfer)
+	defer towerTransferSpan.End()
+	if err := s.receiveTowerFile(); err != nil {
+		if s.pendingAbort.err() != nil {
+			return
+		}
+		s.logger.Error().Err(err).Msg("failed to receive tower file")
+		s.publishAbortEvent(fmt.Sprintf("failed to receive tower file: %v", err))
 		return
 	}
 
+	// the tower file may arrive compressed - its hash is of the file as it is on disk so one that fails to
+	// decompress is caught as a mismatch below
+	if err := s.failoverStream.GetActiveNodeInfo().decodeTowerFile(); err != nil {
+		s.logger.Error().Err(err).Msg("failed to decode tower file")
+	}
+
 	// check that the TowerFileBytes sent are the same as the hash of the tower file
 	computedTowerFileHash := s.failoverStream.GetActiveNodeInfo().ComputeTowerFileHashFromBytes(s.failoverStream.GetActiveNodeInfo().TowerFileBytes)
 	expectedTowerFileHash := s.failoverStream.GetActiveNodeInfo().TowerFileHash
@@ -332,15 +964,34 @@ func (s *Server) handleFailoverStream(stream quic.Stream) {
 	if computedTowerFileHash != expectedTowerFileHash {
 		s.logger.Error().Msgf("tower file hash mismatch: (got: %s) != (expected: %s)", computedTowerFileHash, expectedTowerFileHash)
 		s.logger.Error().Msg("aborting failover - save it by running:")
-		fmt.Printf(
-			"  rsync -avz --no-perms --no-i-r --no-progress --no-motd --no-times -e ssh -i <YOUR-SSH-KEY> -o PubkeyAcceptedKeyTypes=+ssh-ed25519 -o HostKeyAlgorithms=+ssh-ed25519 -o BatchMode=yes -o StrictHostKeyChecking=no %s@%s:%s %s \n",
-			os.Getenv("USER"),
-			s.failoverStream.GetActiveNodeInfo().Hostname,
-			s.failoverStream.GetActiveNodeInfo().TowerFile,
-			s.failoverStream.GetPassiveNodeInfo().TowerFile,
-		)
-		s.logger.Error().Msg("then run:")
-		fmt.Printf("  %s \n", s.failoverStream.GetPassiveNodeInfo().SetIdentityCommand)
+		s.printTowerFileRecovery()
+		s.publishAbortEvent(fmt.Sprintf("tower file hash mismatch: (got: %s) != (expected: %s)", computedTowerFileHash, expectedTowerFileHash))
+		s.logger.Fatal().Msg("something has turned to 💩")
+		return
+	}
+
+	// the hash isn't cryptographic - check the active identity signed the tower file for this failover
+	signed, err := s.failoverStream.verifyTowerFileSignature(s.identities.Active)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("received tower file failed its signature check")
+		s.logger.Error().Msgf("aborting failover - check the tower file on %s and if it is sound save it by running:", s.failoverStream.GetActiveNodeInfo().Hostname)
+		s.printTowerFileRecovery()
+		s.publishAbortEvent(fmt.Sprintf("tower file failed its signature check: %v", err))
+		s.logger.Fatal().Msg("something has turned to 💩")
+		return
+	}
+	if reason := s.failoverStream.GetActiveNodeInfo().TowerFileUnsignedReason; !signed && reason != "" {
+		s.logger.Warn().Str("reason", reason).Msgf("%s couldn't sign the tower file - only its hash vouches for it", s.failoverStream.GetActiveNodeInfo().Hostname)
+	} else if !signed {
+		s.logger.Warn().Msgf("%s didn't sign the tower file - upgrade it so its authenticity is checked as well as its hash", s.failoverStream.GetActiveNodeInfo().Hostname)
+	}
+
+	// the hash only shows the bytes arrived as read - check they are a tower the active identity saved
+	if err := s.checkTowerFile(); err != nil {
+		s.logger.Error().Err(err).Msg("received tower file failed its sanity check")
+		s.logger.Error().Msgf("aborting failover - check the tower file on %s and if it is sound save it by running:", s.failoverStream.GetActiveNodeInfo().Hostname)
+		s.printTowerFileRecovery()
+		s.publishAbortEvent(fmt.Sprintf("tower file failed its sanity check: %v", err))
 		s.logger.Fatal().Msg("something has turned to 💩")
 		return
 	}
@@ -348,17 +999,42 @@ func (s *Server) handleFailoverStream(stream quic.Stream) {
 	// Write bytes and close immediately
 	if _, err := towerFile.Write(s.failoverStream.GetActiveNodeInfo().TowerFileBytes); err != nil {
 		s.logger.Error().Err(err).Msgf("failed to write tower file to %s", s.failoverStream.GetPassiveNodeInfo().TowerFile)
+		s.publishAbortEvent(fmt.Sprintf("failed to write tower file: %v", err))
 		return
 	}
 
-	// close the file handle - defer utils.SafeCloseFile() above won't conflict
-	if err := towerFile.Close(); err != nil {
+	// close the file handle - the deferred release above won't close it twice
+	if err := closeTowerFile(); err != nil {
 		s.logger.Error().Err(err).Msgf("failed to close tower file %s", s.failoverStream.GetPassiveNodeInfo().TowerFile)
+		s.publishAbortEvent(fmt.Sprintf("failed to close tower file: %v", err))
 		return
 	}
 
 	s.failoverStream.SetPassiveNodeSyncTowerFileEndTime()
+	towerTransferSpan.SetAttributes(attribute.Int("tower_file.bytes", len(s.failoverStream.GetActiveNodeInfo().TowerFileBytes)))
+	towerTransferSpan.End()
 	s.logger.Info().Msg("👉 Received tower file")
+	s.observe(ObserverUpdateTypeProgress, "received tower file")
+
+	// run after tower received then before set identity hooks when passive - a failing one that must succeed stops
+	// this node taking over
+	hookEnvMap := s.getHookEnvMap(hookEnvMapParams{
+		isDryRunFailover: s.isDryRunFailover,
+		isPreFailover:    true,
+	})
+	err = s.hooks.RunAfterTowerReceived(hookEnvMap)
+	if err == nil {
+		err = s.hooks.RunBeforeSetIdentityWhenPassive(hookEnvMap)
+	}
+	if err != nil {
+		s.logger.Error().Err(err).Msg("failed to run hooks before setting identity to active")
+		s.failoverStream.SetErrorMessagef("server failed to run its hooks before setting identity to active: %v", err)
+		if encodeErr := s.failoverStream.Encode(); encodeErr != nil {
+			s.logger.Error().Err(encodeErr).Msg("Failed to send error message to client")
+		}
+		s.publishAbortEvent(fmt.Sprintf("failed to run hooks before setting identity to active: %v", err))
+		return
+	}
 
 	// set identity to active
 	dryRunPrefix := " "
@@ -375,19 +1051,28 @@ func (s *Server) handleFailoverStream(stream quic.Stream) {
 
 	s.failoverStream.SetPassiveNodeSetIdentityStartTime()
 
+	setIdentitySpan := s.failoverTrace.start(spanSetIdentity)
 	err = utils.RunCommand(utils.RunCommandParams{
 		CommandSlice: strings.Split(s.failoverStream.GetPassiveNodeInfo().SetIdentityCommand, " "),
+		Stdin:        s.identities.Active.DecryptedKeypair(),
 		DryRun:       s.isDryRunFailover,
 		LogDebug:     s.logger.Debug().Enabled(),
 	})
+	tracing.End(setIdentitySpan, err)
 	if err != nil {
+		s.publishAbortEvent(fmt.Sprintf("failed to set identity to active: %v", err))
 		s.logger.Fatal().Err(err).Msgf("failed to set identity to active with command: %s", s.failoverStream.GetPassiveNodeInfo().SetIdentityCommand)
 	}
 
 	s.failoverStream.SetPassiveNodeSetIdentityEndTime()
+	s.observe(ObserverUpdateTypeProgress, "identity set to active")
 
 	// get the current slot and record it - sometimes rpc will be a slot behind, if so, assume same-slot
+	rpcCallStartTime := time.Now()
 	failoverEndSlot, err := s.solanaRPCClient.GetCurrentSlot()
+	s.failoverStream.RecordRPCCall(newRPCCallRecord(
+		s.passiveNodeInfo.Hostname, "failover end slot", s.solanaRPCClient.NetworkRPCURL(), rpcCallStartTime, err,
+	))
 	if err != nil {
 		s.logger.Warn().Err(err).Msg("failed to get current slot")
 		err = nil
@@ -405,29 +1090,136 @@ func (s *Server) handleFailoverStream(stream quic.Stream) {
 
 	// failover is complete, timings will be reported in the main failover stream
 	s.logger.Info().Msg("🟢 Failover complete:")
-	fmt.Println(s.failoverStream.GetStateTable())
+	completeEvent := s.newEvent(events.TypeComplete, "")
+	completeEvent.Summary = s.failoverStream.GetSummaryLine()
+	s.events.Publish(completeEvent)
+	s.observe(events.TypeComplete, completeEvent.Summary)
+	s.notifier.Notify(s.failoverStream.newNotification(notify.TypeComplete, "", s.passiveNodeInfo.Hostname))
+	auditRecord := s.failoverStream.newAuditRecord(s.passiveNodeInfo.Hostname, constants.NodeRolePassive, s.activePeerName(), "")
+	appendAuditRecord(s.auditLog, auditRecord, s.logger)
+	s.checkDurationRegressions(auditRecord)
+	s.recordRoleIntent()
+	s.recordActivePeerSession()
+	stopSlotContext()
+	s.logger = baseLogger
+	fmt.Fprintln(s.textOutput, s.failoverStream.GetStateTable())
 
 	// run post hooks when active
-	s.hooks.RunPostWhenActive(s.getHookEnvMap(hookEnvMapParams{
-		isDryRunFailover: s.isDryRunFailover,
-		isPostFailover:   true,
-	}))
+	if len(s.hooks.Post.WhenActive) > 0 {
+		phaseStartTime = time.Now()
+		s.hooks.RunPostWhenActive(s.getHookEnvMap(hookEnvMapParams{
+			isDryRunFailover: s.isDryRunFailover,
+			isPostFailover:   true,
+		}))
+		s.failoverStream.RecordPhaseTiming(newPhaseTiming(s.passiveNodeInfo.Hostname, PhasePostHooks, phaseStartTime))
+	}
 
-	s.logger.Info().Msg("🕐 Failover timing summary:")
-	fmt.Println(s.failoverStream.GetFailoverDurationTableString())
+	s.checkIdentityGap()
 
 	if !s.isDryRunFailover {
-		s.confirmGossipNodesPostFailover()
+		phaseStartTime = time.Now()
+		gossipConfirmSpan := s.failoverTrace.start(spanGossipConfirm)
+		confirmed := s.confirmGossipNodesPostFailover()
+		gossipConfirmSpan.End()
+		s.failoverStream.RecordPhaseTiming(newPhaseTiming(s.passiveNodeInfo.Hostname, PhaseGossipConfirm, phaseStartTime))
+
+		// run after gossip confirmed hooks now this is active as far as the cluster can tell
+		if confirmed {
+			s.hooks.RunAfterGossipConfirmed(s.getHookEnvMap(hookEnvMapParams{
+				isPostFailover: true,
+			}))
+		}
+	}
+
+	s.logger.Info().Msg("📡 Critical RPC calls:")
+	fmt.Fprintln(s.textOutput, s.failoverStream.GetRPCCallsTableString())
+
+	s.postMonitor()
+
+	// close the stream and connection cleanly - the tower file transfer may have resumed on another one
+	if err := s.failoverStream.Stream.Close(); err != nil {
+		s.logger.Error().Err(err).Msg("failed to close stream")
+	}
+	if err := s.activeConn.CloseWithError(quic.ApplicationErrorCode(0), "failover complete"); err != nil {
+		s.logger.Debug().Msgf("closing connection after successful failover: %v", err)
+	}
+
+	// export this node's spans before the server stops - the process exits soon after
+	s.failoverTrace.end("")
+
+	// let observers catch up, then close the server listener and cancel the context to stop accepting new connections
+	s.observers.close()
+	s.stopListening()
+	s.cancel()
+}
+
+// printTowerFileRecovery prints the commands to copy the active node's tower file here by hand and set this node's
+// identity to active - for when the failover is aborted after the active node went passive
+func (s *Server) printTowerFileRecovery() {
+	fmt.Printf(
+		"  rsync -avz --no-perms --no-i-r --no-progress --no-motd --no-times -e ssh -i <YOUR-SSH-KEY> -o PubkeyAcceptedKeyTypes=+ssh-ed25519 -o HostKeyAlgorithms=+ssh-ed25519 -o BatchMode=yes -o StrictHostKeyChecking=no %s@%s:%s %s \n",
+		os.Getenv("USER"),
+		s.failoverStream.GetActiveNodeInfo().Hostname,
+		s.failoverStream.GetActiveNodeInfo().TowerFile,
+		s.failoverStream.GetPassiveNodeInfo().TowerFile,
+	)
+	s.logger.Error().Msg("then run:")
+	fmt.Printf("  %s \n", s.failoverStream.GetPassiveNodeInfo().SetIdentityCommand)
+}
+
+// postMonitor monitors vote credits and compares metrics after the failover then prints the timing summary - it is
+// skipped or handed off to a detached process when asked so the operator gets their terminal back
+func (s *Server) postMonitor() {
+	defer s.writeResult()
+	defer s.logSummary()
+	defer s.logRewardsEstimate()
+
+	if s.noPostMonitor {
+		s.logger.Info().Msg("Skipping post-failover vote credit monitoring")
+		s.logTimingSummary()
+		return
+	}
+
+	if s.detachPostMonitor != nil {
+		// a delinquent active identity's rank is no baseline - compare with the first sample after instead
+		baselineRank := 0
+		if samples := s.failoverStream.GetActiveIdentityVoteCreditsSamples(); len(samples) > 0 && !s.failoverStream.GetActiveIdentityDelinquent() {
+			baselineRank = samples[0].VoteRank
+		}
+		err := s.detachPostMonitor(baselineRank)
+		if err == nil {
+			s.logTimingSummary()
+			return
+		}
+		s.logger.Warn().Err(err).Msg("failed to detach post-failover monitoring - monitoring here instead")
 	}
 
 	// monitor the credits by pulling configured samples
 	s.logger.Info().Msg("🩺 Monitoring vote credits post-failover...")
-	err = s.failoverStream.PullActiveIdentityVoteCreditsSamples(s.solanaRPCClient, s.failoverStream.GetMonitorConfig().CreditSamples.Count)
+	phaseStartTime := time.Now()
+	creditSamplingSpan := s.failoverTrace.start(spanCreditSampling, attribute.String("credit_sampling.phase", "post"))
+	err := s.failoverStream.PullActiveIdentityVoteCreditsSamples(s.solanaRPCClient, s.failoverStream.GetMonitorConfig().CreditSamples.Count)
+	tracing.End(creditSamplingSpan, err)
+	s.failoverStream.RecordPhaseTiming(newPhaseTiming(s.passiveNodeInfo.Hostname, PhaseCreditMonitoring, phaseStartTime))
 	if err != nil {
 		s.logger.Error().Err(err).Msg("failed to pull active identity vote credits samples")
+		s.logTimingSummary()
 		return
 	}
 
+	// compare local rpc metrics with the pre-failover snapshot
+	if s.monitorConfig.MetricsSnapshot.Enabled && s.failoverStream.GetPreFailoverMetrics() != nil {
+		if err := s.failoverStream.PullMetricsSnapshot(s.solanaRPCClient, true); err != nil {
+			s.logger.Warn().Err(err).Msg("failed to pull post-failover metrics snapshot")
+		} else if metricsTable, err := s.failoverStream.GetMetricsComparisonTableString(); err == nil {
+			s.logger.Info().Msg("📊 Pre/post-failover metrics:")
+			fmt.Fprintln(s.textOutput, metricsTable)
+		}
+	}
+
+	s.verifyBlockProduction()
+	s.logTimingSummary()
+
 	// report the credit samples difference
 	rankDifference, firstRank, lastRank, err := s.failoverStream.GetVoteCreditRankDifference()
 	if err != nil {
@@ -435,26 +1227,197 @@ func (s *Server) handleFailoverStream(stream quic.Stream) {
 		return
 	}
 	s.logger.Info().Msgf("🏁 Vote credit rank change: %d (%d -> %d)", rankDifference, firstRank, lastRank)
+	if s.failoverStream.GetActiveIdentityDelinquent() {
+		s.logger.Info().Msg("Active identity was delinquent before the failover - not comparing against its rank then")
+		return
+	}
+	if rankDifference < 0 {
+		s.notifier.Notify(s.failoverStream.newNotification(notify.TypeCreditRegression, "", s.passiveNodeInfo.Hostname))
+	}
+}
 
-	// close the stream and connection cleanly
-	if err := stream.Close(); err != nil {
-		s.logger.Error().Err(err).Msg("failed to close stream")
+// verifyBlockProduction checks the active identity produces blocks in its first leader slots after the failover when
+// enabled - credit rank lags behind, a fully skipped first leader window doesn't
+func (s *Server) verifyBlockProduction() {
+	if !s.monitorConfig.BlockProduction.Enabled {
+		return
 	}
-	if err := s.activeConn.CloseWithError(quic.ApplicationErrorCode(0), "failover complete"); err != nil {
-		s.logger.Debug().Msgf("closing connection after successful failover: %v", err)
+	if err := s.failoverStream.PullBlockProduction(s.solanaRPCClient, s.logger); err != nil {
+		s.logger.Warn().Err(err).Msg("failed to check post-failover block production")
+		return
 	}
+	if reason := s.failoverStream.GetBlockProductionFailure(); reason != "" {
+		s.notifier.Notify(s.failoverStream.newNotification(notify.TypeBlockProductionFailure, reason, s.passiveNodeInfo.Hostname))
+	}
+}
 
-	// close the server listener and cancel the context to stop accepting new connections
-	if s.listener != (quic.Listener{}) {
-		if err := s.listener.Close(); err != nil {
-			s.logger.Error().Err(err).Msg("failed to close listener")
-		}
+// logTimingSummary prints the failover timing summary table - once verification has finished so it can be included
+func (s *Server) logTimingSummary() {
+	s.logger.Info().Msg("🕐 Failover timing summary:")
+	fmt.Fprintln(s.textOutput, s.failoverStream.GetFailoverDurationTableString())
+}
+
+// logSummary logs the failover as a single line for pasting into incident channels
+func (s *Server) logSummary() {
+	s.logger.Info().
+		Str("failover_id", s.failoverStream.GetFailoverID()).
+		Str("summary", s.failoverStream.GetSummaryLine()).
+		Msg("📋 Summary:")
+	fmt.Fprintln(s.textOutput, s.failoverStream.GetSummaryLine())
+}
+
+// writeResult writes the failover result as a json document when asked to
+func (s *Server) writeResult() {
+	if err := writeResult(s.failoverStream, s.output); err != nil {
+		s.logger.Error().Err(err).Msg("failed to write failover result")
 	}
-	s.cancel()
 }
 
-// confirmGossipNodesPostFailover confirms that the gossip nodes have switched roles post-failover
-func (s *Server) confirmGossipNodesPostFailover() {
+// logRewardsEstimate logs what the identity gap is estimated to have cost in epoch rewards when enabled
+func (s *Server) logRewardsEstimate() {
+	if !s.monitorConfig.RewardsEstimate.Enabled || s.isDryRunFailover {
+		return
+	}
+
+	rewardsContext, err := s.solanaRPCClient.GetRewardsContext(s.failoverStream.GetActiveNodeInfo().Identities.Active.PubKey())
+	if err != nil {
+		s.logger.Warn().Err(err).Msg("failed to get rewards context to estimate the failover's rewards cost")
+		return
+	}
+
+	identityGap := s.failoverStream.GetIdentityGap()
+	credits, commissionLamports, delegatorLamports := rewardsContext.MissedRewards(identityGap)
+	s.logger.Info().
+		Dur("identity_gap", identityGap).
+		Uint64("epoch", rewardsContext.Epoch).
+		Uint64("missed_vote_credits", credits).
+		Float64("point_value", rewardsContext.PointValue).
+		Uint64("activated_stake_lamports", rewardsContext.ActivatedStake).
+		Uint64("commission_lamports", commissionLamports).
+		Uint64("delegator_lamports", delegatorLamports).
+		Msgf("💸 Estimated rewards cost: %.6f SOL (commission %.6f SOL, delegators %.6f SOL) for ~%d missed vote credits",
+			solana.LamportsToSOL(commissionLamports+delegatorLamports),
+			solana.LamportsToSOL(commissionLamports),
+			solana.LamportsToSOL(delegatorLamports),
+			credits,
+		)
+}
+
+// checkIdentityGap logs how long the validator wasn't voting anywhere and alarms when it exceeds the threshold
+func (s *Server) checkIdentityGap() {
+	identityGap := s.failoverStream.GetIdentityGap()
+	if !s.failoverStream.IsIdentityGapAlarm() {
+		s.logger.Info().Dur("identity_gap", identityGap).Msgf("⏱️ Identity gap (not voting anywhere): %s", identityGap)
+		return
+	}
+
+	reason := fmt.Sprintf(
+		"identity gap %s exceeded failover.monitor.identity_gap_alarm_threshold %s",
+		identityGap,
+		s.monitorConfig.IdentityGapAlarmThreshold,
+	)
+	s.logger.Error().Dur("identity_gap", identityGap).Msgf("🚨 %s", reason)
+	s.events.Publish(s.newEvent(events.TypeIdentityGapAlarm, reason))
+	s.observe(events.TypeIdentityGapAlarm, reason)
+}
+
+// checkDurationRegressions compares the failover's phase durations against the median of earlier failovers in the
+// audit log and alerts on those slower than allowed - they are passed to post hooks too
+func (s *Server) checkDurationRegressions(record audit.Record) {
+	s.durationRegressions = durationRegressions(s.auditLog, record, s.monitorConfig.DurationRegression, s.logger)
+	if len(s.durationRegressions) == 0 {
+		return
+	}
+
+	descriptions := make([]string, 0, len(s.durationRegressions))
+	for _, regression := range s.durationRegressions {
+		s.logger.Warn().
+			Str("phase", regression.Phase).
+			Str("role", regression.Role).
+			Int64("duration_ms", regression.DurationMs).
+			Int64("median_ms", regression.MedianMs).
+			Float64("increase_percent", regression.IncreasePercent).
+			Msgf("🐢 Slower than usual: %s", regression)
+		descriptions = append(descriptions, regression.String())
+	}
+	reason := fmt.Sprintf(
+		"phases exceeded failover.monitor.duration_regression.threshold_percent %v: %s",
+		s.monitorConfig.DurationRegression.ThresholdPercent,
+		strings.Join(descriptions, "; "),
+	)
+	s.events.Publish(s.newEvent(events.TypeDurationRegression, reason))
+}
+
+// newEvent creates a failover event from the current failover stream
+func (s *Server) newEvent(eventType, reason string) events.Event {
+	return events.Event{
+		IdentityGapMs: s.failoverStream.GetIdentityGap().Milliseconds(),
+		Type:          eventType,
+		IsDryRun:      s.isDryRunFailover,
+		ActiveNode: events.Node{
+			Hostname: s.failoverStream.GetActiveNodeInfo().Hostname,
+			PublicIP: s.failoverStream.GetActiveNodeInfo().PublicIP,
+			Pubkey:   s.failoverStream.GetActiveNodeInfo().Identities.Active.PubKey(),
+		},
+		PassiveNode: events.Node{
+			Hostname: s.failoverStream.GetPassiveNodeInfo().Hostname,
+			PublicIP: s.failoverStream.GetPassiveNodeInfo().PublicIP,
+			Pubkey:   s.failoverStream.GetPassiveNodeInfo().Identities.Passive.PubKey(),
+		},
+		Reason:         reason,
+		ClientCertPeer: s.activePeerCertName,
+		FailoverID:     s.failoverStream.GetFailoverID(),
+	}
+}
+
+// publishAbortEvent publishes a failover abort event and notification and waits for them to be sent - the caller is
+// usually about to exit
+func (s *Server) publishAbortEvent(reason string) {
+	// the active node may be waiting to switch - tell it the failover is off rather than leave it hanging
+	if s.pendingAbort.abort(reason) {
+		closeWithAbort(s.activeConn, reason)
+		s.rollbackTowerFile()
+	}
+	abortEvent := s.newEvent(events.TypeAbort, reason)
+	abortEvent.Summary = s.failoverStream.GetSummaryLine()
+	s.events.Publish(abortEvent)
+	s.notifier.Notify(s.failoverStream.newNotification(notify.TypeAbort, reason, s.passiveNodeInfo.Hostname))
+	s.observe(events.TypeAbort, reason)
+	appendAuditRecord(s.auditLog, s.failoverStream.newAuditRecord(s.passiveNodeInfo.Hostname, constants.NodeRolePassive, s.activePeerName(), reason), s.logger)
+	s.failoverTrace.end(reason)
+	s.runAbortHooks(hookEnvMapParams{isPreFailover: true, abortReason: reason})
+	s.events.Wait()
+	s.notifier.Wait()
+	s.observers.close()
+}
+
+// runAbortHooks runs the on abort hooks for the node that was passive - once the active node's info, which they are
+// told about, has been received
+func (s *Server) runAbortHooks(params hookEnvMapParams) {
+	if s.failoverStream == nil || s.failoverStream.GetActiveNodeInfo().Identities == nil {
+		return
+	}
+	params.isDryRunFailover = s.isDryRunFailover
+	s.hooks.RunOnAbortWhenPassive(s.getHookEnvMap(params))
+}
+
+// observe sends an update to any read-only observers
+func (s *Server) observe(updateType, message string) {
+	update := ObserverUpdate{
+		Time:     time.Now().UTC(),
+		Hostname: s.passiveNodeInfo.Hostname,
+		Type:     updateType,
+		Message:  message,
+	}
+	if s.failoverStream != nil {
+		update.FailoverID = s.failoverStream.GetFailoverID()
+	}
+	s.observers.broadcast(update)
+}
+
+// confirmGossipNodesPostFailover confirms that the gossip nodes have switched roles post-failover - returns whether
+// gossip shows both did
+func (s *Server) confirmGossipNodesPostFailover() bool {
 	var (
 		solanaActiveNode                        *solana.Node
 		solanaPassiveNode                       *solana.Node
@@ -463,39 +1426,60 @@ func (s *Server) confirmGossipNodesPostFailover() {
 		isPassiveNodeKeySwitchReflectedInGossip bool
 	)
 
-	sp := spinner.New().Title("confirming gossip nodes switched roles...")
+	sp := ui.NewSpinner().Title("confirming gossip nodes switched roles...")
 	sp.ActionWithErr(func(ctx context.Context) error {
 		maxRetries := 4
 		retryCount := 0
 		retryDelay := 2 * time.Second
-		// it can take a few seconds for gossip to update so try to refresh gossip identities a few times before claiming error
+		// it can take a few seconds for gossip to update so try to refresh gossip identities a few times before claiming
+		// error - failed rpc calls are retried by the rpc client
 		for retryCount < maxRetries {
 			retryCount++
 			hasRetriesLeft := retryCount < maxRetries
 
+			// both lookups share one fresh gossip snapshot - a cached one may predate the switch
+			s.solanaRPCClient.InvalidateClusterNodes()
+
 			// active node is now the old passive node
+			rpcCallStartTime := time.Now()
 			solanaActiveNode, err = s.solanaRPCClient.NodeFromIP(s.failoverStream.GetPassiveNodeInfo().PublicIP)
-			if err != nil && hasRetriesLeft {
-				sp.Title(style.RenderWarningStringf("(attempt %d of %d) failed to refresh active node info from gossip - retrying", retryCount, maxRetries))
+			s.failoverStream.RecordRPCCall(newRPCCallRecord(
+				s.passiveNodeInfo.Hostname,
+				fmt.Sprintf("gossip confirm active node (attempt %d)", retryCount),
+				s.solanaRPCClient.NetworkRPCURL(),
+				rpcCallStartTime,
+				err,
+			))
+			// rpc errors were already retried by the client - only a node missing from gossip is worth waiting on
+			if errors.Is(err, solana.ErrNodeNotFound) && hasRetriesLeft {
+				sp.Title(style.RenderWarningStringf("(attempt %d of %d) active node not in gossip yet - retrying in %s", retryCount, maxRetries, retryDelay))
 				time.Sleep(retryDelay)
 				continue
 			}
-			if err != nil && !hasRetriesLeft {
+			if err != nil {
 				sp.Title(style.RenderErrorStringf("(attempt %d of %d) failed to refresh active node info from gossip - giving up", retryCount, maxRetries))
 				s.logger.Error().Err(err).Msgf("(attempt %d of %d) failed to refresh active node info from gossip - giving up", retryCount, maxRetries)
-				return fmt.Errorf("(attempt %d of %d) failed to refresh active node info from gossip - giving up", retryCount, maxRetries)
+				return fmt.Errorf("(attempt %d of %d) failed to refresh active node info from gossip - giving up: %w", retryCount, maxRetries, err)
 			}
 
 			// passive node is now the old active node
+			rpcCallStartTime = time.Now()
 			solanaPassiveNode, err = s.solanaRPCClient.NodeFromIP(s.failoverStream.GetActiveNodeInfo().PublicIP)
-			if err != nil && hasRetriesLeft {
-				sp.Title(style.RenderWarningStringf("(attempt %d of %d) failed to refresh fetch passive node info - retrying", retryCount, maxRetries))
+			s.failoverStream.RecordRPCCall(newRPCCallRecord(
+				s.passiveNodeInfo.Hostname,
+				fmt.Sprintf("gossip confirm passive node (attempt %d)", retryCount),
+				s.solanaRPCClient.NetworkRPCURL(),
+				rpcCallStartTime,
+				err,
+			))
+			if errors.Is(err, solana.ErrNodeNotFound) && hasRetriesLeft {
+				sp.Title(style.RenderWarningStringf("(attempt %d of %d) passive node not in gossip yet - retrying in %s", retryCount, maxRetries, retryDelay))
 				time.Sleep(retryDelay)
 				continue
 			}
-			if err != nil && !hasRetriesLeft {
+			if err != nil {
 				sp.Title(style.RenderErrorStringf("(attempt %d of %d) failed to refresh fetch passive node info - giving up", retryCount, maxRetries))
-				return fmt.Errorf("(attempt %d of %d) failed to refresh fetch passive node info - giving up", retryCount, maxRetries)
+				return fmt.Errorf("(attempt %d of %d) failed to refresh fetch passive node info - giving up: %w", retryCount, maxRetries, err)
 			}
 
 			// check the gossip pubkeys switched
@@ -573,9 +1557,128 @@ func (s *Server) confirmGossipNodesPostFailover() {
 
 	if isActiveNodeKeySwitchReflectedInGossip && isPassiveNodeKeySwitchReflectedInGossip {
 		s.logger.Info().Msg("Gossip confirms nodes switched roles successfully")
-	} else {
-		s.logger.Error().Msg("Gossip does not confirm role switch")
+		return true
+	}
+
+	s.logger.Error().Msg("Gossip does not confirm role switch")
+	reason := "gossip does not confirm role switch"
+	if err != nil {
+		reason = err.Error()
+	}
+	s.notifier.Notify(s.failoverStream.newNotification(notify.TypeGossipConfirmFailure, reason, s.passiveNodeInfo.Hostname))
+	s.runAbortHooks(hookEnvMapParams{isPostFailover: true, abortReason: reason})
+	return false
+}
+
+// checkSlotLag refuses a failover while this node is further behind the network than allowed - a failure to find out
+// is only warned about as the node already reported healthy
+func (s *Server) checkSlotLag() error {
+	if s.maxSlotLag == 0 {
+		return nil
+	}
+	slotLag, err := s.solanaRPCClient.GetSlotLag()
+	if err != nil {
+		s.logger.Warn().Err(err).Msg("failed to get slot lag - accepting failover")
+		return nil
+	}
+	s.logger.Debug().Uint64("slot_lag", slotLag).Uint64("max_slot_lag", s.maxSlotLag).Msg("slot lag")
+	if slotLag > s.maxSlotLag {
+		return fmt.Errorf(
+			"%s is %d slots behind the network, more than failover.max_slot_lag %d - retry once it has caught up",
+			s.passiveNodeInfo.Hostname,
+			slotLag,
+			s.maxSlotLag,
+		)
+	}
+	return nil
+}
+
+// checkActiveIdentityDelinquency records the active identity's vote account for the summary and refuses a failover when
+// it is already delinquent unless allowed - a failure to find out is only warned about
+func (s *Server) checkActiveIdentityDelinquency() error {
+	activePubkey := s.failoverStream.GetActiveNodeInfo().Identities.Active.PubKey()
+	status, err := s.solanaRPCClient.GetVoteAccountStatus(activePubkey)
+	if err != nil {
+		s.logger.Warn().Err(err).Msg("failed to check whether the active identity is delinquent")
+		return nil
+	}
+	s.failoverStream.SetActiveVoteAccount(status)
+	if !status.Delinquent {
+		return nil
+	}
+
+	s.failoverStream.SetActiveIdentityDelinquent(true)
+	if !s.allowDelinquent {
+		return fmt.Errorf(
+			"active identity %s is already delinquent (last vote %d) - re-run on %s with --force to fail over anyway",
+			activePubkey,
+			status.LastVote,
+			s.passiveNodeInfo.Hostname,
+		)
+	}
+	s.logger.Warn().
+		Str("pubkey", activePubkey).
+		Uint64("last_vote", status.LastVote).
+		Msg("⚠️ Active identity is already delinquent - failing over anyway as forced")
+	return nil
+}
+
+// recordActivePeerSession records the identity material the active node presented when it is a configured peer
+func (s *Server) recordActivePeerSession() {
+	if s.peerPins == nil {
+		return
+	}
+
+	activeNodeInfo := s.failoverStream.GetActiveNodeInfo()
+	peer, ok := PeerInfo{Name: s.activePeerCertName}, s.activePeerCertName != ""
+	if !ok && s.activeConn != nil {
+		peer, ok = matchPeer(s.peers, utils.HostFromAddress(s.activeConn.RemoteAddr().String()), activeNodeInfo.PublicIP, true)
+	}
+	if !ok {
+		s.logger.Debug().Str("hostname", activeNodeInfo.Hostname).Msg("active node is not a configured peer - not recording its session")
+		return
+	}
+
+	recordPeerSession(s.peerPins, peer.Name, activeNodeInfo, "", s.logger)
+}
+
+// activePeerName returns the configured peer name of the connected active node - empty when it isn't a configured peer
+func (s *Server) activePeerName() string {
+	remoteIP := ""
+	if s.activeConn != nil {
+		remoteIP = utils.HostFromAddress(s.activeConn.RemoteAddr().String())
 	}
+	peer, _ := matchPeer(s.peers, remoteIP, s.failoverStream.GetActiveNodeInfo().PublicIP, true)
+	return peer.Name
+}
+
+// expectedActiveNodeGossipIP returns the IP the connecting active node is expected to have in gossip.
+// A configured peer matching the connection's remote IP or the claimed public IP wins, so NAT-mapped
+// peers are validated against their configured gossip_ip rather than the address they connect from.
+func (s *Server) expectedActiveNodeGossipIP() string {
+	claimedPublicIP := s.failoverStream.GetActiveNodeInfo().PublicIP
+
+	remoteIP := ""
+	if s.activeConn != nil {
+		remoteIP = utils.HostFromAddress(s.activeConn.RemoteAddr().String())
+	}
+
+	for _, peer := range s.peers {
+		if peer.GossipIP == "" {
+			continue
+		}
+		peerHost := utils.HostFromAddress(peer.Address)
+		if peerHost == remoteIP || peerHost == claimedPublicIP || peer.GossipIP == remoteIP || peer.GossipIP == claimedPublicIP {
+			s.logger.Debug().
+				Str("peer", peer.Name).
+				Str("gossip_ip", peer.GossipIP).
+				Str("remote_ip", remoteIP).
+				Msg("matched connecting node to configured peer")
+			return peer.GossipIP
+		}
+	}
+
+	return claimedPublicIP
 }
 
 // getEnvMap returns a map of environment variables to pass to the hooks
@@ -583,6 +1686,11 @@ func (s *Server) getHookEnvMap(params hookEnvMapParams) (envMap map[string]strin
 	envMap = map[string]string{}
 
 	envMap["IS_DRY_RUN_FAILOVER"] = fmt.Sprintf("%t", params.isDryRunFailover)
+	envMap["FAILOVER_ID"] = s.failoverStream.GetFailoverID()
+	if params.abortReason != "" {
+		envMap["ABORT_REASON"] = params.abortReason
+	}
+	s.failoverStream.setHookEnv(envMap, params, s.failoverStream.GetPassiveNodeInfo())
 
 	// this node is passive
 	if params.isPreFailover {
@@ -594,16 +1702,26 @@ func (s *Server) getHookEnvMap(params hookEnvMapParams) (envMap map[string]strin
 	if params.isPostFailover {
 		envMap["THIS_NODE_ROLE"] = constants.NodeRoleActive
 		envMap["PEER_NODE_ROLE"] = constants.NodeRolePassive
+		envMap["IDENTITY_GAP_MS"] = strconv.FormatInt(s.failoverStream.GetIdentityGap().Milliseconds(), 10)
+		envMap["SUMMARY"] = s.failoverStream.GetSummaryLine()
+		if switchAt := s.failoverStream.GetSwitchAt(); !switchAt.IsZero() {
+			envMap["SWITCH_AT"] = switchAt.UTC().Format(time.RFC3339Nano)
+		}
+		if len(s.durationRegressions) > 0 {
+			regressions, _ := json.Marshal(s.durationRegressions)
+			envMap["DURATION_REGRESSIONS"] = string(regressions)
+		}
 	}
 
 	// this node is passive
 	envMap["THIS_NODE_NAME"] = s.passiveNodeInfo.Hostname
 	envMap["THIS_NODE_PUBLIC_IP"] = s.passiveNodeInfo.PublicIP
 	envMap["THIS_NODE_ACTIVE_IDENTITY_PUBKEY"] = s.passiveNodeInfo.Identities.Active.PubKey()
-	envMap["THIS_NODE_ACTIVE_IDENTITY_KEYPAIR_FILE"] = s.passiveNodeInfo.Identities.Active.KeyFile
+	envMap["THIS_NODE_ACTIVE_IDENTITY_KEYPAIR_FILE"] = s.identities.Active.KeyFile
 	envMap["THIS_NODE_PASSIVE_IDENTITY_PUBKEY"] = s.passiveNodeInfo.Identities.Passive.PubKey()
-	envMap["THIS_NODE_PASSIVE_IDENTITY_KEYPAIR_FILE"] = s.passiveNodeInfo.Identities.Passive.KeyFile
+	envMap["THIS_NODE_PASSIVE_IDENTITY_KEYPAIR_FILE"] = s.identities.Passive.KeyFile
 	envMap["THIS_NODE_CLIENT_VERSION"] = s.passiveNodeInfo.ClientVersion
+	envMap["THIS_NODE_CLIENT"] = s.passiveNodeInfo.Client
 
 	// peer node is active
 	envMap["PEER_NODE_NAME"] = s.failoverStream.GetActiveNodeInfo().Hostname
@@ -611,6 +1729,27 @@ func (s *Server) getHookEnvMap(params hookEnvMapParams) (envMap map[string]strin
 	envMap["PEER_NODE_ACTIVE_IDENTITY_PUBKEY"] = s.failoverStream.GetActiveNodeInfo().Identities.Active.PubKey()
 	envMap["PEER_NODE_PASSIVE_IDENTITY_PUBKEY"] = s.failoverStream.GetActiveNodeInfo().Identities.Passive.PubKey()
 	envMap["PEER_NODE_CLIENT_VERSION"] = s.failoverStream.GetActiveNodeInfo().ClientVersion
+	envMap["PEER_NODE_CLIENT"] = s.failoverStream.GetActiveNodeInfo().Client
 
 	return
 }
+
+// requireClientCertificates makes tlsConfig require peers to present a client certificate issued by clientCAs for the
+// name of one of peers
+func requireClientCertificates(tlsConfig *tls.Config, clientCAs *x509.CertPool, peers []PeerInfo) {
+	peerNames := make([]string, 0, len(peers))
+	for _, peer := range peers {
+		peerNames = append(peerNames, peer.Name)
+	}
+	tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	tlsConfig.ClientCAs = clientCAs
+	tlsConfig.VerifyConnection = peertrust.VerifyClientCertificatePeerName(peerNames)
+}
+
+// serverCertificate returns the supplied certificate or an ephemeral one when none is supplied
+func serverCertificate(cert *tls.Certificate) (tls.Certificate, error) {
+	if cert != nil {
+		return *cert, nil
+	}
+	return utils.GenerateTLSCertificate()
+}
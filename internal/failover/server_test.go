@@ -0,0 +1,162 @@
+package failover
+
+import (
+	"context"
+	"crypto/tls"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/rs/zerolog"
+	"github.com/sol-strategies/solana-validator-failover/internal/identities"
+	"github.com/sol-strategies/solana-validator-failover/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newListeningTestServer starts a passive node's server handling connections on a loopback quic listener and returns
+// its address
+func newListeningTestServer(t *testing.T) (*Server, string) {
+	t.Helper()
+	tlsCert, err := utils.GenerateTLSCertificate()
+	require.NoError(t, err)
+	listener, err := quic.ListenAddr("127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{tlsCert},
+		NextProtos:   []string{ProtocolName},
+	}, nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(func() {
+		cancel()
+		listener.Close()
+	})
+
+	s := &Server{
+		ctx:             ctx,
+		logger:          zerolog.Nop(),
+		observers:       newObserverHub(zerolog.Nop()),
+		passiveNodeInfo: &NodeInfo{Hostname: "passive"},
+		identities:      &identities.Identities{Active: newTestIdentity(t), Passive: newTestIdentity(t)},
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept(ctx)
+			if err != nil {
+				return
+			}
+			go s.handleConnection(conn)
+		}
+	}()
+	return s, listener.Addr().String()
+}
+
+// observe runs an observer against the server at address in the background and returns the updates it reads and the
+// error it returns once done
+func observe(address string, activeIdentity *identities.Identity) (<-chan ObserverUpdate, <-chan error) {
+	updates := make(chan ObserverUpdate, observerUpdateBufferSize)
+	done := make(chan error, 1)
+	go func() {
+		done <- Observe(ObserveConfig{ServerName: "passive", ServerAddress: address, ActiveIdentity: activeIdentity}, func(update ObserverUpdate) {
+			updates <- update
+		})
+	}()
+	return updates, done
+}
+
+func TestObserve_CatchesUpThenFollows(t *testing.T) {
+	s, address := newListeningTestServer(t)
+	s.observe(ObserverUpdateTypeProgress, "waiting for the active node")
+
+	updates, done := observe(address, s.identities.Active)
+
+	// updates sent before the observer joined are replayed to it
+	select {
+	case update := <-updates:
+		assert.Equal(t, "waiting for the active node", update.Message)
+		assert.Equal(t, "passive", update.Hostname)
+	case <-time.After(5 * time.Second):
+		t.Fatal("observer wasn't sent the updates before it joined")
+	}
+
+	s.observe(ObserverUpdateTypeProgress, "active node connected")
+	select {
+	case update := <-updates:
+		assert.Equal(t, "active node connected", update.Message)
+	case <-time.After(5 * time.Second):
+		t.Fatal("observer wasn't sent the update after it joined")
+	}
+
+	// the observer returns cleanly once the failover ends
+	s.observers.close()
+	require.NoError(t, <-done)
+}
+
+func TestObserve_WrongIdentity(t *testing.T) {
+	s, address := newListeningTestServer(t)
+	s.observe(ObserverUpdateTypeProgress, "waiting for the active node")
+
+	updates, done := observe(address, newTestIdentity(t))
+	assert.ErrorContains(t, <-done, "passive node refused this node")
+	assert.Empty(t, updates)
+}
+
+func TestObserverHub_DropsSlowObserver(t *testing.T) {
+	hub := newObserverHub(zerolog.Nop())
+	slow := &observer{updates: make(chan ObserverUpdate, observerUpdateBufferSize)}
+	hub.observers[slow] = struct{}{}
+
+	// an observer that reads nothing is dropped once its buffer is full rather than holding up the failover
+	for range observerUpdateBufferSize + 1 {
+		hub.broadcast(ObserverUpdate{Message: "update"})
+	}
+	assert.Empty(t, hub.observers)
+	assert.Len(t, hub.history, observerUpdateBufferSize+1)
+
+	// it is still sent what it had queued before being hung up on
+	queued := 0
+	for range slow.updates {
+		queued++
+	}
+	assert.Equal(t, observerUpdateBufferSize, queued)
+}
+
+func TestObserverHub_Closed(t *testing.T) {
+	hub := newObserverHub(zerolog.Nop())
+	hub.close()
+	hub.close()
+
+	// updates after the failover ends aren't kept for late observers
+	hub.broadcast(ObserverUpdate{Message: "late"})
+	assert.Empty(t, hub.history)
+}
+
+func TestHandleFailoverStream_Unauthenticated(t *testing.T) {
+	s, address := newListeningTestServer(t)
+
+	_, _, err := dialAuthenticated("passive", address, newTestIdentity(t), nil, QUICConfig{}, MessageTypeFailoverInitiateRequest)
+	assert.ErrorContains(t, err, "passive node refused this node")
+
+	// the connection isn't taken as the active node's
+	assert.False(t, s.connected.Load())
+	assert.Nil(t, s.activeConn)
+	assert.Empty(t, s.activePeerCertName)
+}
+
+func TestHandleFailoverStream_FailoverInProgress(t *testing.T) {
+	s, address := newListeningTestServer(t)
+	s.failoverInProgress.Store(true)
+
+	conn, stream, err := dialAuthenticated("passive", address, s.identities.Active, nil, QUICConfig{}, MessageTypeFailoverInitiateRequest)
+	require.NoError(t, err)
+	defer conn.CloseWithError(0, "done")
+
+	stream.message.ActiveNodeInfo = NodeInfo{Hostname: "second-active"}
+	require.NoError(t, stream.Encode())
+	require.NoError(t, stream.Decode())
+	assert.Equal(t, "a failover is already in progress on this node", stream.GetErrorMessage())
+
+	// the failover in progress keeps its connection
+	assert.False(t, s.connected.Load())
+	assert.Nil(t, s.activeConn)
+}
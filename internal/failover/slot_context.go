@@ -0,0 +1,99 @@
+package failover
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/solana"
+)
+
+// slotContext samples the current slot and epoch from a background ticker so log lines during the
+// critical failover window can be decorated with them without an rpc call per log line
+type slotContext struct {
+	slot            atomic.Uint64
+	epoch           atomic.Uint64
+	interval        time.Duration
+	solanaRPCClient solana.ClientInterface
+	cancel          context.CancelFunc
+}
+
+// newSlotContext creates a new slot context sampling every interval
+func newSlotContext(solanaRPCClient solana.ClientInterface, interval time.Duration) *slotContext {
+	return &slotContext{
+		interval:        interval,
+		solanaRPCClient: solanaRPCClient,
+	}
+}
+
+// start starts sampling in the background, the first sample straight away - nothing waits on the rpc so log lines
+// go undecorated until it answers
+func (sc *slotContext) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	sc.cancel = cancel
+
+	go func() {
+		sc.sample()
+		ticker := time.NewTicker(sc.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				sc.sample()
+			}
+		}
+	}()
+}
+
+// stop stops the background ticker
+func (sc *slotContext) stop() {
+	if sc.cancel != nil {
+		sc.cancel()
+	}
+}
+
+// sample pulls the current slot and epoch - errors are logged at debug and the last sample kept
+func (sc *slotContext) sample() {
+	epochInfo, err := sc.solanaRPCClient.GetCurrentEpochInfo()
+	if err != nil {
+		log.Debug().Err(err).Msg("failed to sample slot context")
+		return
+	}
+	sc.slot.Store(epochInfo.AbsoluteSlot)
+	sc.epoch.Store(epochInfo.Epoch)
+}
+
+// Run implements zerolog.Hook adding the last sampled slot and epoch to the event
+func (sc *slotContext) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	slot := sc.slot.Load()
+	if slot == 0 {
+		return
+	}
+	e.Uint64("slot", slot).Uint64("epoch", sc.epoch.Load())
+}
+
+// withSlotContext starts a slot context when enabled and returns a logger decorated with it along
+// with a function to stop it - when disabled the logger is returned as is
+func withSlotContext(logger zerolog.Logger, cfg LogSlotContextConfig, solanaRPCClient solana.ClientInterface) (zerolog.Logger, func()) {
+	if !cfg.Enabled {
+		return logger, func() {}
+	}
+
+	sc := newSlotContext(solanaRPCClient, slotContextInterval(cfg))
+	sc.start()
+
+	return logger.Hook(sc), sc.stop
+}
+
+// slotContextInterval returns the configured sampling interval - the default when it isn't set or isn't valid
+func slotContextInterval(cfg LogSlotContextConfig) time.Duration {
+	if interval, err := time.ParseDuration(cfg.Interval); err == nil && interval > 0 {
+		return interval
+	}
+	interval, _ := time.ParseDuration(DefaultLogSlotContextIntervalDurationStr)
+	return interval
+}
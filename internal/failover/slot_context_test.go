@@ -0,0 +1,57 @@
+package failover
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/rs/zerolog"
+	solanapkg "github.com/sol-strategies/solana-validator-failover/internal/solana"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithSlotContext_SamplesInBackground(t *testing.T) {
+	release := make(chan struct{})
+	client := solanapkg.NewMockClient().WithGetCurrentEpochInfo(func() (*rpc.GetEpochInfoResult, error) {
+		<-release
+		return &rpc.GetEpochInfoResult{AbsoluteSlot: 1000, Epoch: 7}, nil
+	})
+
+	var logs bytes.Buffer
+	startTime := time.Now()
+	logger, stop := withSlotContext(zerolog.New(&logs), LogSlotContextConfig{Enabled: true, Interval: "10ms"}, client)
+	defer stop()
+
+	// a slow rpc doesn't hold up the critical window
+	assert.Less(t, time.Since(startTime), 100*time.Millisecond)
+	logger.Info().Msg("before sample")
+	assert.NotContains(t, logs.String(), `"slot"`)
+
+	close(release)
+	assert.Eventually(t, func() bool {
+		logs.Reset()
+		logger.Info().Msg("after sample")
+		return bytes.Contains(logs.Bytes(), []byte(`"slot":1000,"epoch":7`))
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestWithSlotContext_Disabled(t *testing.T) {
+	client := solanapkg.NewMockClient().WithGetCurrentEpochInfo(func() (*rpc.GetEpochInfoResult, error) {
+		t.Error("sampled while disabled")
+		return nil, nil
+	})
+
+	var logs bytes.Buffer
+	logger, stop := withSlotContext(zerolog.New(&logs), LogSlotContextConfig{}, client)
+	stop()
+	logger.Info().Msg("undecorated")
+	assert.NotContains(t, logs.String(), `"slot"`)
+}
+
+func TestSlotContextInterval(t *testing.T) {
+	assert.Equal(t, 400*time.Millisecond, slotContextInterval(LogSlotContextConfig{}))
+	assert.Equal(t, 400*time.Millisecond, slotContextInterval(LogSlotContextConfig{Interval: "soon"}))
+	assert.Equal(t, 400*time.Millisecond, slotContextInterval(LogSlotContextConfig{Interval: "-1s"}))
+	assert.Equal(t, time.Second, slotContextInterval(LogSlotContextConfig{Interval: "1s"}))
+}
@@ -0,0 +1,128 @@
+package failover
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go"
+)
+
+// standbyOutcomeTimeout is how long informing a standby of the failover's outcome may take once connected
+const standbyOutcomeTimeout = 5 * time.Second
+
+// StandbyOutcome tells a passive node waiting for a failover that the active node failed over to another one - sent
+// after authenticating on a stream opened with MessageTypeStandbyOutcome
+type StandbyOutcome struct {
+	// Hostname is the node that was active before the failover
+	Hostname string
+	// NewActiveHostname is the passive node that took over
+	NewActiveHostname string
+	FailoverID        string
+	IsDryRunFailover  bool
+}
+
+// StandbyOutcomeResponse is a standby's answer to being told the outcome - Error is set when it couldn't stand down
+type StandbyOutcomeResponse struct {
+	Error string
+}
+
+// informStandbys tells the passive peers that weren't failed over to who took over so their failover servers stop
+// waiting - standbys that aren't waiting for a failover don't answer and are skipped
+func (c *Client) informStandbys() {
+	if len(c.standbys) == 0 {
+		return
+	}
+
+	outcome := StandbyOutcome{
+		Hostname:          c.activeNodeInfo.Hostname,
+		NewActiveHostname: c.failoverStream.GetPassiveNodeInfo().Hostname,
+		FailoverID:        c.failoverStream.GetFailoverID(),
+		IsDryRunFailover:  c.failoverStream.GetIsDryRunFailover(),
+	}
+
+	var wg sync.WaitGroup
+	for _, standby := range c.standbys {
+		wg.Add(1)
+		go func(standby PeerInfo) {
+			defer wg.Done()
+			if err := c.informStandby(standby, outcome); err != nil {
+				c.logger.Debug().Err(err).Str("peer_name", standby.Name).Msg("standby not informed of the failover outcome - it isn't waiting for a failover")
+				return
+			}
+			c.logger.Info().Str("peer_name", standby.Name).Msgf("Told standby %s that %s took over", standby.Name, outcome.NewActiveHostname)
+		}(standby)
+	}
+	wg.Wait()
+}
+
+// informStandby tells one standby the failover's outcome
+func (c *Client) informStandby(standby PeerInfo, outcome StandbyOutcome) error {
+	conn, stream, err := dialAuthenticated(standby.Name, standby.Address, c.identities.Active, c.clientCertificate, QUICConfig{}, MessageTypeStandbyOutcome)
+	if err != nil {
+		return err
+	}
+	defer conn.CloseWithError(0, "standby outcome done")
+	defer stream.Stream.Close()
+	if err := stream.Stream.SetDeadline(time.Now().Add(standbyOutcomeTimeout)); err != nil {
+		return err
+	}
+
+	if err := stream.encoder.Encode(outcome); err != nil {
+		return fmt.Errorf("failed to send failover outcome: %w", err)
+	}
+	var response StandbyOutcomeResponse
+	if err := stream.decoder.Decode(&response); err != nil {
+		return fmt.Errorf("failed to read failover outcome response: %w", err)
+	}
+	if response.Error != "" {
+		c.logger.Warn().Str("peer_name", standby.Name).Msgf("standby %s didn't stand down: %s", standby.Name, response.Error)
+	}
+	return nil
+}
+
+// handleStandbyOutcomeStream has the active node prove it holds the active identity keypair then, when it failed
+// over to another node, stops waiting for it - this node stays passive
+func (s *Server) handleStandbyOutcomeStream(conn quic.Connection, stream quic.Stream) {
+	outcomeStream := NewFailoverStream(stream)
+	if err := outcomeStream.AuthenticateAsPassive(s.identities.Active); err != nil {
+		s.logger.Error().Err(err).Str("remote_addr", conn.RemoteAddr().String()).Msg("🔒 failover outcome sender authentication failed - ignoring it")
+		return
+	}
+
+	var outcome StandbyOutcome
+	if err := outcomeStream.decoder.Decode(&outcome); err != nil {
+		s.logger.Error().Err(err).Msg("failed to read failover outcome")
+		return
+	}
+
+	var response StandbyOutcomeResponse
+	standingDown := !s.handshakeReceived()
+	if standingDown {
+		// nothing can be aborted once standing down - stopping must not tell the active node it was
+		_ = s.pendingAbort.commit()
+		s.standbyOutcome.Store(&outcome)
+	} else {
+		response.Error = "this node is failing over with the active node itself"
+		s.logger.Error().Msgf("%s says it failed over to %s while failing over with this node - check which node is voting", outcome.Hostname, outcome.NewActiveHostname)
+	}
+	if err := outcomeStream.encoder.Encode(response); err != nil {
+		s.logger.Error().Err(err).Msg("failed to send failover outcome response")
+	}
+	if !standingDown {
+		return
+	}
+
+	failoverKind := "failed over"
+	if outcome.IsDryRunFailover {
+		failoverKind = "ran a drill"
+	}
+	s.logger.Info().
+		Str("failover_id", outcome.FailoverID).
+		Msgf("🟤 %s %s with %s - this node stays passive and stops waiting", outcome.Hostname, failoverKind, outcome.NewActiveHostname)
+	s.observe(ObserverUpdateTypeProgress, fmt.Sprintf("%s %s with %s - standing down", outcome.Hostname, failoverKind, outcome.NewActiveHostname))
+
+	// the active node hangs up once it has read the response - stopping closes every connection
+	awaitPeerClose(conn)
+	s.stop()
+}
@@ -5,19 +5,22 @@ import (
 	"context"
 	"encoding/gob"
 	"fmt"
+	"io"
 	"maps"
 	"strings"
 	"text/template"
 	"time"
 
-	"github.com/charmbracelet/huh/spinner"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/table"
 	"github.com/dustin/go-humanize"
 	"github.com/quic-go/quic-go"
 	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/hooks"
 	"github.com/sol-strategies/solana-validator-failover/internal/solana"
 	"github.com/sol-strategies/solana-validator-failover/internal/style"
+	"github.com/sol-strategies/solana-validator-failover/internal/tracing"
+	"github.com/sol-strategies/solana-validator-failover/internal/ui"
 	pkgconstants "github.com/sol-strategies/solana-validator-failover/pkg/constants"
 )
 
@@ -156,10 +159,10 @@ func (s Stream) GetFailoverEndSlot() uint64 {
 }
 
 // ConfirmFailover is called by the passive node to proceed with the failover
-// it shows confirmation message and waits for user to confirm. once confirmed
+// it shows confirmation message on out and waits for user to confirm. once confirmed
 // it allows the stream to proceed and the active node begins setting identity
 // and tower file sync
-func (s *Stream) ConfirmFailover() (err error) {
+func (s *Stream) ConfirmFailover(out io.Writer) (err error) {
 	// Add custom function to split commands
 	funcMap := template.FuncMap{
 		"splitCommand": func(cmd string) string {
@@ -180,6 +183,12 @@ func (s *Stream) ConfirmFailover() (err error) {
 	tpl, err = tpl.Parse(`{{ Purple "solana-validator-failover v" }}{{ Purple .AppVersion }}
 
 {{ .SummaryTable }}
+{{- if .CheckResultsTable }}
+
+Check hooks:
+
+{{ .CheckResultsTable }}
+{{- end }}
 
 {{/* Clear warning when not a drill i.e not a dry run */}}
 {{- if .IsDryRun -}}
@@ -188,6 +197,15 @@ func (s *Stream) ConfirmFailover() (err error) {
 {{- else -}}
 {{ Warning "WARNING: This is a real failover - identities will be changed on both nodes" }}
 {{- end }}
+{{- if .ActiveIdentityDelinquent }}
+{{ Warning "WARNING: The active identity is already delinquent - it isn't voting now, so vote credits before the failover are no baseline" }}
+{{- end }}
+{{- if .ScheduledSwitch }}
+{{ Blue (printf "INFO: The switch is scheduled for %s - both nodes wait for it once confirmed" .ScheduledSwitch) }}
+{{- end }}
+{{- if .WarmHold }}
+{{ Blue (printf "INFO: %s holds the failover once confirmed - it switches when its operator triggers it" .ActiveNodeInfo.Hostname) }}
+{{- end }}
 
 Failing over will:
 
@@ -212,31 +230,107 @@ Failing over will:
 
 	var buf bytes.Buffer
 	if err := tpl.Execute(&buf, map[string]any{
-		"IsDryRun":        s.message.IsDryRunFailover,
-		"PassiveNodeInfo": s.message.PassiveNodeInfo,
-		"ActiveNodeInfo":  s.message.ActiveNodeInfo,
-		"SummaryTable":    s.message.currentStateTableString(),
-		"AppVersion":      pkgconstants.AppVersion,
+		"IsDryRun":                 s.message.IsDryRunFailover,
+		"PassiveNodeInfo":          s.message.PassiveNodeInfo,
+		"ActiveNodeInfo":           s.message.ActiveNodeInfo,
+		"SummaryTable":             s.message.currentStateTableString(),
+		"CheckResultsTable":        s.checkResultsTableString(),
+		"AppVersion":               pkgconstants.AppVersion,
+		"ActiveIdentityDelinquent": s.message.ActiveIdentityDelinquent,
+		"ScheduledSwitch":          s.scheduledSwitchString(),
+		"WarmHold":                 s.message.WarmHold,
 	}); err != nil {
 		return fmt.Errorf("failed to execute template: %w", err)
 	}
 
 	// print confirm message
-	fmt.Println(style.RenderMessageString(buf.String()))
+	fmt.Fprintln(out, style.RenderMessageString(buf.String()))
+
+	// any check hook disallowing the failover vetoes it
+	if vetoes := hooks.Vetoes(s.message.CheckResults); len(vetoes) > 0 {
+		reasons := make([]string, 0, len(vetoes))
+		for _, veto := range vetoes {
+			reasons = append(reasons, fmt.Sprintf("%s: %s", veto.Name, veto.Reason))
+		}
+		fmt.Fprintln(out, style.RenderWarningString("Failover vetoed by check hooks"))
+		return fmt.Errorf("vetoed by check hooks - %s", strings.Join(reasons, "; "))
+	}
 
 	// automatically proceed with failover without confirmation
-	fmt.Println(style.RenderActiveString("Proceeding with failover", false))
+	fmt.Fprintln(out, style.RenderActiveString("Proceeding with failover", false))
 
 	return nil
 }
 
-// GetFailoverDuration returns the failover duration
+// SetCheckResults sets the verdicts of this node's check hooks
+func (s *Stream) SetCheckResults(results []hooks.CheckResult) {
+	s.message.CheckResults = results
+}
+
+// GetCheckResults returns the verdicts of the passive node's check hooks
+func (s *Stream) GetCheckResults() []hooks.CheckResult {
+	return s.message.CheckResults
+}
+
+// checkResultsTableString returns a table of the check hook verdicts - empty when no check hooks ran
+func (s *Stream) checkResultsTableString() string {
+	if len(s.message.CheckResults) == 0 {
+		return ""
+	}
+
+	rows := make([][]string, 0, len(s.message.CheckResults))
+	for _, result := range s.message.CheckResults {
+		verdict := "allow"
+		if !result.Allow {
+			verdict = "deny"
+		}
+		rows = append(rows, []string{result.Name, verdict, result.Reason})
+	}
+
+	return style.RenderTable(
+		[]string{"Check", "Verdict", "Reason"},
+		rows,
+		func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return style.TableHeaderStyle
+			}
+			if col == 1 && !s.message.CheckResults[row].Allow {
+				return style.TableCellStyle.Align(lipgloss.Left).Foreground(style.ColorWarning)
+			}
+			return style.TableCellStyle.Align(lipgloss.Left)
+		},
+	)
+}
+
+// GetFailoverDuration returns the failover duration - 0 until the passive node has set its identity
 func (s *Stream) GetFailoverDuration() time.Duration {
+	if s.message.PassiveNodeSetIdentityEndTime.IsZero() || s.message.ActiveNodeSetIdentityStartTime.IsZero() {
+		return 0
+	}
 	return s.message.PassiveNodeSetIdentityEndTime.Sub(s.message.ActiveNodeSetIdentityStartTime)
 }
 
-// GetFailoverSlotsDuration returns the failover slots duration
+// GetIdentityGap returns the time between the active node finishing setting its passive identity and the passive node
+// finishing setting its active identity - the window in which the validator isn't voting anywhere. It spans both
+// nodes' clocks so any offset between them adds to it
+func (s *Stream) GetIdentityGap() time.Duration {
+	if s.message.ActiveNodeSetIdentityEndTime.IsZero() || s.message.PassiveNodeSetIdentityEndTime.IsZero() {
+		return 0
+	}
+	return s.message.PassiveNodeSetIdentityEndTime.Sub(s.message.ActiveNodeSetIdentityEndTime)
+}
+
+// IsIdentityGapAlarm returns true if the identity gap exceeds the configured alarm threshold
+func (s *Stream) IsIdentityGapAlarm() bool {
+	threshold := s.message.MonitorConfig.IdentityGapAlarmThresholdDuration()
+	return threshold > 0 && s.GetIdentityGap() > threshold
+}
+
+// GetFailoverSlotsDuration returns the failover slots duration - 0 until the end slot is known
 func (s *Stream) GetFailoverSlotsDuration() uint64 {
+	if s.GetFailoverEndSlot() < s.GetFailoverStartSlot() {
+		return 0
+	}
 	return s.GetFailoverEndSlot() - s.GetFailoverStartSlot()
 }
 
@@ -255,7 +349,89 @@ func (s *Stream) SetMonitorConfig(config MonitorConfig) {
 	s.message.MonitorConfig = config
 }
 
-// GetFailoverDurationTableString returns the failover duration table string
+// GetSwitchCountdown returns how long both nodes count down to the switch
+func (s *Stream) GetSwitchCountdown() time.Duration {
+	return s.message.SwitchCountdown
+}
+
+// SetSwitchCountdown sets how long both nodes count down to the switch
+func (s *Stream) SetSwitchCountdown(countdown time.Duration) {
+	s.message.SwitchCountdown = countdown
+}
+
+// GetActiveIdentityDelinquent returns whether the active identity was already delinquent before the failover
+func (s *Stream) GetActiveIdentityDelinquent() bool {
+	return s.message.ActiveIdentityDelinquent
+}
+
+// SetActiveIdentityDelinquent sets whether the active identity was already delinquent before the failover
+func (s *Stream) SetActiveIdentityDelinquent(delinquent bool) {
+	s.message.ActiveIdentityDelinquent = delinquent
+}
+
+// SetActiveVoteAccount sets the active identity's vote account before the failover
+func (s *Stream) SetActiveVoteAccount(voteAccount solana.VoteAccountStatus) {
+	s.message.ActiveVoteAccount = voteAccount
+}
+
+// GetSwitchAt returns the moment the active node switches identity
+func (s *Stream) GetSwitchAt() time.Time {
+	return s.message.SwitchAt
+}
+
+// SetSwitchAt sets the moment the active node switches identity
+func (s *Stream) SetSwitchAt(switchAt time.Time) {
+	s.message.SwitchAt = switchAt
+}
+
+// GetScheduledSwitchAt returns the moment the failover is scheduled to switch at - zero when it isn't scheduled
+func (s *Stream) GetScheduledSwitchAt() time.Time {
+	return s.message.ScheduledSwitchAt
+}
+
+// SetScheduledSwitchAt sets the moment the failover is scheduled to switch at
+func (s *Stream) SetScheduledSwitchAt(scheduledSwitchAt time.Time) {
+	s.message.ScheduledSwitchAt = scheduledSwitchAt
+}
+
+// GetWarmHold returns whether the active node holds the failover until its operator triggers the switch
+func (s *Stream) GetWarmHold() bool {
+	return s.message.WarmHold
+}
+
+// SetWarmHold sets whether the active node holds the failover until its operator triggers the switch
+func (s *Stream) SetWarmHold(warmHold bool) {
+	s.message.WarmHold = warmHold
+}
+
+// GetTraceParent returns the w3c traceparent of the active node's failover span
+func (s *Stream) GetTraceParent() string {
+	return s.message.TraceParent
+}
+
+// SetTraceParent sets the w3c traceparent of the active node's failover span
+func (s *Stream) SetTraceParent(traceParent string) {
+	s.message.TraceParent = traceParent
+}
+
+// GetPathMTUProbe returns the path mtu the active node probed to the passive node
+func (s *Stream) GetPathMTUProbe() PathMTUProbeResult {
+	return s.message.PathMTUProbe
+}
+
+// SetPathMTUProbe sets the path mtu the active node probed to the passive node
+func (s *Stream) SetPathMTUProbe(result PathMTUProbeResult) {
+	s.message.PathMTUProbe = result
+}
+
+// GetTraceID returns the id of the trace both nodes' spans are exported under - empty when the active node isn't
+// tracing
+func (s *Stream) GetTraceID() string {
+	return tracing.TraceID(tracing.WithTraceParent(context.Background(), s.message.TraceParent))
+}
+
+// GetFailoverDurationTableString returns the failover duration table string - hook and verification phases are
+// shown either side of the critical window when they were timed so it's clear when they dominate
 func (s *Stream) GetFailoverDurationTableString() string {
 	stageColumnRows := formatStageColumnRows(
 		[]string{
@@ -274,41 +450,166 @@ func (s *Stream) GetFailoverDurationTableString() string {
 			style.RenderActiveString(s.message.PassiveNodeInfo.Identities.Active.PubKey(), false),
 		},
 	)
+
+	// phases started before the passive node set its identity ran before the critical window - compared on the
+	// passive node's clock for everything but the active node's pre hooks, which finish before it sets its identity
+	var preFailoverRows, postFailoverRows [][]string
+	for _, timing := range s.message.PhaseTimings {
+		row := []string{s.phaseStageColumn(timing), style.FormatDuration(timing.Duration), " "}
+		if timing.StartTime.Before(s.message.PassiveNodeSetIdentityStartTime) {
+			preFailoverRows = append(preFailoverRows, row)
+		} else {
+			postFailoverRows = append(postFailoverRows, row)
+		}
+	}
+
+	rows := append(preFailoverRows,
+		[]string{
+			stageColumnRows[0],
+			style.FormatDuration(s.message.ActiveNodeSetIdentityEndTime.Sub(s.message.ActiveNodeSetIdentityStartTime)),
+			style.FormatInt(s.GetFailoverStartSlot()),
+		},
+		[]string{
+			stageColumnRows[1],
+			fmt.Sprintf("%s (%s)",
+				style.FormatDuration(s.message.PassiveNodeSyncTowerFileEndTime.Sub(s.message.ActiveNodeSyncTowerFileStartTime)),
+				humanize.Bytes(uint64(len(s.message.ActiveNodeInfo.TowerFileBytes))),
+			),
+			" ",
+		},
+		[]string{
+			stageColumnRows[2],
+			style.FormatDuration(s.message.PassiveNodeSetIdentityEndTime.Sub(s.message.PassiveNodeSetIdentityStartTime)),
+			style.FormatInt(s.GetFailoverEndSlot()),
+		},
+	)
+	rows = append(rows, postFailoverRows...)
+
+	// rows from here on are totals
+	totalsStartRow := len(rows)
+	rows = append(rows, []string{
+		style.RenderBoldMessage("Total"),
+		fmt.Sprintf("%s (wall clock)", style.RenderBoldMessage(style.FormatDuration(s.GetFailoverDuration()))),
+		style.RenderBoldMessage(fmt.Sprintf("%s slots", style.FormatInt(s.GetFailoverSlotsDuration()))),
+	})
+	if len(s.message.PhaseTimings) > 0 {
+		rows = append(rows, []string{
+			style.RenderBoldMessage("Total with hooks and verification"),
+			fmt.Sprintf("%s (wall clock)", style.RenderBoldMessage(style.FormatDuration(s.GetTotalDurationWithPhases()))),
+			" ",
+		})
+	}
+	identityGapRow := len(rows)
+	rows = append(rows, []string{
+		style.RenderBoldMessage("Identity gap (not voting)"),
+		style.FormatDuration(s.GetIdentityGap()),
+		" ",
+	})
+
 	return style.RenderTable(
 		[]string{"Stage", "Duration", "Slot"},
-		[][]string{
-			{
-				stageColumnRows[0],
-				s.message.ActiveNodeSetIdentityEndTime.Sub(s.message.ActiveNodeSetIdentityStartTime).String(),
-				humanize.Comma(int64(s.GetFailoverStartSlot())),
-			},
-			{
-				stageColumnRows[1],
-				fmt.Sprintf("%s (%s)",
-					s.message.PassiveNodeSyncTowerFileEndTime.Sub(s.message.ActiveNodeSyncTowerFileStartTime).String(),
-					humanize.Bytes(uint64(len(s.message.ActiveNodeInfo.TowerFileBytes))),
-				),
-				" ",
-			},
-			{
-				stageColumnRows[2],
-				s.message.PassiveNodeSetIdentityEndTime.Sub(s.message.PassiveNodeSetIdentityStartTime).String(),
-				humanize.Comma(int64(s.GetFailoverEndSlot())),
-			},
-			{
-				style.RenderBoldMessage("Total"),
-				fmt.Sprintf("%s (wall clock)", style.RenderBoldMessage(s.GetFailoverDuration().String())),
-				style.RenderBoldMessage(fmt.Sprintf("%s slots", humanize.Comma(int64(s.GetFailoverSlotsDuration())))),
-			},
-		},
+		rows,
 		func(row, col int) lipgloss.Style {
 			if row == table.HeaderRow {
 				return style.TableHeaderStyle
 			}
-			// total stage title
-			if row == 3 && col == 0 {
+			// totals stage titles
+			if row >= totalsStartRow && col == 0 {
 				return style.TableCellStyle.Align(lipgloss.Right)
 			}
+			if row == identityGapRow && col == 1 && s.IsIdentityGapAlarm() {
+				return style.TableCellStyle.Align(lipgloss.Left).Foreground(style.ColorWarning)
+			}
+			return style.TableCellStyle.Align(lipgloss.Left)
+		},
+	)
+}
+
+// phaseStageColumn returns the stage column for a phase timing - hostnames are coloured by the role their node is
+// taking like the critical window rows
+func (s *Stream) phaseStageColumn(timing PhaseTiming) string {
+	hostname := style.RenderGreyString(timing.Hostname, false)
+	switch timing.Hostname {
+	case s.message.PassiveNodeInfo.Hostname:
+		hostname = style.RenderActiveString(timing.Hostname, false)
+	case s.message.ActiveNodeInfo.Hostname:
+		hostname = style.RenderPassiveString(timing.Hostname, false)
+	}
+	return fmt.Sprintf("%s %s", hostname, style.RenderGreyString(phaseArrow(timing.Phase), false))
+}
+
+// phaseArrow returns a phase name as an arrow the width of the critical window's arrows e.g. ----pre-hooks--->
+func phaseArrow(phase string) string {
+	arrow := "--" + strings.ReplaceAll(phase, " ", "-")
+	if padding := len("--set-identity-->") - len(arrow) - 1; padding > 0 {
+		arrow += strings.Repeat("-", padding)
+	}
+	return arrow + ">"
+}
+
+// RecordPhaseTiming records a hook or verification phase run during the failover
+func (s *Stream) RecordPhaseTiming(timing PhaseTiming) {
+	s.message.PhaseTimings = append(s.message.PhaseTimings, timing)
+}
+
+// GetPhaseTimings returns the hook and verification phases run during the failover
+func (s *Stream) GetPhaseTimings() []PhaseTiming {
+	return s.message.PhaseTimings
+}
+
+// GetTotalDurationWithPhases returns the wall clock duration from the first timed phase or the start of the critical
+// window, whichever was earlier, to the end of the last - like GetFailoverDuration it spans both nodes' clocks
+func (s *Stream) GetTotalDurationWithPhases() time.Duration {
+	start := s.message.ActiveNodeSetIdentityStartTime
+	end := s.message.PassiveNodeSetIdentityEndTime
+	for _, timing := range s.message.PhaseTimings {
+		if timing.StartTime.Before(start) {
+			start = timing.StartTime
+		}
+		if timingEnd := timing.StartTime.Add(timing.Duration); timingEnd.After(end) {
+			end = timingEnd
+		}
+	}
+	return end.Sub(start)
+}
+
+// RecordRPCCall records a critical rpc call made during the failover
+func (s *Stream) RecordRPCCall(record RPCCallRecord) {
+	s.message.RPCCalls = append(s.message.RPCCalls, record)
+}
+
+// GetRPCCalls returns the critical rpc calls made during the failover
+func (s *Stream) GetRPCCalls() []RPCCallRecord {
+	return s.message.RPCCalls
+}
+
+// GetRPCCallsTableString returns a table of the critical rpc calls made during the failover so discrepancies
+// between rpc providers can be spotted when post-failover numbers look odd
+func (s *Stream) GetRPCCallsTableString() string {
+	rows := make([][]string, 0, len(s.message.RPCCalls))
+	for _, call := range s.message.RPCCalls {
+		result := "ok"
+		if call.Error != "" {
+			result = call.Error
+		}
+		rows = append(rows, []string{
+			call.Hostname,
+			call.Call,
+			call.RPCURL,
+			style.FormatDuration(call.Latency.Round(time.Millisecond)),
+			result,
+		})
+	}
+	return style.RenderTable(
+		[]string{"Node", "Call", "RPC", "Latency", "Result"},
+		rows,
+		func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return style.TableHeaderStyle
+			}
+			if col == 4 && rows[row][4] != "ok" {
+				return style.TableCellStyle.Align(lipgloss.Left).Foreground(style.ColorWarning)
+			}
 			return style.TableCellStyle.Align(lipgloss.Left)
 		},
 	)
@@ -351,7 +652,7 @@ func (s *Stream) SetPassiveNodeSyncTowerFileEndTime() {
 
 // PullActiveIdentityVoteCreditsSample pulls a sample of the vote credits for the active identity
 func (s *Stream) PullActiveIdentityVoteCreditsSample(solanaRPCClient solana.ClientInterface) (err error) {
-	identityPubkey := s.message.ActiveNodeInfo.Identities.Active.Key.PublicKey().String()
+	identityPubkey := s.message.ActiveNodeInfo.Identities.Active.PublicKey().String()
 
 	// fetch current state of vote account from its pubkey
 	voteAccount, creditRank, err := solanaRPCClient.GetCreditRankedVoteAccountFromPubkey(identityPubkey)
@@ -401,44 +702,63 @@ func (s *Stream) PullActiveIdentityVoteCreditsSamples(solanaRPCClient solana.Cli
 	}
 
 	// multiple samples may take some time so show a spinner to keep you patient
-	var sp *spinner.Spinner
+	sp := ui.NewSpinner().Title(fmt.Sprintf("Pulling %d vote credit samples %s apart...", nSamples, s.creditSamplesInterval()))
+	sp.ActionWithErr(func(ctx context.Context) error {
+		s.pullActiveIdentityVoteCreditsSamples(solanaRPCClient, nSamples, func(title string) {
+			sp.Title(title)
+		})
+		return nil
+	})
+	return sp.Run()
+}
+
+// creditSamplesInterval returns the configured interval between vote credit samples
+func (s *Stream) creditSamplesInterval() time.Duration {
 	interval := 5 * time.Second // default fallback
 	if s.message.MonitorConfig.CreditSamples.Interval != "" {
 		if parsedInterval, err := time.ParseDuration(s.message.MonitorConfig.CreditSamples.Interval); err == nil {
 			interval = parsedInterval
 		}
 	}
-	sp = spinner.New().Title(fmt.Sprintf("Pulling %d vote credit samples %s apart...", nSamples, interval))
+	return interval
+}
+
+// pullActiveIdentityVoteCreditsSamples pulls nSamples vote credit samples for the active identity the configured
+// interval apart - progress is reported to the given function
+func (s *Stream) pullActiveIdentityVoteCreditsSamples(solanaRPCClient solana.ClientInterface, nSamples int, progress func(title string)) {
+	interval := s.creditSamplesInterval()
+	pubkey := s.message.ActiveNodeInfo.Identities.Active.PubKey()
 
 	sampleCount := 0
-	sp.ActionWithErr(func(ctx context.Context) error {
-		for range make([]struct{}, nSamples) {
-			sampleCount++
-			sp.Title(fmt.Sprintf("Pulling vote credit sample %d of %d...", sampleCount, nSamples))
-			err := s.PullActiveIdentityVoteCreditsSample(solanaRPCClient)
-			if err != nil {
-				sp.Title(fmt.Sprintf("Failed to pull vote credits sample: %s", err))
-				continue
-			}
-			sample := s.message.CreditSamples[s.message.ActiveNodeInfo.Identities.Active.PubKey()][len(s.message.CreditSamples[s.message.ActiveNodeInfo.Identities.Active.PubKey()])-1]
-			if len(s.message.CreditSamples[s.message.ActiveNodeInfo.Identities.Active.PubKey()]) > 2 {
-				// check and warn if credits are not increasing between the last two samples
-				previousSample := s.message.CreditSamples[s.message.ActiveNodeInfo.Identities.Active.PubKey()][len(s.message.CreditSamples[s.message.ActiveNodeInfo.Identities.Active.PubKey()])-2]
-				if sample.Credits <= previousSample.Credits {
-					sp.Title(style.RenderWarningStringf(
-						"Vote credits are not increasing between samples %d and %d - this is not good",
-						sampleCount-1,
-						sampleCount,
-					))
-				}
+	for range make([]struct{}, nSamples) {
+		sampleCount++
+		progress(fmt.Sprintf("Pulling vote credit sample %d of %d...", sampleCount, nSamples))
+		err := s.PullActiveIdentityVoteCreditsSample(solanaRPCClient)
+		if err != nil {
+			progress(fmt.Sprintf("Failed to pull vote credits sample: %s", err))
+			continue
+		}
+		sample := s.message.CreditSamples[pubkey][len(s.message.CreditSamples[pubkey])-1]
+		if len(s.message.CreditSamples[pubkey]) > 2 {
+			// check and warn if credits are not increasing between the last two samples
+			previousSample := s.message.CreditSamples[pubkey][len(s.message.CreditSamples[pubkey])-2]
+			if sample.Credits <= previousSample.Credits {
+				progress(style.RenderWarningStringf(
+					"Vote credits are not increasing between samples %d and %d - this is not good",
+					sampleCount-1,
+					sampleCount,
+				))
 			}
-			time.Sleep(interval)
-			sp.Title(fmt.Sprintf("Pulled vote credit sample %d of %d - credits: %d, rank: %d...", sampleCount, nSamples, sample.Credits, sample.VoteRank))
 		}
-		log.Debug().Msgf("Pulled %d vote credit samples", sampleCount)
-		return nil
-	})
-	return sp.Run()
+		time.Sleep(interval)
+		progress(fmt.Sprintf("Pulled vote credit sample %d of %d - credits: %d, rank: %d...", sampleCount, nSamples, sample.Credits, sample.VoteRank))
+	}
+	log.Debug().Msgf("Pulled %d vote credit samples", sampleCount)
+}
+
+// GetActiveIdentityVoteCreditsSamples returns the vote credit samples pulled for the active identity
+func (s *Stream) GetActiveIdentityVoteCreditsSamples() []CreditsSample {
+	return s.message.CreditSamples[s.message.ActiveNodeInfo.Identities.Active.PubKey()]
 }
 
 // GetVoteCreditRankDifference returns the difference in vote credit rank between the first and last sample
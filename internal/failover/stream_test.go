@@ -0,0 +1,116 @@
+package failover
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPhaseArrow(t *testing.T) {
+	tests := []struct {
+		phase string
+		want  string
+	}{
+		{PhasePreHooks, "--pre-hooks----->"},
+		{PhaseCheckHooks, "--check-hooks--->"},
+		{PhaseGossipConfirm, "--gossip-confirm>"},
+		// longer than the critical window's arrows
+		{PhaseCreditMonitoring, "--credit-monitoring>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.phase, func(t *testing.T) {
+			assert.Equal(t, tt.want, phaseArrow(tt.phase))
+		})
+	}
+}
+
+func TestGetTotalDurationWithPhases(t *testing.T) {
+	tests := []struct {
+		name   string
+		timing []PhaseTiming
+		want   time.Duration
+	}{
+		{
+			name: "no phases timed",
+			want: 400 * time.Millisecond,
+		},
+		{
+			name:   "phase before the critical window",
+			timing: []PhaseTiming{{Phase: PhasePreHooks, StartTime: resultStartTime.Add(-time.Second), Duration: 500 * time.Millisecond}},
+			want:   1400 * time.Millisecond,
+		},
+		{
+			name:   "phase after the critical window",
+			timing: []PhaseTiming{{Phase: PhasePostHooks, StartTime: resultStartTime.Add(500 * time.Millisecond), Duration: time.Second}},
+			want:   1500 * time.Millisecond,
+		},
+		{
+			name:   "phase within the critical window",
+			timing: []PhaseTiming{{Phase: PhaseGossipConfirm, StartTime: resultStartTime.Add(100 * time.Millisecond), Duration: 100 * time.Millisecond}},
+			want:   400 * time.Millisecond,
+		},
+		{
+			name: "phases either side",
+			timing: []PhaseTiming{
+				{Phase: PhaseCheckHooks, StartTime: resultStartTime.Add(-2 * time.Second), Duration: time.Second},
+				{Phase: PhaseCreditMonitoring, StartTime: resultStartTime.Add(time.Second), Duration: time.Second},
+			},
+			want: 4 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newResultStream()
+			s.message.PhaseTimings = nil
+			for _, timing := range tt.timing {
+				s.RecordPhaseTiming(timing)
+			}
+			assert.Equal(t, tt.timing, s.GetPhaseTimings())
+			assert.Equal(t, tt.want, s.GetTotalDurationWithPhases())
+		})
+	}
+}
+
+func TestGetFailoverDurationTableString_PhaseTimings(t *testing.T) {
+	s := newResultStream()
+	s.RecordPhaseTiming(PhaseTiming{Hostname: "passive", Phase: PhasePostHooks, StartTime: resultStartTime.Add(500 * time.Millisecond), Duration: 2 * time.Second})
+
+	// phases sit either side of the critical window by when they started and are added to a second total
+	rows := tableRows(s.GetFailoverDurationTableString())
+	require.Len(t, rows, 8)
+	assert.Contains(t, rows[0], "active --pre-hooks----->")
+	assert.Contains(t, rows[0], "1s")
+	assert.Contains(t, rows[1], "--set-identity-->")
+	assert.Contains(t, rows[2], "---tower-file--->")
+	assert.Contains(t, rows[3], "--set-identity-->")
+	assert.Contains(t, rows[4], "passive --post-hooks---->")
+	assert.Contains(t, rows[4], "2s")
+	assert.Contains(t, rows[5], "400ms (wall clock)")
+	assert.Contains(t, rows[6], "Total with hooks and verification")
+	assert.Contains(t, rows[6], "3.5s (wall clock)")
+	assert.Contains(t, rows[7], "Identity gap (not voting)")
+
+	// without any the table is just the critical window
+	s.message.PhaseTimings = nil
+	rows = tableRows(s.GetFailoverDurationTableString())
+	require.Len(t, rows, 5)
+	assert.Contains(t, rows[0], "--set-identity-->")
+	assert.Contains(t, rows[3], "400ms (wall clock)")
+	assert.Contains(t, rows[4], "Identity gap (not voting)")
+	assert.NotContains(t, strings.Join(rows, "\n"), "Total with hooks and verification")
+}
+
+// tableRows returns the body rows of a rendered table
+func tableRows(table string) []string {
+	var rows []string
+	for _, line := range strings.Split(table, "\n") {
+		if strings.HasPrefix(line, "│") {
+			rows = append(rows, line)
+		}
+	}
+	// the first is the header
+	return rows[1:]
+}
@@ -0,0 +1,65 @@
+package failover
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// newFailoverID returns a short random id to tell failovers apart in logs, events and chat
+func newFailoverID() string {
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Sprintf("%08x", time.Now().UnixNano()&0xffffffff)
+	}
+	return hex.EncodeToString(id)
+}
+
+// SetFailoverID sets the failover id
+func (s *Stream) SetFailoverID(failoverID string) {
+	s.message.FailoverID = failoverID
+}
+
+// GetFailoverID returns the failover id
+func (s *Stream) GetFailoverID() string {
+	return s.message.FailoverID
+}
+
+// GetSummaryLine returns a single line summarising the failover for pasting into incident channels e.g.
+// failover 1a2b3c4d node-a→node-b real duration=8.4s gap=1.2s slots=21 rank 3 → 1 ok - the vote credit rank
+// change is only included once post-failover monitoring has sampled it. A failover that stopped before the active node
+// began switching identity is aborted, with nothing timed to report
+func (s *Stream) GetSummaryLine() string {
+	mode := "real"
+	if s.message.IsDryRunFailover {
+		mode = "drill"
+	}
+
+	parts := []string{
+		"failover",
+		s.message.FailoverID,
+		fmt.Sprintf("%s→%s", s.message.ActiveNodeInfo.Hostname, s.message.PassiveNodeInfo.Hostname),
+		mode,
+	}
+	if !s.message.IsSuccessfullyCompleted && s.message.ActiveNodeSetIdentityStartTime.IsZero() {
+		return strings.Join(append(parts, "aborted"), " ")
+	}
+
+	parts = append(parts,
+		fmt.Sprintf("duration=%s", s.GetFailoverDuration().Round(100*time.Millisecond)),
+		fmt.Sprintf("gap=%s", s.GetIdentityGap().Round(time.Millisecond)),
+		fmt.Sprintf("slots=%d", s.GetFailoverSlotsDuration()),
+	)
+	if _, first, last, err := s.GetVoteCreditRankDifference(); err == nil {
+		parts = append(parts, fmt.Sprintf("rank %d → %d", first, last))
+	}
+
+	if s.message.IsSuccessfullyCompleted {
+		parts = append(parts, "ok")
+	} else {
+		parts = append(parts, "failed")
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,66 @@
+package failover
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSummaryLine(t *testing.T) {
+	tests := []struct {
+		name   string
+		update func(m *Message)
+		want   string
+	}{
+		{
+			name:   "completed",
+			update: func(m *Message) {},
+			want:   "failover failover-1 active→passive real duration=400ms gap=300ms slots=4 ok",
+		},
+		{
+			name:   "drill",
+			update: func(m *Message) { m.IsDryRunFailover = true },
+			want:   "failover failover-1 active→passive drill duration=400ms gap=300ms slots=4 ok",
+		},
+		{
+			name: "with the vote credit rank change",
+			update: func(m *Message) {
+				pubkey := m.ActiveNodeInfo.Identities.Active.PubKey()
+				m.CreditSamples[pubkey] = append(m.CreditSamples[pubkey], CreditsSample{VoteRank: 8})
+			},
+			want: "failover failover-1 active→passive real duration=400ms gap=300ms slots=4 rank 10 → 8 ok",
+		},
+		{
+			name: "failed after the active node switched identity",
+			update: func(m *Message) {
+				m.IsSuccessfullyCompleted = false
+				m.FailoverEndSlot = 0
+				m.PassiveNodeSetIdentityStartTime = time.Time{}
+				m.PassiveNodeSetIdentityEndTime = time.Time{}
+			},
+			want: "failover failover-1 active→passive real duration=0s gap=0s slots=0 failed",
+		},
+		{
+			name: "aborted before the active node switched identity",
+			update: func(m *Message) {
+				*m = Message{FailoverID: m.FailoverID, ActiveNodeInfo: m.ActiveNodeInfo, PassiveNodeInfo: m.PassiveNodeInfo}
+			},
+			want: "failover failover-1 active→passive real aborted",
+		},
+		{
+			name: "aborted drill",
+			update: func(m *Message) {
+				*m = Message{FailoverID: m.FailoverID, IsDryRunFailover: true, ActiveNodeInfo: m.ActiveNodeInfo, PassiveNodeInfo: m.PassiveNodeInfo}
+			},
+			want: "failover failover-1 active→passive drill aborted",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newResultStream()
+			tt.update(&s.message)
+			assert.Equal(t, tt.want, s.GetSummaryLine())
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package failover
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// towerBackupTimeFormat is the UTC timestamp in a tower file backup's name - it sorts oldest first
+	towerBackupTimeFormat = "20060102T150405.000000000Z"
+
+	// towerBackupSuffix ends every tower file backup's name
+	towerBackupSuffix = ".bak"
+)
+
+// TowerBackupConfig is the configuration for backing up a tower file before it is overwritten or removed
+type TowerBackupConfig struct {
+	Enabled bool
+	// Dir is where backups are written - empty writes them next to the tower file
+	Dir string
+	// Retention is how many backups of the tower file are kept, oldest removed first - zero keeps every one
+	Retention int
+}
+
+// BackupTowerFile copies towerFile to a timestamped backup when it exists and backups are enabled, then removes
+// backups beyond the retention - the backup's path is returned, empty when there was nothing to back up
+func BackupTowerFile(towerFile string, config TowerBackupConfig, logger zerolog.Logger) (backupPath string, err error) {
+	if !config.Enabled {
+		return "", nil
+	}
+
+	info, err := os.Stat(towerFile)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to stat tower file %s: %w", towerFile, err)
+	}
+	// an empty tower file has nothing worth recovering
+	if info.Size() == 0 {
+		return "", nil
+	}
+
+	towerFileBytes, err := os.ReadFile(towerFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read tower file %s: %w", towerFile, err)
+	}
+
+	dir := config.Dir
+	if dir == "" {
+		dir = filepath.Dir(towerFile)
+	}
+	backupPath = filepath.Join(dir, fmt.Sprintf(
+		"%s.%s%s",
+		filepath.Base(towerFile),
+		time.Now().UTC().Format(towerBackupTimeFormat),
+		towerBackupSuffix,
+	))
+	if err := writeFileSynced(backupPath, towerFileBytes, info.Mode().Perm()); err != nil {
+		return "", fmt.Errorf("failed to back up tower file %s to %s: %w", towerFile, backupPath, err)
+	}
+	logger.Info().Str("tower_file", towerFile).Str("backup", backupPath).Msg("Backed up existing tower file")
+
+	pruneTowerBackups(towerFile, config, logger)
+	return backupPath, nil
+}
+
+// TowerBackups returns the paths of towerFile's backups, oldest first
+func TowerBackups(towerFile string, config TowerBackupConfig) ([]string, error) {
+	dir := config.Dir
+	if dir == "" {
+		dir = filepath.Dir(towerFile)
+	}
+	backups, err := filepath.Glob(filepath.Join(dir, filepath.Base(towerFile)+".*"+towerBackupSuffix))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// pruneTowerBackups removes the oldest backups of towerFile beyond the retention - failing to is only logged as the
+// backup itself was written
+func pruneTowerBackups(towerFile string, config TowerBackupConfig, logger zerolog.Logger) {
+	if config.Retention <= 0 {
+		return
+	}
+	backups, err := TowerBackups(towerFile, config)
+	if err != nil {
+		logger.Warn().Err(err).Msg("failed to list tower file backups")
+		return
+	}
+	if len(backups) <= config.Retention {
+		return
+	}
+
+	for _, backup := range backups[:len(backups)-config.Retention] {
+		if err := os.Remove(backup); err != nil {
+			logger.Warn().Err(err).Str("backup", backup).Msg("failed to remove old tower file backup")
+			continue
+		}
+		logger.Debug().Str("backup", backup).Msg("removed old tower file backup")
+	}
+}
+
+// writeFileSynced writes data to a new file at path and syncs it to disk
+func writeFileSynced(path string, data []byte, perm os.FileMode) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
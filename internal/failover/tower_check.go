@@ -0,0 +1,43 @@
+package failover
+
+import (
+	"github.com/sol-strategies/solana-validator-failover/internal/tower"
+)
+
+// towerFileMaxSlotsBehind is how far before the failover's start slot the received tower's last vote may be - about
+// an hour of slots, an active node that was voting has voted within a few of them
+const towerFileMaxSlotsBehind uint64 = 9000
+
+// checkTowerFile checks the received tower file is one the active identity saved and that its last vote is plausible
+// for the slot the failover started at - the tower of an active identity that was already delinquent may be any age
+func (s *Server) checkTowerFile() error {
+	if !s.towerSanityCheck {
+		return nil
+	}
+
+	receivedTower, err := tower.Parse(s.failoverStream.GetActiveNodeInfo().TowerFileBytes, s.passiveNodeInfo.Identities.Active.PublicKey())
+	if err != nil {
+		return err
+	}
+
+	// no start slot to compare with when the active node didn't record one
+	startSlot := s.failoverStream.GetFailoverStartSlot()
+	if startSlot == 0 {
+		return nil
+	}
+	maxSlotsBehind := towerFileMaxSlotsBehind
+	if s.failoverStream.GetActiveIdentityDelinquent() {
+		maxSlotsBehind = 0
+	}
+	if err := receivedTower.CheckSlots(startSlot, tower.DefaultMaxSlotsAhead, maxSlotsBehind); err != nil {
+		return err
+	}
+
+	lastVotedSlot, _ := receivedTower.LastVotedSlot()
+	s.logger.Debug().
+		Int("votes", len(receivedTower.Votes)).
+		Uint64("last_voted_slot", lastVotedSlot).
+		Uint64("failover_start_slot", startSlot).
+		Msg("tower file passed its sanity check")
+	return nil
+}
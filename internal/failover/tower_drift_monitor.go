@@ -0,0 +1,151 @@
+package failover
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/constants"
+	"github.com/sol-strategies/solana-validator-failover/internal/solana"
+	"github.com/sol-strategies/solana-validator-failover/internal/utils"
+)
+
+// TowerDriftMonitorParams are the parameters for creating a new tower drift monitor
+type TowerDriftMonitorParams struct {
+	Config          TowerDriftMonitorConfig
+	NodeInfo        *NodeInfo
+	SolanaRPCClient solana.ClientInterface
+}
+
+// TowerDriftMonitor watches the tower file of a passive node and alerts when it unexpectedly appears or
+// changes - a passive node should never write its tower file so drift suggests it may actually be voting. It runs
+// while the failover server waits and between the watch daemon's checks, and only reads the node info it is given
+type TowerDriftMonitor struct {
+	interval        time.Duration
+	autoClean       bool
+	nodeInfo        *NodeInfo
+	solanaRPCClient solana.ClientInterface
+	logger          zerolog.Logger
+	cancel          context.CancelFunc
+
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+// NewTowerDriftMonitor creates a new tower drift monitor
+func NewTowerDriftMonitor(params TowerDriftMonitorParams) *TowerDriftMonitor {
+	interval := 5 * time.Second // default fallback
+	if params.Config.Interval != "" {
+		if parsedInterval, err := time.ParseDuration(params.Config.Interval); err == nil && parsedInterval > 0 {
+			interval = parsedInterval
+		}
+	}
+
+	return &TowerDriftMonitor{
+		interval:        interval,
+		autoClean:       params.Config.AutoClean,
+		nodeInfo:        params.NodeInfo,
+		solanaRPCClient: params.SolanaRPCClient,
+		logger:          log.With().Str("component", "tower_drift_monitor").Logger(),
+	}
+}
+
+// Start records the current state of the tower file and checks it for drift every interval until stopped
+func (m *TowerDriftMonitor) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+
+	m.exists, m.size, m.modTime = m.stat()
+	m.logger.Debug().
+		Str("tower_file", m.nodeInfo.TowerFile).
+		Bool("exists", m.exists).
+		Dur("interval", m.interval).
+		Msg("monitoring tower file for drift")
+
+	go func() {
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.check()
+			}
+		}
+	}()
+}
+
+// Stop stops monitoring - call it before this node legitimately writes its tower file
+func (m *TowerDriftMonitor) Stop() {
+	if m.cancel != nil {
+		m.cancel()
+	}
+}
+
+// stat returns whether the tower file exists, its size and modification time
+func (m *TowerDriftMonitor) stat() (exists bool, size int64, modTime time.Time) {
+	info, err := os.Stat(m.nodeInfo.TowerFile)
+	if err != nil {
+		return false, 0, time.Time{}
+	}
+	return true, info.Size(), info.ModTime()
+}
+
+// check compares the tower file against the last recorded state and alerts on drift
+func (m *TowerDriftMonitor) check() {
+	exists, size, modTime := m.stat()
+	previouslyExisted := m.exists
+	changed := size != m.size || !modTime.Equal(m.modTime)
+	m.exists, m.size, m.modTime = exists, size, modTime
+
+	if !exists {
+		return
+	}
+
+	if !previouslyExisted {
+		m.logger.Warn().
+			Str("tower_file", m.nodeInfo.TowerFile).
+			Int64("size", size).
+			Time("mod_time", modTime).
+			Msg("tower file appeared while passive - this node may be voting with the active identity")
+	} else if changed {
+		m.logger.Warn().
+			Str("tower_file", m.nodeInfo.TowerFile).
+			Int64("size", size).
+			Time("mod_time", modTime).
+			Msg("tower file changed while passive - this node may be voting with the active identity")
+	} else {
+		return
+	}
+
+	if !m.autoClean {
+		return
+	}
+
+	// only clean up when gossip confirms this node is still passive - never delete a tower in use. The node info is
+	// shared with whatever started the monitor so its role is left alone
+	role, err := m.nodeInfo.gossipRole(m.solanaRPCClient)
+	if err != nil {
+		m.logger.Error().Err(err).Msg("not cleaning tower file - failed to confirm this node is passive in gossip")
+		return
+	}
+	if role != constants.NodeRolePassive {
+		m.logger.Error().
+			Str("role", role).
+			Msg("not cleaning tower file - gossip does not report this node as passive, investigate immediately")
+		return
+	}
+
+	if err := utils.RemoveFile(m.nodeInfo.TowerFile); err != nil {
+		m.logger.Error().Err(err).Msg("failed to clean tower file")
+		return
+	}
+	m.exists, m.size, m.modTime = false, 0, time.Time{}
+	m.logger.Warn().
+		Str("tower_file", m.nodeInfo.TowerFile).
+		Msg("cleaned tower file - gossip confirms this node is passive and tower.auto_empty_when_passive is true")
+}
@@ -0,0 +1,60 @@
+package failover
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/sol-strategies/solana-validator-failover/internal/constants"
+	solanapkg "github.com/sol-strategies/solana-validator-failover/internal/solana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDriftMonitor returns an auto cleaning monitor of a tower file in a temp dir for a node gossip shows as gossipKey
+func newDriftMonitor(t *testing.T, identities *NodeIdentities, gossipKey solana.PublicKey) *TowerDriftMonitor {
+	t.Helper()
+	return NewTowerDriftMonitor(TowerDriftMonitorParams{
+		Config: TowerDriftMonitorConfig{Enabled: true, AutoClean: true},
+		NodeInfo: &NodeInfo{
+			Hostname:   "passive",
+			PublicIP:   "10.0.0.3",
+			Identities: identities,
+			TowerFile:  filepath.Join(t.TempDir(), "tower.bin"),
+			Role:       constants.NodeRolePassive,
+		},
+		SolanaRPCClient: solanapkg.NewMockClient().WithNodeFromIP(func(ip string) (*solanapkg.Node, error) {
+			return solanapkg.NewMockNode(gossipKey, "2.0.0"), nil
+		}),
+	})
+}
+
+func TestTowerDriftMonitor_AutoCleansWhenPassive(t *testing.T) {
+	identities := &NodeIdentities{
+		Active:  &NodeIdentity{Pubkey: solana.NewWallet().PublicKey()},
+		Passive: &NodeIdentity{Pubkey: solana.NewWallet().PublicKey()},
+	}
+	monitor := newDriftMonitor(t, identities, identities.Passive.Pubkey)
+
+	require.NoError(t, os.WriteFile(monitor.nodeInfo.TowerFile, []byte("tower"), 0600))
+	monitor.check()
+
+	assert.NoFileExists(t, monitor.nodeInfo.TowerFile)
+	assert.Equal(t, constants.NodeRolePassive, monitor.nodeInfo.Role)
+}
+
+func TestTowerDriftMonitor_KeepsTowerFileWhenNotPassive(t *testing.T) {
+	identities := &NodeIdentities{
+		Active:  &NodeIdentity{Pubkey: solana.NewWallet().PublicKey()},
+		Passive: &NodeIdentity{Pubkey: solana.NewWallet().PublicKey()},
+	}
+	monitor := newDriftMonitor(t, identities, identities.Active.Pubkey)
+
+	require.NoError(t, os.WriteFile(monitor.nodeInfo.TowerFile, []byte("tower"), 0600))
+	monitor.check()
+
+	assert.FileExists(t, monitor.nodeInfo.TowerFile)
+	// the role the node info's owner set is left alone by the monitor's own gossip check
+	assert.Equal(t, constants.NodeRolePassive, monitor.nodeInfo.Role)
+}
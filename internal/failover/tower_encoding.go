@@ -0,0 +1,77 @@
+package failover
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"slices"
+)
+
+const (
+	// CapabilityTowerFileGzip says a node accepts TowerFileBytes gzip-compressed
+	CapabilityTowerFileGzip = "tower-file-gzip"
+
+	// TowerFileEncodingGzip is the TowerFileEncoding of gzip-compressed tower file bytes
+	TowerFileEncodingGzip = "gzip"
+
+	// maxTowerFileSize caps how large a compressed tower file may decompress to
+	maxTowerFileSize = 64 << 20
+)
+
+// capabilities returns the optional protocol features this node supports
+func capabilities() []string {
+	return []string{CapabilityTowerFileGzip, CapabilityWarmHold, CapabilityTowerSignature, CapabilityWarmHoldRelease}
+}
+
+// encodeTowerFile compresses TowerFileBytes for the wire when the peer's capabilities say it accepts them compressed
+// and compressing makes them smaller - they are left as they are otherwise
+func (n *NodeInfo) encodeTowerFile(peerCapabilities []string) error {
+	if n.TowerFileEncoding != "" || !slices.Contains(peerCapabilities, CapabilityTowerFileGzip) {
+		return nil
+	}
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(n.TowerFileBytes); err != nil {
+		return fmt.Errorf("failed to compress tower file: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to compress tower file: %w", err)
+	}
+	if compressed.Len() >= len(n.TowerFileBytes) {
+		return nil
+	}
+
+	n.TowerFileBytes = compressed.Bytes()
+	n.TowerFileEncoding = TowerFileEncodingGzip
+	return nil
+}
+
+// decodeTowerFile restores TowerFileBytes to the tower file as it is on disk when they arrived compressed
+func (n *NodeInfo) decodeTowerFile() error {
+	switch n.TowerFileEncoding {
+	case "":
+		return nil
+	case TowerFileEncodingGzip:
+	default:
+		return fmt.Errorf("unsupported tower file encoding %s", n.TowerFileEncoding)
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(n.TowerFileBytes))
+	if err != nil {
+		return fmt.Errorf("failed to decompress tower file: %w", err)
+	}
+	defer reader.Close()
+	towerFileBytes, err := io.ReadAll(io.LimitReader(reader, maxTowerFileSize+1))
+	if err != nil {
+		return fmt.Errorf("failed to decompress tower file: %w", err)
+	}
+	if len(towerFileBytes) > maxTowerFileSize {
+		return fmt.Errorf("tower file decompresses to more than %d bytes", maxTowerFileSize)
+	}
+
+	n.TowerFileBytes = towerFileBytes
+	n.TowerFileEncoding = ""
+	return nil
+}
@@ -0,0 +1,59 @@
+package failover
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTowerFileEncoding_RoundTrip(t *testing.T) {
+	towerFileBytes := bytes.Repeat([]byte("tower"), 1000)
+	n := &NodeInfo{TowerFileBytes: bytes.Clone(towerFileBytes)}
+
+	require.NoError(t, n.encodeTowerFile(capabilities()))
+	assert.Equal(t, TowerFileEncodingGzip, n.TowerFileEncoding)
+	assert.Less(t, len(n.TowerFileBytes), len(towerFileBytes))
+
+	require.NoError(t, n.decodeTowerFile())
+	assert.Empty(t, n.TowerFileEncoding)
+	assert.Equal(t, towerFileBytes, n.TowerFileBytes)
+}
+
+func TestTowerFileEncoding_LeftAsIs(t *testing.T) {
+	// a peer that doesn't accept compressed tower files
+	n := &NodeInfo{TowerFileBytes: bytes.Repeat([]byte("tower"), 1000)}
+	require.NoError(t, n.encodeTowerFile(nil))
+	assert.Empty(t, n.TowerFileEncoding)
+
+	// a tower file that compressing makes no smaller
+	n = &NodeInfo{TowerFileBytes: []byte("t")}
+	require.NoError(t, n.encodeTowerFile(capabilities()))
+	assert.Empty(t, n.TowerFileEncoding)
+	assert.Equal(t, []byte("t"), n.TowerFileBytes)
+	require.NoError(t, n.decodeTowerFile())
+	assert.Equal(t, []byte("t"), n.TowerFileBytes)
+}
+
+func TestTowerFileEncoding_DecompressesTooLarge(t *testing.T) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, err := writer.Write(make([]byte, maxTowerFileSize+1))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	n := &NodeInfo{TowerFileBytes: compressed.Bytes(), TowerFileEncoding: TowerFileEncodingGzip}
+	err = n.decodeTowerFile()
+	assert.ErrorContains(t, err, "decompresses to more than")
+	assert.Equal(t, TowerFileEncodingGzip, n.TowerFileEncoding)
+}
+
+func TestTowerFileEncoding_Invalid(t *testing.T) {
+	n := &NodeInfo{TowerFileBytes: []byte("tower"), TowerFileEncoding: "zstd"}
+	assert.ErrorContains(t, n.decodeTowerFile(), "unsupported tower file encoding zstd")
+
+	n = &NodeInfo{TowerFileBytes: []byte("not gzip"), TowerFileEncoding: TowerFileEncodingGzip}
+	assert.ErrorContains(t, n.decodeTowerFile(), "failed to decompress tower file")
+}
@@ -0,0 +1,316 @@
+package failover
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/quic-go/quic-go"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/peertrust"
+	"github.com/sol-strategies/solana-validator-failover/internal/tower"
+	"github.com/sol-strategies/solana-validator-failover/internal/utils"
+	pkgconstants "github.com/sol-strategies/solana-validator-failover/pkg/constants"
+)
+
+const (
+	// TowerReplicaSuffix ends the name of the replica of the tower file kept on a passive node
+	TowerReplicaSuffix = ".replica"
+
+	// towerReplicaTimeout is how long shipping a tower replica to a peer's agent may take
+	towerReplicaTimeout = 30 * time.Second
+
+	// towerReplicaMode is the mode replicas and tower files installed from them are written with
+	towerReplicaMode = 0o600
+)
+
+// TowerReplicaConfig is where the agent keeps the tower replica the active peer ships it - no path disables it
+type TowerReplicaConfig struct {
+	// Path is the replica's path, usually the tower file's with TowerReplicaSuffix
+	Path string
+	// ActiveIdentity is the identity a replica must be the signed tower of
+	ActiveIdentity solana.PublicKey
+}
+
+// IsEnabled returns true if the agent accepts tower replicas
+func (c TowerReplicaConfig) IsEnabled() bool {
+	return c.Path != ""
+}
+
+// TowerReplicaRequest is sent by the active node offering the agent a replica of its tower file - answered with a
+// FileTransferResponse before and after the replica's bytes as for a file transfer
+type TowerReplicaRequest struct {
+	Hostname                       string
+	PublicIP                       string
+	Size                           int64
+	SHA256                         string
+	SolanaValidatorFailoverVersion string
+	ProtocolRevision               int
+	MinProtocolRevision            int
+}
+
+// ShipTowerReplicaParams are the parameters for shipping a tower replica to a peer's agent
+type ShipTowerReplicaParams struct {
+	AgentName    string
+	AgentAddress string
+	Hostname     string
+	PublicIP     string
+	TowerFile    string
+	// PeerPins pins the agent's certificate fingerprint on first use and requires it after - nil disables pinning
+	PeerPins *peertrust.Store
+	// ClientCertificate is presented to the agent when it requires client certificates - nil presents none
+	ClientCertificate *tls.Certificate
+}
+
+// ShipTowerReplica sends the tower file to the agent on a peer, which keeps it as a replica to promote with should
+// this node die without handing over - the active node's identity is never switched
+func ShipTowerReplica(params ShipTowerReplicaParams) (err error) {
+	towerFileBytes, err := os.ReadFile(params.TowerFile)
+	if err != nil {
+		return fmt.Errorf("failed to read tower file %s: %w", params.TowerFile, err)
+	}
+	if len(towerFileBytes) == 0 {
+		return fmt.Errorf("tower file %s is empty", params.TowerFile)
+	}
+	hash := sha256.Sum256(towerFileBytes)
+	request := TowerReplicaRequest{
+		Hostname:                       params.Hostname,
+		PublicIP:                       params.PublicIP,
+		Size:                           int64(len(towerFileBytes)),
+		SHA256:                         hex.EncodeToString(hash[:]),
+		SolanaValidatorFailoverVersion: pkgconstants.AppVersion,
+		ProtocolRevision:               ProtocolRevision,
+		MinProtocolRevision:            MinProtocolRevision,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), towerReplicaTimeout)
+	defer cancel()
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: true,
+		NextProtos:         []string{AgentProtocolName},
+	}
+	if params.ClientCertificate != nil {
+		tlsConfig.Certificates = []tls.Certificate{*params.ClientCertificate}
+	}
+	var fingerprint string
+	if params.PeerPins != nil {
+		tlsConfig.VerifyPeerCertificate = params.PeerPins.VerifyPeerCertificate(params.AgentName, &fingerprint)
+	}
+
+	conn, err := quic.DialAddr(ctx, params.AgentAddress, tlsConfig, nil)
+	if err != nil {
+		return fmt.Errorf("failed to connect to agent on %s at %s: %w", params.AgentName, params.AgentAddress, err)
+	}
+	defer conn.CloseWithError(0, "done")
+
+	stream, err := conn.OpenStreamSync(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open stream to agent: %w", err)
+	}
+	defer stream.Close()
+	if err := stream.SetDeadline(time.Now().Add(towerReplicaTimeout)); err != nil {
+		return err
+	}
+
+	if _, err := stream.Write([]byte{MessageTypeTowerReplica}); err != nil {
+		return fmt.Errorf("failed to send tower replica request: %w", err)
+	}
+	encoder := gob.NewEncoder(stream)
+	decoder := gob.NewDecoder(stream)
+	if err := encoder.Encode(request); err != nil {
+		return fmt.Errorf("failed to send tower replica request: %w", err)
+	}
+
+	var response FileTransferResponse
+	if err := decoder.Decode(&response); err != nil {
+		return fmt.Errorf("failed to read tower replica response: %w", err)
+	}
+	if !response.Accepted {
+		return fmt.Errorf("agent on %s rejected tower replica: %s", params.AgentName, response.ErrorMessage)
+	}
+
+	if _, err := stream.Write(towerFileBytes); err != nil {
+		return fmt.Errorf("failed to send tower replica: %w", err)
+	}
+	// gob leaves fields it doesn't send untouched so the result needs its own value
+	var result FileTransferResponse
+	if err := decoder.Decode(&result); err != nil {
+		return fmt.Errorf("failed to read tower replica result: %w", err)
+	}
+	if !result.Accepted {
+		return fmt.Errorf("agent on %s failed to keep tower replica: %s", params.AgentName, result.ErrorMessage)
+	}
+
+	if params.PeerPins != nil {
+		if err := params.PeerPins.Pin(params.AgentName, fingerprint); err != nil {
+			log.Warn().Err(err).Msgf("failed to pin certificate for %s", params.AgentName)
+		}
+	}
+	return nil
+}
+
+// handleTowerReplica handles a tower replica request on a stream the message type has been read from
+func (a *Agent) handleTowerReplica(conn quic.Connection, stream quic.Stream) {
+	// closing the connection straight after the last response can drop it - the peer hangs up once it has read it
+	defer awaitPeerClose(conn)
+
+	decoder := gob.NewDecoder(stream)
+	encoder := gob.NewEncoder(stream)
+
+	var request TowerReplicaRequest
+	if err := decoder.Decode(&request); err != nil {
+		a.logger.Debug().Err(err).Msg("failed to decode tower replica request")
+		return
+	}
+
+	peerCertName := peertrust.ClientCertificatePeerName(conn.ConnectionState().TLS)
+	peer, err := a.acceptTowerReplica(utils.HostFromAddress(conn.RemoteAddr().String()), peerCertName, request)
+	if err != nil {
+		a.logger.Warn().Err(err).Str("requester", request.Hostname).Msg("rejected tower replica")
+		if encodeErr := encoder.Encode(FileTransferResponse{ErrorMessage: err.Error()}); encodeErr != nil {
+			a.logger.Debug().Err(encodeErr).Msg("failed to send tower replica response")
+		}
+		return
+	}
+	if err := encoder.Encode(FileTransferResponse{Accepted: true}); err != nil {
+		a.logger.Debug().Err(err).Msg("failed to send tower replica response")
+		return
+	}
+
+	response := FileTransferResponse{Accepted: true}
+	lastVotedSlot, err := a.receiveTowerReplica(stream, request)
+	if err != nil {
+		response = FileTransferResponse{ErrorMessage: err.Error()}
+		a.logger.Error().Err(err).Str("peer_name", peer.Name).Msg("tower replica rejected")
+	} else {
+		a.logger.Debug().
+			Str("peer_name", peer.Name).
+			Str("path", a.towerReplica.Path).
+			Uint64("last_voted_slot", lastVotedSlot).
+			Str("sha256", request.SHA256).
+			Msgf("Received tower replica from %s", peer.Name)
+	}
+	if err := encoder.Encode(response); err != nil {
+		a.logger.Debug().Err(err).Msg("failed to send tower replica response")
+	}
+}
+
+// acceptTowerReplica validates a tower replica request - the requester must be a configured peer and the replica no
+// larger than a tower file can be
+func (a *Agent) acceptTowerReplica(remoteIP, peerCertName string, request TowerReplicaRequest) (PeerInfo, error) {
+	if !a.towerReplica.IsEnabled() {
+		return PeerInfo{}, fmt.Errorf("tower replicas are disabled - set tower.replication.enabled to accept them")
+	}
+	if err := CheckProtocolRevision(request.SolanaValidatorFailoverVersion, request.ProtocolRevision, request.MinProtocolRevision); err != nil {
+		return PeerInfo{}, err
+	}
+
+	peer, ok := matchPeer(a.peers, remoteIP, request.PublicIP, false)
+	if peerCertName != "" {
+		peer, ok = a.peerByName(peerCertName)
+	}
+	if !ok {
+		return PeerInfo{}, fmt.Errorf("requester %s (%s) is not a configured peer", request.Hostname, remoteIP)
+	}
+
+	if request.Size <= 0 || request.Size > maxTowerFileSize {
+		return PeerInfo{}, fmt.Errorf("tower replica is %d bytes, not between 1 and %d", request.Size, maxTowerFileSize)
+	}
+	if _, err := hex.DecodeString(request.SHA256); err != nil || len(request.SHA256) != sha256.Size*2 {
+		return PeerInfo{}, fmt.Errorf("invalid sha256 %q", request.SHA256)
+	}
+
+	return peer, nil
+}
+
+// receiveTowerReplica reads the replica's bytes from r and moves it in place once its hash matches, it is a tower
+// signed by the active identity and it is no older than the replica already kept - a replica shipped by a node that
+// has since gone passive must not replace a newer one
+func (a *Agent) receiveTowerReplica(r io.Reader, request TowerReplicaRequest) (lastVotedSlot uint64, err error) {
+	towerFileBytes := make([]byte, request.Size)
+	if _, err := io.ReadFull(r, towerFileBytes); err != nil {
+		return 0, fmt.Errorf("failed to receive tower replica: %w", err)
+	}
+	hash := sha256.Sum256(towerFileBytes)
+	if got := hex.EncodeToString(hash[:]); got != request.SHA256 {
+		return 0, fmt.Errorf("sha256 mismatch - sent %s, received %s", request.SHA256, got)
+	}
+
+	replica, err := tower.Parse(towerFileBytes, a.towerReplica.ActiveIdentity)
+	if err != nil {
+		return 0, fmt.Errorf("tower replica failed its sanity check: %w", err)
+	}
+	lastVotedSlot, _ = replica.LastVotedSlot()
+
+	if keptBytes, err := os.ReadFile(a.towerReplica.Path); err == nil {
+		if kept, err := tower.Parse(keptBytes, a.towerReplica.ActiveIdentity); err == nil {
+			if keptSlot, ok := kept.LastVotedSlot(); ok && keptSlot > lastVotedSlot {
+				return 0, fmt.Errorf("tower replica's last voted slot %d is older than the kept replica's %d", lastVotedSlot, keptSlot)
+			}
+		}
+	}
+
+	return lastVotedSlot, receiveFile(bytes.NewReader(towerFileBytes), FileTransferRequest{
+		Path:   a.towerReplica.Path,
+		Size:   request.Size,
+		SHA256: request.SHA256,
+		Mode:   towerReplicaMode,
+	})
+}
+
+// InstallTowerReplica writes the tower replica at replicaPath to towerFile - for promoting a passive node whose
+// active peer died without handing over
+func InstallTowerReplica(replicaPath, towerFile string, activeIdentity solana.PublicKey, logger zerolog.Logger) error {
+	if _, err := os.Stat(replicaPath); os.IsNotExist(err) {
+		return fmt.Errorf("no tower replica at %s - the active peer's agent hasn't shipped one", replicaPath)
+	}
+	return InstallTowerFile(replicaPath, towerFile, activeIdentity, logger)
+}
+
+// InstallTowerFile writes the tower file at sourcePath, e.g. a replica or backup, to towerFile once it passes the
+// same sanity check as a tower file received in a failover
+func InstallTowerFile(sourcePath, towerFile string, activeIdentity solana.PublicKey, logger zerolog.Logger) error {
+	info, err := os.Stat(sourcePath)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", sourcePath, err)
+	}
+	towerFileBytes, err := os.ReadFile(sourcePath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", sourcePath, err)
+	}
+
+	source, err := tower.Parse(towerFileBytes, activeIdentity)
+	if err != nil {
+		return fmt.Errorf("%s failed its sanity check: %w", sourcePath, err)
+	}
+
+	hash := sha256.Sum256(towerFileBytes)
+	err = receiveFile(bytes.NewReader(towerFileBytes), FileTransferRequest{
+		Path:   towerFile,
+		Size:   int64(len(towerFileBytes)),
+		SHA256: hex.EncodeToString(hash[:]),
+		Mode:   towerReplicaMode,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to install %s as %s: %w", sourcePath, towerFile, err)
+	}
+
+	lastVotedSlot, _ := source.LastVotedSlot()
+	logger.Warn().
+		Str("source", sourcePath).
+		Str("tower_file", towerFile).
+		Uint64("last_voted_slot", lastVotedSlot).
+		Str("age", time.Since(info.ModTime()).Round(time.Second).String()).
+		Msgf("Installed %s as the tower file - votes the active node made after it was written are missing from it", sourcePath)
+	return nil
+}
@@ -0,0 +1,83 @@
+package failover
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/sol-strategies/solana-validator-failover/internal/identities"
+)
+
+const (
+	// CapabilityTowerSignature says a node signs the tower file it sends with the validator's active identity
+	CapabilityTowerSignature = "tower-signature"
+
+	// towerSignatureContext separates tower file signatures from authentication ones
+	towerSignatureContext = ProtocolName + "/tower"
+
+	// towerSignerProbeContext separates what a signer is probed with from anything a peer verifies
+	towerSignerProbeContext = ProtocolName + "/tower/probe"
+)
+
+// ErrTowerSignature is returned when the tower file's signature doesn't verify against the active identity
+var ErrTowerSignature = errors.New("tower file signature doesn't verify against the active identity")
+
+// signTowerFile signs the tower file bytes as they are on disk, with the failover and both nodes they are sent in,
+// with the validator's active identity into ActiveNodeInfo.TowerFileSignature
+func (s *Stream) signTowerFile(activeIdentity *identities.Identity) error {
+	signature, err := activeIdentity.SignChallenge(towerSignatureContext, s.towerSignaturePayload())
+	if err != nil {
+		return fmt.Errorf("failed to sign tower file: %w", err)
+	}
+	s.GetActiveNodeInfo().TowerFileSignature = signature
+	return nil
+}
+
+// probeTowerSigner checks a remote signer holding the active identity answers before this node demotes itself - one
+// that is down or slow is found while the failover can still stop with this node voting
+func probeTowerSigner(activeIdentity *identities.Identity) error {
+	if !activeIdentity.IsRemote() {
+		return nil
+	}
+	nonce, err := newAuthNonce()
+	if err != nil {
+		return err
+	}
+	if _, err := activeIdentity.SignChallenge(towerSignerProbeContext, nonce); err != nil {
+		return fmt.Errorf("remote signer failed to sign with the active identity: %w", err)
+	}
+	return nil
+}
+
+// verifyTowerFileSignature checks ActiveNodeInfo.TowerFileSignature is activeIdentity's signature of the received
+// tower file bytes - an unsigned tower file is only accepted from a peer that doesn't advertise signing them or that
+// says why it couldn't, in which case signed is false and only its hash vouches for it
+func (s *Stream) verifyTowerFileSignature(activeIdentity *identities.Identity) (signed bool, err error) {
+	activeNodeInfo := s.GetActiveNodeInfo()
+	if len(activeNodeInfo.TowerFileSignature) == 0 {
+		if slices.Contains(activeNodeInfo.Capabilities, CapabilityTowerSignature) && activeNodeInfo.TowerFileUnsignedReason == "" {
+			return false, fmt.Errorf("%w: %s advertises signing tower files but sent none", ErrTowerSignature, activeNodeInfo.Hostname)
+		}
+		return false, nil
+	}
+	if !activeIdentity.VerifyChallenge(towerSignatureContext, s.towerSignaturePayload(), activeNodeInfo.TowerFileSignature) {
+		return false, fmt.Errorf("%w %s", ErrTowerSignature, activeIdentity.PubKey())
+	}
+	return true, nil
+}
+
+// towerSignaturePayload returns what the tower file signature covers - the failover, both nodes, the start slot and
+// the sha256 of the tower file bytes as they are on disk, so a signature can't be replayed for another failover
+func (s *Stream) towerSignaturePayload() []byte {
+	activeNodeInfo := s.GetActiveNodeInfo()
+	return fmt.Appendf(nil,
+		"failover_id=%s\nactive_hostname=%s\npassive_hostname=%s\nfailover_start_slot=%d\ntower_file_hash=%s\ntower_file_sha256=%x\n",
+		s.GetFailoverID(),
+		activeNodeInfo.Hostname,
+		s.GetPassiveNodeInfo().Hostname,
+		s.GetFailoverStartSlot(),
+		activeNodeInfo.TowerFileHash,
+		sha256.Sum256(activeNodeInfo.TowerFileBytes),
+	)
+}
@@ -0,0 +1,125 @@
+package failover
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/sol-strategies/solana-validator-failover/internal/identities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingSigner is a remote signer that is down
+type failingSigner struct {
+	pubkey solana.PublicKey
+}
+
+func (s *failingSigner) PublicKey() solana.PublicKey {
+	return s.pubkey
+}
+
+func (s *failingSigner) Sign(message []byte) (solana.Signature, error) {
+	return solana.Signature{}, errors.New("remote signer unreachable")
+}
+
+// newTestIdentity returns an identity with a random local key
+func newTestIdentity(t *testing.T) *identities.Identity {
+	t.Helper()
+	key, err := solana.NewRandomPrivateKey()
+	require.NoError(t, err)
+	return &identities.Identity{Key: key}
+}
+
+// newTowerStream returns a stream carrying a tower file for failover id from active to passive
+func newTowerStream(failoverID string) *Stream {
+	s := &Stream{message: Message{CreditSamples: make(CreditSamples)}}
+	s.SetFailoverID(failoverID)
+	s.SetFailoverStartSlot(1000)
+	s.GetActiveNodeInfo().Hostname = "active"
+	s.GetActiveNodeInfo().TowerFileBytes = []byte("tower")
+	s.GetActiveNodeInfo().TowerFileHash = "hash"
+	s.GetActiveNodeInfo().Capabilities = []string{CapabilityTowerSignature}
+	s.GetPassiveNodeInfo().Hostname = "passive"
+	return s
+}
+
+func TestTowerFileSignature(t *testing.T) {
+	identity := newTestIdentity(t)
+	s := newTowerStream("failover-1")
+	require.NoError(t, s.signTowerFile(identity))
+
+	signed, err := s.verifyTowerFileSignature(identity)
+	require.NoError(t, err)
+	assert.True(t, signed)
+
+	// signed by another identity
+	_, err = s.verifyTowerFileSignature(newTestIdentity(t))
+	assert.ErrorIs(t, err, ErrTowerSignature)
+}
+
+func TestTowerFileSignature_TamperedPayload(t *testing.T) {
+	identity := newTestIdentity(t)
+
+	for name, tamper := range map[string]func(s *Stream){
+		"tower file bytes": func(s *Stream) { s.GetActiveNodeInfo().TowerFileBytes = []byte("t0wer") },
+		"tower file hash":  func(s *Stream) { s.GetActiveNodeInfo().TowerFileHash = "other" },
+		"start slot":       func(s *Stream) { s.SetFailoverStartSlot(1001) },
+		"passive hostname": func(s *Stream) { s.GetPassiveNodeInfo().Hostname = "other" },
+		"signature":        func(s *Stream) { s.GetActiveNodeInfo().TowerFileSignature[0] ^= 0xff },
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := newTowerStream("failover-1")
+			require.NoError(t, s.signTowerFile(identity))
+			tamper(s)
+
+			_, err := s.verifyTowerFileSignature(identity)
+			assert.ErrorIs(t, err, ErrTowerSignature)
+		})
+	}
+}
+
+func TestTowerFileSignature_ReplayedInAnotherFailover(t *testing.T) {
+	identity := newTestIdentity(t)
+	signedStream := newTowerStream("failover-1")
+	require.NoError(t, signedStream.signTowerFile(identity))
+
+	replayed := newTowerStream("failover-2")
+	replayed.GetActiveNodeInfo().TowerFileSignature = signedStream.GetActiveNodeInfo().TowerFileSignature
+
+	_, err := replayed.verifyTowerFileSignature(identity)
+	assert.ErrorIs(t, err, ErrTowerSignature)
+}
+
+func TestTowerFileSignature_Unsigned(t *testing.T) {
+	identity := newTestIdentity(t)
+
+	// advertising signatures but sending none
+	s := newTowerStream("failover-1")
+	_, err := s.verifyTowerFileSignature(identity)
+	assert.ErrorIs(t, err, ErrTowerSignature)
+
+	// unless the active node says why it couldn't sign
+	s.GetActiveNodeInfo().TowerFileUnsignedReason = "remote signer unreachable"
+	signed, err := s.verifyTowerFileSignature(identity)
+	require.NoError(t, err)
+	assert.False(t, signed)
+
+	// a peer that doesn't sign tower files at all
+	s = newTowerStream("failover-1")
+	s.GetActiveNodeInfo().Capabilities = nil
+	signed, err = s.verifyTowerFileSignature(identity)
+	require.NoError(t, err)
+	assert.False(t, signed)
+}
+
+func TestProbeTowerSigner(t *testing.T) {
+	assert.NoError(t, probeTowerSigner(newTestIdentity(t)))
+
+	key, err := solana.NewRandomPrivateKey()
+	require.NoError(t, err)
+	assert.NoError(t, probeTowerSigner(&identities.Identity{Signer: &testSigner{key: key}}))
+
+	err = probeTowerSigner(&identities.Identity{Signer: &failingSigner{pubkey: key.PublicKey()}})
+	assert.ErrorContains(t, err, "remote signer unreachable")
+}
@@ -0,0 +1,368 @@
+package failover
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DefaultTowerFileResumeTimeout is how long the active node keeps reconnecting, and the passive node waits for it
+	// to, when the connection is lost while the tower file is sent
+	DefaultTowerFileResumeTimeout = 30 * time.Second
+
+	// towerFileChunkSize is how many tower file bytes are sent before waiting for the passive node to acknowledge them
+	towerFileChunkSize = 64 << 10
+
+	// towerFileResumeRetryInterval is how long the active node waits between attempts to reconnect
+	towerFileResumeRetryInterval = time.Second
+)
+
+// TowerFileChunk is part of the tower file as it is sent on the wire, Offset bytes in
+type TowerFileChunk struct {
+	Offset int64
+	Bytes  []byte
+}
+
+// TowerFileChunkAck acknowledges the tower file bytes the passive node has received so far
+type TowerFileChunkAck struct {
+	Received int64
+}
+
+// TowerFileResumeRequest asks the passive node to resume receiving the tower file after the connection was lost
+// while it was sent - sent after authenticating on a stream opened with MessageTypeTowerFileResume
+type TowerFileResumeRequest struct {
+	Hostname string
+	// Message is the active node's failover message without the tower file bytes - the passive node may not have
+	// received it before the connection was lost
+	Message Message
+}
+
+// TowerFileResumeResponse tells the active node how many bytes of the tower file the passive node already has - the
+// rest are sent from there. Error is set when the transfer can't be resumed
+type TowerFileResumeResponse struct {
+	Received int64
+	Error    string
+}
+
+// towerFileResume is an authenticated resume request handed from its stream's handler to the failover
+type towerFileResume struct {
+	conn    quic.Connection
+	stream  *Stream
+	request TowerFileResumeRequest
+}
+
+// sendTowerFile sends the message without the tower file bytes then the tower file in chunks from the start
+func (s *Stream) sendTowerFile(towerFileBytes []byte) error {
+	activeNodeInfo := s.GetActiveNodeInfo()
+	activeNodeInfo.TowerFileBytes = nil
+	activeNodeInfo.TowerFileWireSize = int64(len(towerFileBytes))
+	if err := s.Encode(); err != nil {
+		return err
+	}
+	return s.sendTowerFileChunks(towerFileBytes, 0)
+}
+
+// sendTowerFileChunks sends the tower file from offset, waiting for each chunk to be acknowledged before the next
+func (s *Stream) sendTowerFileChunks(towerFileBytes []byte, offset int64) error {
+	size := int64(len(towerFileBytes))
+	if offset > size {
+		return fmt.Errorf("passive node has %d bytes of a %d byte tower file", offset, size)
+	}
+	for offset < size {
+		end := min(offset+towerFileChunkSize, size)
+		if err := s.encoder.Encode(TowerFileChunk{Offset: offset, Bytes: towerFileBytes[offset:end]}); err != nil {
+			return fmt.Errorf("failed to send tower file chunk at offset %d: %w", offset, err)
+		}
+		var ack TowerFileChunkAck
+		if err := s.decoder.Decode(&ack); err != nil {
+			return fmt.Errorf("failed to read tower file chunk acknowledgement: %w", err)
+		}
+		if ack.Received != end {
+			return fmt.Errorf("passive node acknowledged %d tower file bytes, expected %d", ack.Received, end)
+		}
+		offset = end
+	}
+	return nil
+}
+
+// receiveTowerFile receives the message then the tower file chunks into ActiveNodeInfo.TowerFileBytes - only what the
+// active node sets with the tower file is taken from the message
+func (s *Stream) receiveTowerFile() error {
+	if err := s.receiveActiveSide(); err != nil {
+		return err
+	}
+	return s.receiveTowerFileChunks()
+}
+
+// receiveActiveSide receives the message the active node sends once it has switched identity, ahead of the tower file
+// chunks
+func (s *Stream) receiveActiveSide() error {
+	var sent Message
+	if err := s.decoder.Decode(&sent); err != nil {
+		log.Err(err).Msg("failed to decode failover message")
+		return err
+	}
+	s.message.takeActiveSide(sent)
+	return nil
+}
+
+// receiveTowerFileChunks appends tower file chunks to ActiveNodeInfo.TowerFileBytes, acknowledging each, until all
+// TowerFileWireSize bytes have arrived
+func (s *Stream) receiveTowerFileChunks() error {
+	activeNodeInfo := s.GetActiveNodeInfo()
+	if activeNodeInfo.TowerFileWireSize > maxTowerFileSize {
+		return fmt.Errorf("tower file of %d bytes is larger than %d bytes", activeNodeInfo.TowerFileWireSize, maxTowerFileSize)
+	}
+	for received := int64(len(activeNodeInfo.TowerFileBytes)); received < activeNodeInfo.TowerFileWireSize; {
+		var chunk TowerFileChunk
+		if err := s.decoder.Decode(&chunk); err != nil {
+			return fmt.Errorf("failed to receive tower file chunk: %w", err)
+		}
+		if chunk.Offset != received {
+			return fmt.Errorf("tower file chunk at offset %d doesn't follow the %d bytes received", chunk.Offset, received)
+		}
+		if received+int64(len(chunk.Bytes)) > activeNodeInfo.TowerFileWireSize {
+			return fmt.Errorf("tower file chunk at offset %d runs past the %d byte tower file", chunk.Offset, activeNodeInfo.TowerFileWireSize)
+		}
+		activeNodeInfo.TowerFileBytes = append(activeNodeInfo.TowerFileBytes, chunk.Bytes...)
+		received = int64(len(activeNodeInfo.TowerFileBytes))
+		if err := s.encoder.Encode(TowerFileChunkAck{Received: received}); err != nil {
+			return fmt.Errorf("failed to acknowledge tower file chunk: %w", err)
+		}
+	}
+	return nil
+}
+
+// continueOn has the failover carry on over another stream once the tower file transfer resumed on it
+func (s *Stream) continueOn(stream *Stream) {
+	s.Stream = stream.Stream
+	s.encoder = stream.encoder
+	s.decoder = stream.decoder
+}
+
+// sendTowerFile sends the tower file once this node has gone passive - when the connection is lost part way it
+// reconnects and resumes the transfer rather than leave no node voting
+func (c *Client) sendTowerFile(towerFileBytes []byte) error {
+	err := c.failoverStream.sendTowerFile(towerFileBytes)
+	if err == nil {
+		return nil
+	}
+	if _, ok := peerAbortReason(err); ok {
+		return err
+	}
+
+	c.logger.Warn().Err(err).Msgf("lost connection to %s while sending the tower file - reconnecting to resume for up to %s", c.serverName, DefaultTowerFileResumeTimeout)
+	_ = c.Conn.CloseWithError(0, "connection lost")
+	deadline := time.Now().Add(DefaultTowerFileResumeTimeout)
+	for attempt := 1; ; attempt++ {
+		received, resumeErr := c.resumeTowerFile(towerFileBytes, deadline)
+		if resumeErr == nil {
+			c.logger.Info().Msgf("👉 Resumed sending the tower file to %s from byte %d of %d", c.serverName, received, len(towerFileBytes))
+			return nil
+		}
+		if time.Now().Add(towerFileResumeRetryInterval).After(deadline) {
+			return fmt.Errorf("failed to resume sending the tower file within %s: %w", DefaultTowerFileResumeTimeout, resumeErr)
+		}
+		c.logger.Debug().Err(resumeErr).Msgf("(attempt %d) failed to resume sending the tower file to %s", attempt, c.serverName)
+		time.Sleep(towerFileResumeRetryInterval)
+	}
+}
+
+// resumeTowerFile reconnects to the passive node and sends the tower file from the bytes it already has - the
+// failover carries on over the new connection once it has all of them
+func (c *Client) resumeTowerFile(towerFileBytes []byte, deadline time.Time) (received int64, err error) {
+	conn, stream, err := dialAuthenticated(c.serverName, c.serverAddress, c.identities.Active, c.clientCertificate, c.quic, MessageTypeTowerFileResume)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			_ = conn.CloseWithError(0, "tower file resume failed")
+		}
+	}()
+	if err := stream.Stream.SetDeadline(deadline); err != nil {
+		return 0, err
+	}
+
+	request := TowerFileResumeRequest{
+		Hostname: c.activeNodeInfo.Hostname,
+		Message:  c.failoverStream.message,
+	}
+	if err := stream.encoder.Encode(request); err != nil {
+		return 0, fmt.Errorf("failed to send tower file resume request: %w", err)
+	}
+	var response TowerFileResumeResponse
+	if err := stream.decoder.Decode(&response); err != nil {
+		return 0, fmt.Errorf("failed to read tower file resume response: %w", err)
+	}
+	if response.Error != "" {
+		return 0, fmt.Errorf("%s refused to resume the tower file transfer: %s", c.serverName, response.Error)
+	}
+	if err := stream.sendTowerFileChunks(towerFileBytes, response.Received); err != nil {
+		return 0, err
+	}
+
+	// the passive node sets its identity before answering - don't hold it to the resume deadline
+	if err := stream.Stream.SetDeadline(time.Time{}); err != nil {
+		return 0, err
+	}
+	c.Conn = conn
+	c.failoverStream.continueOn(stream)
+	return response.Received, nil
+}
+
+// receiveTowerFile receives the tower file the active node sends once it has gone passive - when the connection is
+// lost part way it waits for the active node to reconnect and resume the transfer rather than abort
+func (s *Server) receiveTowerFile() error {
+	err := s.failoverStream.receiveActiveSide()
+	if err == nil {
+		if err = s.commitSwitchedActiveNode(); err != nil {
+			return err
+		}
+		err = s.failoverStream.receiveTowerFileChunks()
+	}
+	if err == nil || s.stopIfAborted(err) {
+		return err
+	}
+
+	activeNodeHostname := s.failoverStream.GetActiveNodeInfo().Hostname
+	s.logger.Warn().Err(err).Msgf("lost connection to %s while receiving the tower file - waiting up to %s for it to reconnect and resume", activeNodeHostname, DefaultTowerFileResumeTimeout)
+	s.observe(ObserverUpdateTypeProgress, fmt.Sprintf("lost connection to %s while receiving the tower file - waiting for it to resume", activeNodeHostname))
+
+	timer := time.NewTimer(DefaultTowerFileResumeTimeout)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			return fmt.Errorf("%s didn't resume sending the tower file within %s: %w", activeNodeHostname, DefaultTowerFileResumeTimeout, err)
+		case <-s.ctx.Done():
+			return fmt.Errorf("server stopped while waiting for %s to resume sending the tower file: %w", activeNodeHostname, err)
+		case resume := <-s.towerFileResumes:
+			received, resumeErr := s.resumeTowerFile(resume)
+			if resumeErr != nil {
+				s.logger.Warn().Err(resumeErr).Msgf("failed to resume receiving the tower file from %s - waiting for it to try again", activeNodeHostname)
+				continue
+			}
+			s.logger.Info().Msgf("👉 %s resumed sending the tower file from byte %d", activeNodeHostname, received)
+			s.observe(ObserverUpdateTypeProgress, fmt.Sprintf("%s resumed sending the tower file", activeNodeHostname))
+			return nil
+		}
+	}
+}
+
+// resumeTowerFile tells the active node how much of the tower file this node already has and receives the rest over
+// the resumed connection, which the failover carries on over - the bytes are kept only when the active node resends
+// the tower file with the same hash
+func (s *Server) resumeTowerFile(resume towerFileResume) (received int64, err error) {
+	var response TowerFileResumeResponse
+	requestMessage := resume.request.Message
+	if requestMessage.FailoverID != s.failoverStream.GetFailoverID() {
+		response.Error = fmt.Sprintf("failover %s is not the failover this node is in", requestMessage.FailoverID)
+		if err := resume.stream.encoder.Encode(response); err != nil {
+			s.logger.Debug().Err(err).Msg("failed to send tower file resume response")
+		}
+		return 0, fmt.Errorf("%s asked to resume failover %s", resume.request.Hostname, requestMessage.FailoverID)
+	}
+
+	// the request carries everything the active node sent - this node may not have received it, but only what the
+	// active node sets is taken from it so nothing this node decided can be changed by a resume
+	towerFileBytes := s.failoverStream.GetActiveNodeInfo().TowerFileBytes
+	if s.failoverStream.GetActiveNodeInfo().TowerFileHash != requestMessage.ActiveNodeInfo.TowerFileHash {
+		towerFileBytes = nil
+	}
+	s.failoverStream.message.takeActiveSide(requestMessage)
+	s.failoverStream.GetActiveNodeInfo().TowerFileBytes = towerFileBytes
+	received = int64(len(towerFileBytes))
+	if err := s.commitSwitchedActiveNode(); err != nil {
+		response.Error = err.Error()
+		if encodeErr := resume.stream.encoder.Encode(response); encodeErr != nil {
+			s.logger.Debug().Err(encodeErr).Msg("failed to send tower file resume response")
+		}
+		return 0, err
+	}
+
+	response.Received = received
+	if err := resume.stream.encoder.Encode(response); err != nil {
+		return 0, fmt.Errorf("failed to send tower file resume response: %w", err)
+	}
+	s.activeConn = resume.conn
+	s.failoverStream.continueOn(resume.stream)
+	if err := s.failoverStream.receiveTowerFileChunks(); err != nil {
+		return 0, err
+	}
+	return received, nil
+}
+
+// commitSwitchedActiveNode stops the failover being aborted once the active node says it has switched identity - an
+// abort from here on would leave neither node voting. It returns the abort's error when the failover already was
+func (s *Server) commitSwitchedActiveNode() error {
+	if err := s.pendingAbort.commit(); err != nil {
+		s.logger.Error().Err(err).Msgf("failover aborted just as %s switched identity - check which node is voting", s.failoverStream.GetActiveNodeInfo().Hostname)
+		return err
+	}
+	return nil
+}
+
+// takeActiveSide takes what the active node sets with the tower file from the message it sent - its node info, the
+// failover start slot, its timings and the rpc calls and phases it recorded. The passive node's own fields are kept
+func (m *Message) takeActiveSide(sent Message) {
+	activeHostname := sent.ActiveNodeInfo.Hostname
+	m.ActiveNodeInfo = sent.ActiveNodeInfo
+	m.FailoverStartSlot = sent.FailoverStartSlot
+	m.ActiveNodeSetIdentityStartTime = sent.ActiveNodeSetIdentityStartTime
+	m.ActiveNodeSetIdentityEndTime = sent.ActiveNodeSetIdentityEndTime
+	m.ActiveNodeSyncTowerFileStartTime = sent.ActiveNodeSyncTowerFileStartTime
+	m.ActiveNodeSyncTowerFileEndTime = sent.ActiveNodeSyncTowerFileEndTime
+	m.RPCCalls = mergeNodeRecords(m.RPCCalls, sent.RPCCalls, activeHostname,
+		func(r RPCCallRecord) (string, time.Time) { return r.Hostname, r.StartTime })
+	m.PhaseTimings = mergeNodeRecords(m.PhaseTimings, sent.PhaseTimings, activeHostname,
+		func(t PhaseTiming) (string, time.Time) { return t.Hostname, t.StartTime })
+}
+
+// mergeNodeRecords returns the records in local not made by hostname with those in sent made by it, in the order
+// they started
+func mergeNodeRecords[T any](local, sent []T, hostname string, key func(T) (string, time.Time)) []T {
+	merged := slices.DeleteFunc(slices.Clone(local), func(record T) bool {
+		recordHostname, _ := key(record)
+		return recordHostname == hostname
+	})
+	for _, record := range sent {
+		if recordHostname, _ := key(record); recordHostname == hostname {
+			merged = append(merged, record)
+		}
+	}
+	slices.SortStableFunc(merged, func(a, b T) int {
+		_, aStart := key(a)
+		_, bStart := key(b)
+		return aStart.Compare(bStart)
+	})
+	return merged
+}
+
+// handleTowerFileResumeStream has the active node prove it holds the active identity keypair then hands its resume
+// request to the failover waiting for it - the stream is kept open until the server stops as the failover carries on
+// over it
+func (s *Server) handleTowerFileResumeStream(conn quic.Connection, stream quic.Stream) {
+	resumeStream := NewFailoverStream(stream)
+	if err := resumeStream.AuthenticateAsPassive(s.identities.Active); err != nil {
+		s.logger.Error().Err(err).Str("remote_addr", conn.RemoteAddr().String()).Msg("🔒 tower file resume requester authentication failed - ignoring it")
+		return
+	}
+
+	var request TowerFileResumeRequest
+	if err := resumeStream.decoder.Decode(&request); err != nil {
+		s.logger.Error().Err(err).Msg("failed to read tower file resume request")
+		return
+	}
+
+	select {
+	case s.towerFileResumes <- towerFileResume{conn: conn, stream: resumeStream, request: request}:
+	case <-s.ctx.Done():
+		return
+	}
+	<-s.ctx.Done()
+}
@@ -0,0 +1,138 @@
+package failover
+
+import (
+	"bytes"
+	"encoding/gob"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPipeStreams returns two ends of an in-memory connection as streams
+func newPipeStreams(t *testing.T) (*Stream, *Stream, net.Conn) {
+	t.Helper()
+	a, b := net.Pipe()
+	t.Cleanup(func() {
+		a.Close()
+		b.Close()
+	})
+	return newConnStream(a), newConnStream(b), a
+}
+
+// newConnStream returns a stream encoding to and decoding from conn
+func newConnStream(conn net.Conn) *Stream {
+	return &Stream{
+		encoder: gob.NewEncoder(conn),
+		decoder: gob.NewDecoder(conn),
+		message: Message{CreditSamples: make(CreditSamples)},
+	}
+}
+
+// droppingConn closes the connection once more than limit bytes have been written to it
+type droppingConn struct {
+	net.Conn
+	limit int
+}
+
+func (c *droppingConn) Write(p []byte) (int, error) {
+	if len(p) > c.limit {
+		c.Conn.Close()
+		return 0, net.ErrClosed
+	}
+	c.limit -= len(p)
+	return c.Conn.Write(p)
+}
+
+func TestTowerFileTransfer_ResumedAfterConnectionDrop(t *testing.T) {
+	towerFileBytes := bytes.Repeat([]byte("tower"), 3*towerFileChunkSize/5+7)
+	startTime := time.Now().Add(-time.Second)
+
+	// the passive node's view before the tower file arrives
+	server := &Server{logger: zerolog.Nop()}
+	serverConn, activeConn := net.Pipe()
+	t.Cleanup(func() {
+		serverConn.Close()
+		activeConn.Close()
+	})
+	server.failoverStream = newConnStream(serverConn)
+	server.failoverStream.message.FailoverID = "failover-1"
+	server.failoverStream.message.IsDryRunFailover = true
+	server.failoverStream.message.PassiveNodeInfo = NodeInfo{Hostname: "passive", SetIdentityCommand: "set-identity-active"}
+	server.failoverStream.message.PassiveNodeSetIdentityStartTime = startTime
+	server.failoverStream.message.PhaseTimings = []PhaseTiming{{Hostname: "passive", Phase: PhasePreHooks, StartTime: startTime}}
+
+	// the active node's message as it sends the tower file - the passive fields it carries must not be taken
+	active := newConnStream(&droppingConn{Conn: activeConn, limit: 2 * towerFileChunkSize})
+	active.message.FailoverID = "failover-1"
+	active.message.FailoverStartSlot = 1000
+	active.message.PassiveNodeInfo = NodeInfo{Hostname: "passive", SetIdentityCommand: "rm -rf /"}
+	active.message.ActiveNodeInfo = NodeInfo{Hostname: "active", TowerFileHash: "hash"}
+	active.message.ActiveNodeSetIdentityEndTime = startTime.Add(time.Millisecond)
+	active.message.RPCCalls = []RPCCallRecord{{Hostname: "active", Call: "failover start slot", StartTime: startTime}}
+	active.message.PhaseTimings = []PhaseTiming{{Hostname: "active", Phase: PhasePreHooks, StartTime: startTime.Add(-time.Millisecond)}}
+
+	sendErr := make(chan error, 1)
+	go func() { sendErr <- active.sendTowerFile(towerFileBytes) }()
+	require.Error(t, server.failoverStream.receiveTowerFile())
+	require.Error(t, <-sendErr)
+	received := int64(len(server.failoverStream.GetActiveNodeInfo().TowerFileBytes))
+	require.Greater(t, received, int64(0))
+	require.Less(t, received, int64(len(towerFileBytes)))
+
+	// the active node reconnects and resumes from where the passive node got to
+	active.GetActiveNodeInfo().TowerFileWireSize = int64(len(towerFileBytes))
+	resumeStream, resumingActive, _ := newPipeStreams(t)
+	go func() {
+		var response TowerFileResumeResponse
+		if err := resumingActive.decoder.Decode(&response); err != nil {
+			sendErr <- err
+			return
+		}
+		sendErr <- resumingActive.sendTowerFileChunks(towerFileBytes, response.Received)
+	}()
+	resumedFrom, err := server.resumeTowerFile(towerFileResume{
+		stream:  resumeStream,
+		request: TowerFileResumeRequest{Hostname: "active", Message: active.message},
+	})
+	require.NoError(t, err)
+	require.NoError(t, <-sendErr)
+	assert.Equal(t, received, resumedFrom)
+
+	message := server.failoverStream.message
+	assert.Equal(t, towerFileBytes, message.ActiveNodeInfo.TowerFileBytes)
+	assert.Equal(t, "hash", message.ActiveNodeInfo.TowerFileHash)
+	assert.Equal(t, uint64(1000), message.FailoverStartSlot)
+	assert.True(t, message.ActiveNodeSetIdentityEndTime.Equal(active.message.ActiveNodeSetIdentityEndTime))
+
+	// what the passive node decided is its own
+	assert.Equal(t, "set-identity-active", message.PassiveNodeInfo.SetIdentityCommand)
+	assert.True(t, message.IsDryRunFailover)
+	assert.True(t, message.PassiveNodeSetIdentityStartTime.Equal(startTime))
+
+	require.Len(t, message.RPCCalls, 1)
+	require.Len(t, message.PhaseTimings, 2)
+	assert.Equal(t, "active", message.PhaseTimings[0].Hostname)
+	assert.Equal(t, "passive", message.PhaseTimings[1].Hostname)
+}
+
+func TestTowerFileTransfer_ResumeOfAnotherFailoverRefused(t *testing.T) {
+	server := &Server{logger: zerolog.Nop(), failoverStream: &Stream{message: Message{FailoverID: "failover-1"}}}
+	resumeStream, resumingActive, _ := newPipeStreams(t)
+
+	responses := make(chan TowerFileResumeResponse, 1)
+	go func() {
+		var response TowerFileResumeResponse
+		_ = resumingActive.decoder.Decode(&response)
+		responses <- response
+	}()
+	_, err := server.resumeTowerFile(towerFileResume{
+		stream:  resumeStream,
+		request: TowerFileResumeRequest{Hostname: "active", Message: Message{FailoverID: "failover-2"}},
+	})
+	assert.Error(t, err)
+	assert.Contains(t, (<-responses).Error, "failover-2")
+}
@@ -0,0 +1,98 @@
+package failover
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-failover/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// span names of the failover pipeline - the root span of each node is named spanFailover
+const (
+	spanFailover          = "failover"
+	spanWaitForHealthy    = "wait_for_healthy"
+	spanWaitForActiveNode = "wait_for_active_node"
+	spanHandshake         = "handshake"
+	spanConfirm           = "confirm"
+	spanLeaderSlotWait    = "leader_slot_wait"
+	spanWarmHold          = "warm_hold"
+	spanSetIdentity       = "set_identity"
+	spanTowerTransfer     = "tower_transfer"
+	spanWaitForPassive    = "wait_for_passive"
+	spanGossipConfirm     = "gossip_confirmation"
+	spanCreditSampling    = "credit_sampling"
+)
+
+// failoverTrace is one node's spans of a failover under a root span - a nil trace records nothing
+type failoverTrace struct {
+	tracer *tracing.Tracer
+	ctx    context.Context
+	span   trace.Span
+	ended  sync.Once
+}
+
+// startFailoverTrace starts this node's root failover span as a child of the span in parent, or a new trace when
+// there is none - the span starts at startTime so stages timed before it fall inside it
+func startFailoverTrace(tracer *tracing.Tracer, parent context.Context, role, hostname string, startTime time.Time) *failoverTrace {
+	ctx, span := tracer.Start(parent, spanFailover,
+		trace.WithTimestamp(startTime),
+		trace.WithAttributes(
+			attribute.String("failover.role", role),
+			attribute.String("host.name", hostname),
+		),
+	)
+	return &failoverTrace{tracer: tracer, ctx: ctx, span: span}
+}
+
+// start starts a stage span under the root failover span - end it with tracing.End
+func (t *failoverTrace) start(name string, attributes ...attribute.KeyValue) trace.Span {
+	if t == nil {
+		return trace.SpanFromContext(context.Background())
+	}
+	_, span := t.tracer.Start(t.ctx, name, trace.WithAttributes(attributes...))
+	return span
+}
+
+// record records a stage timed before the root failover span was started
+func (t *failoverTrace) record(name string, interval tracing.Interval) {
+	if t == nil {
+		return
+	}
+	t.tracer.Record(t.ctx, name, interval)
+}
+
+// setFailover tags the root span with the failover the stream is running once the passive node has named it
+func (t *failoverTrace) setFailover(stream *Stream) {
+	if t == nil {
+		return
+	}
+	t.span.SetAttributes(
+		attribute.String("failover.id", stream.GetFailoverID()),
+		attribute.Bool("failover.dry_run", stream.GetIsDryRunFailover()),
+		attribute.String("failover.active_node", stream.GetActiveNodeInfo().Hostname),
+		attribute.String("failover.passive_node", stream.GetPassiveNodeInfo().Hostname),
+	)
+}
+
+// traceParent returns the w3c traceparent of the root span to carry to the peer
+func (t *failoverTrace) traceParent() string {
+	if t == nil {
+		return ""
+	}
+	return tracing.TraceParent(t.ctx)
+}
+
+// end ends the root span, failed with reason when set, and exports this node's spans - only the first call counts
+// so an abort followed by the deferred end reports the abort
+func (t *failoverTrace) end(reason string) {
+	if t == nil {
+		return
+	}
+	t.ended.Do(func() {
+		tracing.EndWithReason(t.span, reason)
+		t.tracer.Shutdown()
+	})
+}
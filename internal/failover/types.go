@@ -1,8 +1,59 @@
 package failover
 
+import (
+	"strings"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-failover/internal/utils"
+)
+
 // MonitorConfig holds the configuration for a failover monitor
 type MonitorConfig struct {
-	CreditSamples CreditSamplesConfig `mapstructure:"credit_samples"`
+	CreditSamples   CreditSamplesConfig   `mapstructure:"credit_samples"`
+	MetricsSnapshot MetricsSnapshotConfig `mapstructure:"metrics_snapshot"`
+	RewardsEstimate RewardsEstimateConfig `mapstructure:"rewards_estimate"`
+	// IdentityGapAlarmThreshold is the identity gap above which a completed failover alarms - empty or 0s disables it
+	IdentityGapAlarmThreshold string `mapstructure:"identity_gap_alarm_threshold"`
+	// DurationRegression alerts when a completed failover's phases are slower than earlier failovers in the audit log
+	DurationRegression DurationRegressionConfig `mapstructure:"duration_regression"`
+	// BlockProduction checks the active identity produces blocks in its first leader slots after the failover
+	BlockProduction BlockProductionConfig `mapstructure:"block_production"`
+}
+
+// IdentityGapAlarmThresholdDuration returns the identity gap alarm threshold - zero (disabled) when unset or invalid
+func (c MonitorConfig) IdentityGapAlarmThresholdDuration() time.Duration {
+	threshold, err := time.ParseDuration(c.IdentityGapAlarmThreshold)
+	if err != nil || threshold < 0 {
+		return 0
+	}
+	return threshold
+}
+
+// DurationRegressionConfig holds the configuration for comparing a failover's phase durations against the median of
+// the failovers before it
+type DurationRegressionConfig struct {
+	Enabled              bool    `mapstructure:"enabled"`
+	ThresholdPercent     float64 `mapstructure:"threshold_percent"`
+	BaselineFailovers    int     `mapstructure:"baseline_failovers"`
+	MinBaselineFailovers int     `mapstructure:"min_baseline_failovers"`
+	MinIncrease          string  `mapstructure:"min_increase"`
+}
+
+// BlockProductionConfig holds the configuration for checking the active identity's block production in its first leader
+// slots after a failover
+type BlockProductionConfig struct {
+	Enabled     bool   `mapstructure:"enabled"`
+	LeaderSlots int    `mapstructure:"leader_slots"`
+	Timeout     string `mapstructure:"timeout"`
+}
+
+// TimeoutDuration returns how long to wait for the leader slots to pass - zero when unset or invalid
+func (c BlockProductionConfig) TimeoutDuration() time.Duration {
+	timeout, err := time.ParseDuration(c.Timeout)
+	if err != nil || timeout < 0 {
+		return 0
+	}
+	return timeout
 }
 
 // CreditSamplesConfig holds the configuration for a failover monitor credit samples
@@ -10,3 +61,92 @@ type CreditSamplesConfig struct {
 	Count    int    `mapstructure:"count"`
 	Interval string `mapstructure:"interval"`
 }
+
+// RewardsEstimateConfig holds the configuration for estimating what the failover's identity gap cost in epoch rewards
+type RewardsEstimateConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// MetricsSnapshotConfig holds the configuration for snapshotting local rpc metrics before and after a failover
+type MetricsSnapshotConfig struct {
+	Enabled              bool   `mapstructure:"enabled"`
+	BlockProductionSlots uint64 `mapstructure:"block_production_slots"`
+}
+
+// LogSlotContextConfig holds the configuration for decorating critical failover window log lines with slot and epoch
+type LogSlotContextConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Interval string `mapstructure:"interval"`
+}
+
+// TowerDriftMonitorConfig holds the configuration for monitoring a passive node's tower file for drift
+type TowerDriftMonitorConfig struct {
+	Enabled   bool   `mapstructure:"enabled"`
+	Interval  string `mapstructure:"interval"`
+	AutoClean bool   `mapstructure:"auto_clean"`
+}
+
+// PeerInfo is a configured failover peer
+type PeerInfo struct {
+	Name     string
+	Address  string
+	GossipIP string
+	// PassivePubkey is the peer's passive identity pubkey - empty accepts any
+	PassivePubkey string
+}
+
+// RPCCallRecord is a critical rpc call made during a failover - which node made it, which rpc answered and how fast
+type RPCCallRecord struct {
+	Hostname  string
+	Call      string
+	RPCURL    string
+	StartTime time.Time
+	Latency   time.Duration
+	Error     string
+}
+
+// newRPCCallRecord creates a new rpc call record for a call started at startTime - the rpc url is redacted, in the
+// error too, as the record is shown, sent to the peer and passed to hooks
+func newRPCCallRecord(hostname, call, rpcURL string, startTime time.Time, err error) RPCCallRecord {
+	record := RPCCallRecord{
+		Hostname:  hostname,
+		Call:      call,
+		RPCURL:    utils.RedactURL(rpcURL),
+		StartTime: startTime,
+		Latency:   time.Since(startTime),
+	}
+	if err != nil {
+		record.Error = err.Error()
+		if rpcURL != "" {
+			record.Error = strings.ReplaceAll(record.Error, rpcURL, record.RPCURL)
+		}
+	}
+	return record
+}
+
+// phases timed either side of the critical window
+const (
+	PhaseCheckHooks       = "check hooks"
+	PhasePreHooks         = "pre hooks"
+	PhasePostHooks        = "post hooks"
+	PhaseGossipConfirm    = "gossip confirm"
+	PhaseCreditMonitoring = "credit monitoring"
+)
+
+// PhaseTiming is a hook or verification phase run by either node during a failover and how long it took
+type PhaseTiming struct {
+	Hostname  string
+	Phase     string
+	StartTime time.Time
+	Duration  time.Duration
+}
+
+// newPhaseTiming creates a new phase timing for a phase started at startTime
+func newPhaseTiming(hostname, phase string, startTime time.Time) PhaseTiming {
+	return PhaseTiming{
+		Hostname:  hostname,
+		Phase:     phase,
+		StartTime: startTime,
+		Duration:  time.Since(startTime),
+	}
+}
@@ -0,0 +1,338 @@
+package failover
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/rs/zerolog"
+	"github.com/sol-strategies/solana-validator-failover/internal/identities"
+	"github.com/sol-strategies/solana-validator-failover/internal/style"
+	"golang.org/x/term"
+)
+
+const (
+	// CapabilityWarmHold says a passive node follows the active node's heartbeats while it holds a confirmed failover
+	CapabilityWarmHold = "warm-hold"
+
+	// warmHoldHeartbeatInterval is how often the active node sends a heartbeat while it holds the failover
+	warmHoldHeartbeatInterval = 5 * time.Second
+
+	// warmHoldHeartbeatTimeout is how long the passive node waits for a heartbeat before aborting the failover
+	warmHoldHeartbeatTimeout = 3 * warmHoldHeartbeatInterval
+
+	// CapabilityWarmHoldRelease says a node lets a held failover be released on request through the passive node
+	CapabilityWarmHoldRelease = "warm-hold-release"
+
+	// releaseResponseTimeout is how long a release requester has to read the response before its stream is closed
+	releaseResponseTimeout = 3 * time.Second
+)
+
+// WarmHoldHeartbeat is sent by the active node while it holds a confirmed failover - the last has Released set and is
+// sent once the switch is triggered
+type WarmHoldHeartbeat struct {
+	Sequence uint64
+	Released bool
+}
+
+// WarmHoldRelease is sent by the passive node once per hold when both nodes support releasing it on request - Trigger
+// is set to release the hold on a requester's behalf, empty to acknowledge the active node releasing it itself
+type WarmHoldRelease struct {
+	Trigger string
+}
+
+// ReleaseRequest asks a passive node's failover server to release the failover the active node holds - sent after
+// authenticating on a stream opened with MessageTypeReleaseRequest
+type ReleaseRequest struct {
+	Hostname string
+}
+
+// ReleaseResponse is the passive node's answer to a release request - Error is empty when the release was passed on
+// to the active node
+type ReleaseResponse struct {
+	Error string
+}
+
+// ReleaseConfig is the configuration for asking a passive node's failover server to release the held failover
+type ReleaseConfig struct {
+	ServerName    string
+	ServerAddress string
+	// ActiveIdentity is the validator's active identity keypair - proven like the active node does
+	ActiveIdentity    *identities.Identity
+	ClientCertificate *tls.Certificate
+	Request           ReleaseRequest
+}
+
+// warmHoldRelease passes a release request on to the active node holding the failover - at most one WarmHoldRelease is
+// sent per hold
+type warmHoldRelease struct {
+	mutex sync.Mutex
+	// stream is the failover stream while a hold that can be released is followed - nil otherwise
+	stream *Stream
+	sent   bool
+}
+
+// follow lets release requests through to the active node on the other end of stream
+func (r *warmHoldRelease) follow(stream *Stream) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.stream = stream
+	r.sent = false
+}
+
+// stop lets no more release requests through
+func (r *warmHoldRelease) stop() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.stream = nil
+}
+
+// send sends the active node a WarmHoldRelease with trigger unless one was already sent this hold - false when none
+// was sent
+func (r *warmHoldRelease) send(trigger string) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.stream == nil || r.sent {
+		return false, nil
+	}
+	r.sent = true
+	return true, r.stream.encoder.Encode(WarmHoldRelease{Trigger: trigger})
+}
+
+// holdWarm holds the confirmed failover open, sending the passive node heartbeats, until the operator presses enter,
+// the process is sent SIGUSR1 or the passive node passes on a release request - it stops early with the connection's
+// close cause when the peer goes away or aborts
+func (c *Client) holdWarm() error {
+	if !slices.Contains(c.failoverStream.GetPassiveNodeInfo().Capabilities, CapabilityWarmHold) {
+		return fmt.Errorf("%s doesn't support holding the failover - upgrade it or run without --hold", c.serverName)
+	}
+
+	triggers := make(chan string, 2)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+	defer signal.Stop(signals)
+	go func() {
+		if _, ok := <-signals; ok {
+			triggers <- "SIGUSR1"
+		}
+	}()
+	prompts := []string{"send SIGUSR1"}
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		prompts = []string{"press enter", "send SIGUSR1"}
+		go func() {
+			if _, err := bufio.NewReader(os.Stdin).ReadString('\n'); err == nil {
+				triggers <- "enter"
+			}
+		}()
+	}
+	return c.holdUntilTriggered(triggers, prompts)
+}
+
+// holdUntilTriggered sends the passive node heartbeats until a trigger arrives or the passive node passes on a release
+// request - prompts say how the operator can trigger the switch
+func (c *Client) holdUntilTriggered(triggers <-chan string, prompts []string) error {
+	// the passive node sends one WarmHoldRelease - releasing the hold on request, or acknowledging this node releasing
+	// it - which must be read before the failover carries on over the stream
+	var releases chan warmHoldReleaseRead
+	if slices.Contains(c.failoverStream.GetPassiveNodeInfo().Capabilities, CapabilityWarmHoldRelease) {
+		prompts = append(prompts, "run with --release")
+		releases = make(chan warmHoldReleaseRead, 1)
+		go func() {
+			var read warmHoldReleaseRead
+			read.err = c.failoverStream.decoder.Decode(&read.release)
+			releases <- read
+		}()
+	}
+
+	prompt := prompts[0]
+	if last := len(prompts) - 1; last > 0 {
+		prompt = strings.Join(prompts[:last], ", ") + " or " + prompts[last]
+	}
+	heldAt := time.Now()
+	c.logger.Info().
+		Int("pid", os.Getpid()).
+		Msgf("⏸️ Holding the failover with %s - %s to switch", style.RenderPassiveString(c.serverName, false), prompt)
+
+	ticker := time.NewTicker(warmHoldHeartbeatInterval)
+	defer ticker.Stop()
+	var heartbeat WarmHoldHeartbeat
+	for {
+		var trigger string
+		select {
+		case trigger = <-triggers:
+		case read := <-releases:
+			if read.err != nil {
+				return fmt.Errorf("lost %s while holding the failover: %w", c.serverName, read.err)
+			}
+			releases = nil
+			trigger = read.release.Trigger
+		case <-ticker.C:
+			heartbeat.Sequence++
+			if err := c.failoverStream.encoder.Encode(heartbeat); err != nil {
+				return fmt.Errorf("failed to send heartbeat: %w", err)
+			}
+			c.logger.Debug().Uint64("sequence", heartbeat.Sequence).Msg("sent warm hold heartbeat")
+			continue
+		case <-c.Conn.Context().Done():
+			return context.Cause(c.Conn.Context())
+		}
+
+		heartbeat.Sequence++
+		heartbeat.Released = true
+		if err := c.failoverStream.encoder.Encode(heartbeat); err != nil {
+			return fmt.Errorf("failed to release the held failover: %w", err)
+		}
+		if err := c.awaitWarmHoldReleaseAck(releases); err != nil {
+			return err
+		}
+		c.logger.Info().
+			Str("trigger", trigger).
+			Dur("held_for", time.Since(heldAt).Round(time.Second)).
+			Msg("▶️ Switch triggered - releasing the held failover")
+		return nil
+	}
+}
+
+// warmHoldReleaseRead is the WarmHoldRelease read from the passive node, or why it couldn't be
+type warmHoldReleaseRead struct {
+	release WarmHoldRelease
+	err     error
+}
+
+// awaitWarmHoldReleaseAck waits for the passive node to acknowledge this node releasing the hold - nothing to wait for
+// when releases is nil
+func (c *Client) awaitWarmHoldReleaseAck(releases <-chan warmHoldReleaseRead) error {
+	if releases == nil {
+		return nil
+	}
+	select {
+	case read := <-releases:
+		if read.err != nil {
+			return fmt.Errorf("failed to read %s acknowledging the release: %w", c.serverName, read.err)
+		}
+		return nil
+	case <-c.Conn.Context().Done():
+		return context.Cause(c.Conn.Context())
+	case <-time.After(warmHoldHeartbeatTimeout):
+		return fmt.Errorf("%s didn't acknowledge the release within %s", c.serverName, warmHoldHeartbeatTimeout)
+	}
+}
+
+// followWarmHold reads the active node's heartbeats while it holds the failover until it releases it - none arriving
+// for warmHoldHeartbeatTimeout is an error. Release requests are passed on to the active node while it holds when both
+// nodes support it
+func (s *Server) followWarmHold() error {
+	activeNodeHostname := s.failoverStream.GetActiveNodeInfo().Hostname
+	s.logger.Info().Msgf("⏸️ %s holds the failover until its operator triggers the switch", style.RenderActiveString(activeNodeHostname, false))
+	s.observe(ObserverUpdateTypeProgress, fmt.Sprintf("%s holds the failover until triggered", activeNodeHostname))
+
+	releasable := slices.Contains(s.failoverStream.GetActiveNodeInfo().Capabilities, CapabilityWarmHoldRelease)
+	if releasable {
+		s.warmHoldRelease.follow(s.failoverStream)
+		defer s.warmHoldRelease.stop()
+	}
+	if err := s.failoverStream.readWarmHoldHeartbeats(warmHoldHeartbeatTimeout, s.logger); err != nil {
+		return fmt.Errorf("no heartbeat from %s: %w", activeNodeHostname, err)
+	}
+	s.logger.Info().Msgf("▶️ %s triggered the switch", style.RenderActiveString(activeNodeHostname, false))
+	if !releasable {
+		return nil
+	}
+	// acknowledge the release unless it was released on request
+	if _, err := s.warmHoldRelease.send(""); err != nil {
+		return fmt.Errorf("failed to acknowledge the release: %w", err)
+	}
+	return nil
+}
+
+// readWarmHoldHeartbeats reads heartbeats until one says the hold is released - none arriving within timeout is an
+// error
+func (s *Stream) readWarmHoldHeartbeats(timeout time.Duration, logger zerolog.Logger) error {
+	defer func() {
+		if err := s.Stream.SetReadDeadline(time.Time{}); err != nil {
+			logger.Debug().Err(err).Msg("failed to clear read deadline")
+		}
+	}()
+
+	for {
+		if err := s.Stream.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+			return err
+		}
+		var heartbeat WarmHoldHeartbeat
+		if err := s.decoder.Decode(&heartbeat); err != nil {
+			return err
+		}
+		if heartbeat.Released {
+			return nil
+		}
+		logger.Debug().Uint64("sequence", heartbeat.Sequence).Msg("received warm hold heartbeat")
+	}
+}
+
+// handleReleaseStream has a node prove it holds the active identity keypair then passes its request to release the
+// held failover on to the active node
+func (s *Server) handleReleaseStream(conn quic.Connection, stream quic.Stream) {
+	releaseStream := NewFailoverStream(stream)
+	if err := releaseStream.AuthenticateAsPassive(s.identities.Active); err != nil {
+		s.logger.Error().Err(err).Str("remote_addr", conn.RemoteAddr().String()).Msg("🔒 release requester authentication failed - ignoring release request")
+		return
+	}
+
+	var request ReleaseRequest
+	if err := releaseStream.decoder.Decode(&request); err != nil {
+		s.logger.Error().Err(err).Msg("failed to read release request")
+		return
+	}
+
+	var response ReleaseResponse
+	sent, err := s.warmHoldRelease.send(fmt.Sprintf("release request from %s", request.Hostname))
+	switch {
+	case err != nil:
+		response.Error = fmt.Sprintf("failed to pass the release on to the active node: %v", err)
+	case !sent:
+		response.Error = "no failover is held - the active node isn't holding one, already released it or doesn't support releasing it on request"
+	default:
+		s.logger.Info().Msgf("▶️ %s asked to release the held failover - passed on to the active node", request.Hostname)
+	}
+	if err := releaseStream.encoder.Encode(response); err != nil {
+		s.logger.Error().Err(err).Msg("failed to send release response")
+	}
+
+	// the requester hangs up once it has read the response
+	select {
+	case <-conn.Context().Done():
+	case <-time.After(releaseResponseTimeout):
+	}
+}
+
+// RequestRelease asks a passive node's failover server to release the failover the active node holds - the active node
+// then triggers the switch as though its operator had
+func RequestRelease(config ReleaseConfig) error {
+	conn, stream, err := dialAuthenticated(config.ServerName, config.ServerAddress, config.ActiveIdentity, config.ClientCertificate, QUICConfig{}, MessageTypeReleaseRequest)
+	if err != nil {
+		return err
+	}
+	defer conn.CloseWithError(0, "release request done")
+	defer stream.Stream.Close()
+
+	if err := stream.encoder.Encode(config.Request); err != nil {
+		return fmt.Errorf("failed to send release request: %w", err)
+	}
+	var response ReleaseResponse
+	if err := stream.decoder.Decode(&response); err != nil {
+		return fmt.Errorf("failed to read release response: %w", err)
+	}
+	if response.Error != "" {
+		return fmt.Errorf("%s didn't release the failover: %s", config.ServerName, response.Error)
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package failover
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/rs/zerolog"
+	"github.com/sol-strategies/solana-validator-failover/internal/identities"
+	"github.com/sol-strategies/solana-validator-failover/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newQUICStreams returns both ends of a failover stream over a loopback quic connection
+func newQUICStreams(t *testing.T) (activeConn quic.Connection, active *Stream, passiveConn quic.Connection, passive *Stream) {
+	t.Helper()
+	tlsCert, err := utils.GenerateTLSCertificate()
+	require.NoError(t, err)
+	listener, err := quic.ListenAddr("127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{tlsCert}, NextProtos: []string{ProtocolName}}, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	activeConn, err = quic.DialAddr(ctx, listener.Addr().String(), &tls.Config{InsecureSkipVerify: true, NextProtos: []string{ProtocolName}}, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { activeConn.CloseWithError(0, "test done") })
+	activeStream, err := activeConn.OpenStreamSync(ctx)
+	require.NoError(t, err)
+	_, err = activeStream.Write([]byte{MessageTypeFailoverInitiateRequest})
+	require.NoError(t, err)
+
+	passiveConn, err = listener.Accept(ctx)
+	require.NoError(t, err)
+	passiveStream, err := passiveConn.AcceptStream(ctx)
+	require.NoError(t, err)
+	_, err = io.ReadFull(passiveStream, make([]byte, 1))
+	require.NoError(t, err)
+	return activeConn, NewFailoverStream(activeStream), passiveConn, NewFailoverStream(passiveStream)
+}
+
+// newWarmHoldNodes returns an active node's client and a passive node's server for a confirmed failover the active node
+// holds - both support releasing it on request unless releasable is false
+func newWarmHoldNodes(t *testing.T, releasable bool) (*Client, *Server) {
+	t.Helper()
+	activeConn, active, passiveConn, passive := newQUICStreams(t)
+	capabilities := []string{CapabilityWarmHold}
+	if releasable {
+		capabilities = append(capabilities, CapabilityWarmHoldRelease)
+	}
+	ids := &identities.Identities{Active: newTestIdentity(t), Passive: newTestIdentity(t)}
+	nodeIdentities := NewNodeIdentities(ids)
+	activeNodeInfo := NodeInfo{Hostname: "active", Identities: nodeIdentities, Capabilities: capabilities}
+	passiveNodeInfo := NodeInfo{Hostname: "passive", Identities: nodeIdentities, Capabilities: capabilities}
+
+	active.message.ActiveNodeInfo, active.message.PassiveNodeInfo = activeNodeInfo, passiveNodeInfo
+	passive.message.ActiveNodeInfo, passive.message.PassiveNodeInfo = activeNodeInfo, passiveNodeInfo
+	c := &Client{Conn: activeConn, failoverStream: active, logger: zerolog.Nop(), serverName: "passive"}
+	s := &Server{
+		logger:          zerolog.Nop(),
+		observers:       newObserverHub(zerolog.Nop()),
+		identities:      ids,
+		passiveNodeInfo: &passiveNodeInfo,
+		failoverStream:  passive,
+		activeConn:      passiveConn,
+	}
+	s.pendingAbort.arm()
+	return c, s
+}
+
+// holdWarm runs the hold on both nodes in the background until it is released or stopped
+func holdWarm(c *Client, s *Server, triggers <-chan string) (held, followed <-chan error) {
+	heldErrs, followedErrs := make(chan error, 1), make(chan error, 1)
+	go func() { heldErrs <- c.holdUntilTriggered(triggers, []string{"test"}) }()
+	go func() { followedErrs <- s.followWarmHold() }()
+	return heldErrs, followedErrs
+}
+
+// assertStreamCarriesOn asserts nothing of the hold is left unread on the stream in either direction
+func assertStreamCarriesOn(t *testing.T, c *Client, s *Server) {
+	t.Helper()
+	require.NoError(t, c.failoverStream.encoder.Encode(TowerFileChunkAck{Received: 1}))
+	var ack TowerFileChunkAck
+	require.NoError(t, s.failoverStream.decoder.Decode(&ack))
+	assert.Equal(t, int64(1), ack.Received)
+
+	require.NoError(t, s.failoverStream.encoder.Encode(TowerFileChunkAck{Received: 2}))
+	require.NoError(t, c.failoverStream.decoder.Decode(&ack))
+	assert.Equal(t, int64(2), ack.Received)
+}
+
+func TestWarmHold_HeartbeatTiming(t *testing.T) {
+	// a heartbeat or two can go missing before the passive node gives up on the active node
+	assert.Equal(t, 5*time.Second, warmHoldHeartbeatInterval)
+	assert.Equal(t, 15*time.Second, warmHoldHeartbeatTimeout)
+}
+
+func TestReadWarmHoldHeartbeats(t *testing.T) {
+	_, active, _, passive := newQUICStreams(t)
+
+	// heartbeats arriving within the timeout keep the hold going until it is released
+	go func() {
+		for sequence := uint64(1); sequence <= 5; sequence++ {
+			time.Sleep(20 * time.Millisecond)
+			_ = active.encoder.Encode(WarmHoldHeartbeat{Sequence: sequence, Released: sequence == 5})
+		}
+	}()
+	assert.NoError(t, passive.readWarmHoldHeartbeats(75*time.Millisecond, zerolog.Nop()))
+}
+
+func TestReadWarmHoldHeartbeats_Timeout(t *testing.T) {
+	_, active, _, passive := newQUICStreams(t)
+	require.NoError(t, active.encoder.Encode(WarmHoldHeartbeat{Sequence: 1}))
+
+	// the heartbeats stop
+	startTime := time.Now()
+	err := passive.readWarmHoldHeartbeats(100*time.Millisecond, zerolog.Nop())
+	var netErr net.Error
+	require.True(t, errors.As(err, &netErr), "expected a timeout, got %v", err)
+	assert.True(t, netErr.Timeout())
+	assert.GreaterOrEqual(t, time.Since(startTime), 100*time.Millisecond)
+}
+
+func TestWarmHold_ReleasedByOperator(t *testing.T) {
+	tests := []struct {
+		name       string
+		releasable bool
+	}{
+		{"acknowledged", true},
+		{"peer can't release on request", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, s := newWarmHoldNodes(t, tt.releasable)
+			triggers := make(chan string, 1)
+			held, followed := holdWarm(c, s, triggers)
+
+			triggers <- "enter"
+			require.NoError(t, <-held)
+			require.NoError(t, <-followed)
+			assertStreamCarriesOn(t, c, s)
+
+			// the hold is over so there is nothing left to release
+			sent, err := s.warmHoldRelease.send("too late")
+			assert.NoError(t, err)
+			assert.False(t, sent)
+		})
+	}
+}
+
+func TestWarmHold_ReleasedOnRequest(t *testing.T) {
+	c, s := newWarmHoldNodes(t, true)
+	held, followed := holdWarm(c, s, nil)
+
+	assert.Eventually(t, func() bool {
+		sent, err := s.warmHoldRelease.send("release request from ops")
+		require.NoError(t, err)
+		return sent
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, <-held)
+	require.NoError(t, <-followed)
+	assertStreamCarriesOn(t, c, s)
+}
+
+func TestWarmHold_AbortedWhileHeld(t *testing.T) {
+	c, s := newWarmHoldNodes(t, true)
+	held, followed := holdWarm(c, s, nil)
+
+	require.True(t, s.abortPendingFailover("operator asked"))
+
+	// the active node learns why and the passive node doesn't mistake it for losing the active node
+	reason, ok := peerAbortReason(<-held)
+	assert.True(t, ok)
+	assert.Equal(t, "operator asked", reason)
+	assert.True(t, s.stopIfAborted(<-followed))
+	assert.ErrorContains(t, s.pendingAbort.err(), "failover aborted: operator asked")
+}
+
+func TestRequestRelease(t *testing.T) {
+	s, address := newListeningTestServer(t)
+	config := ReleaseConfig{ServerName: "passive", ServerAddress: address, ActiveIdentity: s.identities.Active, Request: ReleaseRequest{Hostname: "ops"}}
+
+	// nothing held
+	assert.ErrorContains(t, RequestRelease(config), "passive didn't release the failover: no failover is held")
+
+	// the release is passed on to the active node holding the failover
+	passive, active, _ := newPipeStreams(t)
+	s.warmHoldRelease.follow(passive)
+	released := make(chan WarmHoldRelease, 1)
+	go func() {
+		var release WarmHoldRelease
+		if active.decoder.Decode(&release) == nil {
+			released <- release
+		}
+	}()
+	require.NoError(t, RequestRelease(config))
+	assert.Equal(t, WarmHoldRelease{Trigger: "release request from ops"}, <-released)
+
+	// once
+	assert.ErrorContains(t, RequestRelease(config), "no failover is held")
+
+	config.ActiveIdentity = newTestIdentity(t)
+	assert.ErrorContains(t, RequestRelease(config), "passive node refused this node")
+}
@@ -2,23 +2,59 @@ package hooks
 
 import (
 	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os/exec"
 	"strings"
 	"sync"
+	"syscall"
+	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/sol-strategies/solana-validator-failover/internal/utils"
 )
 
+const (
+	// hookWaitDelay is how long a hook's output may stay open after it was killed for timing out before it is closed
+	hookWaitDelay = 5 * time.Second
+
+	// DefaultRetryDelay is how long to wait before retrying a failed hook the first time when retry_delay isn't set
+	DefaultRetryDelay = time.Second
+	// MaxRetryBackoff caps how long a failed hook waits between its retries in total - the last delay is cut short to
+	// fit and no retries are made after it, so a hook with many retries can't hold a failover up for long
+	MaxRetryBackoff = time.Minute
+)
+
 // Hook is a hook that is called before or after a failover
 type Hook struct {
-	Name        string   `mapstructure:"name"`
+	Name string `mapstructure:"name"`
+	// Type is command or http - empty is command
+	Type        string   `mapstructure:"type"`
 	Command     string   `mapstructure:"command"`
 	Args        []string `mapstructure:"args"`
 	MustSucceed bool     `mapstructure:"must_succeed"`
+	// URL, Method, Headers and Body are the request an http hook sends - Method defaults to POST and an empty Body
+	// sends the hook's env variables as json
+	URL     string            `mapstructure:"url"`
+	Method  string            `mapstructure:"method"`
+	Headers map[string]string `mapstructure:"headers"`
+	Body    string            `mapstructure:"body"`
+	// Timeout kills the hook and everything it started when it runs longer - empty lets it run for as long as it takes
+	Timeout string `mapstructure:"timeout"`
+	// Retries is how many more times a failed hook is run before it counts as failed, waiting RetryDelay before the
+	// first retry and twice as long before each one after
+	Retries    int    `mapstructure:"retries"`
+	RetryDelay string `mapstructure:"retry_delay"`
+	// When holds the conditions the hook only runs under
+	When When `mapstructure:"when"`
+
+	// templateContext is the FailoverHooks' TemplateContext the hook runs with
+	templateContext map[string]any
 }
 
 // Hooks is a collection of hooks
@@ -36,10 +72,49 @@ type PostHooks struct {
 	WhenActive  Hooks `mapstructure:"when_active"`
 }
 
+// AbortHooks is a collection of hooks run when a failover fails partway
+type AbortHooks struct {
+	WhenPassive Hooks `mapstructure:"when_passive"`
+	WhenActive  Hooks `mapstructure:"when_active"`
+}
+
+// SetIdentityHooks is a collection of hooks run right before a node sets its identity
+type SetIdentityHooks struct {
+	WhenPassive Hooks `mapstructure:"when_passive"`
+	WhenActive  Hooks `mapstructure:"when_active"`
+}
+
 // FailoverHooks is a collection of hooks for pre and post failover
 type FailoverHooks struct {
-	Pre  PreHooks  `mapstructure:"pre"`
-	Post PostHooks `mapstructure:"post"`
+	// Check hooks run on the passive node before the failover is confirmed and can veto it
+	Check Hooks     `mapstructure:"check"`
+	Pre   PreHooks  `mapstructure:"pre"`
+	Post  PostHooks `mapstructure:"post"`
+	// BeforeSetIdentity hooks run on each node right before it sets its identity, by the role it has until then
+	BeforeSetIdentity SetIdentityHooks `mapstructure:"before_set_identity"`
+	// AfterTowerReceived hooks run on the passive node once the tower file it received is written, before it sets
+	// its identity
+	AfterTowerReceived Hooks `mapstructure:"after_tower_received"`
+	// AfterGossipConfirmed hooks run on the node that became active once gossip shows both nodes switched identity
+	AfterGossipConfirmed Hooks `mapstructure:"after_gossip_confirmed"`
+	// OnAbort hooks run when the failover fails partway, by the role the node had when it started
+	OnAbort AbortHooks `mapstructure:"on_abort"`
+	// TemplateContext holds the validator fields hook commands, args and http requests can be templated with besides
+	// the env variables hooks get - set when the config is loaded
+	TemplateContext map[string]any `mapstructure:"-" json:"-"`
+}
+
+// CheckResult is the verdict of a check hook
+type CheckResult struct {
+	Name   string
+	Allow  bool
+	Reason string
+}
+
+// checkOutput is the json a check hook writes to stdout
+type checkOutput struct {
+	Allow  *bool  `json:"allow"`
+	Reason string `json:"reason"`
 }
 
 // HasPreHooksWhenActive returns true if there are any pre hooks when the validator is active
@@ -52,20 +127,199 @@ func (h FailoverHooks) HasPreHooksWhenPassive() bool {
 	return len(h.Pre.WhenPassive) > 0
 }
 
-// Run runs the hook
-func (h Hook) Run(envMap map[string]string) error {
-	hookLogger := log.With().Str("hook", h.Name).Logger()
-	// run the command passing in custom env variables about the state using os.exec
-	cmd := exec.Command(h.Command, h.Args...)
+// Validate checks every hook's settings can be used - key is where the hooks are configured
+func (h FailoverHooks) Validate(key string) error {
+	for _, hooks := range []struct {
+		key   string
+		hooks Hooks
+	}{
+		{key + ".check", h.Check},
+		{key + ".pre.when_active", h.Pre.WhenActive},
+		{key + ".pre.when_passive", h.Pre.WhenPassive},
+		{key + ".post.when_active", h.Post.WhenActive},
+		{key + ".post.when_passive", h.Post.WhenPassive},
+		{key + ".before_set_identity.when_active", h.BeforeSetIdentity.WhenActive},
+		{key + ".before_set_identity.when_passive", h.BeforeSetIdentity.WhenPassive},
+		{key + ".after_tower_received", h.AfterTowerReceived},
+		{key + ".after_gossip_confirmed", h.AfterGossipConfirmed},
+		{key + ".on_abort.when_active", h.OnAbort.WhenActive},
+		{key + ".on_abort.when_passive", h.OnAbort.WhenPassive},
+	} {
+		for i, hook := range hooks.hooks {
+			switch hook.Type {
+			case "", TypeCommand:
+				// a check hook's verdict is needed, the others just fail when run
+				if hook.Command == "" && hooks.key == key+".check" {
+					return fmt.Errorf("%s[%d] %s has no command", hooks.key, i, hook.Name)
+				}
+				if err := hook.validateCommandTemplates(); err != nil {
+					return fmt.Errorf("%s[%d] %s: %w", hooks.key, i, hook.Name, err)
+				}
+				if err := hook.validateSystemdAction(); err != nil {
+					return fmt.Errorf("%s[%d] %s: %w", hooks.key, i, hook.Name, err)
+				}
+				// a systemd action writes no verdict
+				if _, _, ok := systemdAction(hook.Command, hook.Args); ok && hooks.key == key+".check" {
+					return fmt.Errorf("%s[%d] %s: check hooks can't be systemd actions", hooks.key, i, hook.Name)
+				}
+			case TypeHTTP:
+				if err := hook.validateHTTP(); err != nil {
+					return fmt.Errorf("%s[%d] %s: %w", hooks.key, i, hook.Name, err)
+				}
+			default:
+				return fmt.Errorf("%s[%d] %s has invalid type %q - must be %s or %s", hooks.key, i, hook.Name, hook.Type, TypeCommand, TypeHTTP)
+			}
+			if _, err := hook.timeout(); err != nil {
+				return fmt.Errorf("%s[%d] %s: %w", hooks.key, i, hook.Name, err)
+			}
+			if err := hook.When.validate(); err != nil {
+				return fmt.Errorf("%s[%d] %s: %w", hooks.key, i, hook.Name, err)
+			}
+			if hook.Retries < 0 {
+				return fmt.Errorf("%s[%d] %s: retries can't be negative, got %d", hooks.key, i, hook.Name, hook.Retries)
+			}
+			if _, err := hook.retryDelay(); err != nil {
+				return fmt.Errorf("%s[%d] %s: %w", hooks.key, i, hook.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// timeout returns how long the hook may run - zero when it isn't limited
+func (h Hook) timeout() (time.Duration, error) {
+	if h.Timeout == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(h.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse timeout %s: %w", h.Timeout, err)
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("timeout must be positive, got %s", h.Timeout)
+	}
+	return timeout, nil
+}
+
+// retryDelay returns how long to wait before the first retry
+func (h Hook) retryDelay() (time.Duration, error) {
+	if h.RetryDelay == "" {
+		return DefaultRetryDelay, nil
+	}
+	delay, err := time.ParseDuration(h.RetryDelay)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse retry_delay %s: %w", h.RetryDelay, err)
+	}
+	if delay < 0 {
+		return 0, fmt.Errorf("retry_delay can't be negative, got %s", h.RetryDelay)
+	}
+	return delay, nil
+}
+
+// withRetries calls attempt until it succeeds, the hook's retries are used up or MaxRetryBackoff has been waited,
+// backing off between attempts with sleep - the last attempt's error is returned
+func (h Hook) withRetries(attempt func() error, sleep func(time.Duration)) (err error) {
+	// an invalid delay is refused when the config is loaded
+	delay, _ := h.retryDelay()
+	var waited time.Duration
+	for try := 0; ; try++ {
+		err = attempt()
+		if err == nil || try >= h.Retries {
+			return err
+		}
+		if waited >= MaxRetryBackoff && delay > 0 {
+			log.Warn().
+				Str("hook", h.Name).
+				Int("retries_left", h.Retries-try).
+				Dur("max_retry_backoff", MaxRetryBackoff).
+				Msg("🪝  Hook failed - not retrying, backed off for too long")
+			return err
+		}
+		delay = min(delay, MaxRetryBackoff-waited)
+		log.Warn().
+			Str("hook", h.Name).
+			Err(err).
+			Int("retry", try+1).
+			Int("retries", h.Retries).
+			Dur("delay", delay).
+			Msg("🪝  Hook failed - retrying")
+		sleep(delay)
+		waited += delay
+		delay *= 2
+	}
+}
+
+// context returns the context the hook runs in - it is done once the hook's timeout passes
+func (h Hook) context() (context.Context, context.CancelFunc) {
+	if h.Type == TypeHTTP {
+		return context.WithTimeout(context.Background(), h.httpTimeout())
+	}
+	timeout, err := h.timeout()
+	if err != nil || timeout == 0 {
+		// an invalid timeout is refused when the config is loaded
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), timeout)
+}
+
+// command returns the hook's command, with its command and args rendered as templates, passing in custom env variables
+// about the state - it runs in its own process group so the whole group is killed when ctx is done, not just the hook
+// leaving what it started running
+func (h Hook) command(ctx context.Context, envMap map[string]string) (*exec.Cmd, error) {
+	command, args, err := h.renderCommand(envMap)
+	if err != nil {
+		return nil, err
+	}
+	command, args = systemctlCommand(command, args)
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = hookWaitDelay
 	for k, v := range utils.SortStringMap(envMap) {
 		// Trim newlines and whitespace from the value
 		cleanValue := strings.TrimSpace(v)
 		cmd.Env = append(cmd.Env, fmt.Sprintf("SOLANA_VALIDATOR_FAILOVER_%s=%s", k, cleanValue))
 	}
+	return cmd, nil
+}
+
+// waitError returns the error of a hook that exited - naming its timeout when it was killed for running too long
+func (h Hook) waitError(ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s - killed it and its process group", h.Timeout)
+	}
+	return err
+}
+
+// Run runs the hook, retrying it when it fails as configured - it is skipped when its when conditions don't hold
+func (h Hook) Run(envMap map[string]string) error {
+	if skip, err := h.skip(envMap); skip || err != nil {
+		return err
+	}
+	return h.withRetries(func() error {
+		return h.run(envMap)
+	}, time.Sleep)
+}
+
+// run runs the hook once
+func (h Hook) run(envMap map[string]string) error {
+	if h.Type == TypeHTTP {
+		return h.runHTTP(envMap)
+	}
+	hookLogger := log.With().Str("hook", h.Name).Logger()
+	ctx, cancel := h.context()
+	defer cancel()
+	// run the command passing in custom env variables about the state using os.exec
+	cmd, err := h.command(ctx, envMap)
+	if err != nil {
+		return fmt.Errorf("🪝 🔴 Hook %s failed: %v", h.Name, err)
+	}
 
 	hookLogger.Debug().
-		Str("command", h.Command).
-		Str("args", fmt.Sprintf("[%s]", strings.Join(h.Args, ", "))).
+		Str("command", cmd.Args[0]).
+		Str("args", fmt.Sprintf("[%s]", strings.Join(cmd.Args[1:], ", "))).
 		Str("env", fmt.Sprintf("[%s]", strings.Join(cmd.Env, ", "))).
 		Msg("running hook")
 
@@ -81,8 +335,8 @@ func (h Hook) Run(envMap map[string]string) error {
 
 	// Start the command
 	hookLogger.Info().
-		Str("command", h.Command).
-		Str("args", fmt.Sprintf("[%s]", strings.Join(h.Args, ", "))).
+		Str("command", cmd.Args[0]).
+		Str("args", fmt.Sprintf("[%s]", strings.Join(cmd.Args[1:], ", "))).
 		Msg("🪝  Running hook")
 	if err := cmd.Start(); err != nil {
 		return fmt.Errorf("Hook %s failed to start: %v", h.Name, err)
@@ -109,13 +363,86 @@ func (h Hook) Run(envMap map[string]string) error {
 	wg.Wait()
 
 	if err != nil {
-		return fmt.Errorf("🪝 🔴 Hook %s failed: %v", h.Name, err)
+		return fmt.Errorf("🪝 🔴 Hook %s failed: %v", h.Name, h.waitError(ctx, err))
 	}
 
 	hookLogger.Info().Msg("🪝  Hook completed successfully")
 	return nil
 }
 
+// RunCheck runs the hook as a check - it must exit 0 and write {"allow": true|false, "reason": "..."} to stdout. It
+// is retried as configured when it doesn't, but not when it vetoes the failover. A check whose when conditions don't
+// hold returns errSkipped
+func (h Hook) RunCheck(envMap map[string]string) (result CheckResult, err error) {
+	if skip, err := h.skip(envMap); skip || err != nil {
+		if skip {
+			return CheckResult{}, errSkipped
+		}
+		return CheckResult{}, err
+	}
+	err = h.withRetries(func() (err error) {
+		result, err = h.runCheck(envMap)
+		return err
+	}, time.Sleep)
+	return result, err
+}
+
+// runCheck runs the hook as a check once
+func (h Hook) runCheck(envMap map[string]string) (CheckResult, error) {
+	if h.Type == TypeHTTP {
+		return h.runHTTPCheck(envMap)
+	}
+	hookLogger := log.With().Str("hook", h.Name).Logger()
+	ctx, cancel := h.context()
+	defer cancel()
+	cmd, err := h.command(ctx, envMap)
+	if err != nil {
+		return CheckResult{}, fmt.Errorf("🪝 🔴 Check hook %s failed: %v", h.Name, err)
+	}
+
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return CheckResult{}, fmt.Errorf("Check hook %s failed to create stderr pipe: %v", h.Name, err)
+	}
+
+	hookLogger.Info().
+		Str("command", cmd.Args[0]).
+		Str("args", fmt.Sprintf("[%s]", strings.Join(cmd.Args[1:], ", "))).
+		Msg("🪝  Running check hook")
+	if err := cmd.Start(); err != nil {
+		return CheckResult{}, fmt.Errorf("Check hook %s failed to start: %v", h.Name, err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		streamOutput(hookLogger, stderr, "stderr")
+	}()
+	// stderr must be drained before waiting on the command
+	wg.Wait()
+
+	if err := cmd.Wait(); err != nil {
+		return CheckResult{}, fmt.Errorf("🪝 🔴 Check hook %s failed: %v", h.Name, h.waitError(ctx, err))
+	}
+
+	return parseCheckOutput(h.Name, stdout.Bytes())
+}
+
+// parseCheckOutput parses a check hook's stdout into its verdict
+func parseCheckOutput(name string, stdout []byte) (CheckResult, error) {
+	var output checkOutput
+	if err := json.Unmarshal(bytes.TrimSpace(stdout), &output); err != nil {
+		return CheckResult{}, fmt.Errorf("Check hook %s wrote invalid json to stdout: %v", name, err)
+	}
+	if output.Allow == nil {
+		return CheckResult{}, fmt.Errorf("Check hook %s did not say whether to allow the failover", name)
+	}
+	return CheckResult{Name: name, Allow: *output.Allow, Reason: output.Reason}, nil
+}
+
 // streamOutput streams output from a pipe to the logger in real-time
 func streamOutput(logger zerolog.Logger, pipe io.ReadCloser, streamType string) {
 	defer pipe.Close()
@@ -142,9 +469,41 @@ func streamOutput(logger zerolog.Logger, pipe io.ReadCloser, streamType string)
 	}
 }
 
+// RunChecks runs the check hooks - a hook that fails or writes invalid output vetoes the failover when it must
+// succeed and is otherwise logged and ignored
+func (h FailoverHooks) RunChecks(envMap map[string]string) (results []CheckResult) {
+	for _, hook := range h.Check {
+		hook.templateContext = h.TemplateContext
+		result, err := hook.RunCheck(envMap)
+		if errors.Is(err, errSkipped) {
+			continue
+		}
+		if err != nil && hook.MustSucceed {
+			result = CheckResult{Name: hook.Name, Allow: false, Reason: err.Error()}
+		} else if err != nil {
+			log.Error().Err(err).Msgf("check hook %s failed - must_succeed is false, continuing...", hook.Name)
+			continue
+		}
+		log.Debug().Str("hook", result.Name).Bool("allow", result.Allow).Str("reason", result.Reason).Msg("check hook verdict")
+		results = append(results, result)
+	}
+	return results
+}
+
+// Vetoes returns the check results that block the failover
+func Vetoes(results []CheckResult) (vetoes []CheckResult) {
+	for _, result := range results {
+		if !result.Allow {
+			vetoes = append(vetoes, result)
+		}
+	}
+	return vetoes
+}
+
 // RunPreWhenPassive runs the pre hooks when the validator is passive
 func (h FailoverHooks) RunPreWhenPassive(envMap map[string]string) error {
 	for _, hook := range h.Pre.WhenPassive {
+		hook.templateContext = h.TemplateContext
 		err := hook.Run(envMap)
 		if err != nil && hook.MustSucceed {
 			return err
@@ -159,6 +518,7 @@ func (h FailoverHooks) RunPreWhenPassive(envMap map[string]string) error {
 // RunPreWhenActive runs the pre hooks when the validator is active
 func (h FailoverHooks) RunPreWhenActive(envMap map[string]string) error {
 	for _, hook := range h.Pre.WhenActive {
+		hook.templateContext = h.TemplateContext
 		err := hook.Run(envMap)
 		if err != nil && hook.MustSucceed {
 			return err
@@ -174,6 +534,7 @@ func (h FailoverHooks) RunPreWhenActive(envMap map[string]string) error {
 // RunPostWhenPassive runs the post hooks when the validator is passive
 func (h FailoverHooks) RunPostWhenPassive(envMap map[string]string) {
 	for _, hook := range h.Post.WhenPassive {
+		hook.templateContext = h.TemplateContext
 		err := hook.Run(envMap)
 		if err != nil {
 			log.Error().Err(err).Msgf("post hook %s failed", hook.Name)
@@ -184,9 +545,74 @@ func (h FailoverHooks) RunPostWhenPassive(envMap map[string]string) {
 // RunPostWhenActive runs the post hooks when the validator is active
 func (h FailoverHooks) RunPostWhenActive(envMap map[string]string) {
 	for _, hook := range h.Post.WhenActive {
+		hook.templateContext = h.TemplateContext
 		err := hook.Run(envMap)
 		if err != nil {
 			log.Error().Err(err).Msgf("post hook %s failed", hook.Name)
 		}
 	}
 }
+
+// RunBeforeSetIdentityWhenActive runs the before set identity hooks on the active node
+func (h FailoverHooks) RunBeforeSetIdentityWhenActive(envMap map[string]string) error {
+	return h.runMustSucceed(h.BeforeSetIdentity.WhenActive, "before set identity", envMap)
+}
+
+// RunBeforeSetIdentityWhenPassive runs the before set identity hooks on the passive node
+func (h FailoverHooks) RunBeforeSetIdentityWhenPassive(envMap map[string]string) error {
+	return h.runMustSucceed(h.BeforeSetIdentity.WhenPassive, "before set identity", envMap)
+}
+
+// RunAfterTowerReceived runs the after tower received hooks on the passive node
+func (h FailoverHooks) RunAfterTowerReceived(envMap map[string]string) error {
+	return h.runMustSucceed(h.AfterTowerReceived, "after tower received", envMap)
+}
+
+// RunAfterGossipConfirmed runs the after gossip confirmed hooks on the node that became active
+func (h FailoverHooks) RunAfterGossipConfirmed(envMap map[string]string) {
+	for _, hook := range h.AfterGossipConfirmed {
+		hook.templateContext = h.TemplateContext
+		err := hook.Run(envMap)
+		if err != nil {
+			log.Error().Err(err).Msgf("after gossip confirmed hook %s failed", hook.Name)
+		}
+	}
+}
+
+// runMustSucceed runs hooks in order, returning the error of the first one that fails and must succeed - phase names
+// them in the log
+func (h FailoverHooks) runMustSucceed(hooks Hooks, phase string, envMap map[string]string) error {
+	for _, hook := range hooks {
+		hook.templateContext = h.TemplateContext
+		err := hook.Run(envMap)
+		if err != nil && hook.MustSucceed {
+			return err
+		}
+		if err != nil {
+			log.Error().Err(err).Msgf("%s hook %s failed - must_succeed is false, continuing...", phase, hook.Name)
+		}
+	}
+	return nil
+}
+
+// RunOnAbortWhenPassive runs the on abort hooks on the node that was passive when the failover started
+func (h FailoverHooks) RunOnAbortWhenPassive(envMap map[string]string) {
+	for _, hook := range h.OnAbort.WhenPassive {
+		hook.templateContext = h.TemplateContext
+		err := hook.Run(envMap)
+		if err != nil {
+			log.Error().Err(err).Msgf("on abort hook %s failed", hook.Name)
+		}
+	}
+}
+
+// RunOnAbortWhenActive runs the on abort hooks on the node that was active when the failover started
+func (h FailoverHooks) RunOnAbortWhenActive(envMap map[string]string) {
+	for _, hook := range h.OnAbort.WhenActive {
+		hook.templateContext = h.TemplateContext
+		err := hook.Run(envMap)
+		if err != nil {
+			log.Error().Err(err).Msgf("on abort hook %s failed", hook.Name)
+		}
+	}
+}
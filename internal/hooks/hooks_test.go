@@ -0,0 +1,104 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// processRunning reports whether pid is a live process - a zombie waiting to be reaped has already been killed
+func processRunning(pid int) bool {
+	stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return false
+	}
+	// the state follows the parenthesised command name
+	fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+	return len(fields) > 0 && fields[0] != "Z" && fields[0] != "X"
+}
+
+func TestHookRun_TimeoutKillsProcessGroup(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "child.pid")
+	hook := Hook{
+		Name:    "slow",
+		Command: "sh",
+		// sleep in the background so the hook has a child of its own, then wait on it
+		Args:    []string{"-c", "sleep 30 & echo $! > " + pidFile + "; wait"},
+		Timeout: "500ms",
+	}
+
+	start := time.Now()
+	err := hook.Run(nil)
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "timed out after 500ms - killed it and its process group")
+	assert.Less(t, time.Since(start), hookWaitDelay)
+
+	pidBytes, err := os.ReadFile(pidFile)
+	require.NoError(t, err)
+	childPid, err := strconv.Atoi(strings.TrimSpace(string(pidBytes)))
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return !processRunning(childPid) }, 2*time.Second, 10*time.Millisecond,
+		"the hook's sleep %d outlived its timeout", childPid)
+}
+
+func TestHookRun_WithinTimeout(t *testing.T) {
+	hook := Hook{Name: "quick", Command: "true", Timeout: "5s"}
+	assert.NoError(t, hook.Run(nil))
+}
+
+// recordSleeps returns a sleep func that records the delays it is called with instead of sleeping
+func recordSleeps(delays *[]time.Duration) func(time.Duration) {
+	return func(d time.Duration) { *delays = append(*delays, d) }
+}
+
+func TestWithRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		hook         Hook
+		failures     int
+		wantAttempts int
+		wantDelays   []time.Duration
+		wantErr      bool
+	}{
+		{"succeeds first time", Hook{Retries: 3}, 0, 1, nil, false},
+		{"no retries", Hook{}, 5, 1, nil, true},
+		{"succeeds on a retry", Hook{Retries: 3, RetryDelay: "100ms"}, 2, 3, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}, false},
+		{"retries used up", Hook{Retries: 2}, 5, 3, []time.Duration{time.Second, 2 * time.Second}, true},
+		{"no delay", Hook{Retries: 2, RetryDelay: "0s"}, 5, 3, []time.Duration{0, 0}, true},
+		{
+			"total backoff capped",
+			Hook{Retries: 10, RetryDelay: "10s"},
+			20,
+			4,
+			[]time.Duration{10 * time.Second, 20 * time.Second, MaxRetryBackoff - 30*time.Second},
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			var delays []time.Duration
+			err := tt.hook.withRetries(func() error {
+				attempts++
+				if attempts <= tt.failures {
+					return assert.AnError
+				}
+				return nil
+			}, recordSleeps(&delays))
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, assert.AnError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantAttempts, attempts)
+			assert.Equal(t, tt.wantDelays, delays)
+		})
+	}
+}
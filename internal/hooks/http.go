@@ -0,0 +1,171 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// TypeCommand hooks run Command with Args - the default
+	TypeCommand = "command"
+	// TypeHTTP hooks send a request to URL without needing curl on the host
+	TypeHTTP = "http"
+
+	// DefaultHTTPTimeout is how long an http hook's request may take when the hook has no timeout
+	DefaultHTTPTimeout = 10 * time.Second
+
+	// httpResponseLogLimit is how much of an http hook's response is logged or put in its error
+	httpResponseLogLimit = 512
+)
+
+// validateHTTP checks an http hook's url, method, headers and body template
+func (h Hook) validateHTTP() error {
+	if h.URL == "" {
+		return fmt.Errorf("url is required for %s hooks", TypeHTTP)
+	}
+	for name, text := range h.httpTemplates() {
+		if _, err := parseTemplate(name, text); err != nil {
+			return err
+		}
+	}
+	if h.Method != "" && strings.ContainsAny(h.Method, " \t\r\n") {
+		return fmt.Errorf("invalid method %q", h.Method)
+	}
+	return nil
+}
+
+// httpTemplates returns the url, headers and body of an http hook that are rendered as templates - keyed by what they
+// are for
+func (h Hook) httpTemplates() map[string]string {
+	texts := map[string]string{"url": h.URL, "body": h.Body}
+	for name, value := range h.Headers {
+		texts["headers."+name] = value
+	}
+	return texts
+}
+
+// request builds an http hook's request - its url, header values and body are rendered with the same data as a
+// command hook's command and args. An empty body sends the env variables a command hook gets as a json object
+func (h Hook) request(ctx context.Context, envMap map[string]string) (*http.Request, error) {
+	texts := h.httpTemplates()
+	data := h.templateData(envMap)
+	render := func(name string) (string, error) {
+		return renderTemplate(name, texts[name], data)
+	}
+
+	requestURL, err := render("url")
+	if err != nil {
+		return nil, err
+	}
+	if parsed, err := url.Parse(requestURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid url %s - must be an http or https url", requestURL)
+	}
+
+	body, err := render("body")
+	if err != nil {
+		return nil, err
+	}
+	if h.Body == "" {
+		variables := make(map[string]string, len(envMap))
+		for k, v := range envMap {
+			variables[k] = strings.TrimSpace(v)
+		}
+		encoded, err := json.Marshal(variables)
+		if err != nil {
+			return nil, err
+		}
+		body = string(encoded)
+	}
+
+	method := http.MethodPost
+	if h.Method != "" {
+		method = strings.ToUpper(h.Method)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, requestURL, strings.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for name := range h.Headers {
+		value, err := render("headers." + name)
+		if err != nil {
+			return nil, err
+		}
+		request.Header.Set(name, value)
+	}
+	return request, nil
+}
+
+// doHTTP sends an http hook's request and returns the response body - a status other than 2xx is an error
+func (h Hook) doHTTP(envMap map[string]string) ([]byte, error) {
+	ctx, cancel := h.context()
+	defer cancel()
+
+	request, err := h.request(ctx, envMap)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("hook", h.Name).
+		Str("method", request.Method).
+		Str("host", request.URL.Host).
+		Msg("🪝  Running http hook")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("timed out after %s", h.httpTimeout())
+		}
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected status %s: %s", response.Status, strings.TrimSpace(string(body[:min(len(body), httpResponseLogLimit)])))
+	}
+	return body, nil
+}
+
+// httpTimeout returns how long an http hook's request may take
+func (h Hook) httpTimeout() time.Duration {
+	// an invalid timeout is refused when the config is loaded
+	if timeout, _ := h.timeout(); timeout > 0 {
+		return timeout
+	}
+	return DefaultHTTPTimeout
+}
+
+// runHTTP runs an http hook once
+func (h Hook) runHTTP(envMap map[string]string) error {
+	body, err := h.doHTTP(envMap)
+	if err != nil {
+		return fmt.Errorf("🪝 🔴 Hook %s failed: %v", h.Name, err)
+	}
+	if len(body) > 0 {
+		log.Info().Str("hook", h.Name).Msgf("🪝  %s", strings.TrimSpace(string(body[:min(len(body), httpResponseLogLimit)])))
+	}
+	log.Info().Str("hook", h.Name).Msg("🪝  Hook completed successfully")
+	return nil
+}
+
+// runHTTPCheck runs an http hook as a check once - the response body is its verdict
+func (h Hook) runHTTPCheck(envMap map[string]string) (CheckResult, error) {
+	body, err := h.doHTTP(envMap)
+	if err != nil {
+		return CheckResult{}, fmt.Errorf("🪝 🔴 Check hook %s failed: %v", h.Name, err)
+	}
+	return parseCheckOutput(h.Name, body)
+}
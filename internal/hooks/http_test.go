@@ -0,0 +1,101 @@
+package hooks
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receivedRequest is what an http hook's test server was sent
+type receivedRequest struct {
+	method  string
+	path    string
+	headers http.Header
+	body    string
+}
+
+// newHookServer returns a server answering every request with status and body, sending what it receives on the
+// returned channel
+func newHookServer(t *testing.T, status int, body string) (*httptest.Server, <-chan receivedRequest) {
+	t.Helper()
+	received := make(chan receivedRequest, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestBody, _ := io.ReadAll(r.Body)
+		received <- receivedRequest{method: r.Method, path: r.URL.Path, headers: r.Header.Clone(), body: string(requestBody)}
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+func TestHTTPHook_Request(t *testing.T) {
+	server, received := newHookServer(t, http.StatusOK, "ok")
+	hook := Hook{
+		Name:            "notify",
+		Type:            TypeHTTP,
+		URL:             server.URL + "/failover/{{ .PEER_NODE_NAME }}",
+		Method:          "put",
+		Headers:         map[string]string{"Authorization": "Bearer {{ .Token }}", "X-Failover": "yes"},
+		Body:            `{"peer": "{{ .PEER_NODE_NAME }}", "dry_run": {{ .IS_DRY_RUN_FAILOVER }}}`,
+		templateContext: map[string]any{"Token": "secret"},
+	}
+
+	require.NoError(t, hook.Run(map[string]string{"PEER_NODE_NAME": "backup", "IS_DRY_RUN_FAILOVER": "true\n"}))
+
+	request := <-received
+	assert.Equal(t, http.MethodPut, request.method)
+	assert.Equal(t, "/failover/backup", request.path)
+	assert.Equal(t, "Bearer secret", request.headers.Get("Authorization"))
+	assert.Equal(t, "yes", request.headers.Get("X-Failover"))
+	assert.Equal(t, "application/json", request.headers.Get("Content-Type"))
+	assert.JSONEq(t, `{"peer": "backup", "dry_run": true}`, request.body)
+}
+
+func TestHTTPHook_DefaultBody(t *testing.T) {
+	server, received := newHookServer(t, http.StatusNoContent, "")
+	hook := Hook{Name: "notify", Type: TypeHTTP, URL: server.URL}
+
+	require.NoError(t, hook.Run(map[string]string{"PEER_NODE_NAME": " backup\n", "IS_DRY_RUN_FAILOVER": "false"}))
+
+	request := <-received
+	assert.Equal(t, http.MethodPost, request.method)
+	var variables map[string]string
+	require.NoError(t, json.Unmarshal([]byte(request.body), &variables))
+	assert.Equal(t, map[string]string{"PEER_NODE_NAME": "backup", "IS_DRY_RUN_FAILOVER": "false"}, variables)
+}
+
+func TestHTTPHook_Non2xx(t *testing.T) {
+	server, received := newHookServer(t, http.StatusServiceUnavailable, strings.Repeat("down ", 200))
+	hook := Hook{Name: "notify", Type: TypeHTTP, URL: server.URL, Retries: 1, RetryDelay: "0s"}
+
+	err := hook.Run(nil)
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "unexpected status 503 Service Unavailable: down down")
+	// the response is cut short in the error
+	assert.Less(t, len(err.Error()), httpResponseLogLimit+100)
+	// and the hook retried
+	assert.Len(t, received, 2)
+}
+
+func TestHTTPHook_Check(t *testing.T) {
+	server, _ := newHookServer(t, http.StatusOK, `{"allow": false, "reason": "maintenance window"}`)
+	hook := Hook{Name: "gate", Type: TypeHTTP, URL: server.URL}
+
+	result, err := hook.RunCheck(nil)
+	require.NoError(t, err)
+	assert.False(t, result.Allow)
+	assert.Equal(t, "maintenance window", result.Reason)
+}
+
+func TestHTTPHook_InvalidRenderedURL(t *testing.T) {
+	hook := Hook{Name: "notify", Type: TypeHTTP, URL: "{{ .TARGET }}"}
+	err := hook.Run(map[string]string{"TARGET": "file:///etc/passwd"})
+	assert.ErrorContains(t, err, "must be an http or https url")
+}
@@ -0,0 +1,57 @@
+package hooks
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	// systemdActionPrefix starts the command of a command hook that manages systemd units instead of running a
+	// program e.g. systemd:restart jito-relayer.service
+	systemdActionPrefix = "systemd:"
+
+	// systemctlBin is what systemd actions run
+	systemctlBin = "systemctl"
+)
+
+// systemdActions are the systemd actions a hook can take on its units
+var systemdActions = []string{"restart", "stop", "start"}
+
+// systemdAction splits a command of systemd:<action> [unit...] into its action and units - units can also be given
+// as args. ok is false for a command that isn't a systemd action
+func systemdAction(command string, args []string) (action string, units []string, ok bool) {
+	if !strings.HasPrefix(command, systemdActionPrefix) {
+		return "", nil, false
+	}
+	fields := strings.Fields(strings.TrimPrefix(command, systemdActionPrefix))
+	if len(fields) == 0 {
+		return "", args, true
+	}
+	return fields[0], append(fields[1:], args...), true
+}
+
+// validateSystemdAction checks a systemd action hook's action is known and that it names the units to act on
+func (h Hook) validateSystemdAction() error {
+	action, units, ok := systemdAction(h.Command, h.Args)
+	if !ok {
+		return nil
+	}
+	if !slices.Contains(systemdActions, action) {
+		return fmt.Errorf("unknown systemd action %q - must be one of: %s", action, strings.Join(systemdActions, ", "))
+	}
+	if len(units) == 0 {
+		return fmt.Errorf("%s%s needs the units to %s", systemdActionPrefix, action, action)
+	}
+	return nil
+}
+
+// systemctlCommand returns the systemctl command and args a rendered systemd action runs - units follow -- so none
+// can be taken for an option
+func systemctlCommand(command string, args []string) (string, []string) {
+	action, units, ok := systemdAction(command, args)
+	if !ok {
+		return command, args
+	}
+	return systemctlBin, append([]string{action, "--"}, units...)
+}
@@ -0,0 +1,84 @@
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// parseTemplate parses a hook's command, arg or http request field as a go template - referencing something there is
+// no value for fails rendering rather than passing the hook an empty string
+func parseTemplate(name, text string) (*template.Template, error) {
+	tpl, err := template.New(name).Funcs(template.FuncMap{
+		"json": func(v any) (string, error) {
+			encoded, err := json.Marshal(v)
+			return string(encoded), err
+		},
+	}).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s template: %w", name, err)
+	}
+	return tpl, nil
+}
+
+// renderTemplate renders text as a template with data
+func renderTemplate(name, text string, data map[string]any) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	tpl, err := parseTemplate(name, text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s template: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// templateData returns what hook templates are rendered with - the validator fields in the hook's template context
+// e.g. {{ .Identities.Active.PubKey }} and the env variables it gets, without their SOLANA_VALIDATOR_FAILOVER_
+// prefix e.g. {{ .PEER_NODE_PUBLIC_IP }}
+func (h Hook) templateData(envMap map[string]string) map[string]any {
+	data := make(map[string]any, len(h.templateContext)+len(envMap))
+	for k, v := range h.templateContext {
+		data[k] = v
+	}
+	for k, v := range envMap {
+		data[k] = strings.TrimSpace(v)
+	}
+	return data
+}
+
+// validateCommandTemplates checks a command hook's command and args parse as templates
+func (h Hook) validateCommandTemplates() error {
+	if _, err := parseTemplate("command", h.Command); err != nil {
+		return err
+	}
+	for i, arg := range h.Args {
+		if _, err := parseTemplate(fmt.Sprintf("args[%d]", i), arg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderCommand returns the hook's command and args rendered as templates
+func (h Hook) renderCommand(envMap map[string]string) (command string, args []string, err error) {
+	data := h.templateData(envMap)
+	command, err = renderTemplate("command", h.Command, data)
+	if err != nil {
+		return "", nil, err
+	}
+	args = make([]string, len(h.Args))
+	for i, arg := range h.Args {
+		args[i], err = renderTemplate(fmt.Sprintf("args[%d]", i), arg, data)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	return command, args, nil
+}
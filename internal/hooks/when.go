@@ -0,0 +1,73 @@
+package hooks
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// errSkipped is returned running a check hook whose when conditions don't hold
+var errSkipped = errors.New("skipped")
+
+// When holds the conditions a hook only runs under - a hook with none always runs
+type When struct {
+	// OnlyRealFailover skips the hook in dry runs and OnlyDryRun in real failovers e.g. to keep a firewall change or
+	// dns flip out of drills
+	OnlyRealFailover bool `mapstructure:"only_real_failover"`
+	OnlyDryRun       bool `mapstructure:"only_dry_run"`
+	// Expression is a go template rendered like the hook's args that must render true for the hook to run e.g.
+	// {{ eq .PEER_NODE_CLIENT "firedancer" }}
+	Expression string `mapstructure:"expression"`
+}
+
+// validate checks the conditions can hold and the expression parses
+func (w When) validate() error {
+	if w.OnlyRealFailover && w.OnlyDryRun {
+		return fmt.Errorf("when.only_real_failover and when.only_dry_run can't both be set")
+	}
+	if w.Expression != "" {
+		if _, err := parseTemplate("when.expression", w.Expression); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shouldRun reports whether the hook's when conditions hold for envMap - an expression that fails to render or
+// doesn't render a bool is an error
+func (h Hook) shouldRun(envMap map[string]string) (bool, error) {
+	isDryRun := envMap["IS_DRY_RUN_FAILOVER"] == "true"
+	if h.When.OnlyRealFailover && isDryRun {
+		return false, nil
+	}
+	if h.When.OnlyDryRun && !isDryRun {
+		return false, nil
+	}
+	if h.When.Expression == "" {
+		return true, nil
+	}
+	rendered, err := renderTemplate("when.expression", h.When.Expression, h.templateData(envMap))
+	if err != nil {
+		return false, err
+	}
+	run, err := strconv.ParseBool(strings.TrimSpace(rendered))
+	if err != nil {
+		return false, fmt.Errorf("when.expression must render true or false, got %q", rendered)
+	}
+	return run, nil
+}
+
+// skip reports whether the hook is skipped for envMap, logging why when it is
+func (h Hook) skip(envMap map[string]string) (bool, error) {
+	run, err := h.shouldRun(envMap)
+	if err != nil {
+		return false, fmt.Errorf("🪝 🔴 Hook %s failed: %v", h.Name, err)
+	}
+	if !run {
+		log.Info().Str("hook", h.Name).Msg("🪝  Skipping hook - its when conditions don't hold")
+	}
+	return !run, nil
+}
@@ -0,0 +1,57 @@
+package hooks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldRun(t *testing.T) {
+	dryRun := map[string]string{"IS_DRY_RUN_FAILOVER": "true", "PEER_NODE_CLIENT": "firedancer"}
+	realFailover := map[string]string{"IS_DRY_RUN_FAILOVER": "false", "PEER_NODE_CLIENT": "agave"}
+
+	tests := []struct {
+		name    string
+		when    When
+		envMap  map[string]string
+		wantRun bool
+		wantErr string
+	}{
+		{"no conditions", When{}, dryRun, true, ""},
+		{"only real failover in a dry run", When{OnlyRealFailover: true}, dryRun, false, ""},
+		{"only real failover in a real failover", When{OnlyRealFailover: true}, realFailover, true, ""},
+		{"only dry run in a dry run", When{OnlyDryRun: true}, dryRun, true, ""},
+		{"only dry run in a real failover", When{OnlyDryRun: true}, realFailover, false, ""},
+		{"only dry run without the variable", When{OnlyDryRun: true}, map[string]string{}, false, ""},
+		{"expression true", When{Expression: `{{ eq .PEER_NODE_CLIENT "firedancer" }}`}, dryRun, true, ""},
+		{"expression false", When{Expression: `{{ eq .PEER_NODE_CLIENT "firedancer" }}`}, realFailover, false, ""},
+		{"expression with surrounding whitespace", When{Expression: ` {{ true }}` + "\n"}, dryRun, true, ""},
+		{"expression not a bool", When{Expression: `{{ .PEER_NODE_CLIENT }}`}, dryRun, false, `must render true or false, got "firedancer"`},
+		{"expression of a missing variable", When{Expression: `{{ .MISSING }}`}, dryRun, false, "failed to render when.expression template"},
+		{"expression checked after the dry run condition", When{OnlyRealFailover: true, Expression: `{{ .MISSING }}`}, dryRun, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run, err := Hook{When: tt.when}.shouldRun(tt.envMap)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRun, run)
+		})
+	}
+}
+
+func TestWhenValidate(t *testing.T) {
+	assert.NoError(t, When{OnlyDryRun: true, Expression: "{{ true }}"}.validate())
+	assert.ErrorContains(t, When{OnlyRealFailover: true, OnlyDryRun: true}.validate(), "can't both be set")
+	assert.ErrorContains(t, When{Expression: "{{ if }}"}.validate(), "failed to parse when.expression template")
+}
+
+func TestRunCheck_Skipped(t *testing.T) {
+	_, err := Hook{Name: "gate", Command: "false", When: When{OnlyRealFailover: true}}.RunCheck(map[string]string{"IS_DRY_RUN_FAILOVER": "true"})
+	assert.ErrorIs(t, err, errSkipped)
+}
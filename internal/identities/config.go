@@ -1,8 +1,72 @@
 package identities
 
+import (
+	"fmt"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+)
+
 // Config holds the configuration for the identities this validator can assume
 // depending on the role it is assigned
 type Config struct {
 	Active  string `mapstructure:"active"`
 	Passive string `mapstructure:"passive"`
+	// RemoteSigner signs for the identities given by pubkey in it rather than by keypair file
+	RemoteSigner RemoteSignerConfig `mapstructure:"remote_signer"`
+	// Passphrase is where the passphrase of keypair files encrypted with age -p comes from
+	Passphrase PassphraseConfig `mapstructure:"passphrase"`
+}
+
+// RemoteSignerConfig holds the configuration for a remote signer holding identity keys off this host
+type RemoteSignerConfig struct {
+	// URL is the signer's http(s):// endpoint or unix:///path/to.sock for a local agent
+	URL string `mapstructure:"url"`
+	// TokenFile holds a bearer token sent with every request - empty sends none
+	TokenFile string `mapstructure:"token_file"`
+	Timeout   string `mapstructure:"timeout"`
+	// ActivePubkey and PassivePubkey are the identities the signer holds for this validator - set in place of
+	// active and passive
+	ActivePubkey  string `mapstructure:"active_pubkey"`
+	PassivePubkey string `mapstructure:"passive_pubkey"`
+}
+
+// Check returns the problems with the config that can be found without reading keypair files or reaching the signer
+func (c Config) Check() (problems []error) {
+	for _, identity := range []struct{ key, file, pubkey string }{
+		{"active", c.Active, c.RemoteSigner.ActivePubkey},
+		{"passive", c.Passive, c.RemoteSigner.PassivePubkey},
+	} {
+		switch {
+		case identity.file == "" && identity.pubkey == "":
+			problems = append(problems, fmt.Errorf("identities.%s is required - or identities.remote_signer.%s_pubkey to sign remotely", identity.key, identity.key))
+		case identity.file != "" && identity.pubkey != "":
+			problems = append(problems, fmt.Errorf("identities.%s and identities.remote_signer.%s_pubkey can't both be set", identity.key, identity.key))
+		case identity.pubkey != "":
+			if _, err := solana.PublicKeyFromBase58(identity.pubkey); err != nil {
+				problems = append(problems, fmt.Errorf("invalid identities.remote_signer.%s_pubkey %s: %w", identity.key, identity.pubkey, err))
+			}
+		}
+	}
+
+	if c.Passphrase.Env != "" && c.Passphrase.Command != "" {
+		problems = append(problems, fmt.Errorf("identities.passphrase.env and identities.passphrase.command can't both be set"))
+	}
+
+	if c.RemoteSigner.ActivePubkey == "" && c.RemoteSigner.PassivePubkey == "" {
+		return problems
+	}
+	if c.RemoteSigner.URL == "" {
+		problems = append(problems, fmt.Errorf("identities.remote_signer.url is required to sign remotely"))
+	} else if _, err := remoteSignerEndpoint(c.RemoteSigner.URL); err != nil {
+		problems = append(problems, err)
+	}
+	if c.RemoteSigner.Timeout != "" {
+		if timeout, err := time.ParseDuration(c.RemoteSigner.Timeout); err != nil {
+			problems = append(problems, fmt.Errorf("failed to parse identities.remote_signer.timeout %s: %w", c.RemoteSigner.Timeout, err))
+		} else if timeout <= 0 {
+			problems = append(problems, fmt.Errorf("identities.remote_signer.timeout must be positive, got %s", c.RemoteSigner.Timeout))
+		}
+	}
+	return problems
 }
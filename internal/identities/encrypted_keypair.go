@@ -0,0 +1,57 @@
+package identities
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+const (
+	// ageHeaderLine starts every binary age file
+	ageHeaderLine = "age-encryption.org/v1"
+	// ageMaxScryptWorkFactor caps the scrypt work factor a file may ask for, as age does, so a crafted file can't
+	// make decrypting it take minutes
+	ageMaxScryptWorkFactor = 22
+)
+
+// ErrPassphraseRequired is returned when a keypair file is encrypted and there is no passphrase to decrypt it with
+var ErrPassphraseRequired = errors.New("keypair file is encrypted - set identities.passphrase or run in a terminal to be prompted for it")
+
+// isEncryptedKeypair reports whether keypair file content is an age-encrypted file, binary or ascii-armored
+func isEncryptedKeypair(content []byte) bool {
+	trimmed := bytes.TrimSpace(content)
+	return bytes.HasPrefix(trimmed, []byte(ageHeaderLine+"\n")) || bytes.HasPrefix(trimmed, []byte(armor.Header))
+}
+
+// decryptKeypair decrypts an age file encrypted with a passphrase (age -p) - the only recipient type accepted, as a
+// keypair file protected by another key would just move the problem
+func decryptKeypair(content []byte, passphrase []byte) ([]byte, error) {
+	var src io.Reader = bytes.NewReader(content)
+	if bytes.HasPrefix(bytes.TrimSpace(content), []byte(armor.Header)) {
+		src = armor.NewReader(src)
+	}
+
+	identity, err := age.NewScryptIdentity(string(passphrase))
+	if err != nil {
+		return nil, err
+	}
+	identity.SetMaxWorkFactor(ageMaxScryptWorkFactor)
+
+	plaintext, err := age.Decrypt(src, identity)
+	if noMatch := (*age.NoIdentityMatchError)(nil); errors.As(err, &noMatch) {
+		return nil, fmt.Errorf("wrong passphrase, or the keypair file isn't passphrase-encrypted - encrypt it with age -p")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keypair file: %w", err)
+	}
+
+	decrypted, err := io.ReadAll(plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt age payload: %w", err)
+	}
+	return decrypted, nil
+}
@@ -0,0 +1,198 @@
+package identities
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ageEncrypt encrypts plaintext with passphrase as age -p does, with a low work factor to keep tests fast
+func ageEncrypt(t *testing.T, plaintext, passphrase []byte) []byte {
+	t.Helper()
+	recipient, err := age.NewScryptRecipient(string(passphrase))
+	require.NoError(t, err)
+	recipient.SetWorkFactor(10)
+	return ageEncryptTo(t, plaintext, recipient)
+}
+
+// ageEncryptTo encrypts plaintext to recipient
+func ageEncryptTo(t *testing.T, plaintext []byte, recipient age.Recipient) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	w, err := age.Encrypt(&out, recipient)
+	require.NoError(t, err)
+	_, err = w.Write(plaintext)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return out.Bytes()
+}
+
+// writeEncryptedKeyFile writes key as a keygen file encrypted with passphrase and returns its path
+func writeEncryptedKeyFile(t *testing.T, key solana.PrivateKey, passphrase string) string {
+	t.Helper()
+	keyData, err := json.Marshal(toJSONBytes(key))
+	require.NoError(t, err)
+	keyFile := filepath.Join(t.TempDir(), "key.json.age")
+	require.NoError(t, os.WriteFile(keyFile, ageEncrypt(t, keyData, []byte(passphrase)), 0600))
+	return keyFile
+}
+
+// writeTestKeyFile writes key as a plain keygen file and returns its path
+func writeTestKeyFile(t *testing.T, key solana.PrivateKey) string {
+	t.Helper()
+	keyData, err := json.Marshal(toJSONBytes(key))
+	require.NoError(t, err)
+	keyFile := filepath.Join(t.TempDir(), "key.json")
+	require.NoError(t, os.WriteFile(keyFile, keyData, 0600))
+	return keyFile
+}
+
+func TestDecryptKeypair_RoundTrip(t *testing.T) {
+	plaintext := []byte(`[1,2,3]`)
+	encrypted := ageEncrypt(t, plaintext, []byte("correct horse"))
+	require.True(t, isEncryptedKeypair(encrypted))
+
+	decrypted, err := decryptKeypair(encrypted, []byte("correct horse"))
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	// ascii-armored as age -a writes it
+	var armored bytes.Buffer
+	w := armor.NewWriter(&armored)
+	_, err = w.Write(encrypted)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.True(t, isEncryptedKeypair(armored.Bytes()))
+	decrypted, err = decryptKeypair(armored.Bytes(), []byte("correct horse"))
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+}
+
+func TestDecryptKeypair_MultipleChunks(t *testing.T) {
+	plaintext := make([]byte, 2*64<<10+10)
+	_, err := rand.Read(plaintext)
+	require.NoError(t, err)
+
+	decrypted, err := decryptKeypair(ageEncrypt(t, plaintext, []byte("pass")), []byte("pass"))
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+}
+
+func TestDecryptKeypair_WrongPassphrase(t *testing.T) {
+	_, err := decryptKeypair(ageEncrypt(t, []byte(`[1]`), []byte("right")), []byte("wrong"))
+	assert.ErrorContains(t, err, "wrong passphrase")
+}
+
+func TestDecryptKeypair_Altered(t *testing.T) {
+	encrypted := ageEncrypt(t, []byte(`[1,2,3]`), []byte("pass"))
+
+	// a flipped payload byte fails authentication
+	payloadAltered := append([]byte(nil), encrypted...)
+	payloadAltered[len(payloadAltered)-1] ^= 1
+	_, err := decryptKeypair(payloadAltered, []byte("pass"))
+	assert.ErrorContains(t, err, "failed to decrypt age payload")
+
+	// as does a truncated one
+	_, err = decryptKeypair(encrypted[:len(encrypted)-20], []byte("pass"))
+	assert.Error(t, err)
+
+	// and an altered header
+	headerAltered := bytes.Replace(encrypted, []byte("scrypt "), []byte("scrypt  "), 1)
+	_, err = decryptKeypair(headerAltered, []byte("pass"))
+	assert.Error(t, err)
+}
+
+func TestDecryptKeypair_WorkFactorTooLarge(t *testing.T) {
+	// a file asking for more scrypt work than is allowed is refused before any is done
+	encrypted := ageEncrypt(t, []byte(`[1]`), []byte("pass"))
+	crafted := regexp.MustCompile(`(-> scrypt \S+) 10\n`).ReplaceAll(encrypted, []byte(fmt.Sprintf("$1 %d\n", ageMaxScryptWorkFactor+1)))
+	require.NotEqual(t, encrypted, crafted)
+
+	_, err := decryptKeypair(crafted, []byte("pass"))
+	assert.ErrorContains(t, err, "work factor too large")
+}
+
+func TestDecryptKeypair_RejectsOtherRecipients(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	content := ageEncryptTo(t, []byte(`[1]`), identity.Recipient())
+	require.True(t, isEncryptedKeypair(content))
+	_, err = decryptKeypair(content, []byte("pass"))
+	assert.ErrorContains(t, err, "encrypt it with age -p")
+}
+
+func TestIsEncryptedKeypair_PlainKeygenFile(t *testing.T) {
+	assert.False(t, isEncryptedKeypair([]byte(`[1,2,3]`)))
+}
+
+func TestNewIdentityFromFile_EncryptedNeedsPassphrase(t *testing.T) {
+	keyFile := writeEncryptedKeyFile(t, solana.NewWallet().PrivateKey, "pass")
+
+	_, err := NewIdentityFromFile(keyFile)
+	assert.ErrorIs(t, err, ErrPassphraseRequired)
+}
+
+func TestNewFromConfig_EncryptedKeyFiles(t *testing.T) {
+	activeKey := solana.NewWallet().PrivateKey
+	passiveKey := solana.NewWallet().PrivateKey
+	t.Setenv("TEST_IDENTITY_PASSPHRASE", "pass")
+
+	identities, err := NewFromConfig(&Config{
+		Active:     writeEncryptedKeyFile(t, activeKey, "pass"),
+		Passive:    writeEncryptedKeyFile(t, passiveKey, "pass"),
+		Passphrase: PassphraseConfig{Env: "TEST_IDENTITY_PASSPHRASE"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, activeKey.String(), identities.Active.Key.String())
+	assert.Equal(t, passiveKey.String(), identities.Passive.Key.String())
+	assert.True(t, identities.Active.Encrypted)
+
+	// set identity gets the decrypted keypair as a keygen file would hold it
+	var keyBytes []byte
+	require.NoError(t, json.Unmarshal(identities.Active.DecryptedKeypair(), &keyBytes))
+	assert.Equal(t, []byte(activeKey), keyBytes)
+}
+
+func TestNewFromConfig_PassphraseCommand(t *testing.T) {
+	activeKey := solana.NewWallet().PrivateKey
+
+	identities, err := NewFromConfig(&Config{
+		Active:     writeEncryptedKeyFile(t, activeKey, "from-command"),
+		Passive:    writeTestKeyFile(t, solana.NewWallet().PrivateKey),
+		Passphrase: PassphraseConfig{Command: "echo from-command"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, activeKey.String(), identities.Active.Key.String())
+	assert.False(t, identities.Passive.Encrypted)
+	assert.Nil(t, identities.Passive.DecryptedKeypair())
+}
+
+func TestNewFromConfig_PassphraseEnvUnset(t *testing.T) {
+	_, err := NewFromConfig(&Config{
+		Active:     writeEncryptedKeyFile(t, solana.NewWallet().PrivateKey, "pass"),
+		Passive:    writeTestKeyFile(t, solana.NewWallet().PrivateKey),
+		Passphrase: PassphraseConfig{Env: "TEST_IDENTITY_PASSPHRASE_UNSET"},
+	})
+	assert.ErrorContains(t, err, "TEST_IDENTITY_PASSPHRASE_UNSET from identities.passphrase.env is empty")
+}
+
+func TestConfigCheck_PassphraseSources(t *testing.T) {
+	problems := Config{
+		Active:     "/path/to/active.json",
+		Passive:    "/path/to/passive.json",
+		Passphrase: PassphraseConfig{Env: "PASSPHRASE", Command: "cat /run/passphrase"},
+	}.Check()
+	require.Len(t, problems, 1)
+	assert.ErrorContains(t, problems[0], "identities.passphrase.env and identities.passphrase.command can't both be set")
+}
@@ -1,8 +1,10 @@
 package identities
 
 import (
+	"errors"
 	"fmt"
 
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
@@ -12,35 +14,94 @@ type Identities struct {
 	Passive *Identity
 }
 
-// NewFromConfig creates a new identities from a config
+// NewFromConfig creates a new identities from a config - each is read from its keypair file, decrypted with the
+// configured passphrase when it is encrypted, or, when given by pubkey under remote_signer, signed for by the remote
+// signer
 func NewFromConfig(cfg *Config) (identities *Identities, err error) {
 	logger := log.With().Str("component", "identities").Logger()
 	identities = &Identities{}
 
-	// load active identity
-	logger.Debug().
-		Str("file", cfg.Active).
-		Msg("loading active identity")
+	if problems := cfg.Check(); len(problems) > 0 {
+		return nil, errors.Join(problems...)
+	}
 
-	identities.Active, err = NewIdentityFromFile(cfg.Active)
+	var remoteSignerClient *RemoteSignerClient
+	if cfg.RemoteSigner.ActivePubkey != "" || cfg.RemoteSigner.PassivePubkey != "" {
+		remoteSignerClient, err = NewRemoteSignerClient(cfg.RemoteSigner)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	passphrase := &passphraseSource{config: cfg.Passphrase}
+
+	// load active identity
+	identities.Active, err = newIdentity(logger, "active", cfg.Active, passphrase, remoteSignerClient, cfg.RemoteSigner.ActivePubkey)
 	if err != nil {
 		return nil, err
 	}
 
 	// load passive identity
-	logger.Debug().
-		Str("file", cfg.Passive).
-		Msg("loading passive identity")
-
-	identities.Passive, err = NewIdentityFromFile(cfg.Passive)
+	identities.Passive, err = newIdentity(logger, "passive", cfg.Passive, passphrase, remoteSignerClient, cfg.RemoteSigner.PassivePubkey)
 	if err != nil {
 		return nil, err
 	}
 
 	// public keys must be different
-	if identities.Active.Key.PublicKey() == identities.Passive.Key.PublicKey() {
+	if identities.Active.PublicKey() == identities.Passive.PublicKey() {
 		return nil, fmt.Errorf("active and passive identities must be different")
 	}
 
 	return
 }
+
+// newIdentity loads the role's identity from keyFile or, when pubkey is set, the remote signer
+func newIdentity(logger zerolog.Logger, role, keyFile string, passphrase *passphraseSource, remoteSignerClient *RemoteSignerClient, pubkey string) (*Identity, error) {
+	if pubkey != "" {
+		logger.Debug().
+			Str("pubkey", pubkey).
+			Msgf("loading %s identity from remote signer", role)
+		identity, err := NewIdentityFromRemoteSigner(remoteSignerClient, pubkey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s identity: %w", role, err)
+		}
+		return identity, nil
+	}
+
+	logger.Debug().
+		Str("file", keyFile).
+		Msgf("loading %s identity", role)
+	return newIdentityFromFile(keyFile, passphrase)
+}
+
+// PublicIdentity is what may be shown of an identity - its pubkey and keypair file, never its key or signer
+type PublicIdentity struct {
+	KeyFile string
+	PubKey  string
+}
+
+// PublicIdentities are what may be shown of the identities e.g. to hook templates, whose output ends up in argv,
+// http requests and logs
+type PublicIdentities struct {
+	Active  PublicIdentity
+	Passive PublicIdentity
+}
+
+// Public returns what may be shown of the identities - empty when there are none
+func (i *Identities) Public() PublicIdentities {
+	if i == nil {
+		return PublicIdentities{}
+	}
+	return PublicIdentities{
+		Active:  i.Active.public(),
+		Passive: i.Passive.public(),
+	}
+}
+
+// public returns what may be shown of the identity - empty when there is none
+func (i *Identity) public() PublicIdentity {
+	if i == nil {
+		return PublicIdentity{}
+	}
+	return PublicIdentity{KeyFile: i.KeyFile, PubKey: i.PubKey()}
+}
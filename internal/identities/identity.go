@@ -1,21 +1,35 @@
 package identities
 
 import (
+	"crypto/ed25519"
+	"encoding/json"
 	"fmt"
+	"os"
 
 	"github.com/gagliardetto/solana-go"
 	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/deprecation"
 	"github.com/sol-strategies/solana-validator-failover/internal/utils"
 )
 
 // Identity holds the information for an identity
 type Identity struct {
-	KeyFile string // path to the identity key file
+	KeyFile string // path to the identity key file - empty when a remote signer holds the key
 	Key     solana.PrivateKey
+	// Signer signs in place of Key when a remote signer holds the key
+	Signer Signer
+	// Encrypted is set when KeyFile is passphrase-encrypted - set identity commands can't read it
+	Encrypted bool
 }
 
-// NewIdentityFromFile Identity from a key file
+// NewIdentityFromFile Identity from a key file - an encrypted one returns ErrPassphraseRequired
 func NewIdentityFromFile(keyFile string) (identity *Identity, err error) {
+	return newIdentityFromFile(keyFile, nil)
+}
+
+// newIdentityFromFile creates an Identity from a key file, decrypting it with the passphrase from passphrase when it
+// is encrypted
+func newIdentityFromFile(keyFile string, passphrase *passphraseSource) (identity *Identity, err error) {
 	logger := log.With().Str("component", "identities").Logger()
 	// resolve path
 	keyFileAbsolutePath, err := utils.ResolvePath(keyFile)
@@ -31,7 +45,7 @@ func NewIdentityFromFile(keyFile string) (identity *Identity, err error) {
 		Str("file", keyFileAbsolutePath).
 		Msg("reading solana keygen file")
 
-	identity.Key, err = solana.PrivateKeyFromSolanaKeygenFile(keyFileAbsolutePath)
+	identity.Key, identity.Encrypted, err = readKeygenFile(keyFileAbsolutePath, passphrase)
 	if err != nil {
 		err = fmt.Errorf("failed to parse keygen file: %w", err)
 		return
@@ -40,19 +54,113 @@ func NewIdentityFromFile(keyFile string) (identity *Identity, err error) {
 	logger.Debug().
 		Str("pubkey", identity.Key.PublicKey().String()).
 		Str("file", keyFileAbsolutePath).
+		Bool("encrypted", identity.Encrypted).
 		Msg("parsed solana keygen file")
 
 	return identity, nil
 }
 
+// readKeygenFile reads the private key from a solana keygen file, decrypting it first when it is encrypted
+func readKeygenFile(keyFile string, passphrase *passphraseSource) (key solana.PrivateKey, encrypted bool, err error) {
+	content, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, false, err
+	}
+
+	encrypted = isEncryptedKeypair(content)
+	if encrypted {
+		secret, err := passphrase.get(keyFile)
+		if err != nil {
+			return nil, encrypted, err
+		}
+		content, err = decryptKeypair(content, secret)
+		if err != nil {
+			return nil, encrypted, fmt.Errorf("failed to decrypt: %w", err)
+		}
+	}
+
+	var keyBytes []byte
+	if err := json.Unmarshal(content, &keyBytes); err != nil {
+		return nil, encrypted, err
+	}
+	if len(keyBytes) != ed25519.PrivateKeySize {
+		return nil, encrypted, fmt.Errorf("want %d key bytes, got %d", ed25519.PrivateKeySize, len(keyBytes))
+	}
+	return solana.PrivateKey(keyBytes), encrypted, nil
+}
+
+// DecryptedKeypair returns the keypair in solana keygen file format for an identity whose keypair file is encrypted,
+// to be passed to set identity on stdin - nil when the keypair file can be read as it is
+func (i *Identity) DecryptedKeypair() []byte {
+	if !i.Encrypted {
+		return nil
+	}
+	keypair, _ := json.Marshal(toJSONBytes(i.Key))
+	return keypair
+}
+
+// toJSONBytes widens key bytes so they marshal as a json array of numbers rather than base64
+func toJSONBytes(key []byte) []int {
+	values := make([]int, len(key))
+	for i, b := range key {
+		values[i] = int(b)
+	}
+	return values
+}
+
 // Pubkey returns the public key of the identity - prefer its PascalCase counterpart PubKey
 func (i *Identity) Pubkey() string {
-	log.Warn().Msg("Pubkey is deprecated (but still works) in favour of PubKey - using it for you...")
+	deprecation.Use("Identity.Pubkey", "Pubkey is deprecated (but still works) in favour of PubKey - using it for you...")
 	return i.PubKey()
 }
 
 // PubKey is the PascalCase counterpart of Pubkey - it's what we should have always used but let's be honest about why not:
 // @coderigo messed up in the early README and claimed PubKey was supported when it was really Pubkey
 func (i *Identity) PubKey() string {
-	return i.Key.PublicKey().String()
+	return i.PublicKey().String()
+}
+
+// PublicKey returns the identity's public key
+func (i *Identity) PublicKey() solana.PublicKey {
+	if i.Signer != nil {
+		return i.Signer.PublicKey()
+	}
+	return i.Key.PublicKey()
+}
+
+// IsRemote reports whether a remote signer holds the identity's key - there is no keypair file on this host
+func (i *Identity) IsRemote() bool {
+	return i.Signer != nil
+}
+
+// sign signs message with the identity key or has the remote signer sign it
+func (i *Identity) sign(message []byte) (solana.Signature, error) {
+	if i.Signer != nil {
+		return i.Signer.Sign(message)
+	}
+	return i.Key.Sign(message)
+}
+
+// SignChallenge signs a peer's authentication challenge with the identity key, proving this node holds it - context
+// separates the roles signing so a signature can't be replayed as the other side's
+func (i *Identity) SignChallenge(context string, challenge []byte) (signature []byte, err error) {
+	sig, err := i.sign(challengePayload(context, challenge))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign challenge: %w", err)
+	}
+	return sig[:], nil
+}
+
+// VerifyChallenge reports whether signature is this identity's signature of the challenge in context - a peer
+// holding the same keypair produces one that verifies
+func (i *Identity) VerifyChallenge(context string, challenge, signature []byte) bool {
+	if len(signature) != solana.SignatureLength {
+		return false
+	}
+	return solana.SignatureFromBytes(signature).Verify(i.PublicKey(), challengePayload(context, challenge))
+}
+
+// challengePayload returns the bytes signed for a challenge in context
+func challengePayload(context string, challenge []byte) []byte {
+	return append([]byte(context+":"), challenge...)
 }
@@ -365,3 +365,31 @@ func BenchmarkIdentity_Pubkey(b *testing.B) {
 		_ = identity.PubKey()
 	}
 }
+
+func TestIdentity_SignChallenge_VerifiesWithSameKey(t *testing.T) {
+	privateKey := solana.NewWallet().PrivateKey
+	signer := &Identity{Key: privateKey}
+	verifier := &Identity{Key: privateKey}
+	challenge := []byte("nonce")
+
+	signature, err := signer.SignChallenge("client", challenge)
+
+	require.NoError(t, err)
+	assert.True(t, verifier.VerifyChallenge("client", challenge, signature))
+}
+
+func TestIdentity_VerifyChallenge_Rejects(t *testing.T) {
+	identity := &Identity{Key: solana.NewWallet().PrivateKey}
+	other := &Identity{Key: solana.NewWallet().PrivateKey}
+	challenge := []byte("nonce")
+
+	signature, err := identity.SignChallenge("client", challenge)
+	require.NoError(t, err)
+	otherSignature, err := other.SignChallenge("client", challenge)
+	require.NoError(t, err)
+
+	assert.False(t, identity.VerifyChallenge("server", challenge, signature), "different context")
+	assert.False(t, identity.VerifyChallenge("client", []byte("other nonce"), signature), "different challenge")
+	assert.False(t, identity.VerifyChallenge("client", challenge, otherSignature), "different key")
+	assert.False(t, identity.VerifyChallenge("client", challenge, signature[:10]), "truncated signature")
+}
@@ -0,0 +1,73 @@
+package identities
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// PassphraseConfig holds where the passphrase of encrypted keypair files comes from - with neither set it is
+// prompted for when this program runs in a terminal
+type PassphraseConfig struct {
+	// Env is the name of an environment variable holding the passphrase
+	Env string `mapstructure:"env"`
+	// Command prints the passphrase on stdout e.g. a secrets manager's cli - it is split on spaces, not run in a shell
+	Command string `mapstructure:"command"`
+}
+
+// passphraseSource gets the passphrase once and keeps it for the other keypair file - both identities are expected
+// to be encrypted with the same one
+type passphraseSource struct {
+	config     PassphraseConfig
+	passphrase []byte
+}
+
+// get returns the passphrase, prompting for it on the terminal for keyFile when no source is configured
+func (p *passphraseSource) get(keyFile string) ([]byte, error) {
+	if p == nil {
+		return nil, ErrPassphraseRequired
+	}
+	if p.passphrase != nil {
+		return p.passphrase, nil
+	}
+
+	var passphrase []byte
+	switch {
+	case p.config.Env != "":
+		value, ok := os.LookupEnv(p.config.Env)
+		if !ok || value == "" {
+			return nil, fmt.Errorf("keypair file is encrypted but %s from identities.passphrase.env is empty", p.config.Env)
+		}
+		passphrase = []byte(value)
+	case p.config.Command != "":
+		commandSlice := strings.Fields(p.config.Command)
+		var stderr bytes.Buffer
+		cmd := exec.Command(commandSlice[0], commandSlice[1:]...)
+		cmd.Stderr = &stderr
+		output, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("identities.passphrase.command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		passphrase = bytes.TrimRight(output, "\r\n")
+	case term.IsTerminal(int(os.Stdin.Fd())):
+		fmt.Fprintf(os.Stderr, "Passphrase for %s: ", keyFile)
+		value, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read passphrase: %w", err)
+		}
+		passphrase = value
+	default:
+		return nil, ErrPassphraseRequired
+	}
+
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("keypair file passphrase is empty")
+	}
+	p.passphrase = passphrase
+	return passphrase, nil
+}
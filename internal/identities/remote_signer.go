@@ -0,0 +1,219 @@
+package identities
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/sol-strategies/solana-validator-failover/internal/utils"
+)
+
+// DefaultRemoteSignerTimeout is how long a request to the remote signer may take when identities.remote_signer.timeout
+// isn't set
+const DefaultRemoteSignerTimeout = 5 * time.Second
+
+// remoteSignerUnixHost stands in for the host of requests sent over a unix socket
+const remoteSignerUnixHost = "remote-signer"
+
+// Signer signs with an identity's key wherever it is kept
+type Signer interface {
+	PublicKey() solana.PublicKey
+	Sign(message []byte) (solana.Signature, error)
+}
+
+// RemoteSignerPubkeysResponse is the remote signer's answer to GET <url>/pubkeys
+type RemoteSignerPubkeysResponse struct {
+	Pubkeys []string `json:"pubkeys"`
+}
+
+// RemoteSignerSignRequest is POSTed to <url>/sign for the signer to sign the base64 Message with Pubkey's key
+type RemoteSignerSignRequest struct {
+	Pubkey  string `json:"pubkey"`
+	Message string `json:"message"`
+}
+
+// RemoteSignerSignResponse is the remote signer's base58 signature of a RemoteSignerSignRequest
+type RemoteSignerSignResponse struct {
+	Signature string `json:"signature"`
+}
+
+// RemoteSignerClient talks to a remote signer over http(s) or a unix socket
+type RemoteSignerClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// remoteSigner signs with the key the remote signer holds for pubkey
+type remoteSigner struct {
+	pubkey solana.PublicKey
+	client *RemoteSignerClient
+}
+
+// NewRemoteSignerClient creates a client for the remote signer in cfg
+func NewRemoteSignerClient(cfg RemoteSignerConfig) (*RemoteSignerClient, error) {
+	timeout := DefaultRemoteSignerTimeout
+	if cfg.Timeout != "" {
+		var err error
+		timeout, err = time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse identities.remote_signer.timeout %s: %w", cfg.Timeout, err)
+		}
+	}
+
+	endpoint, err := remoteSignerEndpoint(cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+	client := &RemoteSignerClient{
+		baseURL:    strings.TrimSuffix(endpoint.String(), "/"),
+		httpClient: &http.Client{Timeout: timeout},
+	}
+	if endpoint.Scheme == "unix" {
+		socket := endpoint.Path
+		client.baseURL = "http://" + remoteSignerUnixHost
+		client.httpClient.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+			},
+		}
+	}
+
+	if cfg.TokenFile != "" {
+		tokenFile, err := utils.ResolvePath(cfg.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid identities.remote_signer.token_file %s: %w", cfg.TokenFile, err)
+		}
+		token, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read identities.remote_signer.token_file: %w", err)
+		}
+		client.token = strings.TrimSpace(string(token))
+	}
+	return client, nil
+}
+
+// NewIdentityFromRemoteSigner returns the identity the remote signer holds the key of pubkey for - the signer must
+// list it
+func NewIdentityFromRemoteSigner(client *RemoteSignerClient, pubkey string) (*Identity, error) {
+	publicKey, err := solana.PublicKeyFromBase58(pubkey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote signer pubkey %s: %w", pubkey, err)
+	}
+	pubkeys, err := client.Pubkeys()
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(pubkeys, pubkey) {
+		return nil, fmt.Errorf("remote signer %s doesn't hold %s", client.baseURL, pubkey)
+	}
+	return &Identity{Signer: &remoteSigner{pubkey: publicKey, client: client}}, nil
+}
+
+// Pubkeys returns the pubkeys the remote signer holds keys for
+func (c *RemoteSignerClient) Pubkeys() ([]string, error) {
+	var response RemoteSignerPubkeysResponse
+	if err := c.do(http.MethodGet, "/pubkeys", nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Pubkeys, nil
+}
+
+// Sign has the remote signer sign message with pubkey's key - the signature is verified before it is returned so a
+// misbehaving signer can't hand out one for another key
+func (c *RemoteSignerClient) Sign(pubkey solana.PublicKey, message []byte) (solana.Signature, error) {
+	var response RemoteSignerSignResponse
+	err := c.do(http.MethodPost, "/sign", RemoteSignerSignRequest{
+		Pubkey:  pubkey.String(),
+		Message: base64.StdEncoding.EncodeToString(message),
+	}, &response)
+	if err != nil {
+		return solana.Signature{}, err
+	}
+	signature, err := solana.SignatureFromBase58(response.Signature)
+	if err != nil {
+		return solana.Signature{}, fmt.Errorf("remote signer returned an invalid signature: %w", err)
+	}
+	if !signature.Verify(pubkey, message) {
+		return solana.Signature{}, fmt.Errorf("remote signer returned a signature that doesn't verify against %s", pubkey)
+	}
+	return signature, nil
+}
+
+// do sends a request to the remote signer and decodes its json response into response
+func (c *RemoteSignerClient) do(method, path string, request, response any) error {
+	var body io.Reader
+	if request != nil {
+		requestBytes, err := json.Marshal(request)
+		if err != nil {
+			return fmt.Errorf("failed to encode remote signer request: %w", err)
+		}
+		body = bytes.NewReader(requestBytes)
+	}
+	httpRequest, err := http.NewRequest(method, c.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create remote signer request: %w", err)
+	}
+	if request != nil {
+		httpRequest.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		httpRequest.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	httpResponse, err := c.httpClient.Do(httpRequest)
+	if err != nil {
+		return fmt.Errorf("remote signer %s %s failed: %w", method, path, err)
+	}
+	defer httpResponse.Body.Close()
+	if httpResponse.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(httpResponse.Body, 512))
+		return fmt.Errorf("remote signer %s %s returned %s: %s", method, path, httpResponse.Status, strings.TrimSpace(string(message)))
+	}
+	if err := json.NewDecoder(httpResponse.Body).Decode(response); err != nil {
+		return fmt.Errorf("failed to decode remote signer %s %s response: %w", method, path, err)
+	}
+	return nil
+}
+
+// PublicKey returns the pubkey the remote signer signs for
+func (s *remoteSigner) PublicKey() solana.PublicKey {
+	return s.pubkey
+}
+
+// Sign has the remote signer sign message
+func (s *remoteSigner) Sign(message []byte) (solana.Signature, error) {
+	return s.client.Sign(s.pubkey, message)
+}
+
+// remoteSignerEndpoint parses a remote signer url - http, https or unix with the socket's absolute path
+func remoteSignerEndpoint(rawURL string) (*url.URL, error) {
+	endpoint, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid identities.remote_signer.url %s: %w", rawURL, err)
+	}
+	switch endpoint.Scheme {
+	case "http", "https":
+		if endpoint.Host == "" {
+			return nil, fmt.Errorf("invalid identities.remote_signer.url %s: missing host", rawURL)
+		}
+	case "unix":
+		if endpoint.Path == "" {
+			return nil, fmt.Errorf("invalid identities.remote_signer.url %s: missing socket path e.g. unix:///run/signer.sock", rawURL)
+		}
+	default:
+		return nil, fmt.Errorf("invalid identities.remote_signer.url %s: scheme must be http, https or unix", rawURL)
+	}
+	return endpoint, nil
+}
@@ -0,0 +1,139 @@
+package identities
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRemoteSigner serves the remote signer api for keys, requiring token when set - signWith signs in place of
+// the requested key when set
+func newTestRemoteSigner(t *testing.T, token string, keys []solana.PrivateKey, signWith solana.PrivateKey) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token != "" && r.Header.Get("Authorization") != "Bearer "+token {
+			http.Error(w, "bad token", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/pubkeys":
+			response := RemoteSignerPubkeysResponse{}
+			for _, key := range keys {
+				response.Pubkeys = append(response.Pubkeys, key.PublicKey().String())
+			}
+			_ = json.NewEncoder(w).Encode(response)
+		case "/sign":
+			var request RemoteSignerSignRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			message, err := base64.StdEncoding.DecodeString(request.Message)
+			require.NoError(t, err)
+			for _, key := range keys {
+				if key.PublicKey().String() != request.Pubkey {
+					continue
+				}
+				if signWith != nil {
+					key = signWith
+				}
+				signature, err := key.Sign(message)
+				require.NoError(t, err)
+				_ = json.NewEncoder(w).Encode(RemoteSignerSignResponse{Signature: signature.String()})
+				return
+			}
+			http.Error(w, "unknown pubkey", http.StatusNotFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNewFromConfig_RemoteSigner(t *testing.T) {
+	active := solana.NewWallet().PrivateKey
+	passive := solana.NewWallet().PrivateKey
+	server := newTestRemoteSigner(t, "secret", []solana.PrivateKey{active}, nil)
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("secret\n"), 0600))
+	passiveKeyFile := filepath.Join(t.TempDir(), "passive.json")
+	passiveKeyData, err := json.Marshal([]byte(passive))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(passiveKeyFile, passiveKeyData, 0600))
+
+	ids, err := NewFromConfig(&Config{
+		Passive: passiveKeyFile,
+		RemoteSigner: RemoteSignerConfig{
+			URL:          server.URL,
+			TokenFile:    tokenFile,
+			ActivePubkey: active.PublicKey().String(),
+		},
+	})
+	require.NoError(t, err)
+	assert.True(t, ids.Active.IsRemote())
+	assert.Empty(t, ids.Active.KeyFile)
+	assert.Equal(t, active.PublicKey().String(), ids.Active.PubKey())
+	assert.False(t, ids.Passive.IsRemote())
+
+	// challenges are signed remotely and verify against the active identity
+	signature, err := ids.Active.SignChallenge("test", []byte("challenge"))
+	require.NoError(t, err)
+	assert.True(t, ids.Active.VerifyChallenge("test", []byte("challenge"), signature))
+}
+
+func TestNewFromConfig_RemoteSignerErrors(t *testing.T) {
+	active := solana.NewWallet().PrivateKey
+	passive := solana.NewWallet().PrivateKey
+	server := newTestRemoteSigner(t, "", []solana.PrivateKey{active}, nil)
+
+	// the signer must hold the configured pubkeys
+	_, err := NewFromConfig(&Config{RemoteSigner: RemoteSignerConfig{
+		URL:           server.URL,
+		ActivePubkey:  active.PublicKey().String(),
+		PassivePubkey: passive.PublicKey().String(),
+	}})
+	assert.ErrorContains(t, err, "doesn't hold "+passive.PublicKey().String())
+
+	// a signature for another key is refused
+	lying := newTestRemoteSigner(t, "", []solana.PrivateKey{active}, passive)
+	client, err := NewRemoteSignerClient(RemoteSignerConfig{URL: lying.URL})
+	require.NoError(t, err)
+	identity, err := NewIdentityFromRemoteSigner(client, active.PublicKey().String())
+	require.NoError(t, err)
+	_, err = identity.SignChallenge("test", []byte("challenge"))
+	assert.ErrorContains(t, err, "doesn't verify")
+}
+
+func TestConfig_Check(t *testing.T) {
+	pubkey := solana.NewWallet().PublicKey().String()
+
+	assert.Empty(t, Config{Active: "active.json", Passive: "passive.json"}.Check())
+	assert.Empty(t, Config{Active: "active.json", RemoteSigner: RemoteSignerConfig{URL: "unix:///run/signer.sock", PassivePubkey: pubkey}}.Check())
+
+	problems := Config{
+		Active: "active.json",
+		RemoteSigner: RemoteSignerConfig{
+			URL:           "ftp://signer",
+			Timeout:       "soon",
+			ActivePubkey:  pubkey,
+			PassivePubkey: "not-a-pubkey",
+		},
+	}.Check()
+	require.Len(t, problems, 4, problems)
+	assert.ErrorContains(t, problems[0], "identities.active and identities.remote_signer.active_pubkey can't both be set")
+	assert.ErrorContains(t, problems[1], "invalid identities.remote_signer.passive_pubkey")
+	assert.ErrorContains(t, problems[2], "scheme must be http, https or unix")
+	assert.ErrorContains(t, problems[3], "identities.remote_signer.timeout")
+
+	problems = Config{RemoteSigner: RemoteSignerConfig{ActivePubkey: pubkey}}.Check()
+	require.Len(t, problems, 2, problems)
+	assert.ErrorContains(t, problems[0], "identities.passive is required")
+	assert.ErrorContains(t, problems[1], "identities.remote_signer.url is required")
+}
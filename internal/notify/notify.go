@@ -0,0 +1,421 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// TypeStart is sent when the failover critical window starts
+	TypeStart = "start"
+	// TypeComplete is sent when the failover completes successfully
+	TypeComplete = "complete"
+	// TypeAbort is sent when a failover is aborted after being initiated
+	TypeAbort = "abort"
+	// TypeCreditRegression is sent when post-failover monitoring finds the active identity's vote credit rank worse
+	// than before the failover
+	TypeCreditRegression = "credit_regression"
+	// TypeGossipConfirmFailure is sent when gossip doesn't confirm the nodes switched roles after a failover
+	TypeGossipConfirmFailure = "gossip_confirm_failure"
+	// TypeBlockProductionFailure is sent when the active identity produced no blocks in its first leader window after a
+	// failover
+	TypeBlockProductionFailure = "block_production_failure"
+	// TypeDrillFailure is sent when a scheduled drill run by the watch daemon fails
+	TypeDrillFailure = "drill_failure"
+
+	// TargetTypeSlack posts {"text": message} to a slack incoming webhook
+	TargetTypeSlack = "slack"
+	// TargetTypeDiscord posts {"content": message} to a discord webhook
+	TargetTypeDiscord = "discord"
+	// TargetTypeWebhook posts the rendered template, or the notification as json, to any http endpoint
+	TargetTypeWebhook = "webhook"
+	// TargetTypePagerDuty triggers a pagerduty incident on abort, gossip_confirm_failure, credit_regression and
+	// block_production_failure and resolves it on complete
+	TargetTypePagerDuty = "pagerduty"
+
+	// DefaultPagerDutyURL is the pagerduty events api v2 endpoint
+	DefaultPagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+
+	// DefaultTimeout is the default time allowed to send a notification to each target
+	DefaultTimeout = 5 * time.Second
+)
+
+// Types are the notification types targets can be limited to
+var Types = []string{TypeStart, TypeComplete, TypeAbort, TypeCreditRegression, TypeGossipConfirmFailure, TypeBlockProductionFailure, TypeDrillFailure}
+
+// Config is the configuration for sending failover notifications to chat and http targets
+type Config struct {
+	Targets []TargetConfig `mapstructure:"targets"`
+	Timeout string         `mapstructure:"timeout"`
+}
+
+// TargetConfig is a single place notifications are sent to
+type TargetConfig struct {
+	Name string `mapstructure:"name"`
+	// Type is slack, discord, webhook or pagerduty
+	Type string `mapstructure:"type"`
+	// URL is where notifications are posted - optional for pagerduty, which defaults to DefaultPagerDutyURL
+	URL string `mapstructure:"url"`
+	// RoutingKey is the pagerduty events api v2 integration key - required for pagerduty
+	RoutingKey string `mapstructure:"routing_key"`
+	// Types limits the target to these notification types - empty sends all of them
+	Types []string `mapstructure:"types"`
+	// Template is a go template rendered with the notification - the message text for slack and discord, the incident
+	// summary for pagerduty, the whole request body for webhook. Empty sends the default message text, or the notification as json for webhook
+	Template string `mapstructure:"template"`
+	// Headers are set on each request e.g. an Authorization header for a webhook
+	Headers map[string]string `mapstructure:"headers"`
+}
+
+// Node is a node taking part in a failover as it appears in a notification
+type Node struct {
+	Hostname string `json:"hostname"`
+	PublicIP string `json:"public_ip"`
+	Pubkey   string `json:"pubkey"`
+}
+
+// Notification is a failover lifecycle notification
+type Notification struct {
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+	// Hostname is the node sending the notification - both nodes of a failover send them when configured to
+	Hostname    string `json:"hostname"`
+	IsDryRun    bool   `json:"is_dry_run"`
+	FailoverID  string `json:"failover_id,omitempty"`
+	ActiveNode  Node   `json:"active_node"`
+	PassiveNode Node   `json:"passive_node"`
+	Reason      string `json:"reason,omitempty"`
+	Summary     string `json:"summary,omitempty"`
+	// IdentityGapMs is how long the validator wasn't voting anywhere - set once the passive node has set its identity
+	IdentityGapMs int64 `json:"identity_gap_ms,omitempty"`
+	// VoteCreditRankBefore and VoteCreditRankAfter are the active identity's vote credit rank either side of
+	// post-failover monitoring - set on credit_regression
+	VoteCreditRankBefore int `json:"vote_credit_rank_before,omitempty"`
+	VoteCreditRankAfter  int `json:"vote_credit_rank_after,omitempty"`
+}
+
+// Text returns the notification's default message text
+func (n Notification) Text() string {
+	mode := ""
+	if n.IsDryRun {
+		mode = " (dry run)"
+	}
+	from := fmt.Sprintf("%s → %s", n.ActiveNode.Hostname, n.PassiveNode.Hostname)
+
+	switch n.Type {
+	case TypeStart:
+		return fmt.Sprintf("🟢 Failover %s%s started: %s", n.FailoverID, mode, from)
+	case TypeComplete:
+		if n.Summary != "" {
+			return fmt.Sprintf("✅ Failover complete%s: %s", mode, n.Summary)
+		}
+		return fmt.Sprintf("✅ Failover %s%s complete: %s", n.FailoverID, mode, from)
+	case TypeAbort:
+		return fmt.Sprintf("🚨 Failover %s%s aborted: %s - %s", n.FailoverID, mode, from, n.Reason)
+	case TypeCreditRegression:
+		return fmt.Sprintf("📉 Vote credit rank of %s worse after failover %s%s: %d → %d",
+			n.ActiveNode.Pubkey, n.FailoverID, mode, n.VoteCreditRankBefore, n.VoteCreditRankAfter)
+	case TypeGossipConfirmFailure:
+		return fmt.Sprintf("🚨 Gossip doesn't confirm failover %s%s switched roles: %s - %s", n.FailoverID, mode, from, n.Reason)
+	case TypeBlockProductionFailure:
+		return fmt.Sprintf("🚨 %s isn't producing blocks after failover %s%s: %s", n.ActiveNode.Pubkey, n.FailoverID, mode, n.Reason)
+	case TypeDrillFailure:
+		return fmt.Sprintf("🚨 Scheduled drill failed: %s - %s", from, n.Reason)
+	}
+	return fmt.Sprintf("Failover %s%s %s: %s", n.FailoverID, mode, n.Type, from)
+}
+
+// Validate ensures every target is valid
+func (c Config) Validate() error {
+	if c.Timeout != "" {
+		if _, err := time.ParseDuration(c.Timeout); err != nil {
+			return fmt.Errorf("failed to parse timeout %s: %w", c.Timeout, err)
+		}
+	}
+	for i, target := range c.Targets {
+		if err := target.Validate(); err != nil {
+			return fmt.Errorf("targets[%d] %s: %w", i, target.Name, err)
+		}
+	}
+	return nil
+}
+
+// Validate ensures the target's type, url, types and template are valid
+func (c TargetConfig) Validate() error {
+	switch c.Type {
+	case TargetTypeSlack, TargetTypeDiscord, TargetTypeWebhook:
+	case TargetTypePagerDuty:
+		if c.RoutingKey == "" {
+			return fmt.Errorf("routing_key is required for %s", TargetTypePagerDuty)
+		}
+	default:
+		return fmt.Errorf("invalid type %q - must be one of %s, %s, %s or %s", c.Type, TargetTypeSlack, TargetTypeDiscord, TargetTypeWebhook, TargetTypePagerDuty)
+	}
+
+	targetURL, err := url.Parse(c.url())
+	if err != nil || (targetURL.Scheme != "http" && targetURL.Scheme != "https") || targetURL.Host == "" {
+		return fmt.Errorf("invalid url - must be an http or https url")
+	}
+
+	for _, notificationType := range c.Types {
+		if !slices.Contains(Types, notificationType) {
+			return fmt.Errorf("invalid notification type %q - must be one of %s", notificationType, strings.Join(Types, ", "))
+		}
+	}
+
+	if _, err := parseTemplate(c.Template); err != nil {
+		return err
+	}
+	return nil
+}
+
+// url returns where the target's notifications are posted
+func (c TargetConfig) url() string {
+	if c.URL == "" && c.Type == TargetTypePagerDuty {
+		return DefaultPagerDutyURL
+	}
+	return c.URL
+}
+
+// wants returns true if the target is sent notifications of the type - pagerduty targets are only sent the types
+// that trigger or resolve an incident
+func (c TargetConfig) wants(notificationType string) bool {
+	if len(c.Types) > 0 && !slices.Contains(c.Types, notificationType) {
+		return false
+	}
+	if c.Type == TargetTypePagerDuty {
+		_, ok := pagerDutyEventActions[notificationType]
+		return ok
+	}
+	return true
+}
+
+// parseTemplate parses a target's template - nil when it has none
+func parseTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+	tpl, err := template.New("notification").Funcs(template.FuncMap{
+		"json": func(v any) (string, error) {
+			encoded, err := json.Marshal(v)
+			return string(encoded), err
+		},
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	return tpl, nil
+}
+
+// target is a validated target ready to send to
+type target struct {
+	TargetConfig
+	template *template.Template
+}
+
+// Notifier sends notifications to targets in the background - a notifier with no targets is a no-op
+type Notifier struct {
+	targets    []target
+	httpClient *http.Client
+	wg         sync.WaitGroup
+	logger     zerolog.Logger
+}
+
+// NewNotifier creates a new notifier from a config
+func NewNotifier(cfg Config) (*Notifier, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	timeout := DefaultTimeout
+	if cfg.Timeout != "" {
+		timeout, _ = time.ParseDuration(cfg.Timeout)
+	}
+
+	n := &Notifier{
+		httpClient: &http.Client{Timeout: timeout},
+		logger:     log.With().Str("component", "notify").Logger(),
+	}
+	for _, targetConfig := range cfg.Targets {
+		tpl, _ := parseTemplate(targetConfig.Template)
+		n.targets = append(n.targets, target{TargetConfig: targetConfig, template: tpl})
+	}
+
+	return n, nil
+}
+
+// IsEnabled returns true if the notifier has targets to send to
+func (n *Notifier) IsEnabled() bool {
+	return n != nil && len(n.targets) > 0
+}
+
+// Notify sends a notification to every target that wants its type in the background so it never holds up a
+// failover - failures are logged
+func (n *Notifier) Notify(notification Notification) {
+	if !n.IsEnabled() {
+		return
+	}
+
+	if notification.Time.IsZero() {
+		notification.Time = time.Now().UTC()
+	}
+
+	for _, t := range n.targets {
+		if !t.wants(notification.Type) {
+			continue
+		}
+		n.wg.Add(1)
+		go func() {
+			defer n.wg.Done()
+			if err := n.send(t, notification); err != nil {
+				n.logger.Warn().Err(err).Str("target", t.Name).Str("type", notification.Type).Msg("failed to send failover notification")
+				return
+			}
+			n.logger.Debug().Str("target", t.Name).Str("type", notification.Type).Msg("sent failover notification")
+		}()
+	}
+}
+
+// Wait waits for notifications being sent in the background - each is bounded by the timeout
+func (n *Notifier) Wait() {
+	if !n.IsEnabled() {
+		return
+	}
+	n.wg.Wait()
+}
+
+// send posts a single notification to a target
+func (n *Notifier) send(t target, notification Notification) error {
+	body, err := t.payload(notification)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(context.Background(), http.MethodPost, t.url(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for name, value := range t.Headers {
+		request.Header.Set(name, value)
+	}
+
+	response, err := n.httpClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		responseBody, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", response.Status, strings.TrimSpace(string(responseBody)))
+	}
+	return nil
+}
+
+// payload returns the request body for a notification - chat targets are sent the message text in the field they
+// expect, webhooks the rendered template or the notification as json
+func (t target) payload(notification Notification) ([]byte, error) {
+	text := notification.Text()
+	if t.template != nil {
+		var buf bytes.Buffer
+		if err := t.template.Execute(&buf, notification); err != nil {
+			return nil, fmt.Errorf("failed to render template: %w", err)
+		}
+		text = buf.String()
+	}
+
+	switch t.Type {
+	case TargetTypeSlack:
+		return json.Marshal(map[string]string{"text": text})
+	case TargetTypeDiscord:
+		return json.Marshal(map[string]string{"content": text})
+	case TargetTypePagerDuty:
+		return t.pagerDutyPayload(notification, text)
+	}
+
+	if t.template != nil {
+		return []byte(text), nil
+	}
+	return json.Marshal(notification)
+}
+
+// pagerDutyEventActions are the pagerduty event actions notification types map to
+var pagerDutyEventActions = map[string]string{
+	TypeAbort:                  "trigger",
+	TypeGossipConfirmFailure:   "trigger",
+	TypeCreditRegression:       "trigger",
+	TypeBlockProductionFailure: "trigger",
+	TypeDrillFailure:           "trigger",
+	TypeComplete:               "resolve",
+}
+
+// pagerDutyMaxSummaryLength is the longest summary pagerduty accepts
+const pagerDutyMaxSummaryLength = 1024
+
+// pagerDutyEvent is a pagerduty events api v2 event
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+// pagerDutyPayload describes the alert a trigger event raises
+type pagerDutyPayload struct {
+	Summary       string       `json:"summary"`
+	Source        string       `json:"source"`
+	Severity      string       `json:"severity"`
+	Timestamp     time.Time    `json:"timestamp"`
+	Component     string       `json:"component"`
+	Class         string       `json:"class"`
+	CustomDetails Notification `json:"custom_details"`
+}
+
+// pagerDutyDedupKey returns the dedup key of a notification - there's one incident per validator so every trigger
+// for it lands on the open incident, whichever node sent it, and the next successful failover resolves it
+func pagerDutyDedupKey(notification Notification) string {
+	return "solana-validator-failover/" + notification.ActiveNode.Pubkey
+}
+
+// pagerDutyPayload returns the pagerduty event for a notification - dry runs trigger warnings rather than critical
+// alerts
+func (t target) pagerDutyPayload(notification Notification, summary string) ([]byte, error) {
+	event := pagerDutyEvent{
+		RoutingKey:  t.RoutingKey,
+		EventAction: pagerDutyEventActions[notification.Type],
+		DedupKey:    pagerDutyDedupKey(notification),
+	}
+	if len(summary) > pagerDutyMaxSummaryLength {
+		summary = summary[:pagerDutyMaxSummaryLength]
+	}
+	if event.EventAction == "trigger" {
+		severity := "critical"
+		if notification.IsDryRun {
+			severity = "warning"
+		}
+		event.Payload = &pagerDutyPayload{
+			Summary:       summary,
+			Source:        notification.Hostname,
+			Severity:      severity,
+			Timestamp:     notification.Time,
+			Component:     notification.ActiveNode.Pubkey,
+			Class:         notification.Type,
+			CustomDetails: notification,
+		}
+	}
+	return json.Marshal(event)
+}
@@ -0,0 +1,222 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receiver is a local http endpoint recording the requests it receives
+type receiver struct {
+	mu       sync.Mutex
+	bodies   []string
+	headers  []http.Header
+	status   int
+	endpoint *httptest.Server
+}
+
+// newReceiver starts a local http endpoint answering with status
+func newReceiver(t *testing.T, status int) *receiver {
+	r := &receiver{status: status}
+	r.endpoint = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		r.bodies = append(r.bodies, string(body))
+		r.headers = append(r.headers, req.Header.Clone())
+		r.mu.Unlock()
+		w.WriteHeader(r.status)
+	}))
+	t.Cleanup(r.endpoint.Close)
+	return r
+}
+
+func testNotification(notificationType string) Notification {
+	return Notification{
+		Type:        notificationType,
+		Hostname:    "node-b",
+		FailoverID:  "1a2b3c4d",
+		ActiveNode:  Node{Hostname: "node-a", Pubkey: "ActivePubkey"},
+		PassiveNode: Node{Hostname: "node-b", Pubkey: "PassivePubkey"},
+		Reason:      "tower file hash mismatch",
+		Summary:     "failover 1a2b3c4d node-a→node-b real duration=8.4s gap=1.2s slots=21 ok",
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, Config{}.Validate())
+	assert.NoError(t, Config{Timeout: "2s", Targets: []TargetConfig{
+		{Name: "ops", Type: TargetTypeSlack, URL: "https://hooks.slack.com/services/x"},
+		{Name: "pager", Type: TargetTypeWebhook, URL: "http://localhost:8080/failover", Types: []string{TypeAbort}, Template: `{"id":{{ json .FailoverID }}}`},
+	}}.Validate())
+
+	err := Config{Timeout: "soon"}.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "timeout")
+
+	err = Config{Targets: []TargetConfig{{Name: "ops", Type: "email", URL: "https://example.com"}}}.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "targets[0] ops: invalid type")
+
+	err = Config{Targets: []TargetConfig{{Name: "ops", Type: TargetTypeDiscord, URL: "ftp://example.com"}}}.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid url")
+
+	err = Config{Targets: []TargetConfig{{Name: "ops", Type: TargetTypeSlack, URL: "https://example.com", Types: []string{"finish"}}}}.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid notification type")
+
+	err = Config{Targets: []TargetConfig{{Name: "oncall", Type: TargetTypePagerDuty}}}.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "routing_key is required")
+
+	// pagerduty defaults its url
+	assert.NoError(t, Config{Targets: []TargetConfig{{Name: "oncall", Type: TargetTypePagerDuty, RoutingKey: "R0UT1NGKEY"}}}.Validate())
+
+	err = Config{Targets: []TargetConfig{{Name: "ops", Type: TargetTypeSlack, URL: "https://example.com", Template: "{{ .Summary"}}}.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse template")
+}
+
+func TestNotifier_Disabled(t *testing.T) {
+	n, err := NewNotifier(Config{})
+	require.NoError(t, err)
+	assert.False(t, n.IsEnabled())
+
+	// no-ops
+	n.Notify(testNotification(TypeStart))
+	n.Wait()
+}
+
+func TestNotifier_SlackAndDiscord(t *testing.T) {
+	slack := newReceiver(t, http.StatusOK)
+	discord := newReceiver(t, http.StatusNoContent)
+
+	n, err := NewNotifier(Config{Targets: []TargetConfig{
+		{Name: "slack", Type: TargetTypeSlack, URL: slack.endpoint.URL},
+		{Name: "discord", Type: TargetTypeDiscord, URL: discord.endpoint.URL, Template: "{{ .Type }} on {{ .Hostname }}"},
+	}})
+	require.NoError(t, err)
+	assert.True(t, n.IsEnabled())
+
+	n.Notify(testNotification(TypeComplete))
+	n.Wait()
+
+	require.Len(t, slack.bodies, 1)
+	var slackPayload map[string]string
+	require.NoError(t, json.Unmarshal([]byte(slack.bodies[0]), &slackPayload))
+	assert.Equal(t, "✅ Failover complete: failover 1a2b3c4d node-a→node-b real duration=8.4s gap=1.2s slots=21 ok", slackPayload["text"])
+
+	require.Len(t, discord.bodies, 1)
+	var discordPayload map[string]string
+	require.NoError(t, json.Unmarshal([]byte(discord.bodies[0]), &discordPayload))
+	assert.Equal(t, "complete on node-b", discordPayload["content"])
+}
+
+func TestNotifier_WebhookTypesHeadersAndTemplate(t *testing.T) {
+	all := newReceiver(t, http.StatusOK)
+	aborts := newReceiver(t, http.StatusOK)
+
+	n, err := NewNotifier(Config{Targets: []TargetConfig{
+		{Name: "all", Type: TargetTypeWebhook, URL: all.endpoint.URL, Headers: map[string]string{"Authorization": "Bearer secret"}},
+		{Name: "aborts", Type: TargetTypeWebhook, URL: aborts.endpoint.URL, Types: []string{TypeAbort}, Template: `{"reason":{{ json .Reason }}}`},
+	}})
+	require.NoError(t, err)
+
+	n.Notify(testNotification(TypeStart))
+	n.Notify(testNotification(TypeAbort))
+	n.Wait()
+
+	require.Len(t, all.bodies, 2)
+	assert.Equal(t, "Bearer secret", all.headers[0].Get("Authorization"))
+	assert.Equal(t, "application/json", all.headers[0].Get("Content-Type"))
+	var notification Notification
+	require.NoError(t, json.Unmarshal([]byte(all.bodies[0]), &notification))
+	assert.Equal(t, "1a2b3c4d", notification.FailoverID)
+	assert.False(t, notification.Time.IsZero())
+
+	require.Len(t, aborts.bodies, 1)
+	assert.Equal(t, `{"reason":"tower file hash mismatch"}`, aborts.bodies[0])
+}
+
+func TestNotifier_PagerDutyTriggersAndResolves(t *testing.T) {
+	pagerDuty := newReceiver(t, http.StatusAccepted)
+	n, err := NewNotifier(Config{Targets: []TargetConfig{
+		{Name: "oncall", Type: TargetTypePagerDuty, URL: pagerDuty.endpoint.URL, RoutingKey: "R0UT1NGKEY"},
+	}})
+	require.NoError(t, err)
+
+	n.Notify(testNotification(TypeStart))
+	n.Notify(testNotification(TypeAbort))
+	n.Wait()
+	n.Notify(testNotification(TypeComplete))
+	n.Wait()
+
+	// start neither triggers nor resolves an incident
+	require.Len(t, pagerDuty.bodies, 2)
+
+	var trigger map[string]any
+	require.NoError(t, json.Unmarshal([]byte(pagerDuty.bodies[0]), &trigger))
+	assert.Equal(t, "R0UT1NGKEY", trigger["routing_key"])
+	assert.Equal(t, "trigger", trigger["event_action"])
+	assert.Equal(t, "solana-validator-failover/ActivePubkey", trigger["dedup_key"])
+	payload := trigger["payload"].(map[string]any)
+	assert.Equal(t, "🚨 Failover 1a2b3c4d aborted: node-a → node-b - tower file hash mismatch", payload["summary"])
+	assert.Equal(t, "critical", payload["severity"])
+	assert.Equal(t, "node-b", payload["source"])
+	assert.Equal(t, TypeAbort, payload["class"])
+	assert.Equal(t, "1a2b3c4d", payload["custom_details"].(map[string]any)["failover_id"])
+
+	var resolve map[string]any
+	require.NoError(t, json.Unmarshal([]byte(pagerDuty.bodies[1]), &resolve))
+	assert.Equal(t, "resolve", resolve["event_action"])
+	assert.Equal(t, trigger["dedup_key"], resolve["dedup_key"])
+	assert.NotContains(t, resolve, "payload")
+}
+
+func TestNotifier_PagerDutyDryRunIsAWarning(t *testing.T) {
+	notification := testNotification(TypeGossipConfirmFailure)
+	notification.IsDryRun = true
+
+	body, err := target{TargetConfig: TargetConfig{Type: TargetTypePagerDuty, RoutingKey: "R0UT1NGKEY"}}.payload(notification)
+	require.NoError(t, err)
+
+	var event map[string]any
+	require.NoError(t, json.Unmarshal(body, &event))
+	assert.Equal(t, "trigger", event["event_action"])
+	assert.Equal(t, "warning", event["payload"].(map[string]any)["severity"])
+}
+
+func TestNotifier_SendFailure(t *testing.T) {
+	failing := newReceiver(t, http.StatusInternalServerError)
+	n, err := NewNotifier(Config{Targets: []TargetConfig{{Name: "failing", Type: TargetTypeSlack, URL: failing.endpoint.URL}}})
+	require.NoError(t, err)
+
+	err = n.send(n.targets[0], testNotification(TypeStart))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected status 500")
+}
+
+func TestNotification_Text(t *testing.T) {
+	notification := testNotification(TypeAbort)
+	notification.IsDryRun = true
+	assert.Equal(t, "🚨 Failover 1a2b3c4d (dry run) aborted: node-a → node-b - tower file hash mismatch", notification.Text())
+
+	notification = testNotification(TypeCreditRegression)
+	notification.VoteCreditRankBefore = 3
+	notification.VoteCreditRankAfter = 9
+	assert.Equal(t, "📉 Vote credit rank of ActivePubkey worse after failover 1a2b3c4d: 3 → 9", notification.Text())
+
+	notification = testNotification(TypeBlockProductionFailure)
+	notification.Reason = "first leader window after failover fully skipped"
+	assert.Equal(t, "🚨 ActivePubkey isn't producing blocks after failover 1a2b3c4d: first leader window after failover fully skipped", notification.Text())
+
+	notification = testNotification(TypeDrillFailure)
+	notification.Reason = "agent refused the handover"
+	assert.Equal(t, "🚨 Scheduled drill failed: node-a → node-b - agent refused the handover", notification.Text())
+}
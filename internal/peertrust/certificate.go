@@ -0,0 +1,125 @@
+package peertrust
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/utils"
+)
+
+// certificateValidity is how long a persisted certificate is valid for - peers pin its fingerprint rather than
+// verifying a chain so it only needs to outlive the pin
+const certificateValidity = 10 * 365 * 24 * time.Hour
+
+// LoadOrCreateCertificate loads this node's certificate from dir, generating and persisting one when there is none
+// yet - peers pin its fingerprint so it must survive restarts
+func LoadOrCreateCertificate(dir string) (tls.Certificate, error) {
+	certFile := filepath.Join(dir, CertFileName)
+	keyFile := filepath.Join(dir, KeyFileName)
+
+	if utils.FileExists(certFile) && utils.FileExists(keyFile) {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return cert, fmt.Errorf("failed to load certificate from %s: %w", dir, err)
+		}
+		return cert, nil
+	}
+
+	certPEM, keyPEM, err := utils.GenerateTLSCertificatePEM(certificateValidity)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to create tls dir %s: %w", dir, err)
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0600); err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to write key file %s: %w", keyFile, err)
+	}
+	if err := os.WriteFile(certFile, certPEM, 0644); err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to write certificate file %s: %w", certFile, err)
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return cert, fmt.Errorf("failed to create TLS certificate: %w", err)
+	}
+
+	log.Info().Str("cert_file", certFile).Str("fingerprint", Fingerprint(cert.Certificate[0])).Msg("Generated certificate for peers to pin")
+
+	return cert, nil
+}
+
+// LoadCertificateFiles loads an operator-supplied certificate and key - a certificate that isn't valid yet or has
+// expired is refused so a bad rotation is caught at startup rather than in a failover
+func LoadCertificateFiles(certFile, keyFile string) (tls.Certificate, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return cert, fmt.Errorf("failed to load certificate %s with key %s: %w", certFile, keyFile, err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return cert, fmt.Errorf("failed to parse certificate %s: %w", certFile, err)
+	}
+	now := time.Now()
+	if now.Before(leaf.NotBefore) {
+		return cert, fmt.Errorf("certificate %s isn't valid until %s", certFile, leaf.NotBefore.Format(time.RFC3339))
+	}
+	if now.After(leaf.NotAfter) {
+		return cert, fmt.Errorf("certificate %s expired at %s", certFile, leaf.NotAfter.Format(time.RFC3339))
+	}
+	cert.Leaf = leaf
+	return cert, nil
+}
+
+// LoadCAFile loads the certificate authorities in caFile
+func LoadCAFile(caFile string) (*x509.CertPool, error) {
+	caPEM, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate authority %s: %w", caFile, err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificates found in certificate authority %s", caFile)
+	}
+	return pool, nil
+}
+
+// VerifyCertificateChain returns a tls.Config.VerifyPeerCertificate function requiring the presented certificate to
+// chain to roots and be valid for one of names - the peer's name or the host it is dialed at
+func VerifyCertificateChain(roots *x509.CertPool, names ...string) func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return errors.New("peer presented no certificate")
+		}
+		certs := make([]*x509.Certificate, 0, len(rawCerts))
+		for _, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return fmt.Errorf("failed to parse peer certificate: %w", err)
+			}
+			certs = append(certs, cert)
+		}
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+		if _, err := certs[0].Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates}); err != nil {
+			return fmt.Errorf("peer certificate not issued by the configured certificate authority: %w", err)
+		}
+		for _, name := range names {
+			if name != "" && certs[0].VerifyHostname(name) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("peer certificate isn't valid for %s", strings.Join(names, " or "))
+	}
+}
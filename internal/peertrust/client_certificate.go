@@ -0,0 +1,183 @@
+package peertrust
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-failover/internal/utils"
+)
+
+const (
+	// ClientCACertFileName is the name of the certificate authority that issues peer client certificates - every
+	// node requiring client certificates needs it in its tls dir
+	ClientCACertFileName = "client-ca.pem"
+	// ClientCAKeyFileName is the name of the client certificate authority's private key - only needed where client
+	// certificates are issued
+	ClientCAKeyFileName = "client-ca-key.pem"
+	// ClientCertFileName is the name of the client certificate this node presents to peers, issued for its peer name
+	ClientCertFileName = "client-cert.pem"
+	// ClientKeyFileName is the name of the private key of the client certificate this node presents to peers
+	ClientKeyFileName = "client-key.pem"
+)
+
+// clientCertificateValidity is how long the client certificate authority and the certificates it issues are valid for
+const clientCertificateValidity = 10 * 365 * 24 * time.Hour
+
+// CreateClientCA generates a certificate authority for issuing peer client certificates in dir - an existing one is
+// never overwritten as every certificate it issued would stop verifying
+func CreateClientCA(dir string) (certFile string, err error) {
+	certFile = filepath.Join(dir, ClientCACertFileName)
+	keyFile := filepath.Join(dir, ClientCAKeyFileName)
+	if utils.FileExists(certFile) || utils.FileExists(keyFile) {
+		return certFile, fmt.Errorf("client certificate authority already exists in %s", dir)
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return certFile, fmt.Errorf("failed to generate key: %w", err)
+	}
+	serialNumber, err := newSerialNumber()
+	if err != nil {
+		return certFile, err
+	}
+	template := x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{CommonName: "solana-validator-failover client ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(clientCertificateValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return certFile, fmt.Errorf("failed to create certificate: %w", err)
+	}
+
+	return certFile, writeCertificateAndKey(dir, certFile, keyFile, certDER, key)
+}
+
+// IssueClientCertificate issues a client certificate for peerName from the certificate authority in caDir, writing it
+// to outDir as <peer-name>-client-cert.pem and <peer-name>-client-key.pem - the peer's name is its common name, so it
+// must be the name the node is configured as a peer by on the nodes it connects to
+func IssueClientCertificate(caDir, peerName, outDir string) (certFile, keyFile string, err error) {
+	if peerName == "" {
+		return "", "", fmt.Errorf("peer name is required")
+	}
+
+	ca, err := tls.LoadX509KeyPair(filepath.Join(caDir, ClientCACertFileName), filepath.Join(caDir, ClientCAKeyFileName))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to load client certificate authority from %s: %w", caDir, err)
+	}
+	caCert, err := x509.ParseCertificate(ca.Certificate[0])
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse client certificate authority: %w", err)
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate key: %w", err)
+	}
+	serialNumber, err := newSerialNumber()
+	if err != nil {
+		return "", "", err
+	}
+	template := x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject:      pkix.Name{CommonName: peerName},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(clientCertificateValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, &template, caCert, &key.PublicKey, ca.PrivateKey)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create certificate: %w", err)
+	}
+
+	certFile = filepath.Join(outDir, peerName+"-"+ClientCertFileName)
+	keyFile = filepath.Join(outDir, peerName+"-"+ClientKeyFileName)
+	return certFile, keyFile, writeCertificateAndKey(outDir, certFile, keyFile, certDER, key)
+}
+
+// LoadClientCAs loads the client certificate authority in dir that peer client certificates must be issued by
+func LoadClientCAs(dir string) (*x509.CertPool, error) {
+	return LoadCAFile(filepath.Join(dir, ClientCACertFileName))
+}
+
+// LoadClientCertificate loads the client certificate this node presents to peers from dir - it is nil when none has
+// been issued to this node
+func LoadClientCertificate(dir string) (*tls.Certificate, error) {
+	certFile := filepath.Join(dir, ClientCertFileName)
+	keyFile := filepath.Join(dir, ClientKeyFileName)
+	if !utils.FileExists(certFile) && !utils.FileExists(keyFile) {
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client certificate from %s: %w", dir, err)
+	}
+	return &cert, nil
+}
+
+// ClientCertificatePeerName returns the peer name a verified client certificate was issued for - empty when the
+// client presented none
+func ClientCertificatePeerName(state tls.ConnectionState) string {
+	if len(state.PeerCertificates) == 0 {
+		return ""
+	}
+	return state.PeerCertificates[0].Subject.CommonName
+}
+
+// VerifyClientCertificatePeerName returns a tls.Config.VerifyConnection function refusing client certificates issued
+// for a name other than one of peerNames - the chain itself is verified against the client certificate authority
+func VerifyClientCertificatePeerName(peerNames []string) func(tls.ConnectionState) error {
+	return func(state tls.ConnectionState) error {
+		peerName := ClientCertificatePeerName(state)
+		if peerName == "" {
+			return errors.New("peer presented no client certificate")
+		}
+		for _, name := range peerNames {
+			if name == peerName {
+				return nil
+			}
+		}
+		return fmt.Errorf("client certificate issued for %s which is not a configured peer", peerName)
+	}
+}
+
+// newSerialNumber returns a random certificate serial number
+func newSerialNumber() (*big.Int, error) {
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+	return serialNumber, nil
+}
+
+// writeCertificateAndKey writes a der-encoded certificate and its private key as pem to certFile and keyFile in dir
+func writeCertificateAndKey(dir, certFile, keyFile string, certDER []byte, key *rsa.PrivateKey) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create dir %s: %w", dir, err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := os.WriteFile(keyFile, keyPEM, 0600); err != nil {
+		return fmt.Errorf("failed to write key file %s: %w", keyFile, err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	if err := os.WriteFile(certFile, certPEM, 0644); err != nil {
+		return fmt.Errorf("failed to write certificate file %s: %w", certFile, err)
+	}
+	return nil
+}
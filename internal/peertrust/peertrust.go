@@ -0,0 +1,256 @@
+package peertrust
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// CertFileName is the name of this node's persisted certificate file in the tls dir
+	CertFileName = "cert.pem"
+	// KeyFileName is the name of this node's persisted private key file in the tls dir
+	KeyFileName = "key.pem"
+	// KnownPeersFileName is the name of the pinned peer fingerprints file in the tls dir
+	KnownPeersFileName = "known_peers.json"
+)
+
+// ErrFingerprintMismatch is returned when a peer presents a certificate other than the one pinned for it
+var ErrFingerprintMismatch = errors.New("peer certificate fingerprint mismatch")
+
+// maxPeerSessions is how many of a peer's most recent sessions are kept
+const maxPeerSessions = 20
+
+// KnownPeer is a peer's pinned certificate fingerprint and the identity material it presented in recent sessions
+type KnownPeer struct {
+	Fingerprint string    `json:"fingerprint"`
+	FirstSeen   time.Time `json:"first_seen"`
+	LastSeen    time.Time `json:"last_seen"`
+	// Sessions are the peer's most recent successful failovers, oldest first
+	Sessions []PeerSession `json:"sessions,omitempty"`
+}
+
+// PeerSession is the identity material a peer presented in a successful failover
+type PeerSession struct {
+	Time          time.Time `json:"time"`
+	Hostname      string    `json:"hostname"`
+	ActivePubkey  string    `json:"active_pubkey"`
+	PassivePubkey string    `json:"passive_pubkey"`
+	ClientVersion string    `json:"client_version"`
+	// Fingerprint is the certificate the peer presented - empty when this node didn't verify one
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+// Store pins peer certificate fingerprints on first use - the fingerprint a peer presents in its first successful
+// session is saved and required on every later connection until it is forgotten
+type Store struct {
+	path  string
+	mutex sync.Mutex
+	peers map[string]KnownPeer
+}
+
+// Load loads the known peers file at path - a missing file is an empty store
+func Load(path string) (*Store, error) {
+	s := &Store{
+		path:  path,
+		peers: make(map[string]KnownPeer),
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known peers file %s: %w", path, err)
+	}
+
+	if len(content) > 0 {
+		if err := json.Unmarshal(content, &s.peers); err != nil {
+			return nil, fmt.Errorf("failed to parse known peers file %s: %w", path, err)
+		}
+	}
+
+	return s, nil
+}
+
+// Fingerprint returns the sha256 fingerprint of a der-encoded certificate
+func Fingerprint(certDER []byte) string {
+	sum := sha256.Sum256(certDER)
+	return hex.EncodeToString(sum[:])
+}
+
+// Get returns the pinned peer with the given name
+func (s *Store) Get(peerName string) (KnownPeer, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	peer, ok := s.peers[peerName]
+	return peer, ok
+}
+
+// Names returns the sorted names of pinned peers
+func (s *Store) Names() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	names := make([]string, 0, len(s.peers))
+	for name := range s.peers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// VerifyPeerCertificate returns a tls.Config.VerifyPeerCertificate function checking the certificate presented by
+// peerName against its pin - the presented fingerprint is written to presented so it can be pinned once the
+// session succeeds
+func (s *Store) VerifyPeerCertificate(peerName string, presented *string) func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return fmt.Errorf("peer %s presented no certificate", peerName)
+		}
+
+		fingerprint := Fingerprint(rawCerts[0])
+		*presented = fingerprint
+
+		pinned, ok := s.Get(peerName)
+		if !ok || pinned.Fingerprint == "" {
+			log.Debug().Str("peer", peerName).Str("fingerprint", fingerprint).Msg("no pinned certificate for peer - trusting on first use")
+			return nil
+		}
+		if pinned.Fingerprint != fingerprint {
+			return fmt.Errorf(
+				"%w for %s: pinned %s, presented %s - if the peer's certificate was rotated run: solana-validator-failover pins forget %s",
+				ErrFingerprintMismatch,
+				peerName,
+				pinned.Fingerprint,
+				fingerprint,
+				peerName,
+			)
+		}
+		return nil
+	}
+}
+
+// Pin pins fingerprint for peerName after a successful session - a peer that is already pinned only has its last
+// seen time updated, and a different fingerprint is refused
+func (s *Store) Pin(peerName, fingerprint string) error {
+	if fingerprint == "" {
+		return fmt.Errorf("no certificate fingerprint to pin for %s", peerName)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now().UTC()
+	peer, ok := s.peers[peerName]
+	switch {
+	case !ok || peer.Fingerprint == "":
+		peer.Fingerprint = fingerprint
+		if peer.FirstSeen.IsZero() {
+			peer.FirstSeen = now
+		}
+		log.Info().Str("peer", peerName).Str("fingerprint", fingerprint).Msg("Pinned peer certificate")
+	case peer.Fingerprint != fingerprint:
+		return fmt.Errorf("%w for %s: pinned %s, presented %s", ErrFingerprintMismatch, peerName, peer.Fingerprint, fingerprint)
+	}
+	peer.LastSeen = now
+	s.peers[peerName] = peer
+
+	return s.save()
+}
+
+// RecordSession records the identity material peerName presented in a successful failover and returns how it differs
+// from the previous session - a changed hostname or identity may mean the peer was rebuilt or replaced. a changed
+// client version is an upgrade so it is logged rather than returned
+func (s *Store) RecordSession(peerName string, session PeerSession) (changes []string, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if session.Time.IsZero() {
+		session.Time = time.Now().UTC()
+	}
+
+	peer := s.peers[peerName]
+	if peer.FirstSeen.IsZero() {
+		peer.FirstSeen = session.Time
+	}
+	peer.LastSeen = session.Time
+
+	if len(peer.Sessions) > 0 {
+		previous := peer.Sessions[len(peer.Sessions)-1]
+		changes = sessionChanges(previous, session)
+		if previous.ClientVersion != session.ClientVersion {
+			log.Info().
+				Str("peer", peerName).
+				Str("previous", previous.ClientVersion).
+				Str("current", session.ClientVersion).
+				Msg("Peer client version changed since the last failover")
+		}
+	}
+
+	peer.Sessions = append(peer.Sessions, session)
+	if len(peer.Sessions) > maxPeerSessions {
+		peer.Sessions = peer.Sessions[len(peer.Sessions)-maxPeerSessions:]
+	}
+	s.peers[peerName] = peer
+
+	return changes, s.save()
+}
+
+// sessionChanges describes how the identity material in current differs from previous
+func sessionChanges(previous, current PeerSession) (changes []string) {
+	compare := func(name, before, after string) {
+		if before != "" && after != "" && before != after {
+			changes = append(changes, fmt.Sprintf("%s changed from %s to %s", name, before, after))
+		}
+	}
+	compare("hostname", previous.Hostname, current.Hostname)
+	compare("active pubkey", previous.ActivePubkey, current.ActivePubkey)
+	compare("passive pubkey", previous.PassivePubkey, current.PassivePubkey)
+	compare("certificate fingerprint", previous.Fingerprint, current.Fingerprint)
+	return changes
+}
+
+// Forget removes the pin for peerName so its next certificate is trusted on first use
+func (s *Store) Forget(peerName string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.peers[peerName]; !ok {
+		return fmt.Errorf("no pinned certificate for peer %s", peerName)
+	}
+	delete(s.peers, peerName)
+
+	return s.save()
+}
+
+// save writes the store to its file atomically
+func (s *Store) save() error {
+	content, err := json.MarshalIndent(s.peers, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create known peers dir: %w", err)
+	}
+
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, content, 0600); err != nil {
+		return fmt.Errorf("failed to write known peers file: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("failed to write known peers file: %w", err)
+	}
+
+	return nil
+}
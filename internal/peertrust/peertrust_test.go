@@ -0,0 +1,270 @@
+package peertrust
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_TrustOnFirstUse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), KnownPeersFileName)
+	store, err := Load(path)
+	require.NoError(t, err)
+
+	// an unknown peer is trusted and its fingerprint reported
+	var presented string
+	verify := store.VerifyPeerCertificate("backup", &presented)
+	require.NoError(t, verify([][]byte{[]byte("cert-a")}, nil))
+	assert.Equal(t, Fingerprint([]byte("cert-a")), presented)
+
+	require.NoError(t, store.Pin("backup", presented))
+
+	// the pin survives a reload and a different certificate is refused
+	store, err = Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backup"}, store.Names())
+
+	verify = store.VerifyPeerCertificate("backup", &presented)
+	assert.NoError(t, verify([][]byte{[]byte("cert-a")}, nil))
+	err = verify([][]byte{[]byte("cert-b")}, nil)
+	assert.True(t, errors.Is(err, ErrFingerprintMismatch))
+	assert.Contains(t, err.Error(), "pins forget backup")
+	assert.Error(t, store.Pin("backup", Fingerprint([]byte("cert-b"))))
+
+	// forgetting the peer trusts its next certificate
+	require.NoError(t, store.Forget("backup"))
+	assert.NoError(t, verify([][]byte{[]byte("cert-b")}, nil))
+	assert.Error(t, store.Forget("backup"))
+}
+
+func TestLoadOrCreateCertificate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "tls")
+
+	cert, err := LoadOrCreateCertificate(dir)
+	require.NoError(t, err)
+
+	loaded, err := LoadOrCreateCertificate(dir)
+	require.NoError(t, err)
+	assert.Equal(t, Fingerprint(cert.Certificate[0]), Fingerprint(loaded.Certificate[0]))
+}
+
+func TestLoadCertificateFiles(t *testing.T) {
+	dir := t.TempDir()
+	caFile, caKey, caCert := writeTestCA(t, dir)
+	certFile, keyFile := writeTestServerCertificate(t, dir, "valid", caKey, caCert, time.Now().Add(time.Hour))
+	expiredCertFile, expiredKeyFile := writeTestServerCertificate(t, dir, "expired", caKey, caCert, time.Now().Add(-time.Hour))
+
+	cert, err := LoadCertificateFiles(certFile, keyFile)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backup.example"}, cert.Leaf.DNSNames)
+
+	_, err = LoadCertificateFiles(expiredCertFile, expiredKeyFile)
+	assert.ErrorContains(t, err, "expired")
+
+	_, err = LoadCertificateFiles(certFile, expiredKeyFile)
+	assert.Error(t, err, "a key not matching the certificate must be refused")
+
+	_, err = LoadCAFile(keyFile)
+	assert.ErrorContains(t, err, "no certificates found")
+
+	// the certificate chains to the authority and must be valid for the peer's name or address host
+	roots, err := LoadCAFile(caFile)
+	require.NoError(t, err)
+	assert.NoError(t, VerifyCertificateChain(roots, "backup", "backup.example")(cert.Certificate, nil))
+	assert.ErrorContains(t, VerifyCertificateChain(roots, "backup", "10.0.0.2")(cert.Certificate, nil), "isn't valid for backup or 10.0.0.2")
+
+	otherCAFile, _, _ := writeTestCA(t, t.TempDir())
+	otherRoots, err := LoadCAFile(otherCAFile)
+	require.NoError(t, err)
+	assert.ErrorContains(t, VerifyCertificateChain(otherRoots, "backup.example")(cert.Certificate, nil), "not issued by the configured certificate authority")
+}
+
+// writeTestCA writes a certificate authority to dir and returns its path, key and certificate
+func writeTestCA(t *testing.T, dir string) (string, *rsa.PrivateKey, *x509.Certificate) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(certDER)
+	require.NoError(t, err)
+
+	caFile := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}), 0644))
+	return caFile, key, cert
+}
+
+// writeTestServerCertificate writes a server certificate for backup.example issued by ca to dir, valid until notAfter
+func writeTestServerCertificate(t *testing.T, dir, name string, caKey *rsa.PrivateKey, caCert *x509.Certificate, notAfter time.Time) (string, string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "backup"},
+		DNSNames:     []string{"backup.example"},
+		NotBefore:    notAfter.Add(-2 * time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, name+"-cert.pem")
+	keyFile := filepath.Join(dir, name+"-key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}), 0644))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), 0600))
+	return certFile, keyFile
+}
+
+func TestClientCertificates(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "tls")
+
+	_, err := CreateClientCA(dir)
+	require.NoError(t, err)
+	_, err = CreateClientCA(dir)
+	assert.Error(t, err, "an existing client certificate authority must not be overwritten")
+
+	// nothing is presented until a client certificate is issued to this node
+	cert, err := LoadClientCertificate(dir)
+	require.NoError(t, err)
+	assert.Nil(t, cert)
+
+	certFile, keyFile, err := IssueClientCertificate(dir, "backup", dir)
+	require.NoError(t, err)
+	require.NoError(t, os.Rename(certFile, filepath.Join(dir, ClientCertFileName)))
+	require.NoError(t, os.Rename(keyFile, filepath.Join(dir, ClientKeyFileName)))
+
+	cert, err = LoadClientCertificate(dir)
+	require.NoError(t, err)
+	require.NotNil(t, cert)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+
+	// the issued certificate chains to the client certificate authority
+	pool, err := LoadClientCAs(dir)
+	require.NoError(t, err)
+	_, err = leaf.Verify(x509.VerifyOptions{Roots: pool, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+	assert.NoError(t, err)
+
+	// its common name must be a configured peer name
+	state := tls.ConnectionState{PeerCertificates: []*x509.Certificate{leaf}}
+	assert.Equal(t, "backup", ClientCertificatePeerName(state))
+	assert.NoError(t, VerifyClientCertificatePeerName([]string{"primary", "backup"})(state))
+	assert.Error(t, VerifyClientCertificatePeerName([]string{"primary"})(state))
+	assert.Error(t, VerifyClientCertificatePeerName([]string{"backup"})(tls.ConnectionState{}))
+}
+
+func TestClientCertificates_OtherAuthorityIsRefused(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "tls")
+	otherDir := filepath.Join(t.TempDir(), "other")
+	_, err := CreateClientCA(dir)
+	require.NoError(t, err)
+	_, err = CreateClientCA(otherDir)
+	require.NoError(t, err)
+
+	certFile, _, err := IssueClientCertificate(otherDir, "backup", otherDir)
+	require.NoError(t, err)
+	certPEM, err := os.ReadFile(certFile)
+	require.NoError(t, err)
+	block, _ := pem.Decode(certPEM)
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+
+	pool, err := LoadClientCAs(dir)
+	require.NoError(t, err)
+	_, err = leaf.Verify(x509.VerifyOptions{Roots: pool, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+	assert.Error(t, err)
+}
+
+func TestIssueClientCertificate_RequiresAuthority(t *testing.T) {
+	dir := t.TempDir()
+
+	_, _, err := IssueClientCertificate(dir, "backup", dir)
+	assert.Error(t, err)
+
+	_, err = CreateClientCA(dir)
+	require.NoError(t, err)
+	_, _, err = IssueClientCertificate(dir, "", dir)
+	assert.Error(t, err)
+}
+
+func TestStore_RecordSession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), KnownPeersFileName)
+	store, err := Load(path)
+	require.NoError(t, err)
+
+	session := PeerSession{
+		Hostname:      "backup",
+		ActivePubkey:  "active-pubkey",
+		PassivePubkey: "passive-pubkey-1",
+		ClientVersion: "2.1.14",
+	}
+
+	// nothing to compare the first session with
+	changes, err := store.RecordSession("backup", session)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	// an upgrade isn't a change of identity
+	session.ClientVersion = "2.2.0"
+	changes, err = store.RecordSession("backup", session)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	// sessions survive a reload and a new passive identity is reported
+	store, err = Load(path)
+	require.NoError(t, err)
+	session.PassivePubkey = "passive-pubkey-2"
+	changes, err = store.RecordSession("backup", session)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"passive pubkey changed from passive-pubkey-1 to passive-pubkey-2"}, changes)
+
+	peer, ok := store.Get("backup")
+	require.True(t, ok)
+	assert.Len(t, peer.Sessions, 3)
+	assert.False(t, peer.LastSeen.IsZero())
+
+	// a peer with recorded sessions but no pinned certificate is still trusted on first use
+	var presented string
+	assert.NoError(t, store.VerifyPeerCertificate("backup", &presented)([][]byte{[]byte("cert-a")}, nil))
+	require.NoError(t, store.Pin("backup", presented))
+	peer, _ = store.Get("backup")
+	assert.Equal(t, Fingerprint([]byte("cert-a")), peer.Fingerprint)
+	assert.Len(t, peer.Sessions, 3)
+}
+
+func TestStore_RecordSession_KeepsRecentSessions(t *testing.T) {
+	store, err := Load(filepath.Join(t.TempDir(), KnownPeersFileName))
+	require.NoError(t, err)
+
+	for i := 0; i < maxPeerSessions+5; i++ {
+		_, err := store.RecordSession("backup", PeerSession{Hostname: "backup"})
+		require.NoError(t, err)
+	}
+
+	peer, _ := store.Get("backup")
+	assert.Len(t, peer.Sessions, maxPeerSessions)
+}
@@ -0,0 +1,93 @@
+package ports
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// capNetBindService is the CAP_NET_BIND_SERVICE capability bit
+const capNetBindService = 10
+
+// DefaultUnprivilegedPortStart is the lowest port any process may bind when the kernel doesn't say otherwise
+const DefaultUnprivilegedPortStart = 1024
+
+// ErrPrivileged is returned when a port can't be bound without CAP_NET_BIND_SERVICE this process lacks
+var ErrPrivileged = errors.New("binding the port requires CAP_NET_BIND_SERVICE")
+
+var (
+	// procSelfStatusPath is read for this process's effective capabilities
+	procSelfStatusPath = "/proc/self/status"
+	// unprivilegedPortStartPath is read for the lowest port binding doesn't need CAP_NET_BIND_SERVICE for
+	unprivilegedPortStartPath = "/proc/sys/net/ipv4/ip_unprivileged_port_start"
+)
+
+// CheckBindable returns an error wrapping ErrPrivileged with how to allow it when this process can't bind port - it
+// returns nil when it can't tell (e.g. not on linux) and leaves binding to report what went wrong
+func CheckBindable(port int) error {
+	if port <= 0 {
+		return nil
+	}
+	unprivilegedPortStart := UnprivilegedPortStart()
+	if port >= unprivilegedPortStart {
+		return nil
+	}
+
+	hasCapability, err := HasNetBindService()
+	if err != nil || hasCapability {
+		return nil
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		executable = "<path to solana-validator-failover>"
+	}
+	return fmt.Errorf(
+		"%w: port %d is below %d and this process (uid %d) lacks it - run as root, grant it with "+
+			"`sudo setcap cap_net_bind_service=+ep %s`, add AmbientCapabilities=CAP_NET_BIND_SERVICE to its systemd unit "+
+			"or use a port of %d or above",
+		ErrPrivileged, port, unprivilegedPortStart, os.Geteuid(), executable, unprivilegedPortStart,
+	)
+}
+
+// UnprivilegedPortStart returns the lowest port binding doesn't need CAP_NET_BIND_SERVICE for
+func UnprivilegedPortStart() int {
+	content, err := os.ReadFile(unprivilegedPortStartPath)
+	if err != nil {
+		return DefaultUnprivilegedPortStart
+	}
+	start, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		return DefaultUnprivilegedPortStart
+	}
+	return start
+}
+
+// HasNetBindService returns true if this process's effective capabilities include CAP_NET_BIND_SERVICE
+func HasNetBindService() (bool, error) {
+	file, err := os.Open(procSelfStatusPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to read capabilities: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		value, ok := strings.CutPrefix(scanner.Text(), "CapEff:")
+		if !ok {
+			continue
+		}
+		capabilities, err := strconv.ParseUint(strings.TrimSpace(value), 16, 64)
+		if err != nil {
+			return false, fmt.Errorf("failed to parse effective capabilities %q: %w", strings.TrimSpace(value), err)
+		}
+		return capabilities&(1<<capNetBindService) != 0, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("failed to read capabilities: %w", err)
+	}
+	return false, fmt.Errorf("no effective capabilities in %s", procSelfStatusPath)
+}
@@ -0,0 +1,89 @@
+package ports
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProc points the proc files read at temp files with the given effective capabilities and unprivileged port start
+// - empty leaves a file missing
+func fakeProc(t *testing.T, capEff, portStart string) {
+	t.Helper()
+	dir := t.TempDir()
+
+	originalStatusPath, originalPortStartPath := procSelfStatusPath, unprivilegedPortStartPath
+	t.Cleanup(func() {
+		procSelfStatusPath, unprivilegedPortStartPath = originalStatusPath, originalPortStartPath
+	})
+
+	procSelfStatusPath = filepath.Join(dir, "status")
+	if capEff != "" {
+		status := "Name:\tsolana-validator-failover\nCapInh:\t0000000000000000\nCapEff:\t" + capEff + "\nCapBnd:\t000001ffffffffff\n"
+		require.NoError(t, os.WriteFile(procSelfStatusPath, []byte(status), 0644))
+	}
+	unprivilegedPortStartPath = filepath.Join(dir, "ip_unprivileged_port_start")
+	if portStart != "" {
+		require.NoError(t, os.WriteFile(unprivilegedPortStartPath, []byte(portStart+"\n"), 0644))
+	}
+}
+
+func TestCheckBindable_NonRootWithoutCapability(t *testing.T) {
+	fakeProc(t, "0000000000000000", "1024")
+
+	err := CheckBindable(443)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrPrivileged)
+	assert.Contains(t, err.Error(), "port 443 is below 1024")
+	assert.Contains(t, err.Error(), "setcap cap_net_bind_service=+ep")
+	assert.Contains(t, err.Error(), "AmbientCapabilities=CAP_NET_BIND_SERVICE")
+
+	assert.NoError(t, CheckBindable(1024))
+	assert.NoError(t, CheckBindable(9898))
+	assert.NoError(t, CheckBindable(0), "ephemeral ports are always bindable")
+}
+
+func TestCheckBindable_WithCapability(t *testing.T) {
+	fakeProc(t, "0000000000000400", "1024") // CAP_NET_BIND_SERVICE only
+	assert.NoError(t, CheckBindable(443))
+
+	fakeProc(t, "000001ffffffffff", "1024") // root
+	assert.NoError(t, CheckBindable(443))
+}
+
+func TestCheckBindable_LoweredUnprivilegedPortStart(t *testing.T) {
+	fakeProc(t, "0000000000000000", "80")
+	assert.NoError(t, CheckBindable(443))
+	assert.ErrorIs(t, CheckBindable(22), ErrPrivileged)
+}
+
+func TestCheckBindable_Unknown(t *testing.T) {
+	// no proc files e.g. not linux - binding reports what went wrong
+	fakeProc(t, "", "")
+	assert.Equal(t, DefaultUnprivilegedPortStart, UnprivilegedPortStart())
+	assert.NoError(t, CheckBindable(443))
+}
+
+func TestHasNetBindService(t *testing.T) {
+	fakeProc(t, "0000000000000400", "")
+	hasCapability, err := HasNetBindService()
+	require.NoError(t, err)
+	assert.True(t, hasCapability)
+
+	fakeProc(t, "00000000a80425fb", "") // docker default capabilities
+	hasCapability, err = HasNetBindService()
+	require.NoError(t, err)
+	assert.True(t, hasCapability)
+
+	fakeProc(t, "0000000000000000", "")
+	hasCapability, err = HasNetBindService()
+	require.NoError(t, err)
+	assert.False(t, hasCapability)
+
+	fakeProc(t, "not-hex", "")
+	_, err = HasNetBindService()
+	assert.Error(t, err)
+}
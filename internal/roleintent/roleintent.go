@@ -0,0 +1,104 @@
+package roleintent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileName is the name of the role intent file in the state dir
+const FileName = "role-intent.json"
+
+// Intent is the role a node is supposed to hold, recorded when a failover or swap gives it that role
+type Intent struct {
+	// Time is when the intent was recorded
+	Time time.Time `json:"time"`
+	// Role is the role the node should hold and Pubkey the identity it should run with in it
+	Role   string `json:"role"`
+	Pubkey string `json:"pubkey"`
+	// Hostname is the node that recorded the intent
+	Hostname string `json:"hostname"`
+	// FailoverID is the failover that set the role - empty when set by a swap
+	FailoverID string `json:"failover_id,omitempty"`
+	// Reason is how the role was set, e.g. failover or swap
+	Reason string `json:"reason"`
+}
+
+// Store keeps the last recorded intent in a file - a nil store records nothing
+type Store struct {
+	path string
+}
+
+// NewStore returns the role intent store in dir - the dir is created on the first write
+func NewStore(dir string) *Store {
+	return &Store{path: filepath.Join(dir, FileName)}
+}
+
+// Path returns the role intent file path
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Write replaces the recorded intent atomically
+func (s *Store) Write(intent Intent) error {
+	if s == nil {
+		return nil
+	}
+	if intent.Time.IsZero() {
+		intent.Time = time.Now().UTC()
+	}
+
+	content, err := json.MarshalIndent(intent, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create role intent dir: %w", err)
+	}
+
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, content, 0600); err != nil {
+		return fmt.Errorf("failed to write role intent file: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("failed to write role intent file: %w", err)
+	}
+	return nil
+}
+
+// Read returns the recorded intent - ok is false when none has been recorded yet
+func (s *Store) Read() (intent Intent, ok bool, err error) {
+	if s == nil {
+		return intent, false, nil
+	}
+	content, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return intent, false, nil
+	}
+	if err != nil {
+		return intent, false, fmt.Errorf("failed to read role intent file: %w", err)
+	}
+	if err := json.Unmarshal(content, &intent); err != nil {
+		return intent, false, fmt.Errorf("failed to parse role intent file %s: %w", s.path, err)
+	}
+	return intent, true, nil
+}
+
+// Divergence returns why a node in role running with pubkey doesn't match the intent - empty when it does
+func (i Intent) Divergence(role, pubkey string) string {
+	if i.Role == role && (i.Pubkey == "" || i.Pubkey == pubkey) {
+		return ""
+	}
+	setBy := i.Reason
+	if i.FailoverID != "" {
+		setBy = fmt.Sprintf("%s %s", i.Reason, i.FailoverID)
+	}
+	return fmt.Sprintf(
+		"expected to be %s with %s since %s (%s) but is %s with %s - was the identity changed outside solana-validator-failover?",
+		i.Role, i.Pubkey, i.Time.Format(time.RFC3339), setBy, role, pubkey,
+	)
+}
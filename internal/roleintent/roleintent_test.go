@@ -0,0 +1,77 @@
+package roleintent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteAndRead(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "state")
+	store := NewStore(dir)
+	assert.Equal(t, filepath.Join(dir, FileName), store.Path())
+
+	_, ok, err := store.Read()
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	intent := Intent{
+		Time:       time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC),
+		Role:       "active",
+		Pubkey:     "PubkeyA",
+		Hostname:   "node-a",
+		FailoverID: "abc",
+		Reason:     "failover",
+	}
+	require.NoError(t, store.Write(intent))
+	read, ok, err := store.Read()
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, intent, read)
+
+	require.NoError(t, store.Write(Intent{Role: "passive", Pubkey: "PubkeyP", Reason: "swap"}))
+	read, ok, err = store.Read()
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "passive", read.Role)
+	assert.False(t, read.Time.IsZero(), "write stamps intents without a time")
+
+	info, err := os.Stat(store.Path())
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestRead_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, FileName), []byte("not json"), 0600))
+
+	_, ok, err := NewStore(dir).Read()
+	require.Error(t, err)
+	assert.False(t, ok)
+}
+
+func TestNilStore(t *testing.T) {
+	var store *Store
+	assert.NoError(t, store.Write(Intent{Role: "active"}))
+	_, ok, err := store.Read()
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestDivergence(t *testing.T) {
+	intent := Intent{Role: "active", Pubkey: "PubkeyA", Reason: "failover", FailoverID: "abc"}
+
+	assert.Empty(t, intent.Divergence("active", "PubkeyA"))
+	assert.Empty(t, Intent{Role: "active"}.Divergence("active", "PubkeyA"))
+
+	divergence := intent.Divergence("passive", "PubkeyP")
+	assert.Contains(t, divergence, "expected to be active with PubkeyA")
+	assert.Contains(t, divergence, "failover abc")
+	assert.Contains(t, divergence, "is passive with PubkeyP")
+
+	assert.NotEmpty(t, intent.Divergence("active", "PubkeyOther"))
+}
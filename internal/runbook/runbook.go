@@ -0,0 +1,189 @@
+package runbook
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/sol-strategies/solana-validator-failover/internal/hooks"
+	"github.com/sol-strategies/solana-validator-failover/internal/utils"
+	"github.com/spf13/viper"
+)
+
+const (
+	// ConfirmationPrompt prompts where the run needs an answer, the same as running without a runbook
+	ConfirmationPrompt = "prompt"
+	// ConfirmationNever never prompts, the same as --non-interactive
+	ConfirmationNever = "never"
+)
+
+// Runbook describes one planned failover - which peer, whether it is for real, how it is confirmed, which hooks
+// run and the validator config it overrides for the event
+type Runbook struct {
+	Name        string `mapstructure:"name"`
+	Description string `mapstructure:"description"`
+	// Peer is the name of the peer in failover.peers to fail over with
+	Peer                  string `mapstructure:"peer"`
+	NotADrill             bool   `mapstructure:"not_a_drill"`
+	NoWaitForHealthy      bool   `mapstructure:"no_wait_for_healthy"`
+	NoMinTimeToLeaderSlot bool   `mapstructure:"no_min_time_to_leader_slot"`
+	// Confirmation is prompt or never - empty prompts
+	Confirmation string      `mapstructure:"confirmation"`
+	Hooks        HooksConfig `mapstructure:"hooks"`
+	// Validator is merged over the selected validator's config, keyed like the validator section of the config file
+	Validator map[string]any `mapstructure:"validator"`
+
+	// Path and Hash are the file the runbook was loaded from and its sha256, to tie a run to the reviewed file
+	Path string `mapstructure:"-"`
+	Hash string `mapstructure:"-"`
+}
+
+// HooksConfig toggles the configured hooks for the event - a group left unset runs as configured
+type HooksConfig struct {
+	Check   *bool `mapstructure:"check"`
+	Pre     *bool `mapstructure:"pre"`
+	Post    *bool `mapstructure:"post"`
+	OnAbort *bool `mapstructure:"on_abort"`
+	// BeforeSetIdentity, AfterTowerReceived and AfterGossipConfirmed toggle the hooks run at those moments
+	BeforeSetIdentity    *bool `mapstructure:"before_set_identity"`
+	AfterTowerReceived   *bool `mapstructure:"after_tower_received"`
+	AfterGossipConfirmed *bool `mapstructure:"after_gossip_confirmed"`
+	// Skip names hooks from any group that don't run
+	Skip []string `mapstructure:"skip"`
+}
+
+// Load loads and validates a runbook file
+func Load(path string) (r *Runbook, err error) {
+	resolvedPath, err := utils.ResolvePath(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve runbook path: %w", err)
+	}
+
+	content, err := os.ReadFile(resolvedPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read runbook: %w", err)
+	}
+
+	v := viper.New()
+	v.SetConfigFile(resolvedPath)
+	if err = v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to parse runbook %s: %w", resolvedPath, err)
+	}
+
+	r = &Runbook{}
+	if err = v.Unmarshal(r); err != nil {
+		return nil, fmt.Errorf("failed to parse runbook %s: %w", resolvedPath, err)
+	}
+	r.Path = resolvedPath
+	sum := sha256.Sum256(content)
+	r.Hash = hex.EncodeToString(sum[:])
+
+	if err = r.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid runbook %s: %w", resolvedPath, err)
+	}
+	return r, nil
+}
+
+// Validate ensures the runbook is valid
+func (r Runbook) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if !slices.Contains([]string{"", ConfirmationPrompt, ConfirmationNever}, r.Confirmation) {
+		return fmt.Errorf("invalid confirmation %q - must be one of: %s, %s", r.Confirmation, ConfirmationPrompt, ConfirmationNever)
+	}
+	for i, name := range r.Hooks.Skip {
+		if name == "" {
+			return fmt.Errorf("hooks.skip[%d] is empty", i)
+		}
+	}
+	return nil
+}
+
+// IsNonInteractive returns true if the runbook never prompts
+func (r Runbook) IsNonInteractive() bool {
+	return r.Confirmation == ConfirmationNever
+}
+
+// ApplyHooks removes the hooks the runbook turns off from failoverHooks - every skipped hook must be configured
+func (r Runbook) ApplyHooks(failoverHooks *hooks.FailoverHooks) error {
+	configuredNames := map[string]bool{}
+	for _, hookList := range []hooks.Hooks{
+		failoverHooks.Check,
+		failoverHooks.Pre.WhenActive, failoverHooks.Pre.WhenPassive,
+		failoverHooks.Post.WhenActive, failoverHooks.Post.WhenPassive,
+		failoverHooks.OnAbort.WhenActive, failoverHooks.OnAbort.WhenPassive,
+		failoverHooks.BeforeSetIdentity.WhenActive, failoverHooks.BeforeSetIdentity.WhenPassive,
+		failoverHooks.AfterTowerReceived, failoverHooks.AfterGossipConfirmed,
+	} {
+		for _, hook := range hookList {
+			configuredNames[hook.Name] = true
+		}
+	}
+	var unknown []string
+	for _, name := range r.Hooks.Skip {
+		if !configuredNames[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("hooks.skip names hooks that aren't configured: %s", strings.Join(unknown, ", "))
+	}
+
+	if isOff(r.Hooks.Check) {
+		failoverHooks.Check = nil
+	}
+	if isOff(r.Hooks.Pre) {
+		failoverHooks.Pre = hooks.PreHooks{}
+	}
+	if isOff(r.Hooks.Post) {
+		failoverHooks.Post = hooks.PostHooks{}
+	}
+	if isOff(r.Hooks.OnAbort) {
+		failoverHooks.OnAbort = hooks.AbortHooks{}
+	}
+	if isOff(r.Hooks.BeforeSetIdentity) {
+		failoverHooks.BeforeSetIdentity = hooks.SetIdentityHooks{}
+	}
+	if isOff(r.Hooks.AfterTowerReceived) {
+		failoverHooks.AfterTowerReceived = nil
+	}
+	if isOff(r.Hooks.AfterGossipConfirmed) {
+		failoverHooks.AfterGossipConfirmed = nil
+	}
+
+	failoverHooks.Check = r.withoutSkipped(failoverHooks.Check)
+	failoverHooks.Pre.WhenActive = r.withoutSkipped(failoverHooks.Pre.WhenActive)
+	failoverHooks.Pre.WhenPassive = r.withoutSkipped(failoverHooks.Pre.WhenPassive)
+	failoverHooks.Post.WhenActive = r.withoutSkipped(failoverHooks.Post.WhenActive)
+	failoverHooks.Post.WhenPassive = r.withoutSkipped(failoverHooks.Post.WhenPassive)
+	failoverHooks.OnAbort.WhenActive = r.withoutSkipped(failoverHooks.OnAbort.WhenActive)
+	failoverHooks.OnAbort.WhenPassive = r.withoutSkipped(failoverHooks.OnAbort.WhenPassive)
+	failoverHooks.BeforeSetIdentity.WhenActive = r.withoutSkipped(failoverHooks.BeforeSetIdentity.WhenActive)
+	failoverHooks.BeforeSetIdentity.WhenPassive = r.withoutSkipped(failoverHooks.BeforeSetIdentity.WhenPassive)
+	failoverHooks.AfterTowerReceived = r.withoutSkipped(failoverHooks.AfterTowerReceived)
+	failoverHooks.AfterGossipConfirmed = r.withoutSkipped(failoverHooks.AfterGossipConfirmed)
+	return nil
+}
+
+// withoutSkipped returns hookList without the hooks named in hooks.skip
+func (r Runbook) withoutSkipped(hookList hooks.Hooks) hooks.Hooks {
+	if len(hookList) == 0 {
+		return hookList
+	}
+	kept := make(hooks.Hooks, 0, len(hookList))
+	for _, hook := range hookList {
+		if !slices.Contains(r.Hooks.Skip, hook.Name) {
+			kept = append(kept, hook)
+		}
+	}
+	return kept
+}
+
+// isOff returns true if a hook group toggle is set to false
+func isOff(toggle *bool) bool {
+	return toggle != nil && !*toggle
+}
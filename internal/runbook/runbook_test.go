@@ -0,0 +1,109 @@
+package runbook
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sol-strategies/solana-validator-failover/internal/hooks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeRunbook(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "runbook.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := writeRunbook(t, `
+name: kernel upgrade node-a
+description: move the validator to node-b while node-a reboots
+peer: node-b
+not_a_drill: true
+no_min_time_to_leader_slot: true
+confirmation: never
+hooks:
+  post: false
+  skip: [page-oncall]
+validator:
+  failover:
+    switch_countdown: 5s
+`)
+
+	r, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "kernel upgrade node-a", r.Name)
+	assert.Equal(t, "node-b", r.Peer)
+	assert.True(t, r.NotADrill)
+	assert.False(t, r.NoWaitForHealthy)
+	assert.True(t, r.NoMinTimeToLeaderSlot)
+	assert.True(t, r.IsNonInteractive())
+	assert.Nil(t, r.Hooks.Check)
+	require.NotNil(t, r.Hooks.Post)
+	assert.False(t, *r.Hooks.Post)
+	assert.Equal(t, []string{"page-oncall"}, r.Hooks.Skip)
+	assert.Equal(t, path, r.Path)
+	assert.Len(t, r.Hash, 64)
+
+	failover, ok := r.Validator["failover"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "5s", failover["switch_countdown"])
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+
+	_, err = Load(writeRunbook(t, "peer: node-b\n"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "name is required")
+
+	_, err = Load(writeRunbook(t, "name: event\nconfirmation: maybe\n"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid confirmation")
+}
+
+func TestApplyHooks(t *testing.T) {
+	off := false
+	failoverHooks := hooks.FailoverHooks{
+		Check: hooks.Hooks{{Name: "maintenance-window"}},
+		Pre: hooks.PreHooks{
+			WhenActive:  hooks.Hooks{{Name: "drain"}, {Name: "page-oncall"}},
+			WhenPassive: hooks.Hooks{{Name: "warm-up"}},
+		},
+		Post: hooks.PostHooks{
+			WhenPassive: hooks.Hooks{{Name: "announce"}},
+		},
+		OnAbort: hooks.AbortHooks{
+			WhenActive: hooks.Hooks{{Name: "restore-firewall"}, {Name: "page-oncall-abort"}},
+		},
+		BeforeSetIdentity: hooks.SetIdentityHooks{
+			WhenActive: hooks.Hooks{{Name: "stop-relayer"}},
+		},
+		AfterGossipConfirmed: hooks.Hooks{{Name: "update-dns"}},
+	}
+
+	r := Runbook{Name: "event", Hooks: HooksConfig{Post: &off, AfterGossipConfirmed: &off, Skip: []string{"page-oncall", "page-oncall-abort"}}}
+	require.NoError(t, r.ApplyHooks(&failoverHooks))
+
+	assert.Equal(t, hooks.Hooks{{Name: "maintenance-window"}}, failoverHooks.Check)
+	assert.Equal(t, hooks.Hooks{{Name: "drain"}}, failoverHooks.Pre.WhenActive)
+	assert.Equal(t, hooks.Hooks{{Name: "warm-up"}}, failoverHooks.Pre.WhenPassive)
+	assert.Empty(t, failoverHooks.Post.WhenPassive)
+	assert.Equal(t, hooks.Hooks{{Name: "restore-firewall"}}, failoverHooks.OnAbort.WhenActive)
+	assert.Equal(t, hooks.Hooks{{Name: "stop-relayer"}}, failoverHooks.BeforeSetIdentity.WhenActive)
+	assert.Empty(t, failoverHooks.AfterGossipConfirmed)
+}
+
+func TestApplyHooks_UnknownSkip(t *testing.T) {
+	failoverHooks := hooks.FailoverHooks{Check: hooks.Hooks{{Name: "maintenance-window"}}}
+
+	r := Runbook{Name: "event", Hooks: HooksConfig{Skip: []string{"drain"}}}
+	err := r.ApplyHooks(&failoverHooks)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "drain")
+	assert.Len(t, failoverHooks.Check, 1)
+}
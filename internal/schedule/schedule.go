@@ -0,0 +1,172 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// field bounds for the five standard cron fields
+var fieldBounds = []struct {
+	name     string
+	min, max int
+}{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 6},
+}
+
+// Schedule is a parsed standard five field cron expression (minute hour day-of-month month day-of-week)
+type Schedule struct {
+	expression  string
+	minutes     map[int]bool
+	hours       map[int]bool
+	daysOfMonth map[int]bool
+	months      map[int]bool
+	daysOfWeek  map[int]bool
+	// cron semantics: when both day fields are restricted a day matching either is a match
+	daysOfMonthRestricted bool
+	daysOfWeekRestricted  bool
+}
+
+// Parse parses a standard five field cron expression supporting *, lists (1,2), ranges (1-5) and steps (*/15, 1-30/5)
+func Parse(expression string) (*Schedule, error) {
+	fields := strings.Fields(expression)
+	if len(fields) != len(fieldBounds) {
+		return nil, fmt.Errorf("invalid cron expression %q: expected %d fields, got %d", expression, len(fieldBounds), len(fields))
+	}
+
+	parsedFields := make([]map[int]bool, len(fields))
+	for i, field := range fields {
+		values, err := parseField(field, fieldBounds[i].min, fieldBounds[i].max)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %s: %w", expression, fieldBounds[i].name, err)
+		}
+		parsedFields[i] = values
+	}
+
+	// 7 is an accepted alias for sunday
+	if parsedFields[4][7] {
+		parsedFields[4][0] = true
+		delete(parsedFields[4], 7)
+	}
+
+	return &Schedule{
+		expression:            expression,
+		minutes:               parsedFields[0],
+		hours:                 parsedFields[1],
+		daysOfMonth:           parsedFields[2],
+		months:                parsedFields[3],
+		daysOfWeek:            parsedFields[4],
+		daysOfMonthRestricted: fields[2] != "*",
+		daysOfWeekRestricted:  fields[4] != "*",
+	}, nil
+}
+
+// String returns the cron expression the schedule was parsed from
+func (s *Schedule) String() string {
+	return s.expression
+}
+
+// Next returns the first time strictly after t matching the schedule, in t's location
+func (s *Schedule) Next(t time.Time) time.Time {
+	// start at the beginning of the next minute
+	next := t.Truncate(time.Minute).Add(time.Minute)
+
+	// a matching time always exists within a few years (e.g. feb 29) - bound the search regardless
+	limit := next.AddDate(5, 0, 0)
+	for next.Before(limit) {
+		if !s.months[int(next.Month())] {
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, next.Location())
+			continue
+		}
+		if !s.matchesDay(next) {
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, next.Location())
+			continue
+		}
+		if !s.hours[next.Hour()] {
+			next = time.Date(next.Year(), next.Month(), next.Day(), next.Hour()+1, 0, 0, 0, next.Location())
+			continue
+		}
+		if !s.minutes[next.Minute()] {
+			next = next.Add(time.Minute)
+			continue
+		}
+		return next
+	}
+
+	return time.Time{}
+}
+
+// matchesDay returns true if t's day matches the day of month and day of week fields
+func (s *Schedule) matchesDay(t time.Time) bool {
+	domMatch := s.daysOfMonth[t.Day()]
+	dowMatch := s.daysOfWeek[int(t.Weekday())]
+	if s.daysOfMonthRestricted && s.daysOfWeekRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+// parseField parses a single cron field into the set of values it matches
+func parseField(field string, min, max int) (map[int]bool, error) {
+	values := map[int]bool{}
+
+	// day of week accepts 7 as sunday
+	if max == 6 {
+		max = 7
+	}
+
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if idx := strings.Index(part, "/"); idx >= 0 {
+			var err error
+			rangePart = part[:idx]
+			step, err = strconv.Atoi(part[idx+1:])
+			if err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step in %q", part)
+			}
+		}
+
+		start, end := min, max
+		switch {
+		case rangePart == "*":
+			if max == 7 {
+				end = 6
+			}
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if start, err = strconv.Atoi(bounds[0]); err != nil {
+				return nil, fmt.Errorf("invalid range start in %q", part)
+			}
+			if end, err = strconv.Atoi(bounds[1]); err != nil {
+				return nil, fmt.Errorf("invalid range end in %q", part)
+			}
+		default:
+			value, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q", part)
+			}
+			start = value
+			end = value
+			// a single value with a step means value through max
+			if step > 1 {
+				end = max
+			}
+		}
+
+		if start < min || end > max || start > end {
+			return nil, fmt.Errorf("value out of range [%d-%d] in %q", min, max, part)
+		}
+
+		for value := start; value <= end; value += step {
+			values[value] = true
+		}
+	}
+
+	return values, nil
+}
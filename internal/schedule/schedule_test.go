@@ -0,0 +1,84 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_InvalidFieldCount(t *testing.T) {
+	_, err := Parse("* * * *")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "expected 5 fields")
+}
+
+func TestParse_InvalidValues(t *testing.T) {
+	for _, expression := range []string{
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"a * * * *",
+		"5-1 * * * *",
+	} {
+		_, err := Parse(expression)
+		assert.Error(t, err, expression)
+	}
+}
+
+func TestNext_EveryMinute(t *testing.T) {
+	s, err := Parse("* * * * *")
+	require.NoError(t, err)
+
+	from := time.Date(2025, 6, 1, 10, 30, 45, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 6, 1, 10, 31, 0, 0, time.UTC), s.Next(from))
+}
+
+func TestNext_Steps(t *testing.T) {
+	s, err := Parse("*/15 * * * *")
+	require.NoError(t, err)
+
+	from := time.Date(2025, 6, 1, 10, 31, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 6, 1, 10, 45, 0, 0, time.UTC), s.Next(from))
+}
+
+func TestNext_WeeklyAtTime(t *testing.T) {
+	// mondays at 03:30
+	s, err := Parse("30 3 * * 1")
+	require.NoError(t, err)
+
+	// sunday 2025-06-01
+	from := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 6, 2, 3, 30, 0, 0, time.UTC), s.Next(from))
+}
+
+func TestNext_RollsOverYear(t *testing.T) {
+	s, err := Parse("0 0 1 1 *")
+	require.NoError(t, err)
+
+	from := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), s.Next(from))
+}
+
+func TestNext_DayOfMonthOrDayOfWeek(t *testing.T) {
+	// the 15th or any friday
+	s, err := Parse("0 12 15 * 5")
+	require.NoError(t, err)
+
+	// monday 2025-06-02 - next friday is the 6th, before the 15th
+	from := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 6, 6, 12, 0, 0, 0, time.UTC), s.Next(from))
+}
+
+func TestNext_SundayAlias(t *testing.T) {
+	s, err := Parse("0 0 * * 7")
+	require.NoError(t, err)
+
+	// monday 2025-06-02 - next sunday is the 8th
+	from := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 6, 8, 0, 0, 0, 0, time.UTC), s.Next(from))
+}
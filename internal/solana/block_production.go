@@ -0,0 +1,83 @@
+package solana
+
+import (
+	"context"
+	"fmt"
+
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// BlockProduction is how many blocks an identity produced in its leader slots of a slot range
+type BlockProduction struct {
+	FirstSlot      uint64
+	LastSlot       uint64
+	LeaderSlots    uint64
+	BlocksProduced uint64
+}
+
+// SkippedSlots returns the number of leader slots in the range the identity didn't produce a block in
+func (p BlockProduction) SkippedSlots() uint64 {
+	if p.BlocksProduced > p.LeaderSlots {
+		return 0
+	}
+	return p.LeaderSlots - p.BlocksProduced
+}
+
+// SkipRate returns the percentage of leader slots in the range skipped
+func (p BlockProduction) SkipRate() float64 {
+	if p.LeaderSlots == 0 {
+		return 0
+	}
+	return 100 * float64(p.SkippedSlots()) / float64(p.LeaderSlots)
+}
+
+// GetLeaderSlotsAfter returns up to count of the given pubkey's leader slots after slot, in order - only the current
+// epoch's leader schedule is searched so fewer, or none, are returned near its end
+func (c *Client) GetLeaderSlotsAfter(pubkey solanago.PublicKey, slot uint64, count int) (slots []uint64, err error) {
+	epochInfo, err := c.networkRPCClient.GetEpochInfo(context.Background(), rpc.CommitmentConfirmed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get epoch info: %w", err)
+	}
+
+	leaderSchedule, err := c.networkRPCClient.GetLeaderSchedule(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get leader schedule: %w", err)
+	}
+
+	// the schedule holds slot indices relative to the epoch's first slot
+	firstSlotOfEpoch := epochInfo.AbsoluteSlot - epochInfo.SlotIndex
+	for _, relativeSlot := range leaderSchedule[pubkey] {
+		if len(slots) == count {
+			break
+		}
+		if leaderSlot := firstSlotOfEpoch + relativeSlot; leaderSlot > slot {
+			slots = append(slots, leaderSlot)
+		}
+	}
+	return slots, nil
+}
+
+// GetBlockProduction returns the given identity's block production from firstSlot to lastSlot inclusive as seen by
+// the network rpc - both slots must be in the same epoch and no later than the current slot
+func (c *Client) GetBlockProduction(pubkey solanago.PublicKey, firstSlot, lastSlot uint64) (BlockProduction, error) {
+	production := BlockProduction{FirstSlot: firstSlot, LastSlot: lastSlot}
+	blockProduction, err := c.networkRPCClient.GetBlockProductionWithOpts(context.Background(), &rpc.GetBlockProductionOpts{
+		Commitment: rpc.CommitmentConfirmed,
+		Identity:   &pubkey,
+		Range: &rpc.SlotRangeRequest{
+			FirstSlot: firstSlot,
+			LastSlot:  &lastSlot,
+		},
+	})
+	if err != nil {
+		return production, fmt.Errorf("failed to get block production for pubkey %s: %w", pubkey, err)
+	}
+
+	// [leader slots, blocks produced] - absent when the identity had no leader slots in range
+	if counts, ok := blockProduction.Value.ByIdentity[pubkey]; ok {
+		production.LeaderSlots = uint64(counts[0])
+		production.BlocksProduced = uint64(counts[1])
+	}
+	return production, nil
+}
@@ -0,0 +1,64 @@
+package solana
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLeaderSlotsAfter(t *testing.T) {
+	client, _, networkMock := createTestClient()
+	pubkey := createTestPublicKey(1)
+
+	networkMock.On("GetEpochInfo", mock.Anything, rpc.CommitmentConfirmed).Return(&rpc.GetEpochInfoResult{
+		AbsoluteSlot: 1100,
+		SlotIndex:    100,
+	}, nil)
+	networkMock.On("GetLeaderSchedule", mock.Anything).Return(rpc.GetLeaderScheduleResult{
+		pubkey: []uint64{96, 97, 98, 99, 100, 101, 102, 103, 200, 201},
+	}, nil)
+
+	slots, err := client.GetLeaderSlotsAfter(pubkey, 1100, 4)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{1101, 1102, 1103, 1200}, slots)
+
+	slots, err = client.GetLeaderSlotsAfter(pubkey, 1098, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{1099, 1100}, slots, "slots before the current one are returned when after slot")
+
+	slots, err = client.GetLeaderSlotsAfter(createTestPublicKey(2), 1100, 4)
+	require.NoError(t, err)
+	assert.Empty(t, slots, "pubkeys not on the schedule have no leader slots")
+}
+
+func TestGetBlockProduction(t *testing.T) {
+	client, _, networkMock := createTestClient()
+	pubkey := createTestPublicKey(1)
+
+	networkMock.On("GetBlockProductionWithOpts", mock.Anything, mock.MatchedBy(func(opts *rpc.GetBlockProductionOpts) bool {
+		return *opts.Identity == pubkey && opts.Range.FirstSlot == 1100 && *opts.Range.LastSlot == 1103
+	})).Return(&rpc.GetBlockProductionResult{
+		Value: rpc.BlockProductionResult{
+			ByIdentity: rpc.IdentityToSlotsBlocks{pubkey: {4, 3}},
+		},
+	}, nil)
+
+	production, err := client.GetBlockProduction(pubkey, 1100, 1103)
+	require.NoError(t, err)
+	assert.Equal(t, BlockProduction{FirstSlot: 1100, LastSlot: 1103, LeaderSlots: 4, BlocksProduced: 3}, production)
+	assert.Equal(t, uint64(1), production.SkippedSlots())
+	assert.Equal(t, 25.0, production.SkipRate())
+}
+
+func TestGetBlockProduction_Error(t *testing.T) {
+	client, _, networkMock := createTestClient()
+	networkMock.On("GetBlockProductionWithOpts", mock.Anything, mock.Anything).
+		Return((*rpc.GetBlockProductionResult)(nil), errors.New("slot range too new"))
+
+	_, err := client.GetBlockProduction(createTestPublicKey(1), 1100, 1103)
+	require.Error(t, err)
+}
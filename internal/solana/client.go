@@ -3,6 +3,7 @@ package solana
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -11,8 +12,12 @@ import (
 	solanago "github.com/gagliardetto/solana-go"
 	"github.com/gagliardetto/solana-go/rpc"
 	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/utils"
 )
 
+// leaderSlotLogMaxRanges is the maximum number of leader slot ranges listed in debug logs
+const leaderSlotLogMaxRanges = 5
+
 // RPCClientInterface defines the interface for RPC client operations - a solana rpc client interface
 type RPCClientInterface interface {
 	GetClusterNodes(ctx context.Context) ([]*rpc.GetClusterNodesResult, error)
@@ -22,6 +27,12 @@ type RPCClientInterface interface {
 	GetBlockTime(ctx context.Context, slot uint64) (*solanago.UnixTimeSeconds, error)
 	GetHealth(ctx context.Context) (string, error)
 	GetEpochInfo(ctx context.Context, commitment rpc.CommitmentType) (*rpc.GetEpochInfoResult, error)
+	GetBalance(ctx context.Context, account solanago.PublicKey, commitment rpc.CommitmentType) (*rpc.GetBalanceResult, error)
+	GetBlockProductionWithOpts(ctx context.Context, opts *rpc.GetBlockProductionOpts) (*rpc.GetBlockProductionResult, error)
+	GetTransactionCount(ctx context.Context, commitment rpc.CommitmentType) (uint64, error)
+	GetMultipleAccountsWithOpts(ctx context.Context, accounts []solanago.PublicKey, opts *rpc.GetMultipleAccountsOpts) (*rpc.GetMultipleAccountsResult, error)
+	GetInflationRate(ctx context.Context) (*rpc.GetInflationRateResult, error)
+	GetSupply(ctx context.Context, commitment rpc.CommitmentType) (*rpc.GetSupplyResult, error)
 }
 
 // ClientInterface defines the interface for solana rpc operations - just simple wrappers around the rpc client
@@ -33,22 +44,53 @@ type ClientInterface interface {
 	// GetCreditRankedVoteAccountFromPubkey returns the credit rank-sorted current vote accounts rank is the difference
 	// between current epoch credits and total credits (descending)
 	GetCreditRankedVoteAccountFromPubkey(pubkey string) (*rpc.VoteAccountsResult, int, error)
+	// GetVoteAccountStatus returns the status of the vote account whose node is the given identity pubkey
+	GetVoteAccountStatus(pubkey string) (VoteAccountStatus, error)
 	// GetCurrentSlot returns the current slot
 	GetCurrentSlot() (slot uint64, err error)
+	// GetSlotLag returns how many slots the local node's processed slot is behind the network's - 0 when it isn't
+	GetSlotLag() (lag uint64, err error)
 	// GetCurrentSlotEndTime returns the end time of the current slot
 	GetCurrentSlotEndTime() (time.Time, error)
+	// GetCurrentEpochInfo returns the current epoch info
+	GetCurrentEpochInfo() (*rpc.GetEpochInfoResult, error)
+	// GetBalance returns the balance in lamports of the given account
+	GetBalance(pubkey solanago.PublicKey) (lamports uint64, err error)
+	// GetLocalValidatorMetrics returns a snapshot of the given identity's metrics as seen by the local rpc - block
+	// production covers at most the last maxSlots slots of the current epoch starting no earlier than firstSlot
+	GetLocalValidatorMetrics(identity solanago.PublicKey, firstSlot, maxSlots uint64) (*ValidatorMetrics, error)
+	// GetRewardsContext returns what is needed to estimate what missed vote credits cost the vote account whose node
+	// is the given identity pubkey in epoch rewards
+	GetRewardsContext(pubkey string) (*RewardsContext, error)
+	// GetFeatureStatuses returns the activation status of the given feature gates
+	GetFeatureStatuses(ids []solanago.PublicKey) ([]FeatureStatus, error)
+	// GetLeaderSlotsAfter returns up to count of the given pubkey's leader slots after slot in the current epoch
+	GetLeaderSlotsAfter(pubkey solanago.PublicKey, slot uint64, count int) (slots []uint64, err error)
+	// GetBlockProduction returns the given identity's block production from firstSlot to lastSlot inclusive
+	GetBlockProduction(pubkey solanago.PublicKey, firstSlot, lastSlot uint64) (BlockProduction, error)
+	// GetLeaderGaps returns the gaps between the given pubkey's leader slots from the current slot to the end of the epoch
+	GetLeaderGaps(pubkey solanago.PublicKey) (gaps []LeaderGap, err error)
 	// GetTimeToNextLeaderSlotForPubkey returns the time to the next leader slot for the given pubkey
 	GetTimeToNextLeaderSlotForPubkey(pubkey solanago.PublicKey) (isOnLeaderSchedule bool, timeToNextLeaderSlot time.Duration, err error)
 	// GetLocalNodeHealth returns the health of the local node
 	GetLocalNodeHealth() (string, error)
 	// IsLocalNodeHealthy returns true if the local node is healthy
 	IsLocalNodeHealthy() bool
+	// NetworkRPCURL returns the url of the network rpc answering cluster-wide calls
+	NetworkRPCURL() string
+	// InvalidateClusterNodes drops the cached cluster nodes so the next lookup fetches them again - for callers
+	// waiting on gossip to change
+	InvalidateClusterNodes()
 }
 
 // Client implements Interface using an RPC client
 type Client struct {
 	localRPCClient   RPCClientInterface
 	networkRPCClient RPCClientInterface
+	networkRPCURL    string
+	networkRPCPool   *networkRPCPool
+	debugLog         *logSampler
+	clusterNodes     *clusterNodesCache
 	performanceCache struct {
 		avgSlotTime  time.Duration
 		lastUpdated  time.Time
@@ -60,14 +102,49 @@ type Client struct {
 type NewClientParams struct {
 	LocalRPCURL   string
 	NetworkRPCURL string
+	// NetworkRPCURLs are network rpc urls tried in order before NetworkRPCURL, moving on to the next on errors
+	NetworkRPCURLs []string
+	// NetworkRPCHeaders are http headers sent with every call to a network rpc url, by url - e.g. a provider's api
+	// key. Urls without any are called without extra headers
+	NetworkRPCHeaders map[string]map[string]string
+	// NetworkRPCTimeout is how long a network rpc call may take before the next url is tried - 0 waits
+	NetworkRPCTimeout time.Duration
+	// NetworkRPCUnhealthyCooldown is how long a network rpc url that failed is tried after the others
+	NetworkRPCUnhealthyCooldown time.Duration
+	// Retry is how failed network rpc calls are retried once every url has been tried - the zero value never retries
+	Retry RetryConfig
+	// DebugLogSampleInterval is the minimum interval between repeated debug logs on high-volume paths - 0 logs all
+	DebugLogSampleInterval time.Duration
+	// ClusterNodesCacheTTL is how long the cluster nodes gossip lookups search are reused before fetching them
+	// again - 0 fetches them on every lookup
+	ClusterNodesCacheTTL time.Duration
 }
 
 // NewRPCClient creates a new client for the given solana cluster
 func NewRPCClient(params NewClientParams) ClientInterface {
+	pool := newNetworkRPCPool(
+		append(slices.Clone(params.NetworkRPCURLs), params.NetworkRPCURL),
+		params.NetworkRPCTimeout,
+		params.NetworkRPCUnhealthyCooldown,
+		func(url string) RPCClientInterface { return rpc.NewWithHeaders(url, params.NetworkRPCHeaders[url]) },
+	)
 	return &Client{
 		localRPCClient:   rpc.New(params.LocalRPCURL),
-		networkRPCClient: rpc.New(params.NetworkRPCURL),
+		networkRPCClient: newRetryRPCClient(pool, params.Retry),
+		networkRPCURL:    params.NetworkRPCURL,
+		networkRPCPool:   pool,
+		debugLog:         newLogSampler(params.DebugLogSampleInterval),
+		clusterNodes:     newClusterNodesCache(params.ClusterNodesCacheTTL),
+	}
+}
+
+// NetworkRPCURL returns the url of the network rpc answering cluster-wide calls - the one that answered last when
+// several are configured
+func (c *Client) NetworkRPCURL() string {
+	if c.networkRPCPool != nil {
+		return c.networkRPCPool.URL()
 	}
+	return c.networkRPCURL
 }
 
 // GetLocalNodeHealth returns the health of the local node
@@ -112,7 +189,7 @@ func (c *Client) NodeFromPubkey(pubkey string) (*Node, error) {
 }
 
 func (c *Client) nodeFromIP(ip string) (node *rpc.GetClusterNodesResult, err error) {
-	nodes, err := c.networkRPCClient.GetClusterNodes(context.Background())
+	nodes, err := c.getClusterNodes()
 	if err != nil {
 		return nil, err
 	}
@@ -126,11 +203,11 @@ func (c *Client) nodeFromIP(ip string) (node *rpc.GetClusterNodesResult, err err
 		}
 	}
 
-	return nil, fmt.Errorf("gossip node not found for ip: %s", ip)
+	return nil, fmt.Errorf("%w for ip: %s", ErrNodeNotFound, ip)
 }
 
 func (c *Client) gossipNodeFromPubkey(pubkey string) (node *rpc.GetClusterNodesResult, err error) {
-	nodes, err := c.networkRPCClient.GetClusterNodes(context.Background())
+	nodes, err := c.getClusterNodes()
 	if err != nil {
 		return nil, err
 	}
@@ -141,7 +218,7 @@ func (c *Client) gossipNodeFromPubkey(pubkey string) (node *rpc.GetClusterNodesR
 		}
 	}
 
-	return nil, fmt.Errorf("gossip node not found for pubkey: %s", pubkey)
+	return nil, fmt.Errorf("%w for pubkey: %s", ErrNodeNotFound, pubkey)
 }
 
 // GetCreditRankedVoteAccountFromPubkey returns the credit rank-sorted current vote accounts rank is the difference
@@ -199,6 +276,94 @@ func (c *Client) GetCurrentSlot() (slot uint64, err error) {
 	return slot, nil
 }
 
+// GetSlotLag returns how many slots the local node's processed slot is behind the network's - a healthy node can still
+// be far enough behind that it couldn't vote straight away
+func (c *Client) GetSlotLag() (lag uint64, err error) {
+	localSlot, err := c.localRPCClient.GetSlot(context.Background(), rpc.CommitmentProcessed)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get local slot: %w", err)
+	}
+	networkSlot, err := c.networkRPCClient.GetSlot(context.Background(), rpc.CommitmentProcessed)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get network slot: %w", err)
+	}
+	if localSlot >= networkSlot {
+		return 0, nil
+	}
+	return networkSlot - localSlot, nil
+}
+
+// GetCurrentEpochInfo returns the current epoch info
+func (c *Client) GetCurrentEpochInfo() (*rpc.GetEpochInfoResult, error) {
+	epochInfo, err := c.networkRPCClient.GetEpochInfo(context.Background(), rpc.CommitmentConfirmed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get epoch info: %w", err)
+	}
+	return epochInfo, nil
+}
+
+// GetBalance returns the balance in lamports of the given account
+func (c *Client) GetBalance(pubkey solanago.PublicKey) (lamports uint64, err error) {
+	balance, err := c.networkRPCClient.GetBalance(context.Background(), pubkey, rpc.CommitmentConfirmed)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get balance for %s: %w", pubkey, err)
+	}
+	return balance.Value, nil
+}
+
+// GetLocalValidatorMetrics returns a snapshot of the given identity's metrics as seen by the local rpc - block
+// production covers at most the last maxSlots slots of the current epoch starting no earlier than firstSlot
+func (c *Client) GetLocalValidatorMetrics(identity solanago.PublicKey, firstSlot, maxSlots uint64) (*ValidatorMetrics, error) {
+	epochInfo, err := c.localRPCClient.GetEpochInfo(context.Background(), rpc.CommitmentProcessed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get local epoch info: %w", err)
+	}
+
+	metrics := &ValidatorMetrics{
+		Slot:                     epochInfo.AbsoluteSlot,
+		BlockProductionFirstSlot: blockProductionFirstSlot(epochInfo, firstSlot, maxSlots),
+	}
+
+	metrics.TransactionCount, err = c.localRPCClient.GetTransactionCount(context.Background(), rpc.CommitmentProcessed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get local transaction count: %w", err)
+	}
+
+	lastSlot := metrics.Slot
+	blockProduction, err := c.localRPCClient.GetBlockProductionWithOpts(context.Background(), &rpc.GetBlockProductionOpts{
+		Commitment: rpc.CommitmentProcessed,
+		Identity:   &identity,
+		Range: &rpc.SlotRangeRequest{
+			FirstSlot: metrics.BlockProductionFirstSlot,
+			LastSlot:  &lastSlot,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get local block production: %w", err)
+	}
+
+	// [leader slots, blocks produced] - absent when the identity had no leader slots in range
+	if production, ok := blockProduction.Value.ByIdentity[identity]; ok {
+		metrics.LeaderSlots = uint64(production[0])
+		metrics.BlocksProduced = uint64(production[1])
+	}
+
+	return metrics, nil
+}
+
+// blockProductionFirstSlot returns the first slot of a block production range ending at the current slot - no more
+// than maxSlots long, no earlier than firstSlot and within the current epoch as ranges can't span epochs
+func blockProductionFirstSlot(epochInfo *rpc.GetEpochInfoResult, firstSlot, maxSlots uint64) uint64 {
+	first := epochInfo.AbsoluteSlot - epochInfo.SlotIndex
+	if maxSlots > 0 && epochInfo.AbsoluteSlot > maxSlots && epochInfo.AbsoluteSlot-maxSlots > first {
+		first = epochInfo.AbsoluteSlot - maxSlots
+	}
+	if firstSlot > first && firstSlot <= epochInfo.AbsoluteSlot {
+		first = firstSlot
+	}
+	return first
+}
+
 // GetCurrentSlotEndTime returns the end time of the current slot
 func (c *Client) GetCurrentSlotEndTime() (time.Time, error) {
 	slot, err := c.GetCurrentSlot()
@@ -237,7 +402,7 @@ func (c *Client) GetTimeToNextLeaderSlotForPubkey(pubkey solanago.PublicKey) (is
 	// calculate first slot of current epoch
 	firstSlotOfEpoch := epochInfo.AbsoluteSlot - epochInfo.SlotIndex
 
-	log.Debug().
+	c.debugLog.Debug("epoch info for leader slot calculation").
 		Uint64("current_slot", currentSlot).
 		Uint64("absolute_slot", epochInfo.AbsoluteSlot).
 		Uint64("slot_index", epochInfo.SlotIndex).
@@ -256,65 +421,51 @@ func (c *Client) GetTimeToNextLeaderSlotForPubkey(pubkey solanago.PublicKey) (is
 
 	// pubkey not in leader schedule
 	if !ok {
-		log.Debug().
+		c.debugLog.Debug("validator not found in leader schedule").
 			Str("validator_pubkey", pubkey.String()).
 			Int("total_validators_in_schedule", len(leaderSchedule)).
 			Msg("validator not found in leader schedule")
 		return false, time.Duration(0), nil
 	}
 
+	// convert relative slots to absolute slots and find the next future slot - summarized in a single log line
+	// rather than one per slot as there are hundreds of them per epoch on mainnet
 	var nextLeaderSlot uint64
-
-	log.Debug().
-		Str("validator_pubkey", pubkey.String()).
-		Uint64("current_slot", currentSlot).
-		Uint64("first_slot_of_epoch", firstSlotOfEpoch).
-		Int("total_relative_slots", len(relativeSlots)).
-		Msg("checking relative slots for future leader slots")
-
-	// Convert relative slots to absolute slots and find the next future slot
+	slotsChecked := 0
 	for _, relativeSlot := range relativeSlots {
+		slotsChecked++
 		absoluteSlot := firstSlotOfEpoch + relativeSlot
-		
-		log.Debug().
-			Uint64("relative_slot", relativeSlot).
-			Uint64("absolute_slot", absoluteSlot).
-			Uint64("current_slot", currentSlot).
-			Bool("is_future", absoluteSlot > currentSlot).
-			Msg("checking converted slot")
-		
 		if absoluteSlot > currentSlot {
 			nextLeaderSlot = absoluteSlot
-			log.Debug().
-				Uint64("next_leader_slot", nextLeaderSlot).
-				Msg("found next future leader slot")
 			break
 		}
 	}
 
 	// didn't find future slots for the pubkey
 	if nextLeaderSlot == 0 {
-		log.Debug().
+		c.debugLog.Debug("validator found in leader schedule but has no future slots in current epoch").
 			Str("validator_pubkey", pubkey.String()).
 			Uint64("current_slot", currentSlot).
 			Uint64("first_slot_of_epoch", firstSlotOfEpoch).
 			Int("total_relative_slots", len(relativeSlots)).
+			Str("relative_slots", utils.FormatSlotRanges(relativeSlots, leaderSlotLogMaxRanges)).
 			Msg("validator found in leader schedule but has no future slots in current epoch")
-		
-		// Log some sample relative slots for debugging
-		if len(relativeSlots) > 0 {
-			sampleSlots := relativeSlots
-			if len(relativeSlots) > 5 {
-				sampleSlots = relativeSlots[:5]
-			}
-			log.Debug().
-				Uints64("sample_relative_slots", sampleSlots).
-				Msg("sample relative slots from leader schedule")
-		}
-		
 		return false, time.Duration(0), nil
 	}
 
+	c.debugLog.Debug("found next future leader slot").
+		Str("validator_pubkey", pubkey.String()).
+		Uint64("current_slot", currentSlot).
+		Uint64("first_slot_of_epoch", firstSlotOfEpoch).
+		Int("total_relative_slots", len(relativeSlots)).
+		Int("relative_slots_checked", slotsChecked).
+		Uint64("next_leader_slot", nextLeaderSlot).
+		Str("upcoming_leader_slots", utils.FormatSlotRanges(
+			absoluteSlots(firstSlotOfEpoch, relativeSlots[slotsChecked-1:]),
+			leaderSlotLogMaxRanges,
+		)).
+		Msg("found next future leader slot")
+
 	// Calculate slots until leader slot
 	slotsUntilLeader := nextLeaderSlot - currentSlot
 	
@@ -327,7 +478,7 @@ func (c *Client) GetTimeToNextLeaderSlotForPubkey(pubkey solanago.PublicKey) (is
 	// Calculate time to next leader slot based on slots and average slot time
 	timeToNextLeaderSlot = time.Duration(slotsUntilLeader) * avgSlotTime
 
-	log.Debug().
+	c.debugLog.Debug("calculated time to next leader slot").
 		Uint64("next_leader_slot", nextLeaderSlot).
 		Uint64("current_slot", currentSlot).
 		Uint64("slots_until_leader", slotsUntilLeader).
@@ -338,6 +489,15 @@ func (c *Client) GetTimeToNextLeaderSlotForPubkey(pubkey solanago.PublicKey) (is
 	return true, timeToNextLeaderSlot, nil
 }
 
+// absoluteSlots converts relative slot indices within an epoch to absolute slots
+func absoluteSlots(firstSlotOfEpoch uint64, relativeSlots []uint64) []uint64 {
+	slots := make([]uint64, len(relativeSlots))
+	for i, relativeSlot := range relativeSlots {
+		slots[i] = firstSlotOfEpoch + relativeSlot
+	}
+	return slots
+}
+
 // getAverageSlotTime returns the average slot time
 // Uses a fixed 400ms slot time as a reasonable approximation for Solana
 // TODO: Could be enhanced to use getRecentPerformanceSamples for dynamic calculation
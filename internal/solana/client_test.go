@@ -2,6 +2,7 @@ package solana
 
 import (
 	"context"
+	"encoding/binary"
 	"errors"
 	"testing"
 	"time"
@@ -54,6 +55,36 @@ func (m *MockRPCClient) GetEpochInfo(ctx context.Context, commitment rpc.Commitm
 	return args.Get(0).(*rpc.GetEpochInfoResult), args.Error(1)
 }
 
+func (m *MockRPCClient) GetBalance(ctx context.Context, account solanago.PublicKey, commitment rpc.CommitmentType) (*rpc.GetBalanceResult, error) {
+	args := m.Called(ctx, account, commitment)
+	return args.Get(0).(*rpc.GetBalanceResult), args.Error(1)
+}
+
+func (m *MockRPCClient) GetBlockProductionWithOpts(ctx context.Context, opts *rpc.GetBlockProductionOpts) (*rpc.GetBlockProductionResult, error) {
+	args := m.Called(ctx, opts)
+	return args.Get(0).(*rpc.GetBlockProductionResult), args.Error(1)
+}
+
+func (m *MockRPCClient) GetTransactionCount(ctx context.Context, commitment rpc.CommitmentType) (uint64, error) {
+	args := m.Called(ctx, commitment)
+	return args.Get(0).(uint64), args.Error(1)
+}
+
+func (m *MockRPCClient) GetMultipleAccountsWithOpts(ctx context.Context, accounts []solanago.PublicKey, opts *rpc.GetMultipleAccountsOpts) (*rpc.GetMultipleAccountsResult, error) {
+	args := m.Called(ctx, accounts, opts)
+	return args.Get(0).(*rpc.GetMultipleAccountsResult), args.Error(1)
+}
+
+func (m *MockRPCClient) GetInflationRate(ctx context.Context) (*rpc.GetInflationRateResult, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(*rpc.GetInflationRateResult), args.Error(1)
+}
+
+func (m *MockRPCClient) GetSupply(ctx context.Context, commitment rpc.CommitmentType) (*rpc.GetSupplyResult, error) {
+	args := m.Called(ctx, commitment)
+	return args.Get(0).(*rpc.GetSupplyResult), args.Error(1)
+}
+
 // createTestClient creates a test client with mock RPC clients
 func createTestClient() (*Client, *MockRPCClient, *MockRPCClient) {
 	localMock := &MockRPCClient{}
@@ -243,6 +274,7 @@ func TestGossipClient_NodeFromPubkey_NotFound(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, node)
 	assert.Contains(t, err.Error(), "gossip node not found for pubkey: 9999999999999999999999999999999999999999999999999999999999999999")
+	assert.ErrorIs(t, err, ErrNodeNotFound)
 
 	networkMock.AssertExpectations(t)
 }
@@ -493,6 +525,90 @@ func TestGossipClient_GetCreditRankedVoteAccountFromPubkey_RPCError(t *testing.T
 	networkMock.AssertExpectations(t)
 }
 
+func TestGossipClient_GetVoteAccountStatus_Current(t *testing.T) {
+	client, _, networkMock := createTestClient()
+
+	networkMock.On("GetVoteAccounts", mock.Anything, mock.Anything).Return(&rpc.GetVoteAccountsResult{
+		Current: []rpc.VoteAccountsResult{
+			{
+				NodePubkey:     createTestPublicKey(1),
+				VotePubkey:     createTestPublicKey(3),
+				LastVote:       12345,
+				EpochCredits:   [][]int64{{1, 1000, 500}, {2, 1800, 1000}},
+				ActivatedStake: 5_000_000_000_000,
+				Commission:     5,
+			},
+			{NodePubkey: createTestPublicKey(2), ActivatedStake: 15_000_000_000_000},
+		},
+	}, nil)
+
+	status, err := client.GetVoteAccountStatus("11111111111111111111111111111111")
+
+	require.NoError(t, err)
+	assert.True(t, status.Found)
+	assert.False(t, status.Delinquent)
+	assert.Equal(t, createTestPublicKey(3).String(), status.VotePubkey)
+	assert.Equal(t, int64(800), status.EpochCredits)
+	assert.Equal(t, uint64(12345), status.LastVote)
+	assert.Equal(t, uint64(5_000_000_000_000), status.ActivatedStake)
+	assert.Equal(t, uint8(5), status.Commission)
+	assert.Equal(t, 25.0, status.StakeWeight)
+
+	networkMock.AssertExpectations(t)
+}
+
+func TestGossipClient_GetVoteAccountStatus_Delinquent(t *testing.T) {
+	client, _, networkMock := createTestClient()
+
+	networkMock.On("GetVoteAccounts", mock.Anything, mock.Anything).Return(&rpc.GetVoteAccountsResult{
+		Current: []rpc.VoteAccountsResult{
+			{NodePubkey: createTestPublicKey(2)},
+		},
+		Delinquent: []rpc.VoteAccountsResult{
+			{NodePubkey: createTestPublicKey(1), LastVote: 100},
+		},
+	}, nil)
+
+	status, err := client.GetVoteAccountStatus("11111111111111111111111111111111")
+
+	require.NoError(t, err)
+	assert.True(t, status.Found)
+	assert.True(t, status.Delinquent)
+	assert.Equal(t, uint64(100), status.LastVote)
+
+	networkMock.AssertExpectations(t)
+}
+
+func TestGossipClient_GetVoteAccountStatus_NotFound(t *testing.T) {
+	client, _, networkMock := createTestClient()
+
+	networkMock.On("GetVoteAccounts", mock.Anything, mock.Anything).Return(&rpc.GetVoteAccountsResult{
+		Current: []rpc.VoteAccountsResult{
+			{NodePubkey: createTestPublicKey(2)},
+		},
+	}, nil)
+
+	status, err := client.GetVoteAccountStatus("11111111111111111111111111111111")
+
+	require.NoError(t, err)
+	assert.False(t, status.Found)
+
+	networkMock.AssertExpectations(t)
+}
+
+func TestGossipClient_GetVoteAccountStatus_RPCError(t *testing.T) {
+	client, _, networkMock := createTestClient()
+
+	networkMock.On("GetVoteAccounts", mock.Anything, mock.Anything).Return((*rpc.GetVoteAccountsResult)(nil), errors.New("RPC connection failed"))
+
+	_, err := client.GetVoteAccountStatus("11111111111111111111111111111111")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "RPC connection failed")
+
+	networkMock.AssertExpectations(t)
+}
+
 func TestGossipClient_GetCreditRankedVoteAccountFromPubkey_Sorting(t *testing.T) {
 	// Create test client with mocks
 	client, _, networkMock := createTestClient()
@@ -571,6 +687,37 @@ func TestGossipClient_GetCurrentSlot_RPCError(t *testing.T) {
 	networkMock.AssertExpectations(t)
 }
 
+func TestGossipClient_GetSlotLag(t *testing.T) {
+	client, localMock, networkMock := createTestClient()
+	localMock.On("GetSlot", mock.Anything, rpc.CommitmentProcessed).Return(uint64(1000), nil)
+	networkMock.On("GetSlot", mock.Anything, rpc.CommitmentProcessed).Return(uint64(1012), nil)
+
+	lag, err := client.GetSlotLag()
+
+	require.NoError(t, err)
+	assert.Equal(t, uint64(12), lag)
+}
+
+func TestGossipClient_GetSlotLag_LocalAhead(t *testing.T) {
+	client, localMock, networkMock := createTestClient()
+	localMock.On("GetSlot", mock.Anything, rpc.CommitmentProcessed).Return(uint64(1002), nil)
+	networkMock.On("GetSlot", mock.Anything, rpc.CommitmentProcessed).Return(uint64(1000), nil)
+
+	lag, err := client.GetSlotLag()
+
+	require.NoError(t, err)
+	assert.Zero(t, lag)
+}
+
+func TestGossipClient_GetSlotLag_LocalRPCError(t *testing.T) {
+	client, localMock, _ := createTestClient()
+	localMock.On("GetSlot", mock.Anything, rpc.CommitmentProcessed).Return(uint64(0), errors.New("connection refused"))
+
+	_, err := client.GetSlotLag()
+
+	assert.ErrorContains(t, err, "failed to get local slot")
+}
+
 func TestGossipClient_GetLocalNodeHealth_Success(t *testing.T) {
 	// Create test client with mocks
 	client, localMock, _ := createTestClient()
@@ -1113,3 +1260,270 @@ func BenchmarkGossipClient_GetTimeToNextLeaderSlotForPubkey(b *testing.B) {
 		_, _, _ = gossipClient.GetTimeToNextLeaderSlotForPubkey(pubkey)
 	}
 }
+
+func TestGossipClient_GetCurrentEpochInfo_Success(t *testing.T) {
+	client, _, networkMock := createTestClient()
+
+	networkMock.On("GetEpochInfo", mock.Anything, rpc.CommitmentConfirmed).Return(&rpc.GetEpochInfoResult{
+		AbsoluteSlot: 1050,
+		SlotIndex:    50,
+		Epoch:        2,
+	}, nil)
+
+	epochInfo, err := client.GetCurrentEpochInfo()
+
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1050), epochInfo.AbsoluteSlot)
+	assert.Equal(t, uint64(2), epochInfo.Epoch)
+	networkMock.AssertExpectations(t)
+}
+
+func TestGossipClient_GetCurrentEpochInfo_RPCError(t *testing.T) {
+	client, _, networkMock := createTestClient()
+
+	networkMock.On("GetEpochInfo", mock.Anything, rpc.CommitmentConfirmed).Return((*rpc.GetEpochInfoResult)(nil), errors.New("rpc error"))
+
+	epochInfo, err := client.GetCurrentEpochInfo()
+
+	assert.Error(t, err)
+	assert.Nil(t, epochInfo)
+	assert.Contains(t, err.Error(), "failed to get epoch info")
+}
+
+func TestGossipClient_GetBalance_Success(t *testing.T) {
+	client, _, networkMock := createTestClient()
+	pubkey := solanago.NewWallet().PublicKey()
+
+	networkMock.On("GetBalance", mock.Anything, pubkey, rpc.CommitmentConfirmed).Return(&rpc.GetBalanceResult{
+		Value: 1500000000,
+	}, nil)
+
+	lamports, err := client.GetBalance(pubkey)
+
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1500000000), lamports)
+	networkMock.AssertExpectations(t)
+}
+
+func TestGossipClient_GetBalance_RPCError(t *testing.T) {
+	client, _, networkMock := createTestClient()
+	pubkey := solanago.NewWallet().PublicKey()
+
+	networkMock.On("GetBalance", mock.Anything, pubkey, rpc.CommitmentConfirmed).Return((*rpc.GetBalanceResult)(nil), errors.New("rpc error"))
+
+	lamports, err := client.GetBalance(pubkey)
+
+	assert.Error(t, err)
+	assert.Equal(t, uint64(0), lamports)
+	assert.Contains(t, err.Error(), "failed to get balance")
+}
+
+func TestGossipClient_GetLocalValidatorMetrics_Success(t *testing.T) {
+	client, localMock, _ := createTestClient()
+	identity := solanago.NewWallet().PublicKey()
+
+	localMock.On("GetEpochInfo", mock.Anything, rpc.CommitmentProcessed).Return(&rpc.GetEpochInfoResult{
+		AbsoluteSlot: 5000,
+		SlotIndex:    4000,
+	}, nil)
+	localMock.On("GetTransactionCount", mock.Anything, rpc.CommitmentProcessed).Return(uint64(123456), nil)
+	localMock.On("GetBlockProductionWithOpts", mock.Anything, mock.MatchedBy(func(opts *rpc.GetBlockProductionOpts) bool {
+		return opts.Identity.Equals(identity) && opts.Range.FirstSlot == 4000 && *opts.Range.LastSlot == 5000
+	})).Return(&rpc.GetBlockProductionResult{
+		Value: rpc.BlockProductionResult{
+			ByIdentity: rpc.IdentityToSlotsBlocks{identity: {8, 6}},
+		},
+	}, nil)
+
+	metrics, err := client.GetLocalValidatorMetrics(identity, 0, 1000)
+
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5000), metrics.Slot)
+	assert.Equal(t, uint64(123456), metrics.TransactionCount)
+	assert.Equal(t, uint64(4000), metrics.BlockProductionFirstSlot)
+	assert.Equal(t, uint64(8), metrics.LeaderSlots)
+	assert.Equal(t, uint64(6), metrics.BlocksProduced)
+	assert.Equal(t, 25.0, metrics.SkipRate())
+	localMock.AssertExpectations(t)
+}
+
+func TestGossipClient_GetLocalValidatorMetrics_RPCError(t *testing.T) {
+	client, localMock, _ := createTestClient()
+
+	localMock.On("GetEpochInfo", mock.Anything, rpc.CommitmentProcessed).Return((*rpc.GetEpochInfoResult)(nil), errors.New("rpc error"))
+
+	metrics, err := client.GetLocalValidatorMetrics(solanago.NewWallet().PublicKey(), 0, 1000)
+
+	assert.Error(t, err)
+	assert.Nil(t, metrics)
+	assert.Contains(t, err.Error(), "failed to get local epoch info")
+}
+
+func TestBlockProductionFirstSlot(t *testing.T) {
+	epochInfo := &rpc.GetEpochInfoResult{AbsoluteSlot: 10500, SlotIndex: 500}
+
+	// clamped to the epoch start
+	assert.Equal(t, uint64(10000), blockProductionFirstSlot(epochInfo, 0, 1000))
+	// limited to maxSlots
+	assert.Equal(t, uint64(10300), blockProductionFirstSlot(epochInfo, 0, 200))
+	// starts at firstSlot when later
+	assert.Equal(t, uint64(10450), blockProductionFirstSlot(epochInfo, 10450, 200))
+	// firstSlot in the future is ignored
+	assert.Equal(t, uint64(10300), blockProductionFirstSlot(epochInfo, 20000, 200))
+}
+
+func TestGossipClient_GetFeatureStatuses(t *testing.T) {
+	client, _, networkMock := createTestClient()
+
+	missing := solanago.NewWallet().PublicKey()
+	pending := solanago.NewWallet().PublicKey()
+	active := solanago.NewWallet().PublicKey()
+	ids := []solanago.PublicKey{missing, pending, active}
+
+	activeData := make([]byte, 9)
+	activeData[0] = 1
+	binary.LittleEndian.PutUint64(activeData[1:], 123456)
+
+	networkMock.On("GetMultipleAccountsWithOpts", mock.Anything, ids, mock.Anything).Return(&rpc.GetMultipleAccountsResult{
+		Value: []*rpc.Account{
+			nil,
+			{Owner: FeatureProgramID, Data: rpc.DataBytesOrJSONFromBytes(make([]byte, 9))},
+			{Owner: FeatureProgramID, Data: rpc.DataBytesOrJSONFromBytes(activeData)},
+		},
+	}, nil)
+
+	statuses, err := client.GetFeatureStatuses(ids)
+
+	require.NoError(t, err)
+	require.Len(t, statuses, 3)
+	assert.False(t, statuses[0].Exists)
+	assert.False(t, statuses[0].IsPending())
+	assert.True(t, statuses[1].IsPending())
+	assert.False(t, statuses[1].IsActive())
+	assert.True(t, statuses[2].IsActive())
+	assert.Equal(t, uint64(123456), *statuses[2].ActivatedAt)
+	networkMock.AssertExpectations(t)
+}
+
+func TestGossipClient_GetFeatureStatuses_NotAFeature(t *testing.T) {
+	client, _, networkMock := createTestClient()
+
+	id := solanago.NewWallet().PublicKey()
+	networkMock.On("GetMultipleAccountsWithOpts", mock.Anything, []solanago.PublicKey{id}, mock.Anything).Return(&rpc.GetMultipleAccountsResult{
+		Value: []*rpc.Account{{Owner: solanago.SystemProgramID, Data: rpc.DataBytesOrJSONFromBytes([]byte{0})}},
+	}, nil)
+
+	_, err := client.GetFeatureStatuses([]solanago.PublicKey{id})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "is not a feature gate")
+}
+
+func TestGossipClient_GetRewardsContext_Success(t *testing.T) {
+	client, _, networkMock := createTestClient()
+
+	networkMock.On("GetInflationRate", mock.Anything).Return(&rpc.GetInflationRateResult{Validator: 0.05}, nil)
+	networkMock.On("GetSupply", mock.Anything, mock.Anything).Return(&rpc.GetSupplyResult{
+		Value: &rpc.SupplyResult{Total: 1_000_000_000},
+	}, nil)
+	networkMock.On("GetEpochInfo", mock.Anything, mock.Anything).Return(&rpc.GetEpochInfoResult{
+		Epoch:        10,
+		SlotIndex:    216000,
+		SlotsInEpoch: 432000,
+	}, nil)
+	networkMock.On("GetVoteAccounts", mock.Anything, mock.Anything).Return(&rpc.GetVoteAccountsResult{
+		Current: []rpc.VoteAccountsResult{
+			{
+				NodePubkey:     createTestPublicKey(1),
+				ActivatedStake: 1000,
+				Commission:     5,
+				EpochCredits:   [][]int64{{9, 1000, 500}, {10, 1100, 1000}},
+			},
+		},
+		Delinquent: []rpc.VoteAccountsResult{
+			{
+				NodePubkey:     createTestPublicKey(2),
+				ActivatedStake: 3000,
+				EpochCredits:   [][]int64{{10, 600, 500}},
+			},
+		},
+	}, nil)
+
+	rewardsContext, err := client.GetRewardsContext("11111111111111111111111111111111")
+
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10), rewardsContext.Epoch)
+	assert.Equal(t, uint64(1000), rewardsContext.ActivatedStake)
+	assert.Equal(t, uint8(5), rewardsContext.Commission)
+	assert.Equal(t, 400*time.Millisecond, rewardsContext.SlotTime)
+
+	// 4000 stake * 100 credits so far, projected over twice the slots
+	epochYears := float64(432000*400*time.Millisecond) / float64(yearDuration)
+	expectedPointValue := 0.05 * 1_000_000_000 * epochYears / 800000
+	assert.InDelta(t, expectedPointValue, rewardsContext.PointValue, 1e-9)
+	assert.InDelta(t, expectedPointValue*1000, rewardsContext.CreditValue(), 1e-6)
+
+	networkMock.AssertExpectations(t)
+}
+
+func TestGossipClient_GetRewardsContext_NotFound(t *testing.T) {
+	client, _, networkMock := createTestClient()
+
+	networkMock.On("GetInflationRate", mock.Anything).Return(&rpc.GetInflationRateResult{Validator: 0.05}, nil)
+	networkMock.On("GetSupply", mock.Anything, mock.Anything).Return(&rpc.GetSupplyResult{
+		Value: &rpc.SupplyResult{Total: 1_000_000_000},
+	}, nil)
+	networkMock.On("GetEpochInfo", mock.Anything, mock.Anything).Return(&rpc.GetEpochInfoResult{
+		Epoch:        10,
+		SlotIndex:    216000,
+		SlotsInEpoch: 432000,
+	}, nil)
+	networkMock.On("GetVoteAccounts", mock.Anything, mock.Anything).Return(&rpc.GetVoteAccountsResult{
+		Current: []rpc.VoteAccountsResult{
+			{NodePubkey: createTestPublicKey(2), ActivatedStake: 1000, EpochCredits: [][]int64{{10, 600, 500}}},
+		},
+	}, nil)
+
+	_, err := client.GetRewardsContext("11111111111111111111111111111111")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no vote account found")
+}
+
+func TestGossipClient_GetRewardsContext_RPCError(t *testing.T) {
+	client, _, networkMock := createTestClient()
+
+	networkMock.On("GetInflationRate", mock.Anything).Return((*rpc.GetInflationRateResult)(nil), errors.New("RPC connection failed"))
+
+	_, err := client.GetRewardsContext("11111111111111111111111111111111")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get inflation rate")
+
+	networkMock.AssertExpectations(t)
+}
+
+func TestRewardsContext_MissedVoteCredits(t *testing.T) {
+	rewardsContext := RewardsContext{SlotTime: 400 * time.Millisecond}
+
+	assert.Equal(t, uint64(0), rewardsContext.MissedVoteCredits(0))
+	assert.Equal(t, uint64(MaxVoteCreditsPerSlot), rewardsContext.MissedVoteCredits(100*time.Millisecond))
+	assert.Equal(t, uint64(5*MaxVoteCreditsPerSlot), rewardsContext.MissedVoteCredits(2*time.Second))
+	assert.Equal(t, uint64(0), RewardsContext{}.MissedVoteCredits(time.Second))
+}
+
+func TestRewardsContext_MissedRewards(t *testing.T) {
+	rewardsContext := RewardsContext{
+		PointValue:     0.001,
+		ActivatedStake: 1_000_000,
+		Commission:     10,
+		SlotTime:       400 * time.Millisecond,
+	}
+
+	// 2 slots, 32 credits at 1000 lamports each
+	credits, commissionLamports, delegatorLamports := rewardsContext.MissedRewards(800 * time.Millisecond)
+
+	assert.Equal(t, uint64(32), credits)
+	assert.Equal(t, uint64(3200), commissionLamports)
+	assert.Equal(t, uint64(28800), delegatorLamports)
+}
@@ -0,0 +1,72 @@
+package solana
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// DefaultClusterNodesCacheTTL is the default time cluster nodes are reused for gossip lookups
+const DefaultClusterNodesCacheTTL = 5 * time.Second
+
+// clusterNodesCache holds the last getClusterNodes result - thousands of entries on mainnet - so the gossip lookups a
+// failover or watch check makes in quick succession share one call. A nil cache or zero ttl caches nothing
+type clusterNodesCache struct {
+	ttl       time.Duration
+	nodes     []*rpc.GetClusterNodesResult
+	fetchedAt time.Time
+	now       func() time.Time
+	mutex     sync.Mutex
+}
+
+// newClusterNodesCache returns a cache reusing cluster nodes for ttl
+func newClusterNodesCache(ttl time.Duration) *clusterNodesCache {
+	return &clusterNodesCache{ttl: ttl, now: time.Now}
+}
+
+// get returns the cached cluster nodes while they are fresh, otherwise fetches and caches them - concurrent callers
+// wait on a single fetch. Failed fetches aren't cached
+func (c *clusterNodesCache) get(fetch func() ([]*rpc.GetClusterNodesResult, error)) ([]*rpc.GetClusterNodesResult, error) {
+	if c == nil || c.ttl <= 0 {
+		return fetch()
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.nodes != nil && c.now().Sub(c.fetchedAt) < c.ttl {
+		return c.nodes, nil
+	}
+
+	nodes, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	c.nodes = nodes
+	c.fetchedAt = c.now()
+	return nodes, nil
+}
+
+// invalidate drops the cached cluster nodes
+func (c *clusterNodesCache) invalidate() {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.nodes = nil
+}
+
+// getClusterNodes returns the cluster nodes, cached for the client's cluster nodes ttl
+func (c *Client) getClusterNodes() ([]*rpc.GetClusterNodesResult, error) {
+	return c.clusterNodes.get(func() ([]*rpc.GetClusterNodesResult, error) {
+		return c.networkRPCClient.GetClusterNodes(context.Background())
+	})
+}
+
+// InvalidateClusterNodes drops the cached cluster nodes so the next lookup fetches them again
+func (c *Client) InvalidateClusterNodes() {
+	c.clusterNodes.invalidate()
+}
@@ -0,0 +1,90 @@
+package solana
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func testClusterNodes() []*rpc.GetClusterNodesResult {
+	return []*rpc.GetClusterNodesResult{
+		{Pubkey: createTestPublicKey(1), Gossip: stringPtr("192.168.1.100:8001"), Version: stringPtr("1.16.0")},
+		{Pubkey: createTestPublicKey(2), Gossip: stringPtr("192.168.1.101:8001"), Version: stringPtr("1.16.0")},
+	}
+}
+
+func TestClusterNodesCache_SharesFetchWithinTTL(t *testing.T) {
+	client, _, networkMock := createTestClient()
+	client.clusterNodes = newClusterNodesCache(time.Minute)
+	networkMock.On("GetClusterNodes", mock.Anything).Return(testClusterNodes(), nil)
+
+	node, err := client.NodeFromIP("192.168.1.100")
+	require.NoError(t, err)
+	assert.Equal(t, createTestPublicKey(1).String(), node.PubKey())
+
+	node, err = client.NodeFromPubkey(createTestPublicKey(2).String())
+	require.NoError(t, err)
+	assert.Equal(t, "192.168.1.101", node.IP())
+
+	require.NoError(t, node.Refresh(client))
+	networkMock.AssertNumberOfCalls(t, "GetClusterNodes", 1)
+}
+
+func TestClusterNodesCache_FetchesAgainOnceExpired(t *testing.T) {
+	client, _, networkMock := createTestClient()
+	client.clusterNodes = newClusterNodesCache(time.Minute)
+	now := time.Now()
+	client.clusterNodes.now = func() time.Time { return now }
+	networkMock.On("GetClusterNodes", mock.Anything).Return(testClusterNodes(), nil)
+
+	_, err := client.NodeFromIP("192.168.1.100")
+	require.NoError(t, err)
+
+	now = now.Add(time.Minute)
+	_, err = client.NodeFromIP("192.168.1.100")
+	require.NoError(t, err)
+	networkMock.AssertNumberOfCalls(t, "GetClusterNodes", 2)
+}
+
+func TestClusterNodesCache_Invalidate(t *testing.T) {
+	client, _, networkMock := createTestClient()
+	client.clusterNodes = newClusterNodesCache(time.Minute)
+	networkMock.On("GetClusterNodes", mock.Anything).Return(testClusterNodes(), nil)
+
+	_, err := client.NodeFromIP("192.168.1.100")
+	require.NoError(t, err)
+	client.InvalidateClusterNodes()
+	_, err = client.NodeFromIP("192.168.1.100")
+	require.NoError(t, err)
+	networkMock.AssertNumberOfCalls(t, "GetClusterNodes", 2)
+}
+
+func TestClusterNodesCache_ErrorsNotCached(t *testing.T) {
+	client, _, networkMock := createTestClient()
+	client.clusterNodes = newClusterNodesCache(time.Minute)
+	networkMock.On("GetClusterNodes", mock.Anything).Return([]*rpc.GetClusterNodesResult(nil), errors.New("connection reset")).Once()
+	networkMock.On("GetClusterNodes", mock.Anything).Return(testClusterNodes(), nil)
+
+	_, err := client.NodeFromIP("192.168.1.100")
+	require.Error(t, err)
+	_, err = client.NodeFromIP("192.168.1.100")
+	require.NoError(t, err)
+	networkMock.AssertNumberOfCalls(t, "GetClusterNodes", 2)
+}
+
+func TestClusterNodesCache_ZeroTTLNeverCaches(t *testing.T) {
+	client, _, networkMock := createTestClient()
+	client.clusterNodes = newClusterNodesCache(0)
+	networkMock.On("GetClusterNodes", mock.Anything).Return(testClusterNodes(), nil)
+
+	for i := 0; i < 3; i++ {
+		_, err := client.NodeFromIP("192.168.1.100")
+		require.NoError(t, err)
+	}
+	networkMock.AssertNumberOfCalls(t, "GetClusterNodes", 3)
+}
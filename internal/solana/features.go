@@ -0,0 +1,88 @@
+package solana
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// FeatureProgramID owns every feature gate account
+var FeatureProgramID = solanago.MustPublicKeyFromBase58("Feature111111111111111111111111111111111111")
+
+// FeatureStatus is the activation status of a cluster feature gate
+type FeatureStatus struct {
+	ID solanago.PublicKey
+	// Exists is false when no feature account has been created - the feature is not scheduled for activation
+	Exists bool
+	// ActivatedAt is the slot the feature activated at - nil while it is pending activation at the next epoch boundary
+	ActivatedAt *uint64
+}
+
+// IsPending returns true when the feature is scheduled to activate at the next epoch boundary
+func (f FeatureStatus) IsPending() bool {
+	return f.Exists && f.ActivatedAt == nil
+}
+
+// IsActive returns true when the feature has activated
+func (f FeatureStatus) IsActive() bool {
+	return f.Exists && f.ActivatedAt != nil
+}
+
+// GetFeatureStatuses returns the activation status of the given feature gates from the network rpc
+func (c *Client) GetFeatureStatuses(ids []solanago.PublicKey) ([]FeatureStatus, error) {
+	result, err := c.networkRPCClient.GetMultipleAccountsWithOpts(context.Background(), ids, &rpc.GetMultipleAccountsOpts{
+		Encoding:   solanago.EncodingBase64,
+		Commitment: rpc.CommitmentConfirmed,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feature accounts: %w", err)
+	}
+	if len(result.Value) != len(ids) {
+		return nil, fmt.Errorf("expected %d feature accounts, got %d", len(ids), len(result.Value))
+	}
+
+	statuses := make([]FeatureStatus, len(ids))
+	for i, account := range result.Value {
+		statuses[i], err = parseFeatureAccount(ids[i], account)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return statuses, nil
+}
+
+// parseFeatureAccount parses a feature account - its data is a bincode Option<u64> of the activation slot
+func parseFeatureAccount(id solanago.PublicKey, account *rpc.Account) (FeatureStatus, error) {
+	status := FeatureStatus{ID: id}
+	if account == nil {
+		return status, nil
+	}
+
+	if !account.Owner.Equals(FeatureProgramID) {
+		return status, fmt.Errorf("account %s is not a feature gate - owned by %s", id, account.Owner)
+	}
+
+	var data []byte
+	if account.Data != nil {
+		data = account.Data.GetBinary()
+	}
+	if len(data) < 1 {
+		return status, fmt.Errorf("feature account %s has no data", id)
+	}
+
+	status.Exists = true
+	if data[0] == 0 {
+		return status, nil
+	}
+	if len(data) < 9 {
+		return status, fmt.Errorf("feature account %s data too short: %d bytes", id, len(data))
+	}
+
+	activatedAt := binary.LittleEndian.Uint64(data[1:9])
+	status.ActivatedAt = &activatedAt
+	return status, nil
+}
@@ -0,0 +1,87 @@
+package solana
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// LeaderGap is a run of slots in which an identity has no leader slots
+type LeaderGap struct {
+	FirstSlot uint64
+	LastSlot  uint64
+	// Start and End are the estimated wall-clock times FirstSlot starts and LastSlot ends
+	Start time.Time
+	End   time.Time
+	// EndsAtEpochEnd is true when no leader slot follows the gap this epoch - the next epoch's leader schedule isn't
+	// looked at so one may follow straight away
+	EndsAtEpochEnd bool
+}
+
+// Duration returns the estimated time the gap lasts
+func (g LeaderGap) Duration() time.Duration {
+	return g.End.Sub(g.Start)
+}
+
+// Slots returns the number of slots in the gap
+func (g LeaderGap) Slots() uint64 {
+	return g.LastSlot - g.FirstSlot + 1
+}
+
+// GetLeaderGaps returns the gaps between the given pubkey's leader slots from the current slot to the end of the
+// epoch, in order - wall-clock times are estimated from the average slot time
+func (c *Client) GetLeaderGaps(pubkey solanago.PublicKey) (gaps []LeaderGap, err error) {
+	epochInfo, err := c.networkRPCClient.GetEpochInfo(context.Background(), rpc.CommitmentConfirmed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get epoch info: %w", err)
+	}
+	now := time.Now()
+
+	leaderSchedule, err := c.networkRPCClient.GetLeaderSchedule(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get leader schedule: %w", err)
+	}
+
+	avgSlotTime, err := c.getAverageSlotTime()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get average slot time: %w", err)
+	}
+
+	currentSlot := epochInfo.AbsoluteSlot
+	firstSlotOfEpoch := currentSlot - epochInfo.SlotIndex
+	lastSlotOfEpoch := firstSlotOfEpoch + epochInfo.SlotsInEpoch - 1
+	slotTime := func(slot uint64) time.Time {
+		return now.Add(time.Duration(slot-currentSlot) * avgSlotTime)
+	}
+	newGap := func(firstSlot, lastSlot uint64) LeaderGap {
+		return LeaderGap{
+			FirstSlot: firstSlot,
+			LastSlot:  lastSlot,
+			Start:     slotTime(firstSlot),
+			End:       slotTime(lastSlot + 1),
+		}
+	}
+
+	// the schedule holds slot indices relative to the epoch's first slot, in order
+	previousSlot := currentSlot
+	for _, relativeSlot := range leaderSchedule[pubkey] {
+		leaderSlot := firstSlotOfEpoch + relativeSlot
+		if leaderSlot <= currentSlot {
+			continue
+		}
+		if leaderSlot > previousSlot+1 {
+			gaps = append(gaps, newGap(previousSlot+1, leaderSlot-1))
+		}
+		previousSlot = leaderSlot
+	}
+	if lastSlotOfEpoch > previousSlot {
+		gap := newGap(previousSlot+1, lastSlotOfEpoch)
+		gap.EndsAtEpochEnd = true
+		gaps = append(gaps, gap)
+	}
+
+	return gaps, nil
+}
@@ -0,0 +1,71 @@
+package solana
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLeaderGaps(t *testing.T) {
+	client, _, networkMock := createTestClient()
+	pubkey := createTestPublicKey(1)
+
+	networkMock.On("GetEpochInfo", mock.Anything, rpc.CommitmentConfirmed).Return(&rpc.GetEpochInfoResult{
+		AbsoluteSlot: 1100,
+		SlotIndex:    100,
+		SlotsInEpoch: 1000,
+	}, nil)
+	networkMock.On("GetLeaderSchedule", mock.Anything).Return(rpc.GetLeaderScheduleResult{
+		pubkey: []uint64{0, 1, 2, 3, 200, 201, 202, 203, 204, 205, 206, 207, 500, 501, 502, 503},
+	}, nil)
+
+	gaps, err := client.GetLeaderGaps(pubkey)
+	require.NoError(t, err)
+	require.Len(t, gaps, 3)
+
+	assert.Equal(t, uint64(1101), gaps[0].FirstSlot)
+	assert.Equal(t, uint64(1199), gaps[0].LastSlot)
+	assert.Equal(t, uint64(99), gaps[0].Slots())
+	assert.Equal(t, 99*400*time.Millisecond, gaps[0].Duration())
+	assert.False(t, gaps[0].EndsAtEpochEnd)
+
+	assert.Equal(t, uint64(1208), gaps[1].FirstSlot)
+	assert.Equal(t, uint64(1499), gaps[1].LastSlot)
+
+	assert.Equal(t, uint64(1504), gaps[2].FirstSlot)
+	assert.Equal(t, uint64(1999), gaps[2].LastSlot)
+	assert.True(t, gaps[2].EndsAtEpochEnd)
+	assert.True(t, gaps[2].Start.After(gaps[1].End))
+}
+
+func TestGetLeaderGaps_NotOnSchedule(t *testing.T) {
+	client, _, networkMock := createTestClient()
+
+	networkMock.On("GetEpochInfo", mock.Anything, rpc.CommitmentConfirmed).Return(&rpc.GetEpochInfoResult{
+		AbsoluteSlot: 1100,
+		SlotIndex:    100,
+		SlotsInEpoch: 1000,
+	}, nil)
+	networkMock.On("GetLeaderSchedule", mock.Anything).Return(rpc.GetLeaderScheduleResult{}, nil)
+
+	gaps, err := client.GetLeaderGaps(createTestPublicKey(1))
+	require.NoError(t, err)
+	require.Len(t, gaps, 1)
+	assert.Equal(t, uint64(1101), gaps[0].FirstSlot)
+	assert.Equal(t, uint64(1999), gaps[0].LastSlot)
+	assert.True(t, gaps[0].EndsAtEpochEnd)
+}
+
+func TestGetLeaderGaps_Error(t *testing.T) {
+	client, _, networkMock := createTestClient()
+	networkMock.On("GetEpochInfo", mock.Anything, rpc.CommitmentConfirmed).
+		Return((*rpc.GetEpochInfoResult)(nil), errors.New("connection refused"))
+
+	_, err := client.GetLeaderGaps(createTestPublicKey(1))
+	assert.ErrorContains(t, err, "failed to get epoch info")
+}
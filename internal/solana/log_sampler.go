@@ -0,0 +1,63 @@
+package solana
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// logSampler rate-limits repetitive debug logs on high-volume paths (e.g. the leader schedule being polled every
+// couple of seconds while waiting to fail over) - each message is logged at most once per interval along with
+// the number of times it was suppressed since it was last logged
+type logSampler struct {
+	interval time.Duration
+	mutex    sync.Mutex
+	messages map[string]*sampledMessage
+}
+
+// sampledMessage tracks when a sampled message was last logged
+type sampledMessage struct {
+	lastLoggedAt time.Time
+	suppressed   int
+}
+
+// newLogSampler creates a new log sampler - an interval of 0 disables sampling
+func newLogSampler(interval time.Duration) *logSampler {
+	return &logSampler{
+		interval: interval,
+		messages: make(map[string]*sampledMessage),
+	}
+}
+
+// Debug returns a debug event for the given message or a discarded event when it has already been logged within
+// the interval - callers finish the event with Msg(msg) as usual
+func (s *logSampler) Debug(msg string) *zerolog.Event {
+	e := log.Debug()
+	if e == nil || s == nil || s.interval <= 0 {
+		return e
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	sampled, ok := s.messages[msg]
+	if !ok {
+		s.messages[msg] = &sampledMessage{lastLoggedAt: now}
+		return e
+	}
+
+	if now.Sub(sampled.lastLoggedAt) < s.interval {
+		sampled.suppressed++
+		return e.Discard()
+	}
+
+	if sampled.suppressed > 0 {
+		e = e.Int("suppressed", sampled.suppressed)
+	}
+	sampled.lastLoggedAt = now
+	sampled.suppressed = 0
+	return e
+}
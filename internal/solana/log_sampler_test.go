@@ -0,0 +1,48 @@
+package solana
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+// captureDebugLogs sends debug logs to a buffer for the duration of the test
+func captureDebugLogs(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	original := log.Logger
+	log.Logger = zerolog.New(&buf).Level(zerolog.DebugLevel)
+	t.Cleanup(func() { log.Logger = original })
+	return &buf
+}
+
+func TestLogSampler_SuppressesRepeatsWithinInterval(t *testing.T) {
+	buf := captureDebugLogs(t)
+	sampler := newLogSampler(50 * time.Millisecond)
+
+	for i := 0; i < 5; i++ {
+		sampler.Debug("repeated").Int("i", i).Msg("repeated")
+	}
+	sampler.Debug("other").Msg("other")
+	assert.Equal(t, 2, strings.Count(buf.String(), "\n"))
+
+	time.Sleep(60 * time.Millisecond)
+	buf.Reset()
+	sampler.Debug("repeated").Msg("repeated")
+	assert.Contains(t, buf.String(), `"suppressed":4`)
+}
+
+func TestLogSampler_ZeroIntervalLogsEverything(t *testing.T) {
+	buf := captureDebugLogs(t)
+	sampler := newLogSampler(0)
+
+	for i := 0; i < 3; i++ {
+		sampler.Debug("repeated").Msg("repeated")
+	}
+	assert.Equal(t, 3, strings.Count(buf.String(), "\n"))
+	assert.NotContains(t, buf.String(), "suppressed")
+}
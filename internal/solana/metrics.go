@@ -0,0 +1,23 @@
+package solana
+
+// ValidatorMetrics is a point-in-time snapshot of a validator identity's metrics as seen by an rpc node
+type ValidatorMetrics struct {
+	// Slot is the rpc node's current processed slot
+	Slot uint64
+	// TransactionCount is the cumulative transaction count reported by the rpc node
+	TransactionCount uint64
+	// BlockProductionFirstSlot is the first slot of the range block production covers - it ends at Slot
+	BlockProductionFirstSlot uint64
+	// LeaderSlots is the number of leader slots the identity had in the block production range
+	LeaderSlots uint64
+	// BlocksProduced is the number of blocks the identity produced in the block production range
+	BlocksProduced uint64
+}
+
+// SkipRate returns the percentage of leader slots skipped in the block production range
+func (m *ValidatorMetrics) SkipRate() float64 {
+	if m.LeaderSlots == 0 {
+		return 0
+	}
+	return 100 * float64(m.LeaderSlots-m.BlocksProduced) / float64(m.LeaderSlots)
+}
@@ -22,13 +22,36 @@ type MockClient struct {
 
 	// Vote account methods
 	getCreditRankedVoteAccountFromPubkey func(pubkey string) (*rpc.VoteAccountsResult, int, error)
+	getVoteAccountStatus                 func(pubkey string) (VoteAccountStatus, error)
 
 	// Slot methods
 	getCurrentSlot        func() (uint64, error)
+	getSlotLag            func() (uint64, error)
 	getCurrentSlotEndTime func() (time.Time, error)
+	getCurrentEpochInfo   func() (*rpc.GetEpochInfoResult, error)
+
+	// RPC endpoints
+	networkRPCURL string
+
+	// Account methods
+	getBalance func(pubkey solana.PublicKey) (uint64, error)
+
+	// Metrics methods
+	getLocalValidatorMetrics func(identity solana.PublicKey, firstSlot, maxSlots uint64) (*ValidatorMetrics, error)
+
+	// Feature methods
+	getFeatureStatuses func(ids []solana.PublicKey) ([]FeatureStatus, error)
+
+	// Rewards methods
+	getRewardsContext func(pubkey string) (*RewardsContext, error)
 
 	// Leader schedule methods
 	getTimeToNextLeaderSlotForPubkey func(pubkey solana.PublicKey) (bool, time.Duration, error)
+	getLeaderSlotsAfter              func(pubkey solana.PublicKey, slot uint64, count int) ([]uint64, error)
+	getLeaderGaps                    func(pubkey solana.PublicKey) ([]LeaderGap, error)
+
+	// Block production methods
+	getBlockProduction func(pubkey solana.PublicKey, firstSlot, lastSlot uint64) (BlockProduction, error)
 }
 
 // NewMockClient creates a new mock client with default behaviors
@@ -81,24 +104,90 @@ func (m *MockClient) WithGetCreditRankedVoteAccountFromPubkey(fn func(pubkey str
 	return m
 }
 
+// WithGetVoteAccountStatus sets a custom GetVoteAccountStatus function
+func (m *MockClient) WithGetVoteAccountStatus(fn func(pubkey string) (VoteAccountStatus, error)) *MockClient {
+	m.getVoteAccountStatus = fn
+	return m
+}
+
 // WithGetCurrentSlot sets a custom GetCurrentSlot function
 func (m *MockClient) WithGetCurrentSlot(fn func() (uint64, error)) *MockClient {
 	m.getCurrentSlot = fn
 	return m
 }
 
+// WithGetSlotLag sets a custom GetSlotLag function
+func (m *MockClient) WithGetSlotLag(fn func() (uint64, error)) *MockClient {
+	m.getSlotLag = fn
+	return m
+}
+
 // WithGetCurrentSlotEndTime sets a custom GetCurrentSlotEndTime function
 func (m *MockClient) WithGetCurrentSlotEndTime(fn func() (time.Time, error)) *MockClient {
 	m.getCurrentSlotEndTime = fn
 	return m
 }
 
+// WithGetCurrentEpochInfo sets a custom GetCurrentEpochInfo function
+func (m *MockClient) WithGetCurrentEpochInfo(fn func() (*rpc.GetEpochInfoResult, error)) *MockClient {
+	m.getCurrentEpochInfo = fn
+	return m
+}
+
+// WithNetworkRPCURL sets the network rpc url
+func (m *MockClient) WithNetworkRPCURL(url string) *MockClient {
+	m.networkRPCURL = url
+	return m
+}
+
+// WithGetLocalValidatorMetrics sets the GetLocalValidatorMetrics function
+func (m *MockClient) WithGetLocalValidatorMetrics(fn func(identity solana.PublicKey, firstSlot, maxSlots uint64) (*ValidatorMetrics, error)) *MockClient {
+	m.getLocalValidatorMetrics = fn
+	return m
+}
+
+// WithGetFeatureStatuses sets the GetFeatureStatuses function
+func (m *MockClient) WithGetFeatureStatuses(fn func(ids []solana.PublicKey) ([]FeatureStatus, error)) *MockClient {
+	m.getFeatureStatuses = fn
+	return m
+}
+
+// WithGetRewardsContext sets the GetRewardsContext function
+func (m *MockClient) WithGetRewardsContext(fn func(pubkey string) (*RewardsContext, error)) *MockClient {
+	m.getRewardsContext = fn
+	return m
+}
+
+// WithGetBalance sets the GetBalance function
+func (m *MockClient) WithGetBalance(fn func(pubkey solana.PublicKey) (uint64, error)) *MockClient {
+	m.getBalance = fn
+	return m
+}
+
 // WithGetTimeToNextLeaderSlotForPubkey sets a custom GetTimeToNextLeaderSlotForPubkey function
 func (m *MockClient) WithGetTimeToNextLeaderSlotForPubkey(fn func(pubkey solana.PublicKey) (bool, time.Duration, error)) *MockClient {
 	m.getTimeToNextLeaderSlotForPubkey = fn
 	return m
 }
 
+// WithGetLeaderSlotsAfter sets a custom GetLeaderSlotsAfter function
+func (m *MockClient) WithGetLeaderSlotsAfter(fn func(pubkey solana.PublicKey, slot uint64, count int) ([]uint64, error)) *MockClient {
+	m.getLeaderSlotsAfter = fn
+	return m
+}
+
+// WithGetLeaderGaps sets a custom GetLeaderGaps function
+func (m *MockClient) WithGetLeaderGaps(fn func(pubkey solana.PublicKey) ([]LeaderGap, error)) *MockClient {
+	m.getLeaderGaps = fn
+	return m
+}
+
+// WithGetBlockProduction sets a custom GetBlockProduction function
+func (m *MockClient) WithGetBlockProduction(fn func(pubkey solana.PublicKey, firstSlot, lastSlot uint64) (BlockProduction, error)) *MockClient {
+	m.getBlockProduction = fn
+	return m
+}
+
 // WithMockNode sets the mock node
 func (m *MockClient) WithMockNode(node *Node) *MockClient {
 	m.mockNode = node
@@ -129,6 +218,14 @@ func (m *MockClient) GetCreditRankedVoteAccountFromPubkey(pubkey string) (*rpc.V
 	return nil, 0, nil
 }
 
+// GetVoteAccountStatus implements ClientInterface.GetVoteAccountStatus
+func (m *MockClient) GetVoteAccountStatus(pubkey string) (VoteAccountStatus, error) {
+	if m.getVoteAccountStatus != nil {
+		return m.getVoteAccountStatus(pubkey)
+	}
+	return VoteAccountStatus{Found: true}, nil
+}
+
 // GetCurrentSlot implements ClientInterface.GetCurrentSlot
 func (m *MockClient) GetCurrentSlot() (uint64, error) {
 	if m.getCurrentSlot != nil {
@@ -137,6 +234,14 @@ func (m *MockClient) GetCurrentSlot() (uint64, error) {
 	return 0, nil
 }
 
+// GetSlotLag implements ClientInterface.GetSlotLag
+func (m *MockClient) GetSlotLag() (uint64, error) {
+	if m.getSlotLag != nil {
+		return m.getSlotLag()
+	}
+	return 0, nil
+}
+
 // GetCurrentSlotEndTime implements ClientInterface.GetCurrentSlotEndTime
 func (m *MockClient) GetCurrentSlotEndTime() (time.Time, error) {
 	if m.getCurrentSlotEndTime != nil {
@@ -145,6 +250,58 @@ func (m *MockClient) GetCurrentSlotEndTime() (time.Time, error) {
 	return time.Time{}, nil
 }
 
+// GetCurrentEpochInfo implements ClientInterface.GetCurrentEpochInfo
+func (m *MockClient) GetCurrentEpochInfo() (*rpc.GetEpochInfoResult, error) {
+	if m.getCurrentEpochInfo != nil {
+		return m.getCurrentEpochInfo()
+	}
+	return &rpc.GetEpochInfoResult{}, nil
+}
+
+// NetworkRPCURL implements ClientInterface.NetworkRPCURL
+func (m *MockClient) NetworkRPCURL() string {
+	return m.networkRPCURL
+}
+
+// InvalidateClusterNodes implements ClientInterface.InvalidateClusterNodes - the mock caches nothing
+func (m *MockClient) InvalidateClusterNodes() {}
+
+// GetBalance implements ClientInterface.GetBalance
+func (m *MockClient) GetBalance(pubkey solana.PublicKey) (uint64, error) {
+	if m.getBalance != nil {
+		return m.getBalance(pubkey)
+	}
+	return 0, nil
+}
+
+// GetLocalValidatorMetrics implements ClientInterface.GetLocalValidatorMetrics
+func (m *MockClient) GetLocalValidatorMetrics(identity solana.PublicKey, firstSlot, maxSlots uint64) (*ValidatorMetrics, error) {
+	if m.getLocalValidatorMetrics != nil {
+		return m.getLocalValidatorMetrics(identity, firstSlot, maxSlots)
+	}
+	return &ValidatorMetrics{}, nil
+}
+
+// GetFeatureStatuses implements ClientInterface.GetFeatureStatuses
+func (m *MockClient) GetFeatureStatuses(ids []solana.PublicKey) ([]FeatureStatus, error) {
+	if m.getFeatureStatuses != nil {
+		return m.getFeatureStatuses(ids)
+	}
+	statuses := make([]FeatureStatus, len(ids))
+	for i, id := range ids {
+		statuses[i] = FeatureStatus{ID: id}
+	}
+	return statuses, nil
+}
+
+// GetRewardsContext implements ClientInterface.GetRewardsContext
+func (m *MockClient) GetRewardsContext(pubkey string) (*RewardsContext, error) {
+	if m.getRewardsContext != nil {
+		return m.getRewardsContext(pubkey)
+	}
+	return &RewardsContext{SlotTime: 400 * time.Millisecond}, nil
+}
+
 // GetTimeToNextLeaderSlotForPubkey implements ClientInterface.GetTimeToNextLeaderSlotForPubkey
 func (m *MockClient) GetTimeToNextLeaderSlotForPubkey(pubkey solana.PublicKey) (bool, time.Duration, error) {
 	if m.getTimeToNextLeaderSlotForPubkey != nil {
@@ -153,6 +310,30 @@ func (m *MockClient) GetTimeToNextLeaderSlotForPubkey(pubkey solana.PublicKey) (
 	return false, 0, nil
 }
 
+// GetLeaderSlotsAfter implements ClientInterface.GetLeaderSlotsAfter
+func (m *MockClient) GetLeaderSlotsAfter(pubkey solana.PublicKey, slot uint64, count int) ([]uint64, error) {
+	if m.getLeaderSlotsAfter != nil {
+		return m.getLeaderSlotsAfter(pubkey, slot, count)
+	}
+	return nil, nil
+}
+
+// GetLeaderGaps implements ClientInterface.GetLeaderGaps
+func (m *MockClient) GetLeaderGaps(pubkey solana.PublicKey) ([]LeaderGap, error) {
+	if m.getLeaderGaps != nil {
+		return m.getLeaderGaps(pubkey)
+	}
+	return nil, nil
+}
+
+// GetBlockProduction implements ClientInterface.GetBlockProduction
+func (m *MockClient) GetBlockProduction(pubkey solana.PublicKey, firstSlot, lastSlot uint64) (BlockProduction, error) {
+	if m.getBlockProduction != nil {
+		return m.getBlockProduction(pubkey, firstSlot, lastSlot)
+	}
+	return BlockProduction{FirstSlot: firstSlot, LastSlot: lastSlot}, nil
+}
+
 // GetLocalNodeHealth implements ClientInterface.GetLocalNodeHealth
 func (m *MockClient) GetLocalNodeHealth() (string, error) {
 	if m.getLocalNodeHealth != nil {
@@ -277,10 +458,15 @@ func (b *MockClientBuilder) Build() *MockClient {
 
 // NewMockNode creates a new mock node for testing
 func NewMockNode(pubkey solana.PublicKey, version string) *Node {
+	return NewMockNodeAt(pubkey, version, "192.168.1.100")
+}
+
+// NewMockNodeAt creates a new mock node for testing advertising gossip on ip
+func NewMockNodeAt(pubkey solana.PublicKey, version, ip string) *Node {
 	return &Node{
 		gossipNode: &rpc.GetClusterNodesResult{
 			Pubkey:  pubkey,
-			Gossip:  stringPtr("192.168.1.100:8001"),
+			Gossip:  stringPtr(ip + ":8001"),
 			Version: stringPtr(version),
 		},
 	}
@@ -0,0 +1,247 @@
+package solana
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/utils"
+)
+
+const (
+	// DefaultNetworkRPCTimeout is the default time a network rpc call may take before the next endpoint is tried
+	DefaultNetworkRPCTimeout = 10 * time.Second
+	// DefaultNetworkRPCCooldown is the default time a failed network rpc endpoint is tried after healthy ones
+	DefaultNetworkRPCCooldown = 30 * time.Second
+
+	// rpcErrorCodeNodeUnhealthy is the json rpc error an rpc node answers with when it is behind
+	rpcErrorCodeNodeUnhealthy = -32005
+)
+
+// networkRPCEndpoint is a network rpc url and its health
+type networkRPCEndpoint struct {
+	url            string
+	client         RPCClientInterface
+	failures       int
+	unhealthyUntil time.Time
+}
+
+// networkRPCPool is an RPCClientInterface over several network rpc endpoints - each call goes to the first healthy
+// endpoint in the configured order and on to the next when it errors or times out. An endpoint that failed is only
+// tried after healthy ones until its cooldown passes
+type networkRPCPool struct {
+	endpoints []*networkRPCEndpoint
+	timeout   time.Duration
+	cooldown  time.Duration
+	lastURL   string
+	mutex     sync.Mutex
+}
+
+// newNetworkRPCPool creates a pool over the given urls, tried in order - duplicates are dropped
+func newNetworkRPCPool(urls []string, timeout, cooldown time.Duration, newClient func(url string) RPCClientInterface) *networkRPCPool {
+	pool := &networkRPCPool{timeout: timeout, cooldown: cooldown}
+	seen := map[string]bool{}
+	for _, url := range urls {
+		if url == "" || seen[url] {
+			continue
+		}
+		seen[url] = true
+		pool.endpoints = append(pool.endpoints, &networkRPCEndpoint{url: url, client: newClient(url)})
+	}
+	if len(pool.endpoints) > 0 {
+		pool.lastURL = pool.endpoints[0].url
+	}
+	return pool
+}
+
+// URL returns the url of the endpoint that answered last - the first endpoint before any call
+func (p *networkRPCPool) URL() string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.lastURL
+}
+
+// order returns the endpoints to try - healthy ones in the configured order, then those cooling down, soonest to
+// recover first so a call is still attempted when every endpoint has failed
+func (p *networkRPCPool) order() []*networkRPCEndpoint {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := time.Now()
+	healthy := make([]*networkRPCEndpoint, 0, len(p.endpoints))
+	var coolingDown []*networkRPCEndpoint
+	for _, endpoint := range p.endpoints {
+		if now.Before(endpoint.unhealthyUntil) {
+			coolingDown = append(coolingDown, endpoint)
+			continue
+		}
+		healthy = append(healthy, endpoint)
+	}
+	for i := 1; i < len(coolingDown); i++ {
+		for j := i; j > 0 && coolingDown[j].unhealthyUntil.Before(coolingDown[j-1].unhealthyUntil); j-- {
+			coolingDown[j], coolingDown[j-1] = coolingDown[j-1], coolingDown[j]
+		}
+	}
+	return append(healthy, coolingDown...)
+}
+
+// record updates an endpoint's health after a call
+func (p *networkRPCPool) record(endpoint *networkRPCEndpoint, err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if err == nil {
+		endpoint.failures = 0
+		endpoint.unhealthyUntil = time.Time{}
+		p.lastURL = endpoint.url
+		return
+	}
+	endpoint.failures++
+	endpoint.unhealthyUntil = time.Now().Add(p.cooldown)
+}
+
+// isEndpointError returns true if err says the endpoint rather than the request is at fault - an rpc error answered
+// by a healthy node would be answered the same by any other
+func isEndpointError(err error) bool {
+	var rpcErr *jsonrpc.RPCError
+	if errors.As(err, &rpcErr) {
+		return rpcErr.Code == rpcErrorCodeNodeUnhealthy
+	}
+	return true
+}
+
+// callNetworkRPC makes a call on each endpoint in turn until one answers - the error of every endpoint tried is
+// returned when none does
+func callNetworkRPC[T any](p *networkRPCPool, ctx context.Context, method string, call func(ctx context.Context, client RPCClientInterface) (T, error)) (result T, err error) {
+	endpoints := p.order()
+	if len(endpoints) == 0 {
+		return result, fmt.Errorf("no network rpc endpoints configured for %s", method)
+	}
+
+	var errs []error
+	for i, endpoint := range endpoints {
+		callCtx, cancel := ctx, context.CancelFunc(func() {})
+		if p.timeout > 0 {
+			callCtx, cancel = context.WithTimeout(ctx, p.timeout)
+		}
+		result, err = call(callCtx, endpoint.client)
+		cancel()
+
+		if err == nil || !isEndpointError(err) {
+			p.record(endpoint, nil)
+			return result, err
+		}
+		p.record(endpoint, err)
+		errs = append(errs, fmt.Errorf("%s: %w", utils.RedactURL(endpoint.url), err))
+
+		if ctx.Err() != nil {
+			break
+		}
+		if i < len(endpoints)-1 {
+			log.Warn().
+				Err(err).
+				Str("method", method).
+				Str("network_rpc_url", utils.RedactURL(endpoint.url)).
+				Str("next_network_rpc_url", utils.RedactURL(endpoints[i+1].url)).
+				Msg("network rpc call failed - trying the next endpoint")
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+// GetClusterNodes implements RPCClientInterface
+func (p *networkRPCPool) GetClusterNodes(ctx context.Context) ([]*rpc.GetClusterNodesResult, error) {
+	return callNetworkRPC(p, ctx, "getClusterNodes", func(ctx context.Context, client RPCClientInterface) ([]*rpc.GetClusterNodesResult, error) {
+		return client.GetClusterNodes(ctx)
+	})
+}
+
+// GetVoteAccounts implements RPCClientInterface
+func (p *networkRPCPool) GetVoteAccounts(ctx context.Context, opts *rpc.GetVoteAccountsOpts) (*rpc.GetVoteAccountsResult, error) {
+	return callNetworkRPC(p, ctx, "getVoteAccounts", func(ctx context.Context, client RPCClientInterface) (*rpc.GetVoteAccountsResult, error) {
+		return client.GetVoteAccounts(ctx, opts)
+	})
+}
+
+// GetSlot implements RPCClientInterface
+func (p *networkRPCPool) GetSlot(ctx context.Context, commitment rpc.CommitmentType) (uint64, error) {
+	return callNetworkRPC(p, ctx, "getSlot", func(ctx context.Context, client RPCClientInterface) (uint64, error) {
+		return client.GetSlot(ctx, commitment)
+	})
+}
+
+// GetLeaderSchedule implements RPCClientInterface
+func (p *networkRPCPool) GetLeaderSchedule(ctx context.Context) (rpc.GetLeaderScheduleResult, error) {
+	return callNetworkRPC(p, ctx, "getLeaderSchedule", func(ctx context.Context, client RPCClientInterface) (rpc.GetLeaderScheduleResult, error) {
+		return client.GetLeaderSchedule(ctx)
+	})
+}
+
+// GetBlockTime implements RPCClientInterface
+func (p *networkRPCPool) GetBlockTime(ctx context.Context, slot uint64) (*solanago.UnixTimeSeconds, error) {
+	return callNetworkRPC(p, ctx, "getBlockTime", func(ctx context.Context, client RPCClientInterface) (*solanago.UnixTimeSeconds, error) {
+		return client.GetBlockTime(ctx, slot)
+	})
+}
+
+// GetHealth implements RPCClientInterface
+func (p *networkRPCPool) GetHealth(ctx context.Context) (string, error) {
+	return callNetworkRPC(p, ctx, "getHealth", func(ctx context.Context, client RPCClientInterface) (string, error) {
+		return client.GetHealth(ctx)
+	})
+}
+
+// GetEpochInfo implements RPCClientInterface
+func (p *networkRPCPool) GetEpochInfo(ctx context.Context, commitment rpc.CommitmentType) (*rpc.GetEpochInfoResult, error) {
+	return callNetworkRPC(p, ctx, "getEpochInfo", func(ctx context.Context, client RPCClientInterface) (*rpc.GetEpochInfoResult, error) {
+		return client.GetEpochInfo(ctx, commitment)
+	})
+}
+
+// GetBalance implements RPCClientInterface
+func (p *networkRPCPool) GetBalance(ctx context.Context, account solanago.PublicKey, commitment rpc.CommitmentType) (*rpc.GetBalanceResult, error) {
+	return callNetworkRPC(p, ctx, "getBalance", func(ctx context.Context, client RPCClientInterface) (*rpc.GetBalanceResult, error) {
+		return client.GetBalance(ctx, account, commitment)
+	})
+}
+
+// GetBlockProductionWithOpts implements RPCClientInterface
+func (p *networkRPCPool) GetBlockProductionWithOpts(ctx context.Context, opts *rpc.GetBlockProductionOpts) (*rpc.GetBlockProductionResult, error) {
+	return callNetworkRPC(p, ctx, "getBlockProduction", func(ctx context.Context, client RPCClientInterface) (*rpc.GetBlockProductionResult, error) {
+		return client.GetBlockProductionWithOpts(ctx, opts)
+	})
+}
+
+// GetTransactionCount implements RPCClientInterface
+func (p *networkRPCPool) GetTransactionCount(ctx context.Context, commitment rpc.CommitmentType) (uint64, error) {
+	return callNetworkRPC(p, ctx, "getTransactionCount", func(ctx context.Context, client RPCClientInterface) (uint64, error) {
+		return client.GetTransactionCount(ctx, commitment)
+	})
+}
+
+// GetMultipleAccountsWithOpts implements RPCClientInterface
+func (p *networkRPCPool) GetMultipleAccountsWithOpts(ctx context.Context, accounts []solanago.PublicKey, opts *rpc.GetMultipleAccountsOpts) (*rpc.GetMultipleAccountsResult, error) {
+	return callNetworkRPC(p, ctx, "getMultipleAccounts", func(ctx context.Context, client RPCClientInterface) (*rpc.GetMultipleAccountsResult, error) {
+		return client.GetMultipleAccountsWithOpts(ctx, accounts, opts)
+	})
+}
+
+// GetInflationRate implements RPCClientInterface
+func (p *networkRPCPool) GetInflationRate(ctx context.Context) (*rpc.GetInflationRateResult, error) {
+	return callNetworkRPC(p, ctx, "getInflationRate", func(ctx context.Context, client RPCClientInterface) (*rpc.GetInflationRateResult, error) {
+		return client.GetInflationRate(ctx)
+	})
+}
+
+// GetSupply implements RPCClientInterface
+func (p *networkRPCPool) GetSupply(ctx context.Context, commitment rpc.CommitmentType) (*rpc.GetSupplyResult, error) {
+	return callNetworkRPC(p, ctx, "getSupply", func(ctx context.Context, client RPCClientInterface) (*rpc.GetSupplyResult, error) {
+		return client.GetSupply(ctx, commitment)
+	})
+}
@@ -0,0 +1,160 @@
+package solana
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestNetworkRPCPool creates a pool over mocks keyed by url
+func newTestNetworkRPCPool(urls []string, timeout, cooldown time.Duration) (*networkRPCPool, map[string]*MockRPCClient) {
+	mocks := map[string]*MockRPCClient{}
+	pool := newNetworkRPCPool(urls, timeout, cooldown, func(url string) RPCClientInterface {
+		mocks[url] = &MockRPCClient{}
+		return mocks[url]
+	})
+	return pool, mocks
+}
+
+func TestNewNetworkRPCPool_DropsDuplicates(t *testing.T) {
+	pool, _ := newTestNetworkRPCPool([]string{"http://a", "", "http://b", "http://a"}, 0, time.Minute)
+
+	require.Len(t, pool.endpoints, 2)
+	assert.Equal(t, "http://a", pool.endpoints[0].url)
+	assert.Equal(t, "http://b", pool.endpoints[1].url)
+	assert.Equal(t, "http://a", pool.URL())
+}
+
+func TestNetworkRPCPool_FailsOver(t *testing.T) {
+	pool, mocks := newTestNetworkRPCPool([]string{"http://a", "http://b"}, 0, time.Minute)
+	mocks["http://a"].On("GetSlot", mock.Anything, rpc.CommitmentConfirmed).Return(uint64(0), errors.New("connection refused"))
+	mocks["http://b"].On("GetSlot", mock.Anything, rpc.CommitmentConfirmed).Return(uint64(1000), nil)
+
+	slot, err := pool.GetSlot(context.Background(), rpc.CommitmentConfirmed)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1000), slot)
+	assert.Equal(t, "http://b", pool.URL())
+	assert.Equal(t, 1, pool.endpoints[0].failures)
+
+	// a cooling down endpoint is tried last
+	slot, err = pool.GetSlot(context.Background(), rpc.CommitmentConfirmed)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1000), slot)
+	mocks["http://a"].AssertNumberOfCalls(t, "GetSlot", 1)
+	mocks["http://b"].AssertNumberOfCalls(t, "GetSlot", 2)
+}
+
+func TestNetworkRPCPool_RecoversAfterCooldown(t *testing.T) {
+	pool, mocks := newTestNetworkRPCPool([]string{"http://a", "http://b"}, 0, time.Millisecond)
+	mocks["http://a"].On("GetSlot", mock.Anything, rpc.CommitmentConfirmed).Return(uint64(0), errors.New("connection refused")).Once()
+	mocks["http://a"].On("GetSlot", mock.Anything, rpc.CommitmentConfirmed).Return(uint64(1001), nil)
+	mocks["http://b"].On("GetSlot", mock.Anything, rpc.CommitmentConfirmed).Return(uint64(1000), nil)
+
+	_, err := pool.GetSlot(context.Background(), rpc.CommitmentConfirmed)
+	require.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+
+	slot, err := pool.GetSlot(context.Background(), rpc.CommitmentConfirmed)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1001), slot)
+	assert.Equal(t, "http://a", pool.URL())
+	assert.Equal(t, 0, pool.endpoints[0].failures)
+}
+
+func TestNetworkRPCPool_TriesCoolingDownWhenAllFailed(t *testing.T) {
+	pool, mocks := newTestNetworkRPCPool([]string{"http://a", "http://b"}, 0, time.Minute)
+	mocks["http://a"].On("GetHealth", mock.Anything).Return("", errors.New("a down")).Once()
+	mocks["http://a"].On("GetHealth", mock.Anything).Return("ok", nil)
+	mocks["http://b"].On("GetHealth", mock.Anything).Return("", errors.New("b down"))
+
+	_, err := pool.GetHealth(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "http://a: a down")
+	assert.Contains(t, err.Error(), "http://b: b down")
+
+	// both cooling down - a recovers first so is tried first
+	health, err := pool.GetHealth(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "ok", health)
+	mocks["http://b"].AssertNumberOfCalls(t, "GetHealth", 1)
+}
+
+func TestNetworkRPCPool_RequestErrorsDontFailOver(t *testing.T) {
+	pool, mocks := newTestNetworkRPCPool([]string{"http://a", "http://b"}, 0, time.Minute)
+	mocks["http://a"].On("GetBlockTime", mock.Anything, uint64(5)).Return((*solanago.UnixTimeSeconds)(nil), &jsonrpc.RPCError{Code: -32009, Message: "slot skipped"})
+
+	_, err := pool.GetBlockTime(context.Background(), 5)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "slot skipped")
+	assert.Equal(t, 0, pool.endpoints[0].failures)
+	mocks["http://b"].AssertNotCalled(t, "GetBlockTime", mock.Anything, mock.Anything)
+}
+
+func TestNetworkRPCPool_UnhealthyNodeFailsOver(t *testing.T) {
+	pool, mocks := newTestNetworkRPCPool([]string{"http://a", "http://b"}, 0, time.Minute)
+	mocks["http://a"].On("GetEpochInfo", mock.Anything, rpc.CommitmentConfirmed).Return((*rpc.GetEpochInfoResult)(nil), &jsonrpc.RPCError{Code: rpcErrorCodeNodeUnhealthy, Message: "Node is behind by 120 slots"})
+	mocks["http://b"].On("GetEpochInfo", mock.Anything, rpc.CommitmentConfirmed).Return(&rpc.GetEpochInfoResult{Epoch: 7}, nil)
+
+	epochInfo, err := pool.GetEpochInfo(context.Background(), rpc.CommitmentConfirmed)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), epochInfo.Epoch)
+}
+
+func TestNetworkRPCPool_Timeout(t *testing.T) {
+	pool, mocks := newTestNetworkRPCPool([]string{"http://a", "http://b"}, 10*time.Millisecond, time.Minute)
+	mocks["http://a"].On("GetSlot", mock.Anything, rpc.CommitmentConfirmed).
+		Run(func(args mock.Arguments) { <-args.Get(0).(context.Context).Done() }).
+		Return(uint64(0), context.DeadlineExceeded)
+	mocks["http://b"].On("GetSlot", mock.Anything, rpc.CommitmentConfirmed).Return(uint64(1000), nil)
+
+	slot, err := pool.GetSlot(context.Background(), rpc.CommitmentConfirmed)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1000), slot)
+}
+
+func TestNewRPCClient_NetworkRPCURLs(t *testing.T) {
+	client := NewRPCClient(NewClientParams{
+		LocalRPCURL:    "http://localhost:8899",
+		NetworkRPCURL:  "https://api.mainnet-beta.solana.com",
+		NetworkRPCURLs: []string{"https://rpc.example.com", "https://api.mainnet-beta.solana.com"},
+	}).(*Client)
+
+	require.Len(t, client.networkRPCPool.endpoints, 2)
+	assert.Equal(t, "https://rpc.example.com", client.NetworkRPCURL())
+	assert.Equal(t, "https://api.mainnet-beta.solana.com", client.networkRPCPool.endpoints[1].url)
+}
+
+func TestNewRPCClient_NetworkRPCHeaders(t *testing.T) {
+	headers := make(chan http.Header, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":1000}`))
+	}))
+	defer server.Close()
+
+	client := NewRPCClient(NewClientParams{
+		LocalRPCURL:       "http://localhost:8899",
+		NetworkRPCURL:     server.URL + "/public",
+		NetworkRPCURLs:    []string{server.URL},
+		NetworkRPCHeaders: map[string]map[string]string{server.URL: {"X-Api-Key": "secret", "Authorization": "Bearer token"}},
+	})
+
+	slot, err := client.GetCurrentSlot()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1000), slot)
+
+	header := <-headers
+	assert.Equal(t, "secret", header.Get("X-Api-Key"))
+	assert.Equal(t, "Bearer token", header.Get("Authorization"))
+}
@@ -1,12 +1,16 @@
 package solana
 
 import (
+	"errors"
 	"strings"
 
 	"github.com/gagliardetto/solana-go/rpc"
-	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/deprecation"
 )
 
+// ErrNodeNotFound is returned when gossip has no node with the requested ip or pubkey - rpc errors are returned as is
+var ErrNodeNotFound = errors.New("gossip node not found")
+
 // Node represents a gossip node
 type Node struct {
 	gossipNode *rpc.GetClusterNodesResult
@@ -19,7 +23,7 @@ func (n *Node) IP() string {
 
 // Pubkey returns the pubkey of the gossip node - prefer its PascalCase counterpart PubKey
 func (n *Node) Pubkey() string {
-	log.Warn().Msg("Pubkey is deprecated (but still works) in favour of PubKey - using it for you...")
+	deprecation.Use("Node.Pubkey", "Pubkey is deprecated (but still works) in favour of PubKey - using it for you...")
 	return n.PubKey()
 }
 
@@ -34,6 +38,14 @@ func (n *Node) Version() string {
 	return *n.gossipNode.Version
 }
 
+// RPCAddress returns the rpc host:port the gossip node advertises - empty when it doesn't expose rpc
+func (n *Node) RPCAddress() string {
+	if n.gossipNode.RPC == nil {
+		return ""
+	}
+	return *n.gossipNode.RPC
+}
+
 // Refresh refreshes the gossip node using the provided gossip client
 func (n *Node) Refresh(gossipClient ClientInterface) error {
 	refreshedNode, err := gossipClient.NodeFromIP(n.IP())
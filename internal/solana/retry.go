@@ -0,0 +1,179 @@
+package solana
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DefaultRetryMaxAttempts is the default number of times a network rpc call is made before its error is returned
+	DefaultRetryMaxAttempts = 4
+	// DefaultRetryBaseDelay is the default delay before the first retry - doubled for each one after
+	DefaultRetryBaseDelay = 500 * time.Millisecond
+	// DefaultRetryMaxDelay is the default longest delay between retries
+	DefaultRetryMaxDelay = 5 * time.Second
+	// DefaultRetryJitter is the default fraction each delay is randomly shortened or lengthened by
+	DefaultRetryJitter = 0.2
+)
+
+// RetryConfig is how failed rpc calls are retried - with exponential backoff between attempts
+type RetryConfig struct {
+	// MaxAttempts is how many times a call is made before its error is returned - 0 or 1 never retries
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry - doubled for each one after
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between retries - 0 doesn't
+	MaxDelay time.Duration
+	// Jitter is the fraction, between 0 and 1, each delay is randomly shortened or lengthened by
+	Jitter float64
+}
+
+// delay returns the delay before the given retry - 1 for the first
+func (c RetryConfig) delay(retry int) time.Duration {
+	delay := c.BaseDelay
+	for i := 1; i < retry && (c.MaxDelay <= 0 || delay < c.MaxDelay); i++ {
+		delay *= 2
+	}
+	if c.MaxDelay > 0 && delay > c.MaxDelay {
+		delay = c.MaxDelay
+	}
+	if c.Jitter > 0 {
+		delay = time.Duration(float64(delay) * (1 + c.Jitter*(2*rand.Float64()-1)))
+	}
+	return delay
+}
+
+// retryRPCClient is an RPCClientInterface retrying calls that fail for reasons another attempt may not - errors
+// answered by a healthy node are returned as they are
+type retryRPCClient struct {
+	client RPCClientInterface
+	config RetryConfig
+}
+
+// newRetryRPCClient wraps client so failed calls are retried as config says
+func newRetryRPCClient(client RPCClientInterface, config RetryConfig) *retryRPCClient {
+	return &retryRPCClient{client: client, config: config}
+}
+
+// callWithRetry makes a call until it succeeds, fails with an error retrying won't fix or runs out of attempts
+func callWithRetry[T any](r *retryRPCClient, ctx context.Context, method string, call func(ctx context.Context, client RPCClientInterface) (T, error)) (result T, err error) {
+	for attempt := 1; ; attempt++ {
+		result, err = call(ctx, r.client)
+		if err == nil || !isEndpointError(err) || attempt >= r.config.MaxAttempts || ctx.Err() != nil {
+			return result, err
+		}
+
+		delay := r.config.delay(attempt)
+		log.Warn().
+			Err(err).
+			Str("method", method).
+			Int("attempt", attempt).
+			Int("max_attempts", r.config.MaxAttempts).
+			Dur("retry_in", delay).
+			Msg("rpc call failed - retrying")
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result, err
+		case <-timer.C:
+		}
+	}
+}
+
+// GetClusterNodes implements RPCClientInterface
+func (r *retryRPCClient) GetClusterNodes(ctx context.Context) ([]*rpc.GetClusterNodesResult, error) {
+	return callWithRetry(r, ctx, "getClusterNodes", func(ctx context.Context, client RPCClientInterface) ([]*rpc.GetClusterNodesResult, error) {
+		return client.GetClusterNodes(ctx)
+	})
+}
+
+// GetVoteAccounts implements RPCClientInterface
+func (r *retryRPCClient) GetVoteAccounts(ctx context.Context, opts *rpc.GetVoteAccountsOpts) (*rpc.GetVoteAccountsResult, error) {
+	return callWithRetry(r, ctx, "getVoteAccounts", func(ctx context.Context, client RPCClientInterface) (*rpc.GetVoteAccountsResult, error) {
+		return client.GetVoteAccounts(ctx, opts)
+	})
+}
+
+// GetSlot implements RPCClientInterface
+func (r *retryRPCClient) GetSlot(ctx context.Context, commitment rpc.CommitmentType) (uint64, error) {
+	return callWithRetry(r, ctx, "getSlot", func(ctx context.Context, client RPCClientInterface) (uint64, error) {
+		return client.GetSlot(ctx, commitment)
+	})
+}
+
+// GetLeaderSchedule implements RPCClientInterface
+func (r *retryRPCClient) GetLeaderSchedule(ctx context.Context) (rpc.GetLeaderScheduleResult, error) {
+	return callWithRetry(r, ctx, "getLeaderSchedule", func(ctx context.Context, client RPCClientInterface) (rpc.GetLeaderScheduleResult, error) {
+		return client.GetLeaderSchedule(ctx)
+	})
+}
+
+// GetBlockTime implements RPCClientInterface
+func (r *retryRPCClient) GetBlockTime(ctx context.Context, slot uint64) (*solanago.UnixTimeSeconds, error) {
+	return callWithRetry(r, ctx, "getBlockTime", func(ctx context.Context, client RPCClientInterface) (*solanago.UnixTimeSeconds, error) {
+		return client.GetBlockTime(ctx, slot)
+	})
+}
+
+// GetHealth implements RPCClientInterface
+func (r *retryRPCClient) GetHealth(ctx context.Context) (string, error) {
+	return callWithRetry(r, ctx, "getHealth", func(ctx context.Context, client RPCClientInterface) (string, error) {
+		return client.GetHealth(ctx)
+	})
+}
+
+// GetEpochInfo implements RPCClientInterface
+func (r *retryRPCClient) GetEpochInfo(ctx context.Context, commitment rpc.CommitmentType) (*rpc.GetEpochInfoResult, error) {
+	return callWithRetry(r, ctx, "getEpochInfo", func(ctx context.Context, client RPCClientInterface) (*rpc.GetEpochInfoResult, error) {
+		return client.GetEpochInfo(ctx, commitment)
+	})
+}
+
+// GetBalance implements RPCClientInterface
+func (r *retryRPCClient) GetBalance(ctx context.Context, account solanago.PublicKey, commitment rpc.CommitmentType) (*rpc.GetBalanceResult, error) {
+	return callWithRetry(r, ctx, "getBalance", func(ctx context.Context, client RPCClientInterface) (*rpc.GetBalanceResult, error) {
+		return client.GetBalance(ctx, account, commitment)
+	})
+}
+
+// GetBlockProductionWithOpts implements RPCClientInterface
+func (r *retryRPCClient) GetBlockProductionWithOpts(ctx context.Context, opts *rpc.GetBlockProductionOpts) (*rpc.GetBlockProductionResult, error) {
+	return callWithRetry(r, ctx, "getBlockProduction", func(ctx context.Context, client RPCClientInterface) (*rpc.GetBlockProductionResult, error) {
+		return client.GetBlockProductionWithOpts(ctx, opts)
+	})
+}
+
+// GetTransactionCount implements RPCClientInterface
+func (r *retryRPCClient) GetTransactionCount(ctx context.Context, commitment rpc.CommitmentType) (uint64, error) {
+	return callWithRetry(r, ctx, "getTransactionCount", func(ctx context.Context, client RPCClientInterface) (uint64, error) {
+		return client.GetTransactionCount(ctx, commitment)
+	})
+}
+
+// GetMultipleAccountsWithOpts implements RPCClientInterface
+func (r *retryRPCClient) GetMultipleAccountsWithOpts(ctx context.Context, accounts []solanago.PublicKey, opts *rpc.GetMultipleAccountsOpts) (*rpc.GetMultipleAccountsResult, error) {
+	return callWithRetry(r, ctx, "getMultipleAccounts", func(ctx context.Context, client RPCClientInterface) (*rpc.GetMultipleAccountsResult, error) {
+		return client.GetMultipleAccountsWithOpts(ctx, accounts, opts)
+	})
+}
+
+// GetInflationRate implements RPCClientInterface
+func (r *retryRPCClient) GetInflationRate(ctx context.Context) (*rpc.GetInflationRateResult, error) {
+	return callWithRetry(r, ctx, "getInflationRate", func(ctx context.Context, client RPCClientInterface) (*rpc.GetInflationRateResult, error) {
+		return client.GetInflationRate(ctx)
+	})
+}
+
+// GetSupply implements RPCClientInterface
+func (r *retryRPCClient) GetSupply(ctx context.Context, commitment rpc.CommitmentType) (*rpc.GetSupplyResult, error) {
+	return callWithRetry(r, ctx, "getSupply", func(ctx context.Context, client RPCClientInterface) (*rpc.GetSupplyResult, error) {
+		return client.GetSupply(ctx, commitment)
+	})
+}
@@ -0,0 +1,101 @@
+package solana
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryConfig_Delay(t *testing.T) {
+	config := RetryConfig{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	assert.Equal(t, 100*time.Millisecond, config.delay(1))
+	assert.Equal(t, 200*time.Millisecond, config.delay(2))
+	assert.Equal(t, 400*time.Millisecond, config.delay(3))
+	assert.Equal(t, 800*time.Millisecond, config.delay(4))
+	assert.Equal(t, time.Second, config.delay(5))
+	assert.Equal(t, time.Second, config.delay(100))
+
+	config.MaxDelay = 0
+	assert.Equal(t, 1600*time.Millisecond, config.delay(5))
+}
+
+func TestRetryConfig_DelayJitter(t *testing.T) {
+	config := RetryConfig{BaseDelay: time.Second, Jitter: 0.2}
+	for i := 0; i < 100; i++ {
+		delay := config.delay(1)
+		assert.GreaterOrEqual(t, delay, 800*time.Millisecond)
+		assert.LessOrEqual(t, delay, 1200*time.Millisecond)
+	}
+}
+
+func TestRetryRPCClient_RetriesUntilSuccess(t *testing.T) {
+	mockClient := &MockRPCClient{}
+	mockClient.On("GetSlot", mock.Anything, rpc.CommitmentConfirmed).Return(uint64(0), errors.New("connection reset")).Twice()
+	mockClient.On("GetSlot", mock.Anything, rpc.CommitmentConfirmed).Return(uint64(1000), nil)
+
+	client := newRetryRPCClient(mockClient, RetryConfig{MaxAttempts: 4, BaseDelay: time.Millisecond})
+	slot, err := client.GetSlot(context.Background(), rpc.CommitmentConfirmed)
+
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1000), slot)
+	mockClient.AssertNumberOfCalls(t, "GetSlot", 3)
+}
+
+func TestRetryRPCClient_GivesUp(t *testing.T) {
+	mockClient := &MockRPCClient{}
+	mockClient.On("GetClusterNodes", mock.Anything).Return([]*rpc.GetClusterNodesResult(nil), errors.New("connection reset"))
+
+	client := newRetryRPCClient(mockClient, RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond})
+	_, err := client.GetClusterNodes(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "connection reset")
+	mockClient.AssertNumberOfCalls(t, "GetClusterNodes", 3)
+}
+
+func TestRetryRPCClient_ZeroValueNeverRetries(t *testing.T) {
+	mockClient := &MockRPCClient{}
+	mockClient.On("GetSlot", mock.Anything, rpc.CommitmentConfirmed).Return(uint64(0), errors.New("connection reset"))
+
+	client := newRetryRPCClient(mockClient, RetryConfig{})
+	_, err := client.GetSlot(context.Background(), rpc.CommitmentConfirmed)
+
+	require.Error(t, err)
+	mockClient.AssertNumberOfCalls(t, "GetSlot", 1)
+}
+
+func TestRetryRPCClient_RequestErrorsNotRetried(t *testing.T) {
+	mockClient := &MockRPCClient{}
+	mockClient.On("GetBlockTime", mock.Anything, uint64(5)).Return((*solanago.UnixTimeSeconds)(nil), &jsonrpc.RPCError{Code: -32009, Message: "slot skipped"})
+
+	client := newRetryRPCClient(mockClient, RetryConfig{MaxAttempts: 4, BaseDelay: time.Millisecond})
+	_, err := client.GetBlockTime(context.Background(), 5)
+
+	require.Error(t, err)
+	mockClient.AssertNumberOfCalls(t, "GetBlockTime", 1)
+}
+
+func TestRetryRPCClient_StopsWhenContextDone(t *testing.T) {
+	mockClient := &MockRPCClient{}
+	mockClient.On("GetSlot", mock.Anything, rpc.CommitmentConfirmed).Return(uint64(0), errors.New("connection reset"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	client := newRetryRPCClient(mockClient, RetryConfig{MaxAttempts: 10, BaseDelay: time.Minute})
+	start := time.Now()
+	_, err := client.GetSlot(ctx, rpc.CommitmentConfirmed)
+
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+	mockClient.AssertNumberOfCalls(t, "GetSlot", 1)
+}
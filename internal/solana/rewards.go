@@ -0,0 +1,130 @@
+package solana
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// MaxVoteCreditsPerSlot is the most credits a timely vote earns - an estimate of what each slot not voted in costs
+const MaxVoteCreditsPerSlot = 16
+
+// yearDuration is the length of the year inflation rates are quoted over
+const yearDuration = time.Duration(365.25 * 24 * float64(time.Hour))
+
+// RewardsContext is what is needed to estimate what missed vote credits cost a vote account in epoch rewards
+type RewardsContext struct {
+	// Epoch is the epoch the estimate is for
+	Epoch uint64
+	// PointValue is the projected lamports paid per point (a lamport of stake earning a vote credit) this epoch
+	PointValue float64
+	// ActivatedStake is the vote account's active stake in lamports
+	ActivatedStake uint64
+	// Commission is the vote account's commission percentage
+	Commission uint8
+	// SlotTime is the average slot time used to convert durations to slots
+	SlotTime time.Duration
+}
+
+// CreditValue returns the projected lamports the vote account earns per vote credit this epoch
+func (r RewardsContext) CreditValue() float64 {
+	return r.PointValue * float64(r.ActivatedStake)
+}
+
+// MissedVoteCredits estimates the vote credits missed by not voting for duration, assuming each slot would have
+// earned the most credits a timely vote earns
+func (r RewardsContext) MissedVoteCredits(duration time.Duration) uint64 {
+	if duration <= 0 || r.SlotTime <= 0 {
+		return 0
+	}
+	slots := uint64(math.Ceil(float64(duration) / float64(r.SlotTime)))
+	return slots * MaxVoteCreditsPerSlot
+}
+
+// MissedRewards estimates the epoch rewards in lamports lost by not voting for duration, split into the validator's
+// commission and what its delegators lose
+func (r RewardsContext) MissedRewards(duration time.Duration) (credits, commissionLamports, delegatorLamports uint64) {
+	credits = r.MissedVoteCredits(duration)
+	total := r.CreditValue() * float64(credits)
+	commission := total * float64(r.Commission) / 100
+	return credits, uint64(math.Round(commission)), uint64(math.Round(total - commission))
+}
+
+// LamportsToSOL converts lamports to SOL for display
+func LamportsToSOL(lamports uint64) float64 {
+	return float64(lamports) / float64(solanago.LAMPORTS_PER_SOL)
+}
+
+// GetRewardsContext returns the rewards context of the vote account whose node is the given identity pubkey - the
+// point value projects the epoch's inflation rewards over every vote account's credits so far scaled to a full epoch
+func (c *Client) GetRewardsContext(pubkey string) (*RewardsContext, error) {
+	ctx := context.Background()
+
+	inflation, err := c.networkRPCClient.GetInflationRate(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inflation rate: %w", err)
+	}
+	supply, err := c.networkRPCClient.GetSupply(ctx, rpc.CommitmentConfirmed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get supply: %w", err)
+	}
+	if supply.Value == nil {
+		return nil, fmt.Errorf("failed to get supply: empty response")
+	}
+	epochInfo, err := c.networkRPCClient.GetEpochInfo(ctx, rpc.CommitmentConfirmed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get epoch info: %w", err)
+	}
+	voteAccounts, err := c.networkRPCClient.GetVoteAccounts(ctx, &rpc.GetVoteAccountsOpts{Commitment: rpc.CommitmentConfirmed})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vote accounts: %w", err)
+	}
+	slotTime, err := c.getAverageSlotTime()
+	if err != nil {
+		return nil, err
+	}
+
+	rewardsContext := &RewardsContext{
+		Epoch:    epochInfo.Epoch,
+		SlotTime: slotTime,
+	}
+
+	found := false
+	var points float64
+	for _, account := range append(voteAccounts.Current, voteAccounts.Delinquent...) {
+		points += float64(account.ActivatedStake) * float64(epochCredits(account, epochInfo.Epoch))
+		if account.NodePubkey.String() == pubkey {
+			found = true
+			rewardsContext.ActivatedStake = account.ActivatedStake
+			rewardsContext.Commission = account.Commission
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("no vote account found for pubkey %s", pubkey)
+	}
+	if points == 0 || epochInfo.SlotIndex == 0 {
+		return nil, fmt.Errorf("no vote credits earned yet in epoch %d to project rewards from", epochInfo.Epoch)
+	}
+
+	// scale the points earned so far to the full epoch and share the epoch's inflation rewards over them
+	projectedPoints := points * float64(epochInfo.SlotsInEpoch) / float64(epochInfo.SlotIndex)
+	epochYears := float64(time.Duration(epochInfo.SlotsInEpoch)*slotTime) / float64(yearDuration)
+	epochRewards := inflation.Validator * float64(supply.Value.Total) * epochYears
+	rewardsContext.PointValue = epochRewards / projectedPoints
+
+	return rewardsContext, nil
+}
+
+// epochCredits returns the credits a vote account earned in epoch - entries are [epoch, credits, previous credits]
+func epochCredits(account rpc.VoteAccountsResult, epoch uint64) int64 {
+	for _, entry := range account.EpochCredits {
+		if len(entry) == 3 && uint64(entry[0]) == epoch {
+			return entry[1] - entry[2]
+		}
+	}
+	return 0
+}
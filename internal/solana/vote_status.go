@@ -0,0 +1,81 @@
+package solana
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// VoteAccountStatus is the state of an identity's vote account as seen by the network rpc
+type VoteAccountStatus struct {
+	// Found is false when no vote account, current or delinquent, has the identity as its node
+	Found bool
+	// VotePubkey is the vote account's address
+	VotePubkey string
+	// Delinquent is true when the cluster considers the vote account delinquent
+	Delinquent bool
+	// EpochCredits are the credits earned so far in the most recent epoch the vote account voted in
+	EpochCredits int64
+	// LastVote is the most recent slot the vote account voted on
+	LastVote uint64
+	// ActivatedStake is the stake in lamports delegated to the vote account and active this epoch
+	ActivatedStake uint64
+	// Commission is the percentage of rewards the vote account keeps
+	Commission uint8
+	// StakeWeight is the percentage of the cluster's activated stake that is the vote account's
+	StakeWeight float64
+}
+
+// GetVoteAccountStatus returns the status of the vote account whose node is the given identity pubkey
+func (c *Client) GetVoteAccountStatus(pubkey string) (VoteAccountStatus, error) {
+	voteAccounts, err := c.networkRPCClient.GetVoteAccounts(
+		context.Background(),
+		&rpc.GetVoteAccountsOpts{
+			Commitment: rpc.CommitmentConfirmed,
+		},
+	)
+	if err != nil {
+		return VoteAccountStatus{}, fmt.Errorf("failed to get vote accounts for pubkey %s: %w", pubkey, err)
+	}
+
+	var status VoteAccountStatus
+	var totalStake uint64
+	for _, account := range voteAccounts.Current {
+		totalStake += account.ActivatedStake
+		if account.NodePubkey.String() == pubkey {
+			status = newVoteAccountStatus(account, false)
+		}
+	}
+	for _, account := range voteAccounts.Delinquent {
+		totalStake += account.ActivatedStake
+		if account.NodePubkey.String() == pubkey && !status.Found {
+			status = newVoteAccountStatus(account, true)
+		}
+	}
+	if status.Found && totalStake > 0 {
+		status.StakeWeight = 100 * float64(status.ActivatedStake) / float64(totalStake)
+	}
+
+	return status, nil
+}
+
+// newVoteAccountStatus creates a vote account status from a vote account - epoch credits entries are
+// [epoch, credits, previous credits]
+func newVoteAccountStatus(account rpc.VoteAccountsResult, delinquent bool) VoteAccountStatus {
+	status := VoteAccountStatus{
+		Found:          true,
+		VotePubkey:     account.VotePubkey.String(),
+		Delinquent:     delinquent,
+		LastVote:       account.LastVote,
+		ActivatedStake: account.ActivatedStake,
+		Commission:     account.Commission,
+	}
+	if len(account.EpochCredits) > 0 {
+		latest := account.EpochCredits[len(account.EpochCredits)-1]
+		if len(latest) == 3 {
+			status.EpochCredits = latest[1] - latest[2]
+		}
+	}
+	return status
+}
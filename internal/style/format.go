@@ -0,0 +1,80 @@
+package style
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HumanizeTableValues is whether tables show durations like 1m 23.5s and numbers with thousands separators - when
+// false they show go's defaults like 1m23.456789s and 1234567. Logged fields and json are always raw
+var HumanizeTableValues = true
+
+// FormatDuration formats a duration for a table
+func FormatDuration(d time.Duration) string {
+	if !HumanizeTableValues {
+		return d.String()
+	}
+	if d < 0 {
+		return "-" + FormatDuration(-d)
+	}
+
+	// round to the precision shown before picking the unit so 999.96ms shows as 1s rather than 1000ms
+	if d < time.Millisecond {
+		return d.String()
+	}
+	if rounded := d.Round(100 * time.Microsecond); rounded < time.Second {
+		return trimDecimal(float64(rounded)/float64(time.Millisecond)) + "ms"
+	}
+	d = d.Round(100 * time.Millisecond)
+
+	switch {
+	case d < time.Minute:
+		return trimDecimal(d.Seconds()) + "s"
+	case d < time.Hour:
+		minutes := d / time.Minute
+		return fmt.Sprintf("%dm %ss", minutes, trimDecimal((d - minutes*time.Minute).Seconds()))
+	default:
+		d = d.Round(time.Second)
+		hours := d / time.Hour
+		minutes := (d - hours*time.Hour) / time.Minute
+		seconds := (d - hours*time.Hour - minutes*time.Minute) / time.Second
+		return fmt.Sprintf("%dh %dm %ds", hours, minutes, seconds)
+	}
+}
+
+// FormatInt formats a number for a table
+func FormatInt[T ~int | ~int64 | ~uint64](n T) string {
+	digits := fmt.Sprintf("%d", n)
+	if !HumanizeTableValues {
+		return digits
+	}
+	// separated here rather than converting to int64 for humanize, which would turn large uint64s negative
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	return b.String()
+}
+
+// FormatSignedInt formats a change in a number for a table - always signed
+func FormatSignedInt[T ~int | ~int64](n T) string {
+	if n >= 0 {
+		return "+" + FormatInt(n)
+	}
+	return FormatInt(n)
+}
+
+// trimDecimal formats f with one decimal place, dropping it when it is zero
+func trimDecimal(f float64) string {
+	return strings.TrimSuffix(strconv.FormatFloat(f, 'f', 1, 64), ".0")
+}
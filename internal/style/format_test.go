@@ -0,0 +1,101 @@
+package style
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rawTableValues has tables show go's defaults for the rest of the test
+func rawTableValues(t *testing.T) {
+	HumanizeTableValues = false
+	t.Cleanup(func() { HumanizeTableValues = true })
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0s"},
+		{999 * time.Microsecond, "999µs"},
+		{time.Millisecond, "1ms"},
+		{1500 * time.Microsecond, "1.5ms"},
+		{999940 * time.Microsecond, "999.9ms"},
+		{999960 * time.Microsecond, "1s"},
+		{time.Second, "1s"},
+		{1240 * time.Millisecond, "1.2s"},
+		{59940 * time.Millisecond, "59.9s"},
+		{59960 * time.Millisecond, "1m 0s"},
+		{time.Minute, "1m 0s"},
+		{83500 * time.Millisecond, "1m 23.5s"},
+		{time.Minute + 59960*time.Millisecond, "2m 0s"},
+		{59*time.Minute + 59960*time.Millisecond, "1h 0m 0s"},
+		{time.Hour + 2*time.Minute + 3400*time.Millisecond, "1h 2m 3s"},
+		{time.Hour + 59*time.Minute + 59500*time.Millisecond, "2h 0m 0s"},
+		{-1500 * time.Millisecond, "-1.5s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, FormatDuration(tt.d))
+		})
+	}
+}
+
+func TestFormatDuration_Raw(t *testing.T) {
+	rawTableValues(t)
+	assert.Equal(t, "1m23.456789s", FormatDuration(83456789*time.Microsecond))
+	assert.Equal(t, "999.96ms", FormatDuration(999960*time.Microsecond))
+}
+
+func TestFormatInt(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"zero", FormatInt(0), "0"},
+		{"below a thousand", FormatInt(999), "999"},
+		{"a thousand", FormatInt(1000), "1,000"},
+		{"millions", FormatInt(int64(1234567)), "1,234,567"},
+		{"negative", FormatInt(-1234567), "-1,234,567"},
+		{"negative below a thousand", FormatInt(-999), "-999"},
+		{"min int64", FormatInt(int64(math.MinInt64)), "-9,223,372,036,854,775,808"},
+		{"uint64", FormatInt(uint64(350000000)), "350,000,000"},
+		{"max uint64", FormatInt(uint64(math.MaxUint64)), "18,446,744,073,709,551,615"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.got)
+		})
+	}
+}
+
+func TestFormatInt_Raw(t *testing.T) {
+	rawTableValues(t)
+	assert.Equal(t, "1234567", FormatInt(1234567))
+	assert.Equal(t, "18446744073709551615", FormatInt(uint64(math.MaxUint64)))
+}
+
+func TestFormatSignedInt(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "+0"},
+		{5, "+5"},
+		{1234, "+1,234"},
+		{-1234, "-1,234"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, FormatSignedInt(tt.n))
+		})
+	}
+
+	rawTableValues(t)
+	assert.Equal(t, "+1234", FormatSignedInt(1234))
+	assert.Equal(t, "-1234", FormatSignedInt(-1234))
+}
@@ -0,0 +1,106 @@
+package systemd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// notify states sent to systemd
+const (
+	// StateReady tells systemd a Type=notify service finished starting up
+	StateReady = "READY=1"
+	// StateWatchdog tells systemd the service is alive so WatchdogSec doesn't restart it
+	StateWatchdog = "WATCHDOG=1"
+	// StateStopping tells systemd the service is shutting down
+	StateStopping = "STOPPING=1"
+)
+
+// Notify sends state to the socket systemd passes in NOTIFY_SOCKET - it returns false without an error when this
+// process wasn't started by systemd with one, so callers needn't check
+func Notify(state string) (sent bool, err error) {
+	socketPath := os.Getenv("NOTIFY_SOCKET")
+	if socketPath == "" {
+		return false, nil
+	}
+	// a leading @ is a socket in the abstract namespace
+	if socketPath[0] == '@' {
+		socketPath = "\x00" + socketPath[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to systemd notify socket: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, fmt.Errorf("failed to send %s to systemd: %w", state, err)
+	}
+	return true, nil
+}
+
+// WatchdogInterval returns how often systemd expects to hear this process is alive - false when WatchdogSec isn't
+// set for its unit or the watchdog is meant for another process
+func WatchdogInterval() (time.Duration, bool) {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0, false
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0, false
+	}
+	return time.Duration(usec) * time.Microsecond, true
+}
+
+// Ready tells systemd the daemon started - stop tells it the daemon is stopping. It does nothing when this process
+// isn't run by a Type=notify unit, and failing to notify is only logged. The daemon's loop feeds the watchdog itself
+// with Feed and Sleep so a hung loop isn't kept alive
+func Ready(daemon string) (stop func()) {
+	sent, err := Notify(StateReady)
+	if err != nil {
+		log.Warn().Err(err).Str("daemon", daemon).Msg("failed to tell systemd the daemon is ready")
+	} else if sent {
+		log.Debug().Str("daemon", daemon).Msg("told systemd the daemon is ready")
+	}
+	Feed(daemon)
+
+	return func() {
+		if _, err := Notify(StateStopping); err != nil {
+			log.Debug().Err(err).Str("daemon", daemon).Msg("failed to tell systemd the daemon is stopping")
+		}
+	}
+}
+
+// Feed tells systemd the daemon is alive when its unit sets WatchdogSec - called by the daemon's loop each time round
+// so systemd restarts a daemon whose loop hangs
+func Feed(daemon string) {
+	if _, ok := WatchdogInterval(); !ok {
+		return
+	}
+	if _, err := Notify(StateWatchdog); err != nil {
+		log.Warn().Err(err).Str("daemon", daemon).Msg("failed to feed systemd watchdog")
+	}
+}
+
+// Sleep sleeps for d, feeding the watchdog at half the interval systemd expects meanwhile, so a daemon loop waiting
+// for its next iteration isn't taken for hung
+func Sleep(daemon string, d time.Duration) {
+	interval, ok := WatchdogInterval()
+	if !ok {
+		time.Sleep(d)
+		return
+	}
+	for wakeAt := time.Now().Add(d); ; {
+		remaining := time.Until(wakeAt)
+		if remaining <= 0 {
+			return
+		}
+		time.Sleep(min(remaining, interval/2))
+		Feed(daemon)
+	}
+}
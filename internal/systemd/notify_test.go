@@ -0,0 +1,117 @@
+package systemd
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listenNotifySocket listens on a notify socket as systemd would and points NOTIFY_SOCKET at it
+func listenNotifySocket(t *testing.T) *net.UnixConn {
+	t.Helper()
+	socketPath := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	t.Setenv("NOTIFY_SOCKET", socketPath)
+	return conn
+}
+
+// readState reads the next state sent to the notify socket
+func readState(t *testing.T, conn *net.UnixConn) string {
+	t.Helper()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	buf := make([]byte, 256)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	return string(buf[:n])
+}
+
+func TestNotify_NoSocket(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+
+	sent, err := Notify(StateReady)
+	assert.NoError(t, err)
+	assert.False(t, sent)
+}
+
+func TestNotify_SendsState(t *testing.T) {
+	conn := listenNotifySocket(t)
+
+	sent, err := Notify(StateReady)
+	require.NoError(t, err)
+	assert.True(t, sent)
+	assert.Equal(t, StateReady, readState(t, conn))
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	t.Setenv("WATCHDOG_USEC", "")
+	_, ok := WatchdogInterval()
+	assert.False(t, ok)
+
+	t.Setenv("WATCHDOG_USEC", "30000000")
+	t.Setenv("WATCHDOG_PID", "")
+	interval, ok := WatchdogInterval()
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, interval)
+
+	// meant for another process
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()+1))
+	_, ok = WatchdogInterval()
+	assert.False(t, ok)
+}
+
+// enableWatchdog sets the watchdog systemd would for this process
+func enableWatchdog(t *testing.T, interval time.Duration) {
+	t.Helper()
+	t.Setenv("WATCHDOG_USEC", strconv.FormatInt(interval.Microseconds(), 10))
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+}
+
+func TestReady(t *testing.T) {
+	conn := listenNotifySocket(t)
+	enableWatchdog(t, time.Minute)
+
+	stop := Ready("test")
+	assert.Equal(t, StateReady, readState(t, conn))
+	assert.Equal(t, StateWatchdog, readState(t, conn))
+
+	// nothing feeds the watchdog but the daemon's loop
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(50*time.Millisecond)))
+	_, err := conn.Read(make([]byte, 256))
+	assert.Error(t, err)
+
+	stop()
+	assert.Equal(t, StateStopping, readState(t, conn))
+}
+
+func TestFeed(t *testing.T) {
+	conn := listenNotifySocket(t)
+
+	// without a watchdog there is nothing to feed
+	t.Setenv("WATCHDOG_USEC", "")
+	Feed("test")
+	enableWatchdog(t, time.Minute)
+	Feed("test")
+	assert.Equal(t, StateWatchdog, readState(t, conn))
+}
+
+func TestSleep_FeedsWatchdog(t *testing.T) {
+	conn := listenNotifySocket(t)
+	enableWatchdog(t, 20*time.Millisecond)
+
+	start := time.Now()
+	Sleep("test", 55*time.Millisecond)
+	assert.GreaterOrEqual(t, time.Since(start), 55*time.Millisecond)
+
+	// fed every 10ms while asleep
+	for range 5 {
+		assert.Equal(t, StateWatchdog, readState(t, conn))
+	}
+}
@@ -0,0 +1,241 @@
+package tower
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+const (
+	// MaxLockoutHistory is the most votes a tower holds
+	MaxLockoutHistory = 31
+
+	// DefaultMaxSlotsAhead is how far a tower's last voted slot may be past the current slot - rpc nodes can lag the
+	// slot a validator has voted on
+	DefaultMaxSlotsAhead uint64 = 150
+
+	// maxDataSize caps the signed tower data read from a tower file
+	maxDataSize = 1 << 20
+
+	// savedTowerVersions variants - how a tower file's envelope is tagged
+	variantSavedTower1_7_14 = 0
+	variantSavedTower       = 1
+
+	// lockoutSize is a vote's slot and confirmation count, landedVoteSize a vote with the latency newer versions
+	// record before it
+	lockoutSize    = 12
+	landedVoteSize = 13
+)
+
+// Lockout is a vote in a tower
+type Lockout struct {
+	Slot              uint64
+	ConfirmationCount uint32
+}
+
+// Tower is what a tower file records - only the fields needed to sanity check it are read
+type Tower struct {
+	// NodePubkey is the identity the tower was saved by, verified against the tower file's signature
+	NodePubkey solana.PublicKey
+	// Votes are oldest first
+	Votes    []Lockout
+	RootSlot *uint64
+}
+
+// LastVotedSlot returns the tower's newest vote, or its root when it has no votes - false when it has neither
+func (t *Tower) LastVotedSlot() (slot uint64, ok bool) {
+	if len(t.Votes) > 0 {
+		return t.Votes[len(t.Votes)-1].Slot, true
+	}
+	if t.RootSlot != nil {
+		return *t.RootSlot, true
+	}
+	return 0, false
+}
+
+// CheckSlots returns an error when the tower's last voted slot isn't plausible at currentSlot - more than
+// maxSlotsAhead past it, or more than maxSlotsBehind before it when maxSlotsBehind is set
+func (t *Tower) CheckSlots(currentSlot, maxSlotsAhead, maxSlotsBehind uint64) error {
+	lastVotedSlot, ok := t.LastVotedSlot()
+	if !ok {
+		if maxSlotsBehind > 0 {
+			return errors.New("tower has no votes or root slot")
+		}
+		return nil
+	}
+	if lastVotedSlot > currentSlot+maxSlotsAhead {
+		return fmt.Errorf("tower's last voted slot %d is %d slots past the current slot %d", lastVotedSlot, lastVotedSlot-currentSlot, currentSlot)
+	}
+	if maxSlotsBehind > 0 && lastVotedSlot+maxSlotsBehind < currentSlot {
+		return fmt.Errorf("tower's last voted slot %d is %d slots behind the current slot %d", lastVotedSlot, currentSlot-lastVotedSlot, currentSlot)
+	}
+	return nil
+}
+
+// Parse reads a tower file saved by nodePubkey - its envelope must carry nodePubkey's signature of the tower data, the
+// tower must be nodePubkey's and its votes and root slot must be in order
+func Parse(towerFileBytes []byte, nodePubkey solana.PublicKey) (*Tower, error) {
+	reader := bytes.NewReader(towerFileBytes)
+
+	var variant uint32
+	if err := binary.Read(reader, binary.LittleEndian, &variant); err != nil {
+		return nil, fmt.Errorf("failed to read tower file version: %w", err)
+	}
+	if variant != variantSavedTower1_7_14 && variant != variantSavedTower {
+		return nil, fmt.Errorf("unknown tower file version %d", variant)
+	}
+
+	var signature solana.Signature
+	if _, err := io.ReadFull(reader, signature[:]); err != nil {
+		return nil, fmt.Errorf("failed to read tower signature: %w", err)
+	}
+	var dataSize uint64
+	if err := binary.Read(reader, binary.LittleEndian, &dataSize); err != nil {
+		return nil, fmt.Errorf("failed to read tower data size: %w", err)
+	}
+	if dataSize > maxDataSize || dataSize > uint64(reader.Len()) {
+		return nil, fmt.Errorf("tower data size %d doesn't fit the %d byte tower file", dataSize, len(towerFileBytes))
+	}
+	data := make([]byte, dataSize)
+	if _, err := io.ReadFull(reader, data); err != nil {
+		return nil, fmt.Errorf("failed to read tower data: %w", err)
+	}
+	if !signature.Verify(nodePubkey, data) {
+		return nil, fmt.Errorf("tower signature doesn't verify against %s", nodePubkey)
+	}
+
+	return parseData(data, nodePubkey)
+}
+
+// parseData reads the signed tower data - node pubkey, threshold depth and size, then the vote state whose votes are
+// read as landed votes or lockouts, whichever are in order
+func parseData(data []byte, nodePubkey solana.PublicKey) (*Tower, error) {
+	reader := bytes.NewReader(data)
+	tower := &Tower{}
+	if _, err := io.ReadFull(reader, tower.NodePubkey[:]); err != nil {
+		return nil, fmt.Errorf("failed to read tower node pubkey: %w", err)
+	}
+	if !tower.NodePubkey.Equals(nodePubkey) {
+		return nil, fmt.Errorf("tower is %s's, expected %s's", tower.NodePubkey, nodePubkey)
+	}
+
+	var thresholdDepth uint64
+	var thresholdSize float64
+	if err := binary.Read(reader, binary.LittleEndian, &thresholdDepth); err != nil {
+		return nil, fmt.Errorf("failed to read tower threshold depth: %w", err)
+	}
+	if err := binary.Read(reader, binary.LittleEndian, &thresholdSize); err != nil {
+		return nil, fmt.Errorf("failed to read tower threshold size: %w", err)
+	}
+	if thresholdDepth > MaxLockoutHistory || math.IsNaN(thresholdSize) || thresholdSize < 0 || thresholdSize > 1 {
+		return nil, fmt.Errorf("tower threshold depth %d and size %f are out of range", thresholdDepth, thresholdSize)
+	}
+
+	// vote state node pubkey, authorized withdrawer and commission
+	if _, err := reader.Seek(solana.PublicKeyLength*2+1, io.SeekCurrent); err != nil {
+		return nil, fmt.Errorf("failed to read tower vote state: %w", err)
+	}
+	var voteCount uint64
+	if err := binary.Read(reader, binary.LittleEndian, &voteCount); err != nil {
+		return nil, fmt.Errorf("failed to read tower vote count: %w", err)
+	}
+	if voteCount > MaxLockoutHistory {
+		return nil, fmt.Errorf("tower has %d votes, more than %d", voteCount, MaxLockoutHistory)
+	}
+
+	// the error reported is the current version's
+	votesOffset := len(data) - reader.Len()
+	var firstErr error
+	for _, voteSize := range []int{landedVoteSize, lockoutSize} {
+		votes, rootSlot, err := parseVotes(data[votesOffset:], int(voteCount), voteSize)
+		if err == nil {
+			tower.Votes, tower.RootSlot = votes, rootSlot
+			return tower, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
+}
+
+// parseVotes reads voteCount votes of voteSize bytes then the root slot, checking they are in order
+func parseVotes(data []byte, voteCount, voteSize int) (votes []Lockout, rootSlot *uint64, err error) {
+	if len(data) < voteCount*voteSize+1 {
+		return nil, nil, fmt.Errorf("tower data ends before its %d votes", voteCount)
+	}
+
+	votes = make([]Lockout, voteCount)
+	for i := range votes {
+		vote := data[i*voteSize : (i+1)*voteSize]
+		// a landed vote's latency comes before its lockout
+		vote = vote[voteSize-lockoutSize:]
+		votes[i] = Lockout{
+			Slot:              binary.LittleEndian.Uint64(vote),
+			ConfirmationCount: binary.LittleEndian.Uint32(vote[8:]),
+		}
+		if votes[i].ConfirmationCount == 0 || votes[i].ConfirmationCount > MaxLockoutHistory+1 {
+			return nil, nil, fmt.Errorf("tower vote for slot %d has confirmation count %d", votes[i].Slot, votes[i].ConfirmationCount)
+		}
+		if i > 0 && votes[i].Slot <= votes[i-1].Slot {
+			return nil, nil, fmt.Errorf("tower vote for slot %d follows one for slot %d", votes[i].Slot, votes[i-1].Slot)
+		}
+	}
+
+	data = data[voteCount*voteSize:]
+	switch data[0] {
+	case 0:
+	case 1:
+		if len(data) < 9 {
+			return nil, nil, errors.New("tower data ends before its root slot")
+		}
+		root := binary.LittleEndian.Uint64(data[1:])
+		if len(votes) > 0 && root >= votes[0].Slot {
+			return nil, nil, fmt.Errorf("tower root slot %d isn't before its oldest vote for slot %d", root, votes[0].Slot)
+		}
+		rootSlot = &root
+	default:
+		return nil, nil, fmt.Errorf("tower root slot is tagged %d", data[0])
+	}
+	return votes, rootSlot, nil
+}
+
+// Encode writes a tower file for tower signed by nodeKey in the current version - only the fields Parse reads are
+// written, so it is for tests and the mock peer, not for a validator to load
+func Encode(tower Tower, nodeKey solana.PrivateKey) ([]byte, error) {
+	var data bytes.Buffer
+	nodePubkey := nodeKey.PublicKey()
+	data.Write(nodePubkey[:])
+	_ = binary.Write(&data, binary.LittleEndian, uint64(8))
+	_ = binary.Write(&data, binary.LittleEndian, 2.0/3.0)
+	data.Write(nodePubkey[:])
+	data.Write(make([]byte, solana.PublicKeyLength+1))
+	_ = binary.Write(&data, binary.LittleEndian, uint64(len(tower.Votes)))
+	for _, vote := range tower.Votes {
+		data.WriteByte(0)
+		_ = binary.Write(&data, binary.LittleEndian, vote)
+	}
+	if tower.RootSlot == nil {
+		data.WriteByte(0)
+	} else {
+		data.WriteByte(1)
+		_ = binary.Write(&data, binary.LittleEndian, *tower.RootSlot)
+	}
+
+	signature, err := nodeKey.Sign(data.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign tower: %w", err)
+	}
+
+	var towerFile bytes.Buffer
+	_ = binary.Write(&towerFile, binary.LittleEndian, uint32(variantSavedTower))
+	towerFile.Write(signature[:])
+	_ = binary.Write(&towerFile, binary.LittleEndian, uint64(data.Len()))
+	towerFile.Write(data.Bytes())
+	return towerFile.Bytes(), nil
+}
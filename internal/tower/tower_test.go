@@ -0,0 +1,110 @@
+package tower
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testVotes() []Lockout {
+	return []Lockout{{Slot: 100, ConfirmationCount: 3}, {Slot: 101, ConfirmationCount: 2}, {Slot: 103, ConfirmationCount: 1}}
+}
+
+func TestParse_RoundTrip(t *testing.T) {
+	key, err := solana.NewRandomPrivateKey()
+	require.NoError(t, err)
+	root := uint64(90)
+
+	towerFileBytes, err := Encode(Tower{Votes: testVotes(), RootSlot: &root}, key)
+	require.NoError(t, err)
+
+	tower, err := Parse(towerFileBytes, key.PublicKey())
+	require.NoError(t, err)
+	assert.Equal(t, key.PublicKey(), tower.NodePubkey)
+	assert.Equal(t, testVotes(), tower.Votes)
+	require.NotNil(t, tower.RootSlot)
+	assert.Equal(t, root, *tower.RootSlot)
+
+	lastVotedSlot, ok := tower.LastVotedSlot()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(103), lastVotedSlot)
+}
+
+func TestParse_Lockouts(t *testing.T) {
+	key, err := solana.NewRandomPrivateKey()
+	require.NoError(t, err)
+
+	// older versions record votes without their latency
+	var data bytes.Buffer
+	nodePubkey := key.PublicKey()
+	data.Write(nodePubkey[:])
+	_ = binary.Write(&data, binary.LittleEndian, uint64(8))
+	_ = binary.Write(&data, binary.LittleEndian, 2.0/3.0)
+	data.Write(make([]byte, 2*solana.PublicKeyLength+1))
+	_ = binary.Write(&data, binary.LittleEndian, uint64(len(testVotes())))
+	for _, vote := range testVotes() {
+		_ = binary.Write(&data, binary.LittleEndian, vote)
+	}
+	data.WriteByte(0)
+	signature, err := key.Sign(data.Bytes())
+	require.NoError(t, err)
+
+	var towerFile bytes.Buffer
+	_ = binary.Write(&towerFile, binary.LittleEndian, uint32(variantSavedTower1_7_14))
+	towerFile.Write(signature[:])
+	_ = binary.Write(&towerFile, binary.LittleEndian, uint64(data.Len()))
+	towerFile.Write(data.Bytes())
+
+	tower, err := Parse(towerFile.Bytes(), nodePubkey)
+	require.NoError(t, err)
+	assert.Equal(t, testVotes(), tower.Votes)
+	assert.Nil(t, tower.RootSlot)
+}
+
+func TestParse_Rejects(t *testing.T) {
+	key, err := solana.NewRandomPrivateKey()
+	require.NoError(t, err)
+	other, err := solana.NewRandomPrivateKey()
+	require.NoError(t, err)
+
+	towerFileBytes, err := Encode(Tower{Votes: testVotes()}, key)
+	require.NoError(t, err)
+
+	_, err = Parse(towerFileBytes, other.PublicKey())
+	assert.ErrorContains(t, err, "signature")
+
+	corrupted := bytes.Clone(towerFileBytes)
+	corrupted[len(corrupted)-2] ^= 0xff
+	_, err = Parse(corrupted, key.PublicKey())
+	assert.ErrorContains(t, err, "signature")
+
+	_, err = Parse(towerFileBytes[:50], key.PublicKey())
+	assert.Error(t, err)
+
+	_, err = Parse([]byte("mock tower file"), key.PublicKey())
+	assert.Error(t, err)
+
+	// votes out of order
+	outOfOrder, err := Encode(Tower{Votes: []Lockout{{Slot: 5, ConfirmationCount: 2}, {Slot: 4, ConfirmationCount: 1}}}, key)
+	require.NoError(t, err)
+	_, err = Parse(outOfOrder, key.PublicKey())
+	assert.ErrorContains(t, err, "follows")
+}
+
+func TestCheckSlots(t *testing.T) {
+	tower := &Tower{Votes: testVotes()}
+
+	assert.NoError(t, tower.CheckSlots(103, DefaultMaxSlotsAhead, 0))
+	assert.NoError(t, tower.CheckSlots(50, DefaultMaxSlotsAhead, 0))
+	assert.ErrorContains(t, tower.CheckSlots(102, 0, 0), "past the current slot")
+	assert.NoError(t, tower.CheckSlots(1_000_000, DefaultMaxSlotsAhead, 0))
+	assert.ErrorContains(t, tower.CheckSlots(1_000_000, DefaultMaxSlotsAhead, 1000), "behind the current slot")
+
+	empty := &Tower{}
+	assert.NoError(t, empty.CheckSlots(1_000_000, DefaultMaxSlotsAhead, 0))
+	assert.ErrorContains(t, empty.CheckSlots(1_000_000, DefaultMaxSlotsAhead, 1000), "no votes")
+}
@@ -0,0 +1,232 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	pkgconstants "github.com/sol-strategies/solana-validator-failover/pkg/constants"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	// ServiceName is the service spans are reported under
+	ServiceName = "solana-validator-failover"
+
+	// DefaultTimeout is the default time allowed to export spans
+	DefaultTimeout = 5 * time.Second
+
+	// defaultURLPath is the otlp/http traces path used when the endpoint has none
+	defaultURLPath = "/v1/traces"
+
+	// traceParentKey is the w3c trace context header carried in the protocol message
+	traceParentKey = "traceparent"
+)
+
+// Config is the configuration for exporting failover traces over otlp/http
+type Config struct {
+	// Endpoint is the otlp/http url spans are exported to e.g. http://localhost:4318 - empty disables tracing
+	Endpoint string `mapstructure:"endpoint"`
+	// Headers are sent with every export e.g. an api key for a hosted tracing backend
+	Headers map[string]string `mapstructure:"headers"`
+	Timeout string            `mapstructure:"timeout"`
+}
+
+// noopTracer starts spans for a nil tracer
+var noopTracer = noop.NewTracerProvider().Tracer(ServiceName)
+
+// Interval is a stage timed before the trace it belongs to was known - recorded as a span once it is
+type Interval struct {
+	Start time.Time
+	End   time.Time
+	Err   error
+}
+
+// IsZero returns true if the interval was never timed
+func (i Interval) IsZero() bool {
+	return i.Start.IsZero()
+}
+
+// Tracer starts failover spans and exports them in the background - a tracer with no endpoint starts no-op spans
+type Tracer struct {
+	provider *sdktrace.TracerProvider
+	tracer   trace.Tracer
+	timeout  time.Duration
+	logger   zerolog.Logger
+	shutdown sync.Once
+}
+
+// Validate ensures the config is valid when an endpoint is set
+func (c Config) Validate() error {
+	if c.Endpoint == "" {
+		return nil
+	}
+	if _, err := parseEndpoint(c.Endpoint); err != nil {
+		return err
+	}
+	if c.Timeout != "" {
+		if _, err := time.ParseDuration(c.Timeout); err != nil {
+			return fmt.Errorf("failed to parse timeout %s: %w", c.Timeout, err)
+		}
+	}
+	return nil
+}
+
+// NewTracer creates a new tracer from a config - spans are reported as coming from hostname
+func NewTracer(cfg Config, hostname string) (*Tracer, error) {
+	t := &Tracer{
+		tracer:  noopTracer,
+		timeout: DefaultTimeout,
+		logger:  log.With().Str("component", "tracing").Logger(),
+	}
+
+	if cfg.Endpoint == "" {
+		return t, nil
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.Timeout != "" {
+		t.timeout, _ = time.ParseDuration(cfg.Timeout)
+	}
+
+	endpoint, _ := parseEndpoint(cfg.Endpoint)
+	options := []otlptracehttp.Option{
+		otlptracehttp.WithEndpointURL(endpoint.String()),
+		otlptracehttp.WithTimeout(t.timeout),
+	}
+	if len(cfg.Headers) > 0 {
+		options = append(options, otlptracehttp.WithHeaders(cfg.Headers))
+	}
+
+	// the exporter connects lazily so creating it never holds up a failover
+	exporter, err := otlptracehttp.New(context.Background(), options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create otlp trace exporter: %w", err)
+	}
+
+	t.provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", ServiceName),
+			attribute.String("service.version", pkgconstants.AppVersion),
+			attribute.String("host.name", hostname),
+		)),
+	)
+	t.tracer = t.provider.Tracer(ServiceName)
+	return t, nil
+}
+
+// IsEnabled returns true if the tracer has an endpoint to export spans to
+func (t *Tracer) IsEnabled() bool {
+	return t != nil && t.provider != nil
+}
+
+// Start starts a span as a child of the span in ctx, or a new trace when there is none
+func (t *Tracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	if t == nil {
+		return noopTracer.Start(ctx, name, opts...)
+	}
+	return t.tracer.Start(ctx, name, opts...)
+}
+
+// Record records an interval timed before the span in ctx was started as a child span of it
+func (t *Tracer) Record(ctx context.Context, name string, interval Interval, attributes ...attribute.KeyValue) {
+	if !t.IsEnabled() || interval.IsZero() {
+		return
+	}
+	_, span := t.tracer.Start(ctx, name, trace.WithTimestamp(interval.Start), trace.WithAttributes(attributes...))
+	if interval.Err != nil {
+		span.RecordError(interval.Err)
+		span.SetStatus(codes.Error, interval.Err.Error())
+	}
+	span.End(trace.WithTimestamp(interval.End))
+}
+
+// Shutdown exports spans still waiting to be sent and stops the tracer - bounded by the export timeout
+func (t *Tracer) Shutdown() {
+	if !t.IsEnabled() {
+		return
+	}
+	t.shutdown.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), t.timeout)
+		defer cancel()
+		if err := t.provider.Shutdown(ctx); err != nil {
+			t.logger.Warn().Err(err).Msg("failed to export failover trace")
+			return
+		}
+		t.logger.Debug().Msg("exported failover trace")
+	})
+}
+
+// End ends a span, marking it failed with err when set
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// EndWithReason ends a span, marking it failed with reason when set
+func EndWithReason(span trace.Span, reason string) {
+	if reason != "" {
+		span.SetStatus(codes.Error, reason)
+	}
+	span.End()
+}
+
+// TraceParent returns the w3c traceparent of the span in ctx to carry to a peer - empty when it isn't sampled
+func TraceParent(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	return carrier.Get(traceParentKey)
+}
+
+// WithTraceParent returns ctx carrying the remote span a peer sent as a w3c traceparent - spans started from it join
+// the peer's trace
+func WithTraceParent(ctx context.Context, traceParent string) context.Context {
+	if traceParent == "" {
+		return ctx
+	}
+	return propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier{traceParentKey: traceParent})
+}
+
+// TraceID returns the trace id of the span in ctx - empty when there is none
+func TraceID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() {
+		return ""
+	}
+	return spanContext.TraceID().String()
+}
+
+// parseEndpoint parses and validates an otlp/http endpoint, defaulting its path to the traces path
+func parseEndpoint(endpoint string) (*url.URL, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint %s: %w", endpoint, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid endpoint %s: scheme must be http or https", endpoint)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid endpoint %s: missing host", endpoint)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = defaultURLPath
+	}
+	return u, nil
+}
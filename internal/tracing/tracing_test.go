@@ -0,0 +1,114 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigValidate(t *testing.T) {
+	assert.NoError(t, Config{}.Validate())
+	assert.NoError(t, Config{Endpoint: "http://localhost:4318"}.Validate())
+	assert.NoError(t, Config{Endpoint: "https://otlp.example.com/v1/traces", Timeout: "2s"}.Validate())
+
+	err := Config{Endpoint: "grpc://localhost:4317"}.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "scheme must be http or https")
+
+	err = Config{Endpoint: "http://"}.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "missing host")
+
+	err = Config{Endpoint: "http://localhost:4318", Timeout: "soon"}.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse timeout")
+}
+
+func TestParseEndpoint_DefaultPath(t *testing.T) {
+	u, err := parseEndpoint("http://localhost:4318")
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:4318/v1/traces", u.String())
+
+	u, err = parseEndpoint("https://otlp.example.com/custom/traces")
+	require.NoError(t, err)
+	assert.Equal(t, "https://otlp.example.com/custom/traces", u.String())
+}
+
+func TestNewTracer_Disabled(t *testing.T) {
+	tracer, err := NewTracer(Config{}, "node-a")
+	require.NoError(t, err)
+	assert.False(t, tracer.IsEnabled())
+
+	ctx, span := tracer.Start(context.Background(), "failover")
+	assert.False(t, span.IsRecording())
+	assert.Empty(t, TraceParent(ctx))
+	tracer.Record(ctx, "wait_for_healthy", Interval{Start: time.Now(), End: time.Now()})
+	End(span, errors.New("ignored"))
+	tracer.Shutdown()
+}
+
+func TestNilTracer(t *testing.T) {
+	var tracer *Tracer
+	assert.False(t, tracer.IsEnabled())
+
+	_, span := tracer.Start(context.Background(), "failover")
+	assert.False(t, span.IsRecording())
+	tracer.Record(context.Background(), "wait_for_healthy", Interval{Start: time.Now(), End: time.Now()})
+	tracer.Shutdown()
+}
+
+func TestTracer_ExportsJoinedTrace(t *testing.T) {
+	var exports atomic.Int32
+	var gotHeader atomic.Value
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/traces" {
+			exports.Add(1)
+			gotHeader.Store(r.Header.Get("x-api-key"))
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	cfg := Config{Endpoint: collector.URL, Headers: map[string]string{"x-api-key": "secret"}, Timeout: "2s"}
+	active, err := NewTracer(cfg, "node-a")
+	require.NoError(t, err)
+	passive, err := NewTracer(cfg, "node-b")
+	require.NoError(t, err)
+	assert.True(t, active.IsEnabled())
+
+	// the active node starts the trace and carries it to the passive node as a traceparent
+	activeCtx, activeSpan := active.Start(context.Background(), "failover")
+	traceParent := TraceParent(activeCtx)
+	require.NotEmpty(t, traceParent)
+	traceID := TraceID(activeCtx)
+	require.Len(t, traceID, 32)
+
+	passiveCtx, passiveSpan := passive.Start(WithTraceParent(context.Background(), traceParent), "failover")
+	assert.Equal(t, traceID, TraceID(passiveCtx))
+	passive.Record(passiveCtx, "wait_for_healthy", Interval{Start: time.Now().Add(-time.Second), End: time.Now()})
+	EndWithReason(passiveSpan, "")
+	End(activeSpan, nil)
+
+	active.Shutdown()
+	passive.Shutdown()
+	// shutting down twice is harmless
+	active.Shutdown()
+
+	assert.Equal(t, int32(2), exports.Load())
+	assert.Equal(t, "secret", gotHeader.Load())
+}
+
+func TestWithTraceParent_Empty(t *testing.T) {
+	ctx := WithTraceParent(context.Background(), "")
+	assert.Empty(t, TraceID(ctx))
+
+	ctx = WithTraceParent(context.Background(), "not a traceparent")
+	assert.Empty(t, TraceID(ctx))
+}
@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+)
+
+// Option is a choice in a select prompt - Key is shown, Value is selected
+type Option struct {
+	Key   string
+	Value string
+}
+
+// Select prompts for one of the options starting on value's current one and sets value to the chosen one - it asks
+// for a number on stdin when the terminal ui is off or fails to start
+func Select(title string, options []Option, value *string) error {
+	if IsPlain() {
+		return selectPlain(os.Stdin, os.Stderr, title, options, value)
+	}
+
+	huhOptions := make([]huh.Option[string], 0, len(options))
+	for _, option := range options {
+		huhOptions = append(huhOptions, huh.NewOption(option.Key, option.Value))
+	}
+
+	err := huh.NewSelect[string]().
+		Title(title).
+		Options(huhOptions...).
+		Value(value).
+		Run()
+	if err == nil || errors.Is(err, huh.ErrUserAborted) {
+		return err
+	}
+
+	fallBack("failed to start", err)
+	return selectPlain(os.Stdin, os.Stderr, title, options, value)
+}
+
+// selectPlain lists the options numbered and reads the chosen number from in - an empty line keeps value's current
+// option
+func selectPlain(in io.Reader, out io.Writer, title string, options []Option, value *string) error {
+	if len(options) == 0 {
+		return fmt.Errorf("no options to select from")
+	}
+
+	defaultChoice := 1
+	fmt.Fprintln(out, title)
+	for i, option := range options {
+		if option.Value == *value {
+			defaultChoice = i + 1
+		}
+		fmt.Fprintf(out, "  %d) %s\n", i+1, option.Key)
+	}
+
+	reader := bufio.NewReader(in)
+	for {
+		fmt.Fprintf(out, "Enter a number [%d]: ", defaultChoice)
+		line, err := reader.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("failed to read selection: %w", err)
+		}
+		if err == io.EOF && line == "" {
+			return fmt.Errorf("failed to read selection: %w", huh.ErrUserAborted)
+		}
+
+		if line == "" {
+			*value = options[defaultChoice-1].Value
+			return nil
+		}
+		choice, convErr := strconv.Atoi(line)
+		if convErr != nil || choice < 1 || choice > len(options) {
+			fmt.Fprintf(out, "%q is not a number from 1 to %d\n", line, len(options))
+			continue
+		}
+		*value = options[choice-1].Value
+		return nil
+	}
+}
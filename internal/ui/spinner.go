@@ -0,0 +1,152 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/huh/spinner"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Spinner runs an action behind a huh spinner, or prints its title as plain lines when the terminal ui is off or
+// fails to start - the action is run exactly once either way
+type Spinner struct {
+	spinner *spinner.Spinner
+	action  func(ctx context.Context) error
+	ctx     context.Context
+	output  io.Writer
+
+	mu          sync.Mutex
+	title       string
+	isPlain     bool
+	printedLine string
+}
+
+// NewSpinner creates a new spinner
+func NewSpinner() *Spinner {
+	return &Spinner{
+		spinner: spinner.New(),
+		ctx:     context.Background(),
+		output:  os.Stderr,
+	}
+}
+
+// Title sets the spinner's title - printed as a new line when it changes in plain mode
+func (s *Spinner) Title(title string) *Spinner {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.title = title
+	s.spinner.Title(title)
+	if s.isPlain {
+		s.printTitle()
+	}
+	return s
+}
+
+// TitleStyle sets the style of the spinner's title
+func (s *Spinner) TitleStyle(style lipgloss.Style) *Spinner {
+	s.spinner.TitleStyle(style)
+	return s
+}
+
+// Context sets the context the action is run with
+func (s *Spinner) Context(ctx context.Context) *Spinner {
+	s.ctx = ctx
+	return s
+}
+
+// Action sets the action to run
+func (s *Spinner) Action(action func()) *Spinner {
+	return s.ActionWithErr(func(context.Context) error {
+		action()
+		return nil
+	})
+}
+
+// ActionWithErr sets the action to run
+func (s *Spinner) ActionWithErr(action func(ctx context.Context) error) *Spinner {
+	s.action = action
+	return s
+}
+
+// Run runs the action behind the spinner and returns its error - when the terminal ui fails or hasn't started the
+// action within the init timeout the spinner falls back to plain lines and runs it itself
+func (s *Spinner) Run() error {
+	if s.action == nil {
+		return nil
+	}
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	if IsPlain() {
+		return s.runPlain()
+	}
+
+	// whichever of the terminal ui and the fallback claims the action first runs it
+	var claim sync.Once
+	claimed := func() (ok bool) {
+		claim.Do(func() { ok = true })
+		return ok
+	}
+
+	uiCtx, cancelUI := context.WithCancel(s.ctx)
+	defer cancelUI()
+
+	started := make(chan struct{})
+	s.spinner.Context(uiCtx).ActionWithErr(func(context.Context) error {
+		if !claimed() {
+			return nil
+		}
+		close(started)
+		return s.action(s.ctx)
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.spinner.Run()
+	}()
+
+	select {
+	case <-started:
+		return <-done
+	case err := <-done:
+		if !claimed() {
+			// the terminal ui ran the action
+			return err
+		}
+		if ctxErr := s.ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		fallBack("failed to start", err)
+	case <-time.After(InitTimeout):
+		if !claimed() {
+			return <-done
+		}
+		cancelUI()
+		fallBack(fmt.Sprintf("didn't start within %s", InitTimeout), nil)
+	}
+
+	return s.runPlain()
+}
+
+// runPlain prints the title as a line then runs the action
+func (s *Spinner) runPlain() error {
+	s.mu.Lock()
+	s.isPlain = true
+	s.printTitle()
+	s.mu.Unlock()
+	return s.action(s.ctx)
+}
+
+// printTitle prints the title unless it's the line last printed - callers hold the lock
+func (s *Spinner) printTitle() {
+	if s.title == "" || s.title == s.printedLine {
+		return
+	}
+	s.printedLine = s.title
+	fmt.Fprintln(s.output, s.title)
+}
@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/term"
+)
+
+// DefaultInitTimeout is how long the terminal ui may take to start before it is given up on for plain lines - some
+// terminals never answer the queries it makes on start
+const DefaultInitTimeout = 3 * time.Second
+
+var (
+	plain atomic.Bool
+
+	// InitTimeout is how long the terminal ui may take to start before falling back to plain lines
+	InitTimeout = DefaultInitTimeout
+)
+
+// SetPlain sets whether spinners and prompts are drawn as plain lines rather than with the terminal ui
+func SetPlain(isPlain bool) {
+	plain.Store(isPlain)
+}
+
+// IsPlain returns true when spinners and prompts are drawn as plain lines
+func IsPlain() bool {
+	return plain.Load()
+}
+
+// TerminalSupportsUI returns true when stdout is a terminal that isn't dumb - the terminal ui is not tried otherwise
+func TerminalSupportsUI() bool {
+	return os.Getenv("TERM") != "dumb" && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// fallBack switches to plain lines for the rest of the run after the terminal ui failed to start
+func fallBack(reason string, err error) {
+	if !plain.CompareAndSwap(false, true) {
+		return
+	}
+	log.Warn().Err(err).Msgf("terminal ui %s - falling back to plain lines, pass --plain-ui to skip trying it", reason)
+}
@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/huh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// plainSpinner returns a spinner writing its plain lines to out
+func plainSpinner(t *testing.T, out *bytes.Buffer) *Spinner {
+	t.Helper()
+	SetPlain(true)
+	t.Cleanup(func() { SetPlain(false) })
+	s := NewSpinner()
+	s.output = out
+	return s
+}
+
+func TestSpinner_PlainRunsActionOnceAndPrintsTitleChanges(t *testing.T) {
+	var out bytes.Buffer
+	s := plainSpinner(t, &out)
+
+	runs := 0
+	err := s.Title("waiting...").ActionWithErr(func(ctx context.Context) error {
+		runs++
+		s.Title("waiting...")
+		s.Title("still waiting...")
+		s.Title("still waiting...")
+		return nil
+	}).Run()
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, runs)
+	assert.Equal(t, "waiting...\nstill waiting...\n", out.String())
+}
+
+func TestSpinner_PlainReturnsActionError(t *testing.T) {
+	var out bytes.Buffer
+	s := plainSpinner(t, &out)
+
+	err := s.Title("checking...").ActionWithErr(func(ctx context.Context) error {
+		return errors.New("boom")
+	}).Run()
+
+	assert.EqualError(t, err, "boom")
+}
+
+func TestSpinner_CancelledContextSkipsAction(t *testing.T) {
+	var out bytes.Buffer
+	s := plainSpinner(t, &out)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ran := false
+	err := s.Context(ctx).Action(func() { ran = true }).Run()
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, ran)
+}
+
+func TestSelectPlain(t *testing.T) {
+	options := []Option{{Key: "node-a", Value: "a"}, {Key: "node-b", Value: "b"}, {Key: "node-c", Value: "c"}}
+
+	tests := []struct {
+		name     string
+		input    string
+		start    string
+		expected string
+	}{
+		{name: "number", input: "3\n", start: "a", expected: "c"},
+		{name: "empty keeps current", input: "\n", start: "b", expected: "b"},
+		{name: "invalid then valid", input: "9\nx\n1\n", start: "b", expected: "a"},
+		{name: "no trailing newline", input: "2", start: "a", expected: "b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			value := tt.start
+			err := selectPlain(strings.NewReader(tt.input), &out, "Select a peer:", options, &value)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+			assert.Contains(t, out.String(), "Select a peer:\n  1) node-a\n  2) node-b\n  3) node-c\n")
+		})
+	}
+}
+
+func TestSelectPlain_EOFAborts(t *testing.T) {
+	var out bytes.Buffer
+	value := "a"
+	err := selectPlain(strings.NewReader("7\n"), &out, "Select a peer:", []Option{{Key: "node-a", Value: "a"}}, &value)
+	assert.ErrorIs(t, err, huh.ErrUserAborted)
+	assert.Contains(t, out.String(), `"7" is not a number from 1 to 1`)
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"bytes"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
@@ -67,6 +68,37 @@ func IsValidURLWithPort(urlIn string) bool {
 	return true
 }
 
+// HostFromAddress returns the host part of an address that may or may not have a scheme and/or port
+func HostFromAddress(address string) string {
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+
+	parsedURL, err := url.Parse(address)
+	if err != nil {
+		return ""
+	}
+
+	return parsedURL.Hostname()
+}
+
+// RedactURL returns the url with any password and query values replaced so it is safe to log - rpc providers often
+// take api keys as query parameters
+func RedactURL(rawURL string) string {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	if parsedURL.RawQuery != "" {
+		query := parsedURL.Query()
+		for key := range query {
+			query.Set(key, "xxxxx")
+		}
+		parsedURL.RawQuery = query.Encode()
+	}
+	return parsedURL.Redacted()
+}
+
 // GetPublicIP returns the public IP address of the current machine
 func GetPublicIP() (string, error) {
 	log.Debug().Msg("getting public IP...")
@@ -164,8 +196,10 @@ func RemoveFile(path string) error {
 // RunCommandParams represents the parameters for running a command
 type RunCommandParams struct {
 	CommandSlice []string
-	DryRun       bool
-	LogDebug     bool
+	// Stdin is written to the command's stdin when set
+	Stdin    []byte
+	DryRun   bool
+	LogDebug bool
 }
 
 // RunCommand runs a command and returns the output
@@ -183,6 +217,9 @@ func RunCommand(params RunCommandParams) error {
 	}
 
 	cmd := exec.Command(params.CommandSlice[0], params.CommandSlice[1:]...)
+	if params.Stdin != nil {
+		cmd.Stdin = bytes.NewReader(params.Stdin)
+	}
 
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -221,30 +258,40 @@ func FileChecksum(path string) (string, error) {
 
 // GenerateTLSCertificate generates a TLS certificate
 func GenerateTLSCertificate() (tls.Certificate, error) {
+	certPEM, keyPEM, err := GenerateTLSCertificatePEM(24 * time.Hour)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	tlsCert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return tlsCert, fmt.Errorf("failed to create TLS certificate: %w", err)
+	}
+	return tlsCert, nil
+}
+
+// GenerateTLSCertificatePEM generates a self-signed TLS certificate valid for validFor and returns it and its key
+// PEM-encoded so it can be persisted
+func GenerateTLSCertificatePEM(validFor time.Duration) (certPEM, keyPEM []byte, err error) {
 	key, err := rsa.GenerateKey(rand.Reader, 2048)
 	if err != nil {
-		panic(err)
+		return nil, nil, fmt.Errorf("failed to generate key: %w", err)
 	}
 	template := x509.Certificate{
 		SerialNumber: big.NewInt(1),
 		NotBefore:    time.Now(),
-		NotAfter:     time.Now().Add(24 * time.Hour),
+		NotAfter:     time.Now().Add(validFor),
 		KeyUsage:     x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
 		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
 		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
 	}
 	certDER, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
 	if err != nil {
-		panic(err)
+		return nil, nil, fmt.Errorf("failed to create certificate: %w", err)
 	}
-	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
-	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
-
-	tlsCert, err := tls.X509KeyPair(certPEM, keyPEM)
-	if err != nil {
-		return tlsCert, fmt.Errorf("failed to create TLS certificate: %w", err)
-	}
-	return tlsCert, nil
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	return certPEM, keyPEM, nil
 }
 
 // EnsureBins ensures that the bins are installed
@@ -300,3 +347,52 @@ func SafeCloseFile(f *os.File) {
 		f.Close() // ignore error
 	}
 }
+
+// FormatSlotRanges formats slots as compact sorted ranges (e.g. "50-54,120,180-184") for readable diagnostics -
+// when maxRanges > 0 only the first maxRanges ranges are listed followed by a count of the slots left out
+func FormatSlotRanges(slots []uint64, maxRanges int) string {
+	if len(slots) == 0 {
+		return ""
+	}
+
+	sorted := slices.Clone(slots)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+
+	var (
+		b           strings.Builder
+		rangeCount  int
+		rangeStart  = sorted[0]
+		rangeEnd    = sorted[0]
+		slotsListed int
+	)
+
+	writeRange := func() {
+		if rangeCount > 0 {
+			b.WriteString(",")
+		}
+		if rangeStart == rangeEnd {
+			fmt.Fprintf(&b, "%d", rangeStart)
+		} else {
+			fmt.Fprintf(&b, "%d-%d", rangeStart, rangeEnd)
+		}
+		rangeCount++
+		slotsListed += int(rangeEnd-rangeStart) + 1
+	}
+
+	for _, slot := range sorted[1:] {
+		if slot == rangeEnd+1 {
+			rangeEnd = slot
+			continue
+		}
+		writeRange()
+		if maxRanges > 0 && rangeCount >= maxRanges {
+			fmt.Fprintf(&b, ",...(+%d more)", len(sorted)-slotsListed)
+			return b.String()
+		}
+		rangeStart, rangeEnd = slot, slot
+	}
+	writeRange()
+
+	return b.String()
+}
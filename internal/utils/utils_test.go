@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatSlotRanges(t *testing.T) {
+	tests := []struct {
+		name      string
+		slots     []uint64
+		maxRanges int
+		want      string
+	}{
+		{"no slots", nil, 0, ""},
+		{"single slot", []uint64{50}, 0, "50"},
+		{"consecutive slots", []uint64{50, 51, 52, 53}, 0, "50-53"},
+		{"unsorted", []uint64{53, 50, 52, 51}, 0, "50-53"},
+		{"duplicates", []uint64{50, 50, 51, 51, 51}, 0, "50-51"},
+		{"duplicate single slot", []uint64{50, 50}, 0, "50"},
+		{"gaps", []uint64{50, 51, 52, 53, 54, 120, 180, 181, 182, 183, 184}, 0, "50-54,120,180-184"},
+		{"truncated", []uint64{50, 51, 52, 53, 54, 120, 180, 181, 182, 183, 184}, 2, "50-54,120,...(+5 more)"},
+		{"truncated to one range", []uint64{1, 3, 5, 7}, 1, "1,...(+3 more)"},
+		{"as many ranges as allowed", []uint64{50, 51, 120}, 2, "50-51,120"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, FormatSlotRanges(tt.slots, tt.maxRanges))
+		})
+	}
+}
+
+func TestFormatSlotRanges_LeavesSlotsAsTheyAre(t *testing.T) {
+	slots := []uint64{52, 50, 50}
+	FormatSlotRanges(slots, 0)
+	assert.Equal(t, []uint64{52, 50, 50}, slots)
+}
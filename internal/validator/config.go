@@ -1,8 +1,11 @@
 package validator
 
 import (
+	"github.com/sol-strategies/solana-validator-failover/internal/events"
 	"github.com/sol-strategies/solana-validator-failover/internal/hooks"
 	"github.com/sol-strategies/solana-validator-failover/internal/identities"
+	"github.com/sol-strategies/solana-validator-failover/internal/notify"
+	"github.com/sol-strategies/solana-validator-failover/internal/tracing"
 )
 
 // Config is the configuration for the validator
@@ -16,35 +19,174 @@ type Config struct {
 	Tower      TowerConfig       `mapstructure:"tower"`
 	PublicIP   string            `mapstructure:"public_ip"` // subject for removal once poor-man's testing setup is removed
 	Hostname   string            `mapstructure:"hostname"`  // subject for removal once poor-man's testing setup is removed
+	// DebugLogSampleInterval is the minimum interval between repeated debug logs on high-volume rpc paths
+	DebugLogSampleInterval string `mapstructure:"debug_log_sample_interval"`
+	// FiredancerConfig is the firedancer config.toml passed to fdctl by the built-in firedancer set identity commands
+	FiredancerConfig string `mapstructure:"firedancer_config"`
+	// SilenceDeprecationWarnings stops deprecated features like .Pubkey in templates logging a notice when used
+	SilenceDeprecationWarnings bool `mapstructure:"silence_deprecation_warnings"`
+	// NetworkRPC is the network rpc endpoints cluster-wide calls fail over between
+	NetworkRPC NetworkRPCConfig `mapstructure:"network_rpc"`
+}
+
+// NetworkRPCConfig is the configuration for the network rpc endpoints answering cluster-wide calls
+type NetworkRPCConfig struct {
+	// URLs are tried in order before the cluster's public rpc
+	URLs []string `mapstructure:"urls"`
+	// Timeout is how long a call may take before the next url is tried
+	Timeout string `mapstructure:"timeout"`
+	// UnhealthyCooldown is how long a url that failed is only tried after the others
+	UnhealthyCooldown string `mapstructure:"unhealthy_cooldown"`
+	// Retry is how calls every url failed are retried
+	Retry NetworkRPCRetryConfig `mapstructure:"retry"`
+	// ClusterNodesCacheTTL is how long the cluster's gossip nodes are reused for lookups before fetching them again
+	ClusterNodesCacheTTL string `mapstructure:"cluster_nodes_cache_ttl"`
+	// Auth is the credentials sent to urls that need them
+	Auth []NetworkRPCAuthConfig `mapstructure:"auth"`
+}
+
+// NetworkRPCAuthConfig is the credentials sent with every call to one of the network rpc urls
+type NetworkRPCAuthConfig struct {
+	// URL is the network_rpc.urls entry the credentials are sent to
+	URL string `mapstructure:"url"`
+	// BearerToken is sent as an Authorization: Bearer header
+	BearerToken string `mapstructure:"bearer_token"`
+	// Headers are sent as they are, e.g. a provider's api key header
+	Headers map[string]string `mapstructure:"headers"`
+}
+
+// NetworkRPCRetryConfig is the configuration for retrying failed network rpc calls with exponential backoff
+type NetworkRPCRetryConfig struct {
+	MaxAttempts int     `mapstructure:"max_attempts"`
+	BaseDelay   string  `mapstructure:"base_delay"`
+	MaxDelay    string  `mapstructure:"max_delay"`
+	Jitter      float64 `mapstructure:"jitter"`
 }
 
 // TowerConfig is the configuration for the towerfile
 type TowerConfig struct {
-	Dir                  string `mapstructure:"dir"`
-	AutoEmptyWhenPassive bool   `mapstructure:"auto_empty_when_passive"`
-	FileNameTemplate     string `mapstructure:"file_name_template"`
+	Dir                  string                  `mapstructure:"dir"`
+	AutoEmptyWhenPassive bool                    `mapstructure:"auto_empty_when_passive"`
+	FileNameTemplate     string                  `mapstructure:"file_name_template"`
+	DriftMonitor         TowerDriftMonitorConfig `mapstructure:"drift_monitor"`
+	Backup               TowerBackupConfig       `mapstructure:"backup"`
+	SanityCheck          bool                    `mapstructure:"sanity_check"`
+	Replication          TowerReplicationConfig  `mapstructure:"replication"`
+}
+
+// TowerDriftMonitorConfig is the configuration for monitoring the tower file for drift when passive
+type TowerDriftMonitorConfig struct {
+	Enabled   bool   `mapstructure:"enabled"`
+	Interval  string `mapstructure:"interval"`
+	AutoClean bool   `mapstructure:"auto_clean"`
+}
+
+// TowerBackupConfig is the configuration for backing up the tower file before the passive node overwrites or
+// removes it
+type TowerBackupConfig struct {
+	Enabled   bool   `mapstructure:"enabled"`
+	Dir       string `mapstructure:"dir"`
+	Retention int    `mapstructure:"retention"`
+}
+
+// TowerReplicationConfig is the configuration for shipping the active node's tower file to its peers between
+// failovers, so a passive node can be promoted with a recent tower when the active node dies without handing over
+type TowerReplicationConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Interval string `mapstructure:"interval"`
+	Dir      string `mapstructure:"dir"`
 }
 
 // FailoverConfig is the configuration for a failover
 type FailoverConfig struct {
-	SetIdentityPassiveCmdTemplate string              `mapstructure:"set_identity_passive_cmd_template"`
-	SetIdentityActiveCmdTemplate  string              `mapstructure:"set_identity_active_cmd_template"`
-	Hooks                         hooks.FailoverHooks `mapstructure:"hooks"`
-	MinimumTimeToLeaderSlot       string              `mapstructure:"min_time_to_leader_slot"`
-	Monitor                       MonitorConfig       `mapstructure:"monitor"`
-	Peers                         PeersConfig         `mapstructure:"peers"`
-	Server                        ServerConfig        `mapstructure:"server"`
+	SetIdentityPassiveCmdTemplate string               `mapstructure:"set_identity_passive_cmd_template"`
+	SetIdentityActiveCmdTemplate  string               `mapstructure:"set_identity_active_cmd_template"`
+	Hooks                         hooks.FailoverHooks  `mapstructure:"hooks"`
+	MinimumTimeToLeaderSlot       string               `mapstructure:"min_time_to_leader_slot"`
+	SwitchCountdown               string               `mapstructure:"switch_countdown"`
+	MinActiveIdentityBalance      uint64               `mapstructure:"min_active_identity_balance_lamports"`
+	MaxSlotLag                    uint64               `mapstructure:"max_slot_lag"`
+	Monitor                       MonitorConfig        `mapstructure:"monitor"`
+	Peers                         PeersConfig          `mapstructure:"peers"`
+	Server                        ServerConfig         `mapstructure:"server"`
+	Client                        ClientConfig         `mapstructure:"client"`
+	LogSlotContext                LogSlotContextConfig `mapstructure:"log_slot_context"`
+	Drill                         DrillConfig          `mapstructure:"drill"`
+	Events                        events.Config        `mapstructure:"events"`
+	Notifications                 notify.Config        `mapstructure:"notifications"`
+	Agent                         AgentConfig          `mapstructure:"agent"`
+	TLS                           TLSConfig            `mapstructure:"tls"`
+	ClockCheck                    ClockCheckConfig     `mapstructure:"clock_check"`
+	FeatureGates                  FeatureGatesConfig   `mapstructure:"feature_gates"`
+	PeerSelection                 PeerSelectionConfig  `mapstructure:"peer_selection"`
+	Watch                         WatchConfig          `mapstructure:"watch"`
+	Audit                         AuditConfig          `mapstructure:"audit"`
+	RoleIntent                    RoleIntentConfig     `mapstructure:"role_intent"`
+	Tracing                       tracing.Config       `mapstructure:"tracing"`
+	PathMTUProbe                  PathMTUProbeConfig   `mapstructure:"path_mtu_probe"`
+	EpochBoundary                 EpochBoundaryConfig  `mapstructure:"epoch_boundary"`
+	Promote                       PromoteConfig        `mapstructure:"promote"`
 	IsDryRun                      bool
 }
 
 // PeersConfig is the configuration for the peers
-type PeersConfig map[string]struct {
+type PeersConfig map[string]PeerConfig
+
+// PeerConfig is the configuration for a single peer
+type PeerConfig struct {
+	// Address is the host:port to connect to the peer's failover server
 	Address string `mapstructure:"address"`
+	// Port overrides the port in Address - useful for port-forwarded setups
+	Port int `mapstructure:"port"`
+	// GossipIP is the IP the peer advertises in gossip when it differs from Address (NAT)
+	GossipIP string `mapstructure:"gossip_ip"`
+	// PassivePubkey is the peer's passive identity pubkey - when set, the server's peer allowlist requires it
+	PassivePubkey string `mapstructure:"passive_pubkey"`
+	// Discover looks the peer up in gossip by PassivePubkey for its IP instead of configuring Address - Port is the
+	// failover port to connect to it on
+	Discover bool `mapstructure:"discover"`
+	// Priority ranks the peer ahead of healthy passive peers with a lower priority - default 0
+	Priority int `mapstructure:"priority"`
 }
 
 // MonitorConfig holds the configuration for a failover monitor
 type MonitorConfig struct {
-	CreditSamples CreditSamplesConfig `mapstructure:"credit_samples"`
+	CreditSamples   CreditSamplesConfig   `mapstructure:"credit_samples"`
+	MetricsSnapshot MetricsSnapshotConfig `mapstructure:"metrics_snapshot"`
+	RewardsEstimate RewardsEstimateConfig `mapstructure:"rewards_estimate"`
+	// IdentityGapAlarmThreshold is the identity gap (time not voting anywhere) above which a failover alarms - 0s
+	// disables it
+	IdentityGapAlarmThreshold string `mapstructure:"identity_gap_alarm_threshold"`
+	// DetachedLogFile is where post-failover monitoring run in the background writes its output
+	DetachedLogFile string `mapstructure:"detached_log_file"`
+	// DurationRegression alerts when a completed failover's phases are slower than earlier failovers in the audit log
+	DurationRegression DurationRegressionConfig `mapstructure:"duration_regression"`
+	// BlockProduction checks the active identity produces blocks in its first leader slots after the failover
+	BlockProduction BlockProductionConfig `mapstructure:"block_production"`
+}
+
+// BlockProductionConfig holds the configuration for checking the active identity's block production in its first leader
+// slots after a failover
+type BlockProductionConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// LeaderSlots is how many of the active identity's leader slots after the failover are checked
+	LeaderSlots int `mapstructure:"leader_slots"`
+	// Timeout is how long to wait for them to pass - only those that did are checked
+	Timeout string `mapstructure:"timeout"`
+}
+
+// DurationRegressionConfig holds the configuration for comparing a failover's phase durations against the median of
+// the failovers before it
+type DurationRegressionConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// ThresholdPercent is how much slower than the median a phase must be to regress
+	ThresholdPercent float64 `mapstructure:"threshold_percent"`
+	// BaselineFailovers is how many of the latest failovers of the same kind (drill or real) the median is taken over
+	BaselineFailovers int `mapstructure:"baseline_failovers"`
+	// MinBaselineFailovers is how many of them must have timed a phase for it to be compared
+	MinBaselineFailovers int `mapstructure:"min_baseline_failovers"`
+	// MinIncrease is how much slower than the median a phase must also be so fast phases don't regress on noise
+	MinIncrease string `mapstructure:"min_increase"`
 }
 
 // CreditSamplesConfig holds the configuration for a failover monitor credit samples
@@ -53,9 +195,220 @@ type CreditSamplesConfig struct {
 	Interval string `mapstructure:"interval"`
 }
 
+// RewardsEstimateConfig holds the configuration for estimating what the failover's identity gap cost in epoch rewards
+type RewardsEstimateConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// MetricsSnapshotConfig holds the configuration for snapshotting local rpc metrics before and after a failover
+type MetricsSnapshotConfig struct {
+	Enabled              bool   `mapstructure:"enabled"`
+	BlockProductionSlots uint64 `mapstructure:"block_production_slots"`
+}
+
 // ServerConfig holds the configuration for a failover server
 type ServerConfig struct {
 	Port              int    `mapstructure:"port"`
 	HeartbeatInterval string `mapstructure:"heartbeat_interval"`
 	StreamTimeout     string `mapstructure:"stream_timeout"`
+	// WaitProgressInterval is how often progress is logged while waiting for the active node - 0s disables it
+	WaitProgressInterval string `mapstructure:"wait_progress_interval"`
+	// PeerAllowlist rejects connecting nodes that aren't configured peers claiming this validator's identities
+	PeerAllowlist bool `mapstructure:"peer_allowlist"`
+	// EphemeralPortFallback listens on an ephemeral port when port is below 1024 and this process lacks
+	// CAP_NET_BIND_SERVICE - the port is sent to the active node with --via-agent
+	EphemeralPortFallback bool `mapstructure:"ephemeral_port_fallback"`
+	// QUIC tunes the listener's quic transport - its idle timeout and keep alive default to stream_timeout and
+	// heartbeat_interval
+	QUIC QUICConfig `mapstructure:"quic"`
+	// TLS is the certificate this node's failover server and agent present instead of the self-signed one
+	TLS ServerTLSConfig `mapstructure:"tls"`
+}
+
+// ServerTLSConfig holds the paths of an operator-supplied certificate, key and client certificate authority
+type ServerTLSConfig struct {
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+	// CAFile requires connecting peers to present a client certificate it issued for their configured peer name
+	CAFile string `mapstructure:"ca_file"`
+	// SelfSignedFallback presents the self-signed certificate when CertFile or KeyFile can't be loaded instead of
+	// refusing to start
+	SelfSignedFallback bool `mapstructure:"self_signed_fallback"`
+}
+
+// AgentConfig holds the configuration for the agent run on the active node so passive nodes can initiate failovers
+type AgentConfig struct {
+	Port         int                `mapstructure:"port"`
+	FileTransfer FileTransferConfig `mapstructure:"file_transfer"`
+	// ShareConfig answers peers asking for this node's config to diff against theirs
+	ShareConfig bool `mapstructure:"share_config"`
+}
+
+// FileTransferConfig holds the directories peers may push files into through the agent and the largest file
+// accepted - no allowed dirs disables file pushes
+type FileTransferConfig struct {
+	AllowedDirs []string `mapstructure:"allowed_dirs"`
+	MaxSize     int64    `mapstructure:"max_size"`
+}
+
+// TLSConfig holds the configuration for this node's persisted certificate and pinned peer certificates
+type TLSConfig struct {
+	Dir                 string `mapstructure:"dir"`
+	PinPeerCertificates bool   `mapstructure:"pin_peer_certificates"`
+	// RequireClientCertificates requires connecting peers to present a client certificate issued by the client
+	// certificate authority in Dir for their configured peer name
+	RequireClientCertificates bool `mapstructure:"require_client_certificates"`
+}
+
+// ClockCheckConfig holds the configuration for the local clock offset check run before a failover
+type ClockCheckConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Source is where the offset comes from - ntp queries NTPServer directly, chronyc reads chronyc tracking
+	Source    string `mapstructure:"source"`
+	NTPServer string `mapstructure:"ntp_server"`
+	// MaxOffset is the largest local clock offset tolerated before Action is taken
+	MaxOffset string `mapstructure:"max_offset"`
+	// Action is warn to log and carry on or refuse to abort the failover when the offset exceeds MaxOffset
+	Action  string `mapstructure:"action"`
+	Timeout string `mapstructure:"timeout"`
+}
+
+// EpochBoundaryConfig holds the guard against failing over close to an epoch boundary, where the leader schedule is
+// recalculated and vote credits start again
+type EpochBoundaryConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// WindowSlots is how close to an epoch boundary (either side of it) Action is taken
+	WindowSlots uint64 `mapstructure:"window_slots"`
+	// Action is warn to log and carry on or refuse to abort the failover within WindowSlots of a boundary
+	Action string `mapstructure:"action"`
+}
+
+// FeatureGatesConfig holds the cluster feature gates checked for an activation window before a failover
+type FeatureGatesConfig struct {
+	// WindowSlots is how close to an activation (either side of it) a failover is warned about
+	WindowSlots uint64 `mapstructure:"window_slots"`
+	// Features are the feature gates known to affect towers or identity switching - empty disables the check
+	Features []FeatureGateConfig `mapstructure:"features"`
+}
+
+// FeatureGateConfig is a single cluster feature gate to check
+type FeatureGateConfig struct {
+	Name string `mapstructure:"name"`
+	ID   string `mapstructure:"id"`
+}
+
+// PathMTUProbeConfig holds the configuration for probing the path mtu to the passive node before a failover
+type PathMTUProbeConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MinMTU is the path mtu below which the failover warns that the tower file transfer will be slowed
+	MinMTU  int    `mapstructure:"min_mtu"`
+	Timeout string `mapstructure:"timeout"`
+}
+
+// PeerSelectionConfig holds the configuration for ranking passive peers when more than one is configured
+type PeerSelectionConfig struct {
+	// AutoSelect fails over to the best ranked passive peer without prompting when it is in gossip and healthy
+	AutoSelect bool `mapstructure:"auto_select"`
+	// Timeout is how long passive peers' status is queried for - peers that don't answer in time rank unknown
+	Timeout string `mapstructure:"timeout"`
+}
+
+// ClientConfig holds the configuration for a failover client
+type ClientConfig struct {
+	DialRetries          int    `mapstructure:"dial_retries"`
+	DialRetryInterval    string `mapstructure:"dial_retry_interval"`
+	DialRetryMaxInterval string `mapstructure:"dial_retry_max_interval"`
+	// QUIC tunes the quic transport of the connection to the passive peer
+	QUIC QUICConfig `mapstructure:"quic"`
+	// TLS is the client certificate this node presents to peers and the authority their certificates must chain to
+	TLS ClientTLSConfig `mapstructure:"tls"`
+}
+
+// ClientTLSConfig holds the paths of an operator-supplied client certificate, key and server certificate authority
+type ClientTLSConfig struct {
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+	// CAFile requires the passive peer's failover server to present a certificate it issued for the peer's name or
+	// address host
+	CAFile string `mapstructure:"ca_file"`
+}
+
+// QUICConfig holds the quic transport tuning of a failover server or client - unset keys keep quic-go's defaults
+type QUICConfig struct {
+	MaxIdleTimeout  string `mapstructure:"max_idle_timeout"`
+	KeepAlivePeriod string `mapstructure:"keep_alive_period"`
+	// receive windows are in bytes - raise the max ones to the link's bandwidth-delay product on long fat links
+	InitialStreamReceiveWindow     uint64 `mapstructure:"initial_stream_receive_window"`
+	MaxStreamReceiveWindow         uint64 `mapstructure:"max_stream_receive_window"`
+	InitialConnectionReceiveWindow uint64 `mapstructure:"initial_connection_receive_window"`
+	MaxConnectionReceiveWindow     uint64 `mapstructure:"max_connection_receive_window"`
+	Allow0RTT                      bool   `mapstructure:"allow_0rtt"`
+}
+
+// LogSlotContextConfig holds the configuration for decorating critical failover window log lines with slot and epoch
+type LogSlotContextConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Interval string `mapstructure:"interval"`
+}
+
+// DrillConfig holds the configuration for scheduled dry-run failover drills
+type DrillConfig struct {
+	// Schedule is a standard five field cron expression - empty disables scheduled drills
+	Schedule string `mapstructure:"schedule"`
+	// Peer is the name of the peer the active node drills against - required with more than one peer
+	Peer string `mapstructure:"peer"`
+	// Timeout is how long the passive node waits for the active node to connect for a drill
+	Timeout string `mapstructure:"timeout"`
+}
+
+// AuditConfig holds the configuration for the append-only log of every drill and real failover this node takes part in
+type AuditConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Dir is the state dir the audit log is kept in
+	Dir string `mapstructure:"dir"`
+}
+
+// RoleIntentConfig holds the configuration for the file recording which role this node should hold after the last
+// failover or swap - compared against gossip to catch identities changed outside of failovers
+type RoleIntentConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Dir is the state dir the role intent file is kept in
+	Dir string `mapstructure:"dir"`
+}
+
+// WatchConfig holds the configuration for the watch daemon run on the passive node to fail over unattended
+type WatchConfig struct {
+	// Peer is the name of the active peer whose agent is asked to hand over - required with more than one peer
+	Peer string `mapstructure:"peer"`
+	// Interval is how often the active identity is checked
+	Interval string `mapstructure:"interval"`
+	// FailureThreshold is how long a failure condition must persist before failing over
+	FailureThreshold string `mapstructure:"failure_threshold"`
+	// Cooldown is how long watching pauses after a failover attempt
+	Cooldown string `mapstructure:"cooldown"`
+	// Timeout is how long the passive node waits for the active node to connect once its agent accepts
+	Timeout string `mapstructure:"timeout"`
+	// NotADrill fails over for real - otherwise failovers started by watch are dry runs
+	NotADrill bool `mapstructure:"not_a_drill"`
+	// Takeover promotes this node itself when the active identity has left gossip and its last vote stopped advancing -
+	// its node can't hand over then
+	Takeover   bool                  `mapstructure:"takeover"`
+	Conditions WatchConditionsConfig `mapstructure:"conditions"`
+}
+
+// PromoteConfig holds the safety checks for an emergency promotion of a passive node whose active peer is dead
+type PromoteConfig struct {
+	// MinGossipAbsence is how long the active identity must stay out of gossip before this node is promoted
+	MinGossipAbsence string `mapstructure:"min_gossip_absence"`
+}
+
+// WatchConditionsConfig holds the failure conditions the watch daemon checks the active identity for
+type WatchConditionsConfig struct {
+	// NotInGossip fails when the active identity isn't in gossip
+	NotInGossip bool `mapstructure:"not_in_gossip"`
+	// Delinquent fails when the active identity's vote account is delinquent
+	Delinquent bool `mapstructure:"delinquent"`
+	// VoteCreditsStalled fails when the vote account earned no credits and its last vote didn't advance since the
+	// previous check
+	VoteCreditsStalled bool `mapstructure:"vote_credits_stalled"`
 }
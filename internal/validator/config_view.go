@@ -0,0 +1,120 @@
+package validator
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/sol-strategies/solana-validator-failover/internal/failover"
+	"github.com/sol-strategies/solana-validator-failover/internal/utils"
+	pkgconstants "github.com/sol-strategies/solana-validator-failover/pkg/constants"
+)
+
+// newConfigView returns the config this node shares with its peers to catch drift between the pair - settings a
+// failover depends on both nodes agreeing about. Templates and hooks may hold secrets so are only shared hashed
+func newConfigView(cfg *Config, binMetadata BinMetadata) failover.ConfigView {
+	view := failover.ConfigView{}
+	view.Set("version", pkgconstants.AppVersion)
+	view.Set("validator.client", binMetadata.Client)
+	view.Set("validator.client_version", binMetadata.Version)
+	view.Set("cluster", cfg.Cluster)
+
+	flattenConfigView(view, "tower", reflect.ValueOf(cfg.Tower))
+	view.SetHashed("tower.file_name_template", cfg.Tower.FileNameTemplate)
+	delete(view, "tower.dir")
+
+	setHashedUnlessEmpty(view, "failover.set_identity_active_cmd_template", cfg.Failover.SetIdentityActiveCmdTemplate)
+	setHashedUnlessEmpty(view, "failover.set_identity_passive_cmd_template", cfg.Failover.SetIdentityPassiveCmdTemplate)
+	setHashedUnlessEmpty(view, "failover.hooks.check", hooksString(cfg.Failover.Hooks.Check))
+	setHashedUnlessEmpty(view, "failover.hooks.pre", hooksString(cfg.Failover.Hooks.Pre))
+	setHashedUnlessEmpty(view, "failover.hooks.post", hooksString(cfg.Failover.Hooks.Post))
+	setHashedUnlessEmpty(view, "failover.hooks.on_abort", hooksString(cfg.Failover.Hooks.OnAbort))
+	setHashedUnlessEmpty(view, "failover.hooks.before_set_identity", hooksString(cfg.Failover.Hooks.BeforeSetIdentity))
+	setHashedUnlessEmpty(view, "failover.hooks.after_tower_received", hooksString(cfg.Failover.Hooks.AfterTowerReceived))
+	setHashedUnlessEmpty(view, "failover.hooks.after_gossip_confirmed", hooksString(cfg.Failover.Hooks.AfterGossipConfirmed))
+
+	view.Set("failover.min_time_to_leader_slot", cfg.Failover.MinimumTimeToLeaderSlot)
+	view.Set("failover.switch_countdown", cfg.Failover.SwitchCountdown)
+	view.Set("failover.min_active_identity_balance_lamports", strconv.FormatUint(cfg.Failover.MinActiveIdentityBalance, 10))
+	view.Set("failover.max_slot_lag", strconv.FormatUint(cfg.Failover.MaxSlotLag, 10))
+	flattenConfigView(view, "failover.monitor", reflect.ValueOf(cfg.Failover.Monitor))
+	flattenConfigView(view, "failover.server", reflect.ValueOf(cfg.Failover.Server))
+	flattenConfigView(view, "failover.client", reflect.ValueOf(cfg.Failover.Client))
+	flattenConfigView(view, "failover.clock_check", reflect.ValueOf(cfg.Failover.ClockCheck))
+	flattenConfigView(view, "failover.epoch_boundary", reflect.ValueOf(cfg.Failover.EpochBoundary))
+	return view
+}
+
+// setHashedUnlessEmpty adds a hashed value - an empty one is shared as it is so unset reads as unset
+func setHashedUnlessEmpty(view failover.ConfigView, key, value string) {
+	if value == "" {
+		view.Set(key, "")
+		return
+	}
+	view.SetHashed(key, value)
+}
+
+// hooksString returns hooks as a string that is equal for equal hooks - empty when there are none
+func hooksString(hooks any) string {
+	// nil and empty slices format the same
+	formatted := fmt.Sprintf("%+v", hooks)
+	if formatted == fmt.Sprintf("%+v", reflect.Zero(reflect.TypeOf(hooks)).Interface()) {
+		return ""
+	}
+	return formatted
+}
+
+// flattenConfigView adds the fields of a config struct to view by their dotted mapstructure keys under prefix
+func flattenConfigView(view failover.ConfigView, prefix string, value reflect.Value) {
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		name := strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+		if !field.IsExported() || name == "" || name == "-" {
+			continue
+		}
+		key := prefix + "." + name
+		if field.Type.Kind() == reflect.Struct {
+			flattenConfigView(view, key, value.Field(i))
+			continue
+		}
+		view.Set(key, fmt.Sprint(value.Field(i).Interface()))
+	}
+}
+
+// ConfigDiff asks a peer's agent for its config view and returns where it differs from this node's - the peer is
+// required unless only one is configured, and its agent must share its config with failover.agent.share_config
+func (v *Validator) ConfigDiff(peerName string) (peerHostname string, differences []failover.ConfigDifference, err error) {
+	if peerName == "" {
+		if len(v.Peers) != 1 {
+			return "", nil, fmt.Errorf("%d peers configured and none selected - pass --peer <name> to choose the peer to diff against", len(v.Peers))
+		}
+		for name := range v.Peers {
+			peerName = name
+		}
+	}
+	peer, ok := v.Peers[peerName]
+	if !ok {
+		return "", nil, fmt.Errorf("peer %s not found in failover.peers", peerName)
+	}
+
+	agentPort := v.Agent.Port
+	if agentPort == 0 {
+		agentPort = failover.DefaultAgentPort
+	}
+
+	response, err := failover.RequestConfigView(failover.RequestConfigViewParams{
+		AgentName:         peer.Name,
+		AgentAddress:      net.JoinHostPort(utils.HostFromAddress(peer.Address), strconv.Itoa(agentPort)),
+		Hostname:          v.Hostname,
+		PublicIP:          v.PublicIP,
+		PeerPins:          v.PeerPins,
+		ClientCertificate: v.ClientCertificate,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return response.Hostname, failover.DiffConfigViews(v.configView, response.View), nil
+}
@@ -0,0 +1,70 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/sol-strategies/solana-validator-failover/internal/failover"
+	"github.com/sol-strategies/solana-validator-failover/internal/hooks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConfigView(t *testing.T) {
+	cfg := &Config{
+		Cluster: "testnet",
+		Tower: TowerConfig{
+			Dir:                  "/mnt/tower",
+			AutoEmptyWhenPassive: true,
+			FileNameTemplate:     "tower-1_9-{{ .Identities.Active.PubKey }}.bin",
+		},
+		Failover: FailoverConfig{
+			SetIdentityActiveCmdTemplate: "agave-validator --ledger {{ .LedgerDir }} set-identity {{ .Identities.Active.KeyFile }}",
+			SwitchCountdown:              "5s",
+			Hooks: hooks.FailoverHooks{
+				Pre: hooks.PreHooks{WhenActive: hooks.Hooks{{Name: "notify", Command: "curl", Args: []string{"https://hooks.example.com/secret"}}}},
+			},
+		},
+	}
+	view := newConfigView(cfg, BinMetadata{Client: "agave", Version: "2.2.0"})
+
+	assert.Equal(t, "testnet", view["cluster"].String())
+	assert.Equal(t, "agave", view["validator.client"].String())
+	assert.Equal(t, "2.2.0", view["validator.client_version"].String())
+	assert.Equal(t, "true", view["tower.auto_empty_when_passive"].String())
+	assert.Equal(t, "false", view["tower.drift_monitor.enabled"].String())
+	assert.Equal(t, "5s", view["failover.switch_countdown"].String())
+	assert.NotContains(t, view, "tower.dir", "node-specific paths are not shared")
+
+	// templates and hooks are only shared hashed
+	for _, key := range []string{"tower.file_name_template", "failover.set_identity_active_cmd_template", "failover.hooks.pre"} {
+		require.Contains(t, view, key)
+		assert.True(t, view[key].Hashed, key)
+		assert.Len(t, view[key].Value, 16, key)
+	}
+	assert.NotContains(t, view["failover.hooks.pre"].String(), "secret")
+	assert.Equal(t, failover.ConfigViewEntry{}, view["failover.set_identity_passive_cmd_template"], "unset templates read as unset")
+	assert.Equal(t, failover.ConfigViewEntry{}, view["failover.hooks.check"])
+
+	// empty and nil hooks compare equal
+	cfg.Failover.Hooks.Check = hooks.Hooks{}
+	assert.Equal(t, failover.ConfigViewEntry{}, newConfigView(cfg, BinMetadata{})["failover.hooks.check"])
+}
+
+func TestNewConfigView_Diff(t *testing.T) {
+	local := &Config{Cluster: "testnet", Failover: FailoverConfig{SwitchCountdown: "5s", SetIdentityActiveCmdTemplate: "a"}}
+	peer := &Config{Cluster: "testnet", Failover: FailoverConfig{SwitchCountdown: "10s", SetIdentityActiveCmdTemplate: "b"}}
+
+	assert.Empty(t, failover.DiffConfigViews(newConfigView(local, BinMetadata{}), newConfigView(local, BinMetadata{})))
+
+	differences := failover.DiffConfigViews(newConfigView(local, BinMetadata{}), newConfigView(peer, BinMetadata{}))
+	require.Len(t, differences, 2)
+	assert.Equal(t, "failover.set_identity_active_cmd_template", differences[0].Key)
+	assert.Contains(t, differences[0].Local, "sha256:")
+	assert.Equal(t, failover.ConfigDifference{Key: "failover.switch_countdown", Local: "5s", Peer: "10s"}, differences[1])
+
+	// keys only one side has are listed with the other side empty
+	peerView := newConfigView(local, BinMetadata{})
+	peerView.Set("failover.new_setting", "on")
+	differences = failover.DiffConfigViews(newConfigView(local, BinMetadata{}), peerView)
+	assert.Equal(t, []failover.ConfigDifference{{Key: "failover.new_setting", Peer: "on"}}, differences)
+}
@@ -0,0 +1,376 @@
+package validator
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/sol-strategies/solana-validator-failover/internal/constants"
+	"github.com/sol-strategies/solana-validator-failover/internal/failover"
+	"github.com/sol-strategies/solana-validator-failover/internal/identities"
+	"github.com/sol-strategies/solana-validator-failover/internal/ports"
+	"github.com/sol-strategies/solana-validator-failover/internal/style"
+	"github.com/sol-strategies/solana-validator-failover/internal/utils"
+)
+
+const (
+	// DoctorStatusPass is a check that found nothing wrong
+	DoctorStatusPass = "pass"
+	// DoctorStatusWarn is a check that found something that won't stop a failover but may slow or surprise it
+	DoctorStatusWarn = "warn"
+	// DoctorStatusFail is a check that found something that will stop a failover
+	DoctorStatusFail = "fail"
+
+	// adminRPCSocketName is the socket agave's admin rpc listens on in the ledger dir - set-identity goes through it
+	adminRPCSocketName = "admin.rpc"
+
+	// adminRPCDialTimeout is how long connecting to the admin rpc socket may take
+	adminRPCDialTimeout = 2 * time.Second
+)
+
+// DoctorCheck is the outcome of one preflight check
+type DoctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+}
+
+// DoctorReport is the outcome of every preflight check, in the order they ran
+type DoctorReport struct {
+	Checks []DoctorCheck `json:"checks"`
+}
+
+// add appends a check to the report
+func (r *DoctorReport) add(name, status, detail string) {
+	r.Checks = append(r.Checks, DoctorCheck{Name: name, Status: status, Detail: detail})
+}
+
+// addErr appends a check that passes with detail when err is nil and has status with the error otherwise
+func (r *DoctorReport) addErr(name string, err error, status, detail string) {
+	if err != nil {
+		r.add(name, status, err.Error())
+		return
+	}
+	r.add(name, DoctorStatusPass, detail)
+}
+
+// HasFailures returns true if any check failed
+func (r DoctorReport) HasFailures() bool {
+	for _, check := range r.Checks {
+		if check.Status == DoctorStatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// Counts returns how many checks passed, warned and failed
+func (r DoctorReport) Counts() (passed, warned, failed int) {
+	for _, check := range r.Checks {
+		switch check.Status {
+		case DoctorStatusPass:
+			passed++
+		case DoctorStatusWarn:
+			warned++
+		case DoctorStatusFail:
+			failed++
+		}
+	}
+	return passed, warned, failed
+}
+
+// TableString renders the report as a table of checks followed by a summary line
+func (r DoctorReport) TableString() string {
+	rows := make([][]string, 0, len(r.Checks))
+	for _, check := range r.Checks {
+		rows = append(rows, []string{check.Name, check.Status, check.Detail})
+	}
+
+	passed, warned, failed := r.Counts()
+	return style.RenderTable(
+		[]string{"Check", "Status", "Detail"},
+		rows,
+		func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return style.TableHeaderStyle
+			}
+			cellStyle := style.TableCellStyle.Align(lipgloss.Left)
+			if col != 1 {
+				return cellStyle
+			}
+			switch r.Checks[row].Status {
+			case DoctorStatusPass:
+				return cellStyle.Foreground(style.ColorActive)
+			case DoctorStatusWarn:
+				return cellStyle.Foreground(style.ColorWarning)
+			default:
+				return cellStyle.Foreground(style.ColorErrorValue)
+			}
+		},
+	) + fmt.Sprintf("\n%d passed, %d warned, %d failed", passed, warned, failed)
+}
+
+// NewConfigDoctorReport returns a report of a config that failed to validate - the deeper checks need a valid config
+// so none of them run
+func NewConfigDoctorReport(err error) DoctorReport {
+	report := DoctorReport{}
+	report.add("Configuration", DoctorStatusFail, err.Error())
+	return report
+}
+
+// Doctor runs the preflight checks a failover depends on and reports each as pass, warn or fail - it changes nothing
+// on this node or its peers. The config has already been validated by NewFromConfig
+func (v *Validator) Doctor() DoctorReport {
+	report := DoctorReport{}
+	report.add("Configuration", DoctorStatusPass, "valid")
+
+	// gossip first - the role and gossip version feed the checks after it
+	gossipErr := v.GossipNode.Refresh(v.solanaRPCClient)
+	if gossipErr == nil {
+		gossipErr = v.checkGossipIdentity()
+	}
+	role := constants.NodeRoleUnknown
+	switch {
+	case gossipErr != nil:
+	case v.IsActive():
+		role = constants.NodeRoleActive
+	case v.IsPassive():
+		role = constants.NodeRolePassive
+	}
+	report.addErr(
+		"Gossip",
+		gossipErr,
+		DoctorStatusFail,
+		fmt.Sprintf("%s as %s (%s)", v.PublicIP, v.GossipNode.PubKey(), role),
+	)
+
+	binStatus, binDetail := doctorBin(v.BinMetadata, v.GossipNode.Version())
+	report.add("Validator binary", binStatus, binDetail)
+
+	health, err := v.solanaRPCClient.GetLocalNodeHealth()
+	if err == nil && health != rpc.HealthOk {
+		err = fmt.Errorf("local node health is %s", health)
+	}
+	report.addErr("Local RPC", err, DoctorStatusWarn, health)
+
+	adminStatus, adminDetail := v.doctorAdminRPC()
+	report.add("Admin RPC", adminStatus, adminDetail)
+
+	towerDir := filepath.Dir(v.TowerFile)
+	report.addErr("Tower dir writable", checkDirWritable(towerDir), DoctorStatusFail, towerDir)
+
+	for _, identity := range []struct {
+		name     string
+		identity *identities.Identity
+	}{
+		{"Active keyfile", v.Identities.Active},
+		{"Passive keyfile", v.Identities.Passive},
+	} {
+		if identity.identity.IsRemote() {
+			report.add(identity.name, DoctorStatusPass, "held by remote signer")
+			continue
+		}
+		status, detail := doctorKeyFilePermissions(identity.identity.KeyFile)
+		if identity.identity.Encrypted {
+			detail += " - encrypted"
+		}
+		report.add(identity.name, status, detail)
+	}
+
+	publicIPDetail := v.PublicIP
+	publicIPStatus := DoctorStatusPass
+	if v.publicIPFromConfig {
+		publicIPStatus = DoctorStatusWarn
+		publicIPDetail = fmt.Sprintf("%s - set in config rather than resolved", v.PublicIP)
+	}
+	report.add("Public IP", publicIPStatus, publicIPDetail)
+
+	clockStatus, clockDetail := v.doctorClock()
+	report.add("Clock offset", clockStatus, clockDetail)
+
+	portStatus, portDetail := doctorServerPort(v.FailoverServerConfig)
+	report.add("Failover port bindable", portStatus, portDetail)
+
+	for _, peer := range v.doctorPeers() {
+		report.add(peer.name, peer.status, peer.detail)
+	}
+
+	if v.Agent.ShareConfig {
+		names := make([]string, 0, len(v.Peers))
+		for name := range v.Peers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			driftStatus, driftDetail := doctorConfigDrift(v.ConfigDiff(name))
+			report.add(fmt.Sprintf("Peer %s config drift", name), driftStatus, driftDetail)
+		}
+	}
+
+	return report
+}
+
+// doctorConfigDrift checks a peer's config matches this node's where a failover depends on them agreeing - drift or
+// not being able to compare only warns as the peer's agent may not be running or sharing its config
+func doctorConfigDrift(peerHostname string, differences []failover.ConfigDifference, err error) (status, detail string) {
+	if err != nil {
+		return DoctorStatusWarn, fmt.Sprintf("not compared - %s", err)
+	}
+	if len(differences) == 0 {
+		return DoctorStatusPass, fmt.Sprintf("matches %s", peerHostname)
+	}
+	keys := make([]string, 0, len(differences))
+	for _, difference := range differences {
+		keys = append(keys, difference.Key)
+	}
+	return DoctorStatusWarn, fmt.Sprintf("%d difference(s) with %s: %s - run config diff for values", len(differences), peerHostname, strings.Join(keys, ", "))
+}
+
+// doctorServerPort checks this process can bind the failover server port - falling back to an ephemeral port only
+// warns as the active node must then be told the port
+func doctorServerPort(cfg ServerConfig) (status, detail string) {
+	err := ports.CheckBindable(cfg.Port)
+	switch {
+	case err == nil:
+		return DoctorStatusPass, strconv.Itoa(cfg.Port)
+	case cfg.EphemeralPortFallback:
+		return DoctorStatusWarn, fmt.Sprintf("%s - an ephemeral port is used instead, connect with --via-agent", err)
+	}
+	return DoctorStatusFail, err.Error()
+}
+
+// doctorPeerCheck is the outcome of probing one port on a peer
+type doctorPeerCheck struct {
+	name   string
+	status string
+	detail string
+}
+
+// doctorPeers probes every peer's failover port, and its agent port when this node is passive, in parallel. A
+// failover server only listens while its node waits for a failover and an agent is optional, so neither answering
+// is only a warning - a peer address that doesn't resolve fails
+func (v *Validator) doctorPeers() []doctorPeerCheck {
+	agentPort := v.Agent.Port
+	if agentPort == 0 {
+		agentPort = failover.DefaultAgentPort
+	}
+	probeAgent := v.IsPassive()
+
+	names := make([]string, 0, len(v.Peers))
+	for name := range v.Peers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := make([]chan doctorPeerCheck, 0, 2*len(names))
+	probe := func(name, address, notAnswering string, probeFunc func(address string) error) {
+		result := make(chan doctorPeerCheck, 1)
+		results = append(results, result)
+		go func() {
+			check := doctorPeerCheck{name: name, status: DoctorStatusPass, detail: fmt.Sprintf("%s answering", address)}
+			if _, err := net.ResolveUDPAddr("udp", address); err != nil {
+				check.status, check.detail = DoctorStatusFail, err.Error()
+			} else if err := probeFunc(address); err != nil {
+				check.status, check.detail = DoctorStatusWarn, fmt.Sprintf("%s not answering - %s", address, notAnswering)
+			}
+			result <- check
+		}()
+	}
+	for _, name := range names {
+		peer := v.Peers[name]
+		probe(
+			fmt.Sprintf("Peer %s failover port", name),
+			peer.Address,
+			"normal unless it is waiting for a failover",
+			v.peerProbe,
+		)
+		if probeAgent {
+			probe(
+				fmt.Sprintf("Peer %s agent", name),
+				net.JoinHostPort(utils.HostFromAddress(peer.Address), strconv.Itoa(agentPort)),
+				"run --via-agent and watch need the agent running on the active peer",
+				func(address string) error { return failover.ProbeAgent(address, v.ClientCertificate) },
+			)
+		}
+	}
+
+	checks := make([]doctorPeerCheck, 0, len(results))
+	for _, result := range results {
+		checks = append(checks, <-result)
+	}
+	return checks
+}
+
+// doctorBin checks a version was detected from the validator binary and that it matches the version in gossip
+func doctorBin(metadata BinMetadata, gossipVersion string) (status, detail string) {
+	if metadata.Version == "" {
+		return DoctorStatusWarn, fmt.Sprintf("%s - version not detected from --version", metadata.Client)
+	}
+	detail = fmt.Sprintf("%s %s", metadata.Client, metadata.Version)
+	if gossipVersion != "" && gossipVersion != metadata.Version {
+		return DoctorStatusWarn, fmt.Sprintf("%s differs from %s in gossip - restart the validator to run it", detail, gossipVersion)
+	}
+	return DoctorStatusPass, detail
+}
+
+// doctorAdminRPC checks agave's admin rpc socket in the ledger dir accepts connections - firedancer doesn't use it
+func (v *Validator) doctorAdminRPC() (status, detail string) {
+	if v.BinMetadata.Client == constants.ClientTypeFiredancer {
+		return DoctorStatusPass, "not used by firedancer"
+	}
+	socket := filepath.Join(v.LedgerDir, adminRPCSocketName)
+	conn, err := net.DialTimeout("unix", socket, adminRPCDialTimeout)
+	if err != nil {
+		return DoctorStatusFail, fmt.Sprintf("failed to connect to %s: %s", socket, err)
+	}
+	conn.Close()
+	return DoctorStatusPass, socket
+}
+
+// doctorClock checks the local clock offset with the configured clock check - without one it is only a warning
+func (v *Validator) doctorClock() (status, detail string) {
+	if v.clockOffset == nil {
+		return DoctorStatusWarn, "failover.clock_check not enabled"
+	}
+	offset, err := v.clockOffset()
+	if err != nil {
+		return DoctorStatusWarn, fmt.Sprintf("failed to read offset from %s: %s", v.ClockCheck.Source, err)
+	}
+	detail = fmt.Sprintf("%s from %s", offset, v.ClockCheck.Source)
+	if offset.Abs() > v.clockCheckMaxOffset {
+		return DoctorStatusFail, fmt.Sprintf("%s - more than failover.clock_check.max_offset %s", detail, v.clockCheckMaxOffset)
+	}
+	return DoctorStatusPass, detail
+}
+
+// doctorKeyFilePermissions checks a keyfile can only be read by its owner
+func doctorKeyFilePermissions(keyFile string) (status, detail string) {
+	info, err := os.Stat(keyFile)
+	if err != nil {
+		return DoctorStatusFail, fmt.Sprintf("failed to stat %s: %s", keyFile, err)
+	}
+	mode := info.Mode().Perm()
+	if mode&0o077 != 0 {
+		return DoctorStatusWarn, fmt.Sprintf("%s is %04o - readable by others, should be 0600", keyFile, mode)
+	}
+	return DoctorStatusPass, fmt.Sprintf("%s is %04o", keyFile, mode)
+}
+
+// checkDirWritable ensures a file can be created in dir by creating and removing one
+func checkDirWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".solana-validator-failover-doctor-*")
+	if err != nil {
+		return fmt.Errorf("%s is not writable: %w", dir, err)
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
+}
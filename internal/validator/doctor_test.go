@@ -0,0 +1,167 @@
+package validator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-failover/internal/clock"
+	"github.com/sol-strategies/solana-validator-failover/internal/constants"
+	"github.com/sol-strategies/solana-validator-failover/internal/failover"
+	"github.com/sol-strategies/solana-validator-failover/internal/identities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoctorBin(t *testing.T) {
+	status, detail := doctorBin(BinMetadata{Client: constants.ClientTypeAgave, Version: "2.2.14"}, "2.2.14")
+	assert.Equal(t, DoctorStatusPass, status)
+	assert.Equal(t, "agave 2.2.14", detail)
+
+	status, detail = doctorBin(BinMetadata{Client: constants.ClientTypeAgave, Version: "2.2.15"}, "2.2.14")
+	assert.Equal(t, DoctorStatusWarn, status)
+	assert.Contains(t, detail, "differs from 2.2.14 in gossip")
+
+	status, _ = doctorBin(BinMetadata{Client: constants.ClientTypeUnknown}, "2.2.14")
+	assert.Equal(t, DoctorStatusWarn, status)
+}
+
+func TestDoctorKeyFilePermissions(t *testing.T) {
+	dir := t.TempDir()
+	private := filepath.Join(dir, "private.json")
+	require.NoError(t, os.WriteFile(private, []byte("[]"), 0600))
+	shared := filepath.Join(dir, "shared.json")
+	require.NoError(t, os.WriteFile(shared, []byte("[]"), 0600))
+	require.NoError(t, os.Chmod(shared, 0644))
+
+	status, _ := doctorKeyFilePermissions(private)
+	assert.Equal(t, DoctorStatusPass, status)
+
+	status, detail := doctorKeyFilePermissions(shared)
+	assert.Equal(t, DoctorStatusWarn, status)
+	assert.Contains(t, detail, "0644")
+
+	status, _ = doctorKeyFilePermissions(filepath.Join(dir, "missing.json"))
+	assert.Equal(t, DoctorStatusFail, status)
+}
+
+func TestCheckDirWritable(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, checkDirWritable(dir))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	assert.Error(t, checkDirWritable(filepath.Join(dir, "missing")))
+}
+
+func TestDoctorAdminRPC_Firedancer(t *testing.T) {
+	validator := createTestValidator(t)
+	validator.BinMetadata = BinMetadata{Client: constants.ClientTypeFiredancer}
+
+	status, _ := validator.doctorAdminRPC()
+	assert.Equal(t, DoctorStatusPass, status)
+}
+
+func TestDoctorAdminRPC_NoSocket(t *testing.T) {
+	validator := createTestValidator(t)
+	validator.BinMetadata = BinMetadata{Client: constants.ClientTypeAgave}
+	validator.LedgerDir = t.TempDir()
+
+	status, detail := validator.doctorAdminRPC()
+	assert.Equal(t, DoctorStatusFail, status)
+	assert.Contains(t, detail, adminRPCSocketName)
+}
+
+func TestDoctorClock(t *testing.T) {
+	validator := createTestValidator(t)
+
+	status, detail := validator.doctorClock()
+	assert.Equal(t, DoctorStatusWarn, status)
+	assert.Contains(t, detail, "not enabled")
+
+	validator.ClockCheck = ClockCheckConfig{Source: clock.SourceNTP}
+	validator.clockCheckMaxOffset = 100 * time.Millisecond
+	validator.clockOffset = func() (time.Duration, error) { return 20 * time.Millisecond, nil }
+	status, _ = validator.doctorClock()
+	assert.Equal(t, DoctorStatusPass, status)
+
+	validator.clockOffset = func() (time.Duration, error) { return -300 * time.Millisecond, nil }
+	status, detail = validator.doctorClock()
+	assert.Equal(t, DoctorStatusFail, status)
+	assert.Contains(t, detail, "max_offset")
+
+	validator.clockOffset = func() (time.Duration, error) { return 0, errors.New("timeout") }
+	status, _ = validator.doctorClock()
+	assert.Equal(t, DoctorStatusWarn, status)
+}
+
+func TestDoctorServerPort(t *testing.T) {
+	status, detail := doctorServerPort(ServerConfig{Port: 9898})
+	assert.Equal(t, DoctorStatusPass, status)
+	assert.Equal(t, "9898", detail)
+}
+
+func TestDoctorConfigDrift(t *testing.T) {
+	status, detail := doctorConfigDrift("backup", nil, nil)
+	assert.Equal(t, DoctorStatusPass, status)
+	assert.Equal(t, "matches backup", detail)
+
+	status, detail = doctorConfigDrift("backup", []failover.ConfigDifference{
+		{Key: "failover.switch_countdown", Local: "5s", Peer: "10s"},
+		{Key: "tower.auto_empty_when_passive", Local: "true", Peer: "false"},
+	}, nil)
+	assert.Equal(t, DoctorStatusWarn, status)
+	assert.Contains(t, detail, "2 difference(s) with backup: failover.switch_countdown, tower.auto_empty_when_passive")
+
+	status, detail = doctorConfigDrift("", nil, errors.New("config sharing is disabled"))
+	assert.Equal(t, DoctorStatusWarn, status)
+	assert.Contains(t, detail, "not compared - config sharing is disabled")
+}
+
+func TestDoctorReport(t *testing.T) {
+	report := NewConfigDoctorReport(errors.New("must have at least one peer"))
+	assert.True(t, report.HasFailures())
+	assert.Contains(t, report.TableString(), "must have at least one peer")
+
+	report = DoctorReport{}
+	report.add("Gossip", DoctorStatusPass, "ok")
+	report.addErr("Local RPC", errors.New("behind"), DoctorStatusWarn, "ok")
+	assert.False(t, report.HasFailures())
+	passed, warned, failed := report.Counts()
+	assert.Equal(t, 1, passed)
+	assert.Equal(t, 1, warned)
+	assert.Equal(t, 0, failed)
+	assert.Contains(t, report.TableString(), "1 passed, 1 warned, 0 failed")
+}
+
+func TestValidator_Doctor(t *testing.T) {
+	validator := newStatusTestValidator(t, true)
+	validator.BinMetadata = BinMetadata{Client: constants.ClientTypeFiredancer, Version: "1.16.0"}
+	keyDir := t.TempDir()
+	for _, identity := range []*identities.Identity{validator.Identities.Active, validator.Identities.Passive} {
+		identity.KeyFile = filepath.Join(keyDir, identity.PubKey()+".json")
+		require.NoError(t, os.WriteFile(identity.KeyFile, []byte("[]"), 0600))
+	}
+
+	report := validator.Doctor()
+
+	statuses := map[string]string{}
+	for _, check := range report.Checks {
+		statuses[check.Name] = check.Status
+	}
+	assert.Equal(t, DoctorStatusPass, statuses["Configuration"])
+	assert.Equal(t, DoctorStatusPass, statuses["Gossip"])
+	assert.Equal(t, DoctorStatusPass, statuses["Validator binary"])
+	assert.Equal(t, DoctorStatusPass, statuses["Tower dir writable"])
+	assert.Equal(t, DoctorStatusPass, statuses["Active keyfile"])
+	assert.Equal(t, DoctorStatusWarn, statuses["Clock offset"])
+	assert.Equal(t, DoctorStatusPass, statuses["Peer backup-1 failover port"])
+	// a failover port only answers during a failover
+	assert.Equal(t, DoctorStatusWarn, statuses["Peer backup-2 failover port"])
+	// agents are only probed from a passive node
+	assert.NotContains(t, statuses, "Peer backup-1 agent")
+	assert.False(t, report.HasFailures())
+}
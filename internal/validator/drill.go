@@ -0,0 +1,147 @@
+package validator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/audit"
+	"github.com/sol-strategies/solana-validator-failover/internal/constants"
+	"github.com/sol-strategies/solana-validator-failover/internal/events"
+	"github.com/sol-strategies/solana-validator-failover/internal/notify"
+	"github.com/sol-strategies/solana-validator-failover/internal/utils"
+)
+
+// nextDrillAt returns when the next scheduled drill after now is due - zero when drills aren't scheduled
+func (v *Validator) nextDrillAt(now time.Time) time.Time {
+	if v.DrillSchedule == nil {
+		return time.Time{}
+	}
+	return v.DrillSchedule.Next(now.UTC())
+}
+
+// untilNextCheck returns how long the watch daemon sleeps before its next check - cut short when a drill is due first
+func (v *Validator) untilNextCheck(nextDrillAt time.Time) time.Duration {
+	if nextDrillAt.IsZero() {
+		return v.watchInterval
+	}
+	return max(0, min(v.watchInterval, time.Until(nextDrillAt)))
+}
+
+// runScheduledDrill runs a scheduled dry-run drill from the passive node, asking the active peer's agent to hand over
+// - the active node has nothing to do. A failed drill is recorded in the audit log when it failed before the
+// failover recorded itself, and alerted on through events and notifications
+func (v *Validator) runScheduledDrill(failover func(FailoverParams) error) {
+	if !v.IsPassive() {
+		log.Debug().Msg("this node is not passive - the passive node runs scheduled drills")
+		return
+	}
+
+	log.Info().Str("peer", v.drillPeer().Name).Msg("Running scheduled drill")
+	startTime := time.Now()
+	err := failover(v.DrillFailoverParams())
+	if err == nil {
+		log.Info().Dur("duration", time.Since(startTime)).Msg("🟢 Scheduled drill complete")
+		return
+	}
+
+	log.Error().Err(err).Dur("duration", time.Since(startTime)).Msg("🔴 Scheduled drill failed")
+	v.recordFailedDrill(startTime, err)
+	v.alertFailedDrill(err)
+}
+
+// drillPeer returns the peer scheduled drills are run with - the only peer when failover.drill.peer isn't set
+func (v *Validator) drillPeer() Peer {
+	if peer, ok := v.Peers[v.Drill.Peer]; ok {
+		return peer
+	}
+	for _, peer := range v.Peers {
+		return peer
+	}
+	return Peer{}
+}
+
+// drillNodes returns the nodes a drill fails over between as the audit log and alerts show them - the drill peer
+// active and this node passive
+func (v *Validator) drillNodes() (active, passive audit.Node) {
+	peer := v.drillPeer()
+	active = audit.Node{Hostname: peer.Name, PublicIP: utils.HostFromAddress(peer.Address), Pubkey: v.Identities.Active.PubKey()}
+	passive = audit.Node{Hostname: v.Hostname, PublicIP: v.PublicIP, Pubkey: v.Identities.Passive.PubKey()}
+	return active, passive
+}
+
+// recordFailedDrill appends a record of a drill that failed to the audit log unless the failover already wrote one -
+// it only does once the peers have connected, so a drill the active node never joined would otherwise go unrecorded
+func (v *Validator) recordFailedDrill(startTime time.Time, drillErr error) {
+	if v.AuditLog == nil {
+		return
+	}
+	records, err := v.AuditLog.Read()
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to read audit log - recording the failed drill anyway")
+	}
+	for _, record := range records {
+		if !record.Time.Before(startTime) {
+			return
+		}
+	}
+
+	active, passive := v.drillNodes()
+	err = v.AuditLog.Append(audit.Record{
+		Hostname:     v.Hostname,
+		Role:         constants.NodeRolePassive,
+		IsDryRun:     true,
+		ErrorMessage: fmt.Sprintf("scheduled drill failed: %v", drillErr),
+		ActiveNode:   active,
+		PassiveNode:  passive,
+		Peer:         v.drillPeer().Name,
+		DurationMs:   time.Since(startTime).Milliseconds(),
+	})
+	if err != nil {
+		log.Error().Err(err).Str("audit_log", v.AuditLog.Path()).Msg("failed to record failed drill in audit log")
+	}
+}
+
+// alertFailedDrill publishes a drill_failure event and sends a drill_failure notification, waiting for both to be sent
+func (v *Validator) alertFailedDrill(drillErr error) {
+	active, passive := v.drillNodes()
+
+	publisher, err := events.NewPublisher(v.Events)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to create event publisher - drill failure not published")
+	} else {
+		publisher.Publish(events.Event{
+			Type:        events.TypeDrillFailure,
+			IsDryRun:    true,
+			ActiveNode:  events.Node(active),
+			PassiveNode: events.Node(passive),
+			Reason:      drillErr.Error(),
+		})
+		publisher.Wait()
+	}
+
+	notifier, err := notify.NewNotifier(v.Notifications)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to create notifier - drill failure not notified")
+		return
+	}
+	notifier.Notify(notify.Notification{
+		Type:        notify.TypeDrillFailure,
+		Hostname:    v.Hostname,
+		IsDryRun:    true,
+		ActiveNode:  notify.Node(active),
+		PassiveNode: notify.Node(passive),
+		Reason:      drillErr.Error(),
+	})
+	notifier.Wait()
+}
+
+// RunDrills runs scheduled drills like watch does without watching the active identity - for the deprecated drill
+// command
+func (v *Validator) RunDrills() error {
+	if v.DrillSchedule == nil {
+		return fmt.Errorf("failover.drill.schedule is not set - nothing to schedule")
+	}
+	v.Watch.Conditions = WatchConditionsConfig{}
+	return v.RunWatch()
+}
@@ -0,0 +1,59 @@
+package validator
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/audit"
+	"github.com/sol-strategies/solana-validator-failover/internal/constants"
+)
+
+// Failback reverses the most recent real failover in this node's audit log with the same peer - restoring whichever
+// node was active before it, e.g. once maintenance on it is done
+func (v *Validator) Failback(params FailoverParams) (err error) {
+	if v.AuditLog == nil {
+		return fmt.Errorf("failback needs the failover to fail back in the audit log - enable failover.audit")
+	}
+
+	records, err := v.AuditLog.Read()
+	if err != nil {
+		// fail back from the records that could be read
+		log.Warn().Err(err).Msg("some audit records could not be read")
+	}
+	record, ok := audit.LastRealFailover(records)
+	if !ok {
+		return fmt.Errorf("no completed real failover in %s to fail back", v.AuditLog.Path())
+	}
+
+	peerName, err := failbackPeer(record, v.IsActive())
+	if err != nil {
+		return err
+	}
+	if params.PeerName == "" {
+		params.PeerName = peerName
+	}
+
+	log.Info().
+		Str("failover_id", record.FailoverID).
+		Time("failover_time", record.Time).
+		Str("peer", params.PeerName).
+		Msgf("Failing back failover %s so %s is active again", record.FailoverID, record.ActiveNode.Hostname)
+	return v.Failover(params)
+}
+
+// failbackPeer returns the peer to fail back record with given whether this node is active now - it must hold the
+// opposite role to the one it held before the failover or there's nothing to fail back. The peer is empty when the
+// other node wasn't a configured one, leaving it to be selected as for run
+func failbackPeer(record audit.Record, isActive bool) (peerName string, err error) {
+	wasActive := record.Role == constants.NodeRoleActive
+	if wasActive == isActive {
+		return "", fmt.Errorf(
+			"%s is %s again since failover %s at %s - nothing to fail back",
+			record.Hostname,
+			record.Role,
+			record.FailoverID,
+			record.Time.Format("2006-01-02 15:04:05 MST"),
+		)
+	}
+	return record.Peer, nil
+}
@@ -0,0 +1,51 @@
+package validator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sol-strategies/solana-validator-failover/internal/audit"
+	"github.com/sol-strategies/solana-validator-failover/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailbackPeer(t *testing.T) {
+	record := audit.Record{
+		Time:       time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC),
+		FailoverID: "abc123",
+		Hostname:   "node-a",
+		Role:       constants.NodeRoleActive,
+		Peer:       "node-b",
+	}
+
+	// node-a was active so is passive after the failover and makes itself active again
+	peerName, err := failbackPeer(record, false)
+	require.NoError(t, err)
+	assert.Equal(t, "node-b", peerName)
+
+	_, err = failbackPeer(record, true)
+	assert.ErrorContains(t, err, "node-a is active again since failover abc123")
+
+	record.Role = constants.NodeRolePassive
+	peerName, err = failbackPeer(record, true)
+	require.NoError(t, err)
+	assert.Equal(t, "node-b", peerName)
+}
+
+func TestFailback_NoAuditLog(t *testing.T) {
+	validator := createTestValidator(t)
+	validator.AuditLog = nil
+
+	err := validator.Failback(FailoverParams{})
+	assert.ErrorContains(t, err, "enable failover.audit")
+}
+
+func TestFailback_NoRealFailover(t *testing.T) {
+	validator := createTestValidator(t)
+	validator.AuditLog = audit.NewLog(t.TempDir())
+	require.NoError(t, validator.AuditLog.Append(audit.Record{FailoverID: "drill", IsDryRun: true, Success: true}))
+
+	err := validator.Failback(FailoverParams{})
+	assert.ErrorContains(t, err, "no completed real failover")
+}
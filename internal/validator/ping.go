@@ -0,0 +1,176 @@
+package validator
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/sol-strategies/solana-validator-failover/internal/failover"
+	"github.com/sol-strategies/solana-validator-failover/internal/style"
+	"github.com/sol-strategies/solana-validator-failover/internal/utils"
+	pkgconstants "github.com/sol-strategies/solana-validator-failover/pkg/constants"
+)
+
+const (
+	// PingEndpointFailoverServer is the failover server a peer runs while it waits for a failover
+	PingEndpointFailoverServer = "failover server"
+	// PingEndpointAgent is the agent a peer runs as a long-lived service
+	PingEndpointAgent = "agent"
+	// pingFingerprintLength is how many hex characters of a certificate fingerprint are shown
+	pingFingerprintLength = 16
+)
+
+// PeerPing is the outcome of pinging one of a peer's endpoints - Error is empty when it answered the hello
+type PeerPing struct {
+	Peer     string              `json:"peer"`
+	Endpoint string              `json:"endpoint"`
+	Address  string              `json:"address"`
+	Result   failover.PingResult `json:"result"`
+	Error    string              `json:"error,omitempty"`
+}
+
+// PeerPings are the outcomes of pinging peers, by peer name then endpoint
+type PeerPings []PeerPing
+
+// PingPeers pings every peer's failover server and agent in parallel, or only peerName's when set - a failover server
+// only listens while its node waits for a failover and an agent is optional, so one endpoint not answering is normal
+func (v *Validator) PingPeers(peerName string) (pings PeerPings, err error) {
+	names := make([]string, 0, len(v.Peers))
+	for name := range v.Peers {
+		names = append(names, name)
+	}
+	if peerName != "" {
+		if _, ok := v.Peers[peerName]; !ok {
+			return nil, fmt.Errorf("peer %s not found in failover.peers", peerName)
+		}
+		names = []string{peerName}
+	}
+	sort.Strings(names)
+
+	agentPort := v.Agent.Port
+	if agentPort == 0 {
+		agentPort = failover.DefaultAgentPort
+	}
+
+	results := make([]chan PeerPing, 0, 2*len(names))
+	ping := func(name, endpoint, address, protocol string) {
+		result := make(chan PeerPing, 1)
+		results = append(results, result)
+		go func() {
+			peerPing := PeerPing{Peer: name, Endpoint: endpoint, Address: address}
+			pingResult, err := failover.Ping(failover.PingParams{
+				PeerName:          name,
+				Address:           address,
+				Protocol:          protocol,
+				Hostname:          v.Hostname,
+				PublicIP:          v.PublicIP,
+				PeerPins:          v.PeerPins,
+				ClientCertificate: v.ClientCertificate,
+			})
+			peerPing.Result = pingResult
+			if err != nil {
+				peerPing.Error = err.Error()
+			}
+			result <- peerPing
+		}()
+	}
+	for _, name := range names {
+		peer := v.Peers[name]
+		ping(name, PingEndpointFailoverServer, peer.Address, failover.ProtocolName)
+		ping(name, PingEndpointAgent, net.JoinHostPort(utils.HostFromAddress(peer.Address), strconv.Itoa(agentPort)), failover.AgentProtocolName)
+	}
+
+	pings = make(PeerPings, 0, len(results))
+	for _, result := range results {
+		pings = append(pings, <-result)
+	}
+	return pings, nil
+}
+
+// Unreachable returns the peers none of whose endpoints answered the hello
+func (p PeerPings) Unreachable() (peerNames []string) {
+	answered := make(map[string]bool)
+	for _, ping := range p {
+		answered[ping.Peer] = answered[ping.Peer] || ping.Error == ""
+	}
+	for _, ping := range p {
+		if !answered[ping.Peer] {
+			peerNames = append(peerNames, ping.Peer)
+			answered[ping.Peer] = true
+		}
+	}
+	return peerNames
+}
+
+// TableString returns the pings as a table - endpoints that didn't answer and versions whose protocol can't talk to
+// this node's are highlighted
+func (p PeerPings) TableString() string {
+	rows := make([][]string, 0, len(p))
+	warningCells := make(map[[2]int]bool)
+	for i, ping := range p {
+		result := ping.Result
+		handshake, hello, certificate := "-", "-", "-"
+		if result.HandshakeRTT > 0 {
+			handshake = fmt.Sprintf("%s %s/%s/%s", result.HandshakeRTT.Round(time.Millisecond), result.ALPN, result.QUICVersion, result.TLSVersion)
+		}
+		if result.HelloRTT > 0 {
+			hello = result.HelloRTT.Round(time.Millisecond).String()
+		}
+		if result.CertificateFingerprint != "" {
+			pinned := "not pinned"
+			if result.CertificatePinned {
+				pinned = "pinned"
+			}
+			certificate = fmt.Sprintf(
+				"%s %s, expires %s",
+				result.CertificateFingerprint[:pingFingerprintLength],
+				pinned,
+				result.CertificateNotAfter.Format("2006-01-02"),
+			)
+		}
+
+		version := result.Version
+		switch {
+		case result.Incompatible != "":
+			version = fmt.Sprintf("%s - incompatible: %s", version, result.Incompatible)
+			warningCells[[2]int{i, 5}] = true
+		case version != "" && version != pkgconstants.AppVersion:
+			version = fmt.Sprintf("%s - compatible with this node's %s", version, pkgconstants.AppVersion)
+		}
+
+		status := "ok"
+		if ping.Error != "" {
+			status = ping.Error
+			warningCells[[2]int{i, 6}] = true
+		}
+
+		rows = append(rows, []string{
+			ping.Peer,
+			fmt.Sprintf("%s %s", ping.Endpoint, ping.Address),
+			handshake,
+			hello,
+			certificate,
+			version,
+			status,
+		})
+	}
+
+	return style.RenderTable(
+		[]string{"Peer", "Endpoint", "Handshake", "Hello", "Certificate", "Version", "Status"},
+		rows,
+		func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return style.TableHeaderStyle
+			}
+			cellStyle := style.TableCellStyle.Align(lipgloss.Left)
+			if warningCells[[2]int{row, col}] {
+				return cellStyle.Foreground(style.ColorWarning)
+			}
+			return cellStyle
+		},
+	)
+}
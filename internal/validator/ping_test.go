@@ -0,0 +1,42 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/sol-strategies/solana-validator-failover/internal/failover"
+	pkgconstants "github.com/sol-strategies/solana-validator-failover/pkg/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeerPings_Unreachable(t *testing.T) {
+	pings := PeerPings{
+		{Peer: "node-a", Endpoint: PingEndpointFailoverServer, Error: "failed to connect"},
+		{Peer: "node-a", Endpoint: PingEndpointAgent},
+		{Peer: "node-b", Endpoint: PingEndpointFailoverServer, Error: "failed to connect"},
+		{Peer: "node-b", Endpoint: PingEndpointAgent, Error: "hello refused: requester is not a configured peer"},
+	}
+
+	assert.Equal(t, []string{"node-b"}, pings.Unreachable())
+	assert.Empty(t, pings[:2].Unreachable())
+}
+
+func TestPingPeers_UnknownPeer(t *testing.T) {
+	validator := createTestValidator(t)
+	validator.Peers = Peers{"node-a": {Name: "node-a", Address: "10.0.0.1:9898"}}
+
+	_, err := validator.PingPeers("node-z")
+
+	assert.EqualError(t, err, "peer node-z not found in failover.peers")
+}
+
+func TestPeerPings_TableString_Versions(t *testing.T) {
+	pings := PeerPings{
+		{Peer: "node-a", Endpoint: PingEndpointAgent, Result: failover.PingResult{Version: "v0.0.1-other", ProtocolRevision: failover.ProtocolRevision}},
+		{Peer: "node-b", Endpoint: PingEndpointAgent, Result: failover.PingResult{Version: "v0.0.0-old", Incompatible: "peer runs v0.0.0-old, which predates protocol negotiation"}},
+	}
+
+	table := pings.TableString()
+
+	assert.Contains(t, table, "compatible with this node's "+pkgconstants.AppVersion)
+	assert.Contains(t, table, "incompatible: peer runs v0.0.0-old")
+}
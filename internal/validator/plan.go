@@ -0,0 +1,133 @@
+package validator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/sol-strategies/solana-validator-failover/internal/solana"
+	"github.com/sol-strategies/solana-validator-failover/internal/style"
+)
+
+const (
+	// planTimeFormat is how failover window times are shown - local time so operators can line them up with their day
+	planTimeFormat = "Mon 15:04:05 MST"
+	// nextLeaderWindowMargin is how long before the latest moment a failover window allows a switch scheduled
+	// before the next leader window happens - slot times are estimates so the switch isn't cut any finer
+	nextLeaderWindowMargin = 30 * time.Second
+	// nextLeaderWindowSearch is how many failover windows are looked through for one to schedule the switch in
+	nextLeaderWindowSearch = 3
+)
+
+// FailoverWindow is a gap between the active identity's leader slots long enough to fail over in - a failover run
+// from StartAfter until StartBefore has no leader slot within min_time_to_leader_slot
+type FailoverWindow struct {
+	Gap         solana.LeaderGap
+	StartAfter  time.Time
+	StartBefore time.Time
+}
+
+// FailoverPlan is the next windows to fail over in, soonest first
+type FailoverPlan struct {
+	Pubkey              string
+	MinTimeToLeaderSlot time.Duration
+	Windows             []FailoverWindow
+}
+
+// PlanFailoverWindows returns the next count gaps between the active identity's leader slots this epoch that are
+// longer than min_time_to_leader_slot - fewer when the epoch ends first
+func (v *Validator) PlanFailoverWindows(count int) (plan FailoverPlan, err error) {
+	plan = FailoverPlan{
+		Pubkey:              v.Identities.Active.PubKey(),
+		MinTimeToLeaderSlot: v.MinimumTimeToLeaderSlot,
+	}
+
+	gaps, err := v.solanaRPCClient.GetLeaderGaps(v.Identities.Active.PublicKey())
+	if err != nil {
+		return plan, fmt.Errorf("failed to get leader gaps: %w", err)
+	}
+
+	now := time.Now()
+	for _, gap := range gaps {
+		if len(plan.Windows) == count {
+			break
+		}
+		if gap.Duration() <= v.MinimumTimeToLeaderSlot {
+			continue
+		}
+		startBefore := gap.End.Add(-v.MinimumTimeToLeaderSlot)
+		if !startBefore.After(now) {
+			continue
+		}
+		plan.Windows = append(plan.Windows, FailoverWindow{
+			Gap:         gap,
+			StartAfter:  gap.Start,
+			StartBefore: startBefore,
+		})
+	}
+	return plan, nil
+}
+
+// NextLeaderWindowSwitchAt returns the moment to switch so the failover completes just before the active identity's
+// next leader window - as late in the next failover window as its margin allows, so the new active node's first
+// leader slots follow shortly after and show it producing blocks
+func (v *Validator) NextLeaderWindowSwitchAt() (time.Time, error) {
+	plan, err := v.PlanFailoverWindows(nextLeaderWindowSearch)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	now := time.Now()
+	for _, window := range plan.Windows {
+		switchAt := window.StartBefore.Add(-nextLeaderWindowMargin)
+		if switchAt.After(now) && switchAt.After(window.StartAfter) {
+			return switchAt, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf(
+		"no gap between %s's leader slots is left this epoch to schedule the switch in - see the plan command",
+		plan.Pubkey,
+	)
+}
+
+// TableString returns the failover windows as a table
+func (p FailoverPlan) TableString() string {
+	if len(p.Windows) == 0 {
+		return fmt.Sprintf(
+			"No gap between %s's leader slots longer than min_time_to_leader_slot %s is left this epoch",
+			p.Pubkey,
+			p.MinTimeToLeaderSlot,
+		)
+	}
+
+	rows := make([][]string, 0, len(p.Windows))
+	for _, window := range p.Windows {
+		nextLeaderSlot := style.FormatInt(window.Gap.LastSlot + 1)
+		if window.Gap.EndsAtEpochEnd {
+			nextLeaderSlot = "next epoch?"
+		}
+		rows = append(rows, []string{
+			window.StartAfter.Local().Format(planTimeFormat),
+			window.StartBefore.Local().Format(planTimeFormat),
+			style.FormatDuration(window.Gap.Duration().Round(time.Second)),
+			fmt.Sprintf("%s-%s", style.FormatInt(window.Gap.FirstSlot), style.FormatInt(window.Gap.LastSlot)),
+			nextLeaderSlot,
+		})
+	}
+
+	return style.RenderTable(
+		[]string{"Start after", "Start before", "Gap", "Slots", "Next leader slot"},
+		rows,
+		func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return style.TableHeaderStyle
+			}
+			// the window the failover is run in is what matters most
+			if col < 2 {
+				return style.TableCellStyle.Foreground(style.ColorActive)
+			}
+			return style.TableCellStyle
+		},
+	)
+}
@@ -0,0 +1,120 @@
+package validator
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	solanapkg "github.com/sol-strategies/solana-validator-failover/internal/solana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLeaderGap(start time.Time, firstSlot, slots uint64) solanapkg.LeaderGap {
+	return solanapkg.LeaderGap{
+		FirstSlot: firstSlot,
+		LastSlot:  firstSlot + slots - 1,
+		Start:     start,
+		End:       start.Add(time.Duration(slots) * 400 * time.Millisecond),
+	}
+}
+
+func TestPlanFailoverWindows(t *testing.T) {
+	validator := newSwapTestValidator(t, true)
+
+	now := time.Now()
+	gaps := []solanapkg.LeaderGap{
+		// ends too soon to start a failover in
+		testLeaderGap(now.Add(-time.Hour), 1000, 9100),
+		// too short
+		testLeaderGap(now.Add(time.Minute), 10104, 100),
+		testLeaderGap(now.Add(2*time.Minute), 10208, 1500),
+		testLeaderGap(now.Add(20*time.Minute), 11712, 3000),
+		testLeaderGap(now.Add(time.Hour), 14716, 3000),
+	}
+	validator.solanaRPCClient = solanapkg.NewMockClient().
+		WithGetLeaderGaps(func(pubkey solana.PublicKey) ([]solanapkg.LeaderGap, error) {
+			return gaps, nil
+		})
+
+	plan, err := validator.PlanFailoverWindows(2)
+	require.NoError(t, err)
+	assert.Equal(t, validator.Identities.Active.PubKey(), plan.Pubkey)
+	require.Len(t, plan.Windows, 2)
+	assert.Equal(t, uint64(10208), plan.Windows[0].Gap.FirstSlot)
+	assert.Equal(t, gaps[2].Start, plan.Windows[0].StartAfter)
+	assert.Equal(t, gaps[2].End.Add(-5*time.Minute), plan.Windows[0].StartBefore)
+	assert.Equal(t, uint64(11712), plan.Windows[1].Gap.FirstSlot)
+	assert.Contains(t, plan.TableString(), "10,208-11,707")
+}
+
+func TestPlanFailoverWindows_NoneLeft(t *testing.T) {
+	validator := newSwapTestValidator(t, true)
+	validator.solanaRPCClient = solanapkg.NewMockClient().
+		WithGetLeaderGaps(func(pubkey solana.PublicKey) ([]solanapkg.LeaderGap, error) {
+			return []solanapkg.LeaderGap{testLeaderGap(time.Now(), 100, 10)}, nil
+		})
+
+	plan, err := validator.PlanFailoverWindows(5)
+	require.NoError(t, err)
+	assert.Empty(t, plan.Windows)
+	assert.Contains(t, plan.TableString(), "left this epoch")
+}
+
+func TestPlanFailoverWindows_Error(t *testing.T) {
+	validator := newSwapTestValidator(t, true)
+	validator.solanaRPCClient = solanapkg.NewMockClient().
+		WithGetLeaderGaps(func(pubkey solana.PublicKey) ([]solanapkg.LeaderGap, error) {
+			return nil, errors.New("rpc down")
+		})
+
+	_, err := validator.PlanFailoverWindows(5)
+	assert.ErrorContains(t, err, "rpc down")
+}
+
+func TestNextLeaderWindowSwitchAt(t *testing.T) {
+	validator := newSwapTestValidator(t, true)
+
+	now := time.Now()
+	gaps := []solanapkg.LeaderGap{
+		// its latest start less the margin has passed
+		testLeaderGap(now.Add(-5*time.Minute), 1000, 1525),
+		testLeaderGap(now.Add(time.Minute), 3000, 1500),
+	}
+	validator.solanaRPCClient = solanapkg.NewMockClient().
+		WithGetLeaderGaps(func(pubkey solana.PublicKey) ([]solanapkg.LeaderGap, error) {
+			return gaps, nil
+		})
+
+	switchAt, err := validator.NextLeaderWindowSwitchAt()
+	require.NoError(t, err)
+	assert.Equal(t, gaps[1].End.Add(-5*time.Minute-nextLeaderWindowMargin), switchAt)
+}
+
+func TestNextLeaderWindowSwitchAt_NoneLeft(t *testing.T) {
+	validator := newSwapTestValidator(t, true)
+	validator.solanaRPCClient = solanapkg.NewMockClient().
+		WithGetLeaderGaps(func(pubkey solana.PublicKey) ([]solanapkg.LeaderGap, error) {
+			return []solanapkg.LeaderGap{testLeaderGap(time.Now(), 100, 10)}, nil
+		})
+
+	_, err := validator.NextLeaderWindowSwitchAt()
+	assert.ErrorContains(t, err, "no gap")
+}
+
+func TestScheduledSwitchAt(t *testing.T) {
+	validator := newSwapTestValidator(t, true)
+
+	switchAt, err := validator.scheduledSwitchAt(FailoverParams{})
+	require.NoError(t, err)
+	assert.True(t, switchAt.IsZero())
+
+	at := time.Now().Add(time.Hour)
+	switchAt, err = validator.scheduledSwitchAt(FailoverParams{SwitchAt: at})
+	require.NoError(t, err)
+	assert.Equal(t, at, switchAt)
+
+	_, err = validator.scheduledSwitchAt(FailoverParams{SwitchAt: time.Now().Add(-time.Minute)})
+	assert.ErrorContains(t, err, "has passed")
+}
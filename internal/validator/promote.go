@@ -0,0 +1,275 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/constants"
+	"github.com/sol-strategies/solana-validator-failover/internal/failover"
+	"github.com/sol-strategies/solana-validator-failover/internal/solana"
+	"github.com/sol-strategies/solana-validator-failover/internal/style"
+	"github.com/sol-strategies/solana-validator-failover/internal/tower"
+	"github.com/sol-strategies/solana-validator-failover/internal/ui"
+)
+
+const (
+	// promoteGossipCheckInterval is how often gossip is checked for the active identity while waiting out its
+	// absence
+	promoteGossipCheckInterval = 5 * time.Second
+
+	// requireTowerFlag makes set-identity refuse to run without a saved tower - dropped to promote with a fresh one
+	requireTowerFlag = "--require-tower"
+
+	promoteOptionCancel  = "cancel"
+	promoteOptionPromote = "promote"
+)
+
+// EmergencyPromoteParams are the parameters for promoting this passive node when its active peer is dead
+type EmergencyPromoteParams struct {
+	NotADrill bool
+	// FreshTower promotes without a tower when no tower of the active identity is usable - the operator acknowledges
+	// the validator may then vote against lockouts the dead node held
+	FreshTower     bool
+	NonInteractive bool
+}
+
+// towerCandidate is a saved tower of the active identity this node could be promoted with
+type towerCandidate struct {
+	Path          string
+	Source        string
+	LastVotedSlot uint64
+	ModTime       time.Time
+}
+
+// configurePromote ensures the emergency promotion safety checks are valid and sets them
+func (v *Validator) configurePromote(cfg PromoteConfig) (err error) {
+	v.promoteGossipAbsence, err = time.ParseDuration(cfg.MinGossipAbsence)
+	if err != nil {
+		return fmt.Errorf("failed to parse failover.promote.min_gossip_absence %s: %w", cfg.MinGossipAbsence, err)
+	}
+	if v.promoteGossipAbsence < 0 {
+		return fmt.Errorf("failover.promote.min_gossip_absence must not be negative, got %s", cfg.MinGossipAbsence)
+	}
+
+	v.logger.Debug().
+		Dur("min_gossip_absence", v.promoteGossipAbsence).
+		Msg("emergency promotion set")
+	return nil
+}
+
+// EmergencyPromote promotes this passive node to the active identity when the active node is dead and can't hand
+// over - it refuses unless the active identity stays out of gossip for failover.promote.min_gossip_absence with its
+// vote account delinquent and not voting, then promotes with the newest tower of the active identity in the tower
+// file, the replica the active peer shipped or the tower file backups
+func (v *Validator) EmergencyPromote(params EmergencyPromoteParams) (err error) {
+	if err := v.GossipNode.Refresh(v.solanaRPCClient); err != nil {
+		return fmt.Errorf("failed to refresh gossip node: %w", err)
+	}
+	if !v.IsPassive() {
+		return fmt.Errorf("this node runs with %s, not its passive identity %s - nothing to promote", v.GossipNode.PubKey(), v.Identities.Passive.PubKey())
+	}
+
+	err = v.checkActiveIdentityBalance()
+	if err != nil {
+		return err
+	}
+	err = v.checkActiveIdentityDead(time.Sleep)
+	if err != nil {
+		return err
+	}
+
+	candidate := v.newestTowerCandidate()
+	command := v.SetIdentityActiveCommand
+	if candidate == nil {
+		if !params.FreshTower {
+			return fmt.Errorf("no usable tower of the active identity in the tower file, tower replica or tower backups - pass --fresh-tower to promote without one")
+		}
+		command = withoutRequireTower(command)
+	}
+
+	err = v.confirmEmergencyPromote(params, candidate, command)
+	if err != nil {
+		return err
+	}
+
+	dryRun := !params.NotADrill
+	switch {
+	case dryRun:
+		log.Info().Msg("(dry run) Leaving the tower file as it is")
+	case candidate == nil:
+		// set-identity would load whatever tower file is there rather than start a fresh one
+		if _, err := failover.BackupTowerFile(v.TowerFile, failover.TowerBackupConfig(v.TowerBackup), log.Logger); err != nil {
+			return err
+		}
+		if err := os.Remove(v.TowerFile); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove unusable tower file %s: %w", v.TowerFile, err)
+		}
+	case candidate.Path != v.TowerFile:
+		if _, err := failover.BackupTowerFile(v.TowerFile, failover.TowerBackupConfig(v.TowerBackup), log.Logger); err != nil {
+			return err
+		}
+		if err := failover.InstallTowerFile(candidate.Path, v.TowerFile, v.Identities.Active.PublicKey(), log.Logger); err != nil {
+			return err
+		}
+	}
+
+	return v.setIdentityLocally(constants.NodeRoleActive, command, v.Identities.Active, dryRun)
+}
+
+// checkActiveIdentityDead refuses unless the active identity stays out of gossip for failover.promote.min_gossip_absence
+// and its vote account is delinquent without its last vote advancing meanwhile - rpc errors refuse too as the active
+// node can't be shown dead without it
+func (v *Validator) checkActiveIdentityDead(sleep func(time.Duration)) error {
+	activePubkey := v.Identities.Active.PubKey()
+	before, err := v.solanaRPCClient.GetVoteAccountStatus(activePubkey)
+	if err != nil {
+		return fmt.Errorf("failed to get the active identity's vote account: %w", err)
+	}
+
+	log.Info().
+		Dur("min_gossip_absence", v.promoteGossipAbsence).
+		Msgf("Checking active identity %s stays out of gossip...", style.RenderActiveString(activePubkey, false))
+	deadline := time.Now().Add(v.promoteGossipAbsence)
+	for {
+		node, err := v.solanaRPCClient.NodeFromPubkey(activePubkey)
+		if err == nil {
+			return fmt.Errorf("active identity %s is in gossip at %s - its node is alive, fail over with run instead", activePubkey, node.IP())
+		}
+		if !errors.Is(err, solana.ErrNodeNotFound) {
+			return fmt.Errorf("failed to query gossip: %w", err)
+		}
+		if !time.Now().Before(deadline) {
+			break
+		}
+		sleep(min(promoteGossipCheckInterval, time.Until(deadline)))
+	}
+
+	after, err := v.solanaRPCClient.GetVoteAccountStatus(activePubkey)
+	if err != nil {
+		return fmt.Errorf("failed to get the active identity's vote account: %w", err)
+	}
+	if !after.Found {
+		log.Warn().Msgf("No vote account found for active identity %s", activePubkey)
+		return nil
+	}
+	if !after.Delinquent {
+		return fmt.Errorf("active identity %s's vote account %s isn't delinquent - it may still be voting", activePubkey, after.VotePubkey)
+	}
+	if before.Found && after.LastVote > before.LastVote {
+		return fmt.Errorf("active identity %s's vote account %s voted on slot %d while out of gossip - it is still voting", activePubkey, after.VotePubkey, after.LastVote)
+	}
+
+	log.Info().
+		Str("vote_pubkey", after.VotePubkey).
+		Uint64("last_vote", after.LastVote).
+		Msgf("Active identity out of gossip for %s and delinquent", v.promoteGossipAbsence)
+	return nil
+}
+
+// newestTowerCandidate returns the saved tower of the active identity with the newest vote - nil when there is none
+// that passes the sanity check a tower file received in a failover gets
+func (v *Validator) newestTowerCandidate() *towerCandidate {
+	sources := []towerCandidate{{Path: v.TowerFile, Source: "tower file"}}
+	if v.TowerReplicaFile != "" {
+		sources = append(sources, towerCandidate{Path: v.TowerReplicaFile, Source: "tower replica"})
+	}
+	backups, err := failover.TowerBackups(v.TowerFile, failover.TowerBackupConfig(v.TowerBackup))
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to list tower file backups")
+	}
+	for _, backup := range backups {
+		sources = append(sources, towerCandidate{Path: backup, Source: "tower backup"})
+	}
+
+	var newest *towerCandidate
+	for _, candidate := range sources {
+		info, err := os.Stat(candidate.Path)
+		if err != nil {
+			continue
+		}
+		towerFileBytes, err := os.ReadFile(candidate.Path)
+		if err != nil {
+			log.Warn().Err(err).Str("path", candidate.Path).Msgf("failed to read %s", candidate.Source)
+			continue
+		}
+		saved, err := tower.Parse(towerFileBytes, v.Identities.Active.PublicKey())
+		if err != nil {
+			log.Warn().Err(err).Str("path", candidate.Path).Msgf("Skipping unusable %s", candidate.Source)
+			continue
+		}
+		lastVotedSlot, ok := saved.LastVotedSlot()
+		if !ok {
+			log.Warn().Str("path", candidate.Path).Msgf("Skipping %s with no votes", candidate.Source)
+			continue
+		}
+
+		candidate.LastVotedSlot = lastVotedSlot
+		candidate.ModTime = info.ModTime()
+		log.Debug().
+			Str("path", candidate.Path).
+			Uint64("last_voted_slot", lastVotedSlot).
+			Time("mod_time", candidate.ModTime).
+			Msgf("found %s", candidate.Source)
+		// the tower file wins ties as it needs nothing installed
+		if newest == nil || candidate.LastVotedSlot > newest.LastVotedSlot {
+			newest = &candidate
+		}
+	}
+	return newest
+}
+
+// confirmEmergencyPromote shows what promoting will do and asks the operator to confirm - nonInteractive skips the
+// prompt, which is refused without a terminal
+func (v *Validator) confirmEmergencyPromote(params EmergencyPromoteParams, candidate *towerCandidate, command string) error {
+	if candidate == nil {
+		log.Warn().Msg(style.RenderWarningString("No usable tower - promoting with a fresh tower, the validator may vote against lockouts the dead node held"))
+	} else {
+		log.Info().
+			Str("path", candidate.Path).
+			Uint64("last_voted_slot", candidate.LastVotedSlot).
+			Str("age", time.Since(candidate.ModTime).Round(time.Second).String()).
+			Msgf("Promoting with the %s", candidate.Source)
+	}
+	log.Info().Str("command", command).Msgf(
+		"Emergency promotion sets %s to %s %s",
+		style.RenderPassiveString(v.Hostname, false),
+		style.RenderActiveString("ACTIVE", false),
+		style.RenderActiveString(v.Identities.Active.PubKey(), false),
+	)
+
+	if params.NonInteractive {
+		return nil
+	}
+	if v.stdinIsTerminal == nil || !v.stdinIsTerminal() {
+		return fmt.Errorf("emergency promotion needs confirming - pass --yes to promote without prompting")
+	}
+
+	choice := promoteOptionCancel
+	err := ui.Select("Promote this node although its active peer can't hand over?", []ui.Option{
+		{Key: "No - cancel", Value: promoteOptionCancel},
+		{Key: "Yes - promote", Value: promoteOptionPromote},
+	}, &choice)
+	if err != nil {
+		return fmt.Errorf("emergency promotion cancelled: %w", err)
+	}
+	if choice != promoteOptionPromote {
+		return fmt.Errorf("emergency promotion cancelled")
+	}
+	return nil
+}
+
+// withoutRequireTower returns the set identity command without the flag refusing to run without a saved tower
+func withoutRequireTower(command string) string {
+	args := strings.Fields(command)
+	kept := args[:0]
+	for _, arg := range args {
+		if arg != requireTowerFlag {
+			kept = append(kept, arg)
+		}
+	}
+	return strings.Join(kept, " ")
+}
@@ -0,0 +1,116 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/sol-strategies/solana-validator-failover/internal/failover"
+	"github.com/sol-strategies/solana-validator-failover/internal/identities"
+	solanapkg "github.com/sol-strategies/solana-validator-failover/internal/solana"
+	"github.com/sol-strategies/solana-validator-failover/internal/tower"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createPromoteTestValidator(t *testing.T) *TestValidator {
+	validator := createTestValidator(t)
+	validator.Identities = &identities.Identities{
+		Active:  &identities.Identity{KeyFile: "/path/to/active.json", Key: solana.NewWallet().PrivateKey},
+		Passive: &identities.Identity{KeyFile: "/path/to/passive.json", Key: solana.NewWallet().PrivateKey},
+	}
+	require.NoError(t, validator.configureTowerFile(TowerConfig{
+		Dir:              t.TempDir(),
+		FileNameTemplate: "tower.bin",
+		Backup:           TowerBackupConfig{Enabled: true},
+		Replication:      TowerReplicationConfig{Enabled: true, Interval: "10s"},
+	}))
+	return validator
+}
+
+func writeTestTower(t *testing.T, validator *TestValidator, path string, slot uint64) {
+	towerFileBytes, err := tower.Encode(tower.Tower{Votes: []tower.Lockout{{Slot: slot, ConfirmationCount: 1}}}, validator.Identities.Active.Key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, towerFileBytes, 0600))
+}
+
+func TestNewestTowerCandidate(t *testing.T) {
+	validator := createPromoteTestValidator(t)
+	assert.Nil(t, validator.newestTowerCandidate())
+
+	writeTestTower(t, validator, validator.TowerFile, 100)
+	candidate := validator.newestTowerCandidate()
+	require.NotNil(t, candidate)
+	assert.Equal(t, validator.TowerFile, candidate.Path)
+
+	// a newer backup and replica - the replica is newest
+	backupPath, err := failover.BackupTowerFile(validator.TowerFile, failover.TowerBackupConfig(validator.TowerBackup), validator.logger)
+	require.NoError(t, err)
+	writeTestTower(t, validator, backupPath, 150)
+	writeTestTower(t, validator, validator.TowerReplicaFile, 200)
+	candidate = validator.newestTowerCandidate()
+	require.NotNil(t, candidate)
+	assert.Equal(t, validator.TowerReplicaFile, candidate.Path)
+	assert.Equal(t, uint64(200), candidate.LastVotedSlot)
+
+	// a replica that isn't the active identity's tower is skipped
+	otherTower, err := tower.Encode(tower.Tower{Votes: []tower.Lockout{{Slot: 300, ConfirmationCount: 1}}}, solana.NewWallet().PrivateKey)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(validator.TowerReplicaFile, otherTower, 0600))
+	candidate = validator.newestTowerCandidate()
+	require.NotNil(t, candidate)
+	assert.Equal(t, backupPath, candidate.Path)
+}
+
+func TestCheckActiveIdentityDead(t *testing.T) {
+	validator := createPromoteTestValidator(t)
+	validator.promoteGossipAbsence = 12 * time.Second
+	noSleep := func(time.Duration) {}
+
+	inGossip := solanapkg.NewMockClient().
+		WithNodeFromPubkey(func(pubkey string) (*solanapkg.Node, error) {
+			return solanapkg.NewMockNodeAt(validator.Identities.Active.Key.PublicKey(), "2.2.0", "10.0.0.1"), nil
+		})
+	validator.solanaRPCClient = inGossip
+	assert.ErrorContains(t, validator.checkActiveIdentityDead(noSleep), "is in gossip")
+
+	voteStatuses := []solanapkg.VoteAccountStatus{}
+	validator.solanaRPCClient = solanapkg.NewMockClient().
+		WithNodeFromPubkey(func(pubkey string) (*solanapkg.Node, error) {
+			return nil, fmt.Errorf("%w for pubkey: %s", solanapkg.ErrNodeNotFound, pubkey)
+		}).
+		WithGetVoteAccountStatus(func(pubkey string) (solanapkg.VoteAccountStatus, error) {
+			status := voteStatuses[0]
+			voteStatuses = voteStatuses[1:]
+			return status, nil
+		})
+
+	voteStatuses = []solanapkg.VoteAccountStatus{{Found: true, LastVote: 100}, {Found: true, LastVote: 100}}
+	assert.ErrorContains(t, validator.checkActiveIdentityDead(noSleep), "isn't delinquent")
+
+	voteStatuses = []solanapkg.VoteAccountStatus{{Found: true, Delinquent: true, LastVote: 100}, {Found: true, Delinquent: true, LastVote: 120}}
+	assert.ErrorContains(t, validator.checkActiveIdentityDead(noSleep), "voted on slot 120")
+
+	voteStatuses = []solanapkg.VoteAccountStatus{{Found: true, Delinquent: true, LastVote: 100}, {Found: true, Delinquent: true, LastVote: 100}}
+	assert.NoError(t, validator.checkActiveIdentityDead(noSleep))
+}
+
+func TestWithoutRequireTower(t *testing.T) {
+	assert.Equal(t,
+		"agave-validator --ledger /mnt/ledger set-identity /keys/active.json",
+		withoutRequireTower("agave-validator --ledger /mnt/ledger set-identity /keys/active.json --require-tower"),
+	)
+	assert.Equal(t, "fdctl set-identity a.json", withoutRequireTower("fdctl set-identity a.json"))
+}
+
+func TestConfigurePromote(t *testing.T) {
+	validator := createTestValidator(t)
+
+	require.NoError(t, validator.configurePromote(PromoteConfig{MinGossipAbsence: "90s"}))
+	assert.Equal(t, 90*time.Second, validator.promoteGossipAbsence)
+
+	assert.ErrorContains(t, validator.configurePromote(PromoteConfig{MinGossipAbsence: "soon"}), "min_gossip_absence")
+	assert.ErrorContains(t, validator.configurePromote(PromoteConfig{MinGossipAbsence: "-1s"}), "must not be negative")
+}
@@ -0,0 +1,90 @@
+package validator
+
+import (
+	"fmt"
+	"html/template"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// ValidateConfig checks a validator config without touching this host or the network - every problem is returned
+// rather than only the first. Files, directories, the public ip and gossip are checked when the config is loaded
+// to run, or by doctor
+func ValidateConfig(cfg *Config) (problems []error) {
+	v := &Validator{logger: zerolog.Nop()}
+	check := func(err error) {
+		if err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	for _, required := range []struct{ key, value string }{
+		{"bin", cfg.Bin},
+		{"ledger_dir", cfg.LedgerDir},
+		{"tower.dir", cfg.Tower.Dir},
+	} {
+		if required.value == "" {
+			check(fmt.Errorf("%s is required", required.key))
+		}
+	}
+
+	problems = append(problems, cfg.Identities.Check()...)
+
+	check(v.configureDebugLogSampling(cfg.DebugLogSampleInterval))
+	check(v.configureNetworkRPC(cfg.NetworkRPC))
+	check(v.configureRPCClient(cfg.RPCAddress, cfg.Cluster))
+	check(validateTemplate("tower.file_name_template", cfg.Tower.FileNameTemplate))
+	check(validateTemplate("failover.set_identity_active_cmd_template", cfg.Failover.SetIdentityActiveCmdTemplate))
+	check(validateTemplate("failover.set_identity_passive_cmd_template", cfg.Failover.SetIdentityPassiveCmdTemplate))
+	if cfg.Tower.DriftMonitor.AutoClean && !cfg.Tower.AutoEmptyWhenPassive {
+		check(fmt.Errorf("tower.drift_monitor.auto_clean requires tower.auto_empty_when_passive to be true"))
+	}
+	if cfg.Tower.DriftMonitor.Enabled && cfg.Tower.DriftMonitor.Interval != "" {
+		if _, err := time.ParseDuration(cfg.Tower.DriftMonitor.Interval); err != nil {
+			check(fmt.Errorf("failed to parse tower.drift_monitor.interval %s: %w", cfg.Tower.DriftMonitor.Interval, err))
+		}
+	}
+	if cfg.Tower.Backup.Retention < 0 {
+		check(fmt.Errorf("tower.backup.retention must not be negative: %d", cfg.Tower.Backup.Retention))
+	}
+	if cfg.Tower.Replication.Enabled {
+		if interval, err := time.ParseDuration(cfg.Tower.Replication.Interval); err != nil {
+			check(fmt.Errorf("failed to parse tower.replication.interval %s: %w", cfg.Tower.Replication.Interval, err))
+		} else if interval <= 0 {
+			check(fmt.Errorf("tower.replication.interval must be positive: %s", cfg.Tower.Replication.Interval))
+		}
+	}
+	check(v.configureHooks(cfg.Failover))
+	check(v.configurePeers(cfg.Failover.Peers))
+	check(v.configureMinimumTimeToLeaderSlot(cfg.Failover.MinimumTimeToLeaderSlot))
+	check(v.configureSwitchCountdown(cfg.Failover.SwitchCountdown))
+	check(v.configureServer(cfg.Failover.Server))
+	check(v.configureMonitor(cfg.Failover.Monitor))
+	check(v.configureClient(cfg.Failover.Client))
+	check(v.configureLogSlotContext(cfg.Failover.LogSlotContext))
+	check(v.configureDrill(cfg.Failover.Drill))
+	check(v.configureEvents(cfg.Failover.Events))
+	check(v.configureNotifications(cfg.Failover.Notifications))
+	check(v.configureTracing(cfg.Failover.Tracing))
+	check(v.configureAudit(cfg.Failover.Audit))
+	check(v.configureRoleIntent(cfg.Failover.RoleIntent))
+	check(v.configureAgent(cfg.Failover.Agent))
+	check(v.configureClockCheck(cfg.Failover.ClockCheck))
+	check(v.configurePathMTUProbe(cfg.Failover.PathMTUProbe))
+	check(v.configureFeatureGates(cfg.Failover.FeatureGates))
+	check(v.configureEpochBoundary(cfg.Failover.EpochBoundary))
+	check(v.configurePeerSelection(cfg.Failover.PeerSelection))
+	check(v.configureWatch(cfg.Failover.Watch))
+	check(v.configurePromote(cfg.Failover.Promote))
+
+	return problems
+}
+
+// validateTemplate ensures a template parses - it isn't executed as that needs the identities loaded
+func validateTemplate(key, text string) error {
+	if _, err := template.New(key).Parse(text); err != nil {
+		return fmt.Errorf("failed to parse %s %s: %w", key, text, err)
+	}
+	return nil
+}
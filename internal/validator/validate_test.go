@@ -0,0 +1,56 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/sol-strategies/solana-validator-failover/internal/identities"
+	"github.com/stretchr/testify/assert"
+)
+
+func newValidateTestConfig() Config {
+	return Config{
+		Bin:        "agave-validator",
+		Cluster:    "testnet",
+		RPCAddress: "http://localhost:8899",
+		LedgerDir:  "/mnt/ledger",
+		Identities: identities.Config{Active: "/home/sol/active.json", Passive: "/home/sol/passive.json"},
+		Tower:      TowerConfig{Dir: "/mnt/ledger", FileNameTemplate: "tower-1_9-{{ .Identities.Active.PubKey }}.bin"},
+		Failover: FailoverConfig{
+			MinimumTimeToLeaderSlot: "5m",
+			SwitchCountdown:         "3s",
+			Peers:                   PeersConfig{"backup": {Address: "backup.example.com:9898"}},
+			PeerSelection:           PeerSelectionConfig{Timeout: "5s"},
+			Watch:                   WatchConfig{Interval: "5s", FailureThreshold: "30s", Cooldown: "1h", Timeout: "5m"},
+			Promote:                 PromoteConfig{MinGossipAbsence: "1m"},
+		},
+	}
+}
+
+func TestValidateConfig_Valid(t *testing.T) {
+	cfg := newValidateTestConfig()
+	assert.Empty(t, ValidateConfig(&cfg))
+}
+
+func TestValidateConfig_CollectsEveryProblem(t *testing.T) {
+	cfg := newValidateTestConfig()
+	cfg.Bin = ""
+	cfg.Cluster = "mainnet"
+	cfg.Failover.SetIdentityActiveCmdTemplate = "{{ .Bin"
+	cfg.Failover.Peers = PeersConfig{"backup": {Address: "backup.example.com"}}
+	cfg.Failover.SwitchCountdown = "soon"
+	cfg.Failover.Drill = DrillConfig{Schedule: "0 4 * * 2", Peer: "primary"}
+
+	problems := ValidateConfig(&cfg)
+
+	messages := make([]string, 0, len(problems))
+	for _, problem := range problems {
+		messages = append(messages, problem.Error())
+	}
+	assert.Len(t, messages, 6, messages)
+	assert.Contains(t, messages[0], "bin is required")
+	assert.Contains(t, messages[1], "invalid cluster")
+	assert.Contains(t, messages[2], "set_identity_active_cmd_template")
+	assert.Contains(t, messages[3], "invalid peer address")
+	assert.Contains(t, messages[4], "failover.switch_countdown")
+	assert.Contains(t, messages[5], "failover.drill.peer primary")
+}
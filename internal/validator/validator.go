@@ -2,33 +2,153 @@ package validator
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"html/template"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/charmbracelet/huh"
-	"github.com/charmbracelet/huh/spinner"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"github.com/sol-strategies/solana-validator-failover/internal/audit"
+	"github.com/sol-strategies/solana-validator-failover/internal/clock"
 	"github.com/sol-strategies/solana-validator-failover/internal/constants"
+	"github.com/sol-strategies/solana-validator-failover/internal/deprecation"
+	"github.com/sol-strategies/solana-validator-failover/internal/events"
 	"github.com/sol-strategies/solana-validator-failover/internal/failover"
 	"github.com/sol-strategies/solana-validator-failover/internal/hooks"
 	"github.com/sol-strategies/solana-validator-failover/internal/identities"
+	"github.com/sol-strategies/solana-validator-failover/internal/notify"
+	"github.com/sol-strategies/solana-validator-failover/internal/peertrust"
+	"github.com/sol-strategies/solana-validator-failover/internal/roleintent"
+	"github.com/sol-strategies/solana-validator-failover/internal/schedule"
 	"github.com/sol-strategies/solana-validator-failover/internal/solana"
 	"github.com/sol-strategies/solana-validator-failover/internal/style"
+	"github.com/sol-strategies/solana-validator-failover/internal/systemd"
+	"github.com/sol-strategies/solana-validator-failover/internal/tracing"
+	"github.com/sol-strategies/solana-validator-failover/internal/ui"
 	"github.com/sol-strategies/solana-validator-failover/internal/utils"
 	pkgconstants "github.com/sol-strategies/solana-validator-failover/pkg/constants"
+	"golang.org/x/term"
 )
 
+// localSwapGossipTimeout is how long a local identity swap waits for gossip to reflect the new identity
+const localSwapGossipTimeout = 2 * time.Minute
+
+// roleIntentReasonSwap is the reason recorded for a role set by a local identity swap
+const roleIntentReasonSwap = "swap"
+
+// maxSwitchCountdown is the longest countdown to the switch - the active node has already passed its leader slot check
+// and run its pre hooks by the time it starts
+const maxSwitchCountdown = 30 * time.Second
+
+// binVersionTimeout is how long the validator binary may take to print its version
+const binVersionTimeout = 10 * time.Second
+
+// setIdentityCmdTemplates are the built-in set identity command templates for a validator client
+type setIdentityCmdTemplates struct {
+	Active  string
+	Passive string
+	// ActiveFromStdin and PassiveFromStdin read the keypair from stdin, where an encrypted identity's decrypted
+	// keypair is passed - empty when the client can't
+	ActiveFromStdin  string
+	PassiveFromStdin string
+}
+
+// agaveSetIdentityCmdTemplates are the built-in set identity command templates for agave and its forks
+var agaveSetIdentityCmdTemplates = setIdentityCmdTemplates{
+	Active:           "{{ .Bin }} --ledger {{ .LedgerDir }} set-identity {{ .Identities.Active.KeyFile }} --require-tower",
+	Passive:          "{{ .Bin }} --ledger {{ .LedgerDir }} set-identity {{ .Identities.Passive.KeyFile }}",
+	ActiveFromStdin:  "{{ .Bin }} --ledger {{ .LedgerDir }} set-identity --require-tower",
+	PassiveFromStdin: "{{ .Bin }} --ledger {{ .LedgerDir }} set-identity",
+}
+
+// builtInSetIdentityCmdTemplates are the set identity command templates used for each detected client when none are
+// configured - clients that couldn't be detected use agave's
+var builtInSetIdentityCmdTemplates = map[string]setIdentityCmdTemplates{
+	constants.ClientTypeAgave:     agaveSetIdentityCmdTemplates,
+	constants.ClientTypeJitoAgave: agaveSetIdentityCmdTemplates,
+	constants.ClientTypeFiredancer: {
+		Active:  "{{ .Bin }} set-identity --config {{ .FiredancerConfig }} {{ .Identities.Active.KeyFile }} --require-tower",
+		Passive: "{{ .Bin }} set-identity --config {{ .FiredancerConfig }} {{ .Identities.Passive.KeyFile }}",
+	},
+}
+
+// binVersionPattern matches the version in a validator binary's --version output
+var binVersionPattern = regexp.MustCompile(`\d+\.\d+\.\d+`)
+
+// reloadPeersOptionValue is the peer selection prompt value that reloads peers - no peer name contains a NUL
+const reloadPeersOptionValue = "\x00reload-peers"
+
 // FailoverParams are the parameters for running a failover
 type FailoverParams struct {
 	NotADrill             bool
 	NoWaitForHealthy      bool
 	NoMinTimeToLeaderSlot bool
 	MinTimeToLeaderSlot   time.Duration
+	// PeerName selects the passive peer by name instead of prompting - ignored when run on passive node
+	PeerName string
+	// NonInteractive never prompts - selecting a peer among several then requires PeerName or
+	// failover.peer_selection.auto_select
+	NonInteractive bool
+	// WaitTimeout stops waiting for the active node to connect after it - ignored when run on active node
+	WaitTimeout time.Duration
+	// ViaAgent asks the agent on the active peer to hand over instead of waiting for it to be run there - ignored
+	// when run on active node
+	ViaAgent bool
+	// ReloadPeers loads the peers afresh from config - when set the peer selection prompt offers to reload them
+	ReloadPeers func() (PeersConfig, error)
+	// PeerNoWaitForHealthy asks the active peer's agent not to wait for its node to report healthy - ignored unless
+	// ViaAgent is set
+	PeerNoWaitForHealthy bool
+	// NoPostMonitor skips post-failover vote credit monitoring - ignored when run on active node
+	NoPostMonitor bool
+	// PeerPort overrides the selected passive peer's port - set by the agent to the port the requester listens on
+	PeerPort int
+	// DetachPostMonitor hands post-failover vote credit monitoring off to a process that outlives this one, given the
+	// pre-failover vote credit rank - ignored when run on active node
+	DetachPostMonitor func(baselineRank int) error
+	// Output is how the failover result is reported - styled tables by default
+	Output failover.OutputConfig
+	// Force proceeds when the active identity is already delinquent - ignored when run on active node - and when
+	// failover.epoch_boundary refuses
+	Force bool
+	// SwitchAt schedules the switch for this moment once the failover is confirmed - zero switches as soon as the
+	// active node is ready
+	SwitchAt time.Time
+	// BeforeNextLeaderWindow schedules the switch so the failover completes just before the active identity's next
+	// leader window - SwitchAt is set from it
+	BeforeNextLeaderWindow bool
+	// WarmHold holds the session open once the failover is confirmed until the operator triggers the switch, which
+	// then starts without connecting or checking again - ignored when run on passive node
+	WarmHold bool
+	// waitForHealthy is when Failover waited for this node to report healthy - recorded as a span of the failover
+	waitForHealthy tracing.Interval
+}
+
+// SwapParams are the parameters for swapping this validator's identity without a peer
+type SwapParams struct {
+	NotADrill             bool
+	NoWaitForHealthy      bool
+	NoMinTimeToLeaderSlot bool
+	// FromTowerReplica installs the tower replica shipped by the active peer when promoting without a tower file
+	FromTowerReplica bool
 }
 
 // Peers is a map of peers
@@ -36,8 +156,15 @@ type Peers map[string]Peer
 
 // Peer is a peer in the failover configuration
 type Peer struct {
-	Name    string
-	Address string
+	Name          string
+	Address       string
+	GossipIP      string
+	PassivePubkey string
+	// Priority ranks the peer ahead of healthy peers with a lower one when passive peers are ranked
+	Priority int
+	// DiscoverPort is the failover port of a peer discovered in gossip by its passive pubkey - its address and
+	// gossip ip are empty until it is. Zero for peers with a configured address
+	DiscoverPort int
 }
 
 // BinMetadata is the metadata for a validator client
@@ -50,23 +177,73 @@ type BinMetadata struct {
 type Validator struct {
 	Bin                            string
 	BinMetadata                    BinMetadata
+	FailoverClientConfig           ClientConfig
 	FailoverServerConfig           ServerConfig
+	LogSlotContext                 LogSlotContextConfig
 	GossipNode                     *solana.Node
 	Hooks                          hooks.FailoverHooks
 	Hostname                       string
 	Identities                     *identities.Identities
 	LedgerDir                      string
 	MinimumTimeToLeaderSlot        time.Duration
+	SwitchCountdown                time.Duration
+	MinActiveIdentityBalance       uint64
+	MaxSlotLag                     uint64
 	Peers                          Peers
 	PublicIP                       string
 	SetIdentityActiveCommand       string
 	SetIdentityPassiveCommand      string
 	TowerFile                      string
+	FiredancerConfig               string
 	TowerFileAutoDeleteWhenPassive bool
+	TowerDriftMonitor              TowerDriftMonitorConfig
+	TowerBackup                    TowerBackupConfig
+	TowerSanityCheck               bool
+	TowerReplication               TowerReplicationConfig
+	TowerReplicaFile               string
 	Monitor                        MonitorConfig
-
-	logger          zerolog.Logger
-	solanaRPCClient solana.ClientInterface
+	Drill                          DrillConfig
+	Events                         events.Config
+	Tracing                        tracing.Config
+	Notifications                  notify.Config
+	Agent                          AgentConfig
+	TLSCertificate                 *tls.Certificate
+	ClientCertificate              *tls.Certificate
+	ClientCAs                      *x509.CertPool
+	ServerCAs                      *x509.CertPool
+	PeerPins                       *peertrust.Store
+	ClockCheck                     ClockCheckConfig
+	PathMTUProbe                   failover.PathMTUProbeConfig
+	FeatureGates                   FeatureGatesConfig
+	PeerSelection                  PeerSelectionConfig
+	DrillSchedule                  *schedule.Schedule
+	Watch                          WatchConfig
+	AuditLog                       *audit.Log
+	RoleIntent                     *roleintent.Store
+	EpochBoundary                  EpochBoundaryConfig
+
+	logger                 zerolog.Logger
+	solanaRPCClient        solana.ClientInterface
+	debugLogSampleInterval time.Duration
+	networkRPC             solana.NewClientParams
+	clockCheckMaxOffset    time.Duration
+	clockOffset            func() (time.Duration, error)
+	featureGateIDs         []solanago.PublicKey
+	peerSelectionTimeout   time.Duration
+	peerStatusQuery        func(peer Peer) peerStatus
+	stdinIsTerminal        func() bool
+	peerProbe              func(address string) error
+	publicIPFromConfig     bool
+	agentFileTransfer      failover.FileTransferConfig
+	watchInterval          time.Duration
+	watchFailureThreshold  time.Duration
+	watchCooldown          time.Duration
+	watchTimeout           time.Duration
+	configView             failover.ConfigView
+	towerReplicaInterval   time.Duration
+	promoteGossipAbsence   time.Duration
+	failoverServerQUIC     failover.QUICConfig
+	failoverClientQUIC     failover.QUICConfig
 }
 
 // NewSolanaRPCClient creates a new Solana RPC client
@@ -94,8 +271,23 @@ func (v *Validator) NewFromConfig(cfg *Config) error {
 	defer log.Debug().Msg("================================================")
 	defer v.logger.Debug().Msg("configuration done")
 
+	// silence deprecation notices before anything deprecated is used
+	v.configureDeprecationWarnings(cfg.SilenceDeprecationWarnings)
+
+	// sample repetitive debug logs from the rpc client
+	err := v.configureDebugLogSampling(cfg.DebugLogSampleInterval)
+	if err != nil {
+		return err
+	}
+
+	// extra network rpc urls to fail over between
+	err = v.configureNetworkRPC(cfg.NetworkRPC)
+	if err != nil {
+		return err
+	}
+
 	// configure solana rpc clients all in one
-	err := v.configureRPCClient(cfg.RPCAddress, cfg.Cluster)
+	err = v.configureRPCClient(cfg.RPCAddress, cfg.Cluster)
 	if err != nil {
 		return err
 	}
@@ -106,6 +298,15 @@ func (v *Validator) NewFromConfig(cfg *Config) error {
 		return err
 	}
 
+	// detect the validator client from its binary so set identity commands can be chosen for it
+	v.configureBinMetadata()
+
+	// firedancer's config is needed by its built-in set identity commands
+	err = v.configureFiredancerConfig(cfg.FiredancerConfig)
+	if err != nil {
+		return err
+	}
+
 	// ledger dir must be valid and exist
 	err = v.configureLedgerDir(cfg.LedgerDir)
 	if err != nil {
@@ -148,12 +349,30 @@ func (v *Validator) NewFromConfig(cfg *Config) error {
 		return err
 	}
 
+	// look up peers that set discover in gossip - needs the public ip to tell this node apart from them
+	err = v.discoverPeers()
+	if err != nil {
+		return err
+	}
+
 	// get minimum time to leader slot parse and set
 	err = v.configureMinimumTimeToLeaderSlot(cfg.Failover.MinimumTimeToLeaderSlot)
 	if err != nil {
 		return err
 	}
 
+	// countdown both nodes show before the switch
+	err = v.configureSwitchCountdown(cfg.Failover.SwitchCountdown)
+	if err != nil {
+		return err
+	}
+
+	// minimum active identity balance to pay vote fees - zero disables the check
+	v.MinActiveIdentityBalance = cfg.Failover.MinActiveIdentityBalance
+
+	// slots the passive node may be behind the network and still take over - zero disables the check
+	v.MaxSlotLag = cfg.Failover.MaxSlotLag
+
 	// get hostname
 	err = v.configureHostname(cfg.Hostname)
 	if err != nil {
@@ -178,6 +397,117 @@ func (v *Validator) NewFromConfig(cfg *Config) error {
 		return err
 	}
 
+	// configure client
+	err = v.configureClient(cfg.Failover.Client)
+	if err != nil {
+		return err
+	}
+
+	// configure log slot context
+	err = v.configureLogSlotContext(cfg.Failover.LogSlotContext)
+	if err != nil {
+		return err
+	}
+
+	// configure scheduled drills
+	err = v.configureDrill(cfg.Failover.Drill)
+	if err != nil {
+		return err
+	}
+
+	// configure event publishing
+	err = v.configureEvents(cfg.Failover.Events)
+	if err != nil {
+		return err
+	}
+
+	// configure notifications
+	err = v.configureNotifications(cfg.Failover.Notifications)
+	if err != nil {
+		return err
+	}
+
+	// configure tracing
+	err = v.configureTracing(cfg.Failover.Tracing)
+	if err != nil {
+		return err
+	}
+
+	// configure failover audit log
+	err = v.configureAudit(cfg.Failover.Audit)
+	if err != nil {
+		return err
+	}
+
+	// configure the record of which role this node should hold
+	err = v.configureRoleIntent(cfg.Failover.RoleIntent)
+	if err != nil {
+		return err
+	}
+
+	// configure agent
+	err = v.configureAgent(cfg.Failover.Agent)
+	if err != nil {
+		return err
+	}
+
+	// configure persisted certificate and peer pinning
+	err = v.configureTLS(cfg.Failover.TLS)
+	if err != nil {
+		return err
+	}
+
+	// configure operator-supplied certificates
+	err = v.configureCertificateFiles(cfg.Failover.Server.TLS, cfg.Failover.Client.TLS)
+	if err != nil {
+		return err
+	}
+
+	// configure local clock offset check
+	err = v.configureClockCheck(cfg.Failover.ClockCheck)
+	if err != nil {
+		return err
+	}
+
+	// configure the path mtu probe run before the handshake
+	err = v.configurePathMTUProbe(cfg.Failover.PathMTUProbe)
+	if err != nil {
+		return err
+	}
+
+	// configure feature gate activation window warnings
+	err = v.configureFeatureGates(cfg.Failover.FeatureGates)
+	if err != nil {
+		return err
+	}
+
+	// configure the guard against failing over near an epoch boundary
+	err = v.configureEpochBoundary(cfg.Failover.EpochBoundary)
+	if err != nil {
+		return err
+	}
+
+	// configure ranking of passive peers
+	err = v.configurePeerSelection(cfg.Failover.PeerSelection)
+	if err != nil {
+		return err
+	}
+
+	// configure the unattended watch daemon
+	err = v.configureWatch(cfg.Failover.Watch)
+	if err != nil {
+		return err
+	}
+
+	// configure the emergency promotion safety checks
+	err = v.configurePromote(cfg.Failover.Promote)
+	if err != nil {
+		return err
+	}
+
+	// the config diffed against peers' to catch drift
+	v.configView = newConfigView(cfg, v.BinMetadata)
+
 	return nil
 }
 
@@ -202,7 +532,9 @@ func (v *Validator) Failover(params FailoverParams) (err error) {
 	if params.NoWaitForHealthy {
 		log.Debug().Msg("--no-wait-for-healthy flag is set, skipping wait for healthy")
 	} else {
+		params.waitForHealthy.Start = time.Now()
 		err = v.waitUntilHealthy()
+		params.waitForHealthy.End = time.Now()
 		if err != nil {
 			return fmt.Errorf("failed to wait until healthy: %w", err)
 		}
@@ -210,410 +542,2868 @@ func (v *Validator) Failover(params FailoverParams) (err error) {
 
 	params.MinTimeToLeaderSlot = v.MinimumTimeToLeaderSlot
 
-	if v.IsActive() {
-		return v.makePassive(params)
+	// waiting for slots and the timing tables assume this node's clock is roughly right
+	err = v.checkClockOffset()
+	if err != nil {
+		return err
 	}
 
-	return v.makeActive(params)
-}
-
-// configureRPCClient configures the solana rpc client
-func (v *Validator) configureRPCClient(localRPCURL, solanaClusterName string) error {
-	// configure solana rpc clients all in one
-	err := utils.ValidateCluster(solanaClusterName)
+	err = v.checkGossipIdentity()
 	if err != nil {
 		return err
 	}
 
-	if !utils.IsValidURLWithPort(localRPCURL) {
-		return fmt.Errorf(
-			"invalid rpc address: %s, must be a valid url with a port",
-			localRPCURL,
-		)
+	// an identity changed outside of failovers means this node may not be in the role the operator thinks
+	v.warnRoleIntentDivergence("")
+
+	// feature activations around an epoch boundary can change tower and identity switching behaviour
+	v.warnFeatureGateActivations()
+
+	// the leader schedule and vote credits start again at an epoch boundary
+	err = v.checkEpochBoundary(params.Force)
+	if err != nil {
+		return err
 	}
 
-	solanaClusterRPCURL := constants.SolanaClusters[solanaClusterName].RPC
+	// activating an identity that can't pay vote fees silently stops voting - refuse before anything changes
+	err = v.checkActiveIdentityBalance()
+	if err != nil {
+		return err
+	}
 
-	v.logger.Debug().
-		Str("cluster", solanaClusterName).
-		Str("local_rpc_url", localRPCURL).
-		Str("network_rpc_url", solanaClusterRPCURL).
-		Msg("rpc client configured")
+	// work out when a scheduled failover switches before connecting so a bad schedule fails early
+	params.SwitchAt, err = v.scheduledSwitchAt(params)
+	if err != nil {
+		return err
+	}
+	if params.WarmHold && !params.SwitchAt.IsZero() && v.IsActive() {
+		return fmt.Errorf("a held failover switches when triggered - don't schedule the switch too")
+	}
 
-	v.solanaRPCClient = v.NewSolanaRPCClient(solana.NewClientParams{
-		LocalRPCURL:   localRPCURL,
-		NetworkRPCURL: solanaClusterRPCURL,
-	})
+	if v.IsActive() {
+		return v.makePassive(params)
+	}
 
-	return nil
+	return v.makeActive(params)
 }
 
-// configureBin ensures the validator binary exists and sets it
-func (v *Validator) configureBin(bin string) error {
-	err := utils.EnsureBins(bin)
-	if err != nil {
-		return err
+// scheduledSwitchAt returns when the failover's switch is scheduled for - zero when it isn't
+func (v *Validator) scheduledSwitchAt(params FailoverParams) (switchAt time.Time, err error) {
+	switchAt = params.SwitchAt
+	if params.BeforeNextLeaderWindow {
+		switchAt, err = v.NextLeaderWindowSwitchAt()
+		if err != nil {
+			return switchAt, fmt.Errorf("failed to schedule the switch before the next leader window: %w", err)
+		}
 	}
-	v.Bin = bin
-	v.logger.Debug().
-		Str("bin", v.Bin).
-		Msg("validator binary set")
-	return nil
+	if switchAt.IsZero() {
+		return switchAt, nil
+	}
+
+	if !switchAt.After(time.Now()) {
+		return switchAt, fmt.Errorf("scheduled switch time %s has passed", switchAt.Format(time.RFC3339))
+	}
+	log.Info().
+		Time("switch_at", switchAt).
+		Msgf("Switch scheduled for %s - in %s", switchAt.Local().Format(time.RFC3339), time.Until(switchAt).Round(time.Second))
+	return switchAt, nil
 }
 
-// configureLedgerDir ensures the ledger directory exists
-func (v *Validator) configureLedgerDir(ledgerDir string) error {
-	ledgerDir, err := utils.ResolveAndValidateDir(ledgerDir)
-	if err != nil {
-		return err
+// checkGossipIdentity ensures gossip reports this node with one of its configured identities - a gossip identity
+// matching neither is almost always a config mistake
+func (v *Validator) checkGossipIdentity() error {
+	if v.IsActive() || v.IsPassive() {
+		return nil
 	}
-	v.LedgerDir = ledgerDir
-	v.logger.Debug().
-		Str("ledger_dir", v.LedgerDir).
-		Msg("ledger dir set")
-	return nil
+	return fmt.Errorf(
+		"gossip reports %s (%s) with pubkey %s which matches neither its active (%s) nor passive (%s) identity - check validator.identities on %s",
+		v.Hostname,
+		v.PublicIP,
+		v.GossipNode.PubKey(),
+		v.Identities.Active.PubKey(),
+		v.Identities.Passive.PubKey(),
+		v.Hostname,
+	)
 }
 
-// configureIdentities ensures the identities are valid and sets them
-func (v *Validator) configureIdentities(identitiesConfig identities.Config) (err error) {
-	v.Identities, err = identities.NewFromConfig(&identitiesConfig)
+// SwapIdentity switches this validator between its active and passive identities without a peer - for demoting a
+// node before maintenance (or promoting it back after) when the standby is handled separately. The tower file stays
+// in place either way as it belongs to the active identity and is needed to promote the node again
+func (v *Validator) SwapIdentity(params SwapParams) (err error) {
+	log.Debug().Msgf("swap identity with params: %+v", params)
+
+	if params.NoWaitForHealthy {
+		log.Debug().Msg("--no-wait-for-healthy flag is set, skipping wait for healthy")
+	} else {
+		err = v.waitUntilHealthy()
+		if err != nil {
+			return fmt.Errorf("failed to wait until healthy: %w", err)
+		}
+	}
+
+	err = v.checkGossipIdentity()
 	if err != nil {
 		return err
 	}
 
-	v.logger.Debug().
-		Str("active_pubkey", v.Identities.Active.PubKey()).
-		Str("active_keyfile", v.Identities.Active.KeyFile).
-		Str("passive_pubkey", v.Identities.Passive.PubKey()).
-		Str("passive_keyfile", v.Identities.Passive.KeyFile).
-		Msg("identities set")
+	// the active peer died without handing over its tower - promote with the last replica it shipped
+	if params.FromTowerReplica && !v.IsActive() && utils.FileSize(v.TowerFile) == 0 {
+		if err := v.installTowerReplica(); err != nil {
+			return err
+		}
+	}
 
-	return nil
+	// set-identity to active requires the tower and demoting without one leaves nothing to promote back with
+	if !utils.FileExists(v.TowerFile) {
+		return fmt.Errorf("tower file does not exist: %s", v.TowerFile)
+	}
+	if utils.FileSize(v.TowerFile) == 0 {
+		return fmt.Errorf("tower file is empty: %s", v.TowerFile)
+	}
+
+	if v.IsActive() {
+		return v.swapToPassive(params)
+	}
+	return v.swapToActive(params)
 }
 
-// configureTowerFile ensures the tower file is valid and sets it
-func (v *Validator) configureTowerFile(cfg TowerConfig) error {
-	v.TowerFileAutoDeleteWhenPassive = cfg.AutoEmptyWhenPassive
-	v.logger.Debug().
-		Bool("tower_file_auto_delete_when_passive", v.TowerFileAutoDeleteWhenPassive).
-		Msg("tower file auto delete when passive set")
+// installTowerReplica installs the tower replica shipped by the active peer as the tower file
+func (v *Validator) installTowerReplica() error {
+	if !v.TowerReplication.Enabled {
+		return fmt.Errorf("no tower replica to promote with - tower.replication is not enabled")
+	}
+	return failover.InstallTowerReplica(v.TowerReplicaFile, v.TowerFile, v.Identities.Active.PublicKey(), log.Logger)
+}
 
-	// tower dir must exist
-	towerDir, err := utils.ResolveAndValidateDir(cfg.Dir)
+// swapToPassive demotes this active validator to its passive identity once it has no leader slots coming up
+func (v *Validator) swapToPassive(params SwapParams) (err error) {
+	if params.NoMinTimeToLeaderSlot {
+		log.Debug().Msg("--no-min-time-to-leader-slot flag is set, skipping leader slot check")
+	} else {
+		err = v.checkNoLeaderSlotWithin(v.MinimumTimeToLeaderSlot)
+		if err != nil {
+			return err
+		}
+	}
+
+	return v.setIdentityLocally(constants.NodeRolePassive, v.SetIdentityPassiveCommand, v.Identities.Passive, !params.NotADrill)
+}
+
+// swapToActive promotes this passive validator to its active identity - refused while any node in gossip still runs
+// with the active identity as both would vote with it
+func (v *Validator) swapToActive(params SwapParams) (err error) {
+	err = v.checkActiveIdentityBalance()
 	if err != nil {
 		return err
 	}
 
-	// tower file name template must be valid
-	towerFileNameTemplate, err := template.New("tower").Parse(cfg.FileNameTemplate)
-	if err != nil {
+	activeNode, err := v.solanaRPCClient.NodeFromPubkey(v.Identities.Active.PubKey())
+	if err == nil {
 		return fmt.Errorf(
-			"failed to parse file name template %s: %w",
-			cfg.FileNameTemplate,
-			err,
+			"active identity %s is in gossip at %s - demote that node first, promoting this one too would have both voting with it",
+			v.Identities.Active.PubKey(),
+			activeNode.IP(),
 		)
 	}
-	v.logger.Debug().
-		Str("template", cfg.FileNameTemplate).
-		Msg("tower file name template set")
 
-	// tower file name template must compile
-	var towerFileNameBuf strings.Builder
-	if err := towerFileNameTemplate.Execute(&towerFileNameBuf, v); err != nil {
+	return v.setIdentityLocally(constants.NodeRoleActive, v.SetIdentityActiveCommand, v.Identities.Active, !params.NotADrill)
+}
+
+// checkNoLeaderSlotWithin refuses when this validator's active identity is leader within the given time
+func (v *Validator) checkNoLeaderSlotWithin(minTimeToLeaderSlot time.Duration) error {
+	isOnLeaderSchedule, timeToNextLeaderSlot, err := v.solanaRPCClient.GetTimeToNextLeaderSlotForPubkey(v.Identities.Active.PublicKey())
+	if err != nil {
+		return fmt.Errorf("failed to get time to next leader slot: %w", err)
+	}
+	if isOnLeaderSchedule && timeToNextLeaderSlot < minTimeToLeaderSlot {
 		return fmt.Errorf(
-			"failed to execute file name template %s: %w",
-			cfg.FileNameTemplate,
-			err,
+			"next leader slot is in %s, less than validator.failover.min_time_to_leader_slot %s - retry once it has passed or pass --no-min-time-to-leader-slot",
+			timeToNextLeaderSlot.Round(time.Second),
+			minTimeToLeaderSlot,
 		)
 	}
+	return nil
+}
 
-	v.TowerFile = filepath.Join(towerDir, towerFileNameBuf.String())
-	v.logger.Debug().
+// setIdentityLocally runs the set identity command for role and waits for gossip to report the identity for this node
+func (v *Validator) setIdentityLocally(role, command string, identity *identities.Identity, dryRun bool) (err error) {
+	pubkey := identity.PubKey()
+	dryRunPrefix := " "
+	if dryRun {
+		dryRunPrefix = " (dry run) "
+	}
+	renderRole := style.RenderPassiveString
+	if role == constants.NodeRoleActive {
+		renderRole = style.RenderActiveString
+	}
+
+	log.Info().
+		Str("command", command).
 		Str("tower_file", v.TowerFile).
-		Msg("tower file set")
+		Msgf("👉%sSetting identity to %s - %s", dryRunPrefix, renderRole(strings.ToUpper(role), false), renderRole(pubkey, false))
 
-	return nil
+	err = utils.RunCommand(utils.RunCommandParams{
+		CommandSlice: strings.Split(command, " "),
+		Stdin:        identity.DecryptedKeypair(),
+		DryRun:       dryRun,
+		LogDebug:     log.Debug().Enabled(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set identity to %s: %w", role, err)
+	}
+
+	if dryRun {
+		log.Info().Msg("Dry run complete - no identity was changed, re-run with --not-a-drill to swap for real")
+		return nil
+	}
+
+	// the identity changed on purpose - record it so it isn't mistaken for an accidental one
+	v.recordRoleIntent(role, pubkey)
+
+	return v.waitForGossipPubkey(pubkey, localSwapGossipTimeout)
 }
 
-// configureSetIdenttiyCommands ensures the set identity commands are valid and sets them
-func (v *Validator) configureSetIdenttiyCommands(cfg FailoverConfig) (err error) {
-	var (
-		setIdentityActiveCmdBuf  strings.Builder
-		setIdentityPassiveCmdBuf strings.Builder
-	)
+// recordRoleIntent records role as the one this node should hold after a swap
+func (v *Validator) recordRoleIntent(role, pubkey string) {
+	if v.RoleIntent == nil {
+		return
+	}
+	err := v.RoleIntent.Write(roleintent.Intent{
+		Role:     role,
+		Pubkey:   pubkey,
+		Hostname: v.Hostname,
+		Reason:   roleIntentReasonSwap,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("failed to record role intent")
+		return
+	}
+	log.Debug().Str("path", v.RoleIntent.Path()).Str("role", role).Msg("recorded role intent")
+}
 
-	// parse active command template
-	setIdentityActiveCmdTemplate, err := template.New("set_identity_active_cmd").
-		Parse(cfg.SetIdentityActiveCmdTemplate)
+// gossipRole returns the role this node holds by its gossip identity - unknown when it matches neither identity
+func (v *Validator) gossipRole() string {
+	switch {
+	case v.IsActive():
+		return constants.NodeRoleActive
+	case v.IsPassive():
+		return constants.NodeRolePassive
+	}
+	return constants.NodeRoleUnknown
+}
+
+// roleIntentDivergence returns how this node's gossip role differs from the role it was last given by a failover or
+// swap - empty when it matches or none has been recorded
+func (v *Validator) roleIntentDivergence() (divergence string, err error) {
+	intent, ok, err := v.RoleIntent.Read()
+	if err != nil || !ok {
+		return "", err
+	}
+	return intent.Divergence(v.gossipRole(), v.GossipNode.PubKey()), nil
+}
+
+// warnRoleIntentDivergence warns loudly when this node's gossip role isn't the one it was last given and returns the
+// divergence - previous is the divergence already warned about, which isn't warned about again
+func (v *Validator) warnRoleIntentDivergence(previous string) (divergence string) {
+	divergence, err := v.roleIntentDivergence()
 	if err != nil {
-		return fmt.Errorf(
-			"failed to parse set identity active cmd template %s: %w",
-			cfg.SetIdentityActiveCmdTemplate,
-			err,
-		)
+		log.Warn().Err(err).Msg("failed to check role intent")
+		return previous
 	}
-	v.logger.Debug().
-		Str("template", cfg.SetIdentityActiveCmdTemplate).
-		Msg("set identity active command template set")
+	switch {
+	case divergence != "" && divergence != previous:
+		log.Warn().Str("role_intent_file", v.RoleIntent.Path()).Msgf("⚠️ Unexpected role: %s", divergence)
+	case divergence == "" && previous != "":
+		log.Info().Str("role", v.gossipRole()).Msg("✅ Role matches role intent again")
+	}
+	return divergence
+}
 
-	// set identity active command must compile
-	if err := setIdentityActiveCmdTemplate.Execute(&setIdentityActiveCmdBuf, v); err != nil {
-		return fmt.Errorf(
-			"failed to execute set identity active cmd template %s: %w",
-			cfg.SetIdentityActiveCmdTemplate,
-			err,
-		)
+// waitForGossipPubkey waits for gossip to report pubkey for this node - a timeout is only warned about as the identity
+// has already been set
+func (v *Validator) waitForGossipPubkey(pubkey string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		// a cached gossip snapshot would only show the identity from before the swap
+		v.solanaRPCClient.InvalidateClusterNodes()
+		err := v.GossipNode.Refresh(v.solanaRPCClient)
+		if err == nil && v.GossipNode.PubKey() == pubkey {
+			log.Info().Str("pubkey", pubkey).Msg("🟢 Gossip confirms identity swap")
+			return nil
+		}
+		if time.Now().After(deadline) {
+			log.Warn().
+				Str("expected_pubkey", pubkey).
+				Str("gossip_pubkey", v.GossipNode.PubKey()).
+				Dur("timeout", timeout).
+				Msg("gossip has not reflected the identity swap yet - check the validator")
+			return nil
+		}
+		time.Sleep(2 * time.Second)
 	}
+}
 
-	// set identity active command
-	v.SetIdentityActiveCommand = setIdentityActiveCmdBuf.String()
-	v.logger.Debug().
-		Str("command", v.SetIdentityActiveCommand).
-		Msg("set identity active command set")
+// checkActiveIdentityBalance ensures the active identity holds at least the minimum balance to pay vote fees
+func (v *Validator) checkActiveIdentityBalance() (err error) {
+	if v.MinActiveIdentityBalance == 0 {
+		log.Debug().Msg("validator.failover.min_active_identity_balance_lamports is 0, skipping active identity balance check")
+		return nil
+	}
 
-	// parse passive command template
-	setIdentityPassiveCmdTemplate, err := template.New("set_identity_passive_cmd").
-		Parse(cfg.SetIdentityPassiveCmdTemplate)
+	balance, err := v.solanaRPCClient.GetBalance(v.Identities.Active.PublicKey())
 	if err != nil {
+		return fmt.Errorf("failed to check active identity balance: %w", err)
+	}
+
+	log.Debug().
+		Str("pubkey", v.Identities.Active.PubKey()).
+		Uint64("balance_lamports", balance).
+		Uint64("min_balance_lamports", v.MinActiveIdentityBalance).
+		Msg("active identity balance")
+
+	if balance < v.MinActiveIdentityBalance {
 		return fmt.Errorf(
-			"failed to parse set identity passive cmd template %s: %w",
-			cfg.SetIdentityPassiveCmdTemplate,
-			err,
+			"active identity %s holds %d lamports, less than validator.failover.min_active_identity_balance_lamports %d - fund it before failing over or it will stop voting once it runs out",
+			v.Identities.Active.PubKey(),
+			balance,
+			v.MinActiveIdentityBalance,
 		)
 	}
-	v.logger.Debug().
-		Str("template", cfg.SetIdentityPassiveCmdTemplate).
-		Msg("set identity passive command template set")
 
-	// set identity passive command must compile
-	if err := setIdentityPassiveCmdTemplate.Execute(&setIdentityPassiveCmdBuf, v); err != nil {
+	return nil
+}
+
+// configureRPCClient configures the solana rpc client
+func (v *Validator) configureRPCClient(localRPCURL, solanaClusterName string) error {
+	// configure solana rpc clients all in one
+	err := utils.ValidateCluster(solanaClusterName)
+	if err != nil {
+		return err
+	}
+
+	if !utils.IsValidURLWithPort(localRPCURL) {
 		return fmt.Errorf(
-			"failed to execute set identity passive cmd template %s: %w",
-			cfg.SetIdentityPassiveCmdTemplate,
-			err,
+			"invalid rpc address: %s, must be a valid url with a port",
+			localRPCURL,
 		)
 	}
-	v.SetIdentityPassiveCommand = setIdentityPassiveCmdBuf.String()
-	v.logger.Debug().
-		Str("command", v.SetIdentityPassiveCommand).
-		Msg("set identity passive command set")
 
-	// if the commands are the same, warn - could be intentional or a mistake
-	if v.SetIdentityActiveCommand == v.SetIdentityPassiveCommand {
-		log.Warn().
-			Msg("set identity active and passive commands are the same - this could be intentional or a mistake")
+	solanaClusterRPCURL := constants.SolanaClusters[solanaClusterName].RPC
+
+	networkRPCURLs := make([]string, 0, len(v.networkRPC.NetworkRPCURLs))
+	for _, networkRPCURL := range v.networkRPC.NetworkRPCURLs {
+		networkRPCURLs = append(networkRPCURLs, utils.RedactURL(networkRPCURL))
 	}
 
+	v.logger.Debug().
+		Str("cluster", solanaClusterName).
+		Str("local_rpc_url", localRPCURL).
+		Str("network_rpc_url", solanaClusterRPCURL).
+		Strs("network_rpc_urls", networkRPCURLs).
+		Msg("rpc client configured")
+
+	v.solanaRPCClient = v.NewSolanaRPCClient(solana.NewClientParams{
+		LocalRPCURL:                 localRPCURL,
+		NetworkRPCURL:               solanaClusterRPCURL,
+		NetworkRPCURLs:              v.networkRPC.NetworkRPCURLs,
+		NetworkRPCTimeout:           v.networkRPC.NetworkRPCTimeout,
+		NetworkRPCUnhealthyCooldown: v.networkRPC.NetworkRPCUnhealthyCooldown,
+		Retry:                       v.networkRPC.Retry,
+		ClusterNodesCacheTTL:        v.networkRPC.ClusterNodesCacheTTL,
+		DebugLogSampleInterval:      v.debugLogSampleInterval,
+	})
+
 	return nil
 }
 
-// configureHooks ensures the hooks are valid and sets them
-func (v *Validator) configureHooks(cfg FailoverConfig) (err error) {
-	v.Hooks = cfg.Hooks
+// configureNetworkRPC sets the network rpc urls tried before the cluster's public rpc and how they fail over
+func (v *Validator) configureNetworkRPC(cfg NetworkRPCConfig) (err error) {
+	v.networkRPC = solana.NewClientParams{}
+	for _, networkRPCURL := range cfg.URLs {
+		parsedURL, err := url.Parse(networkRPCURL)
+		if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+			return fmt.Errorf("invalid network_rpc.urls entry %s, must be an http(s) url", utils.RedactURL(networkRPCURL))
+		}
+		v.networkRPC.NetworkRPCURLs = append(v.networkRPC.NetworkRPCURLs, networkRPCURL)
+	}
+
+	v.networkRPC.NetworkRPCHeaders, err = networkRPCHeaders(cfg.Auth, v.networkRPC.NetworkRPCURLs)
+	if err != nil {
+		return err
+	}
+
+	v.networkRPC.NetworkRPCTimeout = solana.DefaultNetworkRPCTimeout
+	if cfg.Timeout != "" {
+		v.networkRPC.NetworkRPCTimeout, err = time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return fmt.Errorf("failed to parse network_rpc.timeout %s: %w", cfg.Timeout, err)
+		}
+		if v.networkRPC.NetworkRPCTimeout <= 0 {
+			return fmt.Errorf("network_rpc.timeout must be positive, got %s", cfg.Timeout)
+		}
+	}
+
+	v.networkRPC.NetworkRPCUnhealthyCooldown = solana.DefaultNetworkRPCCooldown
+	if cfg.UnhealthyCooldown != "" {
+		v.networkRPC.NetworkRPCUnhealthyCooldown, err = time.ParseDuration(cfg.UnhealthyCooldown)
+		if err != nil {
+			return fmt.Errorf("failed to parse network_rpc.unhealthy_cooldown %s: %w", cfg.UnhealthyCooldown, err)
+		}
+		if v.networkRPC.NetworkRPCUnhealthyCooldown < 0 {
+			return fmt.Errorf("network_rpc.unhealthy_cooldown must not be negative, got %s", cfg.UnhealthyCooldown)
+		}
+	}
+
+	v.networkRPC.Retry, err = networkRPCRetryConfig(cfg.Retry)
+	if err != nil {
+		return err
+	}
+
+	v.networkRPC.ClusterNodesCacheTTL = solana.DefaultClusterNodesCacheTTL
+	if cfg.ClusterNodesCacheTTL != "" {
+		v.networkRPC.ClusterNodesCacheTTL, err = time.ParseDuration(cfg.ClusterNodesCacheTTL)
+		if err != nil {
+			return fmt.Errorf("failed to parse network_rpc.cluster_nodes_cache_ttl %s: %w", cfg.ClusterNodesCacheTTL, err)
+		}
+		if v.networkRPC.ClusterNodesCacheTTL < 0 {
+			return fmt.Errorf("network_rpc.cluster_nodes_cache_ttl must not be negative, got %s", cfg.ClusterNodesCacheTTL)
+		}
+	}
+
 	v.logger.Debug().
-		Interface("hooks", v.Hooks).
-		Msg("hooks set")
+		Int("network_rpc_urls", len(v.networkRPC.NetworkRPCURLs)).
+		Int("network_rpc_urls_with_auth", len(v.networkRPC.NetworkRPCHeaders)).
+		Dur("network_rpc_timeout", v.networkRPC.NetworkRPCTimeout).
+		Dur("network_rpc_unhealthy_cooldown", v.networkRPC.NetworkRPCUnhealthyCooldown).
+		Int("network_rpc_retry_max_attempts", v.networkRPC.Retry.MaxAttempts).
+		Dur("network_rpc_retry_base_delay", v.networkRPC.Retry.BaseDelay).
+		Dur("network_rpc_retry_max_delay", v.networkRPC.Retry.MaxDelay).
+		Float64("network_rpc_retry_jitter", v.networkRPC.Retry.Jitter).
+		Dur("network_rpc_cluster_nodes_cache_ttl", v.networkRPC.ClusterNodesCacheTTL).
+		Msg("network rpc configured")
 	return nil
 }
 
-// configurePeers ensures the peers are valid and sets them
-func (v *Validator) configurePeers(cfg PeersConfig) (err error) {
-	if len(cfg) == 0 {
-		return fmt.Errorf("must have at least one peer")
+// networkRPCHeaders returns the http headers sent to each network rpc url from its auth config - credentials are only
+// accepted for configured urls so a typo doesn't silently send calls unauthenticated
+func networkRPCHeaders(auths []NetworkRPCAuthConfig, urls []string) (headers map[string]map[string]string, err error) {
+	for _, auth := range auths {
+		redactedURL := utils.RedactURL(auth.URL)
+		if !slices.Contains(urls, auth.URL) {
+			return nil, fmt.Errorf("network_rpc.auth url %s is not one of network_rpc.urls", redactedURL)
+		}
+		if _, ok := headers[auth.URL]; ok {
+			return nil, fmt.Errorf("network_rpc.auth url %s is declared more than once", redactedURL)
+		}
+		if auth.BearerToken == "" && len(auth.Headers) == 0 {
+			return nil, fmt.Errorf("network_rpc.auth for %s must set bearer_token or headers", redactedURL)
+		}
+
+		urlHeaders := make(map[string]string, len(auth.Headers)+1)
+		for name, value := range auth.Headers {
+			if strings.TrimSpace(name) == "" {
+				return nil, fmt.Errorf("network_rpc.auth for %s has a header with an empty name", redactedURL)
+			}
+			urlHeaders[http.CanonicalHeaderKey(name)] = value
+		}
+		if auth.BearerToken != "" {
+			if _, ok := urlHeaders["Authorization"]; ok {
+				return nil, fmt.Errorf("network_rpc.auth for %s sets both bearer_token and an Authorization header - set one", redactedURL)
+			}
+			urlHeaders["Authorization"] = "Bearer " + auth.BearerToken
+		}
+
+		if headers == nil {
+			headers = make(map[string]map[string]string)
+		}
+		headers[auth.URL] = urlHeaders
 	}
+	return headers, nil
+}
 
-	v.Peers = make(Peers)
-	for name, peer := range cfg {
-		if !utils.IsValidURLWithPort(peer.Address) {
-			return fmt.Errorf(
-				"invalid peer address %s for peer %s - must be a valid url with a port",
-				peer.Address,
-				name,
-			)
+// networkRPCRetryConfig parses how failed network rpc calls are retried - unset values take the solana client's
+// defaults
+func networkRPCRetryConfig(cfg NetworkRPCRetryConfig) (retry solana.RetryConfig, err error) {
+	retry = solana.RetryConfig{
+		MaxAttempts: solana.DefaultRetryMaxAttempts,
+		BaseDelay:   solana.DefaultRetryBaseDelay,
+		MaxDelay:    solana.DefaultRetryMaxDelay,
+		Jitter:      cfg.Jitter,
+	}
+
+	if cfg.MaxAttempts < 0 {
+		return retry, fmt.Errorf("network_rpc.retry.max_attempts must not be negative, got %d", cfg.MaxAttempts)
+	}
+	if cfg.MaxAttempts > 0 {
+		retry.MaxAttempts = cfg.MaxAttempts
+	}
+
+	if cfg.BaseDelay != "" {
+		retry.BaseDelay, err = time.ParseDuration(cfg.BaseDelay)
+		if err != nil {
+			return retry, fmt.Errorf("failed to parse network_rpc.retry.base_delay %s: %w", cfg.BaseDelay, err)
 		}
-		v.Peers[name] = Peer{
-			Name:    name,
-			Address: peer.Address,
+		if retry.BaseDelay < 0 {
+			return retry, fmt.Errorf("network_rpc.retry.base_delay must not be negative, got %s", cfg.BaseDelay)
 		}
-		log.Debug().
-			Str("name", name).
-			Str("address", peer.Address).
-			Msg("registered peer")
 	}
 
+	if cfg.MaxDelay != "" {
+		retry.MaxDelay, err = time.ParseDuration(cfg.MaxDelay)
+		if err != nil {
+			return retry, fmt.Errorf("failed to parse network_rpc.retry.max_delay %s: %w", cfg.MaxDelay, err)
+		}
+		if retry.MaxDelay < retry.BaseDelay {
+			return retry, fmt.Errorf("network_rpc.retry.max_delay %s must not be less than base_delay %s", retry.MaxDelay, retry.BaseDelay)
+		}
+	}
+
+	if cfg.Jitter < 0 || cfg.Jitter > 1 {
+		return retry, fmt.Errorf("network_rpc.retry.jitter must be between 0 and 1, got %g", cfg.Jitter)
+	}
+
+	return retry, nil
+}
+
+// configureDebugLogSampling sets the minimum interval between repeated debug logs on high-volume rpc paths - 0 logs
+// every occurrence
+func (v *Validator) configureDebugLogSampling(interval string) (err error) {
+	if interval == "" {
+		v.debugLogSampleInterval = 0
+		return nil
+	}
+	v.debugLogSampleInterval, err = time.ParseDuration(interval)
+	if err != nil {
+		return fmt.Errorf("failed to parse debug_log_sample_interval %s: %w", interval, err)
+	}
+	if v.debugLogSampleInterval < 0 {
+		return fmt.Errorf("debug_log_sample_interval must not be negative, got %s", interval)
+	}
+	v.logger.Debug().
+		Dur("debug_log_sample_interval", v.debugLogSampleInterval).
+		Msg("debug log sampling configured")
+	return nil
+}
+
+// configureDeprecationWarnings silences deprecation notices when asked - they can also be silenced from the
+// environment, which config doesn't override
+func (v *Validator) configureDeprecationWarnings(silence bool) {
+	if silence {
+		deprecation.SetSilenced(true)
+	}
+	v.logger.Debug().
+		Bool("silenced", deprecation.Silenced()).
+		Msg("deprecation warnings configured")
+}
+
+// configureBin ensures the validator binary exists and sets it
+func (v *Validator) configureBin(bin string) error {
+	err := utils.EnsureBins(bin)
+	if err != nil {
+		return err
+	}
+	v.Bin = bin
+	v.logger.Debug().
+		Str("bin", v.Bin).
+		Msg("validator binary set")
 	return nil
 }
 
-// GetPublicIP returns the public IP address - can be overridden in tests
-func (v *Validator) GetPublicIP() (string, error) {
-	return utils.GetPublicIP()
+// configureBinMetadata detects the validator client and its version by running the binary with --version - when that
+// fails the client is detected from the binary name alone. an unknown client only matters when set identity commands
+// are left to be chosen for it
+func (v *Validator) configureBinMetadata() {
+	ctx, cancel := context.WithTimeout(context.Background(), binVersionTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, v.Bin, "--version").Output()
+	if err != nil {
+		v.logger.Debug().Err(err).Str("bin", v.Bin).Msg("failed to get validator binary version")
+		output = nil
+	}
+
+	v.BinMetadata = parseBinMetadata(v.Bin, string(output))
+	v.logger.Debug().
+		Str("client", v.BinMetadata.Client).
+		Str("version", v.BinMetadata.Version).
+		Msg("validator client detected")
 }
 
-// configurePublicIP ensures the public ip is valid and sets it
-func (v *Validator) configurePublicIP(publicIP string) (err error) {
-	if publicIP != "" {
-		v.PublicIP = publicIP
-		v.logger.Debug().
-			Str("public_ip", v.PublicIP).
-			Msg("public ip set in config - not recommended and actually a dirty hack for testing, likely to break and/or be removed in the future")
+// parseBinMetadata detects the validator client and its version from the binary name and its --version output
+func parseBinMetadata(bin, versionOutput string) BinMetadata {
+	metadata := BinMetadata{
+		Client:  constants.ClientTypeUnknown,
+		Version: binVersionPattern.FindString(versionOutput),
+	}
+
+	binName := strings.ToLower(filepath.Base(bin))
+	output := strings.ToLower(versionOutput)
+	switch {
+	case binName == "fdctl" || binName == "fddev" || strings.Contains(output, "firedancer") || strings.Contains(output, "fdctl"):
+		metadata.Client = constants.ClientTypeFiredancer
+	case strings.Contains(output, "client:jitolabs") || strings.Contains(output, "jito"):
+		metadata.Client = constants.ClientTypeJitoAgave
+	case strings.Contains(output, "agave") || strings.Contains(output, "solana-validator"):
+		metadata.Client = constants.ClientTypeAgave
+	}
+
+	return metadata
+}
+
+// configureFiredancerConfig resolves the firedancer config.toml path when one is set - it must exist
+func (v *Validator) configureFiredancerConfig(firedancerConfig string) error {
+	if firedancerConfig == "" {
 		return nil
 	}
 
-	v.PublicIP, err = v.GetPublicIP()
-	if err != nil {
-		return err
+	resolved, err := utils.ResolvePath(firedancerConfig)
+	if err != nil {
+		return fmt.Errorf("invalid firedancer_config %s: %w", firedancerConfig, err)
+	}
+	if !utils.FileExists(resolved) {
+		return fmt.Errorf("firedancer_config %s does not exist", resolved)
+	}
+
+	v.FiredancerConfig = resolved
+	v.logger.Debug().
+		Str("firedancer_config", v.FiredancerConfig).
+		Msg("firedancer config set")
+	return nil
+}
+
+// configureLedgerDir ensures the ledger directory exists
+func (v *Validator) configureLedgerDir(ledgerDir string) error {
+	ledgerDir, err := utils.ResolveAndValidateDir(ledgerDir)
+	if err != nil {
+		return err
+	}
+	v.LedgerDir = ledgerDir
+	v.logger.Debug().
+		Str("ledger_dir", v.LedgerDir).
+		Msg("ledger dir set")
+	return nil
+}
+
+// configureIdentities ensures the identities are valid and sets them
+func (v *Validator) configureIdentities(identitiesConfig identities.Config) (err error) {
+	v.Identities, err = identities.NewFromConfig(&identitiesConfig)
+	if err != nil {
+		return err
+	}
+
+	v.logger.Debug().
+		Str("active_pubkey", v.Identities.Active.PubKey()).
+		Str("active_keyfile", v.Identities.Active.KeyFile).
+		Bool("active_remote", v.Identities.Active.IsRemote()).
+		Str("passive_pubkey", v.Identities.Passive.PubKey()).
+		Str("passive_keyfile", v.Identities.Passive.KeyFile).
+		Bool("passive_remote", v.Identities.Passive.IsRemote()).
+		Msg("identities set")
+
+	return nil
+}
+
+// configureTowerFile ensures the tower file is valid and sets it
+func (v *Validator) configureTowerFile(cfg TowerConfig) error {
+	v.TowerFileAutoDeleteWhenPassive = cfg.AutoEmptyWhenPassive
+	v.logger.Debug().
+		Bool("tower_file_auto_delete_when_passive", v.TowerFileAutoDeleteWhenPassive).
+		Msg("tower file auto delete when passive set")
+
+	// drift monitor cleanup follows the auto empty when passive policy
+	if cfg.DriftMonitor.AutoClean && !cfg.AutoEmptyWhenPassive {
+		return fmt.Errorf("tower.drift_monitor.auto_clean requires tower.auto_empty_when_passive to be true")
+	}
+	if cfg.DriftMonitor.Enabled && cfg.DriftMonitor.Interval != "" {
+		if _, err := time.ParseDuration(cfg.DriftMonitor.Interval); err != nil {
+			return fmt.Errorf("failed to parse tower.drift_monitor.interval %s: %w", cfg.DriftMonitor.Interval, err)
+		}
+	}
+	v.TowerDriftMonitor = cfg.DriftMonitor
+	v.logger.Debug().
+		Bool("enabled", v.TowerDriftMonitor.Enabled).
+		Str("interval", v.TowerDriftMonitor.Interval).
+		Bool("auto_clean", v.TowerDriftMonitor.AutoClean).
+		Msg("tower drift monitor set")
+
+	// tower dir must exist
+	towerDir, err := utils.ResolveAndValidateDir(cfg.Dir)
+	if err != nil {
+		return err
+	}
+
+	// backups go next to the tower file unless a dir is set - it must exist
+	if cfg.Backup.Retention < 0 {
+		return fmt.Errorf("tower.backup.retention must not be negative: %d", cfg.Backup.Retention)
+	}
+	v.TowerBackup = cfg.Backup
+	if cfg.Backup.Enabled && cfg.Backup.Dir != "" {
+		v.TowerBackup.Dir, err = utils.ResolveAndValidateDir(cfg.Backup.Dir)
+		if err != nil {
+			return fmt.Errorf("invalid tower.backup.dir: %w", err)
+		}
+	}
+	v.logger.Debug().
+		Bool("enabled", v.TowerBackup.Enabled).
+		Str("dir", v.TowerBackup.Dir).
+		Int("retention", v.TowerBackup.Retention).
+		Msg("tower backup set")
+
+	v.TowerSanityCheck = cfg.SanityCheck
+	v.logger.Debug().
+		Bool("tower_sanity_check", v.TowerSanityCheck).
+		Msg("tower sanity check set")
+
+	// tower file name template must be valid
+	towerFileNameTemplate, err := template.New("tower").Parse(cfg.FileNameTemplate)
+	if err != nil {
+		return fmt.Errorf(
+			"failed to parse file name template %s: %w",
+			cfg.FileNameTemplate,
+			err,
+		)
+	}
+	v.logger.Debug().
+		Str("template", cfg.FileNameTemplate).
+		Msg("tower file name template set")
+
+	// tower file name template must compile
+	var towerFileNameBuf strings.Builder
+	if err := towerFileNameTemplate.Execute(&towerFileNameBuf, v); err != nil {
+		return fmt.Errorf(
+			"failed to execute file name template %s: %w",
+			cfg.FileNameTemplate,
+			err,
+		)
+	}
+
+	v.TowerFile = filepath.Join(towerDir, towerFileNameBuf.String())
+	v.logger.Debug().
+		Str("tower_file", v.TowerFile).
+		Msg("tower file set")
+
+	// the replica is kept next to the tower file unless a dir is set - it must exist
+	v.TowerReplication = cfg.Replication
+	if cfg.Replication.Enabled {
+		v.towerReplicaInterval, err = time.ParseDuration(cfg.Replication.Interval)
+		if err != nil {
+			return fmt.Errorf("failed to parse tower.replication.interval %s: %w", cfg.Replication.Interval, err)
+		}
+		if v.towerReplicaInterval <= 0 {
+			return fmt.Errorf("tower.replication.interval must be positive: %s", cfg.Replication.Interval)
+		}
+		replicaDir := towerDir
+		if cfg.Replication.Dir != "" {
+			replicaDir, err = utils.ResolveAndValidateDir(cfg.Replication.Dir)
+			if err != nil {
+				return fmt.Errorf("invalid tower.replication.dir: %w", err)
+			}
+		}
+		v.TowerReplication.Dir = replicaDir
+		v.TowerReplicaFile = filepath.Join(replicaDir, filepath.Base(v.TowerFile)+failover.TowerReplicaSuffix)
+	}
+	v.logger.Debug().
+		Bool("enabled", v.TowerReplication.Enabled).
+		Dur("interval", v.towerReplicaInterval).
+		Str("replica_file", v.TowerReplicaFile).
+		Msg("tower replication set")
+
+	return nil
+}
+
+// configureSetIdenttiyCommands ensures the set identity commands are valid and sets them - templates left empty are
+// the built-in ones for the detected client
+func (v *Validator) configureSetIdenttiyCommands(cfg FailoverConfig) (err error) {
+	var (
+		setIdentityActiveCmdBuf  strings.Builder
+		setIdentityPassiveCmdBuf strings.Builder
+	)
+
+	if cfg.SetIdentityActiveCmdTemplate == "" || cfg.SetIdentityPassiveCmdTemplate == "" {
+		builtIn, err := v.builtInSetIdentityCmdTemplates()
+		if err != nil {
+			return err
+		}
+		if cfg.SetIdentityActiveCmdTemplate == "" {
+			cfg.SetIdentityActiveCmdTemplate = builtIn.Active
+			if v.Identities.Active.Encrypted && builtIn.ActiveFromStdin != "" {
+				cfg.SetIdentityActiveCmdTemplate = builtIn.ActiveFromStdin
+			}
+		}
+		if cfg.SetIdentityPassiveCmdTemplate == "" {
+			cfg.SetIdentityPassiveCmdTemplate = builtIn.Passive
+			if v.Identities.Passive.Encrypted && builtIn.PassiveFromStdin != "" {
+				cfg.SetIdentityPassiveCmdTemplate = builtIn.PassiveFromStdin
+			}
+		}
+	}
+
+	// an identity a remote signer holds has no keypair file to pass set identity...
+	for _, remote := range []struct {
+		key, template, field string
+		identity             *identities.Identity
+	}{
+		{"failover.set_identity_active_cmd_template", cfg.SetIdentityActiveCmdTemplate, ".Identities.Active.KeyFile", v.Identities.Active},
+		{"failover.set_identity_passive_cmd_template", cfg.SetIdentityPassiveCmdTemplate, ".Identities.Passive.KeyFile", v.Identities.Passive},
+	} {
+		if remote.identity.IsRemote() && strings.Contains(remote.template, remote.field) {
+			return fmt.Errorf("%s uses %s but a remote signer holds that identity - set a command that gets the key from the signer", remote.key, remote.field)
+		}
+		// nor can set identity read an encrypted one - it gets the decrypted keypair on stdin instead
+		if remote.identity.Encrypted && strings.Contains(remote.template, remote.field) {
+			return fmt.Errorf("%s uses %s but that keypair file is encrypted - set a command that reads the keypair from stdin", remote.key, remote.field)
+		}
+	}
+
+	// parse active command template
+	setIdentityActiveCmdTemplate, err := template.New("set_identity_active_cmd").
+		Parse(cfg.SetIdentityActiveCmdTemplate)
+	if err != nil {
+		return fmt.Errorf(
+			"failed to parse set identity active cmd template %s: %w",
+			cfg.SetIdentityActiveCmdTemplate,
+			err,
+		)
+	}
+	v.logger.Debug().
+		Str("template", cfg.SetIdentityActiveCmdTemplate).
+		Msg("set identity active command template set")
+
+	// set identity active command must compile
+	if err := setIdentityActiveCmdTemplate.Execute(&setIdentityActiveCmdBuf, v); err != nil {
+		return fmt.Errorf(
+			"failed to execute set identity active cmd template %s: %w",
+			cfg.SetIdentityActiveCmdTemplate,
+			err,
+		)
+	}
+
+	// set identity active command
+	v.SetIdentityActiveCommand = setIdentityActiveCmdBuf.String()
+	v.logger.Debug().
+		Str("command", v.SetIdentityActiveCommand).
+		Msg("set identity active command set")
+
+	// parse passive command template
+	setIdentityPassiveCmdTemplate, err := template.New("set_identity_passive_cmd").
+		Parse(cfg.SetIdentityPassiveCmdTemplate)
+	if err != nil {
+		return fmt.Errorf(
+			"failed to parse set identity passive cmd template %s: %w",
+			cfg.SetIdentityPassiveCmdTemplate,
+			err,
+		)
+	}
+	v.logger.Debug().
+		Str("template", cfg.SetIdentityPassiveCmdTemplate).
+		Msg("set identity passive command template set")
+
+	// set identity passive command must compile
+	if err := setIdentityPassiveCmdTemplate.Execute(&setIdentityPassiveCmdBuf, v); err != nil {
+		return fmt.Errorf(
+			"failed to execute set identity passive cmd template %s: %w",
+			cfg.SetIdentityPassiveCmdTemplate,
+			err,
+		)
+	}
+	v.SetIdentityPassiveCommand = setIdentityPassiveCmdBuf.String()
+	v.logger.Debug().
+		Str("command", v.SetIdentityPassiveCommand).
+		Msg("set identity passive command set")
+
+	// if the commands are the same, warn - could be intentional or a mistake
+	if v.SetIdentityActiveCommand == v.SetIdentityPassiveCommand {
+		log.Warn().
+			Msg("set identity active and passive commands are the same - this could be intentional or a mistake")
+	}
+
+	return nil
+}
+
+// builtInSetIdentityCmdTemplates returns the built-in set identity command templates for the detected client
+func (v *Validator) builtInSetIdentityCmdTemplates() (setIdentityCmdTemplates, error) {
+	templates, ok := builtInSetIdentityCmdTemplates[v.BinMetadata.Client]
+	if !ok {
+		v.logger.Warn().
+			Str("bin", v.Bin).
+			Msg("validator client unknown - using agave set identity commands, set failover.set_identity_*_cmd_template if they don't suit it")
+		return agaveSetIdentityCmdTemplates, nil
+	}
+
+	if v.BinMetadata.Client == constants.ClientTypeFiredancer && v.FiredancerConfig == "" {
+		return templates, fmt.Errorf("firedancer_config is required for firedancer's set identity commands - set it or failover.set_identity_*_cmd_template")
+	}
+
+	v.logger.Debug().
+		Str("client", v.BinMetadata.Client).
+		Msg("using built-in set identity commands")
+	return templates, nil
+}
+
+// configureHooks ensures the hooks are valid and sets them
+func (v *Validator) configureHooks(cfg FailoverConfig) (err error) {
+	if err := cfg.Hooks.Validate("failover.hooks"); err != nil {
+		return err
+	}
+	v.Hooks = cfg.Hooks
+	// hook templates see what set identity command templates do besides the env variables hooks get - identities only
+	// by pubkey and keypair file so a template can't render a key into argv, a request or the logs
+	v.Hooks.TemplateContext = map[string]any{
+		"Bin":              v.Bin,
+		"FiredancerConfig": v.FiredancerConfig,
+		"Identities":       v.Identities.Public(),
+		"LedgerDir":        v.LedgerDir,
+		"TowerFile":        v.TowerFile,
+	}
+	v.logger.Debug().
+		Interface("hooks", v.Hooks).
+		Msg("hooks set")
+	return nil
+}
+
+// configurePeers ensures the peers are valid and sets them
+func (v *Validator) configurePeers(cfg PeersConfig) (err error) {
+	if len(cfg) == 0 {
+		return fmt.Errorf("must have at least one peer")
+	}
+
+	v.peerProbe = func(address string) error {
+		return failover.ProbePeer(address, v.ClientCertificate)
+	}
+	v.Peers = make(Peers)
+	for name, peer := range cfg {
+		if peer.Discover {
+			discoverPeer, err := discoverPeerConfig(name, peer)
+			if err != nil {
+				return err
+			}
+			v.Peers[name] = discoverPeer
+			log.Debug().
+				Str("name", name).
+				Str("passive_pubkey", peer.PassivePubkey).
+				Int("port", discoverPeer.DiscoverPort).
+				Msg("registered peer to discover in gossip")
+			continue
+		}
+
+		address := peer.Address
+
+		// port override replaces (or supplies) the port in the address
+		if peer.Port != 0 {
+			host := utils.HostFromAddress(address)
+			if host == "" {
+				return fmt.Errorf("invalid peer address %s for peer %s - must have a valid host", peer.Address, name)
+			}
+			address = net.JoinHostPort(host, strconv.Itoa(peer.Port))
+		}
+
+		if !utils.IsValidURLWithPort(address) {
+			return fmt.Errorf(
+				"invalid peer address %s for peer %s - must be a valid url with a port",
+				address,
+				name,
+			)
+		}
+
+		// expected gossip ip defaults to the address host when it is an IP
+		gossipIP := peer.GossipIP
+		if gossipIP == "" {
+			if host := utils.HostFromAddress(address); net.ParseIP(host) != nil {
+				gossipIP = host
+			}
+		}
+		if gossipIP != "" && net.ParseIP(gossipIP) == nil {
+			return fmt.Errorf("invalid gossip_ip %s for peer %s - must be a valid IP address", gossipIP, name)
+		}
+
+		if peer.PassivePubkey != "" {
+			if _, err := solanago.PublicKeyFromBase58(peer.PassivePubkey); err != nil {
+				return fmt.Errorf("invalid passive_pubkey %s for peer %s: %w", peer.PassivePubkey, name, err)
+			}
+		}
+
+		v.Peers[name] = Peer{
+			Name:          name,
+			Address:       address,
+			GossipIP:      gossipIP,
+			PassivePubkey: peer.PassivePubkey,
+			Priority:      peer.Priority,
+		}
+		log.Debug().
+			Str("name", name).
+			Str("address", address).
+			Str("gossip_ip", gossipIP).
+			Str("passive_pubkey", peer.PassivePubkey).
+			Msg("registered peer")
+	}
+
+	return nil
+}
+
+// discoverPeerConfig returns a peer to discover in gossip by its passive pubkey - it must not also configure where it
+// is
+func discoverPeerConfig(name string, peer PeerConfig) (Peer, error) {
+	if peer.PassivePubkey == "" {
+		return Peer{}, fmt.Errorf("peer %s sets discover so needs passive_pubkey to look it up in gossip", name)
+	}
+	if _, err := solanago.PublicKeyFromBase58(peer.PassivePubkey); err != nil {
+		return Peer{}, fmt.Errorf("invalid passive_pubkey %s for peer %s: %w", peer.PassivePubkey, name, err)
+	}
+	if peer.Address != "" || peer.GossipIP != "" {
+		return Peer{}, fmt.Errorf("peer %s sets discover so its address and gossip_ip come from gossip - remove them", name)
+	}
+	port := peer.Port
+	if port == 0 {
+		port = failover.DefaultPort
+	}
+	return Peer{Name: name, PassivePubkey: peer.PassivePubkey, Priority: peer.Priority, DiscoverPort: port}, nil
+}
+
+// discoverPeers looks up peers that set discover in gossip and sets their address and gossip ip from it - a passive
+// peer runs its passive identity, an active one the active identity. Peers that can't be found are left out with a
+// warning so the rest stay usable, it errors only when none are left
+func (v *Validator) discoverPeers() error {
+	for name, peer := range v.Peers {
+		if peer.DiscoverPort == 0 {
+			continue
+		}
+
+		node, err := v.solanaRPCClient.NodeFromPubkey(peer.PassivePubkey)
+		if err != nil {
+			// this node is passive and the peer active - unless this node is the one running the active identity
+			activeNode, activeErr := v.solanaRPCClient.NodeFromPubkey(v.Identities.Active.PubKey())
+			if activeErr != nil || activeNode.IP() == v.PublicIP {
+				log.Warn().
+					Err(err).
+					Str("peer_name", name).
+					Str("passive_pubkey", peer.PassivePubkey).
+					Msgf("peer %s not found in gossip by its passive or the active identity - leaving it out", name)
+				delete(v.Peers, name)
+				continue
+			}
+			node = activeNode
+		}
+
+		peer.GossipIP = node.IP()
+		peer.Address = net.JoinHostPort(node.IP(), strconv.Itoa(peer.DiscoverPort))
+		v.Peers[name] = peer
+		log.Debug().
+			Str("peer_name", name).
+			Str("pubkey", node.PubKey()).
+			Str("address", peer.Address).
+			Msg("discovered peer in gossip")
+	}
+
+	if len(v.Peers) == 0 {
+		return fmt.Errorf("no peers left after discovering peers in gossip")
+	}
+	return nil
+}
+
+// GetPublicIP returns the public IP address - can be overridden in tests
+func (v *Validator) GetPublicIP() (string, error) {
+	return utils.GetPublicIP()
+}
+
+// configurePublicIP ensures the public ip is valid and sets it
+func (v *Validator) configurePublicIP(publicIP string) (err error) {
+	if publicIP != "" {
+		v.PublicIP = publicIP
+		v.publicIPFromConfig = true
+		v.logger.Debug().
+			Str("public_ip", v.PublicIP).
+			Msg("public ip set in config - not recommended and actually a dirty hack for testing, likely to break and/or be removed in the future")
+		return nil
+	}
+
+	v.PublicIP, err = v.GetPublicIP()
+	if err != nil {
+		return err
+	}
+
+	v.logger.Debug().
+		Str("public_ip", v.PublicIP).
+		Msg("public ip set")
+
+	return nil
+}
+
+// configureSwitchCountdown ensures the switch countdown is a valid duration no longer than the maximum and sets it -
+// 0s switches without one
+func (v *Validator) configureSwitchCountdown(countdown string) (err error) {
+	if countdown == "" {
+		v.SwitchCountdown = 0
+		return nil
+	}
+	v.SwitchCountdown, err = time.ParseDuration(countdown)
+	if err != nil {
+		return fmt.Errorf("failed to parse failover.switch_countdown %s: %w", countdown, err)
+	}
+	if v.SwitchCountdown < 0 || v.SwitchCountdown > maxSwitchCountdown {
+		return fmt.Errorf("failover.switch_countdown %s must be between 0s and %s", countdown, maxSwitchCountdown)
+	}
+	v.logger.Debug().Str("switch_countdown", v.SwitchCountdown.String()).Msg("switch countdown set")
+	return nil
+}
+
+// configureMinimumTimeToLeaderSlot ensures the minimum time to leader slot is valid and sets it
+func (v *Validator) configureMinimumTimeToLeaderSlot(timeToLeaderSlotDurationString string) (err error) {
+	minimumTimeToLeaderSlotDuration, err := time.ParseDuration(timeToLeaderSlotDurationString)
+	if err != nil {
+		return fmt.Errorf(
+			"failed to parse minimum time to leader slot %s: %w",
+			timeToLeaderSlotDurationString,
+			err,
+		)
+	}
+	v.MinimumTimeToLeaderSlot = minimumTimeToLeaderSlotDuration
+	v.logger.Debug().
+		Str("minimum_time_to_leader_slot", v.MinimumTimeToLeaderSlot.String()).
+		Msg("minimum time to leader slot set")
+	return nil
+}
+
+// GetHostname returns the hostname - can be overridden in tests
+func (v *Validator) GetHostname() (string, error) {
+	return os.Hostname()
+}
+
+// configureHostname ensures the hostname is valid and sets it
+func (v *Validator) configureHostname(hostname string) (err error) {
+	if hostname != "" {
+		v.Hostname = hostname
+		v.logger.Debug().
+			Str("hostname", v.Hostname).
+			Msg("hostname set in config")
+		return nil
+	}
+
+	hostname, err = v.GetHostname()
+	if err != nil {
+		return err
+	}
+	v.Hostname = hostname
+	v.logger.Debug().
+		Str("hostname", v.Hostname).
+		Msg("hostname set")
+	return nil
+}
+
+// configureServer ensures the server is valid and sets it
+func (v *Validator) configureServer(cfg ServerConfig) (err error) {
+	if cfg.WaitProgressInterval != "" {
+		waitProgressInterval, err := time.ParseDuration(cfg.WaitProgressInterval)
+		if err != nil {
+			return fmt.Errorf("failed to parse failover.server.wait_progress_interval %s: %w", cfg.WaitProgressInterval, err)
+		}
+		if waitProgressInterval < 0 {
+			return fmt.Errorf("failover.server.wait_progress_interval must not be negative, got %s", cfg.WaitProgressInterval)
+		}
+	}
+
+	v.failoverServerQUIC, err = parseQUICConfig("failover.server.quic", cfg.QUIC)
+	if err != nil {
+		return err
+	}
+
+	v.FailoverServerConfig = cfg
+	v.logger.Debug().
+		Int("port", v.FailoverServerConfig.Port).
+		Str("wait_progress_interval", v.FailoverServerConfig.WaitProgressInterval).
+		Bool("peer_allowlist", v.FailoverServerConfig.PeerAllowlist).
+		Bool("ephemeral_port_fallback", v.FailoverServerConfig.EphemeralPortFallback).
+		Interface("quic", v.failoverServerQUIC).
+		Msg("server set")
+	return nil
+}
+
+// configureClient ensures the client is valid and sets it
+func (v *Validator) configureClient(cfg ClientConfig) (err error) {
+	if cfg.DialRetries < 0 {
+		return fmt.Errorf("invalid failover.client.dial_retries %d - must be >= 0", cfg.DialRetries)
+	}
+	for key, durationStr := range map[string]string{
+		"dial_retry_interval":     cfg.DialRetryInterval,
+		"dial_retry_max_interval": cfg.DialRetryMaxInterval,
+	} {
+		if durationStr == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(durationStr); err != nil {
+			return fmt.Errorf("failed to parse failover.client.%s %s: %w", key, durationStr, err)
+		}
+	}
+	v.failoverClientQUIC, err = parseQUICConfig("failover.client.quic", cfg.QUIC)
+	if err != nil {
+		return err
+	}
+	v.FailoverClientConfig = cfg
+	v.logger.Debug().
+		Int("dial_retries", v.FailoverClientConfig.DialRetries).
+		Str("dial_retry_interval", v.FailoverClientConfig.DialRetryInterval).
+		Str("dial_retry_max_interval", v.FailoverClientConfig.DialRetryMaxInterval).
+		Interface("quic", v.failoverClientQUIC).
+		Msg("client set")
+	return nil
+}
+
+// parseQUICConfig ensures the quic tuning under key is valid and returns it - unset durations and windows stay zero
+// for quic-go's defaults
+func parseQUICConfig(key string, cfg QUICConfig) (quicConfig failover.QUICConfig, err error) {
+	for name, duration := range map[string]struct {
+		value  string
+		target *time.Duration
+	}{
+		"max_idle_timeout":  {cfg.MaxIdleTimeout, &quicConfig.MaxIdleTimeout},
+		"keep_alive_period": {cfg.KeepAlivePeriod, &quicConfig.KeepAlivePeriod},
+	} {
+		if duration.value == "" {
+			continue
+		}
+		*duration.target, err = time.ParseDuration(duration.value)
+		if err != nil {
+			return quicConfig, fmt.Errorf("failed to parse %s.%s %s: %w", key, name, duration.value, err)
+		}
+		if *duration.target < 0 {
+			return quicConfig, fmt.Errorf("%s.%s must not be negative, got %s", key, name, duration.value)
+		}
+	}
+	if cfg.MaxStreamReceiveWindow != 0 && cfg.InitialStreamReceiveWindow > cfg.MaxStreamReceiveWindow {
+		return quicConfig, fmt.Errorf("%s.initial_stream_receive_window %d must not exceed max_stream_receive_window %d", key, cfg.InitialStreamReceiveWindow, cfg.MaxStreamReceiveWindow)
+	}
+	if cfg.MaxConnectionReceiveWindow != 0 && cfg.InitialConnectionReceiveWindow > cfg.MaxConnectionReceiveWindow {
+		return quicConfig, fmt.Errorf("%s.initial_connection_receive_window %d must not exceed max_connection_receive_window %d", key, cfg.InitialConnectionReceiveWindow, cfg.MaxConnectionReceiveWindow)
+	}
+
+	quicConfig.InitialStreamReceiveWindow = cfg.InitialStreamReceiveWindow
+	quicConfig.MaxStreamReceiveWindow = cfg.MaxStreamReceiveWindow
+	quicConfig.InitialConnectionReceiveWindow = cfg.InitialConnectionReceiveWindow
+	quicConfig.MaxConnectionReceiveWindow = cfg.MaxConnectionReceiveWindow
+	quicConfig.Allow0RTT = cfg.Allow0RTT
+	return quicConfig, nil
+}
+
+// configureLogSlotContext ensures the log slot context is valid and sets it
+func (v *Validator) configureLogSlotContext(cfg LogSlotContextConfig) (err error) {
+	if cfg.Enabled && cfg.Interval != "" {
+		if _, err := time.ParseDuration(cfg.Interval); err != nil {
+			return fmt.Errorf("failed to parse failover.log_slot_context.interval %s: %w", cfg.Interval, err)
+		}
+	}
+	v.LogSlotContext = cfg
+	v.logger.Debug().
+		Bool("enabled", v.LogSlotContext.Enabled).
+		Str("interval", v.LogSlotContext.Interval).
+		Msg("log slot context set")
+	return nil
+}
+
+// configureDrill ensures the drill schedule, peer and timeout are valid and sets them
+func (v *Validator) configureDrill(cfg DrillConfig) (err error) {
+	v.Drill = cfg
+	v.DrillSchedule = nil
+
+	if cfg.Schedule == "" {
+		v.logger.Debug().Msg("drill schedule not set - scheduled drills disabled")
+		return nil
+	}
+
+	v.DrillSchedule, err = schedule.Parse(cfg.Schedule)
+	if err != nil {
+		return fmt.Errorf("invalid failover.drill.schedule: %w", err)
+	}
+
+	if cfg.Peer != "" {
+		if _, ok := v.Peers[cfg.Peer]; !ok {
+			return fmt.Errorf("failover.drill.peer %s not found in failover.peers", cfg.Peer)
+		}
+	} else if len(v.Peers) > 1 {
+		return fmt.Errorf("failover.drill.peer is required when more than one peer is declared")
+	}
+
+	if cfg.Timeout != "" {
+		if _, err := time.ParseDuration(cfg.Timeout); err != nil {
+			return fmt.Errorf("failed to parse failover.drill.timeout %s: %w", cfg.Timeout, err)
+		}
+	}
+
+	v.logger.Debug().
+		Str("schedule", v.Drill.Schedule).
+		Str("peer", v.Drill.Peer).
+		Str("timeout", v.Drill.Timeout).
+		Msg("drill set")
+	return nil
+}
+
+// DrillFailoverParams returns the failover parameters for a scheduled dry-run drill - run from the passive node by
+// asking the active peer's agent to hand over
+func (v *Validator) DrillFailoverParams() FailoverParams {
+	params := FailoverParams{
+		NotADrill:      false,
+		PeerName:       v.Drill.Peer,
+		ViaAgent:       true,
+		NonInteractive: true,
+	}
+	if v.Drill.Timeout != "" {
+		params.WaitTimeout, _ = time.ParseDuration(v.Drill.Timeout) // validated in configureDrill
+	}
+	return params
+}
+
+// configureEvents ensures the event publisher config is valid and sets it
+func (v *Validator) configureEvents(cfg events.Config) (err error) {
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid failover.events: %w", err)
+	}
+	v.Events = cfg
+	v.logger.Debug().
+		Str("url", utils.RedactURL(v.Events.URL)).
+		Str("topic", v.Events.Topic).
+		Msg("events set")
+	return nil
+}
+
+// configureNotifications ensures the notification targets are valid and sets them
+func (v *Validator) configureNotifications(cfg notify.Config) (err error) {
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid failover.notifications: %w", err)
+	}
+	v.Notifications = cfg
+
+	targetNames := make([]string, 0, len(cfg.Targets))
+	for _, target := range cfg.Targets {
+		targetNames = append(targetNames, fmt.Sprintf("%s (%s)", target.Name, target.Type))
+	}
+	v.logger.Debug().
+		Strs("targets", targetNames).
+		Str("timeout", v.Notifications.Timeout).
+		Msg("notifications set")
+	return nil
+}
+
+// configureAudit resolves the audit log's state dir and sets the log - nil when disabled
+func (v *Validator) configureAudit(cfg AuditConfig) (err error) {
+	if !cfg.Enabled {
+		v.AuditLog = nil
+		v.logger.Debug().Msg("failover.audit disabled - failovers will not be recorded")
+		return nil
+	}
+	if cfg.Dir == "" {
+		return fmt.Errorf("failover.audit.dir must be set when failover.audit.enabled is true")
+	}
+
+	dir, err := utils.ResolvePath(cfg.Dir)
+	if err != nil {
+		return fmt.Errorf("invalid failover.audit.dir %s: %w", cfg.Dir, err)
+	}
+	v.AuditLog = audit.NewLog(dir)
+	v.logger.Debug().Str("path", v.AuditLog.Path()).Msg("failover audit log set")
+	return nil
+}
+
+// configureRoleIntent ensures the role intent config is valid and sets the store it is kept in
+func (v *Validator) configureRoleIntent(cfg RoleIntentConfig) (err error) {
+	if !cfg.Enabled {
+		v.RoleIntent = nil
+		v.logger.Debug().Msg("failover.role_intent disabled - the role this node should hold will not be recorded")
+		return nil
+	}
+	if cfg.Dir == "" {
+		return fmt.Errorf("failover.role_intent.dir must be set when failover.role_intent.enabled is true")
+	}
+
+	dir, err := utils.ResolvePath(cfg.Dir)
+	if err != nil {
+		return fmt.Errorf("invalid failover.role_intent.dir %s: %w", cfg.Dir, err)
+	}
+	v.RoleIntent = roleintent.NewStore(dir)
+	v.logger.Debug().Str("path", v.RoleIntent.Path()).Msg("role intent file set")
+	return nil
+}
+
+// configureTracing ensures the tracing config is valid and sets it
+func (v *Validator) configureTracing(cfg tracing.Config) (err error) {
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid failover.tracing: %w", err)
+	}
+	v.Tracing = cfg
+	v.logger.Debug().
+		Str("endpoint", utils.RedactURL(v.Tracing.Endpoint)).
+		Str("timeout", v.Tracing.Timeout).
+		Msg("tracing set")
+	return nil
+}
+
+// configureAgent ensures the agent config is valid and sets it
+func (v *Validator) configureAgent(cfg AgentConfig) (err error) {
+	if cfg.Port < 0 || cfg.Port > 65535 {
+		return fmt.Errorf("invalid failover.agent.port %d", cfg.Port)
+	}
+	if cfg.Port != 0 && cfg.Port == v.FailoverServerConfig.Port {
+		return fmt.Errorf("failover.agent.port %d must differ from failover.server.port", cfg.Port)
+	}
+	if cfg.FileTransfer.MaxSize < 0 {
+		return fmt.Errorf("failover.agent.file_transfer.max_size must not be negative, got %d", cfg.FileTransfer.MaxSize)
+	}
+
+	// allowed dirs are resolved so symlinks in a pushed file's path can't lead out of them
+	v.agentFileTransfer = failover.FileTransferConfig{MaxSize: cfg.FileTransfer.MaxSize}
+	for _, dir := range cfg.FileTransfer.AllowedDirs {
+		resolvedDir, err := utils.ResolveAndValidateDir(dir)
+		if err != nil {
+			return fmt.Errorf("invalid failover.agent.file_transfer.allowed_dirs entry %s: %w", dir, err)
+		}
+		resolvedDir, err = filepath.EvalSymlinks(resolvedDir)
+		if err != nil {
+			return fmt.Errorf("invalid failover.agent.file_transfer.allowed_dirs entry %s: %w", dir, err)
+		}
+		v.agentFileTransfer.AllowedDirs = append(v.agentFileTransfer.AllowedDirs, resolvedDir)
+	}
+
+	v.Agent = cfg
+	v.logger.Debug().
+		Int("port", v.Agent.Port).
+		Strs("file_transfer_allowed_dirs", v.agentFileTransfer.AllowedDirs).
+		Int64("file_transfer_max_size", v.agentFileTransfer.MaxSize).
+		Bool("share_config", v.Agent.ShareConfig).
+		Msg("agent set")
+	return nil
+}
+
+// configureTLS loads (or creates) this node's persisted certificate and the pinned peer certificates, and the client
+// certificates when issued - without a dir an ephemeral certificate is generated each run so pinning is disabled
+func (v *Validator) configureTLS(cfg TLSConfig) (err error) {
+	if cfg.Dir == "" {
+		if cfg.RequireClientCertificates {
+			return fmt.Errorf("failover.tls.require_client_certificates needs failover.tls.dir to hold %s", peertrust.ClientCACertFileName)
+		}
+		v.logger.Debug().Msg("failover.tls.dir not set - using ephemeral certificates without peer pinning")
+		return nil
+	}
+
+	dir, err := utils.ResolvePath(cfg.Dir)
+	if err != nil {
+		return fmt.Errorf("invalid failover.tls.dir %s: %w", cfg.Dir, err)
+	}
+
+	cert, err := peertrust.LoadOrCreateCertificate(dir)
+	if err != nil {
+		return err
+	}
+	v.TLSCertificate = &cert
+
+	if cfg.PinPeerCertificates {
+		v.PeerPins, err = peertrust.Load(filepath.Join(dir, peertrust.KnownPeersFileName))
+		if err != nil {
+			return err
+		}
+	}
+
+	// present a client certificate to peers whenever one has been issued to this node
+	v.ClientCertificate, err = peertrust.LoadClientCertificate(dir)
+	if err != nil {
+		return err
+	}
+
+	if cfg.RequireClientCertificates {
+		v.ClientCAs, err = peertrust.LoadClientCAs(dir)
+		if err != nil {
+			return err
+		}
+	}
+
+	v.logger.Debug().
+		Str("dir", dir).
+		Str("fingerprint", peertrust.Fingerprint(cert.Certificate[0])).
+		Bool("pin_peer_certificates", cfg.PinPeerCertificates).
+		Bool("client_certificate", v.ClientCertificate != nil).
+		Bool("require_client_certificates", cfg.RequireClientCertificates).
+		Msg("tls set")
+	return nil
+}
+
+// configureCertificateFiles loads the operator-supplied certificates, keys and certificate authorities in place of
+// the self-signed certificate and the ones in failover.tls.dir - a server certificate that can't be loaded falls back
+// to the self-signed one only when failover.server.tls.self_signed_fallback is set
+func (v *Validator) configureCertificateFiles(server ServerTLSConfig, client ClientTLSConfig) (err error) {
+	serverCertFile, serverKeyFile, err := resolveCertificateFiles("failover.server.tls", server.CertFile, server.KeyFile)
+	if err != nil {
+		return err
+	}
+	if serverCertFile != "" {
+		cert, err := peertrust.LoadCertificateFiles(serverCertFile, serverKeyFile)
+		switch {
+		case err == nil:
+			v.TLSCertificate = &cert
+		case server.SelfSignedFallback:
+			v.logger.Warn().Err(err).Msg(style.RenderWarningString("Falling back to the self-signed certificate - failover.server.tls.self_signed_fallback is set"))
+		default:
+			return fmt.Errorf("%w - set failover.server.tls.self_signed_fallback to present the self-signed certificate instead", err)
+		}
+	}
+	if server.CAFile != "" {
+		caFile, err := utils.ResolvePath(server.CAFile)
+		if err != nil {
+			return fmt.Errorf("invalid failover.server.tls.ca_file %s: %w", server.CAFile, err)
+		}
+		v.ClientCAs, err = peertrust.LoadCAFile(caFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	clientCertFile, clientKeyFile, err := resolveCertificateFiles("failover.client.tls", client.CertFile, client.KeyFile)
+	if err != nil {
+		return err
+	}
+	if clientCertFile != "" {
+		cert, err := peertrust.LoadCertificateFiles(clientCertFile, clientKeyFile)
+		if err != nil {
+			return err
+		}
+		v.ClientCertificate = &cert
+	}
+	if client.CAFile != "" {
+		caFile, err := utils.ResolvePath(client.CAFile)
+		if err != nil {
+			return fmt.Errorf("invalid failover.client.tls.ca_file %s: %w", client.CAFile, err)
+		}
+		v.ServerCAs, err = peertrust.LoadCAFile(caFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	v.logger.Debug().
+		Str("server_cert_file", serverCertFile).
+		Str("server_ca_file", server.CAFile).
+		Str("client_cert_file", clientCertFile).
+		Str("client_ca_file", client.CAFile).
+		Msg("certificate files set")
+	return nil
+}
+
+// resolveCertificateFiles resolves a certificate and key path under key - both or neither must be set
+func resolveCertificateFiles(key, certFile, keyFile string) (resolvedCertFile, resolvedKeyFile string, err error) {
+	if (certFile == "") != (keyFile == "") {
+		return "", "", fmt.Errorf("%s.cert_file and %s.key_file must be set together", key, key)
+	}
+	if certFile == "" {
+		return "", "", nil
+	}
+	resolvedCertFile, err = utils.ResolvePath(certFile)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid %s.cert_file %s: %w", key, certFile, err)
+	}
+	resolvedKeyFile, err = utils.ResolvePath(keyFile)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid %s.key_file %s: %w", key, keyFile, err)
+	}
+	return resolvedCertFile, resolvedKeyFile, nil
+}
+
+// configurePathMTUProbe ensures the path mtu probe minimum mtu and timeout are valid and sets them
+func (v *Validator) configurePathMTUProbe(cfg PathMTUProbeConfig) (err error) {
+	v.PathMTUProbe = failover.PathMTUProbeConfig{Enabled: cfg.Enabled}
+
+	if !cfg.Enabled {
+		v.logger.Debug().Msg("path mtu probe disabled")
+		return nil
+	}
+
+	if cfg.MinMTU < 0 {
+		return fmt.Errorf("failover.path_mtu_probe.min_mtu must not be negative, got %d", cfg.MinMTU)
+	}
+	v.PathMTUProbe.MinMTU = cfg.MinMTU
+
+	v.PathMTUProbe.Timeout, err = time.ParseDuration(cfg.Timeout)
+	if err != nil {
+		return fmt.Errorf("failed to parse failover.path_mtu_probe.timeout %s: %w", cfg.Timeout, err)
+	}
+	if v.PathMTUProbe.Timeout <= 0 {
+		return fmt.Errorf("failover.path_mtu_probe.timeout must be positive, got %s", cfg.Timeout)
+	}
+
+	v.logger.Debug().
+		Int("min_mtu", v.PathMTUProbe.MinMTU).
+		Dur("timeout", v.PathMTUProbe.Timeout).
+		Msg("path mtu probe set")
+	return nil
+}
+
+// configureClockCheck ensures the clock check source, max offset, action and timeout are valid and sets them
+func (v *Validator) configureClockCheck(cfg ClockCheckConfig) (err error) {
+	v.ClockCheck = cfg
+	v.clockOffset = nil
+
+	if !cfg.Enabled {
+		v.logger.Debug().Msg("clock check disabled")
+		return nil
+	}
+
+	v.clockCheckMaxOffset, err = time.ParseDuration(cfg.MaxOffset)
+	if err != nil {
+		return fmt.Errorf("failed to parse failover.clock_check.max_offset %s: %w", cfg.MaxOffset, err)
+	}
+	if v.clockCheckMaxOffset <= 0 {
+		return fmt.Errorf("failover.clock_check.max_offset must be positive, got %s", cfg.MaxOffset)
+	}
+
+	timeout, err := time.ParseDuration(cfg.Timeout)
+	if err != nil {
+		return fmt.Errorf("failed to parse failover.clock_check.timeout %s: %w", cfg.Timeout, err)
+	}
+
+	if cfg.Action != clock.ActionWarn && cfg.Action != clock.ActionRefuse {
+		return fmt.Errorf("invalid failover.clock_check.action %s - must be one of: %s, %s", cfg.Action, clock.ActionWarn, clock.ActionRefuse)
+	}
+
+	switch cfg.Source {
+	case clock.SourceNTP:
+		if _, _, err := net.SplitHostPort(cfg.NTPServer); err != nil {
+			return fmt.Errorf("invalid failover.clock_check.ntp_server %s - must be host:port: %w", cfg.NTPServer, err)
+		}
+		v.clockOffset = func() (time.Duration, error) {
+			return clock.NTPOffset(cfg.NTPServer, timeout)
+		}
+	case clock.SourceChronyc:
+		v.clockOffset = func() (time.Duration, error) {
+			return clock.ChronycOffset(timeout)
+		}
+	default:
+		return fmt.Errorf("invalid failover.clock_check.source %s - must be one of: %s, %s", cfg.Source, clock.SourceNTP, clock.SourceChronyc)
+	}
+
+	v.logger.Debug().
+		Str("source", v.ClockCheck.Source).
+		Str("ntp_server", v.ClockCheck.NTPServer).
+		Dur("max_offset", v.clockCheckMaxOffset).
+		Str("action", v.ClockCheck.Action).
+		Msg("clock check set")
+	return nil
+}
+
+// checkClockOffset ensures the local clock is within the configured max offset - with the warn action an offset
+// that is too large or can't be read is only logged
+func (v *Validator) checkClockOffset() error {
+	if v.clockOffset == nil {
+		log.Debug().Msg("failover.clock_check not enabled, skipping clock offset check")
+		return nil
+	}
+
+	offset, err := v.clockOffset()
+	if err == nil && offset.Abs() > v.clockCheckMaxOffset {
+		err = fmt.Errorf(
+			"local clock is off by %s (from %s), more than failover.clock_check.max_offset %s - fix time sync on %s before failing over",
+			offset,
+			v.ClockCheck.Source,
+			v.clockCheckMaxOffset,
+			v.Hostname,
+		)
+	}
+	if err == nil {
+		log.Debug().Dur("offset", offset).Str("source", v.ClockCheck.Source).Msg("local clock offset within limit")
+		return nil
+	}
+
+	if v.ClockCheck.Action == clock.ActionRefuse {
+		return fmt.Errorf("clock check failed: %w", err)
+	}
+	log.Warn().Err(err).Msg("clock check failed - continuing as failover.clock_check.action is warn")
+	return nil
+}
+
+// configureFeatureGates ensures the feature gate ids are valid pubkeys and sets them
+func (v *Validator) configureFeatureGates(cfg FeatureGatesConfig) (err error) {
+	v.FeatureGates = cfg
+	v.featureGateIDs = make([]solanago.PublicKey, 0, len(cfg.Features))
+
+	for i, feature := range cfg.Features {
+		if feature.Name == "" {
+			return fmt.Errorf("failover.feature_gates.features[%d].name is required", i)
+		}
+		id, err := solanago.PublicKeyFromBase58(feature.ID)
+		if err != nil {
+			return fmt.Errorf("invalid failover.feature_gates.features[%d].id %s for %s: %w", i, feature.ID, feature.Name, err)
+		}
+		v.featureGateIDs = append(v.featureGateIDs, id)
+	}
+
+	v.logger.Debug().
+		Int("features", len(v.featureGateIDs)).
+		Uint64("window_slots", v.FeatureGates.WindowSlots).
+		Msg("feature gates set")
+	return nil
+}
+
+// configurePeerSelection ensures the peer selection timeout is valid and sets it
+func (v *Validator) configurePeerSelection(cfg PeerSelectionConfig) (err error) {
+	v.PeerSelection = cfg
+	v.peerStatusQuery = v.queryPeerStatus
+	v.stdinIsTerminal = func() bool {
+		return term.IsTerminal(int(os.Stdin.Fd()))
+	}
+
+	v.peerSelectionTimeout, err = time.ParseDuration(cfg.Timeout)
+	if err != nil {
+		return fmt.Errorf("failed to parse failover.peer_selection.timeout %s: %w", cfg.Timeout, err)
+	}
+	if v.peerSelectionTimeout <= 0 {
+		return fmt.Errorf("failover.peer_selection.timeout must be positive, got %s", cfg.Timeout)
+	}
+
+	v.logger.Debug().
+		Bool("auto_select", v.PeerSelection.AutoSelect).
+		Dur("timeout", v.peerSelectionTimeout).
+		Msg("peer selection set")
+	return nil
+}
+
+// configureWatch ensures the watch daemon's peer and durations are valid and sets them
+func (v *Validator) configureWatch(cfg WatchConfig) (err error) {
+	v.Watch = cfg
+
+	if cfg.Peer != "" {
+		if _, ok := v.Peers[cfg.Peer]; !ok {
+			return fmt.Errorf("failover.watch.peer %s not found in failover.peers", cfg.Peer)
+		}
+	} else if len(v.Peers) == 1 {
+		for name := range v.Peers {
+			v.Watch.Peer = name
+		}
+	}
+
+	v.watchInterval, err = time.ParseDuration(cfg.Interval)
+	if err != nil {
+		return fmt.Errorf("failed to parse failover.watch.interval %s: %w", cfg.Interval, err)
+	}
+	if v.watchInterval <= 0 {
+		return fmt.Errorf("failover.watch.interval must be positive, got %s", cfg.Interval)
+	}
+
+	v.watchFailureThreshold, err = time.ParseDuration(cfg.FailureThreshold)
+	if err != nil {
+		return fmt.Errorf("failed to parse failover.watch.failure_threshold %s: %w", cfg.FailureThreshold, err)
+	}
+	if v.watchFailureThreshold < 0 {
+		return fmt.Errorf("failover.watch.failure_threshold must not be negative, got %s", cfg.FailureThreshold)
+	}
+
+	v.watchCooldown, err = time.ParseDuration(cfg.Cooldown)
+	if err != nil {
+		return fmt.Errorf("failed to parse failover.watch.cooldown %s: %w", cfg.Cooldown, err)
+	}
+	if v.watchCooldown < 0 {
+		return fmt.Errorf("failover.watch.cooldown must not be negative, got %s", cfg.Cooldown)
+	}
+
+	v.watchTimeout, err = time.ParseDuration(cfg.Timeout)
+	if err != nil {
+		return fmt.Errorf("failed to parse failover.watch.timeout %s: %w", cfg.Timeout, err)
+	}
+	if v.watchTimeout < 0 {
+		return fmt.Errorf("failover.watch.timeout must not be negative, got %s", cfg.Timeout)
+	}
+
+	v.logger.Debug().
+		Str("peer", v.Watch.Peer).
+		Dur("interval", v.watchInterval).
+		Dur("failure_threshold", v.watchFailureThreshold).
+		Dur("cooldown", v.watchCooldown).
+		Dur("timeout", v.watchTimeout).
+		Bool("not_a_drill", v.Watch.NotADrill).
+		Bool("takeover", v.Watch.Takeover).
+		Msg("watch set")
+	return nil
+}
+
+// configureEpochBoundary ensures the epoch boundary window and action are valid and sets them
+func (v *Validator) configureEpochBoundary(cfg EpochBoundaryConfig) error {
+	v.EpochBoundary = cfg
+	if !cfg.Enabled {
+		v.logger.Debug().Msg("epoch boundary guard disabled")
+		return nil
+	}
+
+	if cfg.WindowSlots == 0 {
+		return fmt.Errorf("failover.epoch_boundary.window_slots must be at least 1, got %d", cfg.WindowSlots)
+	}
+	if cfg.Action != clock.ActionWarn && cfg.Action != clock.ActionRefuse {
+		return fmt.Errorf("invalid failover.epoch_boundary.action %s - must be one of: %s, %s", cfg.Action, clock.ActionWarn, clock.ActionRefuse)
+	}
+
+	v.logger.Debug().
+		Uint64("window_slots", v.EpochBoundary.WindowSlots).
+		Str("action", v.EpochBoundary.Action).
+		Msg("epoch boundary guard set")
+	return nil
+}
+
+// checkEpochBoundary takes failover.epoch_boundary.action when the current slot is within the window of an epoch
+// boundary - the leader schedule is recalculated and vote credits start again there, making the failover riskier and
+// the post-failover vote credit comparison meaningless. force only warns
+func (v *Validator) checkEpochBoundary(force bool) error {
+	if !v.EpochBoundary.Enabled {
+		log.Debug().Msg("failover.epoch_boundary not enabled, skipping epoch boundary check")
+		return nil
+	}
+
+	epochInfo, err := v.solanaRPCClient.GetCurrentEpochInfo()
+	if err == nil {
+		err = epochBoundaryError(epochInfo, v.EpochBoundary.WindowSlots)
+	}
+	if err == nil {
+		return nil
+	}
+
+	if v.EpochBoundary.Action == clock.ActionRefuse && !force {
+		return fmt.Errorf("epoch boundary check failed: %w - retry once outside the window or pass --force", err)
+	}
+	log.Warn().Err(err).Msg("epoch boundary check failed - continuing")
+	return nil
+}
+
+// epochBoundaryError returns why the epoch's current slot is too close to either of its boundaries - nil when it
+// isn't within windowSlots of them
+func epochBoundaryError(epochInfo *rpc.GetEpochInfoResult, windowSlots uint64) error {
+	slotsUntilEpochEnd := epochInfo.SlotsInEpoch - epochInfo.SlotIndex
+	switch {
+	case slotsUntilEpochEnd <= windowSlots:
+		return fmt.Errorf(
+			"epoch %d ends in %d slots, within failover.epoch_boundary.window_slots %d",
+			epochInfo.Epoch, slotsUntilEpochEnd, windowSlots,
+		)
+	case epochInfo.SlotIndex < windowSlots:
+		return fmt.Errorf(
+			"epoch %d started %d slots ago, within failover.epoch_boundary.window_slots %d",
+			epochInfo.Epoch, epochInfo.SlotIndex, windowSlots,
+		)
+	}
+	return nil
+}
+
+// warnFeatureGateActivations warns when a configured feature gate is pending activation at an epoch boundary within
+// the window or activated within it - failures to check are only logged as this never blocks a failover
+func (v *Validator) warnFeatureGateActivations() {
+	if len(v.featureGateIDs) == 0 {
+		log.Debug().Msg("no failover.feature_gates.features configured, skipping feature gate check")
+		return
+	}
+
+	for _, warning := range v.featureGateActivationWarnings() {
+		log.Warn().Msg(warning)
+	}
+}
+
+// featureGateActivationWarnings returns a warning for each configured feature gate activating within the window
+func (v *Validator) featureGateActivationWarnings() (warnings []string) {
+	statuses, err := v.solanaRPCClient.GetFeatureStatuses(v.featureGateIDs)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to check feature gates - continuing")
+		return nil
+	}
+
+	epochInfo, err := v.solanaRPCClient.GetCurrentEpochInfo()
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to get epoch info to check feature gates - continuing")
+		return nil
+	}
+
+	slotsUntilEpochEnd := epochInfo.SlotsInEpoch - epochInfo.SlotIndex
+	for i, status := range statuses {
+		name := v.FeatureGates.Features[i].Name
+		switch {
+		case status.IsPending() && slotsUntilEpochEnd <= v.FeatureGates.WindowSlots:
+			warnings = append(warnings, fmt.Sprintf(
+				"⚠️  feature %s (%s) activates at the epoch boundary in %d slots - consider failing over after it",
+				name, status.ID, slotsUntilEpochEnd,
+			))
+		case status.IsActive() && *status.ActivatedAt <= epochInfo.AbsoluteSlot && epochInfo.AbsoluteSlot-*status.ActivatedAt <= v.FeatureGates.WindowSlots:
+			warnings = append(warnings, fmt.Sprintf(
+				"⚠️  feature %s (%s) activated %d slots ago at slot %d - consider waiting for the cluster to settle",
+				name, status.ID, epochInfo.AbsoluteSlot-*status.ActivatedAt, *status.ActivatedAt,
+			))
+		}
+	}
+
+	return warnings
+}
+
+// configureMonitor ensures the monitor is valid and sets it
+func (v *Validator) configureMonitor(cfg MonitorConfig) (err error) {
+	if cfg.IdentityGapAlarmThreshold != "" {
+		threshold, err := time.ParseDuration(cfg.IdentityGapAlarmThreshold)
+		if err != nil {
+			return fmt.Errorf("failed to parse failover.monitor.identity_gap_alarm_threshold %s: %w", cfg.IdentityGapAlarmThreshold, err)
+		}
+		if threshold < 0 {
+			return fmt.Errorf("failover.monitor.identity_gap_alarm_threshold must not be negative, got %s", cfg.IdentityGapAlarmThreshold)
+		}
+	}
+
+	if cfg.DurationRegression.Enabled {
+		if cfg.DurationRegression.ThresholdPercent <= 0 {
+			return fmt.Errorf("failover.monitor.duration_regression.threshold_percent must be greater than 0, got %v", cfg.DurationRegression.ThresholdPercent)
+		}
+		if cfg.DurationRegression.BaselineFailovers < 1 {
+			return fmt.Errorf("failover.monitor.duration_regression.baseline_failovers must be at least 1, got %d", cfg.DurationRegression.BaselineFailovers)
+		}
+		if cfg.DurationRegression.MinBaselineFailovers < 1 || cfg.DurationRegression.MinBaselineFailovers > cfg.DurationRegression.BaselineFailovers {
+			return fmt.Errorf(
+				"failover.monitor.duration_regression.min_baseline_failovers must be between 1 and baseline_failovers %d, got %d",
+				cfg.DurationRegression.BaselineFailovers, cfg.DurationRegression.MinBaselineFailovers,
+			)
+		}
+		if cfg.DurationRegression.MinIncrease != "" {
+			minIncrease, err := time.ParseDuration(cfg.DurationRegression.MinIncrease)
+			if err != nil {
+				return fmt.Errorf("failed to parse failover.monitor.duration_regression.min_increase %s: %w", cfg.DurationRegression.MinIncrease, err)
+			}
+			if minIncrease < 0 {
+				return fmt.Errorf("failover.monitor.duration_regression.min_increase must not be negative, got %s", cfg.DurationRegression.MinIncrease)
+			}
+		}
+	}
+
+	if cfg.BlockProduction.Enabled {
+		if cfg.BlockProduction.LeaderSlots < 1 {
+			return fmt.Errorf("failover.monitor.block_production.leader_slots must be at least 1, got %d", cfg.BlockProduction.LeaderSlots)
+		}
+		timeout, err := time.ParseDuration(cfg.BlockProduction.Timeout)
+		if err != nil {
+			return fmt.Errorf("failed to parse failover.monitor.block_production.timeout %s: %w", cfg.BlockProduction.Timeout, err)
+		}
+		if timeout <= 0 {
+			return fmt.Errorf("failover.monitor.block_production.timeout must be greater than 0, got %s", cfg.BlockProduction.Timeout)
+		}
+	}
+
+	if cfg.DetachedLogFile != "" {
+		cfg.DetachedLogFile, err = utils.ResolvePath(cfg.DetachedLogFile)
+		if err != nil {
+			return fmt.Errorf("invalid failover.monitor.detached_log_file: %w", err)
+		}
+	}
+
+	v.Monitor = cfg
+	v.logger.Debug().
+		Int("credit_samples_count", v.Monitor.CreditSamples.Count).
+		Str("credit_samples_interval", v.Monitor.CreditSamples.Interval).
+		Bool("metrics_snapshot_enabled", v.Monitor.MetricsSnapshot.Enabled).
+		Uint64("metrics_snapshot_block_production_slots", v.Monitor.MetricsSnapshot.BlockProductionSlots).
+		Bool("rewards_estimate_enabled", v.Monitor.RewardsEstimate.Enabled).
+		Str("identity_gap_alarm_threshold", v.Monitor.IdentityGapAlarmThreshold).
+		Str("detached_log_file", v.Monitor.DetachedLogFile).
+		Bool("duration_regression_enabled", v.Monitor.DurationRegression.Enabled).
+		Float64("duration_regression_threshold_percent", v.Monitor.DurationRegression.ThresholdPercent).
+		Bool("block_production_enabled", v.Monitor.BlockProduction.Enabled).
+		Int("block_production_leader_slots", v.Monitor.BlockProduction.LeaderSlots).
+		Str("block_production_timeout", v.Monitor.BlockProduction.Timeout).
+		Msg("monitor set")
+	return nil
+}
+
+// configureGossipNode ensures the gossip node is valid and sets it
+func (v *Validator) configureGossipNode() (err error) {
+	v.GossipNode, err = v.solanaRPCClient.NodeFromIP(v.PublicIP)
+	if err != nil {
+		return err
+	}
+	v.logger.Debug().
+		Str("public_ip", v.GossipNode.IP()).
+		Str("pubkey", v.GossipNode.PubKey()).
+		Msg("gossip node set")
+	return nil
+}
+
+// makeActive makes this validator active
+func (v *Validator) makeActive(params FailoverParams) (err error) {
+	log.Debug().Msg("making this validator active")
+
+	if v.IsActive() {
+		return fmt.Errorf("this validator is already active - nothing to do")
+	}
+
+	log.Info().
+		Str("public_ip", v.PublicIP).
+		Str("pubkey", v.Identities.Passive.PubKey()).
+		Msgf("This validator is currently %s", style.RenderPassiveString(strings.ToUpper(constants.NodeRolePassive), false))
+
+	// check gossip for active peer and ensure its pubkey is the same as what this node would set itself to
+	_, err = v.solanaRPCClient.NodeFromPubkey(v.Identities.Active.PubKey())
+	if err != nil {
+		return fmt.Errorf(
+			"active peer not found in gossip with pubkey %s from file %s: %w",
+			v.Identities.Active.PubKey(),
+			v.Identities.Active.KeyFile,
+			err,
+		)
+	}
+
+	// delete the tower file if it exists and auto empty when passive is true
+	if v.TowerFileAutoDeleteWhenPassive && utils.FileExists(v.TowerFile) {
+		log.Debug().
+			Str("tower_file", v.TowerFile).
+			Msg("deleting tower file because validator.tower.auto_empty_when_passive is true")
+
+		if _, err = failover.BackupTowerFile(v.TowerFile, failover.TowerBackupConfig(v.TowerBackup), v.logger); err != nil {
+			return err
+		}
+		if err = utils.RemoveFile(v.TowerFile); err != nil {
+			return err
+		}
+	}
+
+	// if the tower file exists and auto empty when passive is false, return an error
+	if !v.TowerFileAutoDeleteWhenPassive && utils.FileExists(v.TowerFile) {
+		return fmt.Errorf(
+			"tower file exists and validator.tower.auto_empty_when_passive is false - delete it and re-run: %s",
+			v.TowerFile,
+		)
+	}
+
+	// create a QUIC server that listens for the active node to connect and decide what to do
+	failoverServer, err := failover.NewServerFromConfig(failover.ServerConfig{
+		Port:                 v.FailoverServerConfig.Port,
+		HeartbeatInterval:    v.FailoverServerConfig.HeartbeatInterval,
+		StreamTimeout:        v.FailoverServerConfig.StreamTimeout,
+		WaitProgressInterval: v.FailoverServerConfig.WaitProgressInterval,
+		EphemeralFallback:    v.FailoverServerConfig.EphemeralPortFallback,
+		QUIC:                 v.failoverServerQUIC,
+		Identities:           v.Identities,
+		PassiveNodeInfo: &failover.NodeInfo{
+			Hostname:                       v.Hostname,
+			PublicIP:                       v.PublicIP,
+			Identities:                     failover.NewNodeIdentities(v.Identities),
+			TowerFile:                      v.TowerFile,
+			SetIdentityCommand:             v.SetIdentityActiveCommand,
+			ClientVersion:                  v.GossipNode.Version(),
+			Client:                         v.BinMetadata.Client,
+			BinVersion:                     v.BinMetadata.Version,
+			SolanaValidatorFailoverVersion: pkgconstants.AppVersion,
+		},
+		Peers:                 v.failoverPeers(),
+		SolanaRPCClient:       v.solanaRPCClient,
+		IsDryRunFailover:      !params.NotADrill,
+		Hooks:                 v.Hooks,
+		MonitorConfig:         convertMonitorConfig(v.Monitor),
+		LogSlotContext:        failover.LogSlotContextConfig(v.LogSlotContext),
+		TowerDriftMonitor:     failover.TowerDriftMonitorConfig(v.TowerDriftMonitor),
+		TowerBackup:           failover.TowerBackupConfig(v.TowerBackup),
+		TowerSanityCheck:      v.TowerSanityCheck,
+		Events:                v.Events,
+		Notifications:         v.Notifications,
+		WaitTimeout:           params.WaitTimeout,
+		TLSCertificate:        v.TLSCertificate,
+		ClientCAs:             v.ClientCAs,
+		PeerPins:              v.PeerPins,
+		PeerAllowlist:         v.FailoverServerConfig.PeerAllowlist,
+		NoPostMonitor:         params.NoPostMonitor,
+		DetachPostMonitor:     params.DetachPostMonitor,
+		Output:                params.Output,
+		SwitchCountdown:       v.SwitchCountdown,
+		MaxSlotLag:            v.MaxSlotLag,
+		AuditLog:              v.AuditLog,
+		RoleIntent:            v.RoleIntent,
+		Tracing:               v.Tracing,
+		WaitForHealthy:        params.waitForHealthy,
+		AllowDelinquentActive: params.Force,
+		ScheduledSwitchAt:     params.SwitchAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	// ask the agent on the active peer to connect to this server - it dials with retries so can start first, but the
+	// port is bound beforehand so the agent is told the ephemeral port when falling back to one
+	if params.ViaAgent {
+		if err = failoverServer.Listen(); err != nil {
+			return err
+		}
+		err = v.requestHandoverFromAgent(params, failoverServer.Port())
+		if err != nil {
+			return err
+		}
+	}
+
+	return failoverServer.Start()
+}
+
+// requestHandoverFromAgent asks the agent running on the active peer to hand over to this node
+func (v *Validator) requestHandoverFromAgent(params FailoverParams, serverPort int) (err error) {
+	activePeer, err := v.selectPeer(params.PeerName, constants.NodeRoleActive, params.ReloadPeers, params.NonInteractive)
+	if err != nil {
+		return err
+	}
+
+	agentPort := v.Agent.Port
+	if agentPort == 0 {
+		agentPort = failover.DefaultAgentPort
+	}
+	agentAddress := net.JoinHostPort(utils.HostFromAddress(activePeer.Address), strconv.Itoa(agentPort))
+
+	log.Info().
+		Str("agent_address", agentAddress).
+		Msgf("Asking agent on %s to hand over...", style.RenderActiveString(activePeer.Name, false))
+
+	err = failover.RequestHandover(failover.RequestHandoverParams{
+		AgentName:    activePeer.Name,
+		AgentAddress: agentAddress,
+		Request: failover.AgentRequest{
+			Hostname:                       v.Hostname,
+			PublicIP:                       v.PublicIP,
+			PassivePubkey:                  v.Identities.Passive.PubKey(),
+			NoMinTimeToLeaderSlot:          params.NoMinTimeToLeaderSlot,
+			NoWaitForHealthy:               params.PeerNoWaitForHealthy,
+			SolanaValidatorFailoverVersion: pkgconstants.AppVersion,
+			ProtocolRevision:               failover.ProtocolRevision,
+			MinProtocolRevision:            failover.MinProtocolRevision,
+			ServerPort:                     serverPort,
+		},
+		PeerPins:          v.PeerPins,
+		ClientCertificate: v.ClientCertificate,
+		ActiveIdentity:    v.Identities.Active,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info().Msgf("Agent on %s accepted - it will connect shortly", style.RenderActiveString(activePeer.Name, false))
+	return nil
+}
+
+// RunAgent runs the agent so passive peers can initiate failovers from their side - newValidator re-creates the
+// validator for each request as its role may have changed since the agent started
+func (v *Validator) RunAgent(newValidator func() (*Validator, error)) (err error) {
+	v.warnRoleIntentDivergence("")
+
+	// tell systemd the agent is ready once it listens, when run by a Type=notify unit
+	stopNotify := func() {}
+
+	var agentConfigView failover.ConfigView
+	if v.Agent.ShareConfig {
+		agentConfigView = v.configView
+	}
+
+	agent, err := failover.NewAgentFromConfig(failover.AgentConfig{
+		Port:  v.Agent.Port,
+		Peers: v.failoverPeers(),
+		PrepareHandover: func(peer failover.PeerInfo, request failover.AgentRequest) (func() error, error) {
+			current, err := newValidator()
+			if err != nil {
+				return nil, fmt.Errorf("failed to load validator: %w", err)
+			}
+			if !current.IsActive() {
+				return nil, fmt.Errorf("%s is not active - nothing to hand over", current.Hostname)
+			}
+			return func() error {
+				return current.Failover(FailoverParams{
+					PeerName:              peer.Name,
+					NoMinTimeToLeaderSlot: request.NoMinTimeToLeaderSlot,
+					NoWaitForHealthy:      request.NoWaitForHealthy,
+					NonInteractive:        true,
+					PeerPort:              request.ServerPort,
+				})
+			}, nil
+		},
+		TLSCertificate: v.TLSCertificate,
+		ClientCAs:      v.ClientCAs,
+		FileTransfer:   v.agentFileTransfer,
+		Hostname:       v.Hostname,
+		ConfigView:     agentConfigView,
+		TowerReplica:   v.agentTowerReplica(),
+		ActiveIdentity: v.Identities.Active,
+		OnListening: func() {
+			stopNotify = systemd.Ready("agent")
+		},
+		OnAccepting: func() {
+			systemd.Feed("agent")
+		},
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { stopNotify() }()
+
+	if v.TowerReplication.Enabled {
+		go v.runTowerReplication()
+	}
+
+	return agent.Start()
+}
+
+// agentTowerReplica returns where the agent keeps the tower replica the active peer ships - disabled unless
+// tower.replication is enabled
+func (v *Validator) agentTowerReplica() failover.TowerReplicaConfig {
+	if !v.TowerReplication.Enabled {
+		return failover.TowerReplicaConfig{}
+	}
+	return failover.TowerReplicaConfig{
+		Path:           v.TowerReplicaFile,
+		ActiveIdentity: v.Identities.Active.PublicKey(),
+	}
+}
+
+// runTowerReplication ships the tower file to every peer's agent each tower.replication.interval while this node is
+// active, so a passive peer has a recent tower to promote with should this node die without handing over - identities
+// are never switched. A peer failing is warned about once until it accepts a replica again
+func (v *Validator) runTowerReplication() {
+	log.Info().
+		Dur("interval", v.towerReplicaInterval).
+		Msg("Shipping tower replicas to peers while active")
+
+	failing := map[string]bool{}
+	for {
+		time.Sleep(v.towerReplicaInterval)
+
+		// this node's role changes after a failover so re-read it from gossip each time
+		if err := v.GossipNode.Refresh(v.solanaRPCClient); err != nil {
+			log.Debug().Err(err).Msg("failed to refresh this node's gossip identity - skipping tower replication")
+			continue
+		}
+		if !v.IsActive() {
+			log.Debug().Msg("this node is not active - no tower replica to ship")
+			continue
+		}
+
+		for _, peer := range v.Peers {
+			err := v.shipTowerReplica(peer)
+			if err != nil {
+				if !failing[peer.Name] {
+					log.Warn().Err(err).Str("peer_name", peer.Name).Msgf("failed to ship tower replica to %s", peer.Name)
+				}
+				failing[peer.Name] = true
+				continue
+			}
+			if failing[peer.Name] {
+				log.Info().Str("peer_name", peer.Name).Msgf("Shipping tower replicas to %s again", peer.Name)
+			}
+			failing[peer.Name] = false
+			log.Debug().Str("peer_name", peer.Name).Msgf("shipped tower replica to %s", peer.Name)
+		}
+	}
+}
+
+// shipTowerReplica ships the tower file to a peer's agent
+func (v *Validator) shipTowerReplica(peer Peer) error {
+	agentPort := v.Agent.Port
+	if agentPort == 0 {
+		agentPort = failover.DefaultAgentPort
+	}
+
+	return failover.ShipTowerReplica(failover.ShipTowerReplicaParams{
+		AgentName:         peer.Name,
+		AgentAddress:      net.JoinHostPort(utils.HostFromAddress(peer.Address), strconv.Itoa(agentPort)),
+		Hostname:          v.Hostname,
+		PublicIP:          v.PublicIP,
+		TowerFile:         v.TowerFile,
+		PeerPins:          v.PeerPins,
+		ClientCertificate: v.ClientCertificate,
+	})
+}
+
+// PushFile pushes a file to the agent on a peer, written to remotePath there - the peer is required unless only one is
+// configured, and its agent must allow remotePath's dir in failover.agent.file_transfer.allowed_dirs
+func (v *Validator) PushFile(peerName, localPath, remotePath string) error {
+	if peerName == "" {
+		if len(v.Peers) != 1 {
+			return fmt.Errorf("%d peers configured and none selected - pass --peer <name> to choose the peer to push to", len(v.Peers))
+		}
+		for name := range v.Peers {
+			peerName = name
+		}
+	}
+	peer, ok := v.Peers[peerName]
+	if !ok {
+		return fmt.Errorf("peer %s not found in failover.peers", peerName)
+	}
+
+	localPath, err := utils.ResolvePath(localPath)
+	if err != nil {
+		return fmt.Errorf("invalid file %s: %w", localPath, err)
+	}
+	if remotePath == "" {
+		remotePath = localPath
+	}
+
+	agentPort := v.Agent.Port
+	if agentPort == 0 {
+		agentPort = failover.DefaultAgentPort
+	}
+
+	return failover.PushFile(failover.PushFileParams{
+		AgentName:         peer.Name,
+		AgentAddress:      net.JoinHostPort(utils.HostFromAddress(peer.Address), strconv.Itoa(agentPort)),
+		Hostname:          v.Hostname,
+		PublicIP:          v.PublicIP,
+		LocalPath:         localPath,
+		RemotePath:        remotePath,
+		PeerPins:          v.PeerPins,
+		ClientCertificate: v.ClientCertificate,
+		ActiveIdentity:    v.Identities.Active,
+	})
+}
+
+// activeIdentityWatch is the state of the watch daemon's checks of the active identity
+type activeIdentityWatch struct {
+	// previousVoteStatus is the vote account status at the previous check - nil before the first
+	previousVoteStatus *solana.VoteAccountStatus
+	// failingSince is when the active identity started meeting a failure condition - zero while it isn't
+	failingSince time.Time
+	// notInGossip is true when the latest check found the active identity missing from gossip
+	notInGossip bool
+	// lastVoteStalled is true when the latest check found the active identity's last vote no further than at the
+	// previous one, or no vote account for it - a takeover needs it as well as notInGossip
+	lastVoteStalled bool
+	// roleIntentDivergence is how this node's role differs from its role intent at the latest check - warned about
+	// once each time it changes
+	roleIntentDivergence string
+}
+
+// RunWatch runs on the passive node as a daemon - it checks the active identity every failover.watch.interval and
+// once a failure condition has persisted for failover.watch.failure_threshold asks the active peer's agent to hand
+// over, or with failover.watch.takeover promotes this node itself when the active identity has left gossip and stopped voting. Drills
+// scheduled in failover.drill.schedule are run between checks
+func (v *Validator) RunWatch() (err error) {
+	conditions := v.Watch.Conditions
+	watching := conditions.NotInGossip || conditions.Delinquent || conditions.VoteCreditsStalled
+	if !watching && v.DrillSchedule == nil {
+		return fmt.Errorf("failover.watch.conditions has no conditions enabled and failover.drill.schedule is not set - nothing to watch for")
+	}
+	if watching && v.Watch.Peer == "" {
+		return fmt.Errorf("failover.watch.peer is required when more than one peer is declared")
+	}
+
+	if watching {
+		log.Info().
+			Str("peer", v.Watch.Peer).
+			Str("active_pubkey", v.Identities.Active.PubKey()).
+			Dur("interval", v.watchInterval).
+			Dur("failure_threshold", v.watchFailureThreshold).
+			Bool("not_a_drill", v.Watch.NotADrill).
+			Msg("Watching active identity")
+	}
+	nextDrillAt := v.nextDrillAt(time.Now())
+	if !nextDrillAt.IsZero() {
+		log.Info().
+			Str("schedule", v.DrillSchedule.String()).
+			Str("peer", v.drillPeer().Name).
+			Time("next_drill_at", nextDrillAt).
+			Msg("Scheduling drills")
+	}
+
+	stopNotify := systemd.Ready("watch")
+	defer stopNotify()
+
+	// the tower file is watched for drift between checks while this node is passive
+	var driftMonitor *failover.TowerDriftMonitor
+	defer func() { v.syncTowerDriftMonitor(driftMonitor, false) }()
+
+	watch := &activeIdentityWatch{}
+	for {
+		// the watchdog is fed by this loop so systemd restarts a watch stuck on a hung check
+		systemd.Feed("watch")
+
+		if !nextDrillAt.IsZero() && !time.Now().Before(nextDrillAt) {
+			driftMonitor = v.syncTowerDriftMonitor(driftMonitor, false)
+			v.runScheduledDrill(v.Failover)
+			nextDrillAt = v.nextDrillAt(time.Now())
+			log.Info().Time("next_drill_at", nextDrillAt).Msg("Waiting for next scheduled drill")
+			// the drill switched nothing but the failure conditions are tracked afresh
+			watch = &activeIdentityWatch{}
+			continue
+		}
+
+		failOver := watching && v.watchCheck(watch, time.Now())
+		if !watching {
+			// only drilling - the role is still re-read so the drift monitor only runs while passive
+			if err := v.GossipNode.Refresh(v.solanaRPCClient); err != nil {
+				log.Warn().Err(err).Msg("failed to refresh this node's gossip identity")
+			}
+		}
+		driftMonitor = v.syncTowerDriftMonitor(driftMonitor, v.IsPassive() && !failOver)
+		if !failOver {
+			systemd.Sleep("watch", v.untilNextCheck(nextDrillAt))
+			continue
+		}
+
+		// a sick passive node would take over in no better shape
+		if !v.solanaRPCClient.IsLocalNodeHealthy() {
+			log.Error().Msg("🔴 Not failing over - this node isn't healthy")
+			systemd.Sleep("watch", v.untilNextCheck(nextDrillAt))
+			continue
+		}
+
+		startTime := time.Now()
+		err = v.watchFailover(watch)
+		if err != nil {
+			log.Error().Err(err).Dur("duration", time.Since(startTime)).Msg("🔴 Unattended failover failed")
+		} else {
+			log.Info().Dur("duration", time.Since(startTime)).Msg("🟢 Unattended failover complete")
+		}
+
+		watch = &activeIdentityWatch{}
+		log.Info().Dur("cooldown", v.watchCooldown).Msg("Pausing watch after failover attempt")
+		systemd.Sleep("watch", v.watchCooldown)
+	}
+}
+
+// syncTowerDriftMonitor starts the tower drift monitor when it should run and isn't, and stops it when it shouldn't -
+// it returns the running monitor, nil when none is. It never runs while failing over: the node's own tower file is
+// about to be written and the failover server runs one of its own while it waits
+func (v *Validator) syncTowerDriftMonitor(monitor *failover.TowerDriftMonitor, run bool) *failover.TowerDriftMonitor {
+	if !run || !v.TowerDriftMonitor.Enabled {
+		if monitor != nil {
+			monitor.Stop()
+		}
+		return nil
+	}
+	if monitor != nil {
+		return monitor
+	}
+	monitor = failover.NewTowerDriftMonitor(failover.TowerDriftMonitorParams{
+		Config: failover.TowerDriftMonitorConfig(v.TowerDriftMonitor),
+		NodeInfo: &failover.NodeInfo{
+			Hostname:   v.Hostname,
+			PublicIP:   v.PublicIP,
+			Identities: failover.NewNodeIdentities(v.Identities),
+			TowerFile:  v.TowerFile,
+		},
+		SolanaRPCClient: v.solanaRPCClient,
+	})
+	monitor.Start()
+	return monitor
+}
+
+// watchCheck checks the active identity once and returns true when a failure condition has persisted for the
+// failure threshold - rpc errors don't count as failures so an unreachable rpc never triggers a failover
+func (v *Validator) watchCheck(watch *activeIdentityWatch, now time.Time) (failOver bool) {
+	// this node's role changes after a failover so re-read it from gossip each check
+	if err := v.GossipNode.Refresh(v.solanaRPCClient); err != nil {
+		log.Warn().Err(err).Msg("failed to refresh this node's gossip identity - skipping check")
+		return false
+	}
+	watch.roleIntentDivergence = v.warnRoleIntentDivergence(watch.roleIntentDivergence)
+	if !v.IsPassive() {
+		log.Debug().Msg("this node is not passive - nothing to watch")
+		*watch = activeIdentityWatch{roleIntentDivergence: watch.roleIntentDivergence}
+		return false
+	}
+
+	failures, err := v.activeIdentityFailures(watch)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to check active identity - not counted as a failure")
+		return false
+	}
+
+	if len(failures) == 0 {
+		if !watch.failingSince.IsZero() {
+			log.Info().Dur("failed_for", now.Sub(watch.failingSince)).Msg("✅ Active identity recovered")
+		}
+		watch.failingSince = time.Time{}
+		return false
+	}
+
+	if watch.failingSince.IsZero() {
+		watch.failingSince = now
+	}
+	failingFor := now.Sub(watch.failingSince)
+	log.Warn().
+		Strs("failures", failures).
+		Dur("failing_for", failingFor).
+		Dur("failure_threshold", v.watchFailureThreshold).
+		Msg("⚠️ Active identity failing")
+
+	if failingFor < v.watchFailureThreshold {
+		return false
+	}
+	// a node that left gossip but still votes would vote alongside this one once it takes over
+	if watch.notInGossip && v.Watch.Takeover && !watch.lastVoteStalled {
+		log.Warn().Msg("⚠️ Active identity not in gossip but its last vote is still advancing - not taking over until it stops")
+		return false
+	}
+	return true
+}
+
+// activeIdentityFailures returns the enabled failure conditions the active identity currently meets
+func (v *Validator) activeIdentityFailures(watch *activeIdentityWatch) (failures []string, err error) {
+	conditions := v.Watch.Conditions
+	activePubkey := v.Identities.Active.PubKey()
+
+	watch.notInGossip = false
+	if conditions.NotInGossip {
+		_, err = v.solanaRPCClient.NodeFromPubkey(activePubkey)
+		if errors.Is(err, solana.ErrNodeNotFound) {
+			watch.notInGossip = true
+			failures = append(failures, "not in gossip")
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to query gossip: %w", err)
+		}
+	}
+
+	// a takeover needs the last vote to have stopped advancing, whichever conditions are enabled
+	watch.lastVoteStalled = false
+	if !conditions.Delinquent && !conditions.VoteCreditsStalled && !(watch.notInGossip && v.Watch.Takeover) {
+		watch.previousVoteStatus = nil
+		return failures, nil
+	}
+
+	voteStatus, err := v.solanaRPCClient.GetVoteAccountStatus(activePubkey)
+	if err != nil {
+		return nil, err
+	}
+	previousVoteStatus := watch.previousVoteStatus
+	watch.previousVoteStatus = &voteStatus
+	watch.lastVoteStalled = !voteStatus.Found ||
+		(previousVoteStatus != nil && previousVoteStatus.Found && voteStatus.LastVote <= previousVoteStatus.LastVote)
+
+	if !voteStatus.Found {
+		return append(failures, "vote account not found"), nil
+	}
+	if conditions.Delinquent && voteStatus.Delinquent {
+		failures = append(failures, "delinquent")
+	}
+	// credits reset at epoch boundaries so only a last vote that hasn't moved either counts as stalled
+	if conditions.VoteCreditsStalled && previousVoteStatus != nil && previousVoteStatus.Found &&
+		voteStatus.EpochCredits <= previousVoteStatus.EpochCredits && voteStatus.LastVote <= previousVoteStatus.LastVote {
+		failures = append(failures, "vote credits stalled")
+	}
+
+	return failures, nil
+}
+
+// watchFailover fails over once the active identity has been failing for the failure threshold - a handover needs
+// the active node so when the active identity has left gossip this node can only take over itself
+func (v *Validator) watchFailover(watch *activeIdentityWatch) error {
+	if watch.notInGossip {
+		if !v.Watch.Takeover {
+			return fmt.Errorf("active identity %s is not in gossip so its node can't hand over - set failover.watch.takeover to promote this node instead", v.Identities.Active.PubKey())
+		}
+		if !watch.lastVoteStalled {
+			return fmt.Errorf("active identity %s is not in gossip but its last vote is still advancing - not taking over", v.Identities.Active.PubKey())
+		}
+		log.Error().Msgf("🚨 Active identity not in gossip - taking over as %s", style.RenderActiveString(v.Identities.Active.PubKey(), false))
+		return v.SwapIdentity(SwapParams{
+			NotADrill:        v.Watch.NotADrill,
+			NoWaitForHealthy: true,
+			FromTowerReplica: v.TowerReplication.Enabled,
+		})
+	}
+
+	log.Error().Msgf("🚨 Active identity failing - asking %s to hand over", style.RenderActiveString(v.Watch.Peer, false))
+	return v.Failover(v.WatchFailoverParams())
+}
+
+// WatchFailoverParams returns the failover parameters for a handover started by the watch daemon - the active
+// node is failing so neither node waits for it to be healthy or clear of leader slots
+func (v *Validator) WatchFailoverParams() FailoverParams {
+	return FailoverParams{
+		NotADrill:             v.Watch.NotADrill,
+		NoWaitForHealthy:      true,
+		NoMinTimeToLeaderSlot: true,
+		PeerName:              v.Watch.Peer,
+		WaitTimeout:           v.watchTimeout,
+		ViaAgent:              true,
+		PeerNoWaitForHealthy:  true,
+		NonInteractive:        true,
+		Force:                 true,
+	}
+}
+
+// RunPostMonitor monitors the active identity's vote credits as after a failover - run detached by the failover
+// server so the operator gets their terminal back, baselineRank is the pre-failover vote credit rank
+func (v *Validator) RunPostMonitor(baselineRank int, isDryRunFailover bool) error {
+	return failover.RunPostMonitor(failover.PostMonitorParams{
+		Hostname:         v.Hostname,
+		PublicIP:         v.PublicIP,
+		Identities:       v.Identities,
+		SolanaRPCClient:  v.solanaRPCClient,
+		MonitorConfig:    convertMonitorConfig(v.Monitor),
+		BaselineRank:     baselineRank,
+		IsDryRunFailover: isDryRunFailover,
+		Events:           v.Events,
+		Notifications:    v.Notifications,
+	})
+}
+
+// NodeStatus is a snapshot of this node's role, gossip state, tower file and peer reachability
+type NodeStatus struct {
+	Hostname        string
+	PublicIP        string
+	Role            string
+	GossipPubkey    string
+	ClientVersion   string
+	Bin             BinMetadata
+	TowerFile       string
+	TowerFileExists bool
+	TowerFileSize   int64
+	Peers           []PeerReachability
+	// RoleIntent is the role this node was last given by a failover or swap - empty when none is recorded - and
+	// RoleIntentDivergence how its current role differs from it
+	RoleIntent           string
+	RoleIntentDivergence string
+}
+
+// PeerReachability is whether a peer's failover server answered a probe - Err is nil when it did
+type PeerReachability struct {
+	Peer Peer
+	Err  error
+}
+
+// Status refreshes this node's gossip state and probes every peer's failover server in parallel
+func (v *Validator) Status() (status NodeStatus, err error) {
+	if err := v.GossipNode.Refresh(v.solanaRPCClient); err != nil {
+		return status, fmt.Errorf("failed to refresh gossip node: %w", err)
+	}
+
+	status = NodeStatus{
+		Hostname:      v.Hostname,
+		PublicIP:      v.PublicIP,
+		GossipPubkey:  v.GossipNode.PubKey(),
+		ClientVersion: v.GossipNode.Version(),
+		Bin:           v.BinMetadata,
+		TowerFile:     v.TowerFile,
+	}
+	status.Role = v.gossipRole()
+
+	intent, ok, err := v.RoleIntent.Read()
+	if err != nil {
+		return status, err
+	}
+	if ok {
+		status.RoleIntent = intent.Role
+		status.RoleIntentDivergence = intent.Divergence(status.Role, status.GossipPubkey)
+	}
+
+	if info, err := os.Stat(v.TowerFile); err == nil {
+		status.TowerFileExists = true
+		status.TowerFileSize = info.Size()
+	} else if !os.IsNotExist(err) {
+		return status, fmt.Errorf("failed to stat tower file %s: %w", v.TowerFile, err)
 	}
 
-	v.logger.Debug().
-		Str("public_ip", v.PublicIP).
-		Msg("public ip set")
+	status.Peers = make([]PeerReachability, 0, len(v.Peers))
+	results := make(chan PeerReachability, len(v.Peers))
+	for _, peer := range v.Peers {
+		go func(peer Peer) {
+			results <- PeerReachability{Peer: peer, Err: v.peerProbe(peer.Address)}
+		}(peer)
+	}
+	for range v.Peers {
+		status.Peers = append(status.Peers, <-results)
+	}
+	sort.Slice(status.Peers, func(i, j int) bool {
+		return status.Peers[i].Peer.Name < status.Peers[j].Peer.Name
+	})
 
-	return nil
+	return status, nil
 }
 
-// configureMinimumTimeToLeaderSlot ensures the minimum time to leader slot is valid and sets it
-func (v *Validator) configureMinimumTimeToLeaderSlot(timeToLeaderSlotDurationString string) (err error) {
-	minimumTimeToLeaderSlotDuration, err := time.ParseDuration(timeToLeaderSlotDurationString)
-	if err != nil {
-		return fmt.Errorf(
-			"failed to parse minimum time to leader slot %s: %w",
-			timeToLeaderSlotDurationString,
-			err,
-		)
+// TableString renders the status as a table of this node followed by its peers
+func (s NodeStatus) TableString() string {
+	towerFile := "missing"
+	if s.TowerFileExists {
+		towerFile = fmt.Sprintf("%s bytes", style.FormatInt(s.TowerFileSize))
 	}
-	v.MinimumTimeToLeaderSlot = minimumTimeToLeaderSlotDuration
-	v.logger.Debug().
-		Str("minimum_time_to_leader_slot", v.MinimumTimeToLeaderSlot.String()).
-		Msg("minimum time to leader slot set")
-	return nil
-}
-
-// GetHostname returns the hostname - can be overridden in tests
-func (v *Validator) GetHostname() (string, error) {
-	return os.Hostname()
-}
 
-// configureHostname ensures the hostname is valid and sets it
-func (v *Validator) configureHostname(hostname string) (err error) {
-	if hostname != "" {
-		v.Hostname = hostname
-		v.logger.Debug().
-			Str("hostname", v.Hostname).
-			Msg("hostname set in config")
-		return nil
+	roleStyle := style.TableCellStyle.Align(lipgloss.Left)
+	switch s.Role {
+	case constants.NodeRoleActive:
+		roleStyle = roleStyle.Foreground(style.ColorActive)
+	case constants.NodeRolePassive:
+		roleStyle = roleStyle.Foreground(style.ColorPassive)
+	default:
+		roleStyle = roleStyle.Foreground(style.ColorWarning)
 	}
 
-	hostname, err = v.GetHostname()
-	if err != nil {
-		return err
+	rows := [][]string{
+		{"Role", s.Role},
+		{"Hostname", s.Hostname},
+		{"Public IP", s.PublicIP},
+		{"Gossip pubkey", s.GossipPubkey},
+		{"Client version", s.ClientVersion},
+		{"Validator binary", s.binString()},
+		{"Tower file", fmt.Sprintf("%s (%s)", s.TowerFile, towerFile)},
+		{"Role intent", s.roleIntentString()},
+	}
+	warningRows := make(map[int]bool)
+	// the validator binary was upgraded without restarting the validator
+	warningRows[5] = s.IsBinVersionMismatch()
+	// an active node can't vote safely without its tower file
+	warningRows[6] = !s.TowerFileExists && s.Role == constants.NodeRoleActive
+	// the identity was changed outside of failovers and swaps
+	warningRows[7] = s.RoleIntentDivergence != ""
+	for _, peer := range s.Peers {
+		reachability := "reachable"
+		if peer.Err != nil {
+			reachability = fmt.Sprintf("unreachable: %s", peer.Err)
+			warningRows[len(rows)] = true
+		}
+		rows = append(rows, []string{fmt.Sprintf("Peer %s (%s)", peer.Peer.Name, peer.Peer.Address), reachability})
 	}
-	v.Hostname = hostname
-	v.logger.Debug().
-		Str("hostname", v.Hostname).
-		Msg("hostname set")
-	return nil
-}
 
-// configureServer ensures the server is valid and sets it
-func (v *Validator) configureServer(cfg ServerConfig) (err error) {
-	v.FailoverServerConfig = cfg
-	v.logger.Debug().
-		Int("port", v.FailoverServerConfig.Port).
-		Msg("server set")
-	return nil
+	return style.RenderTable(
+		[]string{"", "Status"},
+		rows,
+		func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return style.TableHeaderStyle
+			}
+			cellStyle := style.TableCellStyle.Align(lipgloss.Left)
+			if col == 1 && row == 0 {
+				return roleStyle
+			}
+			if col == 1 && warningRows[row] {
+				return cellStyle.Foreground(style.ColorWarning)
+			}
+			return cellStyle
+		},
+	)
 }
 
-// configureMonitor ensures the monitor is valid and sets it
-func (v *Validator) configureMonitor(cfg MonitorConfig) (err error) {
-	v.Monitor = cfg
-	v.logger.Debug().
-		Int("credit_samples_count", v.Monitor.CreditSamples.Count).
-		Str("credit_samples_interval", v.Monitor.CreditSamples.Interval).
-		Msg("monitor set")
-	return nil
+// roleIntentString renders the role this node was last given and whether it still holds it
+func (s NodeStatus) roleIntentString() string {
+	switch {
+	case s.RoleIntent == "":
+		return "none recorded"
+	case s.RoleIntentDivergence != "":
+		return s.RoleIntentDivergence
+	}
+	return fmt.Sprintf("%s - matches", s.RoleIntent)
 }
 
-// configureGossipNode ensures the gossip node is valid and sets it
-func (v *Validator) configureGossipNode() (err error) {
-	v.GossipNode, err = v.solanaRPCClient.NodeFromIP(v.PublicIP)
-	if err != nil {
-		return err
+// binString renders the validator client and version detected from the validator binary
+func (s NodeStatus) binString() string {
+	if s.Bin.Version == "" {
+		return s.Bin.Client
 	}
-	v.logger.Debug().
-		Str("public_ip", v.GossipNode.IP()).
-		Str("pubkey", v.GossipNode.PubKey()).
-		Msg("gossip node set")
-	return nil
+	binString := fmt.Sprintf("%s %s", s.Bin.Client, s.Bin.Version)
+	if s.IsBinVersionMismatch() {
+		binString += " - differs from gossip, restart the validator to run it"
+	}
+	return binString
 }
 
-// makeActive makes this validator active
-func (v *Validator) makeActive(params FailoverParams) (err error) {
-	log.Debug().Msg("making this validator active")
+// IsBinVersionMismatch returns true when the validator binary reports a different version to the one the validator
+// runs in gossip - the binary was upgraded without restarting the validator
+func (s NodeStatus) IsBinVersionMismatch() bool {
+	return s.Bin.Version != "" && s.ClientVersion != "" && s.Bin.Version != s.ClientVersion
+}
 
-	if v.IsActive() {
-		return fmt.Errorf("this validator is already active - nothing to do")
+// Observe follows a failover on a passive peer's failover server read-only, logging its progress until the failover
+// completes or aborts - the peer is required unless only one is configured
+func (v *Validator) Observe(peerName string) (err error) {
+	if peerName == "" {
+		if len(v.Peers) != 1 {
+			return fmt.Errorf("%d peers configured and none selected - pass --peer <name> to choose the passive peer to observe", len(v.Peers))
+		}
+		for name := range v.Peers {
+			peerName = name
+		}
+	}
+	peer, ok := v.Peers[peerName]
+	if !ok {
+		return fmt.Errorf("peer %s not found in failover.peers", peerName)
 	}
 
 	log.Info().
-		Str("public_ip", v.PublicIP).
-		Str("pubkey", v.Identities.Passive.PubKey()).
-		Msgf("This validator is currently %s", style.RenderPassiveString(strings.ToUpper(constants.NodeRolePassive), false))
+		Str("peer_name", peerName).
+		Str("peer_address", peer.Address).
+		Msgf("👀 Observing failovers on %s - waiting for the active node to connect", style.RenderPassiveString(peerName, false))
+
+	return failover.Observe(failover.ObserveConfig{
+		ServerName:        peerName,
+		ServerAddress:     peer.Address,
+		ActiveIdentity:    v.Identities.Active,
+		ClientCertificate: v.ClientCertificate,
+	}, func(update failover.ObserverUpdate) {
+		event := log.Info()
+		switch update.Type {
+		case events.TypeAbort, events.TypeIdentityGapAlarm:
+			event = log.Error()
+		}
+		event.
+			Time("time", update.Time).
+			Str("hostname", update.Hostname).
+			Str("failover_id", update.FailoverID).
+			Str("type", update.Type).
+			Msgf("👀 %s", update.Message)
+	})
+}
 
-	// check gossip for active peer and ensure its pubkey is the same as what this node would set itself to
-	_, err = v.solanaRPCClient.NodeFromPubkey(v.Identities.Active.PubKey())
+// AbortFailover asks the passive peer to abort its pending failover - both nodes stop without changing anything as long
+// as the active node hasn't begun switching identity
+func (v *Validator) AbortFailover(peerName, reason string) (err error) {
+	if peerName == "" {
+		if len(v.Peers) != 1 {
+			return fmt.Errorf("%d peers configured and none selected - pass --peer <name> to choose the passive peer to abort the failover on", len(v.Peers))
+		}
+		for name := range v.Peers {
+			peerName = name
+		}
+	}
+	peer, ok := v.Peers[peerName]
+	if !ok {
+		return fmt.Errorf("peer %s not found in failover.peers", peerName)
+	}
+
+	log.Warn().
+		Str("peer_name", peerName).
+		Str("peer_address", peer.Address).
+		Str("reason", reason).
+		Msgf("🛑 Asking %s to abort its pending failover", style.RenderPassiveString(peerName, false))
+
+	err = failover.RequestAbort(failover.AbortConfig{
+		ServerName:        peerName,
+		ServerAddress:     peer.Address,
+		ActiveIdentity:    v.Identities.Active,
+		ClientCertificate: v.ClientCertificate,
+		Request: failover.AbortRequest{
+			Hostname: v.Hostname,
+			Reason:   reason,
+		},
+	})
 	if err != nil {
-		return fmt.Errorf(
-			"active peer not found in gossip with pubkey %s from file %s: %w",
-			v.Identities.Active.PubKey(),
-			v.Identities.Active.KeyFile,
-			err,
-		)
+		return err
 	}
 
-	// delete the tower file if it exists and auto empty when passive is true
-	if v.TowerFileAutoDeleteWhenPassive && utils.FileExists(v.TowerFile) {
-		log.Debug().
-			Str("tower_file", v.TowerFile).
-			Msg("deleting tower file because validator.tower.auto_empty_when_passive is true")
+	log.Info().Msgf("🛑 Failover aborted on %s - neither node changed identity", peerName)
+	return nil
+}
 
-		if err = utils.RemoveFile(v.TowerFile); err != nil {
-			return err
+// ReleaseHeldFailover asks the passive peer to release the failover the active node holds with --hold - the active
+// node then triggers the switch as though its operator had
+func (v *Validator) ReleaseHeldFailover(peerName string) (err error) {
+	if peerName == "" {
+		if len(v.Peers) != 1 {
+			return fmt.Errorf("%d peers configured and none selected - pass --peer <name> to choose the passive peer holding the failover", len(v.Peers))
+		}
+		for name := range v.Peers {
+			peerName = name
 		}
 	}
-
-	// if the tower file exists and auto empty when passive is false, return an error
-	if !v.TowerFileAutoDeleteWhenPassive && utils.FileExists(v.TowerFile) {
-		return fmt.Errorf(
-			"tower file exists and validator.tower.auto_empty_when_passive is false - delete it and re-run: %s",
-			v.TowerFile,
-		)
+	peer, ok := v.Peers[peerName]
+	if !ok {
+		return fmt.Errorf("peer %s not found in failover.peers", peerName)
 	}
 
-	// create a QUIC server that listens for the active node to connect and decide what to do
-	failoverServer, err := failover.NewServerFromConfig(failover.ServerConfig{
-		Port:              v.FailoverServerConfig.Port,
-		HeartbeatInterval: v.FailoverServerConfig.HeartbeatInterval,
-		StreamTimeout:     v.FailoverServerConfig.StreamTimeout,
-		PassiveNodeInfo: &failover.NodeInfo{
-			Hostname:                       v.Hostname,
-			PublicIP:                       v.PublicIP,
-			Identities:                     v.Identities,
-			TowerFile:                      v.TowerFile,
-			SetIdentityCommand:             v.SetIdentityActiveCommand,
-			ClientVersion:                  v.GossipNode.Version(),
-			SolanaValidatorFailoverVersion: pkgconstants.AppVersion,
-		},
-		SolanaRPCClient:  v.solanaRPCClient,
-		IsDryRunFailover: !params.NotADrill,
-		Hooks:            v.Hooks,
-		MonitorConfig:    convertMonitorConfig(v.Monitor),
+	log.Info().
+		Str("peer_name", peerName).
+		Str("peer_address", peer.Address).
+		Msgf("▶️ Asking %s to release the held failover", style.RenderPassiveString(peerName, false))
+
+	err = failover.RequestRelease(failover.ReleaseConfig{
+		ServerName:        peerName,
+		ServerAddress:     peer.Address,
+		ActiveIdentity:    v.Identities.Active,
+		ClientCertificate: v.ClientCertificate,
+		Request:           failover.ReleaseRequest{Hostname: v.Hostname},
 	})
 	if err != nil {
 		return err
 	}
 
-	failoverServer.Start()
-
+	log.Info().Msgf("▶️ Held failover released through %s - the active node triggers the switch", peerName)
 	return nil
 }
 
@@ -640,28 +3430,55 @@ func (v *Validator) makePassive(params FailoverParams) (err error) {
 	}
 
 	// select passive peer to connect to from declared peers
-	selectedPassivePeer, err := v.selectPassivePeer()
+	selectedPassivePeer, err := v.selectPeer(params.PeerName, constants.NodeRolePassive, params.ReloadPeers, params.NonInteractive)
 	if err != nil {
 		return err
 	}
 
+	// connect to the port the passive peer listens on when it told us
+	serverAddress := selectedPassivePeer.Address
+	if params.PeerPort != 0 {
+		serverAddress = net.JoinHostPort(utils.HostFromAddress(serverAddress), strconv.Itoa(params.PeerPort))
+	}
+
 	// connect to the passive peer and follow its lead to handover as active
 	failoverClient, err := failover.NewClientFromConfig(failover.ClientConfig{
 		ServerName:                     selectedPassivePeer.Name,
-		ServerAddress:                  selectedPassivePeer.Address,
+		ServerAddress:                  serverAddress,
 		MinTimeToLeaderSlot:            params.MinTimeToLeaderSlot,
 		WaitMinTimeToLeaderSlotEnabled: !params.NoMinTimeToLeaderSlot,
 		SolanaRPCClient:                v.solanaRPCClient,
+		Identities:                     v.Identities,
 		ActiveNodeInfo: &failover.NodeInfo{
 			Hostname:                       v.Hostname,
 			PublicIP:                       v.PublicIP,
-			Identities:                     v.Identities,
+			Identities:                     failover.NewNodeIdentities(v.Identities),
 			TowerFile:                      v.TowerFile,
 			SetIdentityCommand:             v.SetIdentityPassiveCommand,
 			ClientVersion:                  v.GossipNode.Version(),
+			Client:                         v.BinMetadata.Client,
+			BinVersion:                     v.BinMetadata.Version,
 			SolanaValidatorFailoverVersion: pkgconstants.AppVersion,
 		},
-		Hooks: v.Hooks,
+		Hooks:                v.Hooks,
+		DialRetries:          v.FailoverClientConfig.DialRetries,
+		DialRetryInterval:    v.FailoverClientConfig.DialRetryInterval,
+		DialRetryMaxInterval: v.FailoverClientConfig.DialRetryMaxInterval,
+		QUIC:                 v.failoverClientQUIC,
+		LogSlotContext:       failover.LogSlotContextConfig(v.LogSlotContext),
+		PeerPins:             v.PeerPins,
+		ClientCertificate:    v.ClientCertificate,
+		ServerCAs:            v.ServerCAs,
+		Output:               params.Output,
+		Notifications:        v.Notifications,
+		AuditLog:             v.AuditLog,
+		RoleIntent:           v.RoleIntent,
+		Tracing:              v.Tracing,
+		WaitForHealthy:       params.waitForHealthy,
+		PathMTUProbe:         v.PathMTUProbe,
+		ScheduledSwitchAt:    params.SwitchAt,
+		Standbys:             v.standbyPeers(selectedPassivePeer.Name),
+		WarmHold:             params.WarmHold,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to connect to peer %s: %w", selectedPassivePeer.Name, err)
@@ -675,10 +3492,17 @@ func (v *Validator) makePassive(params FailoverParams) (err error) {
 // waitUntilHealthy waits until the validator is healthy and synced
 func (v *Validator) waitUntilHealthy() (err error) {
 	startTime := time.Now()
-	sp := spinner.New().
+	sp := ui.NewSpinner().
 		TitleStyle(style.SpinnerTitleStyle).
 		Title("waiting for validator to be healthy and synced...")
 
+	// only a node about to go active must be caught up enough to vote straight away - an active node falling behind
+	// is a reason to fail over, not to wait
+	maxSlotLag := v.MaxSlotLag
+	if !v.IsPassive() {
+		maxSlotLag = 0
+	}
+
 	sp.ActionWithErr(func(ctx context.Context) error {
 		for {
 			if !v.solanaRPCClient.IsLocalNodeHealthy() {
@@ -691,6 +3515,24 @@ func (v *Validator) waitUntilHealthy() (err error) {
 				continue
 			}
 
+			if maxSlotLag > 0 {
+				slotLag, err := v.solanaRPCClient.GetSlotLag()
+				if err != nil {
+					sp.Title(style.RenderWarningStringf("waiting for slot lag: %v", err))
+					time.Sleep(2 * time.Second)
+					continue
+				}
+				if slotLag > maxSlotLag {
+					sp.Title(style.RenderWarningStringf(
+						"waiting for validator to catch up - %d slots behind the network, at most %d allowed",
+						slotLag,
+						maxSlotLag,
+					))
+					time.Sleep(2 * time.Second)
+					continue
+				}
+			}
+
 			sp.Title(
 				style.RenderActiveStringf(
 					"validator is healthy and synced - elapsed time %s",
@@ -704,50 +3546,403 @@ func (v *Validator) waitUntilHealthy() (err error) {
 	return sp.Run()
 }
 
-// selectPassivePeer allows selection of a peer from the list of peers
-func (v *Validator) selectPassivePeer() (selectedPeer Peer, err error) {
+// selectPeer allows selection of a peer in the given role (the one to fail over with) from the list of peers - with
+// reloadPeers set the prompt also offers to reload the peers from config and prompt again, with nonInteractive set (or
+// stdin not a terminal) it errors instead of prompting
+func (v *Validator) selectPeer(
+	peerName, role string,
+	reloadPeers func() (PeersConfig, error),
+	nonInteractive bool,
+) (selectedPeer Peer, err error) {
+	renderPeerName := func(name string) string {
+		if role == constants.NodeRoleActive {
+			return style.RenderActiveString(name, false)
+		}
+		return style.RenderPassiveString(name, false)
+	}
+
+	// a named peer skips the selection prompt
+	if peerName != "" {
+		peer, ok := v.Peers[peerName]
+		if !ok {
+			return selectedPeer, fmt.Errorf("peer %s not found in failover.peers", peerName)
+		}
+		log.Info().
+			Str("peer_name", peerName).
+			Str("peer_address", peer.Address).
+			Msgf("Failovering with %s peer %s", role, renderPeerName(peerName))
+		return peer, nil
+	}
+
 	// If there's only one peer, automatically select it
 	if len(v.Peers) == 1 {
 		for name, peer := range v.Peers {
 			log.Info().
 				Str("peer_name", name).
 				Str("peer_address", peer.Address).
-				Msgf("Failovering to passive peer %s", style.RenderPassiveString(name, false))
+				Msgf("Failovering with %s peer %s", role, renderPeerName(name))
 			return peer, nil
 		}
 	}
 
-	// Multiple peers - show selection prompt
-	huhPeerOptions := make([]huh.Option[string], 0)
-	for name, peer := range v.Peers {
-		selectionKey := style.RenderPassiveString(name, false)
+	// Multiple peers - passive peers are ranked so the best candidate is listed first
+	peerNames := make([]string, 0, len(v.Peers))
+	for name := range v.Peers {
+		peerNames = append(peerNames, name)
+	}
+	sort.Strings(peerNames)
+
+	peerSummaries := make(map[string]string, len(v.Peers))
+	if role == constants.NodeRolePassive {
+		statuses := v.rankPassivePeers()
+		peerNames = peerNames[:0]
+		for i, status := range statuses {
+			peerNames = append(peerNames, status.Peer.Name)
+			peerSummaries[status.Peer.Name] = status.summary()
+			log.Info().
+				Int("rank", i+1).
+				Str("peer_name", status.Peer.Name).
+				Str("status", status.summary()).
+				Msg("Ranked passive peer")
+		}
+
+		best := statuses[0]
+		if v.PeerSelection.AutoSelect {
+			if best.isHealthyCandidate() {
+				log.Info().
+					Str("peer_name", best.Peer.Name).
+					Str("peer_address", best.Peer.Address).
+					Msgf("Failovering with %s peer %s - best ranked", role, renderPeerName(best.Peer.Name))
+				return best.Peer, nil
+			}
+			log.Warn().
+				Str("peer_name", best.Peer.Name).
+				Str("status", best.summary()).
+				Msg("Best ranked passive peer is not confirmed healthy - not auto-selecting it")
+		}
+	}
+
+	if nonInteractive || v.stdinIsTerminal == nil || !v.stdinIsTerminal() {
+		return selectedPeer, fmt.Errorf(
+			"%d %s peers configured and none selected - pass --peer <name> to choose one without prompting",
+			len(peerNames), role,
+		)
+	}
+
+	peerOptions := make([]ui.Option, 0, len(peerNames))
+	for _, name := range peerNames {
+		selectionKey := renderPeerName(name)
+		if summary, ok := peerSummaries[name]; ok {
+			selectionKey = fmt.Sprintf("%s %s", selectionKey, style.RenderGreyString(summary, false))
+		}
 		if zerolog.GlobalLevel() == zerolog.DebugLevel {
 			selectionKey = fmt.Sprintf(
 				"%s %s",
-				style.RenderPassiveString(name, false),
-				style.RenderGreyString(peer.Address, false),
+				selectionKey,
+				style.RenderGreyString(v.Peers[name].Address, false),
 			)
 		}
-		huhPeerOptions = append(huhPeerOptions, huh.NewOption(selectionKey, name))
+		peerOptions = append(peerOptions, ui.Option{Key: selectionKey, Value: name})
+	}
+	if reloadPeers != nil {
+		peerOptions = append(peerOptions, ui.Option{
+			Key:   style.RenderGreyString("↻ reload peers from config", false),
+			Value: reloadPeersOptionValue,
+		})
 	}
 
-	var selectedPeerName string
+	// start on the first listed (best ranked) peer
+	selectedPeerName := peerNames[0]
 
-	err = huh.NewSelect[string]().
-		Title("Select a passive peer to failover to:").
-		Options(huhPeerOptions...).
-		Value(&selectedPeerName).
-		Run()
+	err = ui.Select(fmt.Sprintf("Select the %s peer to failover with:", role), peerOptions, &selectedPeerName)
 
 	if err != nil {
 		return selectedPeer, fmt.Errorf("failed to select peer: %w", err)
 	}
 
+	if selectedPeerName == reloadPeersOptionValue {
+		err = v.reloadPeers(reloadPeers)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to reload peers - keeping the current ones")
+		}
+		return v.selectPeer(peerName, role, reloadPeers, nonInteractive)
+	}
+
 	log.Debug().Msgf("selected peer: %s address: %s", selectedPeerName, v.Peers[selectedPeerName].Address)
 
 	return v.Peers[selectedPeerName], nil
 }
 
+// reloadPeers replaces the peers with freshly loaded ones - the current peers are kept when the new ones fail to
+// load or are invalid
+func (v *Validator) reloadPeers(loadPeers func() (PeersConfig, error)) error {
+	cfg, err := loadPeers()
+	if err != nil {
+		return fmt.Errorf("failed to load peers: %w", err)
+	}
+
+	currentPeers := v.Peers
+	err = v.configurePeers(cfg)
+	if err == nil {
+		err = v.discoverPeers()
+	}
+	if err != nil {
+		v.Peers = currentPeers
+		return err
+	}
+
+	log.Info().Int("peers", len(v.Peers)).Msg("Reloaded peers from config")
+	return nil
+}
+
+// peerSlotLagBucket is how many slots of lag rank the same - peers' slot lags drift by a few slots from one query to
+// the next so finer differences would bury everything ranked after it
+const peerSlotLagBucket = 10
+
+// peerStatus is what is known about a passive peer's fitness to take over, used to rank passive peers
+type peerStatus struct {
+	Peer     Peer
+	InGossip bool
+	Version  string
+	// SameVersion is true when the peer runs the same client version as this node
+	SameVersion bool
+	// Healthy and SlotLag are nil when the peer doesn't advertise rpc in gossip or didn't answer in time
+	Healthy *bool
+	SlotLag *uint64
+	// RTT is how long the peer's failover server took to answer a probe - nil when it didn't
+	RTT *time.Duration
+	// LastFailoverAt is the last successful failover or drill with the peer - zero when unknown
+	LastFailoverAt time.Time
+}
+
+// isHealthyCandidate returns true if the peer is in gossip and confirmed healthy
+func (s peerStatus) isHealthyCandidate() bool {
+	return s.InGossip && s.Healthy != nil && *s.Healthy
+}
+
+// summary renders the status for the peer selector and logs
+func (s peerStatus) summary() string {
+	parts := make([]string, 0, 5)
+	switch {
+	case s.InGossip && s.SameVersion:
+		parts = append(parts, fmt.Sprintf("in gossip %s", s.Version))
+	case s.InGossip:
+		parts = append(parts, fmt.Sprintf("in gossip %s (not this node's version)", s.Version))
+	default:
+		parts = append(parts, "not in gossip")
+	}
+
+	switch {
+	case s.Healthy == nil:
+		parts = append(parts, "health unknown")
+	case *s.Healthy:
+		parts = append(parts, "healthy")
+	default:
+		parts = append(parts, "unhealthy")
+	}
+
+	if s.SlotLag != nil {
+		parts = append(parts, fmt.Sprintf("%d slots behind", *s.SlotLag))
+	}
+
+	if s.RTT != nil {
+		parts = append(parts, fmt.Sprintf("rtt %s", s.RTT.Round(time.Millisecond)))
+	}
+
+	if s.LastFailoverAt.IsZero() {
+		parts = append(parts, "no failover on record")
+	} else {
+		parts = append(parts, fmt.Sprintf("last failover %s ago", time.Since(s.LastFailoverAt).Round(time.Minute)))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// healthOrder orders known healthy before unknown before known unhealthy
+func (s peerStatus) healthOrder() int {
+	switch {
+	case s.Healthy == nil:
+		return 1
+	case *s.Healthy:
+		return 0
+	default:
+		return 2
+	}
+}
+
+// rankPeerStatuses sorts statuses best candidate first - in gossip, healthy, highest priority, running this node's
+// client version, least slot lag (to within peerSlotLagBucket slots), failover server answering fastest, then most
+// recently failed over with so the pathway to it is known to work
+func rankPeerStatuses(statuses []peerStatus) {
+	sort.SliceStable(statuses, func(i, j int) bool {
+		a, b := statuses[i], statuses[j]
+		if a.InGossip != b.InGossip {
+			return a.InGossip
+		}
+		if a.healthOrder() != b.healthOrder() {
+			return a.healthOrder() < b.healthOrder()
+		}
+		if a.Peer.Priority != b.Peer.Priority {
+			return a.Peer.Priority > b.Peer.Priority
+		}
+		if a.SameVersion != b.SameVersion {
+			return a.SameVersion
+		}
+		if (a.SlotLag == nil) != (b.SlotLag == nil) {
+			return a.SlotLag != nil
+		}
+		if a.SlotLag != nil && *a.SlotLag/peerSlotLagBucket != *b.SlotLag/peerSlotLagBucket {
+			return *a.SlotLag < *b.SlotLag
+		}
+		if (a.RTT == nil) != (b.RTT == nil) {
+			return a.RTT != nil
+		}
+		if a.RTT != nil && *a.RTT != *b.RTT {
+			return *a.RTT < *b.RTT
+		}
+		if !a.LastFailoverAt.Equal(b.LastFailoverAt) {
+			return a.LastFailoverAt.After(b.LastFailoverAt)
+		}
+		return a.Peer.Name < b.Peer.Name
+	})
+}
+
+// rankPassivePeers queries every peer's status in parallel and returns them best candidate first - peers that
+// don't answer within failover.peer_selection.timeout rank with an unknown status
+func (v *Validator) rankPassivePeers() []peerStatus {
+	results := make(chan peerStatus, len(v.Peers))
+	for _, peer := range v.Peers {
+		go func(peer Peer) {
+			results <- v.peerStatusQuery(peer)
+		}(peer)
+	}
+
+	received := make(map[string]peerStatus, len(v.Peers))
+	deadline := time.After(v.peerSelectionTimeout)
+collect:
+	for len(received) < len(v.Peers) {
+		select {
+		case status := <-results:
+			received[status.Peer.Name] = status
+		case <-deadline:
+			break collect
+		}
+	}
+
+	statuses := make([]peerStatus, 0, len(v.Peers))
+	for name, peer := range v.Peers {
+		status, ok := received[name]
+		if !ok {
+			log.Debug().Str("peer_name", name).Dur("timeout", v.peerSelectionTimeout).Msg("timed out querying peer status")
+			status = peerStatus{Peer: peer}
+		}
+		if v.PeerPins != nil {
+			if pinned, ok := v.PeerPins.Get(name); ok {
+				status.LastFailoverAt = pinned.LastSeen
+			}
+		}
+		statuses = append(statuses, status)
+	}
+
+	rankPeerStatuses(statuses)
+	return statuses
+}
+
+// queryPeerStatus looks the peer up in gossip and, when it advertises rpc, asks it for its health and slot - its
+// failover server is probed for its rtt meanwhile
+func (v *Validator) queryPeerStatus(peer Peer) (status peerStatus) {
+	status = peerStatus{Peer: peer}
+
+	rtt := make(chan *time.Duration, 1)
+	go func() {
+		rtt <- v.peerRTT(peer)
+	}()
+	defer func() {
+		status.RTT = <-rtt
+	}()
+
+	gossipIP := peer.GossipIP
+	if gossipIP == "" {
+		gossipIP = utils.HostFromAddress(peer.Address)
+	}
+	node, err := v.solanaRPCClient.NodeFromIP(gossipIP)
+	if err != nil {
+		log.Debug().Err(err).Str("peer_name", peer.Name).Str("gossip_ip", gossipIP).Msg("peer not found in gossip")
+		return status
+	}
+	status.InGossip = true
+	status.Version = node.Version()
+	status.SameVersion = v.GossipNode != nil && status.Version == v.GossipNode.Version()
+
+	rpcAddress := node.RPCAddress()
+	if rpcAddress == "" {
+		log.Debug().Str("peer_name", peer.Name).Msg("peer does not advertise rpc in gossip - health and slot lag unknown")
+		return status
+	}
+
+	rpcURL := "http://" + rpcAddress
+	peerClient := v.NewSolanaRPCClient(solana.NewClientParams{LocalRPCURL: rpcURL, NetworkRPCURL: rpcURL})
+	healthy := peerClient.IsLocalNodeHealthy()
+	status.Healthy = &healthy
+
+	peerSlot, err := peerClient.GetCurrentSlot()
+	if err != nil {
+		log.Debug().Err(err).Str("peer_name", peer.Name).Msg("failed to get peer slot")
+		return status
+	}
+	networkSlot, err := v.solanaRPCClient.GetCurrentSlot()
+	if err != nil {
+		log.Debug().Err(err).Msg("failed to get network slot")
+		return status
+	}
+	var slotLag uint64
+	if networkSlot > peerSlot {
+		slotLag = networkSlot - peerSlot
+	}
+	status.SlotLag = &slotLag
+
+	return status
+}
+
+// peerRTT returns how long the peer's failover server took to answer a probe - nil when it didn't
+func (v *Validator) peerRTT(peer Peer) *time.Duration {
+	if v.peerProbe == nil {
+		return nil
+	}
+	start := time.Now()
+	if err := v.peerProbe(peer.Address); err != nil {
+		log.Debug().Err(err).Str("peer_name", peer.Name).Msg("peer failover server did not answer probe - rtt unknown")
+		return nil
+	}
+	rtt := time.Since(start)
+	return &rtt
+}
+
+// failoverPeers converts the configured peers to failover.PeerInfo for the failover server
+func (v *Validator) failoverPeers() []failover.PeerInfo {
+	peers := make([]failover.PeerInfo, 0, len(v.Peers))
+	for _, peer := range v.Peers {
+		peers = append(peers, failover.PeerInfo{
+			Name:          peer.Name,
+			Address:       peer.Address,
+			GossipIP:      peer.GossipIP,
+			PassivePubkey: peer.PassivePubkey,
+		})
+	}
+	return peers
+}
+
+// standbyPeers returns the peers other than the selected passive peer - told who took over once the failover completes
+func (v *Validator) standbyPeers(selectedPeerName string) []failover.PeerInfo {
+	standbys := make([]failover.PeerInfo, 0, len(v.Peers))
+	for _, peer := range v.failoverPeers() {
+		if peer.Name != selectedPeerName {
+			standbys = append(standbys, peer)
+		}
+	}
+	return standbys
+}
+
 // convertMonitorConfig converts validator.MonitorConfig to failover.MonitorConfig
 func convertMonitorConfig(cfg MonitorConfig) failover.MonitorConfig {
 	return failover.MonitorConfig{
@@ -755,5 +3950,10 @@ func convertMonitorConfig(cfg MonitorConfig) failover.MonitorConfig {
 			Count:    cfg.CreditSamples.Count,
 			Interval: cfg.CreditSamples.Interval,
 		},
+		MetricsSnapshot:           failover.MetricsSnapshotConfig(cfg.MetricsSnapshot),
+		RewardsEstimate:           failover.RewardsEstimateConfig(cfg.RewardsEstimate),
+		IdentityGapAlarmThreshold: cfg.IdentityGapAlarmThreshold,
+		DurationRegression:        failover.DurationRegressionConfig(cfg.DurationRegression),
+		BlockProduction:           failover.BlockProductionConfig(cfg.BlockProduction),
 	}
 }